@@ -0,0 +1,79 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/dbehnke/allstar-nexus/backend/models"
+	"github.com/dbehnke/allstar-nexus/backend/repository"
+	"github.com/dbehnke/allstar-nexus/internal/core"
+)
+
+// SetAMIConsole enables the superadmin AMI command console
+func (a *API) SetAMIConsole(console *core.AMIConsole, audit *repository.AuditRepo) {
+	a.AMIConsole = console
+	a.Audit = audit
+}
+
+// AMICommand lists the allowed commands and recent console activity (GET) or runs a
+// whitelisted read-only CLI command (POST). Superadmin only; every attempt is audited.
+// Endpoint: /api/admin/ami/command
+// POST body: {"command": "rpt stats 43732"}
+func (a *API) AMICommand(w http.ResponseWriter, r *http.Request) {
+	if a.AMIConsole == nil {
+		writeError(w, 503, "ami_console_unavailable", "AMI console requires an AMI connection")
+		return
+	}
+	u, status := a.currentUser(r)
+	if status != 200 {
+		writeError(w, status, "unauthorized", "authentication required")
+		return
+	}
+	if u.Role != models.RoleSuperAdmin {
+		writeError(w, 403, "forbidden", "superadmin role required")
+		return
+	}
+	switch r.Method {
+	case http.MethodGet:
+		var recent []models.AuditEntry
+		if a.Audit != nil {
+			ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+			defer cancel()
+			var err error
+			if recent, err = a.Audit.Recent(ctx, core.AuditActionAMICommand, 50); err != nil {
+				writeError(w, 500, "server_error", "failed to load audit log")
+				return
+			}
+		}
+		if recent == nil {
+			recent = []models.AuditEntry{}
+		}
+		writeJSON(w, 200, map[string]any{
+			"commands":         a.AMIConsole.Allowed(),
+			"max_output_bytes": a.AMIConsole.MaxBytes(),
+			"recent":           recent,
+		})
+	case http.MethodPost:
+		var body struct {
+			Command string `json:"command"`
+		}
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 4096)).Decode(&body); err != nil {
+			writeError(w, 400, "bad_request", "invalid json body")
+			return
+		}
+		res, err := a.AMIConsole.Run(r.Context(), u.Email, r.RemoteAddr, body.Command)
+		switch {
+		case errors.Is(err, core.ErrCommandNotAllowed):
+			writeError(w, 403, "command_not_allowed", "command is not in the console whitelist")
+		case err != nil:
+			writeError(w, 502, "ami_error", err.Error())
+		default:
+			writeJSON(w, 200, res)
+		}
+	default:
+		writeError(w, 405, "method_not_allowed", "only GET and POST supported")
+	}
+}
@@ -0,0 +1,98 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/dbehnke/allstar-nexus/backend/models"
+	"github.com/dbehnke/allstar-nexus/backend/repository"
+	"github.com/dbehnke/allstar-nexus/internal/core"
+)
+
+// SetAnnouncer enables text-to-speech announcements.
+func (a *API) SetAnnouncer(announcer *core.Announcer, repo *repository.AnnouncementRepo) {
+	a.Announcer = announcer
+	a.Announcements = repo
+}
+
+// AdminAnnouncements lists recent announcements (GET ?limit=), submits one (POST) or
+// cancels a pending one (DELETE ?id=).
+// Endpoint: /api/admin/announcements
+// POST body: {"text": "Net starts in 10 minutes", "node": 43732, "at": "2025-01-01T19:50:00Z"}
+// node and at are optional: the first local node, played right away.
+func (a *API) AdminAnnouncements(w http.ResponseWriter, r *http.Request) {
+	if a.Announcer == nil || a.Announcements == nil {
+		writeError(w, 503, "unavailable", "announcements not enabled")
+		return
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+	actor := ""
+	if u, status := a.currentUser(r); status == 200 {
+		actor = u.Email
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		limit := 50
+		if v := r.URL.Query().Get("limit"); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n <= 0 {
+				writeError(w, 400, "invalid_limit", "limit must be a positive integer")
+				return
+			}
+			limit = min(n, 500)
+		}
+		list, err := a.Announcements.Recent(ctx, limit)
+		if err != nil {
+			writeError(w, 500, "db_error", "failed to load announcements")
+			return
+		}
+		if list == nil {
+			list = []models.Announcement{}
+		}
+		writeJSON(w, 200, map[string]any{"announcements": list, "nodes": a.Announcer.Nodes()})
+	case http.MethodPost:
+		var body struct {
+			Text string    `json:"text"`
+			Node int       `json:"node"`
+			At   time.Time `json:"at"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			writeError(w, 400, "bad_request", "invalid json body (at must be RFC3339)")
+			return
+		}
+		ann, err := a.Announcer.Submit(ctx, actor, body.Text, body.Node, body.At)
+		if errors.Is(err, core.ErrInvalidAnnouncement) {
+			writeError(w, 400, "validation_error", err.Error())
+			return
+		}
+		if err != nil {
+			writeError(w, 500, "db_error", "failed to save announcement")
+			return
+		}
+		writeJSON(w, 200, ann)
+	case http.MethodDelete:
+		id, err := strconv.ParseUint(r.URL.Query().Get("id"), 10, 0)
+		if err != nil || id == 0 {
+			writeError(w, 400, "validation_error", "id query parameter required")
+			return
+		}
+		ok, err := a.Announcer.Cancel(ctx, actor, uint(id))
+		if err != nil {
+			writeError(w, 500, "db_error", "failed to cancel announcement")
+			return
+		}
+		if !ok {
+			writeError(w, 404, "not_found", "no pending announcement with that id")
+			return
+		}
+		writeJSON(w, 200, map[string]any{"id": id, "status": models.AnnouncementCanceled})
+	default:
+		writeError(w, 405, "method_not_allowed", "only GET, POST and DELETE supported")
+	}
+}
@@ -0,0 +1,53 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/dbehnke/allstar-nexus/internal/astdb"
+)
+
+// SetAstDBDownloader enables the admin astdb status and refresh endpoints
+func (a *API) SetAstDBDownloader(d *astdb.Downloader) {
+	a.AstDB = d
+}
+
+// AstDBStatus returns the astdb source URL, node count, last update times and the
+// progress of the running (or last) refresh.
+// Endpoint: GET /api/admin/astdb/status
+func (a *API) AstDBStatus(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, 405, "method_not_allowed", "only GET supported")
+		return
+	}
+	if a.AstDB == nil {
+		writeError(w, 503, "astdb_unavailable", "astdb downloader is not configured")
+		return
+	}
+	writeJSON(w, 200, a.AstDB.Status())
+}
+
+// AstDBRefresh starts an immediate astdb download and import in the background and
+// answers 202 with the status; poll /api/admin/astdb/status for progress. ?force=true
+// re-downloads and re-imports even when upstream reports the file unchanged.
+// Endpoint: POST /api/admin/astdb/refresh
+func (a *API) AstDBRefresh(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, 405, "method_not_allowed", "only POST supported")
+		return
+	}
+	if a.AstDB == nil {
+		writeError(w, 503, "astdb_unavailable", "astdb downloader is not configured")
+		return
+	}
+	force := r.URL.Query().Get("force") == "true"
+	if err := a.AstDB.Refresh(force); err != nil {
+		if errors.Is(err, astdb.ErrRefreshRunning) {
+			writeError(w, 409, "astdb_refresh_running", err.Error())
+			return
+		}
+		writeError(w, 500, "astdb_refresh_failed", err.Error())
+		return
+	}
+	writeJSON(w, 202, a.AstDB.Status())
+}
@@ -0,0 +1,44 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/dbehnke/allstar-nexus/internal/asterisklog"
+)
+
+// SetAsteriskLog enables the Asterisk log endpoint
+func (a *API) SetAsteriskLog(m *asterisklog.Monitor) {
+	a.AsteriskLog = m
+}
+
+// AsteriskLogEntries returns recent classified warnings from the Asterisk messages log, newest
+// first, with per-category counts since startup. ?category= filters and ?limit= caps
+// the list (default 100).
+// Endpoint: GET /api/admin/asterisk-log
+func (a *API) AsteriskLogEntries(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, 405, "method_not_allowed", "only GET supported")
+		return
+	}
+	if a.AsteriskLog == nil {
+		writeError(w, 503, "asterisk_log_unavailable", "asterisk_log is not enabled")
+		return
+	}
+	limit := 100
+	if s := r.URL.Query().Get("limit"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 0 {
+			writeError(w, 400, "invalid_limit", "limit must be a non-negative integer")
+			return
+		}
+		limit = n
+	}
+	st := a.AsteriskLog.Status()
+	writeJSON(w, 200, map[string]any{
+		"path":    st.Path,
+		"counts":  st.Counts,
+		"error":   st.Error,
+		"entries": a.AsteriskLog.Entries(r.URL.Query().Get("category"), limit),
+	})
+}
@@ -0,0 +1,367 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/dbehnke/allstar-nexus/backend/config"
+	"github.com/dbehnke/allstar-nexus/backend/repository"
+	"github.com/dbehnke/allstar-nexus/internal/tenant"
+)
+
+// Settings keys used for branding overrides.
+const (
+	brandingSettingKey = "branding"
+	brandingLogoKey    = "branding.logo"
+	maxLogoBytes       = 1 << 20
+	maxFooterLinks     = 10
+)
+
+// logoTypes maps accepted (sniffed) logo content types to file extensions. SVG is
+// deliberately excluded since it can carry script.
+var logoTypes = map[string]string{
+	"image/png":  ".png",
+	"image/jpeg": ".jpg",
+	"image/gif":  ".gif",
+	"image/webp": ".webp",
+}
+
+var (
+	colorKeyRe   = regexp.MustCompile(`^[a-z][a-z0-9_-]{0,31}$`)
+	colorValueRe = regexp.MustCompile(`^#([0-9a-fA-F]{3,4}|[0-9a-fA-F]{6}|[0-9a-fA-F]{8})$`)
+)
+
+// Branding is the effective dashboard theme returned by /api/branding.
+type Branding struct {
+	ClubName    string              `json:"club_name"`
+	Title       string              `json:"title"`
+	Subtitle    string              `json:"subtitle"`
+	LogoURL     string              `json:"logo_url"`
+	Colors      map[string]string   `json:"colors"`
+	FooterLinks []config.FooterLink `json:"footer_links"`
+}
+
+// brandingOverride is the admin-edited partial document stored in the settings table.
+// Nil fields inherit the config file value; colors are merged key by key.
+type brandingOverride struct {
+	ClubName    *string              `json:"club_name,omitempty"`
+	LogoURL     *string              `json:"logo_url,omitempty"`
+	Colors      map[string]string    `json:"colors,omitempty"`
+	FooterLinks *[]config.FooterLink `json:"footer_links,omitempty"`
+}
+
+type brandingLogo struct {
+	File        string    `json:"file"`
+	ContentType string    `json:"content_type"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// SetBranding enables the branding endpoints. Uploaded logos are stored under dataDir/branding.
+func (a *API) SetBranding(defaults Branding, settings *repository.SettingsRepo, dataDir string) {
+	a.BrandingDefaults = defaults
+	a.Settings = settings
+	a.BrandingDir = filepath.Join(dataDir, "branding")
+}
+
+// effectiveBranding merges config defaults, the stored override and any uploaded logo.
+// Hosted hubs (tenants) get the config defaults with their own branding instead of the
+// instance's stored override.
+func (a *API) effectiveBranding(ctx context.Context) (Branding, error) {
+	b := a.BrandingDefaults
+	b.Colors = make(map[string]string, len(a.BrandingDefaults.Colors))
+	for k, v := range a.BrandingDefaults.Colors {
+		b.Colors[k] = v
+	}
+	if b.FooterLinks == nil {
+		b.FooterLinks = []config.FooterLink{}
+	}
+	if t := tenant.FromContext(ctx); t != nil {
+		tb := t.Branding
+		if tb.Title != "" {
+			b.Title = tb.Title
+		}
+		if tb.Subtitle != "" {
+			b.Subtitle = tb.Subtitle
+		}
+		b.ClubName = tb.ClubName
+		if b.ClubName == "" {
+			b.ClubName = b.Title
+		}
+		if tb.LogoURL != "" {
+			b.LogoURL = tb.LogoURL
+		}
+		for k, v := range tb.Colors {
+			b.Colors[k] = v
+		}
+		return b, nil
+	}
+	if b.ClubName == "" {
+		b.ClubName = b.Title
+	}
+	if a.Settings == nil {
+		return b, nil
+	}
+	var logo brandingLogo
+	if ok, err := a.Settings.GetJSON(ctx, brandingLogoKey, &logo); err != nil {
+		return b, err
+	} else if ok {
+		b.LogoURL = fmt.Sprintf("/api/branding/logo?v=%d", logo.UpdatedAt.Unix())
+	}
+	var o brandingOverride
+	if ok, err := a.Settings.GetJSON(ctx, brandingSettingKey, &o); err != nil {
+		return b, err
+	} else if ok {
+		if o.ClubName != nil {
+			b.ClubName = *o.ClubName
+		}
+		if o.LogoURL != nil {
+			b.LogoURL = *o.LogoURL
+		}
+		for k, v := range o.Colors {
+			b.Colors[k] = v
+		}
+		if o.FooterLinks != nil {
+			b.FooterLinks = *o.FooterLinks
+		}
+	}
+	return b, nil
+}
+
+// GetBranding returns the effective dashboard branding (public).
+// Endpoint: /api/branding
+func (a *API) GetBranding(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, 405, "method_not_allowed", "only GET supported")
+		return
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+	b, err := a.effectiveBranding(ctx)
+	if err != nil {
+		writeError(w, 500, "db_error", "failed to load branding")
+		return
+	}
+	writeJSON(w, 200, b)
+}
+
+// AdminBranding shows (GET), replaces (PUT) or clears (DELETE) the stored branding override.
+// Endpoint: /api/admin/branding
+// PUT body: {"club_name": "WC8MI Hub", "colors": {"primary": "#1e88e5"}, "footer_links": [{"label": "QRZ", "url": "https://qrz.com"}]}
+func (a *API) AdminBranding(w http.ResponseWriter, r *http.Request) {
+	if a.Settings == nil {
+		writeError(w, 503, "branding_unavailable", "branding settings not configured")
+		return
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+	switch r.Method {
+	case http.MethodGet:
+		var o brandingOverride
+		if _, err := a.Settings.GetJSON(ctx, brandingSettingKey, &o); err != nil {
+			writeError(w, 500, "db_error", "failed to load branding")
+			return
+		}
+		b, _ := a.effectiveBranding(ctx)
+		writeJSON(w, 200, map[string]any{"override": o, "effective": b})
+	case http.MethodPut:
+		var o brandingOverride
+		if err := json.NewDecoder(io.LimitReader(r.Body, 64<<10)).Decode(&o); err != nil {
+			writeError(w, 400, "bad_request", "invalid json body")
+			return
+		}
+		if err := validateBrandingOverride(o); err != nil {
+			writeError(w, 400, "validation_error", err.Error())
+			return
+		}
+		if err := a.Settings.SetJSON(ctx, brandingSettingKey, o); err != nil {
+			writeError(w, 500, "db_error", "failed to save branding")
+			return
+		}
+		b, _ := a.effectiveBranding(ctx)
+		writeJSON(w, 200, b)
+	case http.MethodDelete:
+		if err := a.Settings.Delete(ctx, brandingSettingKey); err != nil {
+			writeError(w, 500, "db_error", "failed to reset branding")
+			return
+		}
+		b, _ := a.effectiveBranding(ctx)
+		writeJSON(w, 200, b)
+	default:
+		writeError(w, 405, "method_not_allowed", "only GET, PUT and DELETE supported")
+	}
+}
+
+func validateBrandingOverride(o brandingOverride) error {
+	if o.ClubName != nil && len(*o.ClubName) > 100 {
+		return fmt.Errorf("club_name must be at most 100 characters")
+	}
+	if o.LogoURL != nil && *o.LogoURL != "" && !validLinkURL(*o.LogoURL) {
+		return fmt.Errorf("logo_url must be an http(s) URL or an absolute path")
+	}
+	for k, v := range o.Colors {
+		if !colorKeyRe.MatchString(k) {
+			return fmt.Errorf("invalid color name %q", k)
+		}
+		if !colorValueRe.MatchString(v) {
+			return fmt.Errorf("color %s must be a hex value like #1e88e5, got %q", k, v)
+		}
+	}
+	if o.FooterLinks != nil {
+		if len(*o.FooterLinks) > maxFooterLinks {
+			return fmt.Errorf("at most %d footer links allowed", maxFooterLinks)
+		}
+		for i, l := range *o.FooterLinks {
+			if strings.TrimSpace(l.Label) == "" || len(l.Label) > 64 {
+				return fmt.Errorf("footer_links[%d]: label is required (max 64 characters)", i)
+			}
+			if !validLinkURL(l.URL) {
+				return fmt.Errorf("footer_links[%d]: url must be an http(s) URL or an absolute path", i)
+			}
+		}
+	}
+	return nil
+}
+
+func validLinkURL(u string) bool {
+	if strings.HasPrefix(u, "/") && !strings.HasPrefix(u, "//") {
+		return true
+	}
+	return strings.HasPrefix(u, "https://") || strings.HasPrefix(u, "http://")
+}
+
+// BrandingLogo serves the uploaded logo (public).
+// Endpoint: /api/branding/logo
+func (a *API) BrandingLogo(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		writeError(w, 405, "method_not_allowed", "only GET supported")
+		return
+	}
+	var logo brandingLogo
+	if a.Settings != nil {
+		ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+		defer cancel()
+		if ok, err := a.Settings.GetJSON(ctx, brandingLogoKey, &logo); err != nil || !ok {
+			writeError(w, 404, "not_found", "no logo uploaded")
+			return
+		}
+	} else {
+		writeError(w, 404, "not_found", "no logo uploaded")
+		return
+	}
+	f, err := os.Open(filepath.Join(a.BrandingDir, filepath.Base(logo.File)))
+	if err != nil {
+		writeError(w, 404, "not_found", "logo file missing")
+		return
+	}
+	defer func() { _ = f.Close() }()
+	w.Header().Set("Content-Type", logo.ContentType)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	// URLs carry ?v=<timestamp>, so a new upload busts caches.
+	w.Header().Set("Cache-Control", "public, max-age=86400")
+	http.ServeContent(w, r, logo.File, logo.UpdatedAt, f)
+}
+
+// AdminBrandingLogo uploads (POST multipart field "logo") or removes (DELETE) the dashboard logo.
+// Endpoint: /api/admin/branding/logo
+func (a *API) AdminBrandingLogo(w http.ResponseWriter, r *http.Request) {
+	if a.Settings == nil || a.BrandingDir == "" {
+		writeError(w, 503, "branding_unavailable", "branding settings not configured")
+		return
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+	switch r.Method {
+	case http.MethodPost:
+		r.Body = http.MaxBytesReader(w, r.Body, maxLogoBytes+64<<10)
+		if err := r.ParseMultipartForm(maxLogoBytes); err != nil {
+			writeError(w, 400, "bad_request", "logo must be a multipart upload of at most 1 MiB")
+			return
+		}
+		file, _, err := r.FormFile("logo")
+		if err != nil {
+			writeError(w, 400, "validation_error", "missing form field 'logo'")
+			return
+		}
+		defer func() { _ = file.Close() }()
+		data, err := io.ReadAll(io.LimitReader(file, maxLogoBytes+1))
+		if err != nil || len(data) == 0 || len(data) > maxLogoBytes {
+			writeError(w, 400, "validation_error", "logo must be between 1 byte and 1 MiB")
+			return
+		}
+		ctype := http.DetectContentType(data)
+		ext, ok := logoTypes[ctype]
+		if !ok {
+			writeError(w, 400, "validation_error", "logo must be a PNG, JPEG, GIF or WebP image")
+			return
+		}
+		if err := a.writeLogo(data, "logo"+ext); err != nil {
+			writeError(w, 500, "storage_error", "failed to store logo")
+			return
+		}
+		logo := brandingLogo{File: "logo" + ext, ContentType: ctype, UpdatedAt: time.Now().UTC()}
+		if err := a.Settings.SetJSON(ctx, brandingLogoKey, logo); err != nil {
+			writeError(w, 500, "db_error", "failed to save logo")
+			return
+		}
+		// An uploaded logo replaces any logo_url override.
+		var o brandingOverride
+		if ok, _ := a.Settings.GetJSON(ctx, brandingSettingKey, &o); ok && o.LogoURL != nil {
+			o.LogoURL = nil
+			_ = a.Settings.SetJSON(ctx, brandingSettingKey, o)
+		}
+		b, _ := a.effectiveBranding(ctx)
+		writeJSON(w, 200, b)
+	case http.MethodDelete:
+		if err := a.Settings.Delete(ctx, brandingLogoKey); err != nil {
+			writeError(w, 500, "db_error", "failed to remove logo")
+			return
+		}
+		a.removeLogos("")
+		b, _ := a.effectiveBranding(ctx)
+		writeJSON(w, 200, b)
+	default:
+		writeError(w, 405, "method_not_allowed", "only POST and DELETE supported")
+	}
+}
+
+// writeLogo atomically replaces the stored logo and removes files with other extensions.
+func (a *API) writeLogo(data []byte, name string) error {
+	if err := os.MkdirAll(a.BrandingDir, 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(a.BrandingDir, ".logo-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Rename(tmp.Name(), filepath.Join(a.BrandingDir, name)); err != nil {
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	a.removeLogos(name)
+	return nil
+}
+
+func (a *API) removeLogos(keep string) {
+	for _, ext := range logoTypes {
+		if name := "logo" + ext; name != keep {
+			_ = os.Remove(filepath.Join(a.BrandingDir, name))
+		}
+	}
+}
@@ -0,0 +1,190 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/dbehnke/allstar-nexus/backend/repository"
+	"github.com/dbehnke/allstar-nexus/internal/core"
+	"github.com/dbehnke/allstar-nexus/internal/timefmt"
+)
+
+// dashboardSourceTimeout bounds each part of the dashboard summary; a slow source is
+// reported as unavailable instead of holding up the page.
+const dashboardSourceTimeout = 750 * time.Millisecond
+
+// SetDashboardSources provides the transmission log and (when gamification is enabled)
+// profile stores used by the dashboard summary. Pass a nil interface, not a nil
+// repository pointer, to leave a source out.
+func (a *API) SetDashboardSources(txLogs repository.TxLogStore, profiles repository.ProfileStore) {
+	a.TxLogs = txLogs
+	a.Profiles = profiles
+}
+
+type dashboardSource struct {
+	name  string
+	fetch func(ctx context.Context) (any, error)
+}
+
+// gatherDashboard runs every source concurrently, each with its own timeout. Results are
+// keyed by source name; sources that fail or time out are listed in unavailable.
+func gatherDashboard(ctx context.Context, timeout time.Duration, sources []dashboardSource) (map[string]any, []string) {
+	type result struct {
+		name  string
+		value any
+		err   error
+	}
+	ch := make(chan result, len(sources)) // buffered so late sources never block
+	for _, src := range sources {
+		go func(src dashboardSource) {
+			sctx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+			v, err := src.fetch(sctx)
+			ch <- result{src.name, v, err}
+		}(src)
+	}
+
+	out := make(map[string]any, len(sources))
+	done := make(map[string]bool, len(sources))
+	unavailable := []string{}
+	deadline := time.NewTimer(timeout + 50*time.Millisecond)
+	defer deadline.Stop()
+collect:
+	for range sources {
+		select {
+		case res := <-ch:
+			done[res.name] = true
+			if res.err != nil {
+				unavailable = append(unavailable, res.name)
+				continue
+			}
+			out[res.name] = res.value
+		case <-deadline.C:
+			break collect
+		}
+	}
+	for _, src := range sources {
+		if !done[src.name] {
+			unavailable = append(unavailable, src.name)
+		}
+	}
+	sort.Strings(unavailable)
+	return out, unavailable
+}
+
+// DashboardSummary is the dashboard bootstrap payload: user counts, node state, links,
+// last talker, today's transmission totals, the top of the scoreboard and AMI health,
+// gathered concurrently so the front page loads with one request. Sources that are not
+// configured are omitted; sources that fail or time out are named in "unavailable".
+// Endpoint: GET /api/dashboard/summary
+func (a *API) DashboardSummary(w http.ResponseWriter, r *http.Request) {
+	v := a.viewer(r)
+	now := time.Now()
+	sources := []dashboardSource{
+		{"users", func(ctx context.Context) (any, error) {
+			counts, err := a.Users.RoleCounts(ctx)
+			if err != nil {
+				return nil, err
+			}
+			total, err := a.Users.Count(ctx)
+			if err != nil {
+				return nil, err
+			}
+			new24, err := a.Users.NewUsersSince(ctx, now.Add(-24*time.Hour))
+			if err != nil {
+				return nil, err
+			}
+			return map[string]any{"roles": counts, "total_users": total, "new_last_24h": new24}, nil
+		}},
+		{"ami", func(ctx context.Context) (any, error) {
+			health := map[string]any{"enabled": a.AMIConnector != nil, "connected": false}
+			if a.AMIConnector == nil || !a.AMIConnector.IsConnected() {
+				return health, nil
+			}
+			health["connected"] = true
+			start := time.Now()
+			if err := a.AMIConnector.Ping(ctx); err != nil {
+				health["ping_error"] = err.Error()
+			} else {
+				health["ping_ms"] = time.Since(start).Milliseconds()
+			}
+			return health, nil
+		}},
+	}
+	if a.StateManager != nil {
+		sources = append(sources,
+			dashboardSource{"node", func(ctx context.Context) (any, error) {
+				st := a.Privacy.NodeState(a.snapshot(r), v)
+				return map[string]any{
+					"node_id":     st.NodeID,
+					"rx_keyed":    st.RxKeyed,
+					"tx_keyed":    st.TxKeyed,
+					"uptime_sec":  st.UptimeSec,
+					"updated_at":  st.UpdatedAt,
+					"parrot":      len(st.ParrotModes) > 0,
+					"links":       len(st.Links),
+					"total_links": st.NumLinks,
+					"adjacent":    st.NumALinks,
+				}, nil
+			}},
+			dashboardSource{"last_talker", func(ctx context.Context) (any, error) {
+				events, _ := a.Privacy.TalkerLog(a.talkerLog(r), v).([]core.TalkerEvent)
+				for i := len(events) - 1; i >= 0; i-- {
+					if events[i].Kind == "TX_STOP" || events[i].Kind == "TX_START" {
+						return events[i], nil
+					}
+				}
+				return nil, nil
+			}},
+		)
+	}
+	if a.TxLogs != nil {
+		sources = append(sources, dashboardSource{"tx_today", func(ctx context.Context) (any, error) {
+			local := now.In(a.Locale.Zone())
+			midnight := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, local.Location())
+			totals, err := a.TxLogs.TotalsSince(ctx, midnight)
+			if err != nil {
+				return nil, err
+			}
+			return struct {
+				repository.TxTotals
+				DurationHuman string `json:"duration_human"`
+			}{totals, timefmt.Seconds(totals.Seconds)}, nil
+		}})
+	}
+	if a.Profiles != nil && a.Privacy.ShowCallsigns(v) {
+		sources = append(sources, dashboardSource{"top_talkers", func(ctx context.Context) (any, error) {
+			profiles, err := a.Profiles.GetLeaderboardExcluding(ctx, 3, a.OptOuts.List())
+			if err != nil {
+				return nil, err
+			}
+			top := make([]map[string]any, 0, len(profiles))
+			for i, p := range profiles {
+				top = append(top, map[string]any{
+					"rank":              i + 1,
+					"callsign":          p.Callsign,
+					"level":             p.Level,
+					"renown_level":      p.RenownLevel,
+					"experience_points": p.ExperiencePoints,
+				})
+			}
+			return top, nil
+		}})
+	}
+
+	parts, unavailable := gatherDashboard(r.Context(), dashboardSourceTimeout, sources)
+	resp := map[string]any{"generated_at": now.UTC(), "unavailable": unavailable, "time_format": a.Locale.Hints(now)}
+	// User counts stay at the top level for existing clients
+	if users, ok := parts["users"].(map[string]any); ok {
+		for k, val := range users {
+			resp[k] = val
+		}
+	}
+	delete(parts, "users")
+	for k, val := range parts {
+		resp[k] = val
+	}
+	writeJSON(w, 200, resp)
+}
@@ -0,0 +1,30 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestGatherDashboardIsolatesSlowAndFailingSources(t *testing.T) {
+	sources := []dashboardSource{
+		{"fast", func(ctx context.Context) (any, error) { return 1, nil }},
+		{"broken", func(ctx context.Context) (any, error) { return nil, errors.New("boom") }},
+		{"stuck", func(ctx context.Context) (any, error) {
+			time.Sleep(time.Second) // ignores ctx on purpose
+			return 2, nil
+		}},
+	}
+	start := time.Now()
+	out, unavailable := gatherDashboard(context.Background(), 50*time.Millisecond, sources)
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Fatalf("gather waited %v for a stuck source", elapsed)
+	}
+	if out["fast"] != 1 || len(out) != 1 {
+		t.Fatalf("out = %v", out)
+	}
+	if len(unavailable) != 2 || unavailable[0] != "broken" || unavailable[1] != "stuck" {
+		t.Fatalf("unavailable = %v", unavailable)
+	}
+}
@@ -0,0 +1,79 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/dbehnke/allstar-nexus/backend/repository"
+)
+
+// talkerForgetter is implemented by state managers that can drop in-memory talker history.
+type talkerForgetter interface {
+	ForgetTalker(callsign string, nodes []int) int
+}
+
+// SetCallsignData enables the per-callsign data deletion endpoint
+func (a *API) SetCallsignData(repo *repository.CallsignDataRepo) {
+	a.CallsignData = repo
+}
+
+// CallsignDataDeletion purges or anonymizes all stored data for a callsign: transmission
+// logs, XP activity, level history, the gamification profile and in-memory talker history.
+// Endpoint: POST /api/admin/data-deletion
+// Body: {"callsign": "W1AW", "mode": "delete" | "anonymize", "dry_run": true}
+func (a *API) CallsignDataDeletion(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, 405, "method_not_allowed", "only POST supported")
+		return
+	}
+	if a.CallsignData == nil {
+		writeError(w, 503, "unavailable", "data deletion not configured")
+		return
+	}
+	var body struct {
+		Callsign string `json:"callsign"`
+		Mode     string `json:"mode"`
+		DryRun   bool   `json:"dry_run"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, 400, "bad_request", "invalid json body")
+		return
+	}
+	if body.Callsign == "" {
+		writeError(w, 400, "validation_error", "callsign is required")
+		return
+	}
+	mode := repository.PurgeMode(body.Mode)
+	if mode == "" {
+		mode = repository.PurgeDelete
+	}
+	if mode != repository.PurgeDelete && mode != repository.PurgeAnonymize {
+		writeError(w, 400, "validation_error", "mode must be delete or anonymize")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+	defer cancel()
+	report, err := a.CallsignData.PurgeCallsign(ctx, body.Callsign, mode, body.DryRun)
+	if err != nil {
+		writeError(w, 500, "db_error", "failed to purge callsign data")
+		return
+	}
+
+	talkerEvents := 0
+	if f, ok := a.StateManager.(talkerForgetter); ok && !body.DryRun {
+		talkerEvents = f.ForgetTalker(report.Callsign, report.Nodes)
+	}
+	if !body.DryRun {
+		by := ""
+		if u, status := a.currentUser(r); status == 200 {
+			by = u.Email
+		}
+		log.Printf("[PRIVACY] %s data for %s by %s: tx_logs=%d xp_logs=%d profiles=%d talker_events=%d",
+			report.Mode, report.Callsign, by, report.TransmissionLogs, report.XPActivityLogs, report.Profiles, talkerEvents)
+	}
+	writeJSON(w, 200, map[string]any{"report": report, "talker_events": talkerEvents})
+}
@@ -0,0 +1,98 @@
+package api
+
+import (
+	"encoding/xml"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/dbehnke/allstar-nexus/internal/core"
+	"github.com/dbehnke/allstar-nexus/internal/privacy"
+)
+
+// SetActivityFeed enables the Atom feed of hub activity.
+func (a *API) SetActivityFeed(f *core.ActivityFeed) {
+	a.ActivityFeed = f
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+}
+
+type atomText struct {
+	Type string `xml:"type,attr,omitempty"`
+	Body string `xml:",chardata"`
+}
+
+type atomEntry struct {
+	ID       string `xml:"id"`
+	Title    string `xml:"title"`
+	Updated  string `xml:"updated"`
+	Category struct {
+		Term string `xml:"term,attr"`
+	} `xml:"category"`
+	Summary atomText `xml:"summary"`
+}
+
+type atomFeed struct {
+	XMLName xml.Name `xml:"http://www.w3.org/2005/Atom feed"`
+	ID      string   `xml:"id"`
+	Title   string   `xml:"title"`
+	Updated string   `xml:"updated"`
+	Author  struct {
+		Name string `xml:"name"`
+	} `xml:"author"`
+	Links   []atomLink  `xml:"link"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+// ActivityFeedAtom lists recent notable activity (QSOs, net sessions, level-ups and
+// long transmissions) as an Atom feed. Feed readers do not authenticate, so entries
+// are always filtered as for an anonymous viewer.
+// Endpoint: GET /feed.atom
+func (a *API) ActivityFeedAtom(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, 405, "method_not_allowed", "only GET supported")
+		return
+	}
+	if a.ActivityFeed == nil {
+		writeError(w, 503, "activity_feed_unavailable", "activity feed is not enabled")
+		return
+	}
+	b, _ := a.effectiveBranding(r.Context())
+	scheme := "http"
+	if r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https" {
+		scheme = "https"
+	}
+	self := scheme + "://" + r.Host + "/feed.atom"
+	host := r.Host
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h // tag URIs take a bare authority name
+	}
+
+	feed := atomFeed{ID: self, Title: b.ClubName + " activity", Updated: time.Now().UTC().Format(time.RFC3339)}
+	feed.Author.Name = b.ClubName
+	feed.Links = []atomLink{{Href: self, Rel: "self", Type: "application/atom+xml"}, {Href: scheme + "://" + r.Host + "/"}}
+	entries := a.Privacy.Activity(a.ActivityFeed.Entries(), privacy.ViewerAnonymous)
+	if len(entries) > 0 {
+		feed.Updated = entries[0].End.UTC().Format(time.RFC3339)
+	}
+	for _, e := range entries {
+		ae := atomEntry{
+			ID:      "tag:" + host + ",2025:" + e.ID,
+			Title:   e.Title(),
+			Updated: e.End.UTC().Format(time.RFC3339),
+			Summary: atomText{Type: "text", Body: e.Summary()},
+		}
+		ae.Category.Term = e.Kind
+		feed.Entries = append(feed.Entries, ae)
+	}
+
+	w.Header().Set("Content-Type", "application/atom+xml; charset=utf-8")
+	_, _ = w.Write([]byte(xml.Header))
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	_ = enc.Encode(feed)
+}
@@ -11,12 +11,15 @@ import (
 
 	cfgpkg "github.com/dbehnke/allstar-nexus/backend/config"
 	"github.com/dbehnke/allstar-nexus/backend/gamification"
+	"github.com/dbehnke/allstar-nexus/backend/models"
 	"github.com/dbehnke/allstar-nexus/backend/repository"
+	"github.com/dbehnke/allstar-nexus/internal/tenant"
+	"github.com/dbehnke/allstar-nexus/internal/timefmt"
 )
 
 type GamificationAPI struct {
-	profileRepo      *repository.CallsignProfileRepo
-	txLogRepo        *repository.TransmissionLogRepository
+	profileRepo      repository.ProfileStore
+	txLogRepo        repository.TxLogStore
 	levelRepo        *repository.LevelConfigRepo
 	activityRepo     *repository.XPActivityRepo
 	levelGroupings   []cfgpkg.LevelGrouping
@@ -31,11 +34,14 @@ type GamificationAPI struct {
 	weeklyCapSeconds       int
 	dailyCapSeconds        int
 	drTiers                []cfgpkg.DRTier
+	optOuts                *gamification.OptOuts
+	levelHistory           *repository.LevelHistoryRepo
+	renownTiers            []cfgpkg.RenownTier
 }
 
 func NewGamificationAPI(
-	profileRepo *repository.CallsignProfileRepo,
-	txLogRepo *repository.TransmissionLogRepository,
+	profileRepo repository.ProfileStore,
+	txLogRepo repository.TxLogStore,
 	levelRepo *repository.LevelConfigRepo,
 	activityRepo *repository.XPActivityRepo,
 	levelGroupings []cfgpkg.LevelGrouping,
@@ -69,6 +75,23 @@ func NewGamificationAPI(
 	}
 }
 
+// SetOptOuts hides opted-out callsigns from the scoreboard, profiles and recent
+// transmissions.
+func (g *GamificationAPI) SetOptOuts(o *gamification.OptOuts) {
+	g.optOuts = o
+}
+
+// SetLevelHistory enables the profile history endpoint.
+func (g *GamificationAPI) SetLevelHistory(repo *repository.LevelHistoryRepo) {
+	g.levelHistory = repo
+}
+
+// SetRenownTiers sets the renown titles returned with scoreboard and profile
+// entries.
+func (g *GamificationAPI) SetRenownTiers(tiers []cfgpkg.RenownTier) {
+	g.renownTiers = tiers
+}
+
 // Scoreboard returns top N callsigns ranked by renown, level, and XP
 // GET /api/gamification/scoreboard?limit=50
 func (g *GamificationAPI) Scoreboard(w http.ResponseWriter, r *http.Request) {
@@ -88,8 +111,12 @@ func (g *GamificationAPI) Scoreboard(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	// Get leaderboard
-	profiles, err := g.profileRepo.GetLeaderboard(ctx, limit)
+	// Get leaderboard; a hosted hub ranks the callsigns heard on its own nodes
+	var sources []int
+	if t := tenant.FromContext(r.Context()); t != nil {
+		sources = t.Nodes
+	}
+	profiles, err := g.profileRepo.GetLeaderboardForSources(ctx, limit, sources, g.optOuts.List())
 	if err != nil {
 		http.Error(w, "Failed to get leaderboard", http.StatusInternalServerError)
 		return
@@ -111,7 +138,9 @@ func (g *GamificationAPI) Scoreboard(w http.ResponseWriter, r *http.Request) {
 		RenownLevel        int                        `json:"renown_level"`
 		NextLevelXP        int                        `json:"next_level_xp"`
 		TotalTalkTime      int                        `json:"total_talk_time_seconds,omitempty"`
+		TotalTalkTimeHuman string                     `json:"total_talk_time_human,omitempty"`
 		Grouping           *gamification.GroupingInfo `json:"grouping,omitempty"`
+		RenownTitle        *gamification.GroupingInfo `json:"renown_title,omitempty"`
 		RestedBonusSeconds int                        `json:"rested_bonus_seconds"`
 	}
 
@@ -123,7 +152,7 @@ func (g *GamificationAPI) Scoreboard(w http.ResponseWriter, r *http.Request) {
 		}
 
 		// Get total talk time for this callsign
-		totalTime, _ := g.txLogRepo.GetTotalTransmissionTime(profile.Callsign)
+		totalTime, _ := g.txLogRepo.GetTotalTransmissionTimeForSources(profile.Callsign, sources)
 
 		// Get grouping for this level (if renown is 0)
 		var grouping *gamification.GroupingInfo
@@ -139,7 +168,9 @@ func (g *GamificationAPI) Scoreboard(w http.ResponseWriter, r *http.Request) {
 			RenownLevel:        profile.RenownLevel,
 			NextLevelXP:        nextLevelXP,
 			TotalTalkTime:      totalTime,
+			TotalTalkTimeHuman: timefmt.Seconds(int64(totalTime)),
 			Grouping:           grouping,
+			RenownTitle:        gamification.GetRenownTitle(profile.RenownLevel, g.renownTiers),
 			RestedBonusSeconds: profile.RestedBonusSeconds,
 		})
 	}
@@ -188,6 +219,14 @@ func (g *GamificationAPI) Profile(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Callsign required", http.StatusBadRequest)
 		return
 	}
+	if g.optOuts.Has(callsign) {
+		http.Error(w, "Profile not found", http.StatusNotFound)
+		return
+	}
+	if len(parts) > 5 && parts[5] == "history" {
+		g.profileHistory(w, r, callsign)
+		return
+	}
 
 	// Get profile
 	profile, err := g.profileRepo.GetByCallsign(ctx, callsign)
@@ -219,12 +258,21 @@ func (g *GamificationAPI) Profile(w http.ResponseWriter, r *http.Request) {
 	// Get recent activity breakdown
 	breakdown, _ := g.activityRepo.GetDailyBreakdown(ctx, callsign, 7)
 
+	// Project the next level from the recent pace
+	var estimate *gamification.LevelEstimate
+	if nextLevelXP > 0 {
+		window, _ := g.activityRepo.GetDailyBreakdown(ctx, profile.Callsign, gamification.EstimateWindowDays)
+		est := gamification.EstimateNextLevel(profile.Level, profile.ExperiencePoints, nextLevelXP, window, gamification.EstimateWindowDays, time.Now())
+		estimate = &est
+	}
+
 	w.Header().Set("Content-Type", "application/json")
 	if err := json.NewEncoder(w).Encode(map[string]any{
 		"callsign":                profile.Callsign,
 		"level":                   profile.Level,
 		"experience_points":       profile.ExperiencePoints,
 		"renown_level":            profile.RenownLevel,
+		"renown_title":            gamification.GetRenownTitle(profile.RenownLevel, g.renownTiers),
 		"next_level_xp":           nextLevelXP,
 		"total_talk_time_seconds": totalTime,
 		"rested_bonus_hours":      profile.RestedBonusSeconds / 3600,
@@ -233,11 +281,69 @@ func (g *GamificationAPI) Profile(w http.ResponseWriter, r *http.Request) {
 		"weekly_xp":               weeklyXP,
 		"daily_xp":                dailyXP,
 		"daily_breakdown":         breakdown,
+		"next_level_estimate":     estimate,
 	}); err != nil {
 		log.Printf("Failed to encode profile response: %v", err)
 	}
 }
 
+// profileHistory returns level/renown changes and daily awarded XP (with a running
+// total) for charting a callsign's progression. ?days= bounds both (default 90, max 365).
+// GET /api/gamification/profile/:callsign/history
+func (g *GamificationAPI) profileHistory(w http.ResponseWriter, r *http.Request, callsign string) {
+	if g.levelHistory == nil {
+		http.Error(w, "Level history not available", http.StatusServiceUnavailable)
+		return
+	}
+	days := 90
+	if s := r.URL.Query().Get("days"); s != "" {
+		if parsed, err := strconv.Atoi(s); err == nil && parsed > 0 && parsed <= 365 {
+			days = parsed
+		}
+	}
+	callsign = strings.ToUpper(callsign)
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	since := time.Now().UTC().AddDate(0, 0, -days)
+	levels, err := g.levelHistory.ForCallsign(ctx, callsign, since, 0)
+	if err != nil {
+		http.Error(w, "Failed to get level history", http.StatusInternalServerError)
+		return
+	}
+	daily, err := g.activityRepo.GetDailyBreakdown(ctx, callsign, days)
+	if err != nil {
+		http.Error(w, "Failed to get XP history", http.StatusInternalServerError)
+		return
+	}
+
+	// Daily breakdown is newest first; chart oldest first with a running total
+	type xpPoint struct {
+		Date         string `json:"date"`
+		AwardedXP    int    `json:"awarded_xp"`
+		CumulativeXP int    `json:"cumulative_xp"`
+	}
+	xp := make([]xpPoint, 0, len(daily))
+	total := 0
+	for i := len(daily) - 1; i >= 0; i-- {
+		total += daily[i].AwardedXP
+		xp = append(xp, xpPoint{Date: daily[i].Date, AwardedXP: daily[i].AwardedXP, CumulativeXP: total})
+	}
+	if levels == nil {
+		levels = []models.LevelHistory{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(map[string]any{
+		"callsign": callsign,
+		"days":     days,
+		"levels":   levels,
+		"xp":       xp,
+	}); err != nil {
+		log.Printf("Failed to encode profile history response: %v", err)
+	}
+}
+
 // RecentTransmissions returns paginated recent transmissions
 // GET /api/gamification/recent-transmissions?limit=50&offset=0
 func (g *GamificationAPI) RecentTransmissions(w http.ResponseWriter, r *http.Request) {
@@ -255,8 +361,12 @@ func (g *GamificationAPI) RecentTransmissions(w http.ResponseWriter, r *http.Req
 		}
 	}
 
-	// Get recent logs
-	logs, err := g.txLogRepo.GetRecentLogs(limit)
+	// Get recent logs (a hosted hub's own nodes only)
+	var sources []int
+	if t := tenant.FromContext(r.Context()); t != nil {
+		sources = t.Nodes
+	}
+	logs, err := g.txLogRepo.GetRecentLogsForSources(limit, sources)
 	if err != nil {
 		http.Error(w, "Failed to get transmissions", http.StatusInternalServerError)
 		return
@@ -274,8 +384,12 @@ func (g *GamificationAPI) RecentTransmissions(w http.ResponseWriter, r *http.Req
 	for _, log := range logs {
 		// Ensure we emit a correctly labeled UTC timestamp in RFC3339 format
 		ts := log.TimestampStart.UTC().Format(time.RFC3339)
+		callsign := log.Callsign
+		if g.optOuts.Has(callsign) {
+			callsign = ""
+		}
 		entries = append(entries, TransmissionEntry{
-			Callsign:        log.Callsign,
+			Callsign:        callsign,
 			Node:            log.AdjacentLinkID,
 			TimestampStart:  ts,
 			DurationSeconds: log.DurationSeconds,
@@ -326,6 +440,8 @@ func (g *GamificationAPI) LevelConfig(w http.ResponseWriter, r *http.Request) {
 		"rested_idle_threshold_seconds": g.restedIdleThresholdSec,
 		// DR config for UI
 		"dr_tiers": g.drTiers,
+		// Renown titles and their rested-bonus boosts
+		"renown_titles": g.renownTiers,
 	}); err != nil {
 		log.Printf("Failed to encode level config response: %v", err)
 	}
@@ -0,0 +1,200 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/dbehnke/allstar-nexus/backend/gamification"
+	"github.com/dbehnke/allstar-nexus/backend/models"
+	"github.com/dbehnke/allstar-nexus/backend/repository"
+)
+
+// SetGamificationOptOuts enables the opt-out endpoints and loads the stored opt-outs
+// into set, which the scoreboard, profiles, tally and notifications consult. onChange,
+// if set, runs after every change (e.g. to drop cached scoreboards).
+func (a *API) SetGamificationOptOuts(repo *repository.GamificationOptOutRepo, set *gamification.OptOuts, onChange func()) {
+	a.OptOutRepo = repo
+	a.OptOuts = set
+	a.onOptOutChange = onChange
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if !a.reloadOptOuts(ctx) {
+		log.Printf("[GAMIFICATION] failed to load opt-outs")
+	}
+}
+
+// GamificationOptOut lets a signed-in user check (GET ?callsign=), opt a callsign out
+// (POST) or back in (DELETE ?callsign=). Opting out only ever hides a callsign, so any
+// user may request it; opting back in is limited to the user who opted it out, or an
+// admin.
+// Endpoint: /api/gamification/opt-out
+// POST body: {"callsign": "W1AW"}
+func (a *API) GamificationOptOut(w http.ResponseWriter, r *http.Request) {
+	if a.OptOutRepo == nil {
+		writeError(w, 503, "unavailable", "gamification opt-out not configured")
+		return
+	}
+	u, status := a.currentUser(r)
+	if status != 200 {
+		writeError(w, status, "unauthorized", http.StatusText(status))
+		return
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	switch r.Method {
+	case http.MethodGet:
+		callsign, ok := optOutCallsign(w, r.URL.Query().Get("callsign"))
+		if !ok {
+			return
+		}
+		writeJSON(w, 200, map[string]any{"callsign": callsign, "opted_out": a.OptOuts.Has(callsign), "mode": a.OptOuts.Mode()})
+	case http.MethodPost:
+		var body struct {
+			Callsign string `json:"callsign"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			writeError(w, 400, "bad_request", "invalid json body")
+			return
+		}
+		callsign, ok := optOutCallsign(w, body.Callsign)
+		if !ok {
+			return
+		}
+		a.addOptOut(ctx, w, models.GamificationOptOut{Callsign: callsign, Source: models.OptOutSourceSelf, RequestedBy: u.Email})
+	case http.MethodDelete:
+		callsign, ok := optOutCallsign(w, r.URL.Query().Get("callsign"))
+		if !ok {
+			return
+		}
+		existing, err := a.OptOutRepo.Get(ctx, callsign)
+		if err != nil {
+			writeError(w, 500, "db_error", "failed to load opt-out")
+			return
+		}
+		if existing == nil {
+			writeError(w, 404, "not_found", "callsign has not opted out")
+			return
+		}
+		isAdmin := a.Tenants.IsAdmin(nil, u.Email, u.Role) // opt-outs span every hosted hub
+		if !isAdmin && (existing.Source != models.OptOutSourceSelf || existing.RequestedBy != u.Email) {
+			writeError(w, 403, "forbidden", "only the user who opted this callsign out, or an admin, can opt it back in")
+			return
+		}
+		a.removeOptOut(ctx, w, callsign)
+	default:
+		writeError(w, 405, "method_not_allowed", "only GET, POST and DELETE supported")
+	}
+}
+
+// AdminGamificationOptOuts lists (GET), adds (POST) and removes (DELETE ?callsign=)
+// gamification opt-outs.
+// Endpoint: /api/admin/gamification/opt-outs
+// POST body: {"callsign": "W1AW"}
+func (a *API) AdminGamificationOptOuts(w http.ResponseWriter, r *http.Request) {
+	if a.OptOutRepo == nil {
+		writeError(w, 503, "unavailable", "gamification opt-out not configured")
+		return
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	switch r.Method {
+	case http.MethodGet:
+		list, err := a.OptOutRepo.List(ctx)
+		if err != nil {
+			writeError(w, 500, "db_error", "failed to load opt-outs")
+			return
+		}
+		writeJSON(w, 200, map[string]any{"opt_outs": list, "mode": a.OptOuts.Mode()})
+	case http.MethodPost:
+		var body struct {
+			Callsign string `json:"callsign"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			writeError(w, 400, "bad_request", "invalid json body")
+			return
+		}
+		callsign, ok := optOutCallsign(w, body.Callsign)
+		if !ok {
+			return
+		}
+		by := ""
+		if u, status := a.currentUser(r); status == 200 {
+			by = u.Email
+		}
+		a.addOptOut(ctx, w, models.GamificationOptOut{Callsign: callsign, Source: models.OptOutSourceAdmin, RequestedBy: by})
+	case http.MethodDelete:
+		callsign, ok := optOutCallsign(w, r.URL.Query().Get("callsign"))
+		if !ok {
+			return
+		}
+		a.removeOptOut(ctx, w, callsign)
+	default:
+		writeError(w, 405, "method_not_allowed", "only GET, POST and DELETE supported")
+	}
+}
+
+// optOutCallsign normalizes and validates a callsign, writing a 400 if it is unusable.
+func optOutCallsign(w http.ResponseWriter, raw string) (string, bool) {
+	callsign := strings.ToUpper(strings.TrimSpace(raw))
+	if callsign == "" || len(callsign) > 20 {
+		writeError(w, 400, "validation_error", "callsign required (max 20 characters)")
+		return "", false
+	}
+	return callsign, true
+}
+
+func (a *API) addOptOut(ctx context.Context, w http.ResponseWriter, o models.GamificationOptOut) {
+	if err := a.OptOutRepo.Add(ctx, o); err != nil {
+		writeError(w, 500, "db_error", "failed to save opt-out")
+		return
+	}
+	if !a.reloadOptOuts(ctx) {
+		writeError(w, 500, "db_error", "saved but failed to reload opt-outs")
+		return
+	}
+	log.Printf("[GAMIFICATION] %s opted out by %s (%s)", o.Callsign, o.RequestedBy, o.Source)
+	writeJSON(w, 200, map[string]any{"callsign": o.Callsign, "opted_out": true, "mode": a.OptOuts.Mode()})
+}
+
+func (a *API) removeOptOut(ctx context.Context, w http.ResponseWriter, callsign string) {
+	found, err := a.OptOutRepo.Delete(ctx, callsign)
+	if err != nil {
+		writeError(w, 500, "db_error", "failed to delete opt-out")
+		return
+	}
+	if !found {
+		writeError(w, 404, "not_found", "callsign has not opted out")
+		return
+	}
+	if !a.reloadOptOuts(ctx) {
+		writeError(w, 500, "db_error", "deleted but failed to reload opt-outs")
+		return
+	}
+	writeJSON(w, 200, map[string]any{"callsign": callsign, "opted_out": false})
+}
+
+// reloadOptOuts pushes the stored opt-outs into the shared set.
+func (a *API) reloadOptOuts(ctx context.Context) bool {
+	if a.OptOuts == nil {
+		return true
+	}
+	list, err := a.OptOutRepo.List(ctx)
+	if err != nil {
+		return false
+	}
+	callsigns := make([]string, 0, len(list))
+	for _, o := range list {
+		callsigns = append(callsigns, o.Callsign)
+	}
+	a.OptOuts.Set(callsigns)
+	if a.onOptOutChange != nil {
+		a.onOptOutChange()
+	}
+	return true
+}
@@ -7,13 +7,22 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/dbehnke/allstar-nexus/backend/auth"
+	"github.com/dbehnke/allstar-nexus/backend/gamification"
 	"github.com/dbehnke/allstar-nexus/backend/models"
 	"github.com/dbehnke/allstar-nexus/backend/repository"
 	"github.com/dbehnke/allstar-nexus/internal/ami"
+	"github.com/dbehnke/allstar-nexus/internal/astdb"
+	"github.com/dbehnke/allstar-nexus/internal/asterisklog"
 	"github.com/dbehnke/allstar-nexus/internal/core"
+	"github.com/dbehnke/allstar-nexus/internal/privacy"
+	"github.com/dbehnke/allstar-nexus/internal/tenant"
+	"github.com/dbehnke/allstar-nexus/internal/timefmt"
+	"github.com/dbehnke/allstar-nexus/internal/timesync"
+	"github.com/dbehnke/allstar-nexus/internal/web"
 	"gorm.io/gorm"
 )
 
@@ -23,16 +32,62 @@ type StateManagerInterface interface {
 }
 
 type API struct {
-	Users        *repository.UserRepo
-	Secret       string
-	TTL          time.Duration
-	LinkStats    *repository.LinkStatsRepo
-	AMIConnector *ami.Connector
-	StateManager StateManagerInterface
-	AstDBPath    string
-	TriggerPoll  func(nodeID int)
-	BuildVersion string
-	BuildTime    string
+	Users            repository.UserStore
+	Secret           string
+	TTL              time.Duration
+	LinkStats        repository.LinkStatsStore
+	AMIConnector     *ami.Connector
+	StateManager     StateManagerInterface
+	AstDBPath        string
+	TriggerPoll      func(nodeID int)
+	PollStatus       func() []core.NodePollStatus
+	BuildVersion     string
+	BuildTime        string
+	IdleDetector     *core.IdleDetector
+	Parrot           *core.ParrotController
+	ParrotDefault    time.Duration
+	TimeSync         *timesync.Checker
+	TallyState       *repository.TallyStateRepo
+	Settings         *repository.SettingsRepo
+	BrandingDefaults Branding
+	BrandingDir      string
+	Privacy          privacy.Policy
+	CallsignData     *repository.CallsignDataRepo
+	NodeAnnotations  *repository.NodeAnnotationRepo
+	WatchlistRepo    *repository.WatchlistRepo
+	Watchlist        *core.Watchlist
+	Topology         *core.TopologyService
+	TopologyHistory  *core.TopologyHistory
+	LocalNodes       *repository.LocalNodeRepo
+	NodeLabels       *repository.NodeLabelRepo
+	Lookup           *core.NodeLookupService
+	AMIConsole       *core.AMIConsole
+	IAX              *core.IAXMonitor
+	WSHub            *web.Hub
+	wsBenchRunning   atomic.Bool
+	Audit            *repository.AuditRepo
+	TxLogs           repository.TxLogStore
+	Profiles         repository.ProfileStore
+	WidgetRefresh    time.Duration
+	ActivityFeed     *core.ActivityFeed
+	AsteriskLog      *asterisklog.Monitor
+	NodeHealth       *repository.NodeHealthRepo
+	LinkQuality      *repository.LinkQualityRepo
+	AstDB            *astdb.Downloader
+	Locale           timefmt.Locale
+	OptOutRepo       *repository.GamificationOptOutRepo
+	OptOuts          *gamification.OptOuts
+	onOptOutChange   func()
+	TallyRuns        *repository.TallyRunRepo
+	RunTally         func() (gamification.TallySummary, error)
+	TallyProgress    func() gamification.TallyProgress
+	TelegramLinker   TelegramLinker
+	Announcer        *core.Announcer
+	Announcements    *repository.AnnouncementRepo
+	RptConfig        *core.RptConfigEditor
+	Tenants          *tenant.Registry
+	NodeClaims       *repository.NodeClaimRepo
+	NodeActions      *core.NodeActionRelay
 }
 
 func New(db *gorm.DB, secret string, ttl time.Duration) *API {
@@ -43,6 +98,7 @@ func New(db *gorm.DB, secret string, ttl time.Duration) *API {
 		TTL:          ttl,
 		AMIConnector: nil,
 		AstDBPath:    "",
+		Privacy:      privacy.DefaultPolicy(),
 	}
 }
 
@@ -66,6 +122,37 @@ func (a *API) SetTriggerPoll(fn func(nodeID int)) {
 	a.TriggerPoll = fn
 }
 
+// SetPollStatus configures a function reporting the last poll outcome per node
+func (a *API) SetPollStatus(fn func() []core.NodePollStatus) {
+	a.PollStatus = fn
+}
+
+// SetTopology exposes the multi-hop link graph via the API
+func (a *API) SetTopology(ts *core.TopologyService) {
+	a.Topology = ts
+}
+
+// SetTopologyHistory enables /api/topology/history
+func (a *API) SetTopologyHistory(h *core.TopologyHistory) {
+	a.TopologyHistory = h
+}
+
+// SetIdleDetector exposes the hub idle detector status via the API
+func (a *API) SetIdleDetector(d *core.IdleDetector) {
+	a.IdleDetector = d
+}
+
+// SetLocale sets the hub timezone and language tag reported alongside stats so
+// clients can format times the way the hub does.
+func (a *API) SetLocale(l timefmt.Locale) {
+	a.Locale = l
+}
+
+// timeHints describes the hub's timezone and locale for stats payloads.
+func (a *API) timeHints() timefmt.Hints {
+	return a.Locale.Hints(time.Now())
+}
+
 // SetBuildInfo sets the build version and build time
 func (a *API) SetBuildInfo(version, buildTime string) {
 	a.BuildVersion = version
@@ -160,7 +247,13 @@ func (a *API) Me(w http.ResponseWriter, r *http.Request) {
 		writeError(w, status, "unauthorized", http.StatusText(status))
 		return
 	}
-	writeJSON(w, 200, map[string]any{"email": u.Email, "role": u.Role, "id": u.ID})
+	out := map[string]any{"email": u.Email, "role": u.Role, "id": u.ID}
+	if t := tenant.FromContext(r.Context()); t != nil {
+		// tenant admins keep the user role; the dashboard needs to know they administer this hub
+		out["tenant"] = t.ID
+		out["tenant_admin"] = a.isAdmin(r, u.Email, u.Role)
+	}
+	writeJSON(w, 200, out)
 }
 
 // AdminSummary returns aggregate info (requires admin or superadmin)
@@ -193,6 +286,7 @@ func (a *API) LinkStatsHandler(w http.ResponseWriter, r *http.Request) {
 		writeError(w, 500, "db_error", "failed to load link stats")
 		return
 	}
+	stats = a.scopeLinkStats(r, stats)
 	q := r.URL.Query()
 	// since can be RFC3339 or relative like -1h, -15m, -30s
 	if sinceStr := q.Get("since"); sinceStr != "" {
@@ -255,7 +349,24 @@ func (a *API) LinkStatsHandler(w http.ResponseWriter, r *http.Request) {
 			stats = stats[:lim]
 		}
 	}
-	writeJSON(w, 200, map[string]any{"stats": stats, "generated_at": time.Now().UTC()})
+	// Persisted connection details follow the same privacy policy as live links
+	v := a.viewer(r)
+	showCallsigns := a.Privacy.ShowCallsigns(v)
+	type linkStatView struct {
+		models.LinkStat
+		TotalTxHuman string `json:"total_tx_human"`
+	}
+	out := make([]linkStatView, 0, len(stats))
+	for _, s := range stats {
+		s.IP = a.Privacy.MaskIP(s.IP, v)
+		if !showCallsigns {
+			s.NodeCallsign = ""
+			s.NodeDescription = ""
+			s.NodeLocation = ""
+		}
+		out = append(out, linkStatView{LinkStat: s, TotalTxHuman: timefmt.Seconds(int64(s.TotalTxSeconds))})
+	}
+	writeJSON(w, 200, map[string]any{"stats": out, "generated_at": time.Now().UTC(), "time_format": a.timeHints()})
 }
 
 // TopLinkStatsHandler returns top N links by total_tx_seconds (default) or by tx rate (requires connected_since)
@@ -268,6 +379,7 @@ func (a *API) TopLinkStatsHandler(w http.ResponseWriter, r *http.Request) {
 		writeError(w, 500, "db_error", "failed to load link stats")
 		return
 	}
+	stats = a.scopeLinkStats(r, stats)
 	q := r.URL.Query()
 	mode := q.Get("mode")
 	if mode == "" {
@@ -310,6 +422,7 @@ func (a *API) TopLinkStatsHandler(w http.ResponseWriter, r *http.Request) {
 		limit = len(rows)
 	}
 	out := make([]any, 0, limit)
+	showCallsigns := a.Privacy.ShowCallsigns(a.viewer(r))
 	for i := 0; i < limit; i++ {
 		r := rows[i]
 		// Lookup node information from astdb
@@ -318,12 +431,13 @@ func (a *API) TopLinkStatsHandler(w http.ResponseWriter, r *http.Request) {
 		entry := map[string]any{
 			"node":             r.Node,
 			"total_tx_seconds": r.TotalTxSeconds,
+			"total_tx_human":   timefmt.Seconds(int64(r.TotalTxSeconds)),
 			"connected_since":  r.ConnectedSince,
 			"updated_at":       r.UpdatedAt,
 		}
 
-		// Add node information if found
-		if nodeInfo != nil {
+		// Add node information if found (and the privacy policy allows it)
+		if nodeInfo != nil && showCallsigns {
 			entry["callsign"] = nodeInfo.Callsign
 			entry["description"] = nodeInfo.Description
 			entry["location"] = nodeInfo.Location
@@ -336,7 +450,7 @@ func (a *API) TopLinkStatsHandler(w http.ResponseWriter, r *http.Request) {
 
 		out = append(out, entry)
 	}
-	writeJSON(w, 200, map[string]any{"mode": mode, "limit": limit, "results": out, "generated_at": time.Now().UTC()})
+	writeJSON(w, 200, map[string]any{"mode": mode, "limit": limit, "results": out, "generated_at": time.Now().UTC(), "time_format": a.timeHints()})
 }
 
 // helper: parse bearer JWT and load user
@@ -398,31 +512,69 @@ func (a *API) PollNow(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, 200, map[string]any{"ok": true, "node": nodeID})
 }
 
-// DashboardSummary public minimal placeholder.
-func (a *API) DashboardSummary(w http.ResponseWriter, r *http.Request) {
-	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
-	defer cancel()
-	counts, err := a.Users.RoleCounts(ctx)
-	if err != nil {
-		writeError(w, 500, "db_error", "failed to compute role counts")
+// PollStatusHandler reports when each node was last polled and whether it succeeded.
+func (a *API) PollStatusHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, 405, "method_not_allowed", "only GET supported")
 		return
 	}
-	total, err := a.Users.Count(ctx)
-	if err != nil {
-		writeError(w, 500, "db_error", "failed to count users")
+	if a.PollStatus == nil {
+		writeError(w, 503, "poll_unavailable", "polling service not available")
 		return
 	}
-	new24, err := a.Users.NewUsersSince(ctx, time.Now().Add(-24*time.Hour))
+	writeJSON(w, 200, map[string]any{"nodes": a.PollStatus()})
+}
+
+// TopologyHandler returns the link graph (nodes and edges) assembled from polled XStat data.
+func (a *API) TopologyHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, 405, "method_not_allowed", "only GET supported")
+		return
+	}
+	if a.Topology == nil {
+		writeError(w, 503, "topology_unavailable", "topology requires the polling service")
+		return
+	}
+	writeJSON(w, 200, a.Privacy.Topology(tenant.FromContext(r.Context()).Topology(a.Topology.Snapshot()), a.viewer(r)))
+}
+
+// TopologyHistoryHandler reconstructs the link graph as it was at a past moment.
+// Query: at=<RFC3339 time> or relative like -12h (required)
+func (a *API) TopologyHistoryHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, 405, "method_not_allowed", "only GET supported")
+		return
+	}
+	if a.TopologyHistory == nil || a.Topology == nil {
+		writeError(w, 503, "topology_unavailable", "topology history is not enabled")
+		return
+	}
+	atStr := r.URL.Query().Get("at")
+	var at time.Time
+	if strings.HasPrefix(atStr, "-") {
+		if d, err := time.ParseDuration(strings.TrimPrefix(atStr, "-")); err == nil {
+			at = time.Now().Add(-d)
+		}
+	} else if t, err := time.Parse(time.RFC3339, atStr); err == nil {
+		at = t
+	}
+	if at.IsZero() {
+		writeError(w, 400, "validation_error", "at must be an RFC3339 time or a relative duration like -12h")
+		return
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+	topo, recordedAt, ok, err := a.TopologyHistory.At(ctx, at)
 	if err != nil {
-		writeError(w, 500, "db_error", "failed to compute recent users")
+		writeError(w, 500, "db_error", "failed to load topology history")
 		return
 	}
-	writeJSON(w, 200, map[string]any{
-		"roles":        counts,
-		"total_users":  total,
-		"new_last_24h": new24,
-		"generated_at": time.Now().UTC(),
-	})
+	if !ok {
+		writeError(w, 404, "not_found", "no topology history recorded at or before that time")
+		return
+	}
+	topo = a.Privacy.Topology(tenant.FromContext(r.Context()).Topology(a.Topology.Label(topo)), a.viewer(r))
+	writeJSON(w, 200, map[string]any{"at": at.UTC(), "recorded_at": recordedAt, "topology": topo})
 }
 
 // TalkerLog returns the current talker log snapshot
@@ -441,10 +593,47 @@ func (a *API) TalkerLog(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	events := a.StateManager.TalkerLogSnapshot()
+	v := a.viewer(r)
+	events := a.Privacy.TalkerLog(a.talkerLog(r), v)
+	writeJSON(w, 200, map[string]any{
+		"ok":         true,
+		"events":     events,
+		"restricted": !a.Privacy.ShowTalkerHistory(v),
+	})
+}
+
+// TalkerSessions returns the talker log consolidated into sessions, newest first, with
+// transmissions still in progress flagged as ongoing. ?limit= caps the number returned.
+// Endpoint: GET /api/talker-sessions
+func (a *API) TalkerSessions(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, 405, "method_not_allowed", "only GET supported")
+		return
+	}
+	limit := 0
+	if s := r.URL.Query().Get("limit"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 0 {
+			writeError(w, 400, "invalid_limit", "limit must be a non-negative integer")
+			return
+		}
+		limit = n
+	}
+	if a.StateManager == nil {
+		writeJSON(w, 200, map[string]any{"sessions": []core.TalkerSession{}})
+		return
+	}
+
+	v := a.viewer(r)
+	events, _ := a.Privacy.TalkerLog(a.talkerLog(r), v).([]core.TalkerEvent)
+	sessions := core.GroupTalkerSessions(events, time.Now())
+	if limit > 0 && len(sessions) > limit {
+		sessions = sessions[:limit]
+	}
 	writeJSON(w, 200, map[string]any{
-		"ok":     true,
-		"events": events,
+		"sessions":    sessions,
+		"restricted":  !a.Privacy.ShowTalkerHistory(v),
+		"time_format": a.timeHints(),
 	})
 }
 
@@ -458,6 +647,19 @@ func (a *API) Status(w http.ResponseWriter, r *http.Request) {
 		writeJSON(w, 200, map[string]any{"ok": true, "state": core.NodeState{}})
 		return
 	}
-	snap := a.StateManager.Snapshot()
+	snap := a.Privacy.NodeState(a.snapshot(r), a.viewer(r))
 	writeJSON(w, 200, map[string]any{"ok": true, "state": snap})
 }
+
+// IdleStatus returns hub-wide idle tracking (GET /api/idle-status)
+func (a *API) IdleStatus(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, 405, "method_not_allowed", "only GET supported")
+		return
+	}
+	if a.IdleDetector == nil {
+		writeJSON(w, 200, map[string]any{"enabled": false})
+		return
+	}
+	writeJSON(w, 200, map[string]any{"enabled": true, "status": a.IdleDetector.Status()})
+}
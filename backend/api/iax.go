@@ -0,0 +1,45 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/dbehnke/allstar-nexus/internal/core"
+	"github.com/dbehnke/allstar-nexus/internal/privacy"
+)
+
+// SetIAXMonitor enables the IAX2 registration/peer status endpoint
+func (a *API) SetIAXMonitor(m *core.IAXMonitor) {
+	a.IAX = m
+}
+
+// IAXStatus returns the last parsed `iax2 show registry` / `iax2 show peers` output.
+// Addresses are masked for non-admins like link IPs.
+// Endpoint: GET /api/iax-status
+func (a *API) IAXStatus(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, 405, "method_not_allowed", "only GET supported")
+		return
+	}
+	if a.IAX == nil {
+		writeError(w, 503, "iax_monitor_unavailable", "IAX monitoring requires an AMI connection")
+		return
+	}
+	st := a.IAX.Status()
+	if v := a.viewer(r); v != privacy.ViewerAdmin {
+		mask := func(addr string) string {
+			if strings.HasPrefix(addr, "(") || strings.HasPrefix(addr, "<") {
+				return addr // placeholders such as (Unspecified) and <Unregistered>
+			}
+			return a.Privacy.MaskIP(addr, v)
+		}
+		for i := range st.Registrations {
+			st.Registrations[i].Host = mask(st.Registrations[i].Host)
+			st.Registrations[i].Perceived = mask(st.Registrations[i].Perceived)
+		}
+		for i := range st.Peers {
+			st.Peers[i].Host = mask(st.Peers[i].Host)
+		}
+	}
+	writeJSON(w, 200, st)
+}
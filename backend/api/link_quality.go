@@ -0,0 +1,68 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/dbehnke/allstar-nexus/backend/models"
+	"github.com/dbehnke/allstar-nexus/backend/repository"
+	"github.com/dbehnke/allstar-nexus/internal/privacy"
+)
+
+// SetLinkQuality enables the link quality endpoint
+func (a *API) SetLinkQuality(repo *repository.LinkQualityRepo) {
+	a.LinkQuality = repo
+}
+
+// LinkQualityHandler returns sampled jitter, loss and packet counts per connection.
+// Without ?node= it lists the latest sample of every connection seen in the period
+// (highest receive loss first); with ?node= it lists that node's samples as a time
+// series. ?since= works as for /api/node-health (default -24h). Peer addresses are
+// masked for non-admins like link IPs.
+// Endpoint: GET /api/link-quality
+func (a *API) LinkQualityHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, 405, "method_not_allowed", "only GET supported")
+		return
+	}
+	if a.LinkQuality == nil {
+		writeError(w, 503, "link_quality_unavailable", "link_quality sampling is not enabled")
+		return
+	}
+	q := r.URL.Query()
+	since, ok := parseSince(q.Get("since"), 24*time.Hour)
+	if !ok {
+		writeError(w, 400, "invalid_since", "since must be a relative duration like -24h or an RFC3339 time")
+		return
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	var rows []models.LinkQuality
+	var err error
+	resp := map[string]any{"since": since.UTC()}
+	if s := q.Get("node"); s != "" {
+		node, convErr := strconv.Atoi(s)
+		if convErr != nil || node <= 0 {
+			writeError(w, 400, "invalid_node", "node must be a positive integer")
+			return
+		}
+		rows, err = a.LinkQuality.History(ctx, node, since)
+		resp["node"] = node
+	} else {
+		rows, err = a.LinkQuality.Latest(ctx, since)
+	}
+	if err != nil {
+		writeError(w, 500, "db_error", "failed to load link quality")
+		return
+	}
+	if v := a.viewer(r); v != privacy.ViewerAdmin {
+		for i := range rows {
+			rows[i].Peer = a.Privacy.MaskIP(rows[i].Peer, v)
+		}
+	}
+	resp["samples"] = rows
+	writeJSON(w, 200, resp)
+}
@@ -0,0 +1,135 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dbehnke/allstar-nexus/backend/models"
+	"github.com/dbehnke/allstar-nexus/backend/repository"
+	"github.com/dbehnke/allstar-nexus/internal/core"
+)
+
+// SetLocalNodes enables the private node registry endpoint and loads the stored entries
+// into lookup, which consults them before astdb.
+func (a *API) SetLocalNodes(repo *repository.LocalNodeRepo, lookup *core.NodeLookupService) {
+	a.LocalNodes = repo
+	a.Lookup = lookup
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if !a.reloadLocalNodes(ctx) {
+		log.Printf("[LOCAL NODES] failed to load local node registry")
+	}
+}
+
+// localNodeRequest is the POST body for AdminLocalNodes.
+type localNodeRequest struct {
+	Node        int    `json:"node"`
+	Callsign    string `json:"callsign"`
+	Description string `json:"description"`
+	Location    string `json:"location"`
+}
+
+// AdminLocalNodes lists (GET), adds or updates (POST) and removes (DELETE ?node=) private
+// nodes that are not in astdb.
+// Endpoint: /api/admin/local-nodes
+// POST body: {"node": 1999, "callsign": "W8XYZ", "description": "Club hub", "location": "Detroit, MI"}
+func (a *API) AdminLocalNodes(w http.ResponseWriter, r *http.Request) {
+	if a.LocalNodes == nil {
+		writeError(w, 503, "unavailable", "local node registry not configured")
+		return
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	switch r.Method {
+	case http.MethodGet:
+		list, err := a.LocalNodes.List(ctx)
+		if err != nil {
+			writeError(w, 500, "db_error", "failed to load local nodes")
+			return
+		}
+		writeJSON(w, 200, map[string]any{"nodes": list})
+	case http.MethodPost:
+		var body localNodeRequest
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			writeError(w, 400, "bad_request", "invalid json body")
+			return
+		}
+		body.Callsign = strings.ToUpper(strings.TrimSpace(body.Callsign))
+		body.Description = strings.TrimSpace(body.Description)
+		body.Location = strings.TrimSpace(body.Location)
+		if body.Node <= 0 {
+			writeError(w, 400, "validation_error", "node must be a positive node number")
+			return
+		}
+		if body.Callsign == "" && body.Description == "" {
+			writeError(w, 400, "validation_error", "callsign or description required")
+			return
+		}
+		if len(body.Callsign) > 20 || len(body.Description) > 255 || len(body.Location) > 255 {
+			writeError(w, 400, "validation_error", "callsign (20), description or location (255) too long")
+			return
+		}
+		by := ""
+		if u, status := a.currentUser(r); status == 200 {
+			by = u.Email
+		}
+		entry := models.LocalNode{
+			Node: body.Node, Callsign: body.Callsign, Description: body.Description, Location: body.Location,
+			Source: models.LocalNodeSourceAdmin, UpdatedBy: by,
+		}
+		if err := a.LocalNodes.Save(ctx, entry); err != nil {
+			writeError(w, 500, "db_error", "failed to save local node")
+			return
+		}
+		if !a.reloadLocalNodes(ctx) {
+			writeError(w, 500, "db_error", "saved but failed to reload local nodes")
+			return
+		}
+		writeJSON(w, 200, entry)
+	case http.MethodDelete:
+		node, err := strconv.Atoi(r.URL.Query().Get("node"))
+		if err != nil || node <= 0 {
+			writeError(w, 400, "validation_error", "node query parameter required")
+			return
+		}
+		found, err := a.LocalNodes.Delete(ctx, node)
+		if err != nil {
+			writeError(w, 500, "db_error", "failed to delete local node")
+			return
+		}
+		if !found {
+			writeError(w, 404, "not_found", "node is not in the local registry")
+			return
+		}
+		if !a.reloadLocalNodes(ctx) {
+			writeError(w, 500, "db_error", "deleted but failed to reload local nodes")
+			return
+		}
+		writeJSON(w, 200, map[string]any{"node": node, "deleted": true})
+	default:
+		writeError(w, 405, "method_not_allowed", "only GET, POST and DELETE supported")
+	}
+}
+
+// reloadLocalNodes pushes the stored entries into the node lookup service, if any.
+func (a *API) reloadLocalNodes(ctx context.Context) bool {
+	if a.Lookup == nil {
+		return true
+	}
+	list, err := a.LocalNodes.List(ctx)
+	if err != nil {
+		return false
+	}
+	infos := make([]core.NodeInfo, 0, len(list))
+	for _, n := range list {
+		infos = append(infos, core.NodeInfo{Node: n.Node, Callsign: n.Callsign, Description: n.Description, Location: n.Location})
+	}
+	a.Lookup.SetLocalNodes(infos)
+	return true
+}
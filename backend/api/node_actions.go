@@ -0,0 +1,244 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dbehnke/allstar-nexus/backend/models"
+	"github.com/dbehnke/allstar-nexus/backend/repository"
+	"github.com/dbehnke/allstar-nexus/internal/core"
+)
+
+// SetNodeActions enables node claims and, with a relay, the user node actions. relay is
+// nil without an AMI connection: claims can still be made and reviewed.
+func (a *API) SetNodeActions(claims *repository.NodeClaimRepo, relay *core.NodeActionRelay) {
+	a.NodeClaims = claims
+	a.NodeActions = relay
+}
+
+// MyNodeClaims lists the signed-in user's node claims (GET), claims a node (POST) or
+// withdraws a claim (DELETE ?id=). The claimed callsign must match the node's astdb
+// callsign; an admin then approves the claim.
+// Endpoint: /api/me/node-claims
+// POST body: {"node": 2001, "callsign": "W1AW"}
+func (a *API) MyNodeClaims(w http.ResponseWriter, r *http.Request) {
+	if a.NodeClaims == nil {
+		writeError(w, 503, "unavailable", "node actions not enabled")
+		return
+	}
+	u, status := a.currentUser(r)
+	if status != 200 {
+		writeError(w, status, "unauthorized", http.StatusText(status))
+		return
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	switch r.Method {
+	case http.MethodGet:
+		claims, err := a.NodeClaims.ForUser(ctx, u.ID)
+		if err != nil {
+			writeError(w, 500, "db_error", "failed to load node claims")
+			return
+		}
+		if claims == nil {
+			claims = []models.NodeClaim{}
+		}
+		var actions []string
+		if a.NodeActions != nil {
+			actions = a.NodeActions.Actions()
+		}
+		writeJSON(w, 200, map[string]any{"claims": claims, "actions": actions})
+	case http.MethodPost:
+		var body struct {
+			Node     int    `json:"node"`
+			Callsign string `json:"callsign"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			writeError(w, 400, "bad_request", "invalid json body")
+			return
+		}
+		callsign := strings.ToUpper(strings.TrimSpace(body.Callsign))
+		if body.Node <= 0 || callsign == "" || len(callsign) > 32 {
+			writeError(w, 400, "validation_error", "node and callsign are required")
+			return
+		}
+		var info *core.NodeInfo
+		if a.Lookup != nil {
+			info = a.Lookup.LookupNode(body.Node)
+		}
+		if info == nil {
+			writeError(w, 404, "unknown_node", "node not found in astdb")
+			return
+		}
+		if !strings.EqualFold(strings.TrimSpace(info.Callsign), callsign) {
+			writeError(w, 422, "callsign_mismatch", "callsign does not match the node's registration")
+			return
+		}
+		claim, err := a.NodeClaims.Create(ctx, u.ID, body.Node, callsign)
+		if errors.Is(err, repository.ErrClaimExists) {
+			writeError(w, 409, "claim_exists", "you have already claimed this node")
+			return
+		}
+		if err != nil {
+			writeError(w, 500, "db_error", "failed to save node claim")
+			return
+		}
+		writeJSON(w, 200, claim)
+	case http.MethodDelete:
+		id, err := strconv.ParseUint(r.URL.Query().Get("id"), 10, 0)
+		if err != nil || id == 0 {
+			writeError(w, 400, "validation_error", "id query parameter required")
+			return
+		}
+		found, err := a.NodeClaims.Delete(ctx, uint(id), u.ID)
+		if err != nil {
+			writeError(w, 500, "db_error", "failed to delete node claim")
+			return
+		}
+		if !found {
+			writeError(w, 404, "not_found", "no such claim")
+			return
+		}
+		writeJSON(w, 200, map[string]any{"id": id, "deleted": true})
+	default:
+		writeError(w, 405, "method_not_allowed", "only GET, POST and DELETE supported")
+	}
+}
+
+// MyNodeAction runs a whitelisted action on a node the signed-in user has an approved
+// claim to, e.g. disconnecting it from the hub.
+// Endpoint: POST /api/me/nodes/{id}/actions
+// POST body: {"action": "disconnect"}
+func (a *API) MyNodeAction(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, 405, "method_not_allowed", "only POST supported")
+		return
+	}
+	if a.NodeClaims == nil || a.NodeActions == nil {
+		writeError(w, 503, "unavailable", "node actions need an AMI connection")
+		return
+	}
+	u, status := a.currentUser(r)
+	if status != 200 {
+		writeError(w, status, "unauthorized", http.StatusText(status))
+		return
+	}
+	node, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil || node <= 0 {
+		writeError(w, 400, "validation_error", "invalid node number")
+		return
+	}
+	var body struct {
+		Action string `json:"action"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, 400, "bad_request", "invalid json body")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 15*time.Second)
+	defer cancel()
+	owned, err := a.NodeClaims.Approved(ctx, u.ID, node)
+	if err != nil {
+		writeError(w, 500, "db_error", "failed to check node claim")
+		return
+	}
+	if !owned {
+		a.NodeActions.Deny(u.Email, r.RemoteAddr, node, body.Action, errors.New("no approved claim"))
+		writeError(w, 403, "forbidden", "you do not have an approved claim to this node")
+		return
+	}
+	local, err := a.NodeActions.Run(ctx, u.Email, r.RemoteAddr, node, body.Action)
+	switch {
+	case errors.Is(err, core.ErrNodeActionNotAllowed):
+		writeError(w, 403, "action_not_allowed", err.Error())
+		return
+	case errors.Is(err, core.ErrNodeActionState):
+		writeError(w, 409, "action_not_applicable", err.Error())
+		return
+	case err != nil:
+		writeError(w, 502, "ami_error", err.Error())
+		return
+	}
+	if a.TriggerPoll != nil {
+		a.TriggerPoll(local)
+	}
+	writeJSON(w, 200, map[string]any{"node": node, "action": body.Action, "hub_node": local})
+}
+
+// AdminNodeClaims lists claims (GET ?status=pending), approves or rejects one (PUT) or
+// revokes one (DELETE ?id=).
+// Endpoint: /api/admin/node-claims
+// PUT body: {"id": 3, "status": "approved"}
+func (a *API) AdminNodeClaims(w http.ResponseWriter, r *http.Request) {
+	if a.NodeClaims == nil {
+		writeError(w, 503, "unavailable", "node actions not enabled")
+		return
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+	actor := ""
+	if u, status := a.currentUser(r); status == 200 {
+		actor = u.Email
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		claims, err := a.NodeClaims.List(ctx, r.URL.Query().Get("status"))
+		if err != nil {
+			writeError(w, 500, "db_error", "failed to load node claims")
+			return
+		}
+		if claims == nil {
+			claims = []models.NodeClaim{}
+		}
+		writeJSON(w, 200, map[string]any{"claims": claims})
+	case http.MethodPut:
+		var body struct {
+			ID     uint   `json:"id"`
+			Status string `json:"status"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			writeError(w, 400, "bad_request", "invalid json body")
+			return
+		}
+		if body.ID == 0 || (body.Status != models.NodeClaimApproved && body.Status != models.NodeClaimRejected) {
+			writeError(w, 400, "validation_error", "id and a status of approved or rejected are required")
+			return
+		}
+		found, err := a.NodeClaims.Review(ctx, body.ID, body.Status, actor)
+		if err != nil {
+			writeError(w, 500, "db_error", "failed to update node claim")
+			return
+		}
+		if !found {
+			writeError(w, 404, "not_found", "no such claim")
+			return
+		}
+		writeJSON(w, 200, body)
+	case http.MethodDelete:
+		id, err := strconv.ParseUint(r.URL.Query().Get("id"), 10, 0)
+		if err != nil || id == 0 {
+			writeError(w, 400, "validation_error", "id query parameter required")
+			return
+		}
+		found, err := a.NodeClaims.Delete(ctx, uint(id), 0)
+		if err != nil {
+			writeError(w, 500, "db_error", "failed to delete node claim")
+			return
+		}
+		if !found {
+			writeError(w, 404, "not_found", "no such claim")
+			return
+		}
+		writeJSON(w, 200, map[string]any{"id": id, "deleted": true})
+	default:
+		writeError(w, 405, "method_not_allowed", "only GET, PUT and DELETE supported")
+	}
+}
@@ -0,0 +1,87 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dbehnke/allstar-nexus/backend/repository"
+	"github.com/dbehnke/allstar-nexus/internal/privacy"
+)
+
+// SetNodeHealth enables the node health history endpoint
+func (a *API) SetNodeHealth(repo *repository.NodeHealthRepo) {
+	a.NodeHealth = repo
+}
+
+// NodeHealthHandler returns recorded reachability of linked nodes. Without ?node= it
+// summarises every node probed in the period (worst loss first); with ?node= it lists
+// that node's probe rounds. ?since= takes a relative duration (-24h, the default) or
+// an RFC3339 time. Addresses are masked for non-admins like link IPs.
+// Endpoint: GET /api/node-health
+func (a *API) NodeHealthHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, 405, "method_not_allowed", "only GET supported")
+		return
+	}
+	if a.NodeHealth == nil {
+		writeError(w, 503, "node_health_unavailable", "node_health probing is not enabled")
+		return
+	}
+	q := r.URL.Query()
+	since, ok := parseSince(q.Get("since"), 24*time.Hour)
+	if !ok {
+		writeError(w, 400, "invalid_since", "since must be a relative duration like -24h or an RFC3339 time")
+		return
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	if s := q.Get("node"); s != "" {
+		node, err := strconv.Atoi(s)
+		if err != nil || node <= 0 {
+			writeError(w, 400, "invalid_node", "node must be a positive integer")
+			return
+		}
+		rows, err := a.NodeHealth.History(ctx, node, since)
+		if err != nil {
+			writeError(w, 500, "db_error", "failed to load node health")
+			return
+		}
+		if v := a.viewer(r); v != privacy.ViewerAdmin {
+			for i := range rows {
+				rows[i].IP = a.Privacy.MaskIP(rows[i].IP, v)
+			}
+		}
+		writeJSON(w, 200, map[string]any{"node": node, "since": since.UTC(), "rounds": rows})
+		return
+	}
+	summary, err := a.NodeHealth.Summary(ctx, since)
+	if err != nil {
+		writeError(w, 500, "db_error", "failed to load node health")
+		return
+	}
+	writeJSON(w, 200, map[string]any{"since": since.UTC(), "nodes": summary})
+}
+
+// parseSince reads a ?since= value: a relative duration (-24h) or an RFC3339 time.
+// Empty selects def ago.
+func parseSince(s string, def time.Duration) (time.Time, bool) {
+	if s == "" {
+		return time.Now().Add(-def), true
+	}
+	if strings.HasPrefix(s, "-") {
+		d, err := time.ParseDuration(strings.TrimPrefix(s, "-"))
+		if err != nil {
+			return time.Time{}, false
+		}
+		return time.Now().Add(-d), true
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return t, true
+}
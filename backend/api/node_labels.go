@@ -0,0 +1,131 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dbehnke/allstar-nexus/backend/models"
+	"github.com/dbehnke/allstar-nexus/backend/repository"
+	"github.com/dbehnke/allstar-nexus/internal/core"
+)
+
+// linkRelabeler is implemented by state managers that can look connected links up again.
+type linkRelabeler interface {
+	ReenrichLinks() int
+}
+
+// SetNodeLabels enables the node label override endpoint and loads the stored labels
+// into lookup, where they replace astdb descriptions.
+func (a *API) SetNodeLabels(repo *repository.NodeLabelRepo, lookup *core.NodeLookupService) {
+	a.NodeLabels = repo
+	a.Lookup = lookup
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if !a.reloadNodeLabels(ctx) {
+		log.Printf("[NODE LABELS] failed to load node labels")
+	}
+}
+
+// AdminNodeLabels lists (GET), sets (POST) and removes (DELETE ?node=) display-name
+// overrides that take precedence over the astdb description. Connected links are
+// relabeled right away.
+// Endpoint: /api/admin/node-labels
+// POST body: {"node": 2000, "label": "Newington hub"}
+func (a *API) AdminNodeLabels(w http.ResponseWriter, r *http.Request) {
+	if a.NodeLabels == nil {
+		writeError(w, 503, "unavailable", "node labels not configured")
+		return
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	switch r.Method {
+	case http.MethodGet:
+		list, err := a.NodeLabels.List(ctx)
+		if err != nil {
+			writeError(w, 500, "db_error", "failed to load node labels")
+			return
+		}
+		writeJSON(w, 200, map[string]any{"labels": list})
+	case http.MethodPost:
+		var body struct {
+			Node  int    `json:"node"`
+			Label string `json:"label"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			writeError(w, 400, "bad_request", "invalid json body")
+			return
+		}
+		body.Label = strings.TrimSpace(body.Label)
+		if body.Node <= 0 {
+			writeError(w, 400, "validation_error", "node must be a positive node number")
+			return
+		}
+		if body.Label == "" || len(body.Label) > 255 {
+			writeError(w, 400, "validation_error", "label required (max 255 characters)")
+			return
+		}
+		by := ""
+		if u, status := a.currentUser(r); status == 200 {
+			by = u.Email
+		}
+		entry := models.NodeLabel{Node: body.Node, Label: body.Label, UpdatedBy: by}
+		if err := a.NodeLabels.Save(ctx, entry); err != nil {
+			writeError(w, 500, "db_error", "failed to save node label")
+			return
+		}
+		if !a.reloadNodeLabels(ctx) {
+			writeError(w, 500, "db_error", "saved but failed to reload node labels")
+			return
+		}
+		writeJSON(w, 200, entry)
+	case http.MethodDelete:
+		node, err := strconv.Atoi(r.URL.Query().Get("node"))
+		if err != nil || node <= 0 {
+			writeError(w, 400, "validation_error", "node query parameter required")
+			return
+		}
+		found, err := a.NodeLabels.Delete(ctx, node)
+		if err != nil {
+			writeError(w, 500, "db_error", "failed to delete node label")
+			return
+		}
+		if !found {
+			writeError(w, 404, "not_found", "node has no label")
+			return
+		}
+		if !a.reloadNodeLabels(ctx) {
+			writeError(w, 500, "db_error", "deleted but failed to reload node labels")
+			return
+		}
+		writeJSON(w, 200, map[string]any{"node": node, "deleted": true})
+	default:
+		writeError(w, 405, "method_not_allowed", "only GET, POST and DELETE supported")
+	}
+}
+
+// reloadNodeLabels pushes the stored labels into the node lookup service, if any, and
+// relabels connected links.
+func (a *API) reloadNodeLabels(ctx context.Context) bool {
+	if a.Lookup == nil {
+		return true
+	}
+	list, err := a.NodeLabels.List(ctx)
+	if err != nil {
+		return false
+	}
+	labels := make(map[int]string, len(list))
+	for _, l := range list {
+		labels[l.Node] = l.Label
+	}
+	a.Lookup.SetLabels(labels)
+	if sm, ok := a.StateManager.(linkRelabeler); ok {
+		sm.ReenrichLinks()
+	}
+	return true
+}
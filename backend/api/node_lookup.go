@@ -7,6 +7,7 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/dbehnke/allstar-nexus/internal/astdb"
 	"github.com/dbehnke/allstar-nexus/internal/core"
 )
 
@@ -46,6 +47,34 @@ func (a *API) NodeLookup(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// AstDBFile serves the cached astdb.txt so other LAN tools can fetch it from Nexus
+// instead of the AllStar server. Conditional requests (If-None-Match using the
+// upstream ETag, If-Modified-Since) and ranges are honoured.
+// Endpoint: GET /astdb.txt
+func (a *API) AstDBFile(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		writeError(w, 405, "method_not_allowed", "only GET supported")
+		return
+	}
+	file, err := os.Open(a.AstDBPath)
+	if err != nil {
+		writeError(w, 404, "not_found", "astdb has not been downloaded yet")
+		return
+	}
+	defer func() { _ = file.Close() }()
+	info, err := file.Stat()
+	if err != nil {
+		writeError(w, 500, "file_error", "unable to read astdb.txt")
+		return
+	}
+	if meta, ok := astdb.ReadMeta(a.AstDBPath); ok && meta.ETag != "" {
+		w.Header().Set("ETag", meta.ETag)
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-cache")
+	http.ServeContent(w, r, "astdb.txt", info.ModTime(), file)
+}
+
 // searchAstDB reads the astdb.txt file and returns matching nodes.
 // The astdb.txt file format is typically: node|callsign|description|location
 // If query is not numeric and not found in database, returns the query as a callsign
@@ -56,7 +85,15 @@ func (a *API) searchAstDB(query string) ([]NodeRecord, error) {
 	}
 	defer func() { _ = file.Close() }()
 
+	// Private nodes first; they shadow astdb entries with the same number
 	var results []NodeRecord
+	local := make(map[int]bool)
+	if a.Lookup != nil {
+		for _, n := range a.Lookup.SearchLocal(query) {
+			results = append(results, NodeRecord{Node: n.Node, Callsign: n.Callsign, Description: n.Description, Location: n.Location})
+			local[n.Node] = true
+		}
+	}
 	scanner := bufio.NewScanner(file)
 	queryLower := strings.ToLower(query)
 
@@ -91,6 +128,9 @@ func (a *API) searchAstDB(query string) ([]NodeRecord, error) {
 		}
 
 		// Match against node number or callsign
+		if local[nodeNum] {
+			continue
+		}
 		if strings.Contains(nodeStr, query) ||
 			strings.Contains(strings.ToLower(callsign), queryLower) ||
 			strings.Contains(strings.ToLower(description), queryLower) ||
@@ -152,9 +192,15 @@ func (a *API) searchAstDB(query string) ([]NodeRecord, error) {
 	return results, nil
 }
 
-// LookupNodeByID performs a fast lookup of a single node by ID.
+// LookupNodeByID performs a fast lookup of a single node by ID, checking the private
+// node registry before astdb.
 // Returns nil if not found.
 func (a *API) LookupNodeByID(nodeID int) *NodeRecord {
+	if a.Lookup != nil {
+		if n, ok := a.Lookup.LocalNode(nodeID); ok {
+			return &NodeRecord{Node: n.Node, Callsign: n.Callsign, Description: n.Description, Location: n.Location}
+		}
+	}
 	file, err := os.Open(a.AstDBPath)
 	if err != nil {
 		return nil
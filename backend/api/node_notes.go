@@ -0,0 +1,133 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/dbehnke/allstar-nexus/backend/models"
+	"github.com/dbehnke/allstar-nexus/backend/repository"
+)
+
+// nodeAnnotator is implemented by state managers that copy annotations onto live links.
+type nodeAnnotator interface {
+	SetNodeAnnotation(node int, tags []string, notes string)
+}
+
+// SetNodeAnnotations enables the node notes/tags admin endpoints
+func (a *API) SetNodeAnnotations(repo *repository.NodeAnnotationRepo) {
+	a.NodeAnnotations = repo
+}
+
+// nodeAnnotationView is the JSON form of an annotation (tags as a list).
+type nodeAnnotationView struct {
+	Node      int        `json:"node"`
+	Notes     string     `json:"notes"`
+	Tags      []string   `json:"tags"`
+	UpdatedBy string     `json:"updated_by,omitempty"`
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+}
+
+func annotationView(node int, m *models.NodeAnnotation) nodeAnnotationView {
+	if m == nil {
+		return nodeAnnotationView{Node: node, Tags: []string{}}
+	}
+	at := m.UpdatedAt
+	return nodeAnnotationView{Node: m.Node, Notes: m.Notes, Tags: m.TagList(), UpdatedBy: m.UpdatedBy, UpdatedAt: &at}
+}
+
+// NodeNotes reads (GET), replaces (PUT/POST) or clears (DELETE) the admin notes and
+// tags for one node. Text nodes use their negative IDs.
+// Endpoint: /api/admin/nodes/{id}/notes
+// PUT body: {"notes": "Intermittent audio", "tags": ["problem node", "mobile"]}
+func (a *API) NodeNotes(w http.ResponseWriter, r *http.Request) {
+	if a.NodeAnnotations == nil {
+		writeError(w, 503, "unavailable", "node notes not configured")
+		return
+	}
+	node, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil || node == 0 {
+		writeError(w, 400, "validation_error", "invalid node id")
+		return
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	switch r.Method {
+	case http.MethodGet:
+		m, err := a.NodeAnnotations.Get(ctx, node)
+		if err != nil {
+			writeError(w, 500, "db_error", "failed to load node notes")
+			return
+		}
+		writeJSON(w, 200, annotationView(node, m))
+	case http.MethodPut, http.MethodPost:
+		var body struct {
+			Notes string   `json:"notes"`
+			Tags  []string `json:"tags"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			writeError(w, 400, "bad_request", "invalid json body")
+			return
+		}
+		by := ""
+		if u, status := a.currentUser(r); status == 200 {
+			by = u.Email
+		}
+		m, err := a.NodeAnnotations.Save(ctx, node, body.Notes, body.Tags, by)
+		if errors.Is(err, repository.ErrInvalidAnnotation) {
+			writeError(w, 400, "validation_error", err.Error())
+			return
+		}
+		if err != nil {
+			writeError(w, 500, "db_error", "failed to save node notes")
+			return
+		}
+		view := annotationView(node, m)
+		a.publishAnnotation(view)
+		writeJSON(w, 200, view)
+	case http.MethodDelete:
+		if err := a.NodeAnnotations.Delete(ctx, node); err != nil {
+			writeError(w, 500, "db_error", "failed to delete node notes")
+			return
+		}
+		a.publishAnnotation(annotationView(node, nil))
+		writeJSON(w, 200, map[string]any{"node": node, "deleted": true})
+	default:
+		writeError(w, 405, "method_not_allowed", "only GET, PUT and DELETE supported")
+	}
+}
+
+// NodeNotesList returns every annotated node, optionally filtered by ?tag=.
+// Endpoint: GET /api/admin/nodes/notes
+func (a *API) NodeNotesList(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, 405, "method_not_allowed", "only GET supported")
+		return
+	}
+	if a.NodeAnnotations == nil {
+		writeError(w, 503, "unavailable", "node notes not configured")
+		return
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+	list, err := a.NodeAnnotations.List(ctx, r.URL.Query().Get("tag"))
+	if err != nil {
+		writeError(w, 500, "db_error", "failed to list node notes")
+		return
+	}
+	out := make([]nodeAnnotationView, 0, len(list))
+	for i := range list {
+		out = append(out, annotationView(list[i].Node, &list[i]))
+	}
+	writeJSON(w, 200, map[string]any{"nodes": out})
+}
+
+func (a *API) publishAnnotation(v nodeAnnotationView) {
+	if sm, ok := a.StateManager.(nodeAnnotator); ok {
+		sm.SetNodeAnnotation(v.Node, v.Tags, v.Notes)
+	}
+}
@@ -0,0 +1,72 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/dbehnke/allstar-nexus/internal/core"
+)
+
+// SetParrotController enables the parrot (audio test) mode admin endpoint
+func (a *API) SetParrotController(pc *core.ParrotController, defaultDuration time.Duration) {
+	a.Parrot = pc
+	a.ParrotDefault = defaultDuration
+}
+
+// ParrotMode reports (GET) or changes (POST) parrot mode on a source node.
+// Endpoint: /api/admin/parrot
+// POST body: {"node": 43732, "enabled": true, "duration_seconds": 120}
+func (a *API) ParrotMode(w http.ResponseWriter, r *http.Request) {
+	if a.Parrot == nil {
+		writeError(w, 503, "parrot_unavailable", "parrot mode requires an AMI connection")
+		return
+	}
+	switch r.Method {
+	case http.MethodGet:
+		writeJSON(w, 200, map[string]any{"modes": a.Parrot.Status(), "max_seconds": int(a.Parrot.MaxDuration().Seconds())})
+	case http.MethodPost:
+		var body struct {
+			Node            int   `json:"node"`
+			Enabled         *bool `json:"enabled"`
+			DurationSeconds int   `json:"duration_seconds"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			writeError(w, 400, "bad_request", "invalid json body")
+			return
+		}
+		if body.Node <= 0 {
+			writeError(w, 400, "validation_error", "node is required")
+			return
+		}
+		enable := body.Enabled == nil || *body.Enabled
+		ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+		defer cancel()
+		if !enable {
+			st, err := a.Parrot.Disable(ctx, body.Node)
+			if err != nil {
+				writeError(w, 502, "ami_error", err.Error())
+				return
+			}
+			writeJSON(w, 200, st)
+			return
+		}
+		dur := a.ParrotDefault
+		if body.DurationSeconds > 0 {
+			dur = time.Duration(body.DurationSeconds) * time.Second
+		}
+		by := ""
+		if u, status := a.currentUser(r); status == 200 {
+			by = u.Email
+		}
+		st, err := a.Parrot.Enable(ctx, body.Node, dur, by)
+		if err != nil {
+			writeError(w, 400, "parrot_failed", err.Error())
+			return
+		}
+		writeJSON(w, 200, st)
+	default:
+		writeError(w, 405, "method_not_allowed", "only GET and POST supported")
+	}
+}
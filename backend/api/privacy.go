@@ -0,0 +1,24 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/dbehnke/allstar-nexus/internal/privacy"
+)
+
+// SetPrivacyPolicy sets the masking policy applied to non-admin REST responses
+func (a *API) SetPrivacyPolicy(p privacy.Policy) {
+	a.Privacy = p
+}
+
+// viewer classifies the caller for the privacy policy (anonymous, signed-in user or admin).
+func (a *API) viewer(r *http.Request) privacy.Viewer {
+	u, status := a.currentUser(r)
+	if status != 200 {
+		return privacy.ViewerAnonymous
+	}
+	if a.isAdmin(r, u.Email, u.Role) {
+		return privacy.ViewerAdmin
+	}
+	return privacy.ViewerUser
+}
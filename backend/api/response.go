@@ -34,6 +34,9 @@ func writeError(w http.ResponseWriter, status int, code, msg string) {
 	}
 }
 
+// ValidatePassword applies the registration password rules (used by the setup wizard).
+func ValidatePassword(pw string) error { return validatePassword(pw) }
+
 // validatePassword enforces minimal password rules.
 func validatePassword(pw string) error {
 	if len(pw) < 8 {
@@ -0,0 +1,86 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/dbehnke/allstar-nexus/internal/core"
+	"github.com/dbehnke/allstar-nexus/internal/rptconf"
+)
+
+// SetRptConfigEditor enables the per-node telemetry settings endpoint.
+func (a *API) SetRptConfigEditor(e *core.RptConfigEditor) {
+	a.RptConfig = e
+}
+
+// NodeTelemetry reads (GET) or changes (PUT) a local node's courtesy tone, ID and
+// timer settings in rpt.conf. Changes are followed by an app_rpt reload. Tenant admins
+// may edit their own hub's nodes.
+// Endpoint: /api/admin/nodes/{id}/telemetry
+// PUT body: {"settings": {"hangtime": "1500", "unlinkedct": "ct2", "politeid": ""}}
+// An empty value removes the node's own setting so the template or app_rpt default applies.
+func (a *API) NodeTelemetry(w http.ResponseWriter, r *http.Request) {
+	if a.RptConfig == nil {
+		writeError(w, 503, "unavailable", "rpt.conf editing not enabled")
+		return
+	}
+	node, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil || node <= 0 {
+		writeError(w, 400, "validation_error", "invalid node id")
+		return
+	}
+	if !tenantHasNode(r, node) {
+		writeError(w, 404, "not_found", "node not found")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPut, http.MethodPost:
+		var body struct {
+			Settings map[string]string `json:"settings"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil || len(body.Settings) == 0 {
+			writeError(w, 400, "bad_request", "body must be {\"settings\": {key: value}}")
+			return
+		}
+		actor := ""
+		if u, status := a.currentUser(r); status == 200 {
+			actor = u.Email
+		}
+		err := a.RptConfig.Set(r.Context(), actor, node, body.Settings)
+		switch {
+		case errors.Is(err, rptconf.ErrInvalidSetting):
+			writeError(w, 400, "validation_error", err.Error())
+			return
+		case errors.Is(err, rptconf.ErrNodeNotFound):
+			writeError(w, 404, "not_found", err.Error())
+			return
+		case errors.Is(err, core.ErrRptReload):
+			writeError(w, 502, "reload_failed", err.Error())
+			return
+		case err != nil:
+			writeError(w, 500, "write_error", "failed to update rpt.conf")
+			return
+		}
+	default:
+		writeError(w, 405, "method_not_allowed", "only GET and PUT supported")
+		return
+	}
+
+	settings, tones, err := a.RptConfig.Get(node)
+	if errors.Is(err, rptconf.ErrNodeNotFound) {
+		writeError(w, 404, "not_found", err.Error())
+		return
+	}
+	if err != nil {
+		writeError(w, 500, "read_error", "failed to read rpt.conf")
+		return
+	}
+	if tones == nil {
+		tones = []string{}
+	}
+	writeJSON(w, 200, map[string]any{"node": node, "settings": settings, "tones": tones})
+}
@@ -0,0 +1,95 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/dbehnke/allstar-nexus/backend/gamification"
+	"github.com/dbehnke/allstar-nexus/backend/repository"
+)
+
+// SetTallyRuns enables the tally run history endpoint; run, if set, triggers an
+// immediate tally (normally TallyService.RunNow).
+func (a *API) SetTallyRuns(repo *repository.TallyRunRepo, run func() (gamification.TallySummary, error)) {
+	a.TallyRuns = repo
+	a.RunTally = run
+}
+
+// AdminTallyRuns lists recent tally runs, newest first.
+// Endpoint: /api/admin/gamification/tally-runs?limit=50&failed=1
+func (a *API) AdminTallyRuns(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, 405, "method_not_allowed", "only GET supported")
+		return
+	}
+	if a.TallyRuns == nil {
+		writeError(w, 503, "unavailable", "gamification not enabled")
+		return
+	}
+	limit := 50
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			writeError(w, 400, "invalid_limit", "limit must be a positive integer")
+			return
+		}
+		limit = min(n, 500)
+	}
+	failed := r.URL.Query().Get("failed")
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+	runs, err := a.TallyRuns.Recent(ctx, limit, failed == "1" || failed == "true")
+	if err != nil {
+		writeError(w, 500, "db_error", err.Error())
+		return
+	}
+	writeJSON(w, 200, map[string]any{"runs": runs})
+}
+
+// SetTallyProgress exposes the running tally's progress (normally
+// TallyService.Progress).
+func (a *API) SetTallyProgress(progress func() gamification.TallyProgress) {
+	a.TallyProgress = progress
+}
+
+// AdminTallyProgress reports how far the current or last tally has got, e.g. while
+// a backlog is caught up in the background after downtime.
+// Endpoint: /api/admin/gamification/tally-progress
+func (a *API) AdminTallyProgress(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, 405, "method_not_allowed", "only GET supported")
+		return
+	}
+	if a.TallyProgress == nil {
+		writeError(w, 503, "unavailable", "gamification not enabled")
+		return
+	}
+	writeJSON(w, 200, a.TallyProgress())
+}
+
+// AdminTallyNow runs a tally immediately and returns its summary. It answers 409
+// if a tally is already in progress.
+// Endpoint: POST /api/admin/gamification/tally-now
+func (a *API) AdminTallyNow(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, 405, "method_not_allowed", "only POST supported")
+		return
+	}
+	if a.RunTally == nil {
+		writeError(w, 503, "unavailable", "gamification not enabled")
+		return
+	}
+	summary, err := a.RunTally()
+	if errors.Is(err, gamification.ErrTallyRunning) {
+		writeError(w, 409, "tally_running", err.Error())
+		return
+	}
+	if err != nil {
+		writeError(w, 500, "tally_failed", err.Error())
+		return
+	}
+	writeJSON(w, 200, map[string]any{"summary": summary})
+}
@@ -0,0 +1,55 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// TelegramLinker issues one-time codes that link a Telegram account to a dashboard
+// user (implemented by telegrambot.Bot).
+type TelegramLinker interface {
+	NewLinkCode(userID int64) (string, time.Time)
+}
+
+// SetTelegramLinker enables linking Telegram accounts for bot admin commands.
+func (a *API) SetTelegramLinker(l TelegramLinker) {
+	a.TelegramLinker = l
+}
+
+// TelegramLink reports whether the caller's Telegram account is linked (GET), issues a
+// code to send to the bot as "/link CODE" (POST) or unlinks the account (DELETE).
+// Endpoint: /api/admin/telegram/link
+func (a *API) TelegramLink(w http.ResponseWriter, r *http.Request) {
+	if a.TelegramLinker == nil {
+		writeError(w, 503, "unavailable", "Telegram bot not enabled")
+		return
+	}
+	u, status := a.currentUser(r)
+	if status != 200 {
+		writeError(w, status, "unauthorized", "authentication required")
+		return
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+	switch r.Method {
+	case http.MethodGet:
+		usr, err := a.Users.GetByEmail(ctx, u.Email)
+		if err != nil || usr == nil {
+			writeError(w, 500, "db_error", "failed to load user")
+			return
+		}
+		writeJSON(w, 200, map[string]any{"linked": usr.TelegramID != nil})
+	case http.MethodPost:
+		code, expires := a.TelegramLinker.NewLinkCode(u.ID)
+		writeJSON(w, 200, map[string]any{"code": code, "expires_at": expires.UTC(), "command": "/link " + code})
+	case http.MethodDelete:
+		if err := a.Users.SetTelegramID(ctx, u.ID, nil); err != nil {
+			writeError(w, 500, "db_error", "failed to unlink Telegram account")
+			return
+		}
+		writeJSON(w, 200, map[string]any{"linked": false})
+	default:
+		writeError(w, 405, "method_not_allowed", "only GET, POST and DELETE supported")
+	}
+}
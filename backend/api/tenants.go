@@ -0,0 +1,80 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/dbehnke/allstar-nexus/backend/models"
+	"github.com/dbehnke/allstar-nexus/internal/core"
+	"github.com/dbehnke/allstar-nexus/internal/tenant"
+)
+
+// SetTenants enables multi-tenant hub hosting: responses for requests resolved to a
+// tenant are narrowed to its nodes, and its admins are recognised.
+func (a *API) SetTenants(reg *tenant.Registry) {
+	a.Tenants = reg
+}
+
+// TenantList lists the hosted hubs (public, for a landing page).
+// Endpoint: GET /api/tenants
+func (a *API) TenantList(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, 405, "method_not_allowed", "only GET supported")
+		return
+	}
+	type view struct {
+		ID    string `json:"id"`
+		Name  string `json:"name"`
+		Path  string `json:"path"`
+		Nodes []int  `json:"nodes"`
+	}
+	out := []view{}
+	for _, t := range a.Tenants.Tenants() {
+		out = append(out, view{ID: t.ID, Name: t.Name, Path: tenant.PathPrefix + t.ID + "/", Nodes: t.Nodes})
+	}
+	writeJSON(w, 200, map[string]any{"tenants": out})
+}
+
+// isAdmin reports whether the user administers the request's tenant (or, for unscoped
+// requests, the instance).
+func (a *API) isAdmin(r *http.Request, email, role string) bool {
+	return a.Tenants.IsAdmin(tenant.FromContext(r.Context()), email, role)
+}
+
+// scopeLinkStats keeps persisted link stats for links on the request's tenant's nodes.
+func (a *API) scopeLinkStats(r *http.Request, stats []models.LinkStat) []models.LinkStat {
+	t := tenant.FromContext(r.Context())
+	if t == nil {
+		return stats
+	}
+	primary := 0
+	if a.StateManager != nil {
+		primary = a.StateManager.Snapshot().NodeID
+	}
+	out := make([]models.LinkStat, 0, len(stats))
+	for _, s := range stats {
+		local := s.LocalNode
+		if local == 0 {
+			local = primary
+		}
+		if t.HasNode(local) {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// snapshot returns the live node state narrowed to the request's tenant.
+func (a *API) snapshot(r *http.Request) core.NodeState {
+	return tenant.FromContext(r.Context()).NodeState(a.StateManager.Snapshot())
+}
+
+// talkerLog returns the talker log narrowed to the request's tenant.
+func (a *API) talkerLog(r *http.Request) any {
+	return tenant.FromContext(r.Context()).TalkerLog(a.StateManager.TalkerLogSnapshot())
+}
+
+// tenantHasNode reports whether node belongs to the request's tenant (always true for
+// unscoped requests).
+func tenantHasNode(r *http.Request, node int) bool {
+	return tenant.FromContext(r.Context()).HasNode(node)
+}
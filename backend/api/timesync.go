@@ -0,0 +1,41 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/dbehnke/allstar-nexus/backend/repository"
+	"github.com/dbehnke/allstar-nexus/internal/timesync"
+)
+
+// SetTimeSync exposes the clock sanity checker and tally skew annotations via the API
+func (a *API) SetTimeSync(c *timesync.Checker, tallyState *repository.TallyStateRepo) {
+	a.TimeSync = c
+	a.TallyState = tallyState
+}
+
+// TimeSyncStatus reports the last clock sanity check and recent tally runs annotated for skew.
+// Endpoint: /api/admin/time-sync
+func (a *API) TimeSyncStatus(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, 405, "method_not_allowed", "only GET supported")
+		return
+	}
+	if a.TimeSync == nil {
+		writeJSON(w, 200, map[string]any{"enabled": false})
+		return
+	}
+	resp := map[string]any{"enabled": true, "max_skew_seconds": a.TimeSync.MaxSkew.Seconds()}
+	if last, ok := a.TimeSync.Last(); ok {
+		resp["last_check"] = last
+	}
+	if a.TallyState != nil {
+		ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+		defer cancel()
+		if annotations, err := a.TallyState.ListSkewAnnotations(ctx, 50); err == nil {
+			resp["skewed_tallies"] = annotations
+		}
+	}
+	writeJSON(w, 200, resp)
+}
@@ -3,12 +3,13 @@ package api
 import (
 	"context"
 	"fmt"
+	"net"
 	"net/http"
 	"strconv"
 	"strings"
 	"time"
 
-	"github.com/dbehnke/allstar-nexus/backend/models"
+	"github.com/dbehnke/allstar-nexus/internal/privacy"
 )
 
 // VoterReceiver represents a single receiver in the RTCM voter system
@@ -31,13 +32,8 @@ func (a *API) VoterStats(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Determine if caller is admin to decide masking behavior
-	isAdmin := false
-	if u, status := a.currentUser(r); status == 200 {
-		if u.Role == models.RoleAdmin || u.Role == models.RoleSuperAdmin {
-			isAdmin = true
-		}
-	}
+	// Classify caller to decide masking behavior
+	viewer := a.viewer(r)
 
 	nodeStr := strings.TrimSpace(r.URL.Query().Get("node"))
 	if nodeStr == "" {
@@ -92,13 +88,12 @@ func (a *API) VoterStats(w http.ResponseWriter, r *http.Request) {
 
 	// Parse the voter stats
 	receivers := parseVoterStats(output)
-	if !isAdmin {
-		// Mask receiver addresses for non-admins
+	if viewer != privacy.ViewerAdmin {
+		// Mask receiver addresses for non-admins; raw output would leak them unmasked
 		for i := range receivers {
-			if receivers[i].Address != "" {
-				receivers[i].Address = maskIPv4(receivers[i].Address)
-			}
+			receivers[i].Address = a.Privacy.MaskIP(receivers[i].Address, viewer)
 		}
+		output = ""
 	}
 
 	writeJSON(w, 200, map[string]any{
@@ -224,38 +219,19 @@ func extractReceiverName(line string) string {
 func extractIPAddress(line string) string {
 	tokens := strings.Fields(line)
 	for _, token := range tokens {
-		// Simple IP address check: xxx.xxx.xxx.xxx format
-		if strings.Count(token, ".") == 3 {
-			parts := strings.Split(token, ".")
-			if len(parts) == 4 {
-				valid := true
-				for _, part := range parts {
-					if num, err := strconv.Atoi(part); err != nil || num < 0 || num > 255 {
-						valid = false
-						break
-					}
-				}
-				if valid {
-					return token
-				}
-			}
+		// Accept IPv4 and IPv6, optionally bracketed and/or with a port
+		host := token
+		if h, _, err := net.SplitHostPort(token); err == nil {
+			host = h
+		}
+		host = strings.Trim(host, "[],;")
+		if ip := net.ParseIP(host); ip != nil {
+			return ip.String()
 		}
 	}
 	return ""
 }
 
-// maskIPv4 masks last two octets of IPv4 address, leaves others unchanged
-func maskIPv4(ip string) string {
-	if ip == "" {
-		return ip
-	}
-	parts := strings.Split(ip, ".")
-	if len(parts) == 4 {
-		return parts[0] + "." + parts[1] + ".*.*"
-	}
-	return ip
-}
-
 // hasLetters checks if a string contains any letters.
 func hasLetters(s string) bool {
 	for _, r := range s {
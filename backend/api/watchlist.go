@@ -0,0 +1,127 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/dbehnke/allstar-nexus/backend/models"
+	"github.com/dbehnke/allstar-nexus/backend/repository"
+	"github.com/dbehnke/allstar-nexus/internal/core"
+)
+
+// SetWatchlist enables the watchlist admin endpoint and loads the stored entries into w.
+// w may be nil when AMI is not configured; entries can still be managed but nothing is watched.
+func (a *API) SetWatchlist(repo *repository.WatchlistRepo, w *core.Watchlist) {
+	a.WatchlistRepo = repo
+	a.Watchlist = w
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if !a.reloadWatchlist(ctx) {
+		log.Printf("[WATCHLIST] failed to load watched nodes")
+	}
+}
+
+// AdminWatchlist lists (GET), adds or updates (POST) and removes (DELETE ?node=) watched nodes.
+// Endpoint: /api/admin/watchlist
+// POST body: {"node": 2001, "label": "Portable", "auto_connect": true, "connect_from": 43732}
+func (a *API) AdminWatchlist(w http.ResponseWriter, r *http.Request) {
+	if a.WatchlistRepo == nil {
+		writeError(w, 503, "unavailable", "watchlist not configured")
+		return
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	switch r.Method {
+	case http.MethodGet:
+		if a.Watchlist != nil {
+			writeJSON(w, 200, map[string]any{"nodes": a.Watchlist.Status(), "active": true})
+			return
+		}
+		list, err := a.WatchlistRepo.List(ctx)
+		if err != nil {
+			writeError(w, 500, "db_error", "failed to load watchlist")
+			return
+		}
+		out := make([]core.WatchStatus, 0, len(list))
+		for _, t := range watchTargets(list) {
+			out = append(out, core.WatchStatus{WatchTarget: t})
+		}
+		writeJSON(w, 200, map[string]any{"nodes": out, "active": false})
+	case http.MethodPost:
+		var body core.WatchTarget
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			writeError(w, 400, "bad_request", "invalid json body")
+			return
+		}
+		if body.Node <= 0 {
+			writeError(w, 400, "validation_error", "node must be a positive node number")
+			return
+		}
+		if body.ConnectFrom < 0 || len(body.Label) > 64 {
+			writeError(w, 400, "validation_error", "invalid connect_from or label too long")
+			return
+		}
+		by := ""
+		if u, status := a.currentUser(r); status == 200 {
+			by = u.Email
+		}
+		entry := models.WatchedNode{Node: body.Node, Label: body.Label, AutoConnect: body.AutoConnect, ConnectFrom: body.ConnectFrom, CreatedBy: by}
+		if err := a.WatchlistRepo.Save(ctx, entry); err != nil {
+			writeError(w, 500, "db_error", "failed to save watched node")
+			return
+		}
+		if !a.reloadWatchlist(ctx) {
+			writeError(w, 500, "db_error", "saved but failed to reload watchlist")
+			return
+		}
+		writeJSON(w, 200, body)
+	case http.MethodDelete:
+		node, err := strconv.Atoi(r.URL.Query().Get("node"))
+		if err != nil || node <= 0 {
+			writeError(w, 400, "validation_error", "node query parameter required")
+			return
+		}
+		found, err := a.WatchlistRepo.Delete(ctx, node)
+		if err != nil {
+			writeError(w, 500, "db_error", "failed to delete watched node")
+			return
+		}
+		if !found {
+			writeError(w, 404, "not_found", "node is not on the watchlist")
+			return
+		}
+		if !a.reloadWatchlist(ctx) {
+			writeError(w, 500, "db_error", "deleted but failed to reload watchlist")
+			return
+		}
+		writeJSON(w, 200, map[string]any{"node": node, "deleted": true})
+	default:
+		writeError(w, 405, "method_not_allowed", "only GET, POST and DELETE supported")
+	}
+}
+
+// reloadWatchlist pushes the stored entries into the running watchlist, if any.
+func (a *API) reloadWatchlist(ctx context.Context) bool {
+	if a.Watchlist == nil {
+		return true
+	}
+	list, err := a.WatchlistRepo.List(ctx)
+	if err != nil {
+		return false
+	}
+	a.Watchlist.SetTargets(watchTargets(list))
+	return true
+}
+
+func watchTargets(list []models.WatchedNode) []core.WatchTarget {
+	out := make([]core.WatchTarget, 0, len(list))
+	for _, w := range list {
+		out = append(out, core.WatchTarget{Node: w.Node, Label: w.Label, AutoConnect: w.AutoConnect, ConnectFrom: w.ConnectFrom})
+	}
+	return out
+}
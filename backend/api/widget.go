@@ -0,0 +1,144 @@
+package api
+
+import (
+	"html/template"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/dbehnke/allstar-nexus/internal/core"
+	"github.com/dbehnke/allstar-nexus/internal/privacy"
+)
+
+// MinWidgetRefresh is the shortest refresh interval the widget will poll at.
+const MinWidgetRefresh = 5 * time.Second
+
+// SetWidget enables the embeddable status widget with the given refresh interval.
+func (a *API) SetWidget(refresh time.Duration) {
+	if refresh < MinWidgetRefresh {
+		refresh = MinWidgetRefresh
+	}
+	a.WidgetRefresh = refresh
+}
+
+// widgetTalker is the talker shown in the widget.
+type widgetTalker struct {
+	Node     int       `json:"node"`
+	Callsign string    `json:"callsign,omitempty"`
+	At       time.Time `json:"at"`
+	Duration int       `json:"duration,omitempty"`
+}
+
+// widgetTalkers finds the station transmitting now (a TX_START not yet followed by a
+// TX_STOP for the same node) and the last completed transmission.
+func widgetTalkers(events []core.TalkerEvent) (current, last *widgetTalker) {
+	stopped := map[int]bool{}
+	for i := len(events) - 1; i >= 0 && (current == nil || last == nil); i-- {
+		evt := events[i]
+		switch evt.Kind {
+		case "TX_STOP":
+			stopped[evt.Node] = true
+			if last == nil {
+				last = &widgetTalker{Node: evt.Node, Callsign: evt.Callsign, At: evt.At, Duration: evt.Duration}
+			}
+		case "TX_START":
+			if current == nil && !stopped[evt.Node] {
+				current = &widgetTalker{Node: evt.Node, Callsign: evt.Callsign, At: evt.At}
+			}
+			stopped[evt.Node] = true // older starts for this node are finished
+		}
+	}
+	return current, last
+}
+
+// WidgetState returns the node status shown by the embeddable widget. It is always
+// filtered as for an anonymous viewer and may be fetched cross-origin.
+// Endpoint: GET /api/widget/state
+func (a *API) WidgetState(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, 405, "method_not_allowed", "only GET supported")
+		return
+	}
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	out := map[string]any{
+		"title":           a.BrandingDefaults.Title,
+		"online":          a.AMIConnector != nil && a.AMIConnector.IsConnected(),
+		"refresh_seconds": int(a.WidgetRefresh / time.Second),
+		"updated_at":      time.Now().UTC(),
+	}
+	if b, err := a.effectiveBranding(r.Context()); err == nil && b.ClubName != "" {
+		out["title"] = b.ClubName
+	}
+	if a.StateManager != nil {
+		st := a.snapshot(r)
+		out["node_id"] = st.NodeID
+		out["keyed"] = st.RxKeyed || st.TxKeyed
+		out["links"] = len(st.Links)
+		out["parrot"] = len(st.ParrotModes) > 0
+		if events, ok := a.Privacy.TalkerLog(a.talkerLog(r), privacy.ViewerAnonymous).([]core.TalkerEvent); ok {
+			current, last := widgetTalkers(events)
+			out["current_talker"], out["last_talker"] = current, last
+		}
+	}
+	writeJSON(w, 200, out)
+}
+
+// Widget serves a small self-contained status page meant to be iframed into club
+// websites, e.g. <iframe src="https://hub.example.org/widget?theme=dark" width="320"
+// height="120"></iframe>. ?refresh= (seconds) may slow polling below the configured
+// interval but never speed it up.
+// Endpoint: GET /widget
+func (a *API) Widget(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, 405, "method_not_allowed", "only GET supported")
+		return
+	}
+	refresh := a.WidgetRefresh
+	if s, err := strconv.Atoi(r.URL.Query().Get("refresh")); err == nil && time.Duration(s)*time.Second > refresh {
+		refresh = time.Duration(s) * time.Second
+	}
+	b, _ := a.effectiveBranding(r.Context())
+	accent := b.Colors["primary"]
+	if accent == "" {
+		accent = "#1e88e5"
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	_ = widgetTemplate.Execute(w, map[string]any{
+		"Title":     b.ClubName,
+		"Accent":    accent,
+		"Dark":      r.URL.Query().Get("theme") == "dark",
+		"RefreshMS": refresh.Milliseconds(),
+	})
+}
+
+var widgetTemplate = template.Must(template.New("widget").Parse(`<!doctype html>
+<html><head><meta charset="utf-8"><meta name="viewport" content="width=device-width,initial-scale=1">
+<title>{{.Title}}</title>
+<style>
+body{margin:0;font:14px/1.4 system-ui,sans-serif;background:{{if .Dark}}#121212{{else}}#fff{{end}};color:{{if .Dark}}#eee{{else}}#222{{end}}}
+.w{padding:8px 10px;border-left:4px solid {{.Accent}}}
+.t{font-weight:600}.s{opacity:.75;font-size:12px}
+.dot{display:inline-block;width:9px;height:9px;border-radius:50%;background:#999;margin-right:6px}
+.on .dot{background:#43a047}.keyed .dot{background:#e53935}
+</style></head>
+<body><div class="w" id="w"><div class="t"><span class="dot"></span><span id="title">{{.Title}}</span></div>
+<div id="status" class="s">Loading…</div><div id="talker"></div></div>
+<script>
+(function(){
+var el=function(id){return document.getElementById(id)};
+function ago(t){var s=Math.max(0,Math.round((Date.now()-new Date(t))/1000));return s<60?s+"s ago":Math.round(s/60)+"m ago"}
+function who(t){return t.callsign?t.callsign+" ("+t.node+")":"node "+t.node}
+function render(d){
+  el("w").className="w"+(d.online?" on":"")+(d.keyed?" keyed":"");
+  if(d.title)el("title").textContent=d.title;
+  var st=d.online?(d.node_id?"Node "+d.node_id+" · ":"")+d.links+" link"+(d.links===1?"":"s"):"Offline";
+  if(d.parrot)st+=" · test mode";
+  el("status").textContent=st;
+  el("talker").textContent=d.current_talker?"On air: "+who(d.current_talker):d.last_talker?"Last: "+who(d.last_talker)+", "+ago(d.last_talker.at):"";
+}
+function poll(){fetch("/api/widget/state",{cache:"no-store"}).then(function(r){return r.json()}).then(function(b){if(b.ok)render(b.data)}).catch(function(){el("status").textContent="Unavailable"})}
+poll();setInterval(poll,{{.RefreshMS}});
+})();
+</script></body></html>
+`))
@@ -0,0 +1,110 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/dbehnke/allstar-nexus/internal/web"
+)
+
+// SetWSHub enables the admin view of connected dashboard websocket clients
+func (a *API) SetWSHub(h *web.Hub) {
+	a.WSHub = h
+}
+
+// AdminWSClients lists connected websocket clients (GET) or disconnects one (DELETE ?id=).
+// Endpoint: /api/admin/ws-clients
+func (a *API) AdminWSClients(w http.ResponseWriter, r *http.Request) {
+	if a.WSHub == nil {
+		writeError(w, 503, "ws_unavailable", "websocket hub not initialized")
+		return
+	}
+	switch r.Method {
+	case http.MethodGet:
+		clients := a.WSHub.Clients()
+		writeJSON(w, 200, map[string]any{"clients": clients, "count": len(clients)})
+	case http.MethodDelete:
+		id, err := strconv.ParseUint(r.URL.Query().Get("id"), 10, 64)
+		if err != nil || id == 0 {
+			writeError(w, 400, "bad_request", "query parameter 'id' must be a client id")
+			return
+		}
+		if !a.WSHub.Disconnect(id) {
+			writeError(w, 404, "not_found", "no connected client with that id")
+			return
+		}
+		writeJSON(w, 200, map[string]any{"disconnected": id})
+	default:
+		writeError(w, 405, "method_not_allowed", "only GET and DELETE supported")
+	}
+}
+
+// Limits for synthetic websocket storms, so a typo cannot wedge the server.
+const (
+	maxBenchCount = 100000
+	maxBenchSize  = 64 << 10
+	maxBenchTime  = 10 * time.Minute
+)
+
+// AdminWSBench broadcasts a synthetic storm of BENCH messages to every connected
+// websocket client, for load-testing fan-out with tools/ws_client -bench. The storm runs
+// in the background; the response says how many messages to expect. Only one storm runs
+// at a time.
+// Endpoint: POST /api/admin/ws-bench?count=1000&interval=1ms&size=0&run=
+func (a *API) AdminWSBench(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, 405, "method_not_allowed", "only POST supported")
+		return
+	}
+	if a.WSHub == nil {
+		writeError(w, 503, "ws_unavailable", "websocket hub not initialized")
+		return
+	}
+	q := r.URL.Query()
+	storm := web.BenchStorm{Run: q.Get("run"), Count: 1000}
+	if storm.Run == "" {
+		storm.Run = strconv.FormatInt(time.Now().UnixNano(), 36)
+	}
+	if v := q.Get("count"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxBenchCount {
+			writeError(w, 400, "bad_request", "count must be between 1 and "+strconv.Itoa(maxBenchCount))
+			return
+		}
+		storm.Count = n
+	}
+	if v := q.Get("interval"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d < 0 {
+			writeError(w, 400, "bad_request", "interval must be a duration such as 1ms")
+			return
+		}
+		storm.Interval = d
+	}
+	if v := q.Get("size"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 || n > maxBenchSize {
+			writeError(w, 400, "bad_request", "size must be between 0 and "+strconv.Itoa(maxBenchSize))
+			return
+		}
+		storm.Size = n
+	}
+	if time.Duration(storm.Count)*storm.Interval > maxBenchTime {
+		writeError(w, 400, "bad_request", "storm would run longer than "+maxBenchTime.String())
+		return
+	}
+	if !a.wsBenchRunning.CompareAndSwap(false, true) {
+		writeError(w, 409, "bench_running", "a websocket storm is already running")
+		return
+	}
+	clients := len(a.WSHub.Clients())
+	go func() {
+		defer a.wsBenchRunning.Store(false)
+		ctx, cancel := context.WithTimeout(context.Background(), maxBenchTime)
+		defer cancel()
+		a.WSHub.Bench(ctx, storm)
+	}()
+	writeJSON(w, 202, map[string]any{"run": storm.Run, "count": storm.Count, "interval": storm.Interval.String(), "size": storm.Size, "clients": clients})
+}
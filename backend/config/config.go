@@ -1,11 +1,11 @@
 package config
 
 import (
-	"bufio"
-	"bytes"
 	"fmt"
 	"log"
 	"os"
+	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 
@@ -14,21 +14,40 @@ import (
 
 // NodeConfig represents configuration for a single AllStar node
 type NodeConfig struct {
-	NodeID int    `mapstructure:"node_id" yaml:"node_id" json:"node_id"`
-	Name   string `mapstructure:"name" yaml:"name,omitempty" json:"name,omitempty"` // Optional - if empty, lookup from astdb
+	NodeID       int           `mapstructure:"node_id" yaml:"node_id" json:"node_id"`
+	Name         string        `mapstructure:"name" yaml:"name,omitempty" json:"name,omitempty"`      // Optional - if empty, lookup from astdb
+	PollInterval time.Duration `mapstructure:"poll_interval" yaml:"poll_interval,omitempty" json:"-"` // Optional - overrides poll_interval for this node
+}
+
+// LocalNodeConfig seeds the private node registry with a node that is not in astdb.
+type LocalNodeConfig struct {
+	Node        int    `mapstructure:"node" yaml:"node" json:"node"`
+	Callsign    string `mapstructure:"callsign" yaml:"callsign" json:"callsign"`
+	Description string `mapstructure:"description" yaml:"description,omitempty" json:"description,omitempty"`
+	Location    string `mapstructure:"location" yaml:"location,omitempty" json:"location,omitempty"`
+}
+
+// AstDBSourceConfig is a supplementary astdb-format file merged over the astdb_url
+// download. Set exactly one of URL and Path.
+type AstDBSourceConfig struct {
+	Name string `mapstructure:"name" yaml:"name,omitempty"`
+	URL  string `mapstructure:"url" yaml:"url,omitempty"`
+	Path string `mapstructure:"path" yaml:"path,omitempty"`
 }
 
 // GamificationConfig holds gamification system settings
 type GamificationConfig struct {
-	Enabled              bool                     `mapstructure:"enabled" yaml:"enabled"`
-	TallyIntervalMinutes int                      `mapstructure:"tally_interval_minutes" yaml:"tally_interval_minutes"`
-	RestedBonus          RestedBonusConfig        `mapstructure:"rested_bonus" yaml:"rested_bonus"`
-	DiminishingReturns   DiminishingReturnsConfig `mapstructure:"diminishing_returns" yaml:"diminishing_returns"`
-	KerchunkDetection    KerchunkConfig           `mapstructure:"kerchunk_detection" yaml:"kerchunk_detection"`
-	XPCaps               XPCapsConfig             `mapstructure:"xp_caps" yaml:"xp_caps"`
-	LevelScale           []LevelScaleConfig       `mapstructure:"level_scale" yaml:"level_scale"`
-	LevelGroupings       []LevelGrouping          `mapstructure:"level_groupings" yaml:"level_groupings"`
-	Renown               RenownConfig             `mapstructure:"renown" yaml:"renown"`
+	Enabled               bool                     `mapstructure:"enabled" yaml:"enabled"`
+	TallyIntervalMinutes  int                      `mapstructure:"tally_interval_minutes" yaml:"tally_interval_minutes"`
+	BacklogThresholdHours int                      `mapstructure:"backlog_threshold_hours" yaml:"backlog_threshold_hours"` // startup backlogs older than this are tallied in the background
+	RestedBonus           RestedBonusConfig        `mapstructure:"rested_bonus" yaml:"rested_bonus"`
+	DiminishingReturns    DiminishingReturnsConfig `mapstructure:"diminishing_returns" yaml:"diminishing_returns"`
+	KerchunkDetection     KerchunkConfig           `mapstructure:"kerchunk_detection" yaml:"kerchunk_detection"`
+	XPCaps                XPCapsConfig             `mapstructure:"xp_caps" yaml:"xp_caps"`
+	LevelScale            []LevelScaleConfig       `mapstructure:"level_scale" yaml:"level_scale"`
+	LevelGroupings        []LevelGrouping          `mapstructure:"level_groupings" yaml:"level_groupings"`
+	Renown                RenownConfig             `mapstructure:"renown" yaml:"renown"`
+	OptOutMode            string                   `mapstructure:"opt_out_mode" yaml:"opt_out_mode"` // hide (still counted) or exclude (no XP)
 }
 
 type RestedBonusConfig struct {
@@ -86,17 +105,363 @@ type LevelGrouping struct {
 }
 
 type RenownConfig struct {
-	Enabled    bool `mapstructure:"enabled" yaml:"enabled"`
-	XPPerLevel int  `mapstructure:"xp_per_level" yaml:"xp_per_level"`
+	Enabled    bool         `mapstructure:"enabled" yaml:"enabled"`
+	XPPerLevel int          `mapstructure:"xp_per_level" yaml:"xp_per_level"`
+	Titles     []RenownTier `mapstructure:"titles" yaml:"titles,omitempty"`
+}
+
+// RenownTier names a range of renown levels and optionally sweetens the
+// rested bonus for callsigns in it.
+type RenownTier struct {
+	Levels                string  `mapstructure:"levels" yaml:"levels"` // renown levels, e.g. "1-2", "5" or "10+"
+	Title                 string  `mapstructure:"title" yaml:"title"`   // e.g., "Bronze", "Net Legend"
+	Badge                 string  `mapstructure:"badge" yaml:"badge"`
+	Color                 string  `mapstructure:"color" yaml:"color"`
+	RestedMultiplierBonus float64 `mapstructure:"rested_multiplier_bonus" yaml:"rested_multiplier_bonus"` // added to rested_bonus.multiplier
+	RestedMaxHoursBonus   int     `mapstructure:"rested_max_hours_bonus" yaml:"rested_max_hours_bonus"`   // added to rested_bonus.max_hours
+}
+
+// IdleReminderConfig controls the hub idle detector and its reminder action.
+type IdleReminderConfig struct {
+	Enabled     bool     `mapstructure:"enabled" yaml:"enabled"`
+	IdleMinutes int      `mapstructure:"idle_minutes" yaml:"idle_minutes"`
+	StartHour   int      `mapstructure:"start_hour" yaml:"start_hour"`   // Local hour (0-23) reminders may begin
+	EndHour     int      `mapstructure:"end_hour" yaml:"end_hour"`       // Local hour (0-23) reminders stop; equal to start_hour = all day
+	Repeat      bool     `mapstructure:"repeat" yaml:"repeat"`           // Fire again after each further idle_minutes of silence
+	AMICommand  string   `mapstructure:"ami_command" yaml:"ami_command"` // e.g. "rpt fun 43732 *81" or "rpt localplay 43732 /etc/asterisk/id"
+	WebhookURL  string   `mapstructure:"webhook_url" yaml:"webhook_url"`
+	Notify      []string `mapstructure:"notify" yaml:"notify"` // notification providers, e.g. [telegram, ntfy]
+}
+
+// TalkerWebhookConfig controls webhook notifications for transmissions.
+type TalkerWebhookConfig struct {
+	Enabled        bool          `mapstructure:"enabled" yaml:"enabled"`
+	WebhookURL     string        `mapstructure:"webhook_url" yaml:"webhook_url"`
+	Notify         []string      `mapstructure:"notify" yaml:"notify"`                     // notification providers
+	MinDuration    time.Duration `mapstructure:"min_duration" yaml:"min_duration"`         // ignore shorter transmissions
+	Cooldown       time.Duration `mapstructure:"cooldown" yaml:"cooldown"`                 // per callsign between notifications
+	QuietStartHour int           `mapstructure:"quiet_start_hour" yaml:"quiet_start_hour"` // Local hour (0-23) notifications stop
+	QuietEndHour   int           `mapstructure:"quiet_end_hour" yaml:"quiet_end_hour"`     // Local hour (0-23) they resume; equal = no quiet hours
+	Digest         bool          `mapstructure:"digest" yaml:"digest"`                     // one summary per digest_interval instead
+	DigestInterval time.Duration `mapstructure:"digest_interval" yaml:"digest_interval"`
+}
+
+// TalkerDedupConfig controls how a transmission seen through several configured source
+// nodes (the remote is linked to more than one of them) is presented.
+type TalkerDedupConfig struct {
+	Mode   string        `mapstructure:"mode" yaml:"mode"`     // per_source or deduplicated
+	Window time.Duration `mapstructure:"window" yaml:"window"` // reports of one remote node this close together are one transmission
+}
+
+// AMISSHConfig reaches AMI through an SSH server when the hub is not directly
+// routable (CGNAT, firewall). ami_host/ami_port are resolved on the SSH server's side.
+type AMISSHConfig struct {
+	Host                  string        `mapstructure:"host" yaml:"host"` // empty disables the tunnel
+	Port                  int           `mapstructure:"port" yaml:"port"`
+	User                  string        `mapstructure:"user" yaml:"user"`
+	KeyFile               string        `mapstructure:"key_file" yaml:"key_file"`       // unencrypted private key
+	KnownHostsFile        string        `mapstructure:"known_hosts" yaml:"known_hosts"` // default ~/.ssh/known_hosts
+	InsecureIgnoreHostKey bool          `mapstructure:"insecure_ignore_host_key" yaml:"insecure_ignore_host_key"`
+	KeepAlive             time.Duration `mapstructure:"keepalive" yaml:"keepalive"`
+}
+
+// AMIConsoleConfig controls the superadmin AMI command console.
+type AMIConsoleConfig struct {
+	Enabled        bool          `mapstructure:"enabled" yaml:"enabled"`
+	Commands       []string      `mapstructure:"commands" yaml:"commands"`                 // whitelist; each may be followed by node numbers
+	MaxOutputBytes int           `mapstructure:"max_output_bytes" yaml:"max_output_bytes"` // longer output is truncated
+	Timeout        time.Duration `mapstructure:"timeout" yaml:"timeout"`
+}
+
+// AnnouncementsConfig controls text-to-speech announcements played over a node.
+type AnnouncementsConfig struct {
+	Enabled       bool          `mapstructure:"enabled" yaml:"enabled"`
+	Engine        string        `mapstructure:"engine" yaml:"engine"`             // espeak or piper
+	Binary        string        `mapstructure:"binary" yaml:"binary"`             // defaults to espeak-ng or piper on PATH
+	Voice         string        `mapstructure:"voice" yaml:"voice"`               // espeak voice or Piper .onnx model
+	SoxPath       string        `mapstructure:"sox_path" yaml:"sox_path"`         // resamples to 8 kHz mono for app_rpt
+	SoundsDir     string        `mapstructure:"sounds_dir" yaml:"sounds_dir"`     // rendered audio; must be readable by Asterisk
+	PlayCommand   string        `mapstructure:"play_command" yaml:"play_command"` // fmt template receiving the node and the sound file without extension
+	MaxChars      int           `mapstructure:"max_chars" yaml:"max_chars"`
+	CheckInterval time.Duration `mapstructure:"check_interval" yaml:"check_interval"` // how often scheduled announcements are checked
+}
+
+// RptConfigConfig controls editing of per-node telemetry settings in rpt.conf.
+type RptConfigConfig struct {
+	Enabled       bool   `mapstructure:"enabled" yaml:"enabled"`
+	Path          string `mapstructure:"path" yaml:"path"`                     // must be writable by Nexus
+	ReloadCommand string `mapstructure:"reload_command" yaml:"reload_command"` // AMI command applying the changes
+}
+
+// NodeActionsConfig lets registered users run a whitelist of actions on nodes they
+// have claimed (and an admin has approved), e.g. disconnecting their node from the hub.
+type NodeActionsConfig struct {
+	Enabled           bool     `mapstructure:"enabled" yaml:"enabled"`
+	Actions           []string `mapstructure:"actions" yaml:"actions"`                       // disconnect, connect
+	ConnectCommand    string   `mapstructure:"connect_command" yaml:"connect_command"`       // fmt template receiving the hub node and the user's node
+	DisconnectCommand string   `mapstructure:"disconnect_command" yaml:"disconnect_command"` // fmt template receiving the hub node and the user's node
+	RateLimitRPM      int      `mapstructure:"rate_limit_rpm" yaml:"rate_limit_rpm"`         // actions per minute per user
+}
+
+// TenantConfig defines one hub hosted by a multi-tenant instance. Tenants are served
+// under /h/{id}/ and on any of their hosts; empty branding fields inherit the instance's.
+type TenantConfig struct {
+	ID       string            `mapstructure:"id" yaml:"id"` // lowercase slug
+	Name     string            `mapstructure:"name" yaml:"name"`
+	Hosts    []string          `mapstructure:"hosts" yaml:"hosts"`
+	Nodes    []int             `mapstructure:"nodes" yaml:"nodes"`   // local nodes owned by the tenant; the first is its primary
+	Admins   []string          `mapstructure:"admins" yaml:"admins"` // emails of the tenant's admins
+	Title    string            `mapstructure:"title" yaml:"title"`
+	Subtitle string            `mapstructure:"subtitle" yaml:"subtitle"`
+	ClubName string            `mapstructure:"club_name" yaml:"club_name"`
+	LogoURL  string            `mapstructure:"logo_url" yaml:"logo_url"`
+	Colors   map[string]string `mapstructure:"colors" yaml:"colors"`
+}
+
+// IAXMonitorConfig controls polling of IAX2 registrations and peers and the alerts
+// raised when a registration lapses or a qualified peer becomes unreachable.
+type IAXMonitorConfig struct {
+	Enabled    bool          `mapstructure:"enabled" yaml:"enabled"`
+	Interval   time.Duration `mapstructure:"interval" yaml:"interval"`
+	LapsePolls int           `mapstructure:"lapse_polls" yaml:"lapse_polls"` // consecutive failed polls before alerting
+	WebhookURL string        `mapstructure:"webhook_url" yaml:"webhook_url"`
+	Notify     []string      `mapstructure:"notify" yaml:"notify"` // notification providers
+}
+
+// AsteriskLogRuleConfig classifies log lines whose message matches Pattern (a regular
+// expression) as Category.
+type AsteriskLogRuleConfig struct {
+	Category string `mapstructure:"category" yaml:"category"`
+	Pattern  string `mapstructure:"pattern" yaml:"pattern"`
+}
+
+// AsteriskLogConfig controls tailing the Asterisk messages log for app_rpt warnings.
+type AsteriskLogConfig struct {
+	Enabled         bool                    `mapstructure:"enabled" yaml:"enabled"`
+	Path            string                  `mapstructure:"path" yaml:"path"`
+	MaxEntries      int                     `mapstructure:"max_entries" yaml:"max_entries"`           // classified lines kept for /api/admin/asterisk-log
+	Rules           []AsteriskLogRuleConfig `mapstructure:"rules" yaml:"rules"`                       // checked before the built-in classes
+	AlertCategories []string                `mapstructure:"alert_categories" yaml:"alert_categories"` // categories that raise alerts
+	AlertCooldown   time.Duration           `mapstructure:"alert_cooldown" yaml:"alert_cooldown"`     // per category
+	WebhookURL      string                  `mapstructure:"webhook_url" yaml:"webhook_url"`
+	Notify          []string                `mapstructure:"notify" yaml:"notify"` // notification providers
+}
+
+// NodeHealthConfig controls probing linked remote nodes for reachability.
+type NodeHealthConfig struct {
+	Enabled      bool          `mapstructure:"enabled" yaml:"enabled"`
+	Method       string        `mapstructure:"method" yaml:"method"` // iax2 (POKE/PONG) or icmp (needs CAP_NET_RAW)
+	Interval     time.Duration `mapstructure:"interval" yaml:"interval"`
+	Count        int           `mapstructure:"count" yaml:"count"` // probes per node per round
+	Timeout      time.Duration `mapstructure:"timeout" yaml:"timeout"`
+	Window       int           `mapstructure:"window" yaml:"window"`               // rounds the live link health covers
+	DegradedLoss float64       `mapstructure:"degraded_loss" yaml:"degraded_loss"` // percent
+	DegradedRTT  time.Duration `mapstructure:"degraded_rtt" yaml:"degraded_rtt"`
+	Retention    time.Duration `mapstructure:"retention" yaml:"retention"` // 0 keeps history forever
+}
+
+// LinkQualityConfig controls sampling per-connection jitter and packet statistics.
+type LinkQualityConfig struct {
+	Enabled   bool          `mapstructure:"enabled" yaml:"enabled"`
+	Interval  time.Duration `mapstructure:"interval" yaml:"interval"`
+	Retention time.Duration `mapstructure:"retention" yaml:"retention"` // 0 keeps history forever
+}
+
+// ParrotConfig controls the admin-triggered parrot (audio test) mode.
+type ParrotConfig struct {
+	EnableCommand  string `mapstructure:"enable_command" yaml:"enable_command"`   // fmt template receiving the node number
+	DisableCommand string `mapstructure:"disable_command" yaml:"disable_command"` // fmt template receiving the node number
+	DefaultSeconds int    `mapstructure:"default_seconds" yaml:"default_seconds"`
+	MaxSeconds     int    `mapstructure:"max_seconds" yaml:"max_seconds"`
+}
+
+// WatchlistConfig controls how the admin-managed node watchlist is checked and what it
+// does when a watched node appears. Watched nodes themselves are stored in the database.
+type WatchlistConfig struct {
+	CheckInterval   time.Duration `mapstructure:"check_interval" yaml:"check_interval"`
+	ConnectCommand  string        `mapstructure:"connect_command" yaml:"connect_command"`   // fmt template receiving local and remote node numbers
+	ConnectCooldown time.Duration `mapstructure:"connect_cooldown" yaml:"connect_cooldown"` // minimum time between auto-connects to one node
+	WebhookURL      string        `mapstructure:"webhook_url" yaml:"webhook_url"`           // optional alert when a watched node appears
+	Notify          []string      `mapstructure:"notify" yaml:"notify"`                     // notification providers for the same alert
+}
+
+// NodeLookupFallbackConfig controls looking up nodes missing from astdb (e.g. registered
+// since the last dump) through the AllStarLink web API.
+type NodeLookupFallbackConfig struct {
+	Enabled           bool          `mapstructure:"enabled" yaml:"enabled"`
+	URL               string        `mapstructure:"url" yaml:"url"`                                 // fmt template receiving the node number
+	CacheTTL          time.Duration `mapstructure:"cache_ttl" yaml:"cache_ttl"`                     // refresh cached web results after this long
+	NegativeTTL       time.Duration `mapstructure:"negative_ttl" yaml:"negative_ttl"`               // do not ask again for unknown nodes for this long
+	RequestsPerMinute int           `mapstructure:"requests_per_minute" yaml:"requests_per_minute"` // cap on external calls
+}
+
+// TopologyHistoryConfig controls recording of the link graph for /api/topology/history.
+type TopologyHistoryConfig struct {
+	Enabled       bool          `mapstructure:"enabled" yaml:"enabled"`
+	Interval      time.Duration `mapstructure:"interval" yaml:"interval"`             // at most one entry per interval
+	KeyframeEvery int           `mapstructure:"keyframe_every" yaml:"keyframe_every"` // full graph every N entries, diffs in between
+	RetentionDays int           `mapstructure:"retention_days" yaml:"retention_days"` // 0 keeps history forever
+}
+
+// TimeSyncConfig controls the startup/periodic clock sanity check.
+type TimeSyncConfig struct {
+	Enabled         bool     `mapstructure:"enabled" yaml:"enabled"`
+	NTPServers      []string `mapstructure:"ntp_servers" yaml:"ntp_servers"`
+	HTTPURLs        []string `mapstructure:"http_urls" yaml:"http_urls"` // Fallback when UDP/123 is blocked (Date header)
+	IntervalMinutes int      `mapstructure:"interval_minutes" yaml:"interval_minutes"`
+	MaxSkewSeconds  int      `mapstructure:"max_skew_seconds" yaml:"max_skew_seconds"`
+	WebhookURL      string   `mapstructure:"webhook_url" yaml:"webhook_url"` // Optional alert on detected skew
+	Notify          []string `mapstructure:"notify" yaml:"notify"`           // Notification providers for the same alert
+}
+
+// NotificationsConfig configures the chat/push services alert rules can name in their
+// notify lists. A provider is available once its required fields are set.
+type NotificationsConfig struct {
+	Discord  DiscordConfig  `mapstructure:"discord" yaml:"discord"`
+	Pushover PushoverConfig `mapstructure:"pushover" yaml:"pushover"`
+	Telegram TelegramConfig `mapstructure:"telegram" yaml:"telegram"`
+	Ntfy     NtfyConfig     `mapstructure:"ntfy" yaml:"ntfy"`
+}
+
+// DiscordConfig posts to a Discord channel webhook.
+type DiscordConfig struct {
+	WebhookURL string `mapstructure:"webhook_url" yaml:"webhook_url"`
+	Username   string `mapstructure:"username" yaml:"username"` // optional override of the webhook's name
+}
+
+// DiscordBotConfig answers slash commands (/whos-talking, /scoreboard, /profile,
+// /lastheard) that Discord posts to /api/discord/interactions.
+type DiscordBotConfig struct {
+	Enabled       bool   `mapstructure:"enabled" yaml:"enabled"`
+	ApplicationID string `mapstructure:"application_id" yaml:"application_id"`
+	PublicKey     string `mapstructure:"public_key" yaml:"public_key"` // hex key that signs interaction requests
+	BotToken      string `mapstructure:"bot_token" yaml:"bot_token"`   // registers the commands at startup; optional if registered elsewhere
+	GuildID       string `mapstructure:"guild_id" yaml:"guild_id"`     // register for one server (instant) instead of globally
+}
+
+// TelegramBotConfig runs a Telegram bot that answers /whoson, posts activity feed
+// entries to a group chat and lets linked admins poll, connect and disconnect nodes.
+type TelegramBotConfig struct {
+	Enabled           bool     `mapstructure:"enabled" yaml:"enabled"`
+	BotToken          string   `mapstructure:"bot_token" yaml:"bot_token"`
+	AlertChatID       string   `mapstructure:"alert_chat_id" yaml:"alert_chat_id"`           // group chat for activity alerts; empty = no alerts
+	Alerts            []string `mapstructure:"alerts" yaml:"alerts"`                         // activity kinds to post (qso, net, level_up, long_tx); empty = all
+	ConnectCommand    string   `mapstructure:"connect_command" yaml:"connect_command"`       // fmt template receiving the local and remote node numbers
+	DisconnectCommand string   `mapstructure:"disconnect_command" yaml:"disconnect_command"` // fmt template receiving the local and remote node numbers
+}
+
+// PushoverConfig sends through the Pushover API.
+type PushoverConfig struct {
+	Token  string `mapstructure:"token" yaml:"token"`   // application API token
+	User   string `mapstructure:"user" yaml:"user"`     // user or group key
+	Device string `mapstructure:"device" yaml:"device"` // optional; empty = all devices
+	Sound  string `mapstructure:"sound" yaml:"sound"`
+}
+
+// TelegramConfig sends through a Telegram bot.
+type TelegramConfig struct {
+	BotToken string `mapstructure:"bot_token" yaml:"bot_token"`
+	ChatID   string `mapstructure:"chat_id" yaml:"chat_id"` // numeric chat ID or @channelname
+}
+
+// NtfyConfig publishes to an ntfy topic.
+type NtfyConfig struct {
+	Server string `mapstructure:"server" yaml:"server"` // e.g. https://ntfy.sh or a self-hosted server
+	Topic  string `mapstructure:"topic" yaml:"topic"`
+	Token  string `mapstructure:"token" yaml:"token"` // optional access token
+}
+
+// Configured returns the names of the providers whose required fields are set.
+func (n NotificationsConfig) Configured() []string {
+	var out []string
+	if n.Discord.WebhookURL != "" {
+		out = append(out, "discord")
+	}
+	if n.Pushover.Token != "" && n.Pushover.User != "" {
+		out = append(out, "pushover")
+	}
+	if n.Telegram.BotToken != "" && n.Telegram.ChatID != "" {
+		out = append(out, "telegram")
+	}
+	if n.Ntfy.Topic != "" {
+		out = append(out, "ntfy")
+	}
+	return out
+}
+
+// BrandingConfig holds the dashboard theme served by /api/branding. Admins can override
+// these values at runtime; overrides are stored in the settings table.
+type BrandingConfig struct {
+	ClubName    string            `mapstructure:"club_name" yaml:"club_name"` // Defaults to title when empty
+	LogoURL     string            `mapstructure:"logo_url" yaml:"logo_url"`
+	Colors      map[string]string `mapstructure:"colors" yaml:"colors"` // e.g. primary: "#1e88e5"
+	FooterLinks []FooterLink      `mapstructure:"footer_links" yaml:"footer_links"`
+}
+
+// FooterLink is a labelled link rendered in the dashboard footer.
+type FooterLink struct {
+	Label string `mapstructure:"label" yaml:"label" json:"label"`
+	URL   string `mapstructure:"url" yaml:"url" json:"url"`
+}
+
+// PrivacyConfig controls what non-admin dashboard viewers see, in both REST and WS payloads.
+type PrivacyConfig struct {
+	IPMasking         string `mapstructure:"ip_masking" yaml:"ip_masking"`                   // full | partial | none (non-admins)
+	AnonShowCallsigns bool   `mapstructure:"anon_show_callsigns" yaml:"anon_show_callsigns"` // false = anonymous viewers see node numbers only
+	AnonTalkerHistory bool   `mapstructure:"anon_talker_history" yaml:"anon_talker_history"`
+}
+
+// RateLimitPolicyConfig overrides the rate limit for a single route. Zero values inherit
+// the route's default (auth_rpm or public_stats_rpm).
+type RateLimitPolicyConfig struct {
+	RequestsPerMinute int    `mapstructure:"rpm" yaml:"rpm"`
+	Burst             int    `mapstructure:"burst" yaml:"burst"`
+	KeyBy             string `mapstructure:"key_by" yaml:"key_by"` // ip | token | api_key
+}
+
+// RateLimitConfig holds per-route rate limit policies and the client store bound.
+type RateLimitConfig struct {
+	MaxKeys int                              `mapstructure:"max_keys" yaml:"max_keys"` // Max tracked clients per route (LRU evicted)
+	Routes  map[string]RateLimitPolicyConfig `mapstructure:"routes" yaml:"routes"`     // Keyed by path, e.g. "/api/auth/login"
+}
+
+// ResponseCacheConfig controls the in-process cache in front of public read endpoints
+// (scoreboard, link stats). Route TTLs override the built-in defaults; 0 disables a route.
+type ResponseCacheConfig struct {
+	Enabled    bool                     `mapstructure:"enabled" yaml:"enabled"`
+	MaxEntries int                      `mapstructure:"max_entries" yaml:"max_entries"` // Cached responses kept (LRU evicted)
+	Routes     map[string]time.Duration `mapstructure:"routes" yaml:"routes"`           // Keyed by path, e.g. "/api/link-stats"
+}
+
+// WidgetConfig controls the public embeddable status widget (/widget and /api/widget/state).
+type WidgetConfig struct {
+	Enabled        bool `mapstructure:"enabled" yaml:"enabled"`
+	RefreshSeconds int  `mapstructure:"refresh_seconds" yaml:"refresh_seconds"` // Poll interval; ?refresh= may slow it, never speed it up
+	RPM            int  `mapstructure:"rpm" yaml:"rpm"`                         // Requests per minute per client IP
+}
+
+// ActivityFeedConfig controls the public Atom feed of notable hub activity (/feed.atom).
+type ActivityFeedConfig struct {
+	Enabled            bool          `mapstructure:"enabled" yaml:"enabled"`
+	Include            []string      `mapstructure:"include" yaml:"include"`                           // qso, net, level_up, long_tx; empty = all
+	MaxEntries         int           `mapstructure:"max_entries" yaml:"max_entries"`                   // Entries kept in memory and listed
+	LongTx             time.Duration `mapstructure:"long_tx" yaml:"long_tx"`                           // Minimum length of a listed single transmission
+	SessionGap         time.Duration `mapstructure:"session_gap" yaml:"session_gap"`                   // Silence that ends a QSO or net session
+	QSOMinOvers        int           `mapstructure:"qso_min_overs" yaml:"qso_min_overs"`               // Speaker changes needed for a QSO
+	NetMinParticipants int           `mapstructure:"net_min_participants" yaml:"net_min_participants"` // Distinct stations needed for a net
 }
 
 // Config holds runtime configuration values.
 type Config struct {
 	Port                    string
+	DataDir                 string // Everything Nexus writes (database, astdb cache, uploaded logos) lives here by default
 	DBPath                  string
+	DBBusyTimeout           time.Duration // How long SQLite waits on a locked database before failing
 	AstDBPath               string
 	AstDBURL                string
 	AstDBUpdateHours        int
+	AstDBServe              bool                // Serve the cached file at /astdb.txt for other LAN tools
+	AstDBSources            []AstDBSourceConfig // Supplements merged over astdb_url, highest priority first
 	JWTSecret               string
 	Env                     string
 	BuildTime               string
@@ -104,6 +469,11 @@ type Config struct {
 	TokenTTL                time.Duration
 	AuthRateLimitRPM        int
 	PublicStatsRateLimitRPM int
+	RateLimits              RateLimitConfig
+	EndpointAccess          map[string]string // route path -> anonymous | user | admin; unset routes follow AllowAnonDashboard
+	ResponseCache           ResponseCacheConfig
+	Widget                  WidgetConfig
+	ActivityFeed            ActivityFeedConfig
 	AMIEnabled              bool
 	AMIHost                 string
 	AMIPort                 int
@@ -112,12 +482,43 @@ type Config struct {
 	AMIEvents               string
 	AMIRetryInterval        time.Duration
 	AMIRetryMax             time.Duration
-	Nodes                   []NodeConfig // Multiple nodes support
+	AMITLS                  bool              // Dial AMI over TLS (Asterisk manager.conf tlsenable)
+	AMITLSSkipVerify        bool              // Accept any server certificate (self-signed, testing only)
+	AMITLSCAFile            string            // PEM CA bundle for verifying a private-CA AMI certificate
+	Nodes                   []NodeConfig      // Multiple nodes support
+	LocalNodes              []LocalNodeConfig // Private node numbers not in astdb
 	DisableLinkPoller       bool
+	PollInterval            time.Duration // Default XStat/SawStat poll interval per node
+	PollWorkers             int           // Max nodes polled concurrently
 	AllowAnonDashboard      bool
 	Title                   string
 	Subtitle                string
+	Timezone                string // IANA zone for calendar-based resets; empty = system local
+	Locale                  string // BCP 47 tag handed to clients for date/number formatting
 	Gamification            GamificationConfig
+	IdleReminder            IdleReminderConfig
+	TalkerWebhook           TalkerWebhookConfig
+	TalkerDedup             TalkerDedupConfig
+	Parrot                  ParrotConfig
+	Watchlist               WatchlistConfig
+	TopologyHistory         TopologyHistoryConfig
+	NodeLookupFallback      NodeLookupFallbackConfig
+	AMISSH                  AMISSHConfig
+	AMIConsole              AMIConsoleConfig
+	Announcements           AnnouncementsConfig
+	RptConfig               RptConfigConfig
+	NodeActions             NodeActionsConfig
+	Tenants                 []TenantConfig
+	IAXMonitor              IAXMonitorConfig
+	AsteriskLog             AsteriskLogConfig
+	NodeHealth              NodeHealthConfig
+	LinkQuality             LinkQualityConfig
+	TimeSync                TimeSyncConfig
+	Notifications           NotificationsConfig
+	DiscordBot              DiscordBotConfig
+	TelegramBot             TelegramBotConfig
+	Branding                BrandingConfig
+	Privacy                 PrivacyConfig
 }
 
 // Load loads configuration from config file and environment variables using Viper
@@ -125,15 +526,30 @@ type Config struct {
 func Load(configPath ...string) Config {
 	// Set default values
 	viper.SetDefault("port", "8080")
-	viper.SetDefault("db_path", "data/allstar.db")
-	viper.SetDefault("astdb_path", "data/astdb.txt")
+	// data_dir, db_path and astdb_path have no viper defaults: unset paths are derived
+	// from data_dir after loading (see resolveDataPaths)
+	viper.SetDefault("db_busy_timeout", "5s")
 	viper.SetDefault("astdb_url", "http://allmondb.allstarlink.org/")
 	viper.SetDefault("astdb_update_hours", 24)
+	viper.SetDefault("astdb_serve", true)
 	viper.SetDefault("jwt_secret", "dev-secret-change-me")
 	viper.SetDefault("app_env", "development")
 	viper.SetDefault("token_ttl_seconds", 86400)
 	viper.SetDefault("auth_rpm", 60)
 	viper.SetDefault("public_stats_rpm", 120)
+	viper.SetDefault("rate_limits.max_keys", 10000)
+	viper.SetDefault("response_cache.enabled", true)
+	viper.SetDefault("response_cache.max_entries", 1000)
+	viper.SetDefault("widget.enabled", false)
+	viper.SetDefault("widget.refresh_seconds", 15)
+	viper.SetDefault("widget.rpm", 30)
+	viper.SetDefault("activity_feed.enabled", false)
+	viper.SetDefault("activity_feed.include", []string{"qso", "net", "level_up", "long_tx"})
+	viper.SetDefault("activity_feed.max_entries", 50)
+	viper.SetDefault("activity_feed.long_tx", "5m")
+	viper.SetDefault("activity_feed.session_gap", "3m")
+	viper.SetDefault("activity_feed.qso_min_overs", 4)
+	viper.SetDefault("activity_feed.net_min_participants", 4)
 	viper.SetDefault("ami_enabled", true)
 	viper.SetDefault("ami_host", "127.0.0.1")
 	viper.SetDefault("ami_port", 5038)
@@ -142,15 +558,23 @@ func Load(configPath ...string) Config {
 	viper.SetDefault("ami_events", "on")
 	viper.SetDefault("ami_retry_interval", "15s")
 	viper.SetDefault("ami_retry_max", "60s")
+	viper.SetDefault("ami_tls", false)
+	viper.SetDefault("ami_tls_skip_verify", false)
+	viper.SetDefault("ami_tls_ca_file", "")
 	viper.SetDefault("ami_node_id", 0)
 	viper.SetDefault("disable_link_poller", false)
+	viper.SetDefault("poll_interval", "60s")
+	viper.SetDefault("poll_workers", 4)
 	viper.SetDefault("allow_anon_dashboard", true)
 	viper.SetDefault("title", "Allstar Nexus")
 	viper.SetDefault("subtitle", "")
+	viper.SetDefault("timezone", "")
+	viper.SetDefault("locale", "")
 
 	// Gamification defaults (low-activity hub configuration)
 	viper.SetDefault("gamification.enabled", false) // Disabled by default
 	viper.SetDefault("gamification.tally_interval_minutes", 30)
+	viper.SetDefault("gamification.backlog_threshold_hours", 24)
 	viper.SetDefault("gamification.rested_bonus.enabled", true)
 	viper.SetDefault("gamification.rested_bonus.accumulation_rate", 1.5)
 	viper.SetDefault("gamification.rested_bonus.max_hours", 336)
@@ -170,6 +594,116 @@ func Load(configPath ...string) Config {
 	// By default renown is enabled and each renown level requires 36,000 seconds (10 hours) of XP
 	viper.SetDefault("gamification.renown.enabled", true)
 	viper.SetDefault("gamification.renown.xp_per_level", 36000)
+	viper.SetDefault("gamification.opt_out_mode", "hide")
+
+	// Idle reminder defaults (disabled)
+	viper.SetDefault("idle_reminder.enabled", false)
+	viper.SetDefault("idle_reminder.idle_minutes", 60)
+	viper.SetDefault("idle_reminder.start_hour", 0)
+	viper.SetDefault("idle_reminder.end_hour", 0)
+	viper.SetDefault("idle_reminder.repeat", false)
+
+	// AMI SSH tunnel defaults (disabled until ami_ssh.host is set)
+	viper.SetDefault("ami_ssh.port", 22)
+	viper.SetDefault("ami_ssh.keepalive", "30s")
+
+	// AMI console defaults: read-only commands only (empty commands = built-in whitelist)
+	viper.SetDefault("ami_console.enabled", true)
+	viper.SetDefault("ami_console.max_output_bytes", 65536)
+	viper.SetDefault("ami_console.timeout", "10s")
+
+	// Announcement defaults (off): espeak-ng, played with rpt localplay
+	viper.SetDefault("announcements.enabled", false)
+	viper.SetDefault("announcements.engine", "espeak")
+	viper.SetDefault("announcements.sox_path", "sox")
+	viper.SetDefault("announcements.sounds_dir", "/var/lib/asterisk/sounds/nexus")
+	viper.SetDefault("announcements.play_command", "rpt localplay %d %s")
+	viper.SetDefault("announcements.max_chars", 500)
+	viper.SetDefault("announcements.check_interval", "15s")
+
+	// rpt.conf editing defaults (off)
+	viper.SetDefault("rpt_config.enabled", false)
+	viper.SetDefault("rpt_config.path", "/etc/asterisk/rpt.conf")
+	viper.SetDefault("rpt_config.reload_command", "rpt reload")
+	viper.SetDefault("node_actions.enabled", false)
+	viper.SetDefault("node_actions.actions", []string{"disconnect"})
+	viper.SetDefault("node_actions.connect_command", "rpt cmd %d ilink 3 %d")
+	viper.SetDefault("node_actions.disconnect_command", "rpt cmd %d ilink 1 %d")
+	viper.SetDefault("node_actions.rate_limit_rpm", 4)
+
+	// IAX2 monitoring defaults: poll every minute, alert after two failed polls
+	viper.SetDefault("iax_monitor.enabled", true)
+	viper.SetDefault("iax_monitor.interval", "1m")
+	viper.SetDefault("iax_monitor.lapse_polls", 2)
+	viper.SetDefault("asterisk_log.enabled", false)
+	viper.SetDefault("asterisk_log.path", "/var/log/asterisk/messages")
+	viper.SetDefault("asterisk_log.max_entries", 500)
+	viper.SetDefault("asterisk_log.alert_categories", []string{"telemetry_timeout", "registration_failure"})
+	viper.SetDefault("asterisk_log.alert_cooldown", "15m")
+	viper.SetDefault("node_health.enabled", false)
+	viper.SetDefault("node_health.method", "iax2")
+	viper.SetDefault("node_health.interval", "1m")
+	viper.SetDefault("node_health.count", 3)
+	viper.SetDefault("node_health.timeout", "2s")
+	viper.SetDefault("node_health.window", 10)
+	viper.SetDefault("node_health.degraded_loss", 20)
+	viper.SetDefault("node_health.degraded_rtt", "500ms")
+	viper.SetDefault("node_health.retention", "168h")
+	viper.SetDefault("link_quality.enabled", false)
+	viper.SetDefault("link_quality.interval", "1m")
+	viper.SetDefault("link_quality.retention", "168h")
+
+	// Parrot (audio test) mode defaults: app_rpt COP 21/22
+	viper.SetDefault("parrot.enable_command", "rpt cmd %d cop 21")
+	viper.SetDefault("parrot.disable_command", "rpt cmd %d cop 22")
+	viper.SetDefault("parrot.default_seconds", 120)
+	viper.SetDefault("parrot.max_seconds", 1800)
+
+	// Talker webhook defaults (off); quiet overnight
+	viper.SetDefault("talker_webhook.enabled", false)
+	viper.SetDefault("talker_webhook.min_duration", "5s")
+	viper.SetDefault("talker_webhook.cooldown", "10m")
+	viper.SetDefault("talker_webhook.quiet_start_hour", 23)
+	viper.SetDefault("talker_webhook.quiet_end_hour", 7)
+	viper.SetDefault("talker_webhook.digest", false)
+	viper.SetDefault("talker_webhook.digest_interval", "1h")
+	viper.SetDefault("talker_dedup.mode", "per_source")
+	viper.SetDefault("talker_dedup.window", "3s")
+
+	// Watchlist defaults: app_rpt ilink 3 connects in transceive mode
+	viper.SetDefault("watchlist.check_interval", "30s")
+	viper.SetDefault("watchlist.connect_command", "rpt cmd %d ilink 3 %d")
+	viper.SetDefault("watchlist.connect_cooldown", "10m")
+
+	// Telegram bot defaults: ilink 3 connects in transceive mode, ilink 1 disconnects
+	viper.SetDefault("telegram_bot.connect_command", "rpt cmd %d ilink 3 %d")
+	viper.SetDefault("telegram_bot.disconnect_command", "rpt cmd %d ilink 1 %d")
+
+	// AllStarLink web API fallback for nodes missing from astdb (off by default)
+	viper.SetDefault("node_lookup_fallback.enabled", false)
+	viper.SetDefault("node_lookup_fallback.url", "https://stats.allstarlink.org/api/stats/%d")
+	viper.SetDefault("node_lookup_fallback.cache_ttl", "6h")
+	viper.SetDefault("node_lookup_fallback.negative_ttl", "1h")
+	viper.SetDefault("node_lookup_fallback.requests_per_minute", 10)
+
+	// Topology history defaults
+	viper.SetDefault("topology_history.enabled", true)
+	viper.SetDefault("topology_history.interval", "1m")
+	viper.SetDefault("topology_history.keyframe_every", 60)
+	viper.SetDefault("topology_history.retention_days", 30)
+
+	// Clock sanity check defaults
+	viper.SetDefault("time_sync.enabled", true)
+	viper.SetDefault("time_sync.ntp_servers", []string{"pool.ntp.org"})
+	viper.SetDefault("time_sync.http_urls", []string{"https://www.google.com"})
+	viper.SetDefault("time_sync.interval_minutes", 60)
+	viper.SetDefault("time_sync.max_skew_seconds", 5)
+	viper.SetDefault("notifications.ntfy.server", "https://ntfy.sh")
+
+	// Privacy defaults preserve the historical behaviour (partial IP masking for non-admins)
+	viper.SetDefault("privacy.ip_masking", "partial")
+	viper.SetDefault("privacy.anon_show_callsigns", true)
+	viper.SetDefault("privacy.anon_talker_history", true)
 
 	// Config file search paths
 	if len(configPath) > 0 && configPath[0] != "" {
@@ -196,6 +730,16 @@ func Load(configPath ...string) Config {
 		}
 	} else {
 		log.Printf("Using config file: %s", viper.ConfigFileUsed())
+		// Re-read with includes merged and ${ENV} references expanded
+		tree, unset, err := configTree(viper.ConfigFileUsed())
+		if err != nil {
+			log.Printf("Error reading config file: %v", err)
+		} else if err := viper.MergeConfigMap(tree); err != nil {
+			log.Printf("Error merging config includes: %v", err)
+		}
+		for _, name := range unset {
+			log.Printf("warning: config references unset environment variable %s (using empty value)", name)
+		}
 	}
 
 	// Environment variables override config file
@@ -220,11 +764,14 @@ func Load(configPath ...string) Config {
 
 	// Build config struct
 	cfg := Config{
-		Port:                    viper.GetString("port"),
+		Port:                    normalizePort(viper.GetString("port")),
+		DataDir:                 viper.GetString("data_dir"),
 		DBPath:                  viper.GetString("db_path"),
+		DBBusyTimeout:           viper.GetDuration("db_busy_timeout"),
 		AstDBPath:               viper.GetString("astdb_path"),
 		AstDBURL:                viper.GetString("astdb_url"),
 		AstDBUpdateHours:        viper.GetInt("astdb_update_hours"),
+		AstDBServe:              viper.GetBool("astdb_serve"),
 		JWTSecret:               viper.GetString("jwt_secret"),
 		Env:                     viper.GetString("app_env"),
 		BuildTime:               viper.GetString("build_time"),
@@ -240,10 +787,24 @@ func Load(configPath ...string) Config {
 		AMIEvents:               viper.GetString("ami_events"),
 		AMIRetryInterval:        viper.GetDuration("ami_retry_interval"),
 		AMIRetryMax:             viper.GetDuration("ami_retry_max"),
+		AMITLS:                  viper.GetBool("ami_tls"),
+		AMITLSSkipVerify:        viper.GetBool("ami_tls_skip_verify"),
+		AMITLSCAFile:            viper.GetString("ami_tls_ca_file"),
 		DisableLinkPoller:       viper.GetBool("disable_link_poller"),
+		PollInterval:            viper.GetDuration("poll_interval"),
+		PollWorkers:             viper.GetInt("poll_workers"),
 		AllowAnonDashboard:      viper.GetBool("allow_anon_dashboard"),
 		Title:                   viper.GetString("title"),
 		Subtitle:                viper.GetString("subtitle"),
+		Timezone:                viper.GetString("timezone"),
+		Locale:                  viper.GetString("locale"),
+	}
+
+	// AMI over TLS conventionally listens on 5039; use it unless a port was given explicitly
+	if cfg.AMITLS && !viper.InConfig("ami_port") {
+		if _, ok := os.LookupEnv("AMI_PORT"); !ok {
+			cfg.AMIPort = 5039
+		}
 	}
 
 	// Load gamification configuration
@@ -251,6 +812,138 @@ func Load(configPath ...string) Config {
 		log.Printf("warning: failed to load gamification config: %v (using defaults)", err)
 	}
 
+	// Load per-route rate limit policies
+	if err := viper.UnmarshalKey("rate_limits", &cfg.RateLimits); err != nil {
+		log.Printf("warning: failed to load rate_limits config: %v (using defaults)", err)
+	}
+	if err := viper.UnmarshalKey("endpoint_access", &cfg.EndpointAccess); err != nil {
+		log.Printf("warning: failed to load endpoint_access config: %v (using defaults)", err)
+	}
+	if err := viper.UnmarshalKey("response_cache", &cfg.ResponseCache); err != nil {
+		log.Printf("warning: failed to load response_cache config: %v (using defaults)", err)
+	}
+	if err := viper.UnmarshalKey("widget", &cfg.Widget); err != nil {
+		log.Printf("warning: failed to load widget config: %v (using defaults)", err)
+	}
+	if err := viper.UnmarshalKey("activity_feed", &cfg.ActivityFeed); err != nil {
+		log.Printf("warning: failed to load activity_feed config: %v (using defaults)", err)
+	}
+
+	// Load idle reminder configuration
+	if err := viper.UnmarshalKey("idle_reminder", &cfg.IdleReminder); err != nil {
+		log.Printf("warning: failed to load idle_reminder config: %v (using defaults)", err)
+	}
+
+	// Load AMI SSH tunnel configuration
+	if err := viper.UnmarshalKey("ami_ssh", &cfg.AMISSH); err != nil {
+		log.Printf("warning: failed to load ami_ssh config: %v (using defaults)", err)
+	}
+
+	// Load AMI console configuration
+	if err := viper.UnmarshalKey("ami_console", &cfg.AMIConsole); err != nil {
+		log.Printf("warning: failed to load ami_console config: %v (using defaults)", err)
+	}
+
+	// Load announcements configuration
+	if err := viper.UnmarshalKey("announcements", &cfg.Announcements); err != nil {
+		log.Printf("warning: failed to load announcements config: %v (using defaults)", err)
+	}
+
+	// Load rpt.conf editing configuration
+	if err := viper.UnmarshalKey("rpt_config", &cfg.RptConfig); err != nil {
+		log.Printf("warning: failed to load rpt_config config: %v (using defaults)", err)
+	}
+	if err := viper.UnmarshalKey("node_actions", &cfg.NodeActions); err != nil {
+		log.Printf("warning: failed to load node_actions config: %v (using defaults)", err)
+	}
+
+	// Load hosted tenants (multi-tenant mode)
+	if err := viper.UnmarshalKey("tenants", &cfg.Tenants); err != nil {
+		log.Printf("warning: failed to load tenants config: %v (using defaults)", err)
+	}
+
+	// Load IAX2 monitoring configuration
+	if err := viper.UnmarshalKey("iax_monitor", &cfg.IAXMonitor); err != nil {
+		log.Printf("warning: failed to load iax_monitor config: %v (using defaults)", err)
+	}
+	if err := viper.UnmarshalKey("asterisk_log", &cfg.AsteriskLog); err != nil {
+		log.Printf("warning: failed to load asterisk_log config: %v (using defaults)", err)
+	}
+	if err := viper.UnmarshalKey("node_health", &cfg.NodeHealth); err != nil {
+		log.Printf("warning: failed to load node_health config: %v (using defaults)", err)
+	}
+	if err := viper.UnmarshalKey("link_quality", &cfg.LinkQuality); err != nil {
+		log.Printf("warning: failed to load link_quality config: %v (using defaults)", err)
+	}
+
+	// Load parrot mode configuration
+	if err := viper.UnmarshalKey("parrot", &cfg.Parrot); err != nil {
+		log.Printf("warning: failed to load parrot config: %v (using defaults)", err)
+	}
+
+	// Load talker webhook configuration
+	if err := viper.UnmarshalKey("talker_webhook", &cfg.TalkerWebhook); err != nil {
+		log.Printf("warning: failed to load talker_webhook config: %v (using defaults)", err)
+	}
+	if err := viper.UnmarshalKey("talker_dedup", &cfg.TalkerDedup); err != nil {
+		log.Printf("warning: failed to load talker_dedup config: %v (using defaults)", err)
+	}
+
+	// Load watchlist configuration
+	if err := viper.UnmarshalKey("watchlist", &cfg.Watchlist); err != nil {
+		log.Printf("warning: failed to load watchlist config: %v (using defaults)", err)
+	}
+
+	// Load supplementary astdb sources
+	if err := viper.UnmarshalKey("astdb_sources", &cfg.AstDBSources); err != nil {
+		log.Printf("warning: failed to load astdb_sources config: %v (using defaults)", err)
+	}
+
+	// Load private node registry seed
+	if err := viper.UnmarshalKey("local_nodes", &cfg.LocalNodes); err != nil {
+		log.Printf("warning: failed to load local_nodes config: %v", err)
+	}
+
+	// Load node lookup web fallback configuration
+	if err := viper.UnmarshalKey("node_lookup_fallback", &cfg.NodeLookupFallback); err != nil {
+		log.Printf("warning: failed to load node_lookup_fallback config: %v (using defaults)", err)
+	}
+
+	// Load topology history configuration
+	if err := viper.UnmarshalKey("topology_history", &cfg.TopologyHistory); err != nil {
+		log.Printf("warning: failed to load topology_history config: %v (using defaults)", err)
+	}
+
+	// Load clock sanity check configuration
+	if err := viper.UnmarshalKey("time_sync", &cfg.TimeSync); err != nil {
+		log.Printf("warning: failed to load time_sync config: %v (using defaults)", err)
+	}
+
+	// Load notification provider configuration
+	if err := viper.UnmarshalKey("notifications", &cfg.Notifications); err != nil {
+		log.Printf("warning: failed to load notifications config: %v (using defaults)", err)
+	}
+
+	// Load Discord bot configuration
+	if err := viper.UnmarshalKey("discord_bot", &cfg.DiscordBot); err != nil {
+		log.Printf("warning: failed to load discord_bot config: %v (using defaults)", err)
+	}
+
+	// Load Telegram bot configuration
+	if err := viper.UnmarshalKey("telegram_bot", &cfg.TelegramBot); err != nil {
+		log.Printf("warning: failed to load telegram_bot config: %v (using defaults)", err)
+	}
+
+	// Load branding configuration
+	if err := viper.UnmarshalKey("branding", &cfg.Branding); err != nil {
+		log.Printf("warning: failed to load branding config: %v (using defaults)", err)
+	}
+
+	// Load privacy configuration
+	if err := viper.UnmarshalKey("privacy", &cfg.Privacy); err != nil {
+		log.Printf("warning: failed to load privacy config: %v (using defaults)", err)
+	}
+
 	// Load nodes configuration - supports multiple formats:
 	// 1. Simple array of integers: nodes: [43732, 48412]
 	// 2. Array of objects with optional names: nodes: [{node_id: 43732, name: "My Node"}, {node_id: 48412}]
@@ -279,9 +972,15 @@ func Load(configPath ...string) Config {
 		}
 	}
 
-	// Ensure data directory exists
-	if err := os.MkdirAll(dirOf(cfg.DBPath), 0o755); err != nil {
-		log.Printf("warning: unable to create data dir: %v", err)
+	// Ensure data directory exists and can be written; on a read-only root filesystem it
+	// must be a mounted volume
+	resolveDataPaths(&cfg)
+	for _, dir := range []string{cfg.DataDir, dirOf(cfg.DBPath)} {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			log.Printf("warning: unable to create data dir: %v", err)
+		} else if err := dirWritable(dir); err != nil {
+			log.Printf("warning: data dir %s is not writable (read-only filesystem? mount a volume there): %v", dir, err)
+		}
 	}
 
 	// Validation
@@ -301,6 +1000,53 @@ func Load(configPath ...string) Config {
 	return cfg
 }
 
+// resolveDataPaths fills in the database and astdb paths that were not configured from
+// data_dir. Configs predating data_dir that set db_path keep their other files beside the
+// database.
+func resolveDataPaths(cfg *Config) {
+	if cfg.DataDir == "" {
+		cfg.DataDir = "data"
+		if cfg.DBPath != "" {
+			cfg.DataDir = dirOf(cfg.DBPath)
+		}
+	}
+	if cfg.DBPath == "" {
+		cfg.DBPath = filepath.Join(cfg.DataDir, "allstar.db")
+	}
+	if cfg.AstDBPath == "" {
+		cfg.AstDBPath = filepath.Join(cfg.DataDir, "astdb.txt")
+	}
+}
+
+// dirWritable checks that a file can be created in dir.
+func dirWritable(dir string) error {
+	f, err := os.CreateTemp(dir, ".nexus-write-check-*")
+	if err != nil {
+		return err
+	}
+	name := f.Name()
+	_ = f.Close()
+	return os.Remove(name)
+}
+
+// normalizePort accepts the listen port (PORT / port) as a bare number, ":8080", or a
+// service link such as "tcp://10.0.0.1:8080" as injected by Kubernetes and Docker links.
+// Anything else falls back to 8080.
+func normalizePort(v string) string {
+	v = strings.TrimSpace(v)
+	if v == "" {
+		return "8080"
+	}
+	if i := strings.LastIndex(v, ":"); i >= 0 {
+		v = v[i+1:]
+	}
+	if p, err := strconv.Atoi(v); err != nil || p < 1 || p > 65535 {
+		log.Printf("warning: invalid port %q (using 8080)", v)
+		return "8080"
+	}
+	return v
+}
+
 func dirOf(path string) string {
 	for i := len(path) - 1; i >= 0; i-- {
 		if path[i] == '/' {
@@ -333,43 +1079,17 @@ func Validate(configPath ...string) error {
 		return fmt.Errorf("failed to read config: %w", err)
 	}
 
-	// Extra lint: detect tabs used for indentation (YAML forbids tab indentation)
+	// Includes and ${ENV} references; also rejects tab indentation in every file
 	used := v.ConfigFileUsed()
 	if used == "" {
 		return fmt.Errorf("unexpected: viper did not report a ConfigFileUsed after successful read")
 	}
-	data, err := os.ReadFile(used)
+	tree, _, err := configTree(used)
 	if err != nil {
-		return fmt.Errorf("unable to read config file for linting: %w", err)
-	}
-	scanner := bufio.NewScanner(bytes.NewReader(data))
-	lineNum := 0
-	for scanner.Scan() {
-		lineNum++
-		line := scanner.Text()
-		// Extract leading whitespace
-		i := 0
-		for i < len(line) {
-			if line[i] == ' ' || line[i] == '\t' {
-				i++
-				continue
-			}
-			break
-		}
-		if i > 0 {
-			// If any of the leading whitespace characters are tabs, flag as error
-			if strings.Contains(line[:i], "\t") {
-				// Show a short preview of the offending line (without tabs)
-				preview := strings.ReplaceAll(line, "\t", "[TAB]")
-				if len(preview) > 120 {
-					preview = preview[:120] + "…"
-				}
-				return fmt.Errorf("invalid YAML indentation: tabs detected at line %d. YAML requires spaces for indentation. Offending line: %q", lineNum, preview)
-			}
-		}
+		return fmt.Errorf("failed to read config: %w", err)
 	}
-	if err := scanner.Err(); err != nil {
-		return fmt.Errorf("error scanning config file: %w", err)
+	if err := v.MergeConfigMap(tree); err != nil {
+		return fmt.Errorf("failed to merge config includes: %w", err)
 	}
 
 	// Basic structural checks: attempt to unmarshal known sections
@@ -420,6 +1140,12 @@ db_path: data/allstar.db
 astdb_path: data/astdb.txt
 astdb_url: http://allmondb.allstarlink.org/
 astdb_update_hours: 24
+astdb_serve: true  # serve the cached copy at /astdb.txt for other LAN tools
+# astdb_sources:  # merged over astdb_url on import, first entry wins
+#   - name: hub
+#     url: https://hub.example.org/astdb-private.txt
+#   - name: local
+#     path: data/astdb-local.txt
 
 # Security
 jwt_secret: change-me-in-production
@@ -462,6 +1188,7 @@ allow_anon_dashboard: true
 gamification:
 	enabled: false            # Set to true to enable the gamification system
 	tally_interval_minutes: 30
+	backlog_threshold_hours: 24 # after longer downtime, catch up in the background
 
 	# Rested XP Bonus
 	rested_bonus:
@@ -53,3 +53,157 @@ func TestValidate_MalformedNodes(t *testing.T) {
 		t.Fatalf("expected error for malformed nodes section, but got nil")
 	}
 }
+
+func TestConfigTree_IncludesAndEnv(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"config.yaml":              "include: [nodes.yaml, conf.d/*.yaml]\nami_password: ${TEST_NEXUS_AMI_PASSWORD}\njwt_secret: ${TEST_NEXUS_UNSET}\nport: ${TEST_NEXUS_PORT:-8080}\ntitle: \"$${LITERAL}\"\ngamification:\n  enabled: true\n",
+		"nodes.yaml":               "nodes: [43732]\nport: 9000\n",
+		"conf.d/gamification.yaml": "gamification:\n  enabled: false\n  tally_interval_minutes: 15\n",
+	}
+	for name, content := range files {
+		_ = os.MkdirAll(filepath.Dir(filepath.Join(dir, name)), 0o755)
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Setenv("TEST_NEXUS_AMI_PASSWORD", "s3cret")
+
+	tree, unset, err := configTree(filepath.Join(dir, "config.yaml"))
+	if err != nil {
+		t.Fatalf("configTree: %v", err)
+	}
+	if tree["ami_password"] != "s3cret" || tree["jwt_secret"] != "" || tree["title"] != "${LITERAL}" {
+		t.Fatalf("interpolation: %v", tree)
+	}
+	// the including file wins over its includes
+	if tree["port"] != "8080" || tree["include"] != nil {
+		t.Fatalf("port/include: %v", tree)
+	}
+	gam := tree["gamification"].(map[string]any)
+	if gam["enabled"] != true || gam["tally_interval_minutes"] != 15 {
+		t.Fatalf("nested merge: %v", gam)
+	}
+	if nodes, _ := tree["nodes"].([]any); len(nodes) != 1 {
+		t.Fatalf("nodes: %v", tree["nodes"])
+	}
+	if len(unset) != 1 || unset[0] != "TEST_NEXUS_UNSET" {
+		t.Fatalf("unset: %v", unset)
+	}
+	if err := Validate(filepath.Join(dir, "config.yaml")); err != nil {
+		t.Fatalf("validate: %v", err)
+	}
+}
+
+func TestValidate_BadIncludes(t *testing.T) {
+	missing := writeTempConfig(t, "missing.yaml", "include: nodes.yaml\n")
+	if err := Validate(missing); err == nil {
+		t.Fatal("expected error for a missing include")
+	}
+	dir := t.TempDir()
+	_ = os.WriteFile(filepath.Join(dir, "a.yaml"), []byte("include: b.yaml\n"), 0o644)
+	_ = os.WriteFile(filepath.Join(dir, "b.yaml"), []byte("include: a.yaml\n"), 0o644)
+	if err := Validate(filepath.Join(dir, "a.yaml")); err == nil {
+		t.Fatal("expected error for an include cycle")
+	}
+	_ = os.WriteFile(filepath.Join(dir, "c.yaml"), []byte("include: tabs.yaml\n"), 0o644)
+	_ = os.WriteFile(filepath.Join(dir, "tabs.yaml"), []byte("gamification:\n\tenabled: true\n"), 0o644)
+	if err := Validate(filepath.Join(dir, "c.yaml")); err == nil {
+		t.Fatal("expected error for tabs in an included file")
+	}
+}
+
+func TestLint_ReportsSemanticProblems(t *testing.T) {
+	cfg := Config{
+		Port:          "99999",
+		DBPath:        "data/allstar.db",
+		JWTSecret:     "a-sufficiently-long-random-secret",
+		TokenTTL:      1,
+		AMIEnabled:    true,
+		AMIHost:       "127.0.0.1",
+		AMIPort:       5038,
+		AMIUser:       "admin",
+		AMIPassword:   "secret",
+		Nodes:         []NodeConfig{{NodeID: 43732}, {NodeID: 43732}},
+		LocalNodes:    []LocalNodeConfig{{Node: 1999, Callsign: "K8FBI"}, {Node: 1999, Callsign: "K8FBI"}},
+		Watchlist:     WatchlistConfig{Notify: []string{"telegram"}},
+		TimeSync:      TimeSyncConfig{Notify: []string{"ntfy"}},
+		Notifications: NotificationsConfig{Ntfy: NtfyConfig{Topic: "hub"}},
+		Gamification: GamificationConfig{
+			Enabled:              true,
+			TallyIntervalMinutes: 30,
+			DiminishingReturns: DiminishingReturnsConfig{Tiers: []DRTier{
+				{MaxSeconds: 2400, Multiplier: 1.0},
+				{MaxSeconds: 1200, Multiplier: 0.5},
+			}},
+		},
+	}
+	fields := map[string]bool{}
+	for _, is := range Lint(cfg) {
+		if is.Severity == SeverityError {
+			fields[is.Field] = true
+		}
+	}
+	for _, want := range []string{"port", "nodes[1]", "local_nodes[1]", "watchlist.notify", "gamification.diminishing_returns.tiers[1]"} {
+		if !fields[want] {
+			t.Errorf("expected error for %s, got %v", want, fields)
+		}
+	}
+	if len(fields) != 5 {
+		t.Errorf("unexpected extra errors: %v", fields)
+	}
+}
+
+func TestRenderConfig_ProducesValidConfig(t *testing.T) {
+	out := RenderConfig(SetupAnswers{
+		Title:               `Hub "Alpha"`,
+		Port:                "8080",
+		DBPath:              "data/allstar.db",
+		JWTSecret:           "0123456789abcdef0123456789abcdef",
+		AMIEnabled:          true,
+		AMIHost:             "127.0.0.1",
+		AMIPort:             5038,
+		AMIUser:             "admin",
+		AMIPassword:         "s3cr3t",
+		Nodes:               []int{43732, 48412},
+		AllowAnonDashboard:  true,
+		GamificationEnabled: true,
+	})
+	p := writeTempConfig(t, "rendered.yaml", out)
+	if err := Validate(p); err != nil {
+		t.Fatalf("rendered config failed validation: %v\n%s", err, out)
+	}
+}
+
+func TestResolveDataPathsAndPort(t *testing.T) {
+	cases := []struct {
+		in                Config
+		dir, db, astdbTxt string
+	}{
+		{Config{}, "data", "data/allstar.db", "data/astdb.txt"},
+		{Config{DataDir: "/var/lib/nexus"}, "/var/lib/nexus", "/var/lib/nexus/allstar.db", "/var/lib/nexus/astdb.txt"},
+		// configs predating data_dir keep everything beside the database
+		{Config{DBPath: "/srv/nexus/app.db"}, "/srv/nexus", "/srv/nexus/app.db", "/srv/nexus/astdb.txt"},
+		{Config{DataDir: "/data", AstDBPath: "/cache/astdb.txt"}, "/data", "/data/allstar.db", "/cache/astdb.txt"},
+	}
+	for _, tc := range cases {
+		cfg := tc.in
+		resolveDataPaths(&cfg)
+		if cfg.DataDir != tc.dir || cfg.DBPath != tc.db || cfg.AstDBPath != tc.astdbTxt {
+			t.Errorf("%+v: got %q %q %q", tc.in, cfg.DataDir, cfg.DBPath, cfg.AstDBPath)
+		}
+	}
+
+	for in, want := range map[string]string{
+		"":                     "8080",
+		"9090":                 "9090",
+		":9090":                "9090",
+		"tcp://10.0.0.7:18080": "18080",
+		"http":                 "8080",
+		"70000":                "8080",
+	} {
+		if got := normalizePort(in); got != want {
+			t.Errorf("normalizePort(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,197 @@
+package config
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// includeKey is the top-level key listing files merged into a config file, e.g.
+//
+//	include: [nodes.yaml, secrets.yaml, conf.d/*.yaml]
+//
+// Paths are relative to the including file and may be globs. Files are merged in order
+// and the including file's own values win over its includes; lists are replaced, not
+// appended. Included files may include further files.
+const includeKey = "include"
+
+// envRef matches ${VAR} and ${VAR:-default}; $${VAR} is left as a literal ${VAR}.
+var envRef = regexp.MustCompile(`\$?\$\{([A-Za-z_][A-Za-z0-9_]*)(?::-([^}]*))?\}`)
+
+// configTree reads path and its includes, interpolating environment references in
+// string values. It returns the merged settings and the referenced variables that are
+// unset and have no default (they expand to "").
+func configTree(path string) (map[string]any, []string, error) {
+	r := &treeReader{unset: map[string]bool{}}
+	tree, err := r.read(path, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	unset := make([]string, 0, len(r.unset))
+	for name := range r.unset {
+		unset = append(unset, name)
+	}
+	sort.Strings(unset)
+	return tree, unset, nil
+}
+
+type treeReader struct {
+	unset map[string]bool
+}
+
+func (r *treeReader) read(path string, stack []string) (map[string]any, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range stack {
+		if p == abs {
+			return nil, fmt.Errorf("include cycle: %s", strings.Join(append(stack, abs), " -> "))
+		}
+	}
+	stack = append(stack, abs)
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkIndentation(data); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	doc := map[string]any{}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	doc, _ = r.expand(doc).(map[string]any)
+
+	includes, err := includeList(doc[includeKey])
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	delete(doc, includeKey)
+
+	merged := map[string]any{}
+	for _, inc := range includes {
+		if !filepath.IsAbs(inc) {
+			inc = filepath.Join(filepath.Dir(path), inc)
+		}
+		files := []string{inc}
+		if strings.ContainsAny(inc, "*?[") {
+			if files, err = filepath.Glob(inc); err != nil {
+				return nil, fmt.Errorf("%s: include %q: %w", path, inc, err)
+			}
+		}
+		for _, f := range files {
+			sub, err := r.read(f, stack)
+			if err != nil {
+				return nil, fmt.Errorf("%s: include: %w", path, err)
+			}
+			mergeTree(merged, sub)
+		}
+	}
+	mergeTree(merged, doc)
+	return merged, nil
+}
+
+// expand interpolates environment references in every string value and lower-cases
+// map keys so files merge the same way viper looks keys up.
+func (r *treeReader) expand(v any) any {
+	switch t := v.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(t))
+		for k, val := range t {
+			out[strings.ToLower(k)] = r.expand(val)
+		}
+		return out
+	case []any:
+		for i, val := range t {
+			t[i] = r.expand(val)
+		}
+		return t
+	case string:
+		return envRef.ReplaceAllStringFunc(t, func(ref string) string {
+			if strings.HasPrefix(ref, "$$") {
+				return ref[1:]
+			}
+			m := envRef.FindStringSubmatch(ref)
+			if val, ok := os.LookupEnv(m[1]); ok {
+				return val
+			}
+			if strings.Contains(ref, ":-") {
+				return m[2]
+			}
+			r.unset[m[1]] = true
+			return ""
+		})
+	default:
+		return v
+	}
+}
+
+func includeList(v any) ([]string, error) {
+	switch t := v.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		return []string{t}, nil
+	case []any:
+		out := make([]string, 0, len(t))
+		for _, item := range t {
+			s, ok := item.(string)
+			if !ok || s == "" {
+				return nil, fmt.Errorf("include entries must be file paths, got %v", item)
+			}
+			out = append(out, s)
+		}
+		return out, nil
+	default:
+		return nil, fmt.Errorf("include must be a path or a list of paths, got %T", v)
+	}
+}
+
+// mergeTree merges src into dst; nested maps merge, anything else in src replaces.
+func mergeTree(dst, src map[string]any) {
+	for k, v := range src {
+		if sm, ok := v.(map[string]any); ok {
+			if dm, ok := dst[k].(map[string]any); ok {
+				mergeTree(dm, sm)
+				continue
+			}
+		}
+		dst[k] = v
+	}
+}
+
+// checkIndentation rejects tabs in leading whitespace, which YAML forbids.
+func checkIndentation(data []byte) error {
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := scanner.Text()
+		// Extract leading whitespace
+		i := 0
+		for i < len(line) && (line[i] == ' ' || line[i] == '\t') {
+			i++
+		}
+		if strings.Contains(line[:i], "\t") {
+			// Show a short preview of the offending line (without tabs)
+			preview := strings.ReplaceAll(line, "\t", "[TAB]")
+			if len(preview) > 120 {
+				preview = preview[:120] + "…"
+			}
+			return fmt.Errorf("invalid YAML indentation: tabs detected at line %d. YAML requires spaces for indentation. Offending line: %q", lineNum, preview)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("error scanning config file: %w", err)
+	}
+	return nil
+}
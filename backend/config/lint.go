@@ -0,0 +1,582 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Severity of a configuration issue reported by Lint.
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+)
+
+// Issue describes a semantic problem with a loaded configuration.
+type Issue struct {
+	Severity Severity
+	Field    string
+	Message  string
+}
+
+func (i Issue) String() string {
+	return fmt.Sprintf("%s: %s", i.Field, i.Message)
+}
+
+// insecureSecrets are the placeholder JWT secrets shipped in defaults and examples.
+var insecureSecrets = map[string]bool{
+	"":                        true,
+	"dev-secret-change-me":    true,
+	"change-me-in-production": true,
+}
+
+// Lint performs semantic checks on a loaded configuration that Validate (syntax only)
+// cannot catch: value ranges, required fields, node IDs and gamification tier ordering.
+func Lint(cfg Config) []Issue {
+	var issues []Issue
+	errorf := func(field, format string, args ...any) {
+		issues = append(issues, Issue{SeverityError, field, fmt.Sprintf(format, args...)})
+	}
+	warnf := func(field, format string, args ...any) {
+		issues = append(issues, Issue{SeverityWarning, field, fmt.Sprintf(format, args...)})
+	}
+
+	if p, err := strconv.Atoi(cfg.Port); err != nil || p < 1 || p > 65535 {
+		errorf("port", "must be a number between 1 and 65535, got %q", cfg.Port)
+	}
+	if cfg.DBPath == "" {
+		errorf("db_path", "is required")
+	}
+	if insecureSecrets[cfg.JWTSecret] {
+		if cfg.Env == "production" {
+			errorf("jwt_secret", "placeholder secret in use; set a long random value")
+		} else {
+			warnf("jwt_secret", "placeholder secret in use; set a long random value before exposing the server")
+		}
+	} else if len(cfg.JWTSecret) < 16 {
+		warnf("jwt_secret", "is short (%d chars); use at least 32 random characters", len(cfg.JWTSecret))
+	}
+	if cfg.TokenTTL <= 0 {
+		errorf("token_ttl_seconds", "must be positive")
+	}
+	if cfg.Timezone != "" {
+		if _, err := time.LoadLocation(cfg.Timezone); err != nil {
+			errorf("timezone", "unknown timezone %q", cfg.Timezone)
+		}
+	}
+	if cfg.Locale != "" && !localeTag.MatchString(cfg.Locale) {
+		errorf("locale", "%q is not a language tag like \"en-US\"", cfg.Locale)
+	}
+
+	// AMI
+	if cfg.AMIEnabled {
+		if cfg.AMIHost == "" {
+			errorf("ami_host", "is required when ami_enabled is true")
+		}
+		if cfg.AMIPort < 1 || cfg.AMIPort > 65535 {
+			errorf("ami_port", "must be between 1 and 65535, got %d", cfg.AMIPort)
+		}
+		if cfg.AMIUser == "" {
+			errorf("ami_username", "is required when ami_enabled is true")
+		}
+		if cfg.AMIPassword == "" || cfg.AMIPassword == "change-me" {
+			warnf("ami_password", "looks like a placeholder; it must match manager.conf")
+		}
+		if cfg.AMITLS {
+			if cfg.AMITLSSkipVerify {
+				warnf("ami_tls_skip_verify", "certificate verification is disabled; use ami_tls_ca_file instead")
+			}
+			if cfg.AMITLSCAFile != "" {
+				if _, err := os.Stat(cfg.AMITLSCAFile); err != nil {
+					errorf("ami_tls_ca_file", "cannot read %s: %v", cfg.AMITLSCAFile, err)
+				}
+			}
+		} else if cfg.AMITLSSkipVerify || cfg.AMITLSCAFile != "" {
+			warnf("ami_tls", "TLS options are set but ami_tls is false; they will be ignored")
+		}
+		if s := cfg.AMISSH; s.Host != "" {
+			if s.User == "" {
+				errorf("ami_ssh.user", "is required when ami_ssh.host is set")
+			}
+			if s.KeyFile == "" {
+				errorf("ami_ssh.key_file", "is required when ami_ssh.host is set")
+			} else if _, err := os.Stat(s.KeyFile); err != nil {
+				errorf("ami_ssh.key_file", "cannot read %s: %v", s.KeyFile, err)
+			}
+			if s.Port < 1 || s.Port > 65535 {
+				errorf("ami_ssh.port", "must be between 1 and 65535, got %d", s.Port)
+			}
+			if s.InsecureIgnoreHostKey {
+				warnf("ami_ssh.insecure_ignore_host_key", "SSH host key verification is disabled; use ami_ssh.known_hosts instead")
+			}
+		}
+	}
+
+	// Nodes
+	if len(cfg.Nodes) == 0 {
+		warnf("nodes", "no nodes configured; COS/PTT and link mode features will be unavailable")
+	}
+	seen := map[int]bool{}
+	for i, n := range cfg.Nodes {
+		field := fmt.Sprintf("nodes[%d]", i)
+		if n.NodeID <= 0 {
+			errorf(field, "node_id must be a positive AllStar node number, got %d", n.NodeID)
+			continue
+		}
+		if seen[n.NodeID] {
+			errorf(field, "duplicate node_id %d", n.NodeID)
+		}
+		seen[n.NodeID] = true
+		if n.PollInterval != 0 && n.PollInterval < 5*time.Second {
+			errorf(field, "poll_interval must be at least 5s, got %s", n.PollInterval)
+		}
+	}
+	if !cfg.DisableLinkPoller {
+		if cfg.PollInterval != 0 && cfg.PollInterval < 5*time.Second {
+			errorf("poll_interval", "must be at least 5s, got %s", cfg.PollInterval)
+		}
+		if cfg.PollWorkers < 0 {
+			errorf("poll_workers", "must not be negative, got %d", cfg.PollWorkers)
+		}
+	}
+
+	if cfg.Gamification.Enabled {
+		issues = append(issues, lintGamification(cfg.Gamification)...)
+	}
+
+	// Per-endpoint access levels
+	for route, level := range cfg.EndpointAccess {
+		field := "endpoint_access." + route
+		if !strings.HasPrefix(route, "/") {
+			errorf(field, "route must be a path starting with /")
+		}
+		switch level {
+		case "anonymous", "user", "admin":
+		default:
+			errorf(field, "must be anonymous, user or admin, got %q (the endpoint is admin-only until fixed)", level)
+		}
+	}
+
+	// Per-route rate limits
+	for route, p := range cfg.RateLimits.Routes {
+		field := "rate_limits.routes." + route
+		if !strings.HasPrefix(route, "/") {
+			errorf(field, "route must be a path starting with /")
+		}
+		switch p.KeyBy {
+		case "", "ip", "token", "api_key":
+		default:
+			errorf(field, "key_by must be ip, token or api_key, got %q", p.KeyBy)
+		}
+		if p.RequestsPerMinute < 0 || p.Burst < 0 {
+			errorf(field, "rpm and burst must not be negative")
+		}
+	}
+
+	if cfg.ResponseCache.Enabled {
+		if cfg.ResponseCache.MaxEntries < 0 {
+			errorf("response_cache.max_entries", "must not be negative, got %d", cfg.ResponseCache.MaxEntries)
+		}
+		for route, ttl := range cfg.ResponseCache.Routes {
+			field := "response_cache.routes." + route
+			if !strings.HasPrefix(route, "/") {
+				errorf(field, "route must be a path starting with /")
+			}
+			if ttl < 0 {
+				errorf(field, "ttl must not be negative, got %s", ttl)
+			}
+		}
+	}
+
+	if cfg.Widget.Enabled {
+		if cfg.Widget.RefreshSeconds < 5 {
+			warnf("widget.refresh_seconds", "%d is below the 5 second minimum and will be raised", cfg.Widget.RefreshSeconds)
+		}
+		if cfg.Widget.RPM < 0 {
+			errorf("widget.rpm", "must not be negative, got %d", cfg.Widget.RPM)
+		}
+	}
+
+	if af := cfg.ActivityFeed; af.Enabled {
+		for _, kind := range af.Include {
+			switch kind {
+			case "qso", "net", "level_up", "long_tx":
+			default:
+				errorf("activity_feed.include", "unknown kind %q (want qso, net, level_up or long_tx)", kind)
+			}
+		}
+		if af.MaxEntries < 0 || af.QSOMinOvers < 0 || af.NetMinParticipants < 0 {
+			errorf("activity_feed", "max_entries, qso_min_overs and net_min_participants must not be negative")
+		}
+		if af.LongTx < 0 || af.SessionGap < 0 {
+			errorf("activity_feed", "long_tx and session_gap must not be negative")
+		}
+		if af.NetMinParticipants > 0 && af.NetMinParticipants < 3 {
+			warnf("activity_feed.net_min_participants", "%d is low; most QSOs would be listed as nets", af.NetMinParticipants)
+		}
+	}
+
+	if cfg.IdleReminder.Enabled {
+		if cfg.IdleReminder.IdleMinutes <= 0 {
+			errorf("idle_reminder.idle_minutes", "must be positive")
+		}
+		if cfg.IdleReminder.AMICommand == "" && cfg.IdleReminder.WebhookURL == "" && len(cfg.IdleReminder.Notify) == 0 {
+			warnf("idle_reminder", "enabled but none of ami_command, webhook_url or notify is set")
+		}
+		if !validHour(cfg.IdleReminder.StartHour) || !validHour(cfg.IdleReminder.EndHour) {
+			errorf("idle_reminder", "start_hour and end_hour must be 0-23")
+		}
+	}
+	if tw := cfg.TalkerWebhook; tw.Enabled {
+		if tw.WebhookURL == "" && len(tw.Notify) == 0 {
+			errorf("talker_webhook", "webhook_url or notify is required when talker_webhook is enabled")
+		}
+		if !validHour(tw.QuietStartHour) || !validHour(tw.QuietEndHour) {
+			errorf("talker_webhook", "quiet_start_hour and quiet_end_hour must be 0-23")
+		}
+		if tw.MinDuration < 0 || tw.Cooldown < 0 || tw.DigestInterval < 0 {
+			errorf("talker_webhook", "min_duration, cooldown and digest_interval must not be negative")
+		}
+	}
+	if td := cfg.TalkerDedup; td.Mode != "" && td.Mode != "per_source" && td.Mode != "deduplicated" {
+		errorf("talker_dedup.mode", "must be per_source or deduplicated, got %q", td.Mode)
+	} else if td.Window < 0 {
+		errorf("talker_dedup.window", "must not be negative")
+	}
+	if ac := cfg.AMIConsole; ac.Enabled {
+		if ac.MaxOutputBytes < 0 || ac.Timeout < 0 {
+			errorf("ami_console", "max_output_bytes and timeout must not be negative")
+		}
+		for i, c := range ac.Commands {
+			if strings.TrimSpace(c) == "" || strings.ContainsAny(c, "\r\n") {
+				errorf(fmt.Sprintf("ami_console.commands[%d]", i), "must be a single non-empty command")
+			}
+		}
+	}
+	if an := cfg.Announcements; an.Enabled {
+		switch strings.ToLower(an.Engine) {
+		case "", "espeak":
+		case "piper":
+			if an.Voice == "" {
+				errorf("announcements.voice", "piper needs the path to a voice model (.onnx)")
+			}
+		default:
+			errorf("announcements.engine", "must be espeak or piper, got %q", an.Engine)
+		}
+		if cmd := an.PlayCommand; cmd != "" && (strings.Count(cmd, "%d") != 1 || strings.Count(cmd, "%s") != 1) {
+			errorf("announcements.play_command", "must contain one %%d (node) and one %%s (sound file), got %q", cmd)
+		}
+		if an.MaxChars < 0 || an.CheckInterval < 0 {
+			errorf("announcements", "max_chars and check_interval must not be negative")
+		}
+		if an.SoundsDir == "" {
+			errorf("announcements.sounds_dir", "required when announcements are enabled")
+		}
+		if len(cfg.Nodes) == 0 {
+			warnf("announcements", "enabled but no nodes configured; nothing can be announced")
+		}
+	}
+	if rc := cfg.RptConfig; rc.Enabled {
+		if strings.TrimSpace(rc.Path) == "" {
+			errorf("rpt_config.path", "is required when rpt_config is enabled")
+		}
+		if len(cfg.Nodes) == 0 {
+			warnf("rpt_config", "enabled but no nodes configured; nothing can be edited")
+		}
+	}
+	if na := cfg.NodeActions; na.Enabled {
+		for _, action := range na.Actions {
+			if action != "disconnect" && action != "connect" {
+				errorf("node_actions.actions", "unknown action %q (want disconnect or connect)", action)
+			}
+		}
+		if cmd := na.ConnectCommand; cmd != "" && strings.Count(cmd, "%d") != 2 {
+			errorf("node_actions.connect_command", "must contain two %%d verbs (hub node, user's node), got %q", cmd)
+		}
+		if cmd := na.DisconnectCommand; cmd != "" && strings.Count(cmd, "%d") != 2 {
+			errorf("node_actions.disconnect_command", "must contain two %%d verbs (hub node, user's node), got %q", cmd)
+		}
+		if na.RateLimitRPM < 0 {
+			errorf("node_actions.rate_limit_rpm", "must not be negative")
+		}
+	}
+	issues = append(issues, lintTenants(cfg)...)
+	if im := cfg.IAXMonitor; im.Enabled && (im.Interval < 0 || im.LapsePolls < 0) {
+		errorf("iax_monitor", "interval and lapse_polls must not be negative")
+	}
+	if al := cfg.AsteriskLog; al.Enabled {
+		if strings.TrimSpace(al.Path) == "" {
+			errorf("asterisk_log.path", "is required when asterisk_log is enabled")
+		}
+		if al.MaxEntries < 0 || al.AlertCooldown < 0 {
+			errorf("asterisk_log", "max_entries and alert_cooldown must not be negative")
+		}
+		for i, r := range al.Rules {
+			field := fmt.Sprintf("asterisk_log.rules[%d]", i)
+			if strings.TrimSpace(r.Category) == "" {
+				errorf(field, "category is required")
+			}
+			if _, err := regexp.Compile(r.Pattern); err != nil {
+				errorf(field, "invalid pattern: %v", err)
+			}
+		}
+		if len(al.AlertCategories) > 0 && al.WebhookURL == "" && len(al.Notify) == 0 {
+			warnf("asterisk_log", "alert_categories set but neither webhook_url nor notify; alerts only reach admin dashboards")
+		}
+	}
+	if nh := cfg.NodeHealth; nh.Enabled {
+		if nh.Method != "iax2" && nh.Method != "icmp" {
+			errorf("node_health.method", "must be iax2 or icmp, got %q", nh.Method)
+		}
+		if nh.Interval < 0 || nh.Timeout < 0 || nh.DegradedRTT < 0 || nh.Retention < 0 {
+			errorf("node_health", "interval, timeout, degraded_rtt and retention must not be negative")
+		}
+		if nh.Count < 0 || nh.Window < 0 || nh.DegradedLoss < 0 || nh.DegradedLoss > 100 {
+			errorf("node_health", "count and window must not be negative and degraded_loss must be 0-100")
+		}
+		if nh.Interval > 0 && time.Duration(max(nh.Count, 1))*nh.Timeout >= nh.Interval {
+			warnf("node_health", "count x timeout (%s) reaches the interval (%s); rounds may overlap", time.Duration(nh.Count)*nh.Timeout, nh.Interval)
+		}
+	}
+	if lq := cfg.LinkQuality; lq.Enabled {
+		if lq.Interval < 0 || lq.Retention < 0 {
+			errorf("link_quality", "interval and retention must not be negative")
+		}
+		if len(cfg.Nodes) == 0 {
+			warnf("link_quality", "enabled but no nodes configured; nothing will be sampled")
+		}
+	}
+	if cfg.Parrot.MaxSeconds > 0 && cfg.Parrot.DefaultSeconds > cfg.Parrot.MaxSeconds {
+		errorf("parrot.default_seconds", "exceeds max_seconds (%d > %d)", cfg.Parrot.DefaultSeconds, cfg.Parrot.MaxSeconds)
+	}
+	if cmd := cfg.Watchlist.ConnectCommand; cmd != "" && strings.Count(cmd, "%d") != 2 {
+		errorf("watchlist.connect_command", "must contain two %%d verbs (local node, remote node), got %q", cmd)
+	}
+	if cfg.Watchlist.CheckInterval < 0 || cfg.Watchlist.ConnectCooldown < 0 {
+		errorf("watchlist", "check_interval and connect_cooldown must not be negative")
+	}
+	configured := make(map[string]bool)
+	for _, name := range cfg.Notifications.Configured() {
+		configured[name] = true
+	}
+	for _, rule := range []struct {
+		field string
+		names []string
+	}{
+		{"idle_reminder.notify", cfg.IdleReminder.Notify},
+		{"talker_webhook.notify", cfg.TalkerWebhook.Notify},
+		{"watchlist.notify", cfg.Watchlist.Notify},
+		{"time_sync.notify", cfg.TimeSync.Notify},
+		{"iax_monitor.notify", cfg.IAXMonitor.Notify},
+		{"asterisk_log.notify", cfg.AsteriskLog.Notify},
+	} {
+		for _, name := range rule.names {
+			switch name {
+			case "discord", "pushover", "telegram", "ntfy":
+				if !configured[name] {
+					errorf(rule.field, "%s is not configured under notifications.%s", name, name)
+				}
+			default:
+				errorf(rule.field, "unknown provider %q (want discord, pushover, telegram or ntfy)", name)
+			}
+		}
+	}
+	if p := cfg.Notifications.Pushover; (p.Token == "") != (p.User == "") {
+		errorf("notifications.pushover", "token and user must be set together")
+	}
+	if t := cfg.Notifications.Telegram; (t.BotToken == "") != (t.ChatID == "") {
+		errorf("notifications.telegram", "bot_token and chat_id must be set together")
+	}
+	if d := cfg.DiscordBot; d.Enabled {
+		if d.ApplicationID == "" || d.PublicKey == "" {
+			errorf("discord_bot", "application_id and public_key are required")
+		}
+		if d.BotToken == "" {
+			warnf("discord_bot.bot_token", "not set; slash commands must be registered some other way")
+		}
+	}
+	if tg := cfg.TelegramBot; tg.Enabled {
+		if tg.BotToken == "" {
+			errorf("telegram_bot.bot_token", "required when the Telegram bot is enabled")
+		}
+		for _, kind := range tg.Alerts {
+			switch kind {
+			case "qso", "net", "level_up", "long_tx":
+			default:
+				errorf("telegram_bot.alerts", "unknown kind %q (want qso, net, level_up or long_tx)", kind)
+			}
+		}
+		if tg.AlertChatID != "" && !cfg.ActivityFeed.Enabled {
+			warnf("telegram_bot.alert_chat_id", "activity alerts need activity_feed.enabled")
+		}
+		if cmd := tg.ConnectCommand; cmd != "" && strings.Count(cmd, "%d") != 2 {
+			errorf("telegram_bot.connect_command", "must contain two %%d verbs (local node, remote node), got %q", cmd)
+		}
+		if cmd := tg.DisconnectCommand; cmd != "" && strings.Count(cmd, "%d") != 2 {
+			errorf("telegram_bot.disconnect_command", "must contain two %%d verbs (local node, remote node), got %q", cmd)
+		}
+	}
+	for i, src := range cfg.AstDBSources {
+		field := fmt.Sprintf("astdb_sources[%d]", i)
+		if (src.URL == "") == (src.Path == "") {
+			errorf(field, "set exactly one of url or path")
+		}
+	}
+	seenLocal := make(map[int]bool, len(cfg.LocalNodes))
+	for i, n := range cfg.LocalNodes {
+		field := fmt.Sprintf("local_nodes[%d]", i)
+		switch {
+		case n.Node <= 0:
+			errorf(field, "node must be a positive node number")
+		case seenLocal[n.Node]:
+			errorf(field, "node %d is listed more than once", n.Node)
+		case n.Callsign == "" && n.Description == "":
+			errorf(field, "node %d needs a callsign or description", n.Node)
+		case len(n.Callsign) > 20:
+			errorf(field, "callsign longer than 20 characters")
+		}
+		seenLocal[n.Node] = true
+	}
+	if fb := cfg.NodeLookupFallback; fb.Enabled {
+		if fb.URL != "" && strings.Count(fb.URL, "%d") != 1 {
+			errorf("node_lookup_fallback.url", "must contain one %%d verb for the node number, got %q", fb.URL)
+		}
+		if fb.CacheTTL < 0 || fb.NegativeTTL < 0 || fb.RequestsPerMinute < 0 {
+			errorf("node_lookup_fallback", "cache_ttl, negative_ttl and requests_per_minute must not be negative")
+		}
+	}
+	if th := cfg.TopologyHistory; th.Interval < 0 || th.KeyframeEvery < 0 || th.RetentionDays < 0 {
+		errorf("topology_history", "interval, keyframe_every and retention_days must not be negative")
+	}
+	switch strings.ToLower(cfg.Privacy.IPMasking) {
+	case "", "full", "partial", "none":
+	default:
+		errorf("privacy.ip_masking", "must be full, partial or none, got %q", cfg.Privacy.IPMasking)
+	}
+
+	for name, c := range cfg.Branding.Colors {
+		if !hexColor.MatchString(c) {
+			errorf("branding.colors."+name, "must be a hex color like #1e88e5, got %q", c)
+		}
+	}
+	for i, l := range cfg.Branding.FooterLinks {
+		if l.Label == "" || !(strings.HasPrefix(l.URL, "http://") || strings.HasPrefix(l.URL, "https://") || strings.HasPrefix(l.URL, "/")) {
+			errorf(fmt.Sprintf("branding.footer_links[%d]", i), "needs a label and an http(s) URL or absolute path")
+		}
+	}
+	return issues
+}
+
+// hexColor matches CSS hex colors (#rgb, #rgba, #rrggbb, #rrggbbaa).
+var hexColor = regexp.MustCompile(`^#([0-9a-fA-F]{3,4}|[0-9a-fA-F]{6}|[0-9a-fA-F]{8})$`)
+
+// localeTag loosely matches BCP 47 language tags (en, en-US, zh-Hant-TW).
+var localeTag = regexp.MustCompile(`^[A-Za-z]{2,3}(-[A-Za-z0-9]{2,8})*$`)
+
+func lintGamification(g GamificationConfig) []Issue {
+	var issues []Issue
+	errorf := func(field, format string, args ...any) {
+		issues = append(issues, Issue{SeverityError, field, fmt.Sprintf(format, args...)})
+	}
+	if g.TallyIntervalMinutes <= 0 {
+		errorf("gamification.tally_interval_minutes", "must be positive")
+	}
+	if g.BacklogThresholdHours < 0 {
+		errorf("gamification.backlog_threshold_hours", "must not be negative")
+	}
+	if g.OptOutMode != "" && g.OptOutMode != "hide" && g.OptOutMode != "exclude" {
+		errorf("gamification.opt_out_mode", "must be hide or exclude, got %q", g.OptOutMode)
+	}
+	prevMax := 0
+	for i, tier := range g.DiminishingReturns.Tiers {
+		field := fmt.Sprintf("gamification.diminishing_returns.tiers[%d]", i)
+		if tier.MaxSeconds <= prevMax {
+			errorf(field, "max_seconds must be strictly increasing (%d after %d)", tier.MaxSeconds, prevMax)
+		}
+		if tier.Multiplier < 0 || tier.Multiplier > 1 {
+			errorf(field, "multiplier must be between 0 and 1, got %g", tier.Multiplier)
+		}
+		if i > 0 && tier.Multiplier > g.DiminishingReturns.Tiers[i-1].Multiplier {
+			errorf(field, "multiplier should not increase across tiers (%g after %g)", tier.Multiplier, g.DiminishingReturns.Tiers[i-1].Multiplier)
+		}
+		prevMax = tier.MaxSeconds
+	}
+	if g.XPCaps.Enabled {
+		if g.XPCaps.DailyCap <= 0 || g.XPCaps.WeeklyCap <= 0 {
+			errorf("gamification.xp_caps", "daily_cap_seconds and weekly_cap_seconds must be positive")
+		} else if g.XPCaps.DailyCap > g.XPCaps.WeeklyCap {
+			errorf("gamification.xp_caps", "daily cap (%d) exceeds weekly cap (%d)", g.XPCaps.DailyCap, g.XPCaps.WeeklyCap)
+		}
+		if !validHour(g.XPCaps.ResetHour) {
+			errorf("gamification.xp_caps.reset_hour", "must be 0-23, got %d", g.XPCaps.ResetHour)
+		}
+	}
+	for i, t := range g.Renown.Titles {
+		field := fmt.Sprintf("gamification.renown.titles[%d]", i)
+		if strings.TrimSpace(t.Levels) == "" || strings.TrimSpace(t.Title) == "" {
+			errorf(field, "levels and title are required")
+		}
+		if t.RestedMultiplierBonus < 0 || t.RestedMaxHoursBonus < 0 {
+			errorf(field, "rested bonuses must not be negative")
+		}
+	}
+	if g.RestedBonus.Enabled && g.RestedBonus.Multiplier < 1 {
+		errorf("gamification.rested_bonus.multiplier", "must be at least 1.0, got %g", g.RestedBonus.Multiplier)
+	}
+	return issues
+}
+
+func validHour(h int) bool { return h >= 0 && h <= 23 }
+
+var tenantIDRe = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,31}$`)
+
+func lintTenants(cfg Config) []Issue {
+	var issues []Issue
+	errorf := func(field, format string, args ...any) {
+		issues = append(issues, Issue{SeverityError, field, fmt.Sprintf(format, args...)})
+	}
+	warnf := func(field, format string, args ...any) {
+		issues = append(issues, Issue{SeverityWarning, field, fmt.Sprintf(format, args...)})
+	}
+	configured := map[int]bool{}
+	for _, n := range cfg.Nodes {
+		configured[n.NodeID] = true
+	}
+	ids := map[string]bool{}
+	nodeOwner := map[int]string{}
+	hostOwner := map[string]string{}
+	for i, t := range cfg.Tenants {
+		field := fmt.Sprintf("tenants[%d]", i)
+		if !tenantIDRe.MatchString(t.ID) {
+			errorf(field+".id", "must be a lowercase slug (letters, digits, -), got %q", t.ID)
+		} else if ids[t.ID] {
+			errorf(field+".id", "duplicate tenant id %q", t.ID)
+		}
+		ids[t.ID] = true
+		if len(t.Nodes) == 0 {
+			errorf(field+".nodes", "at least one node is required")
+		}
+		for _, n := range t.Nodes {
+			if other, ok := nodeOwner[n]; ok {
+				errorf(field+".nodes", "node %d already belongs to tenant %q", n, other)
+			}
+			nodeOwner[n] = t.ID
+			if !configured[n] {
+				warnf(field+".nodes", "node %d is not a configured node; the tenant will see no activity on it", n)
+			}
+		}
+		for _, h := range t.Hosts {
+			h = strings.ToLower(strings.TrimSpace(h))
+			if other, ok := hostOwner[h]; ok {
+				errorf(field+".hosts", "host %q already belongs to tenant %q", h, other)
+			}
+			hostOwner[h] = t.ID
+		}
+	}
+	return issues
+}
@@ -0,0 +1,85 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// SetupAnswers holds the values collected by the `allstar-nexus init` wizard.
+type SetupAnswers struct {
+	Title               string
+	Port                string
+	DBPath              string
+	JWTSecret           string
+	Timezone            string
+	AMIEnabled          bool
+	AMIHost             string
+	AMIPort             int
+	AMIUser             string
+	AMIPassword         string
+	Nodes               []int
+	AllowAnonDashboard  bool
+	GamificationEnabled bool
+}
+
+// RenderConfig produces a complete config.yaml for the given answers. Settings not
+// covered by the wizard are written with their defaults so the file is self-documenting.
+func RenderConfig(a SetupAnswers) string {
+	nodes := make([]string, len(a.Nodes))
+	for i, n := range a.Nodes {
+		nodes[i] = strconv.Itoa(n)
+	}
+	var b strings.Builder
+	w := func(format string, args ...any) { fmt.Fprintf(&b, format+"\n", args...) }
+
+	w("# Allstar Nexus Configuration File")
+	w("# Generated by `allstar-nexus init`; see config.yaml.example for all options.")
+	w("# Environment variables will override these values")
+	w("")
+	w("# Server Configuration")
+	w("port: %s", a.Port)
+	w("app_env: production")
+	w("timezone: %q", a.Timezone)
+	w("")
+	w("# Branding")
+	w("title: %q", a.Title)
+	w("subtitle: \"\"")
+	w("")
+	w("# Database")
+	w("db_path: %s", a.DBPath)
+	w("astdb_path: data/astdb.txt")
+	w("astdb_url: http://allmondb.allstarlink.org/")
+	w("astdb_update_hours: 24")
+	w("")
+	w("# Security")
+	w("jwt_secret: %q", a.JWTSecret)
+	w("token_ttl_seconds: 86400  # 24 hours")
+	w("")
+	w("# Rate Limiting")
+	w("auth_rpm: 60")
+	w("public_stats_rpm: 120")
+	w("")
+	w("# AMI Configuration")
+	w("ami_enabled: %t", a.AMIEnabled)
+	w("ami_host: %s", a.AMIHost)
+	w("ami_port: %d", a.AMIPort)
+	w("ami_username: %q", a.AMIUser)
+	w("ami_password: %q", a.AMIPassword)
+	w("ami_events: \"on\"")
+	w("ami_retry_interval: 15s")
+	w("ami_retry_max: 60s")
+	w("")
+	w("# Nodes (names are looked up from astdb)")
+	w("nodes: [%s]", strings.Join(nodes, ", "))
+	w("")
+	w("# Feature Toggles")
+	w("disable_link_poller: false")
+	w("allow_anon_dashboard: %t", a.AllowAnonDashboard)
+	w("")
+	w("# Gamification System (low-activity defaults; see config.yaml.example to tune)")
+	w("gamification:")
+	w("  enabled: %t", a.GamificationEnabled)
+	w("  tally_interval_minutes: 30")
+	return b.String()
+}
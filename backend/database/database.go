@@ -3,50 +3,159 @@ package database
 import (
 	"context"
 	"errors"
+	"fmt"
+	"net/url"
+	"strings"
 	"time"
 
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 
-	_ "modernc.org/sqlite"
+	sqlitedrv "modernc.org/sqlite"
 )
 
+// SQLite result codes (primary code in the low byte of extended codes).
+const (
+	sqliteBusy   = 5
+	sqliteLocked = 6
+)
+
+// Options tune how the SQLite connection pool is opened. Settings are passed as
+// per-connection pragmas in the DSN, so every pooled connection gets them, not just the
+// first one.
+type Options struct {
+	BusyTimeout  time.Duration // how long SQLite waits on a locked database before SQLITE_BUSY
+	JournalMode  string        // WAL lets readers proceed while a write is in progress
+	Synchronous  string        // NORMAL is safe with WAL and much faster for write bursts
+	ForeignKeys  bool
+	TxLock       string       // "immediate" takes the write lock at BEGIN, avoiding read-to-write upgrade deadlocks
+	MaxOpenConns int          // 0 leaves the pool unbounded
+	Gorm         *gorm.Config // nil uses GORM defaults
+}
+
+// DefaultOptions returns the settings every process opening the application database
+// should use.
+func DefaultOptions() Options {
+	return Options{
+		BusyTimeout: 5 * time.Second,
+		JournalMode: "WAL",
+		Synchronous: "NORMAL",
+		ForeignKeys: true,
+		TxLock:      "immediate",
+	}
+}
+
+// DSN returns path with the option pragmas appended as modernc.org/sqlite query parameters.
+func DSN(path string, opts Options) string {
+	q := url.Values{}
+	// busy_timeout goes first so switching the journal mode waits on other connections too
+	if opts.BusyTimeout > 0 {
+		q.Add("_pragma", fmt.Sprintf("busy_timeout(%d)", opts.BusyTimeout.Milliseconds()))
+	}
+	if opts.JournalMode != "" {
+		q.Add("_pragma", "journal_mode("+opts.JournalMode+")")
+	}
+	if opts.Synchronous != "" {
+		q.Add("_pragma", "synchronous("+opts.Synchronous+")")
+	}
+	if opts.ForeignKeys {
+		q.Add("_pragma", "foreign_keys(1)")
+	}
+	if opts.TxLock != "" {
+		q.Set("_txlock", opts.TxLock)
+	}
+	if len(q) == 0 {
+		return path
+	}
+	sep := "?"
+	if strings.Contains(path, "?") {
+		sep = "&"
+	}
+	return path + sep + q.Encode()
+}
+
 // DB wraps gorm.DB for convenience.
 // NOTE: This wrapper is deprecated. Use gorm.DB directly instead.
 type DB struct {
 	*gorm.DB
 }
 
-// Open opens (and creates if needed) a SQLite database at path using GORM with modernc.org/sqlite (pure Go, no CGO).
+// Open opens (and creates if needed) a SQLite database at path using GORM with modernc.org/sqlite (pure Go, no CGO)
+// and DefaultOptions.
 func Open(path string) (*DB, error) {
+	return OpenWithOptions(path, DefaultOptions())
+}
+
+// OpenWithOptions opens the database at path with opts. Use a single pool per process:
+// separate pools on the same file contend for the write lock.
+func OpenWithOptions(path string, opts Options) (*DB, error) {
+	gcfg := opts.Gorm
+	if gcfg == nil {
+		gcfg = &gorm.Config{}
+	}
 	gormDB, err := gorm.Open(sqlite.New(sqlite.Config{
 		DriverName: "sqlite",
-		DSN:        path,
-	}), &gorm.Config{})
+		DSN:        DSN(path, opts),
+	}), gcfg)
 	if err != nil {
 		return nil, err
 	}
 
-	// Get underlying sql.DB for PRAGMA settings
 	sqlDB, err := gormDB.DB()
 	if err != nil {
 		return nil, err
 	}
+	if opts.MaxOpenConns > 0 {
+		sqlDB.SetMaxOpenConns(opts.MaxOpenConns)
+	}
 
-	// Ping to verify connection
+	// Ping to verify connection (and that the pragmas were accepted)
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 	if err := sqlDB.PingContext(ctx); err != nil {
+		_ = sqlDB.Close()
 		return nil, err
 	}
 
-	// Optimize for write bursts on link tx events
-	_, _ = sqlDB.ExecContext(ctx, "PRAGMA journal_mode=WAL;")
-	_, _ = sqlDB.ExecContext(ctx, "PRAGMA synchronous=NORMAL;")
-
 	return &DB{gormDB}, nil
 }
 
+// IsBusy reports whether err means the database was locked by another connection
+// (SQLITE_BUSY or SQLITE_LOCKED), i.e. the operation may succeed if retried.
+func IsBusy(err error) bool {
+	if err == nil {
+		return false
+	}
+	var se *sqlitedrv.Error
+	if errors.As(err, &se) {
+		code := se.Code() & 0xff
+		return code == sqliteBusy || code == sqliteLocked
+	}
+	msg := err.Error()
+	return strings.Contains(msg, "database is locked") || strings.Contains(msg, "SQLITE_BUSY")
+}
+
+// retryDelays is the backoff between attempts of Retry, on top of busy_timeout.
+var retryDelays = []time.Duration{25 * time.Millisecond, 100 * time.Millisecond, 250 * time.Millisecond}
+
+// Retry runs fn, retrying with backoff while it fails with a busy error. fn must be safe
+// to repeat, e.g. a single statement or a whole transaction.
+func Retry(ctx context.Context, fn func() error) error {
+	err := fn()
+	for _, d := range retryDelays {
+		if !IsBusy(err) {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(d):
+		}
+		err = fn()
+	}
+	return err
+}
+
 // CountUsers returns total users using GORM.
 func (db *DB) CountUsers(ctx context.Context) (int64, error) {
 	var count int64
@@ -2,6 +2,8 @@ package database
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"path/filepath"
 	"testing"
 	"time"
@@ -98,3 +100,89 @@ func TestCountUsers(t *testing.T) {
 		t.Fatalf("expected count 1, got %d", count)
 	}
 }
+
+func TestOpenAppliesPragmasToEveryConnection(t *testing.T) {
+	db, err := Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer func() { _ = db.CloseSafe() }()
+	sqlDB, _ := db.DB.DB()
+
+	ctx := context.Background()
+	// Hold two connections at once so the second one is freshly opened by the pool
+	c1, err := sqlDB.Conn(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = c1.Close() }()
+	c2, err := sqlDB.Conn(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = c2.Close() }()
+
+	for i, c := range []*sql.Conn{c1, c2} {
+		var mode string
+		var timeout, fk int
+		if err := c.QueryRowContext(ctx, "PRAGMA journal_mode").Scan(&mode); err != nil {
+			t.Fatal(err)
+		}
+		if err := c.QueryRowContext(ctx, "PRAGMA busy_timeout").Scan(&timeout); err != nil {
+			t.Fatal(err)
+		}
+		if err := c.QueryRowContext(ctx, "PRAGMA foreign_keys").Scan(&fk); err != nil {
+			t.Fatal(err)
+		}
+		if mode != "wal" || timeout != 5000 || fk != 1 {
+			t.Fatalf("conn %d: journal_mode=%s busy_timeout=%d foreign_keys=%d", i, mode, timeout, fk)
+		}
+	}
+}
+
+func TestRetryOnBusy(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.db")
+	holder, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer func() { _ = holder.CloseSafe() }()
+	if err := holder.Exec("CREATE TABLE t (id INTEGER)").Error; err != nil {
+		t.Fatal(err)
+	}
+
+	// A second pool that fails immediately instead of waiting on the lock
+	opts := DefaultOptions()
+	opts.BusyTimeout = 0
+	other, err := OpenWithOptions(path, opts)
+	if err != nil {
+		t.Fatalf("OpenWithOptions failed: %v", err)
+	}
+	defer func() { _ = other.CloseSafe() }()
+
+	tx := holder.Begin()
+	if err := tx.Exec("INSERT INTO t (id) VALUES (1)").Error; err != nil {
+		t.Fatal(err)
+	}
+	err = other.Exec("INSERT INTO t (id) VALUES (2)").Error
+	if !IsBusy(err) {
+		t.Fatalf("expected busy error while locked, got %v", err)
+	}
+
+	// Release the lock while Retry is backing off
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		tx.Commit()
+	}()
+	attempts := 0
+	err = Retry(context.Background(), func() error {
+		attempts++
+		return other.Exec("INSERT INTO t (id) VALUES (2)").Error
+	})
+	if err != nil || attempts < 2 {
+		t.Fatalf("Retry: err=%v attempts=%d", err, attempts)
+	}
+	if IsBusy(errors.New("no such table: t")) {
+		t.Fatal("unrelated error reported as busy")
+	}
+}
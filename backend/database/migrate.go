@@ -0,0 +1,150 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Migration is one versioned schema or data change. Up and Down run in a transaction;
+// a nil Down makes the migration irreversible.
+type Migration struct {
+	Version     int
+	Description string
+	Up          func(tx *gorm.DB) error
+	Down        func(tx *gorm.DB) error
+}
+
+// SchemaVersion records an applied migration.
+type SchemaVersion struct {
+	Version     int       `gorm:"primaryKey;autoIncrement:false"`
+	Description string    `gorm:"not null"`
+	AppliedAt   time.Time `gorm:"not null"`
+}
+
+// TableName keeps the conventional singular name.
+func (SchemaVersion) TableName() string { return "schema_version" }
+
+// Migrator applies and reverts migrations, tracking them in the schema_version table.
+type Migrator struct {
+	db         *gorm.DB
+	migrations []Migration
+}
+
+// NewMigrator checks that migrations have unique positive versions and sorts them.
+func NewMigrator(db *gorm.DB, migrations []Migration) (*Migrator, error) {
+	ms := append([]Migration(nil), migrations...)
+	sort.Slice(ms, func(i, j int) bool { return ms[i].Version < ms[j].Version })
+	for i, m := range ms {
+		if m.Version <= 0 || m.Up == nil {
+			return nil, fmt.Errorf("migration %d (%s): version must be positive and Up set", m.Version, m.Description)
+		}
+		if i > 0 && ms[i-1].Version == m.Version {
+			return nil, fmt.Errorf("duplicate migration version %d", m.Version)
+		}
+	}
+	return &Migrator{db: db, migrations: ms}, nil
+}
+
+func (m *Migrator) applied(ctx context.Context) (map[int]bool, error) {
+	if err := m.db.WithContext(ctx).AutoMigrate(&SchemaVersion{}); err != nil {
+		return nil, fmt.Errorf("create schema_version: %w", err)
+	}
+	var rows []SchemaVersion
+	if err := m.db.WithContext(ctx).Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	out := make(map[int]bool, len(rows))
+	for _, r := range rows {
+		out[r.Version] = true
+	}
+	return out, nil
+}
+
+// Version returns the highest applied migration version (0 for a new database).
+func (m *Migrator) Version(ctx context.Context) (int, error) {
+	done, err := m.applied(ctx)
+	if err != nil {
+		return 0, err
+	}
+	v := 0
+	for version := range done {
+		v = max(v, version)
+	}
+	return v, nil
+}
+
+// Pending lists the migrations not yet applied, in order.
+func (m *Migrator) Pending(ctx context.Context) ([]Migration, error) {
+	done, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var out []Migration
+	for _, mg := range m.migrations {
+		if !done[mg.Version] {
+			out = append(out, mg)
+		}
+	}
+	return out, nil
+}
+
+// Up applies every pending migration in version order and returns those applied. It
+// stops at the first failure, leaving that migration unapplied.
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	pending, err := m.Pending(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var applied []Migration
+	for _, mg := range pending {
+		err := m.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			if err := mg.Up(tx); err != nil {
+				return err
+			}
+			return tx.Create(&SchemaVersion{Version: mg.Version, Description: mg.Description, AppliedAt: time.Now().UTC()}).Error
+		})
+		if err != nil {
+			return applied, fmt.Errorf("migration %d (%s): %w", mg.Version, mg.Description, err)
+		}
+		applied = append(applied, mg)
+	}
+	return applied, nil
+}
+
+// Down reverts applied migrations above target, newest first, and returns those
+// reverted. It refuses to start if any of them is irreversible.
+func (m *Migrator) Down(ctx context.Context, target int) ([]Migration, error) {
+	done, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var todo []Migration
+	for i := len(m.migrations) - 1; i >= 0; i-- {
+		mg := m.migrations[i]
+		if mg.Version <= target || !done[mg.Version] {
+			continue
+		}
+		if mg.Down == nil {
+			return nil, fmt.Errorf("migration %d (%s) is irreversible", mg.Version, mg.Description)
+		}
+		todo = append(todo, mg)
+	}
+	var reverted []Migration
+	for _, mg := range todo {
+		err := m.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			if err := mg.Down(tx); err != nil {
+				return err
+			}
+			return tx.Delete(&SchemaVersion{}, mg.Version).Error
+		})
+		if err != nil {
+			return reverted, fmt.Errorf("revert migration %d (%s): %w", mg.Version, mg.Description, err)
+		}
+		reverted = append(reverted, mg)
+	}
+	return reverted, nil
+}
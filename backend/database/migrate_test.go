@@ -0,0 +1,114 @@
+package database
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/dbehnke/allstar-nexus/backend/models"
+	"gorm.io/gorm"
+)
+
+func TestMigratorUpDown(t *testing.T) {
+	db, err := Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer func() { _ = db.CloseSafe() }()
+	ctx := t.Context()
+
+	type widget struct {
+		ID   uint
+		Name string
+	}
+	failing := false
+	migrations := []Migration{
+		{Version: 2, Description: "add widgets", Up: func(tx *gorm.DB) error { return tx.AutoMigrate(&widget{}) },
+			Down: func(tx *gorm.DB) error { return tx.Migrator().DropTable(&widget{}) }},
+		{Version: 1, Description: "seed", Up: func(tx *gorm.DB) error { return nil }},
+		{Version: 3, Description: "flaky", Up: func(tx *gorm.DB) error {
+			if err := tx.Create(&widget{Name: "partial"}).Error; err != nil {
+				return err
+			}
+			if failing {
+				return errors.New("boom")
+			}
+			return nil
+		}, Down: func(tx *gorm.DB) error { return tx.Where("name = ?", "partial").Delete(&widget{}).Error }},
+	}
+	if _, err := NewMigrator(db.DB, append(migrations, Migration{Version: 2, Up: migrations[0].Up})); err == nil {
+		t.Fatal("duplicate versions should be rejected")
+	}
+	m, err := NewMigrator(db.DB, migrations)
+	if err != nil {
+		t.Fatalf("NewMigrator: %v", err)
+	}
+
+	// a failing migration is rolled back and left pending
+	failing = true
+	applied, err := m.Up(ctx)
+	if err == nil || len(applied) != 2 || applied[0].Version != 1 {
+		t.Fatalf("Up with failure: applied=%v err=%v", applied, err)
+	}
+	var n int64
+	db.Model(&widget{}).Count(&n)
+	if v, _ := m.Version(ctx); v != 2 || n != 0 {
+		t.Fatalf("after failure: version %d, %d widgets (want 2, 0)", v, n)
+	}
+
+	failing = false
+	if applied, err := m.Up(ctx); err != nil || len(applied) != 1 || applied[0].Version != 3 {
+		t.Fatalf("Up: applied=%v err=%v", applied, err)
+	}
+	if pending, _ := m.Pending(ctx); len(pending) != 0 {
+		t.Fatalf("pending after Up: %v", pending)
+	}
+
+	// reverting past the irreversible migration 1 is refused without changing anything
+	if _, err := m.Down(ctx, 0); err == nil {
+		t.Fatal("Down past an irreversible migration should fail")
+	}
+	if v, _ := m.Version(ctx); v != 3 {
+		t.Fatalf("refused Down changed version to %d", v)
+	}
+	reverted, err := m.Down(ctx, 1)
+	if err != nil || len(reverted) != 2 || reverted[0].Version != 3 {
+		t.Fatalf("Down: reverted=%v err=%v", reverted, err)
+	}
+	if v, _ := m.Version(ctx); v != 1 || db.Migrator().HasTable(&widget{}) {
+		t.Fatalf("after Down: version %d, widgets table present %v", v, db.Migrator().HasTable(&widget{}))
+	}
+}
+
+func TestMigrationsBackfillLocalNode(t *testing.T) {
+	db, err := Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer func() { _ = db.CloseSafe() }()
+	ctx := t.Context()
+
+	// a database from before versioned migrations: AutoMigrate-created, no schema_version
+	if err := db.AutoMigrate(&models.LinkStat{}); err != nil {
+		t.Fatal(err)
+	}
+	db.Create(&[]models.LinkStat{{Node: 500}, {Node: 501, LocalNode: 3001}})
+
+	m, err := NewMigrator(db.DB, Migrations(MigrationOptions{PrimaryNode: 2001}))
+	if err != nil {
+		t.Fatalf("NewMigrator: %v", err)
+	}
+	if _, err := m.Up(ctx); err != nil {
+		t.Fatalf("Up: %v", err)
+	}
+	var stats []models.LinkStat
+	db.Order("node").Find(&stats)
+	if len(stats) != 2 || stats[0].LocalNode != 2001 || stats[1].LocalNode != 3001 {
+		t.Fatalf("backfill: %+v", stats)
+	}
+	for _, model := range Models() {
+		if !db.Migrator().HasTable(model) {
+			t.Errorf("baseline did not create %T", model)
+		}
+	}
+}
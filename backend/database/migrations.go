@@ -0,0 +1,86 @@
+package database
+
+import (
+	"github.com/dbehnke/allstar-nexus/backend/models"
+	"gorm.io/gorm"
+)
+
+// MigrationOptions carries the configuration data migrations depend on.
+type MigrationOptions struct {
+	PrimaryNode int // first configured node; 0 when none is configured
+}
+
+// Models lists every table the application owns, for the baseline migration.
+func Models() []any {
+	return []any{
+		&models.User{},
+		&models.TransmissionLog{},
+		&models.NodeInfo{},
+		&models.LinkStat{},
+		&models.CallsignProfile{},
+		&models.LevelConfig{},
+		&models.XPActivityLog{},
+		&models.TallyState{},
+		&models.TallySkewAnnotation{},
+		&models.TallyWindowMark{},
+		&models.Setting{},
+		&models.TextNode{},
+		&models.NodeAnnotation{},
+		&models.WatchedNode{},
+		&models.TopologySnapshot{},
+		&models.LocalNode{},
+		&models.NodeLabel{},
+		&models.GamificationOptOut{},
+		&models.LevelHistory{},
+		&models.TallyRun{},
+		&models.AuditEntry{},
+		&models.NodeHealth{},
+		&models.LinkQuality{},
+		&models.Announcement{},
+		&models.NodeClaim{},
+	}
+}
+
+// Migrations returns the application's migrations. Append new ones with the next
+// version; never renumber or edit a released migration.
+//
+// The baseline creates tables from the current models, so on a new database later
+// migrations run against a schema that may already have their changes: write them to
+// tolerate that (check Migrator().HasColumn / HasTable before altering).
+func Migrations(opts MigrationOptions) []Migration {
+	return []Migration{
+		{
+			Version:     1,
+			Description: "baseline schema",
+			// Databases created before versioned migrations were kept current by
+			// AutoMigrate on every start; this brings them (and new ones) up to date once.
+			Up: func(tx *gorm.DB) error { return tx.AutoMigrate(Models()...) },
+		},
+		{
+			Version:     2,
+			Description: "backfill link_stats.local_node",
+			// Rows persisted before multi-node support have local_node 0, which readers
+			// attribute to the primary node; record it explicitly. Without a configured
+			// node the rows stay 0, which is still read as the primary.
+			Up: func(tx *gorm.DB) error {
+				if opts.PrimaryNode == 0 {
+					return nil
+				}
+				return tx.Model(&models.LinkStat{}).Where("local_node = 0").Update("local_node", opts.PrimaryNode).Error
+			},
+			// 0 and the primary node are read the same way, so there is nothing to undo
+			Down: func(tx *gorm.DB) error { return nil },
+		},
+		{
+			Version:     3,
+			Description: "create node_claims",
+			Up: func(tx *gorm.DB) error {
+				if tx.Migrator().HasTable(&models.NodeClaim{}) {
+					return nil
+				}
+				return tx.Migrator().CreateTable(&models.NodeClaim{})
+			},
+			Down: func(tx *gorm.DB) error { return tx.Migrator().DropTable(&models.NodeClaim{}) },
+		},
+	}
+}
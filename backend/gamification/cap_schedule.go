@@ -0,0 +1,83 @@
+package gamification
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// CapSchedule defines calendar-based daily/weekly XP cap periods in the hub's timezone.
+// Boundaries are computed with time.Date so they stay at the configured wall-clock hour
+// across DST transitions (a "day" may therefore be 23 or 25 hours long).
+type CapSchedule struct {
+	Location     *time.Location
+	ResetHour    int          // local hour (0-23) at which daily caps reset
+	FirstWeekday time.Weekday // weekday on which weekly caps reset (at ResetHour)
+}
+
+// DefaultCapSchedule matches the historical behaviour: midnight UTC, weeks starting Sunday.
+func DefaultCapSchedule() CapSchedule {
+	return CapSchedule{Location: time.UTC, ResetHour: 0, FirstWeekday: time.Sunday}
+}
+
+// NewCapSchedule builds a schedule from configuration values. An empty timezone uses the
+// system local zone; weekStarts accepts English weekday names (e.g. "sunday", "Mon").
+func NewCapSchedule(timezone string, resetHour int, weekStarts string) (CapSchedule, error) {
+	sched := DefaultCapSchedule()
+	sched.Location = time.Local
+	if timezone != "" {
+		loc, err := time.LoadLocation(timezone)
+		if err != nil {
+			return DefaultCapSchedule(), fmt.Errorf("invalid timezone %q: %w", timezone, err)
+		}
+		sched.Location = loc
+	}
+	if resetHour < 0 || resetHour > 23 {
+		return DefaultCapSchedule(), fmt.Errorf("reset_hour must be 0-23, got %d", resetHour)
+	}
+	sched.ResetHour = resetHour
+	if weekStarts != "" {
+		wd, err := ParseWeekday(weekStarts)
+		if err != nil {
+			return DefaultCapSchedule(), err
+		}
+		sched.FirstWeekday = wd
+	}
+	return sched, nil
+}
+
+// ParseWeekday parses a full or three-letter English weekday name, case-insensitively.
+func ParseWeekday(s string) (time.Weekday, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		name := strings.ToLower(d.String())
+		if s == name || (len(s) == 3 && strings.HasPrefix(name, s)) {
+			return d, nil
+		}
+	}
+	return time.Sunday, fmt.Errorf("invalid weekday %q", s)
+}
+
+func (c CapSchedule) location() *time.Location {
+	if c.Location == nil {
+		return time.UTC
+	}
+	return c.Location
+}
+
+// DayStart returns the start of the daily cap period containing t.
+func (c CapSchedule) DayStart(t time.Time) time.Time {
+	local := t.In(c.location())
+	start := time.Date(local.Year(), local.Month(), local.Day(), c.ResetHour, 0, 0, 0, c.location())
+	if start.After(t) {
+		start = time.Date(local.Year(), local.Month(), local.Day()-1, c.ResetHour, 0, 0, 0, c.location())
+	}
+	return start
+}
+
+// WeekStart returns the start of the weekly cap period containing t.
+func (c CapSchedule) WeekStart(t time.Time) time.Time {
+	day := c.DayStart(t)
+	back := (int(day.Weekday()) - int(c.FirstWeekday) + 7) % 7
+	return time.Date(day.Year(), day.Month(), day.Day()-back, c.ResetHour, 0, 0, 0, c.location())
+}
@@ -0,0 +1,100 @@
+package gamification
+
+import (
+	"testing"
+	"time"
+)
+
+func mustLoad(t *testing.T, name string) *time.Location {
+	t.Helper()
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		t.Skipf("timezone data unavailable: %v", err)
+	}
+	return loc
+}
+
+func TestCapScheduleDayStartAcrossDST(t *testing.T) {
+	ny := mustLoad(t, "America/New_York")
+	sched := CapSchedule{Location: ny, ResetHour: 4, FirstWeekday: time.Monday}
+
+	cases := []struct {
+		name string
+		now  time.Time
+		want time.Time
+	}{
+		// Spring forward (2025-03-09 02:00 -> 03:00): day is 23 hours long
+		{"before reset on DST day", time.Date(2025, 3, 9, 3, 30, 0, 0, ny), time.Date(2025, 3, 8, 4, 0, 0, 0, ny)},
+		{"after reset on DST day", time.Date(2025, 3, 9, 4, 0, 0, 0, ny), time.Date(2025, 3, 9, 4, 0, 0, 0, ny)},
+		// Fall back (2025-11-02 02:00 -> 01:00): day is 25 hours long
+		{"fall back before reset", time.Date(2025, 11, 2, 1, 30, 0, 0, ny), time.Date(2025, 11, 1, 4, 0, 0, 0, ny)},
+		{"fall back after reset", time.Date(2025, 11, 2, 23, 0, 0, 0, ny), time.Date(2025, 11, 2, 4, 0, 0, 0, ny)},
+	}
+	for _, tc := range cases {
+		if got := sched.DayStart(tc.now); !got.Equal(tc.want) {
+			t.Errorf("%s: DayStart(%s) = %s, want %s", tc.name, tc.now, got, tc.want)
+		}
+	}
+
+	// Reset stays at 04:00 local even though UTC offset changed
+	before := sched.DayStart(time.Date(2025, 3, 8, 12, 0, 0, 0, ny))
+	after := sched.DayStart(time.Date(2025, 3, 9, 12, 0, 0, 0, ny))
+	if d := after.Sub(before); d != 23*time.Hour {
+		t.Errorf("expected 23h day across spring forward, got %s", d)
+	}
+	before = sched.DayStart(time.Date(2025, 11, 1, 12, 0, 0, 0, ny))
+	after = sched.DayStart(time.Date(2025, 11, 2, 12, 0, 0, 0, ny))
+	if d := after.Sub(before); d != 25*time.Hour {
+		t.Errorf("expected 25h day across fall back, got %s", d)
+	}
+}
+
+func TestCapScheduleWeekStart(t *testing.T) {
+	berlin := mustLoad(t, "Europe/Berlin")
+	sched := CapSchedule{Location: berlin, ResetHour: 0, FirstWeekday: time.Monday}
+
+	// Sunday 2025-03-30 is the EU spring-forward day; the week began Monday 2025-03-24
+	now := time.Date(2025, 3, 30, 12, 0, 0, 0, berlin)
+	want := time.Date(2025, 3, 24, 0, 0, 0, 0, berlin)
+	if got := sched.WeekStart(now); !got.Equal(want) {
+		t.Fatalf("WeekStart = %s, want %s", got, want)
+	}
+	// Monday just after midnight starts a new week
+	now = time.Date(2025, 3, 31, 0, 0, 1, 0, berlin)
+	want = time.Date(2025, 3, 31, 0, 0, 0, 0, berlin)
+	if got := sched.WeekStart(now); !got.Equal(want) {
+		t.Fatalf("WeekStart = %s, want %s", got, want)
+	}
+	// Before the reset hour on the first weekday still belongs to the previous week
+	late := CapSchedule{Location: berlin, ResetHour: 6, FirstWeekday: time.Monday}
+	now = time.Date(2025, 3, 31, 5, 0, 0, 0, berlin)
+	want = time.Date(2025, 3, 24, 6, 0, 0, 0, berlin)
+	if got := late.WeekStart(now); !got.Equal(want) {
+		t.Fatalf("WeekStart before reset = %s, want %s", got, want)
+	}
+}
+
+func TestNewCapSchedule(t *testing.T) {
+	sched, err := NewCapSchedule("UTC", 3, "Wed")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if sched.ResetHour != 3 || sched.FirstWeekday != time.Wednesday {
+		t.Fatalf("unexpected schedule: %+v", sched)
+	}
+	if _, err := NewCapSchedule("Not/AZone", 0, "sunday"); err == nil {
+		t.Fatalf("expected error for invalid timezone")
+	}
+	if _, err := NewCapSchedule("", 24, "sunday"); err == nil {
+		t.Fatalf("expected error for invalid reset hour")
+	}
+	if _, err := NewCapSchedule("", 0, "someday"); err == nil {
+		t.Fatalf("expected error for invalid weekday")
+	}
+	// Zero value behaves like the legacy UTC midnight / Sunday schedule
+	var zero CapSchedule
+	now := time.Date(2025, 1, 8, 15, 0, 0, 0, time.UTC) // Wednesday
+	if got := zero.WeekStart(now); !got.Equal(time.Date(2025, 1, 5, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("zero schedule WeekStart = %s", got)
+	}
+}
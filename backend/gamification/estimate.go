@@ -0,0 +1,61 @@
+package gamification
+
+import (
+	"math"
+	"time"
+
+	"github.com/dbehnke/allstar-nexus/backend/repository"
+)
+
+// EstimateWindowDays is how far back the level estimate looks for the current pace.
+const EstimateWindowDays = 30
+
+// LevelEstimate projects when a callsign reaches its next level (or renown) at the pace
+// of its recent awarded XP.
+type LevelEstimate struct {
+	WindowDays        int        `json:"window_days"`
+	ActiveDays        int        `json:"active_days"`           // days in the window with awarded XP
+	AvgXPPerActiveDay float64    `json:"avg_xp_per_active_day"` // awarded XP / active days
+	XPPerDay          float64    `json:"xp_per_day"`            // awarded XP / window days (the pace used)
+	NextLevel         int        `json:"next_level"`
+	NextIsRenown      bool       `json:"next_is_renown"` // reaching level 60 starts a new renown cycle
+	XPToNext          int        `json:"xp_to_next"`
+	DaysToNext        *float64   `json:"days_to_next,omitempty"` // nil without recent activity
+	ProjectedAt       *time.Time `json:"projected_at,omitempty"`
+}
+
+// EstimateNextLevel projects the next level from daily activity (as returned by
+// XPActivityRepo.GetDailyBreakdown over windowDays). The pace spreads the window's XP
+// over every day, idle ones included, so occasional talkers get realistic dates.
+func EstimateNextLevel(level, xp, nextLevelXP int, daily []repository.DailyActivity, windowDays int, now time.Time) LevelEstimate {
+	est := LevelEstimate{
+		WindowDays:   windowDays,
+		NextLevel:    level + 1,
+		NextIsRenown: level+1 >= 60,
+		XPToNext:     max(nextLevelXP-xp, 0),
+	}
+	total := 0
+	for _, d := range daily {
+		if d.AwardedXP > 0 {
+			est.ActiveDays++
+			total += d.AwardedXP
+		}
+	}
+	if est.ActiveDays > 0 {
+		est.AvgXPPerActiveDay = round1(float64(total) / float64(est.ActiveDays))
+	}
+	if windowDays > 0 {
+		est.XPPerDay = round1(float64(total) / float64(windowDays))
+	}
+	if total > 0 && windowDays > 0 {
+		days := float64(est.XPToNext) * float64(windowDays) / float64(total)
+		at := now.Add(time.Duration(days * float64(24*time.Hour))).UTC()
+		days = round1(days)
+		est.DaysToNext, est.ProjectedAt = &days, &at
+	}
+	return est
+}
+
+func round1(f float64) float64 {
+	return math.Round(f*10) / 10
+}
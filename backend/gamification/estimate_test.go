@@ -0,0 +1,26 @@
+package gamification
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dbehnke/allstar-nexus/backend/repository"
+)
+
+func TestEstimateNextLevel(t *testing.T) {
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	daily := []repository.DailyActivity{{Date: "2026-09-30", AwardedXP: 600}, {Date: "2026-09-20", AwardedXP: 0}, {Date: "2026-09-10", AwardedXP: 300}}
+
+	est := EstimateNextLevel(15, 1000, 1900, daily, 30, now)
+	if est.ActiveDays != 2 || est.AvgXPPerActiveDay != 450 || est.XPPerDay != 30 || est.XPToNext != 900 || est.NextLevel != 16 || est.NextIsRenown {
+		t.Fatalf("estimate = %+v", est)
+	}
+	if est.DaysToNext == nil || *est.DaysToNext != 30 || !est.ProjectedAt.Equal(now.AddDate(0, 0, 30)) {
+		t.Fatalf("projection = %v %v", est.DaysToNext, est.ProjectedAt)
+	}
+
+	idle := EstimateNextLevel(59, 0, 5000, nil, 30, now)
+	if !idle.NextIsRenown || idle.DaysToNext != nil || idle.ProjectedAt != nil || idle.XPToNext != 5000 {
+		t.Fatalf("idle estimate = %+v", idle)
+	}
+}
@@ -0,0 +1,79 @@
+package gamification
+
+import (
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Opt-out modes (gamification.opt_out_mode).
+const (
+	OptOutHide    = "hide"    // keep earning XP, but never shown or announced
+	OptOutExclude = "exclude" // additionally skipped by the tally
+)
+
+// OptOuts is the in-memory set of callsigns that opted out of public gamification
+// display. A nil *OptOuts has no members.
+type OptOuts struct {
+	mu      sync.RWMutex
+	set     map[string]bool
+	exclude bool
+}
+
+// NewOptOuts creates an empty set; mode is OptOutHide or OptOutExclude.
+func NewOptOuts(mode string) *OptOuts {
+	return &OptOuts{set: make(map[string]bool), exclude: mode == OptOutExclude}
+}
+
+// Set replaces the members.
+func (o *OptOuts) Set(callsigns []string) {
+	set := make(map[string]bool, len(callsigns))
+	for _, c := range callsigns {
+		set[normalizeCallsign(c)] = true
+	}
+	o.mu.Lock()
+	o.set = set
+	o.mu.Unlock()
+}
+
+// Has reports whether callsign opted out.
+func (o *OptOuts) Has(callsign string) bool {
+	if o == nil || callsign == "" {
+		return false
+	}
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	return o.set[normalizeCallsign(callsign)]
+}
+
+// Excluded reports whether the tally should skip callsign.
+func (o *OptOuts) Excluded(callsign string) bool {
+	return o != nil && o.exclude && o.Has(callsign)
+}
+
+// Mode returns OptOutHide or OptOutExclude.
+func (o *OptOuts) Mode() string {
+	if o != nil && o.exclude {
+		return OptOutExclude
+	}
+	return OptOutHide
+}
+
+// List returns the members, sorted.
+func (o *OptOuts) List() []string {
+	if o == nil {
+		return nil
+	}
+	o.mu.RLock()
+	out := make([]string, 0, len(o.set))
+	for c := range o.set {
+		out = append(out, c)
+	}
+	o.mu.RUnlock()
+	sort.Strings(out)
+	return out
+}
+
+func normalizeCallsign(c string) string {
+	return strings.ToUpper(strings.TrimSpace(c))
+}
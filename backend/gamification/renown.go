@@ -0,0 +1,83 @@
+package gamification
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	cfgpkg "github.com/dbehnke/allstar-nexus/backend/config"
+)
+
+// parseRenownRange parses a renown tier range. It accepts everything
+// parseLevelRangeStrict does plus an open-ended "N+" form, since renown has
+// no upper bound.
+func parseRenownRange(r string) (int, int, error) {
+	r = strings.TrimSpace(r)
+	if open, ok := strings.CutSuffix(r, "+"); ok {
+		start, err := strconv.Atoi(strings.TrimSpace(open))
+		if err != nil {
+			return 0, 0, fmt.Errorf("invalid start: %w", err)
+		}
+		return start, math.MaxInt, nil
+	}
+	return parseLevelRangeStrict(r)
+}
+
+// ValidateRenownTiers checks that renown tiers parse, start at renown 1 or
+// later and don't overlap.
+func ValidateRenownTiers(tiers []cfgpkg.RenownTier) error {
+	type span struct {
+		start, end int
+		title      string
+	}
+	var seen []span
+	for _, t := range tiers {
+		start, end, err := parseRenownRange(t.Levels)
+		if err != nil {
+			return fmt.Errorf("invalid renown range %q: %w", t.Levels, err)
+		}
+		if start < 1 {
+			return fmt.Errorf("renown range %q must start at 1 or higher", t.Levels)
+		}
+		for _, s := range seen {
+			if start <= s.end && s.start <= end {
+				return fmt.Errorf("renown tiers %q and %q overlap", s.title, t.Title)
+			}
+		}
+		seen = append(seen, span{start, end, t.Title})
+	}
+	return nil
+}
+
+// GetRenownTier returns the tier covering the given renown level, or nil when
+// the callsign has no renown or no tier matches.
+func GetRenownTier(renown int, tiers []cfgpkg.RenownTier) *cfgpkg.RenownTier {
+	if renown <= 0 {
+		return nil
+	}
+	for i := range tiers {
+		start, end, err := parseRenownRange(tiers[i].Levels)
+		if err != nil {
+			continue
+		}
+		if renown >= start && renown <= end {
+			return &tiers[i]
+		}
+	}
+	return nil
+}
+
+// GetRenownTitle returns the display title for a renown level. Max is 0 for
+// open-ended tiers.
+func GetRenownTitle(renown int, tiers []cfgpkg.RenownTier) *GroupingInfo {
+	t := GetRenownTier(renown, tiers)
+	if t == nil {
+		return nil
+	}
+	start, end, _ := parseRenownRange(t.Levels)
+	if end == math.MaxInt {
+		end = 0
+	}
+	return &GroupingInfo{Title: t.Title, Badge: t.Badge, Color: t.Color, Min: start, Max: end}
+}
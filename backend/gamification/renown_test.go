@@ -0,0 +1,58 @@
+package gamification
+
+import (
+	"testing"
+
+	cfgpkg "github.com/dbehnke/allstar-nexus/backend/config"
+	"github.com/dbehnke/allstar-nexus/backend/models"
+)
+
+var testRenownTiers = []cfgpkg.RenownTier{
+	{Levels: "1-2", Title: "Bronze"},
+	{Levels: "3-5", Title: "Silver", RestedMultiplierBonus: 0.5},
+	{Levels: "6+", Title: "Net Legend", RestedMultiplierBonus: 1.0, RestedMaxHoursBonus: 10},
+}
+
+func TestValidateRenownTiers(t *testing.T) {
+	if err := ValidateRenownTiers(testRenownTiers); err != nil {
+		t.Fatalf("expected valid tiers, got %v", err)
+	}
+	overlap := append([]cfgpkg.RenownTier{}, testRenownTiers...)
+	overlap = append(overlap, cfgpkg.RenownTier{Levels: "10-12", Title: "Clash"})
+	if err := ValidateRenownTiers(overlap); err == nil {
+		t.Error("expected overlap with open-ended tier to fail")
+	}
+	if err := ValidateRenownTiers([]cfgpkg.RenownTier{{Levels: "0-1", Title: "Zero"}}); err == nil {
+		t.Error("expected renown 0 to be rejected")
+	}
+}
+
+func TestGetRenownTitle(t *testing.T) {
+	if got := GetRenownTitle(0, testRenownTiers); got != nil {
+		t.Errorf("renown 0 should have no title, got %+v", got)
+	}
+	if got := GetRenownTitle(4, testRenownTiers); got == nil || got.Title != "Silver" || got.Min != 3 || got.Max != 5 {
+		t.Errorf("renown 4: got %+v", got)
+	}
+	if got := GetRenownTitle(42, testRenownTiers); got == nil || got.Title != "Net Legend" || got.Max != 0 {
+		t.Errorf("renown 42: got %+v", got)
+	}
+}
+
+func TestRenownRestedBoost(t *testing.T) {
+	s := &TallyService{config: &Config{RestedEnabled: true, RestedMultiplier: 2.0, RestedMaxSeconds: 3600, RenownTiers: testRenownTiers}}
+
+	plain := &models.CallsignProfile{RestedBonusSeconds: 600}
+	if m := s.applyRestedBonus(plain, 60); m != 2.0 {
+		t.Errorf("no renown: expected 2.0, got %g", m)
+	}
+	silver := &models.CallsignProfile{RenownLevel: 3, RestedBonusSeconds: 600}
+	if m := s.applyRestedBonus(silver, 60); m != 2.5 {
+		t.Errorf("silver: expected 2.5, got %g", m)
+	}
+	legend := &models.CallsignProfile{RenownLevel: 7, RestedBonusSeconds: 30}
+	if m := s.applyRestedBonus(legend, 60); m != 2.0 {
+		// half the transmission at 3.0x, half at 1.0x
+		t.Errorf("legend partial: expected 2.0, got %g", m)
+	}
+}
@@ -2,9 +2,12 @@ package gamification
 
 import (
 	"context"
+	"errors"
 	"log"
+	"sync"
 	"time"
 
+	cfgpkg "github.com/dbehnke/allstar-nexus/backend/config"
 	"github.com/dbehnke/allstar-nexus/backend/models"
 	"github.com/dbehnke/allstar-nexus/backend/repository"
 	"go.uber.org/zap"
@@ -35,14 +38,35 @@ type Config struct {
 
 	// XP Caps
 	CapsEnabled      bool
-	DailyCapSeconds  int // 1,200 = 20 minutes/day for low-activity hub
-	WeeklyCapSeconds int // 7,200 = 2 hours/week for low-activity hub
+	DailyCapSeconds  int         // 1,200 = 20 minutes/day for low-activity hub
+	WeeklyCapSeconds int         // 7,200 = 2 hours/week for low-activity hub
+	CapSchedule      CapSchedule // calendar reset boundaries; zero value = midnight UTC, weeks start Sunday
 
 	// Renown (prestige)
 	RenownEnabled    bool
-	RenownXPPerLevel int // fixed XP required per renown-level (applies after level 60)
+	RenownXPPerLevel int                 // fixed XP required per renown-level (applies after level 60)
+	RenownTiers      []cfgpkg.RenownTier // titles and rested-bonus boosts per renown range
+
+	// Backlogs older than this at startup (e.g. after days of downtime) are tallied in
+	// the background so startup isn't blocked; zero means DefaultBacklogThreshold.
+	BacklogThreshold time.Duration
 }
 
+// DefaultBacklogThreshold is the startup backlog beyond which the catch-up tally
+// runs in the background.
+const DefaultBacklogThreshold = 24 * time.Hour
+
+// backlogYield is the pause between windows of a background catch-up tally, which
+// lets API requests get at the database between window transactions.
+const backlogYield = 10 * time.Millisecond
+
+// Tally run triggers, as recorded in models.TallyRun.
+const (
+	triggerScheduled = "scheduled"
+	triggerManual    = "manual"
+	triggerBacklog   = "backlog"
+)
+
 type DRTier struct {
 	MaxSeconds int     // upper bound for this tier
 	Multiplier float64 // XP multiplier for this tier
@@ -51,7 +75,7 @@ type DRTier struct {
 // TallyService processes XP from transmission logs periodically
 type TallyService struct {
 	db                *gorm.DB
-	txLogRepo         *repository.TransmissionLogRepository
+	txLogRepo         repository.TxLogStore
 	profileRepo       *repository.CallsignProfileRepo
 	levelConfigRepo   *repository.LevelConfigRepo
 	activityRepo      *repository.XPActivityRepo
@@ -62,9 +86,46 @@ type TallyService struct {
 	ticker            *time.Ticker
 	stopChan          chan struct{}
 	lastTallyTime     time.Time
+	capDayStart       time.Time // start of the daily cap period last seen by the tally
+	capWeekStart      time.Time // start of the weekly cap period last seen by the tally
 	logger            *zap.Logger
 	// Optional hook invoked after each tally completes
 	OnTallyComplete func(summary TallySummary)
+	// Optional callback invoked for each profile that gained a level (or renown) during a tally
+	OnLevelUp func(callsign string, level, renown int)
+	// Optional clock sanity provider; runs during detected skew are annotated for later correction
+	ClockSkew func() (offset time.Duration, skewed bool)
+	// Optional filter; callsigns it reports (e.g. fully excluded opt-outs) earn no XP
+	Excluded func(callsign string) bool
+	// Optional store for level/renown changes; see SetLevelHistory
+	levelHistory *repository.LevelHistoryRepo
+	// Optional store for run outcomes; see SetTallyRuns
+	tallyRuns  *repository.TallyRunRepo
+	runMu      sync.Mutex // serializes scheduled and manual tallies
+	progressMu sync.Mutex
+	progress   TallyProgress
+	onProgress func(p TallyProgress) // see SetProgressHook
+}
+
+// ErrTallyRunning is returned by RunNow when a tally is already in progress.
+var ErrTallyRunning = errors.New("tally already running")
+
+// ErrTallyStopped is returned when a tally is interrupted by Stop. Completed
+// windows are persisted, so the next run resumes where it left off.
+var ErrTallyStopped = errors.New("tally stopped")
+
+// TallyProgress reports how far the current (or last) tally has got through its
+// windows.
+type TallyProgress struct {
+	Running     bool      `json:"running"`
+	Background  bool      `json:"background"` // catching up a startup backlog
+	From        time.Time `json:"from"`
+	To          time.Time `json:"to"`
+	Cursor      time.Time `json:"cursor"` // end of the last completed window
+	Windows     int       `json:"windows"`
+	WindowsDone int       `json:"windows_done"`
+	Percent     float64   `json:"percent"`
+	StartedAt   time.Time `json:"started_at"`
 }
 
 // TallySummary contains basic metrics about a completed tally run
@@ -73,11 +134,15 @@ type TallySummary struct {
 	TransmissionsHandled int       `json:"transmissions_handled"`
 	StartedAt            time.Time `json:"started_at"`
 	CompletedAt          time.Time `json:"completed_at"`
+	ClockSkewed          bool      `json:"clock_skewed,omitempty"`
+	ClockOffsetSeconds   float64   `json:"clock_offset_seconds,omitempty"`
+	TransmissionsSkipped int       `json:"transmissions_skipped,omitempty"` // already tallied by an earlier, interrupted run
+	Errors               int       `json:"errors,omitempty"`                // per-callsign failures that did not abort the run
 }
 
 func NewTallyService(
 	db *gorm.DB,
-	txRepo *repository.TransmissionLogRepository,
+	txRepo repository.TxLogStore,
 	profileRepo *repository.CallsignProfileRepo,
 	levelRepo *repository.LevelConfigRepo,
 	activityRepo *repository.XPActivityRepo,
@@ -101,6 +166,16 @@ func NewTallyService(
 	}
 }
 
+// SetLevelHistory records every level and renown change into repo.
+func (s *TallyService) SetLevelHistory(repo *repository.LevelHistoryRepo) {
+	s.levelHistory = repo
+}
+
+// SetTallyRuns records the outcome of every tally into repo.
+func (s *TallyService) SetTallyRuns(repo *repository.TallyRunRepo) {
+	s.tallyRuns = repo
+}
+
 func (s *TallyService) Start() error {
 	// Load level requirements
 	levelMap, err := s.levelConfigRepo.GetAllAsMap(context.Background())
@@ -130,14 +205,33 @@ func (s *TallyService) Start() error {
 	// Test-trace: emit at error-level so it shows in tests
 	s.logger.Error("tally.start.seed", zap.Time("lastTallyTime", s.lastTallyTime))
 
-	// Run initial tally
-	if err := s.ProcessTally(); err != nil {
-		s.logger.Error("Initial tally failed", zap.Error(err))
-	}
-
 	// Start ticker
 	s.ticker = time.NewTicker(s.tallyInterval)
+
+	threshold := s.config.BacklogThreshold
+	if threshold <= 0 {
+		threshold = DefaultBacklogThreshold
+	}
+	background := time.Since(s.lastTallyTime) > threshold
+	if background {
+		s.logger.Info("Tally backlog exceeds threshold; catching up in the background",
+			zap.Time("from", s.lastTallyTime), zap.Duration("threshold", threshold))
+	} else {
+		// Run initial tally
+		if err := s.ProcessTally(); err != nil {
+			s.logger.Error("Initial tally failed", zap.Error(err))
+		}
+	}
+
 	go func() {
+		if background {
+			s.runMu.Lock()
+			_, err := s.run(triggerBacklog)
+			s.runMu.Unlock()
+			if err != nil && !errors.Is(err, ErrTallyStopped) {
+				s.logger.Error("Backlog tally failed", zap.Error(err))
+			}
+		}
 		for {
 			select {
 			case <-s.ticker.C:
@@ -161,7 +255,83 @@ func (s *TallyService) Stop() {
 	close(s.stopChan)
 }
 
+// ProcessTally runs a scheduled tally, waiting for any run already in progress.
 func (s *TallyService) ProcessTally() error {
+	s.runMu.Lock()
+	defer s.runMu.Unlock()
+	_, err := s.run(triggerScheduled)
+	return err
+}
+
+// RunNow runs a tally immediately unless one is already in progress, in which
+// case it returns ErrTallyRunning.
+func (s *TallyService) RunNow() (TallySummary, error) {
+	if !s.runMu.TryLock() {
+		return TallySummary{}, ErrTallyRunning
+	}
+	defer s.runMu.Unlock()
+	return s.run(triggerManual)
+}
+
+// run processes a tally and records its outcome when a run store is set.
+func (s *TallyService) run(trigger string) (TallySummary, error) {
+	started := time.Now()
+	summary, err := s.processTally(trigger == triggerBacklog)
+	if s.tallyRuns != nil {
+		finished := time.Now()
+		rec := &models.TallyRun{
+			Trigger:              trigger,
+			StartedAt:            started.UTC(),
+			FinishedAt:           finished.UTC(),
+			WindowEnd:            summary.CompletedAt.UTC(),
+			DurationMs:           finished.Sub(started).Milliseconds(),
+			CallsignsProcessed:   summary.CallsignsProcessed,
+			TransmissionsHandled: summary.TransmissionsHandled,
+			Errors:               summary.Errors,
+			ClockSkewed:          summary.ClockSkewed,
+		}
+		if err != nil {
+			rec.Error = err.Error()
+		}
+		if rerr := s.tallyRuns.Record(context.Background(), rec); rerr != nil {
+			s.logger.Warn("failed to record tally run", zap.Error(rerr))
+		}
+	}
+	return summary, err
+}
+
+// Progress returns the progress of the current tally, or of the last one if none
+// is running.
+func (s *TallyService) Progress() TallyProgress {
+	s.progressMu.Lock()
+	defer s.progressMu.Unlock()
+	return s.progress
+}
+
+// SetProgressHook registers fn to be called after each window of a background
+// catch-up tally and when it ends. It may be set while a tally is running.
+func (s *TallyService) SetProgressHook(fn func(p TallyProgress)) {
+	s.progressMu.Lock()
+	defer s.progressMu.Unlock()
+	s.onProgress = fn
+}
+
+func (s *TallyService) setProgress(update func(p *TallyProgress)) {
+	s.progressMu.Lock()
+	update(&s.progress)
+	if s.progress.Windows > 0 {
+		s.progress.Percent = float64(s.progress.WindowsDone) * 100 / float64(s.progress.Windows)
+	}
+	p, hook := s.progress, s.onProgress
+	s.progressMu.Unlock()
+	if p.Background && hook != nil {
+		hook(p)
+	}
+}
+
+// processTally tallies every window from the last tally time to now. A background
+// (catch-up) tally pauses between windows and stops early on Stop.
+func (s *TallyService) processTally(background bool) (TallySummary, error) {
 	ctx := context.Background()
 
 	now := time.Now().UTC()
@@ -169,100 +339,52 @@ func (s *TallyService) ProcessTally() error {
 	s.logger.Error("tally.process.begin", zap.Time("from", s.lastTallyTime), zap.Time("to", now))
 	s.logger.Info("Processing XP tally (windowed)", zap.Time("from", s.lastTallyTime), zap.Time("to", now), zap.Duration("step", s.tallyInterval))
 
+	s.rollCapPeriods(ctx, now)
+
 	processed := make(map[string]struct{})
 	summary := TallySummary{
 		CallsignsProcessed:   0,
 		TransmissionsHandled: 0,
 		StartedAt:            time.Now(),
 	}
+	if s.ClockSkew != nil {
+		if offset, skewed := s.ClockSkew(); skewed {
+			summary.ClockSkewed = true
+			summary.ClockOffsetSeconds = offset.Seconds()
+			s.logger.Warn("tally running during detected clock skew; window will be annotated", zap.Duration("offset", offset))
+		}
+	}
 
-	// Helper: process grouped logs for a window
-	processGroup := func(transmissions map[string][]models.TransmissionLog) {
+	// Helper: process grouped logs for the window starting at windowStart. Each
+	// callsign is tallied in its own transaction together with its window mark, so a
+	// failure rolls back that callsign's XP and a re-run never counts it twice.
+	processGroup := func(windowStart, windowEnd time.Time, transmissions map[string][]models.TransmissionLog) {
 		for callsign, txLogs := range transmissions {
-			if callsign == "" {
+			if callsign == "" || (s.Excluded != nil && s.Excluded(callsign)) {
 				continue
 			}
 			processed[callsign] = struct{}{}
 
-			// Load or create profile
-			profile, err := s.profileRepo.GetByCallsign(ctx, callsign)
+			var result callsignTally
+			err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+				var err error
+				result, err = s.tallyCallsign(ctx, tx, callsign, windowStart, windowEnd, txLogs, now)
+				return err
+			})
 			if err != nil {
-				s.logger.Error("Failed to get profile", zap.String("callsign", callsign), zap.Error(err))
+				s.logger.Error("Tally rolled back for callsign", zap.String("callsign", callsign), zap.Time("window_start", windowStart), zap.Error(err))
+				summary.Errors++
 				continue
 			}
-
-			// Update rested bonus accumulation
-			s.updateRestedBonus(profile)
-
-			// Caps context
-			weeklyXP, dailyXP := 0, 0
-			if s.config.CapsEnabled {
-				weeklyXP, _ = s.activityRepo.GetWeeklyXP(ctx, callsign)
-				dailyXP, _ = s.activityRepo.GetDailyXP(ctx, callsign)
-			}
-
-			// DR context
-			currentDailySeconds := 0
-			if s.config.DREnabled {
-				recentActivity, _ := s.activityRepo.GetLast24Hours(ctx, callsign)
-				for _, activity := range recentActivity {
-					currentDailySeconds += activity.RawXP
-				}
-			}
-
-			// Process each transmission in order
-			for i, tx := range txLogs {
-				rawXP := tx.DurationSeconds
-				summary.TransmissionsHandled++
-
-				if s.config.CapsEnabled && weeklyXP >= s.config.WeeklyCapSeconds {
-					_ = s.activityRepo.LogActivity(ctx, callsign, rawXP, 0, 0, 0, 0)
-					continue
-				}
-				if s.config.CapsEnabled && dailyXP >= s.config.DailyCapSeconds {
-					_ = s.activityRepo.LogActivity(ctx, callsign, rawXP, 0, 0, 0, 0)
-					continue
-				}
-
-				restedMultiplier := s.applyRestedBonus(profile, tx.DurationSeconds)
-				drMultiplier := s.calculateDRMultiplier(currentDailySeconds)
-				currentDailySeconds += tx.DurationSeconds
-				kerchunkPenalty := s.calculateKerchunkPenalty(txLogs[:i], tx)
-
-				finalXP := float64(rawXP) * restedMultiplier * drMultiplier * kerchunkPenalty
-				awardedXP := int(finalXP)
-
-				if s.config.CapsEnabled {
-					remainingDaily := s.config.DailyCapSeconds - dailyXP
-					if awardedXP > remainingDaily {
-						awardedXP = remainingDaily
-					}
-					remainingWeekly := s.config.WeeklyCapSeconds - weeklyXP
-					if awardedXP > remainingWeekly {
-						awardedXP = remainingWeekly
-					}
+			summary.TransmissionsHandled += result.handled
+			summary.TransmissionsSkipped += result.skipped
+
+			if result.leveledUp {
+				p := result.profile
+				s.logger.Info("Level up!", zap.String("callsign", p.Callsign), zap.Int("level", p.Level), zap.Int("renown", p.RenownLevel))
+				if s.OnLevelUp != nil {
+					s.OnLevelUp(p.Callsign, p.Level, p.RenownLevel)
 				}
-
-				_ = s.activityRepo.LogActivity(ctx, callsign, rawXP, awardedXP, restedMultiplier, drMultiplier, kerchunkPenalty)
-				profile.ExperiencePoints += awardedXP
-				profile.DailyXP += awardedXP
-				profile.WeeklyXP += awardedXP
-				weeklyXP += awardedXP
-				dailyXP += awardedXP
-			}
-
-			if len(txLogs) > 0 {
-				profile.LastTransmissionAt = txLogs[len(txLogs)-1].TimestampEnd
-				profile.LastTallyAt = time.Now().UTC()
-			}
-
-			leveledUp := s.processLevelUps(profile)
-			if leveledUp {
-				s.logger.Info("Level up!", zap.String("callsign", profile.Callsign), zap.Int("level", profile.Level), zap.Int("renown", profile.RenownLevel))
-			}
-
-			if err := s.profileRepo.Upsert(ctx, profile); err != nil {
-				s.logger.Error("Failed to save profile", zap.String("callsign", callsign), zap.Error(err))
 			}
 		}
 	}
@@ -273,7 +395,24 @@ func (s *TallyService) ProcessTally() error {
 	if cursor.IsZero() || cursor.After(now) {
 		cursor = now.Add(-s.tallyInterval)
 	}
-	for cursor.Before(now) {
+	windows := 0
+	if s.tallyInterval > 0 && cursor.Before(now) {
+		windows = int((now.Sub(cursor) + s.tallyInterval - 1) / s.tallyInterval)
+	}
+	s.setProgress(func(p *TallyProgress) {
+		*p = TallyProgress{Running: true, Background: background, From: cursor, To: now, Cursor: cursor, Windows: windows, StartedAt: time.Now()}
+	})
+	defer s.setProgress(func(p *TallyProgress) { p.Running = false })
+	for done := 0; cursor.Before(now); done++ {
+		if background && done > 0 {
+			select {
+			case <-s.stopChan:
+				summary.CallsignsProcessed = len(processed)
+				summary.CompletedAt = s.lastTallyTime
+				return summary, ErrTallyStopped
+			case <-time.After(backlogYield):
+			}
+		}
 		next := cursor.Add(s.tallyInterval)
 		if next.After(now) {
 			next = now
@@ -283,7 +422,7 @@ func (s *TallyService) ProcessTally() error {
 		s.logger.Error("tally.process.window", zap.Time("from", cursor), zap.Time("to", next))
 		transmissions, err := s.txLogRepo.GetLogsBetween(cursor, next)
 		if err != nil {
-			return err
+			return summary, err
 		}
 		if len(transmissions) > 0 {
 			// Test-trace: log counts
@@ -292,7 +431,7 @@ func (s *TallyService) ProcessTally() error {
 				txCount += len(arr)
 			}
 			s.logger.Error("tally.window.results", zap.Int("callsigns", len(transmissions)), zap.Int("tx_count", txCount))
-			processGroup(transmissions)
+			processGroup(cursor, next, transmissions)
 		}
 		// Persist window completion
 		s.lastTallyTime = next
@@ -302,6 +441,10 @@ func (s *TallyService) ProcessTally() error {
 			}
 		}
 		cursor = next
+		s.setProgress(func(p *TallyProgress) {
+			p.Cursor = next
+			p.WindowsDone++
+		})
 	}
 
 	summary.CallsignsProcessed = len(processed)
@@ -309,15 +452,15 @@ func (s *TallyService) ProcessTally() error {
 
 	// Fallback: if no transmissions were handled (e.g., due to DB datetime format edge cases),
 	// run a single-batch tally using the legacy GetLogsSince path to preserve compatibility.
-	if summary.TransmissionsHandled == 0 {
+	if summary.TransmissionsHandled+summary.TransmissionsSkipped == 0 {
 		s.logger.Info("Windowed tally handled 0 transmissions; falling back to single-batch GetLogsSince",
 			zap.Time("since", originalStart))
 		transmissions, err := s.txLogRepo.GetLogsSince(originalStart)
 		if err != nil {
-			return err
+			return summary, err
 		}
 		if len(transmissions) > 0 {
-			processGroup(transmissions)
+			processGroup(originalStart, now, transmissions)
 			s.lastTallyTime = now
 			summary.CompletedAt = s.lastTallyTime
 			if s.stateRepo != nil {
@@ -329,6 +472,25 @@ func (s *TallyService) ProcessTally() error {
 		}
 	}
 
+	// Marks only matter for windows that can still be re-run
+	if s.stateRepo != nil {
+		if _, err := s.stateRepo.PruneWindowMarks(ctx, originalStart.Add(-24*time.Hour)); err != nil {
+			s.logger.Warn("failed to prune tally window marks", zap.Error(err))
+		}
+	}
+
+	if summary.ClockSkewed && s.stateRepo != nil {
+		annotation := &models.TallySkewAnnotation{
+			WindowStart:   originalStart,
+			WindowEnd:     summary.CompletedAt,
+			OffsetSeconds: summary.ClockOffsetSeconds,
+			Transmissions: summary.TransmissionsHandled,
+		}
+		if err := s.stateRepo.AddSkewAnnotation(ctx, annotation); err != nil {
+			s.logger.Warn("failed to record tally skew annotation", zap.Error(err))
+		}
+	}
+
 	// Process rested XP accumulation for ALL profiles (including idle ones)
 	// This ensures users who haven't transmitted recently still accumulate rested bonus
 	if s.config.RestedEnabled {
@@ -372,7 +534,198 @@ func (s *TallyService) ProcessTally() error {
 			cb(sum)
 		}(s.OnTallyComplete, summary)
 	}
-	return nil
+	return summary, nil
+}
+
+// callsignTally is the outcome of one callsign's share of a tally window.
+type callsignTally struct {
+	handled   int
+	skipped   int
+	leveledUp bool
+	profile   *models.CallsignProfile
+}
+
+// tallyCallsign awards XP for callsign's transmissions in one window using db for
+// every read and write. Transmissions covered by the callsign's mark for this
+// window were awarded by an earlier run and are skipped.
+func (s *TallyService) tallyCallsign(ctx context.Context, db *gorm.DB, callsign string, windowStart, windowEnd time.Time, txLogs []models.TransmissionLog, now time.Time) (callsignTally, error) {
+	var result callsignTally
+	profileRepo := s.profileRepo.WithTx(db)
+	activityRepo := s.activityRepo.WithTx(db)
+
+	var mark *models.TallyWindowMark
+	if s.stateRepo != nil {
+		var err error
+		if mark, err = s.stateRepo.WithTx(db).GetWindowMark(ctx, callsign, windowStart); err != nil {
+			return result, err
+		}
+	}
+	pending := txLogs[:0:0]
+	for _, tx := range txLogs {
+		if mark.Covers(tx) {
+			result.skipped++
+			continue
+		}
+		pending = append(pending, tx)
+	}
+	if len(pending) == 0 {
+		return result, nil
+	}
+
+	// Load or create profile
+	profile, err := profileRepo.GetByCallsign(ctx, callsign)
+	if err != nil {
+		return result, err
+	}
+
+	// Update rested bonus accumulation
+	s.updateRestedBonus(profile)
+
+	// Caps context
+	weeklyXP, dailyXP := 0, 0
+	if s.config.CapsEnabled {
+		weeklyXP, _ = activityRepo.GetWeeklyXP(ctx, callsign)
+		dailyXP, _ = activityRepo.GetDailyXP(ctx, callsign)
+	}
+
+	// DR context
+	currentDailySeconds := 0
+	if s.config.DREnabled {
+		recentActivity, _ := activityRepo.GetLast24Hours(ctx, callsign)
+		for _, activity := range recentActivity {
+			currentDailySeconds += activity.RawXP
+		}
+	}
+
+	// Process each transmission in order; kerchunk detection still sees the whole window
+	for _, tx := range pending {
+		rawXP := tx.DurationSeconds
+		result.handled++
+
+		if s.config.CapsEnabled && (weeklyXP >= s.config.WeeklyCapSeconds || dailyXP >= s.config.DailyCapSeconds) {
+			if err := activityRepo.LogActivity(ctx, callsign, rawXP, 0, 0, 0, 0); err != nil {
+				return result, err
+			}
+			continue
+		}
+
+		restedMultiplier := s.applyRestedBonus(profile, tx.DurationSeconds)
+		drMultiplier := s.calculateDRMultiplier(currentDailySeconds)
+		currentDailySeconds += tx.DurationSeconds
+		kerchunkPenalty := s.calculateKerchunkPenalty(precedingLogs(txLogs, tx), tx)
+
+		finalXP := float64(rawXP) * restedMultiplier * drMultiplier * kerchunkPenalty
+		awardedXP := int(finalXP)
+
+		if s.config.CapsEnabled {
+			remainingDaily := s.config.DailyCapSeconds - dailyXP
+			if awardedXP > remainingDaily {
+				awardedXP = remainingDaily
+			}
+			remainingWeekly := s.config.WeeklyCapSeconds - weeklyXP
+			if awardedXP > remainingWeekly {
+				awardedXP = remainingWeekly
+			}
+		}
+
+		if err := activityRepo.LogActivity(ctx, callsign, rawXP, awardedXP, restedMultiplier, drMultiplier, kerchunkPenalty); err != nil {
+			return result, err
+		}
+		profile.ExperiencePoints += awardedXP
+		profile.DailyXP += awardedXP
+		profile.WeeklyXP += awardedXP
+		weeklyXP += awardedXP
+		dailyXP += awardedXP
+	}
+
+	last := pending[len(pending)-1]
+	profile.LastTransmissionAt = last.TimestampEnd
+	profile.LastTallyAt = time.Now().UTC()
+
+	// Date changes by the transmission that earned them, so catch-up tallies chart correctly
+	reachedAt := now
+	if !profile.LastTransmissionAt.IsZero() {
+		reachedAt = profile.LastTransmissionAt.UTC()
+	}
+	changes := s.processLevelUps(profile, reachedAt)
+	if s.levelHistory != nil {
+		if err := s.levelHistory.WithTx(db).Record(ctx, changes); err != nil {
+			return result, err
+		}
+	}
+
+	if err := profileRepo.Upsert(ctx, profile); err != nil {
+		return result, err
+	}
+
+	if s.stateRepo != nil {
+		if mark == nil {
+			mark = &models.TallyWindowMark{Callsign: callsign, WindowStart: windowStart}
+		}
+		mark.WindowEnd = windowEnd
+		mark.LastTxStart = last.TimestampStart
+		mark.LastTxID = last.ID
+		mark.Transmissions += len(pending)
+		if err := s.stateRepo.WithTx(db).SaveWindowMark(ctx, mark); err != nil {
+			return result, err
+		}
+	}
+
+	result.leveledUp = len(changes) > 0
+	result.profile = profile
+	return result, nil
+}
+
+// precedingLogs returns the transmissions in logs that come before tx.
+func precedingLogs(logs []models.TransmissionLog, tx models.TransmissionLog) []models.TransmissionLog {
+	for i := range logs {
+		if logs[i].ID == tx.ID {
+			return logs[:i]
+		}
+	}
+	return logs
+}
+
+// rollCapPeriods zeroes profile DailyXP/WeeklyXP counters when a calendar cap period
+// (in the configured hub timezone) has ended since the profile was last tallied.
+func (s *TallyService) rollCapPeriods(ctx context.Context, now time.Time) {
+	dayStart := s.config.CapSchedule.DayStart(now)
+	weekStart := s.config.CapSchedule.WeekStart(now)
+	if dayStart.Equal(s.capDayStart) && weekStart.Equal(s.capWeekStart) {
+		return
+	}
+	profiles, err := s.profileRepo.GetAllProfiles(ctx)
+	if err != nil {
+		s.logger.Warn("Failed to load profiles for cap reset", zap.Error(err))
+		return
+	}
+	reset := 0
+	for i := range profiles {
+		p := &profiles[i]
+		changed := false
+		if p.DailyXP != 0 && p.LastTallyAt.Before(dayStart) {
+			p.DailyXP = 0
+			changed = true
+		}
+		if p.WeeklyXP != 0 && p.LastTallyAt.Before(weekStart) {
+			p.WeeklyXP = 0
+			changed = true
+		}
+		if !changed {
+			continue
+		}
+		if err := s.profileRepo.Upsert(ctx, p); err != nil {
+			s.logger.Warn("Failed to reset cap counters", zap.String("callsign", p.Callsign), zap.Error(err))
+			continue
+		}
+		reset++
+	}
+	s.capDayStart = dayStart
+	s.capWeekStart = weekStart
+	s.logger.Info("XP cap periods rolled over",
+		zap.Time("day_start", dayStart),
+		zap.Time("week_start", weekStart),
+		zap.Int("profiles_reset", reset))
 }
 
 // updateRestedBonus accumulates rested bonus for inactive callsigns
@@ -409,9 +762,13 @@ func (s *TallyService) updateRestedBonus(profile *models.CallsignProfile) {
 	bonusHours := hoursIdle * s.config.RestedAccumulationRate
 	profile.RestedBonusSeconds += int(bonusHours * 3600)
 
-	// Cap at maximum
-	if profile.RestedBonusSeconds > s.config.RestedMaxSeconds {
-		profile.RestedBonusSeconds = s.config.RestedMaxSeconds
+	// Cap at maximum, raised for renown tiers that grant extra rested hours
+	maxSeconds := s.config.RestedMaxSeconds
+	if tier := GetRenownTier(profile.RenownLevel, s.config.RenownTiers); tier != nil {
+		maxSeconds += tier.RestedMaxHoursBonus * 3600
+	}
+	if profile.RestedBonusSeconds > maxSeconds {
+		profile.RestedBonusSeconds = maxSeconds
 	}
 
 	// Update the last calculation timestamp
@@ -424,6 +781,11 @@ func (s *TallyService) applyRestedBonus(profile *models.CallsignProfile, duratio
 		return 1.0
 	}
 
+	rested := s.config.RestedMultiplier
+	if tier := GetRenownTier(profile.RenownLevel, s.config.RenownTiers); tier != nil {
+		rested += tier.RestedMultiplierBonus
+	}
+
 	// Consume rested bonus
 	profile.RestedBonusSeconds -= durationSeconds
 
@@ -431,12 +793,12 @@ func (s *TallyService) applyRestedBonus(profile *models.CallsignProfile, duratio
 	if profile.RestedBonusSeconds < 0 {
 		secondsWithBonus := durationSeconds + profile.RestedBonusSeconds
 		secondsWithoutBonus := -profile.RestedBonusSeconds
-		multiplier := (float64(secondsWithBonus)*s.config.RestedMultiplier + float64(secondsWithoutBonus)) / float64(durationSeconds)
+		multiplier := (float64(secondsWithBonus)*rested + float64(secondsWithoutBonus)) / float64(durationSeconds)
 		profile.RestedBonusSeconds = 0
 		return multiplier
 	}
 
-	return s.config.RestedMultiplier
+	return rested
 }
 
 // calculateDRMultiplier returns diminishing returns multiplier based on daily talk time
@@ -499,9 +861,16 @@ func (s *TallyService) calculateKerchunkPenalty(previousLogs []models.Transmissi
 	}
 }
 
-// processLevelUps handles leveling up (including renown/prestige)
-func (s *TallyService) processLevelUps(profile *models.CallsignProfile) bool {
-	leveledUp := false
+// processLevelUps handles leveling up (including renown/prestige) and returns one
+// history entry per level or renown gained, stamped at.
+func (s *TallyService) processLevelUps(profile *models.CallsignProfile, at time.Time) []models.LevelHistory {
+	var changes []models.LevelHistory
+	record := func(kind string) {
+		changes = append(changes, models.LevelHistory{
+			Callsign: profile.Callsign, Kind: kind, Level: profile.Level, RenownLevel: profile.RenownLevel,
+			CarryoverXP: profile.ExperiencePoints, ReachedAt: at,
+		})
+	}
 
 	// Loop to handle multiple level-ups at once
 	for {
@@ -530,7 +899,6 @@ func (s *TallyService) processLevelUps(profile *models.CallsignProfile) bool {
 		// Level up!
 		profile.ExperiencePoints -= requiredXP
 		profile.Level++
-		leveledUp = true
 
 		// If we've reached level 60 (i.e., reached renown threshold), award renown
 		if profile.Level >= 60 {
@@ -546,9 +914,11 @@ func (s *TallyService) processLevelUps(profile *models.CallsignProfile) bool {
 				zap.Int("renown", profile.RenownLevel),
 				zap.Int("carryover_xp", profile.ExperiencePoints),
 			)
+			record(models.LevelHistoryRenown)
 			break // Stop after renown to avoid infinite loop
 		}
+		record(models.LevelHistoryLevel)
 	}
 
-	return leveledUp
+	return changes
 }
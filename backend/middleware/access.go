@@ -0,0 +1,102 @@
+package middleware
+
+import (
+	"net/http"
+	"sort"
+)
+
+// Access levels for routes registered through an AccessRouter.
+const (
+	AccessAnonymous = "anonymous"
+	AccessUser      = "user"
+	AccessAdmin     = "admin"
+)
+
+// ValidAccess reports whether level is a known access level.
+func ValidAccess(level string) bool {
+	switch level {
+	case AccessAnonymous, AccessUser, AccessAdmin:
+		return true
+	}
+	return false
+}
+
+// AccessRouterOptions configures an AccessRouter.
+type AccessRouterOptions struct {
+	Default  string            // level for routes without a policy or registration default
+	Policies map[string]string // route path (as registered) -> access level
+	Auth     func(http.Handler) http.Handler
+	Admin    func(http.Handler) http.Handler                    // applied after Auth for admin routes
+	Public   func(route string) func(http.Handler) http.Handler // wraps anonymous routes, e.g. with a rate limit
+}
+
+// AccessRouter registers read endpoints with the access level configured for each route,
+// so operators can open or close individual endpoints independently of the dashboard-wide
+// default.
+type AccessRouter struct {
+	mux  *http.ServeMux
+	opts AccessRouterOptions
+	used map[string]bool
+}
+
+// NewAccessRouter creates a router registering on mux.
+func NewAccessRouter(mux *http.ServeMux, opts AccessRouterOptions) *AccessRouter {
+	if opts.Policies == nil {
+		opts.Policies = map[string]string{}
+	}
+	if opts.Public == nil {
+		opts.Public = func(string) func(http.Handler) http.Handler {
+			return func(next http.Handler) http.Handler { return next }
+		}
+	}
+	return &AccessRouter{mux: mux, opts: opts, used: map[string]bool{}}
+}
+
+// Handle registers h at route with its configured level, or the router default.
+func (ar *AccessRouter) Handle(route string, h http.Handler) {
+	ar.HandleDefault(route, ar.opts.Default, h)
+}
+
+// HandleDefault registers h at route with its configured level, or fallback when the
+// route has no policy.
+func (ar *AccessRouter) HandleDefault(route, fallback string, h http.Handler) {
+	ar.used[route] = true
+	switch ar.level(route, fallback) {
+	case AccessAnonymous:
+		h = ar.opts.Public(route)(h)
+	case AccessAdmin:
+		h = ar.opts.Auth(ar.opts.Admin(h))
+	default:
+		h = ar.opts.Auth(h)
+	}
+	ar.mux.Handle(route, h)
+}
+
+// Level returns the access level route is (or would be) registered with by Handle.
+func (ar *AccessRouter) Level(route string) string {
+	return ar.level(route, ar.opts.Default)
+}
+
+func (ar *AccessRouter) level(route, fallback string) string {
+	l, ok := ar.opts.Policies[route]
+	switch {
+	case !ok:
+		return fallback
+	case !ValidAccess(l):
+		return AccessAdmin // fail closed on a misspelt level
+	}
+	return l
+}
+
+// Unused lists configured policies naming routes that were never registered, usually a
+// typo or an endpoint whose feature is disabled.
+func (ar *AccessRouter) Unused() []string {
+	var out []string
+	for route := range ar.opts.Policies {
+		if !ar.used[route] {
+			out = append(out, route)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
@@ -0,0 +1,181 @@
+package middleware
+
+import (
+	"bytes"
+	"container/list"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/dbehnke/allstar-nexus/internal/tenant"
+)
+
+// DefaultCacheEntries bounds the number of responses a ResponseCache holds.
+const DefaultCacheEntries = 1000
+
+// ResponseCache keeps successful GET responses in memory for a short TTL so that many
+// dashboard viewers polling the same public endpoint do not each query SQLite. Entries
+// carry a tag (e.g. "gamification") so they can be dropped as soon as the underlying
+// data changes. Responses are keyed by URL and credentials, so viewers with different
+// privacy classes (or hosted hubs) never share an entry. A nil *ResponseCache disables caching.
+type ResponseCache struct {
+	mu         sync.Mutex
+	maxEntries int
+	ttls       map[string]time.Duration // route overrides
+	entries    map[string]*list.Element
+	lru        *list.List // front = most recently used
+	now        func() time.Time
+	hits       atomic.Uint64
+	misses     atomic.Uint64
+}
+
+type cachedResponse struct {
+	key     string
+	tag     string
+	status  int
+	header  http.Header
+	body    []byte
+	expires time.Time
+}
+
+// NewResponseCache creates a cache; ttls maps route paths to TTL overrides (0 disables
+// caching for that route).
+func NewResponseCache(maxEntries int, ttls map[string]time.Duration) *ResponseCache {
+	if maxEntries <= 0 {
+		maxEntries = DefaultCacheEntries
+	}
+	return &ResponseCache{
+		maxEntries: maxEntries,
+		ttls:       ttls,
+		entries:    make(map[string]*list.Element),
+		lru:        list.New(),
+		now:        time.Now,
+	}
+}
+
+// For returns middleware caching route's responses under tag for its configured TTL,
+// or fallback if none is configured.
+func (c *ResponseCache) For(route, tag string, fallback time.Duration) func(http.Handler) http.Handler {
+	if c == nil {
+		return func(next http.Handler) http.Handler { return next }
+	}
+	ttl := fallback
+	if t, ok := c.ttls[route]; ok {
+		ttl = t
+	}
+	return func(next http.Handler) http.Handler {
+		if ttl <= 0 {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodGet {
+				next.ServeHTTP(w, r)
+				return
+			}
+			key := tag + "\x00" + tenant.FromContext(r.Context()).Key() + "\x00" + r.URL.RequestURI() + "\x00" + r.Header.Get("Authorization") + "\x00" + r.Header.Get("X-API-Key")
+			if e, ok := c.get(key); ok {
+				c.hits.Add(1)
+				for k, v := range e.header {
+					w.Header()[k] = v
+				}
+				w.Header().Set("X-Cache", "HIT")
+				w.WriteHeader(e.status)
+				_, _ = w.Write(e.body)
+				return
+			}
+			c.misses.Add(1)
+			rec := &recordingWriter{ResponseWriter: w, status: http.StatusOK}
+			w.Header().Set("X-Cache", "MISS")
+			next.ServeHTTP(rec, r)
+			if rec.status == http.StatusOK {
+				header := w.Header().Clone()
+				header.Del("X-Cache")
+				c.put(&cachedResponse{key: key, tag: tag, status: rec.status, header: header, body: rec.buf.Bytes(), expires: c.now().Add(ttl)})
+			}
+		})
+	}
+}
+
+// Invalidate drops every entry with one of tags.
+func (c *ResponseCache) Invalidate(tags ...string) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for key, el := range c.entries {
+		e := el.Value.(*cachedResponse)
+		for _, t := range tags {
+			if e.tag == t {
+				c.lru.Remove(el)
+				delete(c.entries, key)
+				break
+			}
+		}
+	}
+}
+
+// Stats reports cache hits, misses and the current number of entries.
+func (c *ResponseCache) Stats() (hits, misses uint64, entries int) {
+	if c == nil {
+		return 0, 0, 0
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.hits.Load(), c.misses.Load(), len(c.entries)
+}
+
+func (c *ResponseCache) get(key string) (*cachedResponse, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	e := el.Value.(*cachedResponse)
+	if !c.now().Before(e.expires) {
+		c.lru.Remove(el)
+		delete(c.entries, key)
+		return nil, false
+	}
+	c.lru.MoveToFront(el)
+	return e, true
+}
+
+func (c *ResponseCache) put(e *cachedResponse) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.entries[e.key]; ok {
+		el.Value = e
+		c.lru.MoveToFront(el)
+		return
+	}
+	c.entries[e.key] = c.lru.PushFront(e)
+	for c.lru.Len() > c.maxEntries {
+		oldest := c.lru.Back()
+		c.lru.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cachedResponse).key)
+	}
+}
+
+// recordingWriter passes a response through while keeping a copy of its status and body.
+type recordingWriter struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+	buf         bytes.Buffer
+}
+
+func (w *recordingWriter) WriteHeader(status int) {
+	if !w.wroteHeader {
+		w.status, w.wroteHeader = status, true
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *recordingWriter) Write(b []byte) (int, error) {
+	w.wroteHeader = true
+	w.buf.Write(b)
+	return w.ResponseWriter.Write(b)
+}
@@ -6,56 +6,21 @@ import (
 	"net"
 	"net/http"
 	"strings"
-	"sync"
 	"time"
 
 	"github.com/dbehnke/allstar-nexus/backend/auth"
 	"github.com/dbehnke/allstar-nexus/backend/repository"
+	"github.com/dbehnke/allstar-nexus/internal/tenant"
 )
 
 type key int
 
 const userKey key = 0
 
-// simpleTokenBucket holds state for a single IP.
-type simpleTokenBucket struct {
-	tokens     int
-	lastRefill time.Time
-}
-
-// RateLimiter middleware (fixed window token bucket per IP, refill each minute) for low volume prototypes.
+// RateLimiter middleware limits each client IP to maxPerMinute requests (token bucket, burst = maxPerMinute).
+// Use NewLimiter or RateLimits for burst, keying and per-route policies.
 func RateLimiter(maxPerMinute int) func(http.Handler) http.Handler {
-	if maxPerMinute <= 0 {
-		maxPerMinute = 60
-	}
-	buckets := make(map[string]*simpleTokenBucket)
-	mu := sync.Mutex{}
-	return func(next http.Handler) http.Handler {
-		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			ip := clientIP(r)
-			now := time.Now()
-			mu.Lock()
-			b, ok := buckets[ip]
-			if !ok {
-				b = &simpleTokenBucket{tokens: maxPerMinute, lastRefill: now}
-				buckets[ip] = b
-			}
-			// Refill per 60s window
-			if now.Sub(b.lastRefill) >= time.Minute {
-				b.tokens = maxPerMinute
-				b.lastRefill = now
-			}
-			if b.tokens <= 0 {
-				mu.Unlock()
-				w.Header().Set("Retry-After", "60")
-				writeJSONError(w, http.StatusTooManyRequests, "rate_limited", "rate limit exceeded")
-				return
-			}
-			b.tokens--
-			mu.Unlock()
-			next.ServeHTTP(w, r)
-		})
-	}
+	return NewLimiter(RatePolicy{RequestsPerMinute: maxPerMinute}, DefaultMaxKeys).Middleware
 }
 
 func clientIP(r *http.Request) string {
@@ -131,6 +96,30 @@ func RequireRole(roles ...string) func(http.Handler) http.Handler {
 	}
 }
 
+// RequireAdmin admits the instance's admins (see tenant.Registry.IsAdmin). With
+// tenantAdmins set it also admits the admins of the request's tenant; use it only for
+// routes whose handlers confine themselves to that tenant's nodes.
+func RequireAdmin(reg *tenant.Registry, tenantAdmins bool) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			u, ok := UserFromContext(r.Context())
+			if !ok {
+				writeJSONError(w, http.StatusUnauthorized, "unauthorized", "missing auth context")
+				return
+			}
+			var t *tenant.Tenant
+			if tenantAdmins {
+				t = tenant.FromContext(r.Context())
+			}
+			if !reg.IsAdmin(t, u.Email, u.Role) {
+				writeJSONError(w, http.StatusForbidden, "forbidden", "insufficient role")
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
 // writeJSONError returns standardized error envelope.
 func writeJSONError(w http.ResponseWriter, status int, code, msg string) {
 	w.Header().Set("Content-Type", "application/json")
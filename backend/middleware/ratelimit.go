@@ -0,0 +1,197 @@
+package middleware
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// KeyStrategy selects how requests are grouped into rate limit buckets.
+type KeyStrategy string
+
+const (
+	KeyByIP     KeyStrategy = "ip"      // client IP (X-Forwarded-For aware)
+	KeyByToken  KeyStrategy = "token"   // bearer token, falling back to IP for anonymous requests
+	KeyByAPIKey KeyStrategy = "api_key" // X-API-Key header or api_key query param, falling back to IP
+)
+
+// DefaultMaxKeys bounds the number of tracked clients per limiter.
+const DefaultMaxKeys = 10000
+
+// RatePolicy describes a token bucket: RequestsPerMinute refill rate and Burst capacity.
+// Burst defaults to RequestsPerMinute, matching the old fixed one-minute window.
+type RatePolicy struct {
+	RequestsPerMinute int
+	Burst             int
+	KeyBy             KeyStrategy
+}
+
+func (p RatePolicy) normalized() RatePolicy {
+	if p.RequestsPerMinute <= 0 {
+		p.RequestsPerMinute = 60
+	}
+	if p.Burst <= 0 {
+		p.Burst = p.RequestsPerMinute
+	}
+	switch p.KeyBy {
+	case KeyByIP, KeyByToken, KeyByAPIKey:
+	default:
+		p.KeyBy = KeyByIP
+	}
+	return p
+}
+
+// tokenBucket holds state for a single client key.
+type tokenBucket struct {
+	key        string
+	tokens     float64
+	lastRefill time.Time
+}
+
+// Limiter is a token bucket rate limiter whose per-client state lives in an LRU-bounded
+// store, so memory stays flat on long-running servers that see many unique clients.
+type Limiter struct {
+	policy  RatePolicy
+	maxKeys int
+	mu      sync.Mutex
+	order   *list.List // front = most recently used
+	buckets map[string]*list.Element
+	now     func() time.Time
+}
+
+// NewLimiter creates a limiter for policy tracking at most maxKeys clients (DefaultMaxKeys if <= 0).
+func NewLimiter(policy RatePolicy, maxKeys int) *Limiter {
+	if maxKeys <= 0 {
+		maxKeys = DefaultMaxKeys
+	}
+	return &Limiter{
+		policy:  policy.normalized(),
+		maxKeys: maxKeys,
+		order:   list.New(),
+		buckets: make(map[string]*list.Element),
+		now:     time.Now,
+	}
+}
+
+// Allow consumes a token for key. When the bucket is empty it returns false and the
+// time until the next token is available.
+func (l *Limiter) Allow(key string) (bool, time.Duration) {
+	now := l.now()
+	perSecond := float64(l.policy.RequestsPerMinute) / 60
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var b *tokenBucket
+	if el, ok := l.buckets[key]; ok {
+		l.order.MoveToFront(el)
+		b = el.Value.(*tokenBucket)
+		elapsed := now.Sub(b.lastRefill).Seconds()
+		if elapsed > 0 {
+			b.tokens = math.Min(float64(l.policy.Burst), b.tokens+elapsed*perSecond)
+			b.lastRefill = now
+		}
+	} else {
+		b = &tokenBucket{key: key, tokens: float64(l.policy.Burst), lastRefill: now}
+		l.buckets[key] = l.order.PushFront(b)
+		for l.order.Len() > l.maxKeys {
+			oldest := l.order.Back()
+			l.order.Remove(oldest)
+			delete(l.buckets, oldest.Value.(*tokenBucket).key)
+		}
+	}
+
+	if b.tokens < 1 {
+		wait := time.Duration((1 - b.tokens) / perSecond * float64(time.Second))
+		return false, wait
+	}
+	b.tokens--
+	return true, 0
+}
+
+// Len reports the number of clients currently tracked.
+func (l *Limiter) Len() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.order.Len()
+}
+
+// Middleware enforces the limiter on next, responding 429 with Retry-After when exceeded.
+func (l *Limiter) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ok, wait := l.Allow(clientKey(r, l.policy.KeyBy))
+		if !ok {
+			secs := int(math.Ceil(wait.Seconds()))
+			if secs < 1 {
+				secs = 1
+			}
+			w.Header().Set("Retry-After", strconv.Itoa(secs))
+			writeJSONError(w, http.StatusTooManyRequests, "rate_limited", "rate limit exceeded")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// clientKey derives the bucket key for r. Credentials are hashed so raw tokens are not retained.
+func clientKey(r *http.Request, by KeyStrategy) string {
+	switch by {
+	case KeyByToken:
+		if authz := r.Header.Get("Authorization"); strings.HasPrefix(authz, "Bearer ") {
+			return "tok:" + hashKey(strings.TrimPrefix(authz, "Bearer "))
+		}
+	case KeyByAPIKey:
+		k := r.Header.Get("X-API-Key")
+		if k == "" {
+			k = r.URL.Query().Get("api_key")
+		}
+		if k != "" {
+			return "key:" + hashKey(k)
+		}
+	}
+	return "ip:" + clientIP(r)
+}
+
+func hashKey(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:8])
+}
+
+// RateLimits builds per-route limiters from configured policies. Routes without an
+// explicit policy use the fallback supplied at registration time.
+type RateLimits struct {
+	maxKeys  int
+	policies map[string]RatePolicy
+}
+
+// NewRateLimits creates a registry; policies maps route paths (e.g. "/api/auth/login") to overrides.
+func NewRateLimits(maxKeys int, policies map[string]RatePolicy) *RateLimits {
+	if policies == nil {
+		policies = map[string]RatePolicy{}
+	}
+	return &RateLimits{maxKeys: maxKeys, policies: policies}
+}
+
+// For returns middleware for route using its configured policy, or fallback if none.
+// Each route gets an independent limiter.
+func (rl *RateLimits) For(route string, fallback RatePolicy) func(http.Handler) http.Handler {
+	policy := fallback
+	if p, ok := rl.policies[route]; ok {
+		// Unset fields inherit from the fallback
+		if p.RequestsPerMinute > 0 {
+			policy.RequestsPerMinute = p.RequestsPerMinute
+		}
+		if p.Burst > 0 {
+			policy.Burst = p.Burst
+		}
+		if p.KeyBy != "" {
+			policy.KeyBy = p.KeyBy
+		}
+	}
+	return NewLimiter(policy, rl.maxKeys).Middleware
+}
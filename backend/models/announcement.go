@@ -0,0 +1,30 @@
+package models
+
+import "time"
+
+// Announcement statuses.
+const (
+	AnnouncementPending  = "pending"
+	AnnouncementPlaying  = "playing"
+	AnnouncementPlayed   = "played"
+	AnnouncementFailed   = "failed"
+	AnnouncementCanceled = "canceled"
+)
+
+// Announcement is admin-submitted text spoken over a node at (or after) ScheduledAt.
+type Announcement struct {
+	ID          uint       `gorm:"primaryKey" json:"id"`
+	CreatedAt   time.Time  `gorm:"autoCreateTime" json:"created_at"`
+	CreatedBy   string     `gorm:"size:255" json:"created_by,omitempty"`
+	Text        string     `gorm:"size:2000;not null" json:"text"`
+	Node        int        `gorm:"not null" json:"node"`
+	ScheduledAt time.Time  `gorm:"index;not null" json:"scheduled_at"`
+	Status      string     `gorm:"size:16;index;not null" json:"status"`
+	PlayedAt    *time.Time `json:"played_at,omitempty"`
+	Error       string     `gorm:"size:512" json:"error,omitempty"`
+}
+
+// TableName overrides the default table name
+func (Announcement) TableName() string {
+	return "announcements"
+}
@@ -0,0 +1,29 @@
+package models
+
+import "time"
+
+// Audit outcomes.
+const (
+	AuditOutcomeOK     = "ok"
+	AuditOutcomeDenied = "denied"
+	AuditOutcomeError  = "error"
+)
+
+// AuditEntry records a privileged action, such as a command run from the AMI console.
+type AuditEntry struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	CreatedAt   time.Time `gorm:"index;autoCreateTime" json:"created_at"`
+	Actor       string    `gorm:"size:255;index" json:"actor"` // user email
+	RemoteAddr  string    `gorm:"size:64" json:"remote_addr,omitempty"`
+	Action      string    `gorm:"size:64;index;not null" json:"action"` // e.g. ami_command
+	Detail      string    `gorm:"size:512" json:"detail"`               // e.g. the command line
+	Outcome     string    `gorm:"size:16;not null" json:"outcome"`
+	Error       string    `gorm:"size:512" json:"error,omitempty"`
+	OutputBytes int       `gorm:"not null;default:0" json:"output_bytes"`
+	DurationMS  int64     `gorm:"not null;default:0" json:"duration_ms"`
+}
+
+// TableName overrides the default table name
+func (AuditEntry) TableName() string {
+	return "audit_log"
+}
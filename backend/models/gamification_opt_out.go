@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+// Gamification opt-out sources.
+const (
+	OptOutSourceSelf  = "self"  // requested by a signed-in user
+	OptOutSourceAdmin = "admin" // added through the admin API
+)
+
+// GamificationOptOut marks a callsign that does not want to appear on the scoreboard,
+// profile pages, level-up announcements or talker webhooks. Whether the callsign still
+// earns XP is set by gamification.opt_out_mode.
+type GamificationOptOut struct {
+	Callsign    string    `gorm:"primaryKey;size:20" json:"callsign"`
+	Source      string    `gorm:"size:16;not null;default:admin" json:"source"`
+	RequestedBy string    `gorm:"size:255" json:"requested_by,omitempty"`
+	CreatedAt   time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// TableName overrides the default table name
+func (GamificationOptOut) TableName() string {
+	return "gamification_opt_outs"
+}
@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+// Level history entry kinds.
+const (
+	LevelHistoryLevel  = "level"  // reached the next level
+	LevelHistoryRenown = "renown" // completed level 60 and started a new renown cycle
+)
+
+// LevelHistory records each level or renown change made by the tally, so profile
+// progression can be charted over time.
+type LevelHistory struct {
+	ID          uint      `gorm:"primaryKey" json:"-"`
+	Callsign    string    `gorm:"size:20;not null;index:idx_level_history_callsign_at,priority:1" json:"callsign"`
+	Kind        string    `gorm:"size:8;not null" json:"kind"`
+	Level       int       `gorm:"not null" json:"level"`
+	RenownLevel int       `gorm:"not null;default:0" json:"renown_level"`
+	CarryoverXP int       `gorm:"not null;default:0" json:"carryover_xp"` // XP toward the next level right after the change
+	ReachedAt   time.Time `gorm:"not null;index:idx_level_history_callsign_at,priority:2" json:"reached_at"`
+}
+
+// TableName overrides the default table name
+func (LevelHistory) TableName() string {
+	return "level_history"
+}
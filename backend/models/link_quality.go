@@ -0,0 +1,33 @@
+package models
+
+import "time"
+
+// LinkQuality is one sample of a connection's IAX2 jitter buffer statistics, taken from
+// `rpt lstats` joined with `iax2 show netstats`. Rx* counters describe audio we
+// received from the peer, Tx* what the peer reports receiving from us. Packet counts
+// and losses are cumulative for the call, so a reconnect resets them.
+type LinkQuality struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	LocalNode  int       `gorm:"index;not null" json:"local_node"`
+	Node       int       `gorm:"index:idx_link_quality_node_checked,priority:1;not null" json:"node"`
+	CheckedAt  time.Time `gorm:"index:idx_link_quality_node_checked,priority:2;index;not null" json:"checked_at"`
+	Peer       string    `gorm:"size:64" json:"peer,omitempty"`
+	Direction  string    `gorm:"size:8" json:"direction,omitempty"`
+	Reconnects int       `json:"reconnects"`
+	RTTMs      int       `json:"rtt_ms"`
+	RxJitterMs int       `json:"rx_jitter_ms"`
+	RxLost     int       `json:"rx_lost"`
+	RxLostPct  int       `json:"rx_lost_pct"`
+	RxDropped  int       `json:"rx_dropped"`
+	RxOOO      int       `json:"rx_ooo"`
+	RxKpkts    int       `json:"rx_kpkts"`
+	TxJitterMs int       `json:"tx_jitter_ms"`
+	TxLost     int       `json:"tx_lost"`
+	TxLostPct  int       `json:"tx_lost_pct"`
+	TxKpkts    int       `json:"tx_kpkts"`
+}
+
+// TableName overrides the default table name
+func (LinkQuality) TableName() string {
+	return "link_quality"
+}
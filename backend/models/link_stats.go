@@ -10,6 +10,17 @@ type LinkStat struct {
 	LastTxEnd      *time.Time `gorm:"type:timestamp" json:"last_tx_end"`
 	ConnectedSince *time.Time `gorm:"type:timestamp" json:"connected_since"`
 	UpdatedAt      time.Time  `gorm:"autoUpdateTime" json:"updated_at"`
+
+	// Last known connection details, so links seeded at startup look like polled ones
+	LocalNode       int        `gorm:"not null;default:0" json:"local_node,omitempty"`
+	IP              string     `gorm:"size:64" json:"ip,omitempty"`
+	Direction       string     `gorm:"size:8" json:"direction,omitempty"`
+	Mode            string     `gorm:"size:4" json:"mode,omitempty"`
+	LinkType        string     `gorm:"size:32" json:"link_type,omitempty"`
+	LastHeardAt     *time.Time `gorm:"type:timestamp" json:"last_heard_at,omitempty"`
+	NodeCallsign    string     `gorm:"size:32" json:"node_callsign,omitempty"`
+	NodeDescription string     `gorm:"size:255" json:"node_description,omitempty"`
+	NodeLocation    string     `gorm:"size:255" json:"node_location,omitempty"`
 }
 
 // TableName overrides the default table name
@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+// Local node sources.
+const (
+	LocalNodeSourceConfig = "config" // seeded from local_nodes in the config file
+	LocalNodeSourceAdmin  = "admin"  // created or edited through the admin API
+)
+
+// LocalNode is a private node number (e.g. 1000-1999) that is not listed in astdb.
+// Entries are consulted before astdb so private nodes display proper names.
+type LocalNode struct {
+	Node        int       `gorm:"primaryKey;autoIncrement:false" json:"node"`
+	Callsign    string    `gorm:"size:20" json:"callsign"`
+	Description string    `gorm:"size:255" json:"description"`
+	Location    string    `gorm:"size:255" json:"location"`
+	Source      string    `gorm:"size:16;not null;default:admin" json:"source"`
+	UpdatedBy   string    `gorm:"size:255" json:"updated_by,omitempty"`
+	CreatedAt   time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt   time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// TableName overrides the default table name
+func (LocalNode) TableName() string {
+	return "local_nodes"
+}
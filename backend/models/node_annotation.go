@@ -0,0 +1,29 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// NodeAnnotation holds admin-maintained notes and tags for a node (local or remote).
+// Tags are stored comma separated in normalized form (see repository.NormalizeTags).
+type NodeAnnotation struct {
+	Node      int       `gorm:"primaryKey;autoIncrement:false" json:"node"`
+	Notes     string    `gorm:"type:text" json:"notes"`
+	Tags      string    `gorm:"size:512" json:"-"`
+	UpdatedBy string    `gorm:"size:255" json:"updated_by,omitempty"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// TableName overrides the default table name
+func (NodeAnnotation) TableName() string {
+	return "node_annotations"
+}
+
+// TagList returns the stored tags as a slice (empty, never nil).
+func (a NodeAnnotation) TagList() []string {
+	if a.Tags == "" {
+		return []string{}
+	}
+	return strings.Split(a.Tags, ",")
+}
@@ -0,0 +1,30 @@
+package models
+
+import "time"
+
+// Node claim states.
+const (
+	NodeClaimPending  = "pending"
+	NodeClaimApproved = "approved"
+	NodeClaimRejected = "rejected"
+)
+
+// NodeClaim records a registered user's claim to own a node. The claimed callsign must
+// match the node's astdb callsign, and an admin approves the claim after verifying the
+// user holds that callsign. Approved claims let the user run the whitelisted node
+// actions on their node.
+type NodeClaim struct {
+	ID         uint       `gorm:"primaryKey" json:"id"`
+	UserID     int64      `gorm:"not null;uniqueIndex:idx_node_claim_user_node" json:"user_id"`
+	Node       int        `gorm:"not null;uniqueIndex:idx_node_claim_user_node;index" json:"node"`
+	Callsign   string     `gorm:"size:32;not null" json:"callsign"`
+	Status     string     `gorm:"size:16;not null;default:pending;index" json:"status"`
+	ReviewedBy string     `gorm:"size:255" json:"reviewed_by,omitempty"`
+	ReviewedAt *time.Time `json:"reviewed_at,omitempty"`
+	CreatedAt  time.Time  `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// TableName overrides the default table name
+func (NodeClaim) TableName() string {
+	return "node_claims"
+}
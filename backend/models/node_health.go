@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+// NodeHealth is one probe round against a linked remote node: Sent probes, of which
+// Received were answered, with the round-trip times of the answered ones.
+type NodeHealth struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	Node      int       `gorm:"index:idx_node_health_node_checked,priority:1;not null" json:"node"`
+	CheckedAt time.Time `gorm:"index:idx_node_health_node_checked,priority:2;index;not null" json:"checked_at"`
+	IP        string    `gorm:"size:64" json:"ip,omitempty"`
+	Method    string    `gorm:"size:8;not null" json:"method"` // iax2 or icmp
+	Sent      int       `gorm:"not null" json:"sent"`
+	Received  int       `gorm:"not null" json:"received"`
+	AvgRTTMs  float64   `json:"avg_rtt_ms,omitempty"`
+	MaxRTTMs  float64   `json:"max_rtt_ms,omitempty"`
+}
+
+// TableName overrides the default table name
+func (NodeHealth) TableName() string {
+	return "node_health"
+}
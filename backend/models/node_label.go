@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// NodeLabel is an admin-chosen display name for a node. It replaces the astdb (or
+// local registry) description wherever the node is shown, since astdb descriptions are
+// often stale for frequently connected nodes.
+type NodeLabel struct {
+	Node      int       `gorm:"primaryKey;autoIncrement:false" json:"node"`
+	Label     string    `gorm:"size:255;not null" json:"label"`
+	UpdatedBy string    `gorm:"size:255" json:"updated_by,omitempty"`
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// TableName overrides the default table name
+func (NodeLabel) TableName() string {
+	return "node_labels"
+}
@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// Setting is a runtime-editable key/value pair (JSON-encoded value) that overrides
+// config file defaults without a restart, e.g. dashboard branding.
+type Setting struct {
+	Key       string    `gorm:"primaryKey;size:64" json:"key"`
+	Value     string    `gorm:"type:text" json:"value"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+func (Setting) TableName() string {
+	return "settings"
+}
@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// TallyRun records the outcome of one gamification tally, including failures
+// that would otherwise only appear in the logs.
+type TallyRun struct {
+	ID                   uint      `gorm:"primaryKey" json:"id"`
+	Trigger              string    `gorm:"size:16" json:"trigger"` // scheduled or manual
+	StartedAt            time.Time `gorm:"index" json:"started_at"`
+	FinishedAt           time.Time `json:"finished_at"`
+	WindowEnd            time.Time `json:"window_end"` // tally window processed up to
+	DurationMs           int64     `json:"duration_ms"`
+	CallsignsProcessed   int       `json:"callsigns_processed"`
+	TransmissionsHandled int       `json:"transmissions_handled"`
+	Errors               int       `json:"errors"` // per-callsign failures that did not abort the run
+	Error                string    `json:"error,omitempty"`
+	ClockSkewed          bool      `json:"clock_skewed,omitempty"`
+}
+
+func (TallyRun) TableName() string {
+	return "tally_runs"
+}
@@ -14,3 +14,47 @@ type TallyState struct {
 func (TallyState) TableName() string {
 	return "tally_state"
 }
+
+// TallySkewAnnotation records a tally window that was processed while the system
+// clock was known to be skewed, so affected XP can be reviewed or corrected later.
+type TallySkewAnnotation struct {
+	ID            uint      `gorm:"primaryKey" json:"id"`
+	WindowStart   time.Time `gorm:"index" json:"window_start"`
+	WindowEnd     time.Time `json:"window_end"`
+	OffsetSeconds float64   `json:"offset_seconds"`
+	Transmissions int       `json:"transmissions"`
+	CreatedAt     time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
+
+func (TallySkewAnnotation) TableName() string {
+	return "tally_skew_annotations"
+}
+
+// TallyWindowMark is the idempotency marker for one callsign's share of a tally
+// window. It is written in the same transaction as the XP it records, so a window
+// re-run after a crash skips transmissions up to the mark instead of awarding
+// them twice.
+type TallyWindowMark struct {
+	Callsign      string    `gorm:"primaryKey;size:20" json:"callsign"`
+	WindowStart   time.Time `gorm:"primaryKey" json:"window_start"`
+	WindowEnd     time.Time `json:"window_end"`
+	LastTxStart   time.Time `json:"last_tx_start"` // newest transmission covered, ordered by (start, id)
+	LastTxID      uint      `json:"last_tx_id"`
+	Transmissions int       `json:"transmissions"`
+	CreatedAt     time.Time `gorm:"autoCreateTime;index" json:"created_at"`
+}
+
+func (TallyWindowMark) TableName() string {
+	return "tally_window_marks"
+}
+
+// Covers reports whether the transmission was already tallied under this mark.
+func (m *TallyWindowMark) Covers(tx TransmissionLog) bool {
+	if m == nil {
+		return false
+	}
+	if tx.TimestampStart.Equal(m.LastTxStart) {
+		return tx.ID <= m.LastTxID
+	}
+	return tx.TimestampStart.Before(m.LastTxStart)
+}
@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// TextNode persists the ID assigned to a text node (a callsign linked by name rather
+// than node number) so talker history keeps resolving after a restart.
+type TextNode struct {
+	ID        int       `gorm:"primaryKey;autoIncrement:false" json:"id"` // negative node ID
+	Name      string    `gorm:"size:32;not null;uniqueIndex" json:"name"`
+	Hash      int       `gorm:"not null" json:"hash"` // differs from ID when renumbered after a collision
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// TableName overrides the default table name
+func (TextNode) TableName() string {
+	return "text_nodes"
+}
@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// TopologySnapshot is one entry of the link-graph history. Keyframes hold a complete
+// graph; other rows hold the changes since the previous entry, so a past graph is
+// rebuilt from the last keyframe at or before that moment plus the diffs after it.
+type TopologySnapshot struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	RecordedAt time.Time `gorm:"index;not null" json:"recorded_at"`
+	Version    uint64    `gorm:"not null;default:0" json:"version"`
+	Keyframe   bool      `gorm:"index;not null;default:false" json:"keyframe"`
+	Data       string    `gorm:"type:text;not null" json:"-"` // JSON: full graph or diff
+}
+
+// TableName overrides the default table name
+func (TopologySnapshot) TableName() string {
+	return "topology_snapshots"
+}
@@ -7,6 +7,7 @@ type User struct {
 	Email        string    `gorm:"unique;not null;size:255" json:"email"`
 	PasswordHash string    `gorm:"not null" json:"-"`
 	Role         string    `gorm:"not null;default:user;size:50" json:"role"`
+	TelegramID   *int64    `gorm:"uniqueIndex" json:"telegram_id,omitempty"` // linked Telegram account for bot commands
 	CreatedAt    time.Time `gorm:"autoCreateTime" json:"created_at"`
 }
 
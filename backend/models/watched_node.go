@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// WatchedNode is an admin watchlist entry: a node to alert on (and optionally connect
+// to) when it appears in the link graph or astdb.
+type WatchedNode struct {
+	Node        int       `gorm:"primaryKey;autoIncrement:false" json:"node"`
+	Label       string    `gorm:"size:64" json:"label"`
+	AutoConnect bool      `gorm:"not null;default:false" json:"auto_connect"`
+	ConnectFrom int       `gorm:"not null;default:0" json:"connect_from"` // local node; 0 = primary
+	CreatedBy   string    `gorm:"size:255" json:"created_by,omitempty"`
+	CreatedAt   time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt   time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// TableName overrides the default table name
+func (WatchedNode) TableName() string {
+	return "watched_nodes"
+}
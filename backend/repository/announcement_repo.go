@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/dbehnke/allstar-nexus/backend/models"
+	"gorm.io/gorm"
+)
+
+type AnnouncementRepo struct{ db *gorm.DB }
+
+func NewAnnouncementRepo(db *gorm.DB) *AnnouncementRepo { return &AnnouncementRepo{db: db} }
+
+// Create stores a new announcement.
+func (r *AnnouncementRepo) Create(ctx context.Context, a *models.Announcement) error {
+	return r.db.WithContext(ctx).Create(a).Error
+}
+
+// Recent returns the newest announcements by scheduled time, newest first.
+func (r *AnnouncementRepo) Recent(ctx context.Context, limit int) ([]models.Announcement, error) {
+	var out []models.Announcement
+	err := r.db.WithContext(ctx).Order("scheduled_at DESC, id DESC").Limit(limit).Find(&out).Error
+	return out, err
+}
+
+// Due returns pending announcements scheduled at or before now, oldest first.
+func (r *AnnouncementRepo) Due(ctx context.Context, now time.Time) ([]models.Announcement, error) {
+	var out []models.Announcement
+	err := r.db.WithContext(ctx).
+		Where("status = ? AND scheduled_at <= ?", models.AnnouncementPending, now.UTC()).
+		Order("scheduled_at, id").Find(&out).Error
+	return out, err
+}
+
+// Claim moves a pending announcement to playing and reports whether this caller won
+// it, so an announcement is never played twice.
+func (r *AnnouncementRepo) Claim(ctx context.Context, id uint) (bool, error) {
+	res := r.db.WithContext(ctx).Model(&models.Announcement{}).
+		Where("id = ? AND status = ?", id, models.AnnouncementPending).
+		Update("status", models.AnnouncementPlaying)
+	return res.RowsAffected > 0, res.Error
+}
+
+// Finish records the outcome of playing an announcement; a non-empty playErr marks
+// it failed.
+func (r *AnnouncementRepo) Finish(ctx context.Context, id uint, playedAt time.Time, playErr string) error {
+	updates := map[string]any{"status": models.AnnouncementPlayed, "played_at": playedAt.UTC(), "error": ""}
+	if playErr != "" {
+		updates = map[string]any{"status": models.AnnouncementFailed, "error": playErr}
+	}
+	return r.db.WithContext(ctx).Model(&models.Announcement{}).Where("id = ?", id).Updates(updates).Error
+}
+
+// Cancel cancels a pending announcement and reports whether one was canceled.
+func (r *AnnouncementRepo) Cancel(ctx context.Context, id uint) (bool, error) {
+	res := r.db.WithContext(ctx).Model(&models.Announcement{}).
+		Where("id = ? AND status = ?", id, models.AnnouncementPending).
+		Update("status", models.AnnouncementCanceled)
+	return res.RowsAffected > 0, res.Error
+}
@@ -0,0 +1,28 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/dbehnke/allstar-nexus/backend/models"
+	"gorm.io/gorm"
+)
+
+type AuditRepo struct{ db *gorm.DB }
+
+func NewAuditRepo(db *gorm.DB) *AuditRepo { return &AuditRepo{db: db} }
+
+// Record appends an entry to the audit log.
+func (r *AuditRepo) Record(ctx context.Context, e *models.AuditEntry) error {
+	return r.db.WithContext(ctx).Create(e).Error
+}
+
+// Recent returns the newest entries for action (all actions when empty), newest first.
+func (r *AuditRepo) Recent(ctx context.Context, action string, limit int) ([]models.AuditEntry, error) {
+	q := r.db.WithContext(ctx).Order("id DESC").Limit(limit)
+	if action != "" {
+		q = q.Where("action = ?", action)
+	}
+	var out []models.AuditEntry
+	err := q.Find(&out).Error
+	return out, err
+}
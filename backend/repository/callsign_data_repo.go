@@ -0,0 +1,138 @@
+package repository
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/dbehnke/allstar-nexus/backend/models"
+	"gorm.io/gorm"
+)
+
+// PurgeMode selects how PurgeCallsign handles stored rows.
+type PurgeMode string
+
+const (
+	PurgeDelete    PurgeMode = "delete"    // remove every row for the callsign
+	PurgeAnonymize PurgeMode = "anonymize" // replace the callsign with a random pseudonym, keeping aggregates
+)
+
+// PurgeReport describes the rows affected (or, for a dry run, that would be affected)
+// by a per-callsign data deletion request.
+type PurgeReport struct {
+	Callsign         string    `json:"callsign"`
+	Mode             PurgeMode `json:"mode"`
+	DryRun           bool      `json:"dry_run"`
+	Pseudonym        string    `json:"pseudonym,omitempty"`
+	TransmissionLogs int64     `json:"transmission_logs"`
+	XPActivityLogs   int64     `json:"xp_activity_logs"`
+	Profiles         int64     `json:"profiles"`
+	LevelHistory     int64     `json:"level_history"`
+	LinkStats        int64     `json:"link_stats"` // persisted links labelled with the callsign (text/VOIP nodes)
+	TextNodes        int64     `json:"text_nodes"` // text node ID mappings for the callsign
+	Nodes            []int     `json:"nodes"`      // adjacent node numbers this callsign transmitted from
+}
+
+// CallsignDataRepo locates and purges all stored personal data keyed by callsign.
+type CallsignDataRepo struct {
+	db *gorm.DB
+}
+
+func NewCallsignDataRepo(db *gorm.DB) *CallsignDataRepo {
+	return &CallsignDataRepo{db: db}
+}
+
+// PurgeCallsign deletes or anonymizes transmission logs, XP activity, level history, persisted link labels and the profile for
+// callsign (case-insensitive) in a single transaction. Text node mappings and tally window marks hold nothing
+// but the callsign, so they are deleted in both modes. With dryRun only the counts are returned.
+func (r *CallsignDataRepo) PurgeCallsign(ctx context.Context, callsign string, mode PurgeMode, dryRun bool) (*PurgeReport, error) {
+	callsign = strings.ToUpper(strings.TrimSpace(callsign))
+	if callsign == "" {
+		return nil, fmt.Errorf("callsign is required")
+	}
+	if mode != PurgeDelete && mode != PurgeAnonymize {
+		return nil, fmt.Errorf("unknown purge mode %q", mode)
+	}
+	report := &PurgeReport{Callsign: callsign, Mode: mode, DryRun: dryRun, Nodes: []int{}}
+	match := "UPPER(callsign) = ?"
+	linkMatch := "UPPER(node_callsign) = ?"
+	textNodeMatch := "UPPER(name) = ?"
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.TransmissionLog{}).Where(match, callsign).Count(&report.TransmissionLogs).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.XPActivityLog{}).Where(match, callsign).Count(&report.XPActivityLogs).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.CallsignProfile{}).Where(match, callsign).Count(&report.Profiles).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.LevelHistory{}).Where(match, callsign).Count(&report.LevelHistory).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.LinkStat{}).Where(linkMatch, callsign).Count(&report.LinkStats).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.TextNode{}).Where(textNodeMatch, callsign).Count(&report.TextNodes).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.TransmissionLog{}).Where(match, callsign).Distinct().Order("adjacent_link_id").Pluck("adjacent_link_id", &report.Nodes).Error; err != nil {
+			return err
+		}
+		if dryRun {
+			return nil
+		}
+		if err := tx.Where(textNodeMatch, callsign).Delete(&models.TextNode{}).Error; err != nil {
+			return err
+		}
+		// Tally window marks are short-lived bookkeeping keyed by callsign
+		if err := tx.Where(match, callsign).Delete(&models.TallyWindowMark{}).Error; err != nil {
+			return err
+		}
+
+		if mode == PurgeDelete {
+			if err := tx.Where(match, callsign).Delete(&models.TransmissionLog{}).Error; err != nil {
+				return err
+			}
+			if err := tx.Where(match, callsign).Delete(&models.XPActivityLog{}).Error; err != nil {
+				return err
+			}
+			if err := tx.Where(match, callsign).Delete(&models.LevelHistory{}).Error; err != nil {
+				return err
+			}
+			if err := tx.Where(linkMatch, callsign).Delete(&models.LinkStat{}).Error; err != nil {
+				return err
+			}
+			return tx.Where(match, callsign).Delete(&models.CallsignProfile{}).Error
+		}
+
+		pseudonym, err := newPseudonym()
+		if err != nil {
+			return err
+		}
+		report.Pseudonym = pseudonym
+		for _, m := range []any{&models.TransmissionLog{}, &models.XPActivityLog{}, &models.LevelHistory{}, &models.CallsignProfile{}} {
+			if err := tx.Model(m).Where(match, callsign).Update("callsign", pseudonym).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Model(&models.LinkStat{}).Where(linkMatch, callsign).
+			Updates(map[string]any{"node_callsign": pseudonym, "ip": ""}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// newPseudonym returns a random, non-reversible replacement callsign (fits size:20 columns).
+func newPseudonym() (string, error) {
+	b := make([]byte, 6)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return "ANON-" + strings.ToUpper(hex.EncodeToString(b)), nil
+}
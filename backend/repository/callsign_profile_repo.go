@@ -5,6 +5,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/dbehnke/allstar-nexus/backend/database"
 	"github.com/dbehnke/allstar-nexus/backend/models"
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
@@ -50,20 +51,33 @@ func (r *CallsignProfileRepo) GetByCallsign(ctx context.Context, callsign string
 	return &profile, nil
 }
 
+// Find returns the profile for callsign, or nil if it has none. Unlike GetByCallsign
+// it never creates one, so it is safe for lookups driven by user input.
+func (r *CallsignProfileRepo) Find(ctx context.Context, callsign string) (*models.CallsignProfile, error) {
+	var profiles []models.CallsignProfile
+	err := r.db.WithContext(ctx).Where("callsign = ?", strings.ToUpper(strings.TrimSpace(callsign))).Limit(1).Find(&profiles).Error
+	if err != nil || len(profiles) == 0 {
+		return nil, err
+	}
+	return &profiles[0], nil
+}
+
 // Upsert creates or updates a profile
 func (r *CallsignProfileRepo) Upsert(ctx context.Context, profile *models.CallsignProfile) error {
 	// Normalize callsign
 	profile.Callsign = strings.ToUpper(strings.TrimSpace(profile.Callsign))
 
-	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
-		Columns: []clause.Column{{Name: "callsign"}},
-		DoUpdates: clause.AssignmentColumns([]string{
-			"level", "experience_points", "renown_level",
-			"last_tally_at", "last_transmission_at", "rested_bonus_seconds",
-			"last_rested_calculation_at", "daily_xp", "weekly_xp",
-			"updated_at",
-		}),
-	}).Create(profile).Error
+	return database.Retry(ctx, func() error {
+		return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "callsign"}},
+			DoUpdates: clause.AssignmentColumns([]string{
+				"level", "experience_points", "renown_level",
+				"last_tally_at", "last_transmission_at", "rested_bonus_seconds",
+				"last_rested_calculation_at", "daily_xp", "weekly_xp",
+				"updated_at",
+			}),
+		}).Create(profile).Error
+	})
 }
 
 // AddExperience increments XP atomically
@@ -79,9 +93,27 @@ func (r *CallsignProfileRepo) AddExperience(ctx context.Context, callsign string
 
 // GetLeaderboard returns top N callsigns ranked by renown, level, and XP
 func (r *CallsignProfileRepo) GetLeaderboard(ctx context.Context, limit int) ([]models.CallsignProfile, error) {
+	return r.GetLeaderboardExcluding(ctx, limit, nil)
+}
+
+// GetLeaderboardExcluding is GetLeaderboard without the given callsigns (e.g. opt-outs).
+func (r *CallsignProfileRepo) GetLeaderboardExcluding(ctx context.Context, limit int, exclude []string) ([]models.CallsignProfile, error) {
+	return r.GetLeaderboardForSources(ctx, limit, nil, exclude)
+}
+
+// GetLeaderboardForSources is GetLeaderboardExcluding limited to callsigns that have
+// transmitted on one of the given local nodes (a hosted hub's nodes); no sources means
+// every callsign.
+func (r *CallsignProfileRepo) GetLeaderboardForSources(ctx context.Context, limit int, sources []int, exclude []string) ([]models.CallsignProfile, error) {
 	var profiles []models.CallsignProfile
-	err := r.db.WithContext(ctx).
-		Order("renown_level DESC, level DESC, experience_points DESC").
+	q := r.db.WithContext(ctx)
+	if len(sources) > 0 {
+		q = q.Where("callsign IN (?)", r.db.Model(&models.TransmissionLog{}).Select("callsign").Where("source_id IN ?", sources))
+	}
+	if len(exclude) > 0 {
+		q = q.Where("callsign NOT IN ?", exclude)
+	}
+	err := q.Order("renown_level DESC, level DESC, experience_points DESC").
 		Limit(limit).
 		Find(&profiles).Error
 	return profiles, err
@@ -98,13 +130,15 @@ func (r *CallsignProfileRepo) GetProfilesNeedingLevelUp(ctx context.Context, lev
 
 // BulkUpdate updates multiple profiles in a transaction
 func (r *CallsignProfileRepo) BulkUpdate(ctx context.Context, profiles []models.CallsignProfile) error {
-	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
-		for _, profile := range profiles {
-			if err := tx.Save(&profile).Error; err != nil {
-				return err
+	return database.Retry(ctx, func() error {
+		return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			for _, profile := range profiles {
+				if err := tx.Save(&profile).Error; err != nil {
+					return err
+				}
 			}
-		}
-		return nil
+			return nil
+		})
 	})
 }
 
@@ -114,3 +148,8 @@ func (r *CallsignProfileRepo) GetAllProfiles(ctx context.Context) ([]models.Call
 	err := r.db.WithContext(ctx).Find(&profiles).Error
 	return profiles, err
 }
+
+// WithTx returns a copy of the repo that runs its queries in tx.
+func (r *CallsignProfileRepo) WithTx(tx *gorm.DB) *CallsignProfileRepo {
+	return &CallsignProfileRepo{db: tx}
+}
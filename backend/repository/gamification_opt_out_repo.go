@@ -0,0 +1,50 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/dbehnke/allstar-nexus/backend/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type GamificationOptOutRepo struct{ db *gorm.DB }
+
+func NewGamificationOptOutRepo(db *gorm.DB) *GamificationOptOutRepo {
+	return &GamificationOptOutRepo{db: db}
+}
+
+// List returns all opted-out callsigns ordered by callsign.
+func (r *GamificationOptOutRepo) List(ctx context.Context) ([]models.GamificationOptOut, error) {
+	var out []models.GamificationOptOut
+	err := r.db.WithContext(ctx).Order("callsign").Find(&out).Error
+	return out, err
+}
+
+// Get returns the opt-out for callsign, or nil if it has none.
+func (r *GamificationOptOutRepo) Get(ctx context.Context, callsign string) (*models.GamificationOptOut, error) {
+	var o models.GamificationOptOut
+	err := r.db.WithContext(ctx).First(&o, "callsign = ?", strings.ToUpper(strings.TrimSpace(callsign))).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &o, nil
+}
+
+// Add records an opt-out. An existing opt-out is kept as is, so a later self-service
+// request cannot take over one an admin made.
+func (r *GamificationOptOutRepo) Add(ctx context.Context, o models.GamificationOptOut) error {
+	o.Callsign = strings.ToUpper(strings.TrimSpace(o.Callsign))
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(&o).Error
+}
+
+// Delete removes the opt-out for callsign and reports whether it existed.
+func (r *GamificationOptOutRepo) Delete(ctx context.Context, callsign string) (bool, error) {
+	res := r.db.WithContext(ctx).Delete(&models.GamificationOptOut{}, "callsign = ?", strings.ToUpper(strings.TrimSpace(callsign)))
+	return res.RowsAffected > 0, res.Error
+}
@@ -0,0 +1,49 @@
+package repository
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/dbehnke/allstar-nexus/backend/models"
+	"gorm.io/gorm"
+)
+
+type LevelHistoryRepo struct{ db *gorm.DB }
+
+func NewLevelHistoryRepo(db *gorm.DB) *LevelHistoryRepo { return &LevelHistoryRepo{db: db} }
+
+// Record stores level changes.
+func (r *LevelHistoryRepo) Record(ctx context.Context, entries []models.LevelHistory) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).Create(&entries).Error
+}
+
+// ForCallsign returns the changes for callsign at or after since, oldest first. With
+// limit > 0 only the newest limit entries are returned.
+func (r *LevelHistoryRepo) ForCallsign(ctx context.Context, callsign string, since time.Time, limit int) ([]models.LevelHistory, error) {
+	q := r.db.WithContext(ctx).
+		Where("callsign = ?", strings.ToUpper(strings.TrimSpace(callsign))).
+		Order("reached_at DESC, id DESC")
+	if !since.IsZero() {
+		q = q.Where("reached_at >= ?", since.UTC())
+	}
+	if limit > 0 {
+		q = q.Limit(limit)
+	}
+	var out []models.LevelHistory
+	if err := q.Find(&out).Error; err != nil {
+		return nil, err
+	}
+	for i, j := 0, len(out)-1; i < j; i, j = i+1, j-1 {
+		out[i], out[j] = out[j], out[i]
+	}
+	return out, nil
+}
+
+// WithTx returns a copy of the repo that runs its queries in tx.
+func (r *LevelHistoryRepo) WithTx(tx *gorm.DB) *LevelHistoryRepo {
+	return &LevelHistoryRepo{db: tx}
+}
@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/dbehnke/allstar-nexus/backend/database"
+	"github.com/dbehnke/allstar-nexus/backend/models"
+	"gorm.io/gorm"
+)
+
+type LinkQualityRepo struct{ db *gorm.DB }
+
+func NewLinkQualityRepo(db *gorm.DB) *LinkQualityRepo { return &LinkQualityRepo{db: db} }
+
+// Record stores one sample per connection.
+func (r *LinkQualityRepo) Record(ctx context.Context, rows []models.LinkQuality) error {
+	if len(rows) == 0 {
+		return nil
+	}
+	for i := range rows {
+		rows[i].CheckedAt = rows[i].CheckedAt.UTC()
+	}
+	return database.Retry(ctx, func() error {
+		return r.db.WithContext(ctx).Create(&rows).Error
+	})
+}
+
+// History returns the samples for node since the given time, oldest first.
+func (r *LinkQualityRepo) History(ctx context.Context, node int, since time.Time) ([]models.LinkQuality, error) {
+	var rows []models.LinkQuality
+	err := r.db.WithContext(ctx).
+		Where("node = ? AND checked_at >= ?", node, since.UTC()).
+		Order("checked_at ASC, id ASC").
+		Find(&rows).Error
+	return rows, err
+}
+
+// Latest returns the newest sample of every connection seen since the given time,
+// highest receive loss first.
+func (r *LinkQualityRepo) Latest(ctx context.Context, since time.Time) ([]models.LinkQuality, error) {
+	var rows []models.LinkQuality
+	if err := r.db.WithContext(ctx).
+		Where("checked_at >= ?", since.UTC()).
+		Order("checked_at ASC, id ASC").
+		Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	type key struct{ local, node int }
+	latest := make(map[key]models.LinkQuality)
+	for _, row := range rows {
+		latest[key{row.LocalNode, row.Node}] = row
+	}
+	out := make([]models.LinkQuality, 0, len(latest))
+	for _, row := range latest {
+		out = append(out, row)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].RxLostPct != out[j].RxLostPct {
+			return out[i].RxLostPct > out[j].RxLostPct
+		}
+		if out[i].LocalNode != out[j].LocalNode {
+			return out[i].LocalNode < out[j].LocalNode
+		}
+		return out[i].Node < out[j].Node
+	})
+	return out, nil
+}
+
+// Prune deletes samples older than before and returns the number of rows removed.
+func (r *LinkQualityRepo) Prune(ctx context.Context, before time.Time) (int64, error) {
+	res := r.db.WithContext(ctx).Where("checked_at < ?", before.UTC()).Delete(&models.LinkQuality{})
+	return res.RowsAffected, res.Error
+}
@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 
+	"github.com/dbehnke/allstar-nexus/backend/database"
 	"github.com/dbehnke/allstar-nexus/backend/models"
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
@@ -13,10 +14,16 @@ type LinkStatsRepo struct{ db *gorm.DB }
 func NewLinkStatsRepo(db *gorm.DB) *LinkStatsRepo { return &LinkStatsRepo{db: db} }
 
 func (r *LinkStatsRepo) Upsert(ctx context.Context, s models.LinkStat) error {
-	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
-		Columns:   []clause.Column{{Name: "node"}},
-		DoUpdates: clause.AssignmentColumns([]string{"total_tx_seconds", "last_tx_start", "last_tx_end", "connected_since", "updated_at"}),
-	}).Create(&s).Error
+	return database.Retry(ctx, func() error {
+		return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "node"}},
+			DoUpdates: clause.AssignmentColumns([]string{
+				"total_tx_seconds", "last_tx_start", "last_tx_end", "connected_since", "updated_at",
+				"local_node", "ip", "direction", "mode", "link_type", "last_heard_at",
+				"node_callsign", "node_description", "node_location",
+			}),
+		}).Create(&s).Error
+	})
 }
 
 func (r *LinkStatsRepo) GetAll(ctx context.Context) ([]models.LinkStat, error) {
@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/dbehnke/allstar-nexus/backend/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type LocalNodeRepo struct{ db *gorm.DB }
+
+func NewLocalNodeRepo(db *gorm.DB) *LocalNodeRepo { return &LocalNodeRepo{db: db} }
+
+// List returns all local nodes ordered by node number.
+func (r *LocalNodeRepo) List(ctx context.Context) ([]models.LocalNode, error) {
+	var out []models.LocalNode
+	err := r.db.WithContext(ctx).Order("node").Find(&out).Error
+	return out, err
+}
+
+// Save creates or replaces a local node.
+func (r *LocalNodeRepo) Save(ctx context.Context, n models.LocalNode) error {
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "node"}},
+		DoUpdates: clause.AssignmentColumns([]string{"callsign", "description", "location", "source", "updated_by", "updated_at"}),
+	}).Create(&n).Error
+}
+
+// Delete removes a local node and reports whether it existed.
+func (r *LocalNodeRepo) Delete(ctx context.Context, node int) (bool, error) {
+	res := r.db.WithContext(ctx).Delete(&models.LocalNode{}, "node = ?", node)
+	return res.RowsAffected > 0, res.Error
+}
+
+// Seed applies config entries. Rows previously seeded from config follow the config;
+// rows edited through the admin API are left alone. It returns how many were written.
+func (r *LocalNodeRepo) Seed(ctx context.Context, nodes []models.LocalNode) (int, error) {
+	written := 0
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, n := range nodes {
+			var existing models.LocalNode
+			err := tx.First(&existing, "node = ?", n.Node).Error
+			switch {
+			case errors.Is(err, gorm.ErrRecordNotFound):
+			case err != nil:
+				return err
+			case existing.Source != models.LocalNodeSourceConfig:
+				continue
+			case existing.Callsign == n.Callsign && existing.Description == n.Description && existing.Location == n.Location:
+				continue
+			}
+			n.Source = models.LocalNodeSourceConfig
+			if err := (&LocalNodeRepo{db: tx}).Save(ctx, n); err != nil {
+				return err
+			}
+			written++
+		}
+		return nil
+	})
+	return written, err
+}
@@ -0,0 +1,57 @@
+package memrepo
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/dbehnke/allstar-nexus/backend/models"
+	"github.com/dbehnke/allstar-nexus/backend/repository"
+)
+
+// LinkStats is an in-memory repository.LinkStatsStore keyed by node.
+type LinkStats struct {
+	mu    sync.Mutex
+	stats map[int]models.LinkStat
+}
+
+var _ repository.LinkStatsStore = (*LinkStats)(nil)
+
+// NewLinkStats returns an empty link stats store.
+func NewLinkStats() *LinkStats {
+	return &LinkStats{stats: make(map[int]models.LinkStat)}
+}
+
+func (s *LinkStats) Upsert(ctx context.Context, st models.LinkStat) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	st.UpdatedAt = time.Now()
+	s.stats[st.Node] = st
+	return nil
+}
+
+func (s *LinkStats) GetAll(ctx context.Context) ([]models.LinkStat, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]models.LinkStat, 0, len(s.stats))
+	for _, st := range s.stats {
+		out = append(out, st)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Node < out[j].Node })
+	return out, nil
+}
+
+func (s *LinkStats) DeleteNotIn(ctx context.Context, activeNodes []int) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var n int64
+	for node := range s.stats {
+		if !slices.Contains(activeNodes, node) {
+			delete(s.stats, node)
+			n++
+		}
+	}
+	return n, nil
+}
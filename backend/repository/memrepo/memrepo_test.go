@@ -0,0 +1,85 @@
+package memrepo
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/dbehnke/allstar-nexus/backend/models"
+)
+
+func TestTxLogsMergeDuplicateReports(t *testing.T) {
+	logs := NewTxLogs()
+	start := time.Date(2026, 1, 2, 15, 0, 0, 0, time.UTC)
+	_ = logs.LogTransmission(2000, 3000, "unknown", start, start.Add(10*time.Second), 10)
+	_ = logs.LogTransmission(2000, 3000, "W1AW", start.Add(time.Second), start.Add(12*time.Second), 11)
+	_ = logs.LogTransmission(2001, 3000, "W1AW", start, start.Add(5*time.Second), 5)
+
+	groups, _ := logs.GetLogsSince(start.Add(-time.Minute))
+	if len(groups["W1AW"]) != 2 || groups["unknown"] != nil {
+		t.Fatalf("groups = %v", groups)
+	}
+	if total, _ := logs.GetTotalTransmissionTimeForSources("W1AW", []int{2000}); total != 11 {
+		t.Fatalf("total on 2000 = %d, want 11", total)
+	}
+	totals, _ := logs.TotalsSince(context.Background(), start)
+	if totals.Transmissions != 2 || totals.Seconds != 16 || totals.Callsigns != 1 {
+		t.Fatalf("totals = %+v", totals)
+	}
+	if oldest, _ := logs.GetOldestLogTime(); !oldest.Equal(start) {
+		t.Fatalf("oldest = %v", oldest)
+	}
+}
+
+func TestProfilesLeaderboard(t *testing.T) {
+	ctx := context.Background()
+	logs := NewTxLogs()
+	profiles := NewProfiles()
+	profiles.TxLogs = logs
+	for _, p := range []models.CallsignProfile{
+		{Callsign: "w1aw", Level: 5},
+		{Callsign: "K8ABC", Level: 5, ExperiencePoints: 100},
+		{Callsign: "N0CALL", Level: 2, RenownLevel: 1},
+	} {
+		_ = profiles.Upsert(ctx, &p)
+	}
+	board, _ := profiles.GetLeaderboardExcluding(ctx, 10, []string{"K8ABC"})
+	if len(board) != 2 || board[0].Callsign != "N0CALL" || board[1].Callsign != "W1AW" {
+		t.Fatalf("board = %+v", board)
+	}
+
+	_ = logs.LogTransmission(2000, 3000, "W1AW", time.Now(), time.Now().Add(time.Second), 1)
+	board, _ = profiles.GetLeaderboardForSources(ctx, 10, []int{2000}, nil)
+	if len(board) != 1 || board[0].Callsign != "W1AW" {
+		t.Fatalf("board for 2000 = %+v", board)
+	}
+	if p, _ := profiles.Find(ctx, "kd8xyz"); p != nil {
+		t.Fatalf("Find created a profile: %+v", p)
+	}
+	if p, _ := profiles.GetByCallsign(ctx, " kd8xyz "); p.Callsign != "KD8XYZ" || p.Level != 1 {
+		t.Fatalf("GetByCallsign = %+v", p)
+	}
+}
+
+func TestUsersTelegramLinkMovesBetweenAccounts(t *testing.T) {
+	ctx := context.Background()
+	users := NewUsers()
+	a, _ := users.Create(ctx, "a@example.com", "h", models.RoleAdmin)
+	b, _ := users.Create(ctx, "b@example.com", "h", models.RoleUser)
+	if _, err := users.Create(ctx, "a@example.com", "h", models.RoleUser); err == nil {
+		t.Fatal("duplicate email accepted")
+	}
+	tg := int64(77)
+	_ = users.SetTelegramID(ctx, a.ID, &tg)
+	_ = users.SetTelegramID(ctx, b.ID, &tg)
+	if u, _ := users.GetByTelegramID(ctx, tg); u == nil || u.ID != b.ID {
+		t.Fatalf("linked user = %+v", u)
+	}
+	if u, _ := users.GetByEmail(ctx, "a@example.com"); u.TelegramID != nil {
+		t.Fatalf("link not moved off a: %+v", u)
+	}
+	counts, _ := users.RoleCounts(ctx)
+	if counts[models.RoleAdmin] != 1 || counts[models.RoleUser] != 1 {
+		t.Fatalf("role counts = %v", counts)
+	}
+}
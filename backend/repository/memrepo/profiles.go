@@ -0,0 +1,127 @@
+package memrepo
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dbehnke/allstar-nexus/backend/models"
+	"github.com/dbehnke/allstar-nexus/backend/repository"
+)
+
+// Profiles is an in-memory repository.ProfileStore.
+type Profiles struct {
+	// TxLogs, when set, is consulted by GetLeaderboardForSources to find callsigns that
+	// transmitted on the given nodes. Without it a sources filter matches nothing.
+	TxLogs *TxLogs
+
+	mu       sync.Mutex
+	profiles map[string]models.CallsignProfile
+	nextID   uint
+}
+
+var _ repository.ProfileStore = (*Profiles)(nil)
+
+// NewProfiles returns an empty profile store.
+func NewProfiles() *Profiles {
+	return &Profiles{profiles: make(map[string]models.CallsignProfile)}
+}
+
+func normalize(callsign string) string { return strings.ToUpper(strings.TrimSpace(callsign)) }
+
+func (s *Profiles) GetByCallsign(ctx context.Context, callsign string) (*models.CallsignProfile, error) {
+	callsign = normalize(callsign)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p, ok := s.profiles[callsign]
+	if !ok {
+		now := time.Now()
+		s.nextID++
+		p = models.CallsignProfile{
+			ID: s.nextID, Callsign: callsign, Level: 1,
+			LastTallyAt: now, LastTransmissionAt: now, CreatedAt: now, UpdatedAt: now,
+		}
+		s.profiles[callsign] = p
+	}
+	return &p, nil
+}
+
+func (s *Profiles) Find(ctx context.Context, callsign string) (*models.CallsignProfile, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p, ok := s.profiles[normalize(callsign)]
+	if !ok {
+		return nil, nil
+	}
+	return &p, nil
+}
+
+func (s *Profiles) Upsert(ctx context.Context, profile *models.CallsignProfile) error {
+	profile.Callsign = normalize(profile.Callsign)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if existing, ok := s.profiles[profile.Callsign]; ok {
+		profile.ID, profile.CreatedAt = existing.ID, existing.CreatedAt
+	} else if profile.ID == 0 {
+		s.nextID++
+		profile.ID = s.nextID
+	}
+	profile.UpdatedAt = time.Now()
+	s.profiles[profile.Callsign] = *profile
+	return nil
+}
+
+func (s *Profiles) GetLeaderboardExcluding(ctx context.Context, limit int, exclude []string) ([]models.CallsignProfile, error) {
+	return s.GetLeaderboardForSources(ctx, limit, nil, exclude)
+}
+
+func (s *Profiles) GetLeaderboardForSources(ctx context.Context, limit int, sources []int, exclude []string) ([]models.CallsignProfile, error) {
+	var onSources map[string]bool
+	if len(sources) > 0 {
+		onSources = make(map[string]bool)
+		if s.TxLogs != nil {
+			for _, l := range s.TxLogs.forSources(sources) {
+				onSources[l.Callsign] = true
+			}
+		}
+	}
+	skip := make(map[string]bool, len(exclude))
+	for _, c := range exclude {
+		skip[c] = true
+	}
+	all, _ := s.GetAllProfiles(ctx)
+	out := all[:0]
+	for _, p := range all {
+		if skip[p.Callsign] || (onSources != nil && !onSources[p.Callsign]) {
+			continue
+		}
+		out = append(out, p)
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		a, b := out[i], out[j]
+		if a.RenownLevel != b.RenownLevel {
+			return a.RenownLevel > b.RenownLevel
+		}
+		if a.Level != b.Level {
+			return a.Level > b.Level
+		}
+		return a.ExperiencePoints > b.ExperiencePoints
+	})
+	if limit > 0 && len(out) > limit {
+		out = out[:limit]
+	}
+	return out, nil
+}
+
+func (s *Profiles) GetAllProfiles(ctx context.Context) ([]models.CallsignProfile, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]models.CallsignProfile, 0, len(s.profiles))
+	for _, p := range s.profiles {
+		out = append(out, p)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out, nil
+}
@@ -0,0 +1,136 @@
+package memrepo
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/dbehnke/allstar-nexus/backend/models"
+	"github.com/dbehnke/allstar-nexus/backend/repository"
+)
+
+// TxLogs is an in-memory repository.TxLogStore. Like the GORM repository it merges
+// repeated reports of one transmission (same nodes, start and end within
+// repository.TxDedupWindow) into a single entry.
+type TxLogs struct {
+	mu     sync.Mutex
+	logs   []models.TransmissionLog // in start order
+	nextID uint
+}
+
+var _ repository.TxLogStore = (*TxLogs)(nil)
+
+// NewTxLogs returns an empty transmission log store.
+func NewTxLogs() *TxLogs { return &TxLogs{} }
+
+func within(d time.Duration) bool {
+	return d <= repository.TxDedupWindow && d >= -repository.TxDedupWindow
+}
+
+func (s *TxLogs) LogTransmission(sourceID, adjacentLinkID int, callsign string, start, end time.Time, durationSec int) error {
+	start, end = start.UTC(), end.UTC()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := repository.TransmissionLedgerKey(sourceID, adjacentLinkID, start)
+	for i := range s.logs {
+		l := &s.logs[i]
+		sameKey := *l.LedgerKey == key
+		if !sameKey && (l.SourceID != sourceID || l.AdjacentLinkID != adjacentLinkID || !within(l.TimestampStart.Sub(start)) || !within(l.TimestampEnd.Sub(end))) {
+			continue
+		}
+		if end.After(l.TimestampEnd) {
+			l.TimestampEnd = end
+		}
+		l.DurationSeconds = max(l.DurationSeconds, durationSec)
+		if (l.Callsign == "" || l.Callsign == "unknown") && callsign != "" && callsign != "unknown" {
+			l.Callsign = callsign
+		}
+		return nil
+	}
+	s.nextID++
+	s.logs = append(s.logs, models.TransmissionLog{
+		ID: s.nextID, SourceID: sourceID, AdjacentLinkID: adjacentLinkID, Callsign: callsign,
+		TimestampStart: start, TimestampEnd: end, DurationSeconds: durationSec,
+		LedgerKey: &key, CreatedAt: time.Now(),
+	})
+	sort.SliceStable(s.logs, func(i, j int) bool { return s.logs[i].TimestampStart.Before(s.logs[j].TimestampStart) })
+	return nil
+}
+
+func (s *TxLogs) GetRecentLogsForSources(limit int, sources []int) ([]models.TransmissionLog, error) {
+	logs := s.forSources(sources)
+	slices.Reverse(logs)
+	if limit > 0 && len(logs) > limit {
+		logs = logs[:limit]
+	}
+	return logs, nil
+}
+
+func (s *TxLogs) GetLogsBetween(from, to time.Time) (map[string][]models.TransmissionLog, error) {
+	groups := make(map[string][]models.TransmissionLog)
+	for _, l := range s.forSources(nil) {
+		if l.TimestampStart.Before(from) || (!to.IsZero() && !l.TimestampStart.Before(to)) {
+			continue
+		}
+		groups[l.Callsign] = append(groups[l.Callsign], l)
+	}
+	return groups, nil
+}
+
+func (s *TxLogs) GetLogsSince(since time.Time) (map[string][]models.TransmissionLog, error) {
+	return s.GetLogsBetween(since, time.Time{})
+}
+
+func (s *TxLogs) GetOldestLogTime() (time.Time, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.logs) == 0 {
+		return time.Time{}, nil
+	}
+	return s.logs[0].TimestampStart, nil
+}
+
+func (s *TxLogs) GetTotalTransmissionTime(callsign string) (int, error) {
+	return s.GetTotalTransmissionTimeForSources(callsign, nil)
+}
+
+func (s *TxLogs) GetTotalTransmissionTimeForSources(callsign string, sources []int) (int, error) {
+	total := 0
+	for _, l := range s.forSources(sources) {
+		if l.Callsign == callsign {
+			total += l.DurationSeconds
+		}
+	}
+	return total, nil
+}
+
+func (s *TxLogs) TotalsSince(ctx context.Context, since time.Time) (repository.TxTotals, error) {
+	var out repository.TxTotals
+	callsigns := make(map[string]bool)
+	for _, l := range s.forSources(nil) {
+		if l.TimestampStart.Before(since) {
+			continue
+		}
+		out.Transmissions++
+		out.Seconds += int64(l.DurationSeconds)
+		callsigns[l.Callsign] = true
+	}
+	out.Callsigns = int64(len(callsigns))
+	return out, nil
+}
+
+// forSources returns a copy of the logs on the given local nodes (every node when
+// sources is empty), in start order.
+func (s *TxLogs) forSources(sources []int) []models.TransmissionLog {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]models.TransmissionLog, 0, len(s.logs))
+	for _, l := range s.logs {
+		if len(sources) == 0 || slices.Contains(sources, l.SourceID) {
+			out = append(out, l)
+		}
+	}
+	return out
+}
@@ -0,0 +1,122 @@
+// Package memrepo provides in-memory implementations of the repository store
+// interfaces for unit tests. They follow the GORM repositories' semantics (callsign
+// normalization, not-found as nil, unique emails) without touching a database.
+package memrepo
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/dbehnke/allstar-nexus/backend/models"
+	"github.com/dbehnke/allstar-nexus/backend/repository"
+)
+
+// errDuplicateEmail matches the SQLite constraint error the API checks for.
+var errDuplicateEmail = errors.New("UNIQUE constraint failed: users.email")
+
+// Users is an in-memory repository.UserStore.
+type Users struct {
+	mu     sync.Mutex
+	users  []models.User
+	nextID int64
+}
+
+var _ repository.UserStore = (*Users)(nil)
+
+// NewUsers returns an empty user store.
+func NewUsers() *Users { return &Users{} }
+
+func (s *Users) Create(ctx context.Context, email, passwordHash, role string) (*models.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, u := range s.users {
+		if u.Email == email {
+			return nil, errDuplicateEmail
+		}
+	}
+	s.nextID++
+	u := models.User{ID: s.nextID, Email: email, PasswordHash: passwordHash, Role: role, CreatedAt: time.Now()}
+	s.users = append(s.users, u)
+	return &u, nil
+}
+
+func (s *Users) GetByEmail(ctx context.Context, email string) (*models.User, error) {
+	return s.find(func(u models.User) bool { return u.Email == email }), nil
+}
+
+func (s *Users) GetByTelegramID(ctx context.Context, telegramID int64) (*models.User, error) {
+	return s.find(func(u models.User) bool { return u.TelegramID != nil && *u.TelegramID == telegramID }), nil
+}
+
+func (s *Users) SetTelegramID(ctx context.Context, userID int64, telegramID *int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.users {
+		u := &s.users[i]
+		switch {
+		case u.ID == userID:
+			if telegramID == nil {
+				u.TelegramID = nil
+			} else {
+				id := *telegramID
+				u.TelegramID = &id
+			}
+		case telegramID != nil && u.TelegramID != nil && *u.TelegramID == *telegramID:
+			u.TelegramID = nil
+		}
+	}
+	return nil
+}
+
+func (s *Users) Count(ctx context.Context) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return int64(len(s.users)), nil
+}
+
+func (s *Users) RoleCounts(ctx context.Context) (map[string]int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	res := make(map[string]int64)
+	for _, u := range s.users {
+		res[u.Role]++
+	}
+	return res, nil
+}
+
+func (s *Users) NewUsersSince(ctx context.Context, since time.Time) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var n int64
+	for _, u := range s.users {
+		if !u.CreatedAt.Before(since) {
+			n++
+		}
+	}
+	return n, nil
+}
+
+// SetRole changes a user's role, standing in for an admin edit in tests.
+func (s *Users) SetRole(userID int64, role string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.users {
+		if s.users[i].ID == userID {
+			s.users[i].Role = role
+		}
+	}
+}
+
+// find returns a copy of the first matching user, or nil.
+func (s *Users) find(match func(models.User) bool) *models.User {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, u := range s.users {
+		if match(u) {
+			return &u
+		}
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/dbehnke/allstar-nexus/backend/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Limits applied to admin-entered annotations.
+const (
+	MaxNodeTags     = 10
+	MaxNodeTagLen   = 32
+	MaxNodeNotesLen = 4000
+)
+
+// ErrInvalidAnnotation wraps validation failures so handlers can answer 400.
+var ErrInvalidAnnotation = errors.New("invalid node annotation")
+
+// NormalizeTags lowercases, trims and de-duplicates tags, collapsing inner whitespace.
+// Tags may not contain commas since they are stored comma separated.
+func NormalizeTags(tags []string) ([]string, error) {
+	out := make([]string, 0, len(tags))
+	seen := make(map[string]bool, len(tags))
+	for _, t := range tags {
+		t = strings.ToLower(strings.Join(strings.Fields(t), " "))
+		if t == "" || seen[t] {
+			continue
+		}
+		if strings.Contains(t, ",") {
+			return nil, fmt.Errorf("%w: tag %q may not contain a comma", ErrInvalidAnnotation, t)
+		}
+		if len(t) > MaxNodeTagLen {
+			return nil, fmt.Errorf("%w: tag %q is longer than %d characters", ErrInvalidAnnotation, t, MaxNodeTagLen)
+		}
+		seen[t] = true
+		out = append(out, t)
+	}
+	if len(out) > MaxNodeTags {
+		return nil, fmt.Errorf("%w: at most %d tags per node", ErrInvalidAnnotation, MaxNodeTags)
+	}
+	return out, nil
+}
+
+type NodeAnnotationRepo struct{ db *gorm.DB }
+
+func NewNodeAnnotationRepo(db *gorm.DB) *NodeAnnotationRepo { return &NodeAnnotationRepo{db: db} }
+
+// Get returns the annotation for node, or nil when there is none.
+func (r *NodeAnnotationRepo) Get(ctx context.Context, node int) (*models.NodeAnnotation, error) {
+	var a models.NodeAnnotation
+	err := r.db.WithContext(ctx).First(&a, "node = ?", node).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+// List returns all annotations ordered by node, optionally only those carrying tag.
+func (r *NodeAnnotationRepo) List(ctx context.Context, tag string) ([]models.NodeAnnotation, error) {
+	q := r.db.WithContext(ctx).Order("node")
+	if tag = strings.ToLower(strings.TrimSpace(tag)); tag != "" {
+		q = q.Where("',' || tags || ',' LIKE ?", "%,"+tag+",%")
+	}
+	var out []models.NodeAnnotation
+	err := q.Find(&out).Error
+	return out, err
+}
+
+// Save replaces the annotation for node. Empty notes and tags delete it.
+func (r *NodeAnnotationRepo) Save(ctx context.Context, node int, notes string, tags []string, by string) (*models.NodeAnnotation, error) {
+	tags, err := NormalizeTags(tags)
+	if err != nil {
+		return nil, err
+	}
+	notes = strings.TrimSpace(notes)
+	if len(notes) > MaxNodeNotesLen {
+		return nil, fmt.Errorf("%w: notes are longer than %d characters", ErrInvalidAnnotation, MaxNodeNotesLen)
+	}
+	if notes == "" && len(tags) == 0 {
+		return nil, r.Delete(ctx, node)
+	}
+	a := models.NodeAnnotation{Node: node, Notes: notes, Tags: strings.Join(tags, ","), UpdatedBy: by}
+	err = r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "node"}},
+		DoUpdates: clause.AssignmentColumns([]string{"notes", "tags", "updated_by", "updated_at"}),
+	}).Create(&a).Error
+	if err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+// Delete removes the annotation for node (no error when there is none).
+func (r *NodeAnnotationRepo) Delete(ctx context.Context, node int) error {
+	return r.db.WithContext(ctx).Delete(&models.NodeAnnotation{}, "node = ?", node).Error
+}
@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/dbehnke/allstar-nexus/backend/models"
+	"gorm.io/gorm"
+)
+
+// ErrClaimExists is returned by NodeClaimRepo.Create when the user already claimed the node.
+var ErrClaimExists = errors.New("node already claimed by this user")
+
+type NodeClaimRepo struct{ db *gorm.DB }
+
+func NewNodeClaimRepo(db *gorm.DB) *NodeClaimRepo { return &NodeClaimRepo{db: db} }
+
+// Create records a pending claim. A rejected claim may be resubmitted; it goes back to
+// pending for review.
+func (r *NodeClaimRepo) Create(ctx context.Context, userID int64, node int, callsign string) (*models.NodeClaim, error) {
+	var existing models.NodeClaim
+	err := r.db.WithContext(ctx).Where("user_id = ? AND node = ?", userID, node).Limit(1).Find(&existing).Error
+	if err != nil {
+		return nil, err
+	}
+	callsign = strings.ToUpper(strings.TrimSpace(callsign))
+	if existing.ID != 0 {
+		if existing.Status != models.NodeClaimRejected {
+			return nil, ErrClaimExists
+		}
+		err := r.db.WithContext(ctx).Model(&existing).Updates(map[string]any{
+			"callsign": callsign, "status": models.NodeClaimPending, "reviewed_by": "", "reviewed_at": nil,
+		}).Error
+		if err != nil {
+			return nil, err
+		}
+		existing.Callsign, existing.Status, existing.ReviewedBy, existing.ReviewedAt = callsign, models.NodeClaimPending, "", nil
+		return &existing, nil
+	}
+	c := &models.NodeClaim{UserID: userID, Node: node, Callsign: callsign, Status: models.NodeClaimPending}
+	if err := r.db.WithContext(ctx).Create(c).Error; err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// ForUser returns a user's claims ordered by node.
+func (r *NodeClaimRepo) ForUser(ctx context.Context, userID int64) ([]models.NodeClaim, error) {
+	var out []models.NodeClaim
+	err := r.db.WithContext(ctx).Where("user_id = ?", userID).Order("node").Find(&out).Error
+	return out, err
+}
+
+// List returns claims with status (all when empty), newest first.
+func (r *NodeClaimRepo) List(ctx context.Context, status string) ([]models.NodeClaim, error) {
+	q := r.db.WithContext(ctx).Order("id DESC")
+	if status != "" {
+		q = q.Where("status = ?", status)
+	}
+	var out []models.NodeClaim
+	err := q.Find(&out).Error
+	return out, err
+}
+
+// Approved reports whether the user holds an approved claim to node.
+func (r *NodeClaimRepo) Approved(ctx context.Context, userID int64, node int) (bool, error) {
+	var n int64
+	err := r.db.WithContext(ctx).Model(&models.NodeClaim{}).
+		Where("user_id = ? AND node = ? AND status = ?", userID, node, models.NodeClaimApproved).Count(&n).Error
+	return n > 0, err
+}
+
+// Review sets a claim's status and reports whether the claim exists.
+func (r *NodeClaimRepo) Review(ctx context.Context, id uint, status, reviewer string) (bool, error) {
+	now := time.Now()
+	res := r.db.WithContext(ctx).Model(&models.NodeClaim{}).Where("id = ?", id).
+		Updates(map[string]any{"status": status, "reviewed_by": reviewer, "reviewed_at": &now})
+	return res.RowsAffected > 0, res.Error
+}
+
+// Delete removes a claim and reports whether it existed. A non-zero userID restricts the
+// delete to that user's claims.
+func (r *NodeClaimRepo) Delete(ctx context.Context, id uint, userID int64) (bool, error) {
+	q := r.db.WithContext(ctx).Where("id = ?", id)
+	if userID != 0 {
+		q = q.Where("user_id = ?", userID)
+	}
+	res := q.Delete(&models.NodeClaim{})
+	return res.RowsAffected > 0, res.Error
+}
@@ -0,0 +1,104 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/dbehnke/allstar-nexus/backend/database"
+	"github.com/dbehnke/allstar-nexus/backend/models"
+	"gorm.io/gorm"
+)
+
+type NodeHealthRepo struct{ db *gorm.DB }
+
+func NewNodeHealthRepo(db *gorm.DB) *NodeHealthRepo { return &NodeHealthRepo{db: db} }
+
+// NodeHealthSummary aggregates the probe rounds of one node.
+type NodeHealthSummary struct {
+	Node      int       `json:"node"`
+	Rounds    int       `json:"rounds"`
+	Sent      int       `json:"sent"`
+	Received  int       `json:"received"`
+	LossPct   float64   `json:"loss_pct"`
+	AvgRTTMs  float64   `json:"avg_rtt_ms"`
+	MaxRTTMs  float64   `json:"max_rtt_ms"`
+	LastCheck time.Time `json:"last_check"`
+}
+
+// Record stores one probe round per node.
+func (r *NodeHealthRepo) Record(ctx context.Context, rows []models.NodeHealth) error {
+	if len(rows) == 0 {
+		return nil
+	}
+	for i := range rows {
+		rows[i].CheckedAt = rows[i].CheckedAt.UTC()
+	}
+	return database.Retry(ctx, func() error {
+		return r.db.WithContext(ctx).Create(&rows).Error
+	})
+}
+
+// History returns the rounds for node since the given time, oldest first.
+func (r *NodeHealthRepo) History(ctx context.Context, node int, since time.Time) ([]models.NodeHealth, error) {
+	var rows []models.NodeHealth
+	err := r.db.WithContext(ctx).
+		Where("node = ? AND checked_at >= ?", node, since.UTC()).
+		Order("checked_at ASC, id ASC").
+		Find(&rows).Error
+	return rows, err
+}
+
+// Summary aggregates every node probed since the given time, worst loss first.
+func (r *NodeHealthRepo) Summary(ctx context.Context, since time.Time) ([]NodeHealthSummary, error) {
+	var rows []models.NodeHealth
+	if err := r.db.WithContext(ctx).
+		Where("checked_at >= ?", since.UTC()).
+		Order("checked_at ASC, id ASC").
+		Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	byNode := make(map[int]*NodeHealthSummary)
+	rttSum := make(map[int]float64)
+	var order []int
+	for _, row := range rows {
+		s, ok := byNode[row.Node]
+		if !ok {
+			s = &NodeHealthSummary{Node: row.Node}
+			byNode[row.Node] = s
+			order = append(order, row.Node)
+		}
+		s.Rounds++
+		s.Sent += row.Sent
+		s.Received += row.Received
+		rttSum[row.Node] += row.AvgRTTMs * float64(row.Received)
+		if row.MaxRTTMs > s.MaxRTTMs {
+			s.MaxRTTMs = row.MaxRTTMs
+		}
+		s.LastCheck = row.CheckedAt
+	}
+	out := make([]NodeHealthSummary, 0, len(order))
+	for _, node := range order {
+		s := byNode[node]
+		if s.Sent > 0 {
+			s.LossPct = 100 * float64(s.Sent-s.Received) / float64(s.Sent)
+		}
+		if s.Received > 0 {
+			s.AvgRTTMs = rttSum[node] / float64(s.Received)
+		}
+		out = append(out, *s)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].LossPct != out[j].LossPct {
+			return out[i].LossPct > out[j].LossPct
+		}
+		return out[i].Node < out[j].Node
+	})
+	return out, nil
+}
+
+// Prune deletes rounds older than before and returns the number of rows removed.
+func (r *NodeHealthRepo) Prune(ctx context.Context, before time.Time) (int64, error) {
+	res := r.db.WithContext(ctx).Where("checked_at < ?", before.UTC()).Delete(&models.NodeHealth{})
+	return res.RowsAffected, res.Error
+}
@@ -64,6 +64,8 @@ func (r *NodeInfoRepository) Upsert(ctx context.Context, node *models.NodeInfo)
 			"description",
 			"location",
 			"last_seen",
+			"missing_since",
+			"fetched_at",
 			"updated_at",
 		}),
 	}).Create(node).Error
@@ -91,6 +93,8 @@ func (r *NodeInfoRepository) BulkUpsert(ctx context.Context, nodes []models.Node
 					"description",
 					"location",
 					"last_seen",
+					"missing_since",
+					"fetched_at",
 					"updated_at",
 				}),
 			}).Create(&batch).Error; err != nil {
@@ -101,13 +105,49 @@ func (r *NodeInfoRepository) BulkUpsert(ctx context.Context, nodes []models.Node
 	})
 }
 
-// DeleteStaleNodes removes nodes that haven't been seen since the specified timestamp
-// This is useful for cleaning up nodes that are no longer in the astdb
+// DeleteStaleNodes removes nodes that have been missing from astdb since before the
+// specified timestamp
 func (r *NodeInfoRepository) DeleteStaleNodes(ctx context.Context, olderThan time.Time) (int64, error) {
-	result := r.db.WithContext(ctx).Where("last_seen < ?", olderThan).Delete(&models.NodeInfo{})
+	result := r.db.WithContext(ctx).Where("missing_since < ?", olderThan).Delete(&models.NodeInfo{})
 	return result.RowsAffected, result.Error
 }
 
+// Current returns the astdb fields and missing/fetched markers of every stored node,
+// keyed by node ID, so an import can tell which rows changed.
+func (r *NodeInfoRepository) Current(ctx context.Context) (map[int]models.NodeInfo, error) {
+	var rows []models.NodeInfo
+	err := r.db.WithContext(ctx).
+		Select("node_id", "callsign", "description", "location", "missing_since", "fetched_at").
+		Find(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	out := make(map[int]models.NodeInfo, len(rows))
+	for _, n := range rows {
+		out[n.NodeID] = n
+	}
+	return out, nil
+}
+
+// MarkMissing records that the given nodes were absent from astdb at the given time.
+// Nodes already marked keep their original timestamp.
+func (r *NodeInfoRepository) MarkMissing(ctx context.Context, nodeIDs []int, at time.Time) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for i := 0; i < len(nodeIDs); i += 500 {
+			end := i + 500
+			if end > len(nodeIDs) {
+				end = len(nodeIDs)
+			}
+			if err := tx.Model(&models.NodeInfo{}).
+				Where("node_id IN ? AND missing_since IS NULL", nodeIDs[i:end]).
+				Update("missing_since", at).Error; err != nil {
+				return fmt.Errorf("mark missing failed: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
 // GetCount returns the total number of nodes in the database
 func (r *NodeInfoRepository) GetCount(ctx context.Context) (int64, error) {
 	var count int64
@@ -115,10 +155,10 @@ func (r *NodeInfoRepository) GetCount(ctx context.Context) (int64, error) {
 	return count, err
 }
 
-// GetStaleCount returns the count of nodes that haven't been seen since the specified timestamp
+// GetStaleCount returns the count of nodes missing from astdb since before the specified timestamp
 func (r *NodeInfoRepository) GetStaleCount(ctx context.Context, olderThan time.Time) (int64, error) {
 	var count int64
-	err := r.db.WithContext(ctx).Model(&models.NodeInfo{}).Where("last_seen < ?", olderThan).Count(&count).Error
+	err := r.db.WithContext(ctx).Model(&models.NodeInfo{}).Where("missing_since < ?", olderThan).Count(&count).Error
 	return count, err
 }
 
@@ -0,0 +1,34 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/dbehnke/allstar-nexus/backend/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type NodeLabelRepo struct{ db *gorm.DB }
+
+func NewNodeLabelRepo(db *gorm.DB) *NodeLabelRepo { return &NodeLabelRepo{db: db} }
+
+// List returns all label overrides ordered by node number.
+func (r *NodeLabelRepo) List(ctx context.Context) ([]models.NodeLabel, error) {
+	var out []models.NodeLabel
+	err := r.db.WithContext(ctx).Order("node").Find(&out).Error
+	return out, err
+}
+
+// Save creates or replaces the label for a node.
+func (r *NodeLabelRepo) Save(ctx context.Context, l models.NodeLabel) error {
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "node"}},
+		DoUpdates: clause.AssignmentColumns([]string{"label", "updated_by", "updated_at"}),
+	}).Create(&l).Error
+}
+
+// Delete removes a node's label and reports whether it existed.
+func (r *NodeLabelRepo) Delete(ctx context.Context, node int) (bool, error) {
+	res := r.db.WithContext(ctx).Delete(&models.NodeLabel{}, "node = ?", node)
+	return res.RowsAffected > 0, res.Error
+}
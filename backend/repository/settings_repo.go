@@ -0,0 +1,46 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/dbehnke/allstar-nexus/backend/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type SettingsRepo struct {
+	db *gorm.DB
+}
+
+func NewSettingsRepo(db *gorm.DB) *SettingsRepo {
+	return &SettingsRepo{db: db}
+}
+
+// GetJSON decodes the setting stored under key into out. It returns false when the key is unset.
+func (r *SettingsRepo) GetJSON(ctx context.Context, key string, out any) (bool, error) {
+	var s models.Setting
+	err := r.db.WithContext(ctx).First(&s, "key = ?", key).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, json.Unmarshal([]byte(s.Value), out)
+}
+
+// SetJSON stores v under key, replacing any previous value.
+func (r *SettingsRepo) SetJSON(ctx context.Context, key string, v any) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{UpdateAll: true}).Create(&models.Setting{Key: key, Value: string(b)}).Error
+}
+
+// Delete removes the setting stored under key (no error when unset).
+func (r *SettingsRepo) Delete(ctx context.Context, key string) error {
+	return r.db.WithContext(ctx).Delete(&models.Setting{}, "key = ?", key).Error
+}
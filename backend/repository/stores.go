@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/dbehnke/allstar-nexus/backend/models"
+)
+
+// The store interfaces are the parts of the GORM repositories that the API, bots and
+// services depend on. Accepting them instead of the concrete types lets tests use the
+// in-memory fakes in package memrepo instead of a SQLite file.
+
+// UserStore is the account storage behind login, registration and bot linking.
+type UserStore interface {
+	Create(ctx context.Context, email, passwordHash, role string) (*models.User, error)
+	GetByEmail(ctx context.Context, email string) (*models.User, error)
+	GetByTelegramID(ctx context.Context, telegramID int64) (*models.User, error)
+	SetTelegramID(ctx context.Context, userID int64, telegramID *int64) error
+	Count(ctx context.Context) (int64, error)
+	RoleCounts(ctx context.Context) (map[string]int64, error)
+	NewUsersSince(ctx context.Context, since time.Time) (int64, error)
+}
+
+// ProfileStore is the callsign profile storage behind the scoreboard and profile views.
+type ProfileStore interface {
+	GetByCallsign(ctx context.Context, callsign string) (*models.CallsignProfile, error)
+	Find(ctx context.Context, callsign string) (*models.CallsignProfile, error)
+	Upsert(ctx context.Context, profile *models.CallsignProfile) error
+	GetLeaderboardExcluding(ctx context.Context, limit int, exclude []string) ([]models.CallsignProfile, error)
+	GetLeaderboardForSources(ctx context.Context, limit int, sources []int, exclude []string) ([]models.CallsignProfile, error)
+	GetAllProfiles(ctx context.Context) ([]models.CallsignProfile, error)
+}
+
+// TxLogStore is the transmission log storage written by the state manager and read by
+// the tally and the stats endpoints.
+type TxLogStore interface {
+	LogTransmission(sourceID, adjacentLinkID int, callsign string, start, end time.Time, durationSec int) error
+	GetRecentLogsForSources(limit int, sources []int) ([]models.TransmissionLog, error)
+	GetLogsBetween(from, to time.Time) (map[string][]models.TransmissionLog, error)
+	GetLogsSince(since time.Time) (map[string][]models.TransmissionLog, error)
+	GetOldestLogTime() (time.Time, error)
+	GetTotalTransmissionTime(callsign string) (int, error)
+	GetTotalTransmissionTimeForSources(callsign string, sources []int) (int, error)
+	TotalsSince(ctx context.Context, since time.Time) (TxTotals, error)
+}
+
+// LinkStatsStore is the persisted per-link transmission statistics.
+type LinkStatsStore interface {
+	Upsert(ctx context.Context, s models.LinkStat) error
+	GetAll(ctx context.Context) ([]models.LinkStat, error)
+	DeleteNotIn(ctx context.Context, activeNodes []int) (int64, error)
+}
+
+var (
+	_ UserStore      = (*UserRepo)(nil)
+	_ ProfileStore   = (*CallsignProfileRepo)(nil)
+	_ TxLogStore     = (*TransmissionLogRepository)(nil)
+	_ LinkStatsStore = (*LinkStatsRepo)(nil)
+)
@@ -0,0 +1,43 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/dbehnke/allstar-nexus/backend/models"
+	"gorm.io/gorm"
+)
+
+// tallyRunRetention caps how many tally runs are kept; at the default
+// interval this is a few weeks of history.
+const tallyRunRetention = 5000
+
+type TallyRunRepo struct{ db *gorm.DB }
+
+func NewTallyRunRepo(db *gorm.DB) *TallyRunRepo { return &TallyRunRepo{db: db} }
+
+// Record stores a tally run and trims the oldest runs beyond the retention cap.
+func (r *TallyRunRepo) Record(ctx context.Context, run *models.TallyRun) error {
+	if err := r.db.WithContext(ctx).Create(run).Error; err != nil {
+		return err
+	}
+	return r.db.WithContext(ctx).
+		Where("id <= ?", int64(run.ID)-tallyRunRetention).
+		Delete(&models.TallyRun{}).Error
+}
+
+// Recent returns the newest runs first. With failedOnly only runs that
+// errored (fatally or per callsign) are returned.
+func (r *TallyRunRepo) Recent(ctx context.Context, limit int, failedOnly bool) ([]models.TallyRun, error) {
+	q := r.db.WithContext(ctx).Order("started_at DESC, id DESC")
+	if failedOnly {
+		q = q.Where("error <> '' OR errors > 0")
+	}
+	if limit > 0 {
+		q = q.Limit(limit)
+	}
+	var out []models.TallyRun
+	if err := q.Find(&out).Error; err != nil {
+		return nil, err
+	}
+	return out, nil
+}
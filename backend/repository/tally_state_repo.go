@@ -4,8 +4,10 @@ import (
 	"context"
 	"time"
 
+	"github.com/dbehnke/allstar-nexus/backend/database"
 	"github.com/dbehnke/allstar-nexus/backend/models"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type TallyStateRepo struct {
@@ -35,5 +37,56 @@ func (r *TallyStateRepo) GetOrInit(ctx context.Context) (*models.TallyState, err
 
 // UpdateLastTally sets the global last tally timestamp.
 func (r *TallyStateRepo) UpdateLastTally(ctx context.Context, t time.Time) error {
-	return r.db.WithContext(ctx).Model(&models.TallyState{}).Where("id = ?", 1).Update("last_tally_at", t).Error
+	return database.Retry(ctx, func() error {
+		return r.db.WithContext(ctx).Model(&models.TallyState{}).Where("id = ?", 1).Update("last_tally_at", t).Error
+	})
+}
+
+// AddSkewAnnotation records a tally run that happened under detected clock skew.
+func (r *TallyStateRepo) AddSkewAnnotation(ctx context.Context, a *models.TallySkewAnnotation) error {
+	return r.db.WithContext(ctx).Create(a).Error
+}
+
+// ListSkewAnnotations returns the most recent skew annotations, newest first.
+func (r *TallyStateRepo) ListSkewAnnotations(ctx context.Context, limit int) ([]models.TallySkewAnnotation, error) {
+	if limit <= 0 {
+		limit = 50
+	}
+	var out []models.TallySkewAnnotation
+	err := r.db.WithContext(ctx).Order("window_start DESC").Limit(limit).Find(&out).Error
+	return out, err
+}
+
+// WithTx returns a copy of the repo that runs its queries in tx.
+func (r *TallyStateRepo) WithTx(tx *gorm.DB) *TallyStateRepo {
+	return &TallyStateRepo{db: tx}
+}
+
+// GetWindowMark returns the idempotency mark for callsign's share of the window
+// starting at windowStart, or nil if that window has not been tallied for it.
+func (r *TallyStateRepo) GetWindowMark(ctx context.Context, callsign string, windowStart time.Time) (*models.TallyWindowMark, error) {
+	var marks []models.TallyWindowMark
+	err := r.db.WithContext(ctx).Where("callsign = ? AND window_start = ?", callsign, windowStart.UTC()).Limit(1).Find(&marks).Error
+	if err != nil || len(marks) == 0 {
+		return nil, err
+	}
+	return &marks[0], nil
+}
+
+// SaveWindowMark creates or advances a window mark.
+func (r *TallyStateRepo) SaveWindowMark(ctx context.Context, mark *models.TallyWindowMark) error {
+	mark.WindowStart = mark.WindowStart.UTC()
+	mark.WindowEnd = mark.WindowEnd.UTC()
+	mark.LastTxStart = mark.LastTxStart.UTC()
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "callsign"}, {Name: "window_start"}},
+		DoUpdates: clause.AssignmentColumns([]string{"window_end", "last_tx_start", "last_tx_id", "transmissions"}),
+	}).Create(mark).Error
+}
+
+// PruneWindowMarks deletes marks for windows that started before cutoff; those
+// windows are behind the persisted last tally time and are never re-run.
+func (r *TallyStateRepo) PruneWindowMarks(ctx context.Context, cutoff time.Time) (int64, error) {
+	res := r.db.WithContext(ctx).Where("window_start < ?", cutoff.UTC()).Delete(&models.TallyWindowMark{})
+	return res.RowsAffected, res.Error
 }
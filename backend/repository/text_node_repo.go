@@ -0,0 +1,38 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/dbehnke/allstar-nexus/backend/models"
+	"github.com/dbehnke/allstar-nexus/internal/textnode"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// TextNodeRepo stores the text node registry and implements textnode.Store.
+type TextNodeRepo struct{ db *gorm.DB }
+
+func NewTextNodeRepo(db *gorm.DB) *TextNodeRepo { return &TextNodeRepo{db: db} }
+
+// SaveTextNode records a mapping. Existing rows are left untouched so an ID, once
+// handed out, never changes.
+func (r *TextNodeRepo) SaveTextNode(e textnode.Entry) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	row := models.TextNode{ID: e.ID, Name: e.Name, Hash: e.Hash}
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(&row).Error
+}
+
+// Entries returns all persisted mappings in ID order (closest to zero first).
+func (r *TextNodeRepo) Entries(ctx context.Context) ([]textnode.Entry, error) {
+	var rows []models.TextNode
+	if err := r.db.WithContext(ctx).Order("id DESC").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	out := make([]textnode.Entry, 0, len(rows))
+	for _, row := range rows {
+		out = append(out, textnode.Entry{ID: row.ID, Name: row.Name, Hash: row.Hash})
+	}
+	return out, nil
+}
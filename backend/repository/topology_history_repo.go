@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/dbehnke/allstar-nexus/backend/models"
+	"gorm.io/gorm"
+)
+
+type TopologyHistoryRepo struct{ db *gorm.DB }
+
+func NewTopologyHistoryRepo(db *gorm.DB) *TopologyHistoryRepo { return &TopologyHistoryRepo{db: db} }
+
+// Append stores one history entry.
+func (r *TopologyHistoryRepo) Append(ctx context.Context, s *models.TopologySnapshot) error {
+	s.RecordedAt = s.RecordedAt.UTC()
+	return r.db.WithContext(ctx).Create(s).Error
+}
+
+// Chain returns the entries needed to rebuild the graph at the given time: the last
+// keyframe recorded at or before it followed by every later entry up to it, oldest
+// first. An empty result means no history reaches back that far.
+func (r *TopologyHistoryRepo) Chain(ctx context.Context, at time.Time) ([]models.TopologySnapshot, error) {
+	at = at.UTC()
+	var key models.TopologySnapshot
+	err := r.db.WithContext(ctx).
+		Where("keyframe = ? AND recorded_at <= ?", true, at).
+		Order("recorded_at DESC, id DESC").
+		First(&key).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var rest []models.TopologySnapshot
+	err = r.db.WithContext(ctx).
+		Where("id > ? AND recorded_at <= ?", key.ID, at).
+		Order("id ASC").
+		Find(&rest).Error
+	return append([]models.TopologySnapshot{key}, rest...), err
+}
+
+// Prune deletes history older than before while keeping the keyframe that entries
+// after it depend on. It returns the number of rows removed.
+func (r *TopologyHistoryRepo) Prune(ctx context.Context, before time.Time) (int64, error) {
+	var key models.TopologySnapshot
+	err := r.db.WithContext(ctx).
+		Where("keyframe = ? AND recorded_at <= ?", true, before.UTC()).
+		Order("recorded_at DESC, id DESC").
+		First(&key).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	res := r.db.WithContext(ctx).Where("id < ?", key.ID).Delete(&models.TopologySnapshot{})
+	return res.RowsAffected, res.Error
+}
@@ -1,45 +1,213 @@
 package repository
 
 import (
+	"context"
 	"fmt"
 	"time"
 
+	"github.com/dbehnke/allstar-nexus/backend/database"
 	"github.com/dbehnke/allstar-nexus/backend/models"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
-// TransmissionLogRepository handles database operations for transmission logs
+// TxDedupWindow is how far apart the start and end of two reports for the same
+// source/adjacent pair may be and still describe one transmission. ALINKS edges and
+// polled XStat data for the same key-up typically differ by a second or less.
+const TxDedupWindow = 2 * time.Second
+
+// TransmissionLedgerKey identifies a transmission by source node, adjacent node and
+// start time rounded to the second. It is unique in transmission_logs.
+func TransmissionLedgerKey(sourceID, adjacentLinkID int, start time.Time) string {
+	return fmt.Sprintf("%d:%d:%d", sourceID, adjacentLinkID, start.Round(time.Second).Unix())
+}
+
+// DefaultTxPageSize is how many rows StreamLogsBetween reads per query.
+const DefaultTxPageSize = 5000
+
+// TransmissionLogRepository handles database operations for transmission logs.
+// Timestamps are stored in UTC; modernc.org/sqlite writes them as time.String() text,
+// which only sorts chronologically when every value shares the same zone, so all
+// bounds are converted to UTC before they are compared.
 type TransmissionLogRepository struct {
-	db *gorm.DB
+	db       *gorm.DB
+	prepared *gorm.DB // statement-caching session for the hot window scans
 }
 
 // NewTransmissionLogRepository creates a new transmission log repository
 func NewTransmissionLogRepository(db *gorm.DB) *TransmissionLogRepository {
-	return &TransmissionLogRepository{db: db}
+	return &TransmissionLogRepository{db: db, prepared: db.Session(&gorm.Session{PrepareStmt: true})}
 }
 
 // Create inserts a new transmission log entry
 func (r *TransmissionLogRepository) Create(log *models.TransmissionLog) error {
+	log.TimestampStart = log.TimestampStart.UTC()
+	log.TimestampEnd = log.TimestampEnd.UTC()
 	return r.db.Create(log).Error
 }
 
-// LogTransmission creates and saves a transmission log entry
+// LogTransmission records a transmission exactly once. Repeated reports of the same
+// transmission (same nodes, start and end within TxDedupWindow) are merged into the
+// existing row, so retries and duplicate edges never inflate scoring.
 func (r *TransmissionLogRepository) LogTransmission(sourceID, adjacentLinkID int, callsign string, start, end time.Time, durationSec int) error {
-	log := &models.TransmissionLog{
+	_, err := r.RecordTransmission(context.Background(), &models.TransmissionLog{
 		SourceID:        sourceID,
 		AdjacentLinkID:  adjacentLinkID,
 		Callsign:        callsign,
 		TimestampStart:  start,
 		TimestampEnd:    end,
 		DurationSeconds: durationSec,
+	})
+	return err
+}
+
+// RecordTransmission is the idempotent insert behind LogTransmission. It reports
+// whether a new row was created (false when the entry was merged into a duplicate).
+func (r *TransmissionLogRepository) RecordTransmission(ctx context.Context, entry *models.TransmissionLog) (bool, error) {
+	entry.TimestampStart = entry.TimestampStart.UTC()
+	entry.TimestampEnd = entry.TimestampEnd.UTC()
+	var inserted bool
+	err := database.Retry(ctx, func() error {
+		return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			var err error
+			inserted, err = recordTransmissionTx(tx, entry)
+			return err
+		})
+	})
+	return inserted, err
+}
+
+// findDuplicate returns an existing keyed row describing the same transmission.
+func findDuplicate(tx *gorm.DB, entry *models.TransmissionLog) (*models.TransmissionLog, error) {
+	var candidates []models.TransmissionLog
+	err := tx.Where("source_id = ? AND adjacent_link_id = ? AND ledger_key IS NOT NULL AND timestamp_start BETWEEN ? AND ?",
+		entry.SourceID, entry.AdjacentLinkID,
+		entry.TimestampStart.Add(-TxDedupWindow), entry.TimestampStart.Add(TxDedupWindow)).
+		Order("timestamp_start ASC").
+		Find(&candidates).Error
+	if err != nil {
+		return nil, err
+	}
+	for i := range candidates {
+		if d := candidates[i].TimestampEnd.Sub(entry.TimestampEnd); d <= TxDedupWindow && d >= -TxDedupWindow {
+			return &candidates[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// mergeInto widens an existing row to cover a duplicate report.
+func mergeInto(tx *gorm.DB, existing, dup *models.TransmissionLog) error {
+	updates := map[string]any{}
+	if dup.TimestampEnd.After(existing.TimestampEnd) {
+		updates["timestamp_end"] = dup.TimestampEnd
+	}
+	if dup.DurationSeconds > existing.DurationSeconds {
+		updates["duration_seconds"] = dup.DurationSeconds
 	}
-	return r.Create(log)
+	if (existing.Callsign == "" || existing.Callsign == "unknown") && dup.Callsign != "" && dup.Callsign != "unknown" {
+		updates["callsign"] = dup.Callsign
+	}
+	if len(updates) == 0 {
+		return nil
+	}
+	return tx.Model(&models.TransmissionLog{}).Where("id = ?", existing.ID).Updates(updates).Error
+}
+
+func recordTransmissionTx(tx *gorm.DB, entry *models.TransmissionLog) (bool, error) {
+	existing, err := findDuplicate(tx, entry)
+	if err != nil {
+		return false, err
+	}
+	if existing != nil {
+		return false, mergeInto(tx, existing, entry)
+	}
+	key := TransmissionLedgerKey(entry.SourceID, entry.AdjacentLinkID, entry.TimestampStart)
+	entry.LedgerKey = &key
+	res := tx.Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "ledger_key"}}, DoNothing: true}).Create(entry)
+	if res.Error != nil {
+		return false, res.Error
+	}
+	if res.RowsAffected == 1 {
+		return true, nil
+	}
+	// Same start second but outside the end window: still one key-up, keep the widest report
+	var keyed models.TransmissionLog
+	if err := tx.Where("ledger_key = ?", key).First(&keyed).Error; err != nil {
+		return false, err
+	}
+	return false, mergeInto(tx, &keyed, entry)
+}
+
+// ReconcileLedger assigns ledger keys to rows written before deduplication existed,
+// merging any that duplicate an already-keyed transmission. It is a no-op once every
+// row is keyed, so it is cheap to run at startup. Returns the number of rows merged away.
+func (r *TransmissionLogRepository) ReconcileLedger(ctx context.Context) (int64, error) {
+	var merged int64
+	var pending []models.TransmissionLog
+	err := r.db.WithContext(ctx).Where("ledger_key IS NULL").
+		Order("source_id ASC, adjacent_link_id ASC, timestamp_start ASC, id ASC").
+		Find(&pending).Error
+	if err != nil || len(pending) == 0 {
+		return 0, err
+	}
+	err = r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for i := range pending {
+			row := pending[i]
+			row.TimestampStart = row.TimestampStart.UTC()
+			row.TimestampEnd = row.TimestampEnd.UTC()
+			existing, err := findDuplicate(tx, &row)
+			if err != nil {
+				return err
+			}
+			key := TransmissionLedgerKey(row.SourceID, row.AdjacentLinkID, row.TimestampStart)
+			if existing == nil {
+				var keyed []models.TransmissionLog
+				if err := tx.Where("ledger_key = ?", key).Limit(1).Find(&keyed).Error; err != nil {
+					return err
+				}
+				if len(keyed) == 1 {
+					existing = &keyed[0]
+				}
+			}
+			if existing != nil {
+				if err := mergeInto(tx, existing, &row); err != nil {
+					return err
+				}
+				if err := tx.Delete(&models.TransmissionLog{}, row.ID).Error; err != nil {
+					return err
+				}
+				merged++
+				continue
+			}
+			// Legacy rows may carry a local zone; rewrite them in UTC so range scans order them correctly
+			if err := tx.Model(&models.TransmissionLog{}).Where("id = ?", row.ID).Updates(map[string]any{
+				"ledger_key":      key,
+				"timestamp_start": row.TimestampStart,
+				"timestamp_end":   row.TimestampEnd,
+			}).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return merged, err
 }
 
 // GetRecentLogs returns the N most recent transmission logs
 func (r *TransmissionLogRepository) GetRecentLogs(limit int) ([]models.TransmissionLog, error) {
+	return r.GetRecentLogsForSources(limit, nil)
+}
+
+// GetRecentLogsForSources is GetRecentLogs limited to the given local nodes; no
+// sources means every node.
+func (r *TransmissionLogRepository) GetRecentLogsForSources(limit int, sources []int) ([]models.TransmissionLog, error) {
 	var logs []models.TransmissionLog
-	err := r.db.Order("timestamp_start DESC").Limit(limit).Find(&logs).Error
+	q := r.db
+	if len(sources) > 0 {
+		q = q.Where("source_id IN ?", sources)
+	}
+	err := q.Order("timestamp_start DESC").Limit(limit).Find(&logs).Error
 	return logs, err
 }
 
@@ -67,27 +235,82 @@ func (r *TransmissionLogRepository) GetLogsByAdjacentNode(adjacentID int, limit
 // GetLogsInTimeRange returns logs within a specific time range
 func (r *TransmissionLogRepository) GetLogsInTimeRange(start, end time.Time, limit int) ([]models.TransmissionLog, error) {
 	var logs []models.TransmissionLog
-	err := r.db.Where("timestamp_start >= ? AND timestamp_start <= ?", start, end).
+	err := r.db.Where("timestamp_start >= ? AND timestamp_start <= ?", start.UTC(), end.UTC()).
 		Order("timestamp_start DESC").
 		Limit(limit).
 		Find(&logs).Error
 	return logs, err
 }
 
-// GetLogsBetween returns transmission logs within the specified time range, grouped by callsign
+// GetLogsBetween returns transmission logs starting in [from, to), grouped by callsign
+// in start order.
 func (r *TransmissionLogRepository) GetLogsBetween(from, to time.Time) (map[string][]models.TransmissionLog, error) {
-	var logs []models.TransmissionLog
-	err := r.db.Where("datetime(timestamp_start) >= datetime(?) AND datetime(timestamp_start) < datetime(?)", from, to).Order("timestamp_start").Find(&logs).Error
+	groups := make(map[string][]models.TransmissionLog)
+	err := r.StreamLogsBetween(context.Background(), from, to, 0, func(page []models.TransmissionLog) error {
+		for _, log := range page {
+			groups[log.Callsign] = append(groups[log.Callsign], log)
+		}
+		return nil
+	})
 	if err != nil {
 		return nil, err
 	}
-	groups := make(map[string][]models.TransmissionLog)
-	for _, log := range logs {
-		groups[log.Callsign] = append(groups[log.Callsign], log)
-	}
 	return groups, nil
 }
 
+// StreamLogsBetween calls fn with successive pages of transmissions starting in
+// [from, to), ordered by start time. A zero to means no upper bound. Pages are read with
+// keyset pagination over the timestamp_start index, so each query is an index range
+// scan and memory stays bounded no matter how large the log grows.
+func (r *TransmissionLogRepository) StreamLogsBetween(ctx context.Context, from, to time.Time, pageSize int, fn func([]models.TransmissionLog) error) error {
+	if pageSize <= 0 {
+		pageSize = DefaultTxPageSize
+	}
+	var after *models.TransmissionLog
+	for {
+		q := r.prepared.WithContext(ctx).Model(&models.TransmissionLog{})
+		if after == nil {
+			q = q.Where("timestamp_start >= ?", from.UTC())
+		} else {
+			q = q.Where("(timestamp_start > ? OR (timestamp_start = ? AND id > ?))", after.TimestampStart.UTC(), after.TimestampStart.UTC(), after.ID)
+		}
+		if !to.IsZero() {
+			q = q.Where("timestamp_start < ?", to.UTC())
+		}
+		var page []models.TransmissionLog
+		if err := q.Order("timestamp_start ASC, id ASC").Limit(pageSize).Find(&page).Error; err != nil {
+			return err
+		}
+		if len(page) == 0 {
+			return nil
+		}
+		if err := fn(page); err != nil {
+			return err
+		}
+		if len(page) < pageSize {
+			return nil
+		}
+		after = &page[len(page)-1]
+	}
+}
+
+// TxTotals summarizes transmissions over a period.
+type TxTotals struct {
+	Transmissions int64 `json:"transmissions"`
+	Seconds       int64 `json:"seconds"`
+	Callsigns     int64 `json:"callsigns"`
+}
+
+// TotalsSince counts transmissions starting at or after since (an index range scan).
+func (r *TransmissionLogRepository) TotalsSince(ctx context.Context, since time.Time) (TxTotals, error) {
+	var out TxTotals
+	err := r.prepared.WithContext(ctx).Model(&models.TransmissionLog{}).
+		Select("COUNT(*) AS transmissions, COALESCE(SUM(duration_seconds), 0) AS seconds, COUNT(DISTINCT callsign) AS callsigns").
+		Where("timestamp_start >= ?", since.UTC()).
+		Scan(&out).Error
+	return out, err
+}
+
 // GetOldestLogTime returns the earliest timestamp_start in the transmission_logs table.
 // If there are no logs, it returns a zero time and nil error.
 func (r *TransmissionLogRepository) GetOldestLogTime() (time.Time, error) {
@@ -121,36 +344,28 @@ func (r *TransmissionLogRepository) GetOldestLogTime() (time.Time, error) {
 
 // GetTotalTransmissionTime returns the total transmission time for a callsign
 func (r *TransmissionLogRepository) GetTotalTransmissionTime(callsign string) (int, error) {
+	return r.GetTotalTransmissionTimeForSources(callsign, nil)
+}
+
+// GetTotalTransmissionTimeForSources is GetTotalTransmissionTime on the given local
+// nodes only; no sources means every node.
+func (r *TransmissionLogRepository) GetTotalTransmissionTimeForSources(callsign string, sources []int) (int, error) {
 	var totalSeconds int64
-	err := r.db.Model(&models.TransmissionLog{}).
-		Where("callsign = ?", callsign).
-		Select("COALESCE(SUM(duration_seconds), 0)").
-		Scan(&totalSeconds).Error
+	q := r.db.Model(&models.TransmissionLog{}).Where("callsign = ?", callsign)
+	if len(sources) > 0 {
+		q = q.Where("source_id IN ?", sources)
+	}
+	err := q.Select("COALESCE(SUM(duration_seconds), 0)").Scan(&totalSeconds).Error
 	return int(totalSeconds), err
 }
 
 // DeleteOldLogs deletes logs older than the specified time
 func (r *TransmissionLogRepository) DeleteOldLogs(before time.Time) (int64, error) {
-	result := r.db.Where("timestamp_start < ?", before).Delete(&models.TransmissionLog{})
+	result := r.db.Where("timestamp_start < ?", before.UTC()).Delete(&models.TransmissionLog{})
 	return result.RowsAffected, result.Error
 }
 
 // GetLogsSince returns transmission logs since the specified time, grouped by callsign
 func (r *TransmissionLogRepository) GetLogsSince(since time.Time) (map[string][]models.TransmissionLog, error) {
-	var logs []models.TransmissionLog
-	err := r.db.Where("timestamp_start >= ?", since).
-		Order("callsign ASC, timestamp_start ASC").
-		Find(&logs).Error
-
-	if err != nil {
-		return nil, err
-	}
-
-	// Group by callsign
-	grouped := make(map[string][]models.TransmissionLog)
-	for _, log := range logs {
-		grouped[log.Callsign] = append(grouped[log.Callsign], log)
-	}
-
-	return grouped, nil
+	return r.GetLogsBetween(since, time.Time{})
 }
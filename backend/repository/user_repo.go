@@ -37,6 +37,31 @@ func (r *UserRepo) GetByEmail(ctx context.Context, email string) (*models.User,
 	return &user, nil
 }
 
+// GetByTelegramID returns the user linked to a Telegram account, or nil.
+func (r *UserRepo) GetByTelegramID(ctx context.Context, telegramID int64) (*models.User, error) {
+	var users []models.User
+	if err := r.DB.WithContext(ctx).Where("telegram_id = ?", telegramID).Limit(1).Find(&users).Error; err != nil {
+		return nil, err
+	}
+	if len(users) == 0 {
+		return nil, nil
+	}
+	return &users[0], nil
+}
+
+// SetTelegramID links a Telegram account to a user, moving it off any other user, or
+// unlinks the user when telegramID is nil.
+func (r *UserRepo) SetTelegramID(ctx context.Context, userID int64, telegramID *int64) error {
+	return r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if telegramID != nil {
+			if err := tx.Model(&models.User{}).Where("telegram_id = ? AND id <> ?", *telegramID, userID).Update("telegram_id", nil).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Model(&models.User{}).Where("id = ?", userID).Update("telegram_id", telegramID).Error
+	})
+}
+
 // Count returns total number of users.
 func (r *UserRepo) Count(ctx context.Context) (int64, error) {
 	var count int64
@@ -80,3 +80,28 @@ func TestUserRepoCRUDAndCounts(t *testing.T) {
 		t.Fatalf("unexpected created at time")
 	}
 }
+
+func TestUserRepoTelegramLink(t *testing.T) {
+	repo := NewUserRepo(setupTestDB(t))
+	ctx := context.Background()
+	a, _ := repo.Create(ctx, "a@example.com", "h", models.RoleAdmin)
+	b, _ := repo.Create(ctx, "b@example.com", "h", models.RoleAdmin)
+
+	tg := int64(4242)
+	if err := repo.SetTelegramID(ctx, a.ID, &tg); err != nil {
+		t.Fatalf("link a: %v", err)
+	}
+	// Linking the same Telegram account to another user moves it
+	if err := repo.SetTelegramID(ctx, b.ID, &tg); err != nil {
+		t.Fatalf("link b: %v", err)
+	}
+	if u, err := repo.GetByTelegramID(ctx, tg); err != nil || u == nil || u.ID != b.ID {
+		t.Fatalf("expected b linked, got %+v err=%v", u, err)
+	}
+	if err := repo.SetTelegramID(ctx, b.ID, nil); err != nil {
+		t.Fatalf("unlink: %v", err)
+	}
+	if u, err := repo.GetByTelegramID(ctx, tg); err != nil || u != nil {
+		t.Fatalf("expected no linked user, got %+v err=%v", u, err)
+	}
+}
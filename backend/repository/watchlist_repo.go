@@ -0,0 +1,34 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/dbehnke/allstar-nexus/backend/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type WatchlistRepo struct{ db *gorm.DB }
+
+func NewWatchlistRepo(db *gorm.DB) *WatchlistRepo { return &WatchlistRepo{db: db} }
+
+// List returns all watched nodes ordered by node number.
+func (r *WatchlistRepo) List(ctx context.Context) ([]models.WatchedNode, error) {
+	var out []models.WatchedNode
+	err := r.db.WithContext(ctx).Order("node").Find(&out).Error
+	return out, err
+}
+
+// Save adds node to the watchlist or updates its settings.
+func (r *WatchlistRepo) Save(ctx context.Context, w models.WatchedNode) error {
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "node"}},
+		DoUpdates: clause.AssignmentColumns([]string{"label", "auto_connect", "connect_from", "updated_at"}),
+	}).Create(&w).Error
+}
+
+// Delete removes node from the watchlist and reports whether it was present.
+func (r *WatchlistRepo) Delete(ctx context.Context, node int) (bool, error) {
+	res := r.db.WithContext(ctx).Delete(&models.WatchedNode{}, "node = ?", node)
+	return res.RowsAffected > 0, res.Error
+}
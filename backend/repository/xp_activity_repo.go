@@ -4,18 +4,49 @@ import (
 	"context"
 	"time"
 
+	"github.com/dbehnke/allstar-nexus/backend/database"
 	"github.com/dbehnke/allstar-nexus/backend/models"
 	"gorm.io/gorm"
 )
 
+// ResetSchedule computes the start of the daily/weekly XP cap periods containing a time.
+type ResetSchedule interface {
+	DayStart(t time.Time) time.Time
+	WeekStart(t time.Time) time.Time
+}
+
 type XPActivityRepo struct {
-	db *gorm.DB
+	db       *gorm.DB
+	schedule ResetSchedule
 }
 
 func NewXPActivityRepo(db *gorm.DB) *XPActivityRepo {
 	return &XPActivityRepo{db: db}
 }
 
+// SetResetSchedule configures calendar-based cap periods (e.g. hub timezone, reset hour).
+// Without a schedule, days start at midnight UTC and weeks on Sunday.
+func (r *XPActivityRepo) SetResetSchedule(s ResetSchedule) {
+	r.schedule = s
+}
+
+// WithTx returns a copy of the repo, keeping its reset schedule, that runs its
+// queries in tx.
+func (r *XPActivityRepo) WithTx(tx *gorm.DB) *XPActivityRepo {
+	return &XPActivityRepo{db: tx, schedule: r.schedule}
+}
+
+// periodStarts returns the UTC start of the current day and week cap periods.
+// Activity is bucketed by hour, so boundaries are aligned down to the hour.
+func (r *XPActivityRepo) periodStarts(now time.Time) (day, week time.Time) {
+	if r.schedule == nil {
+		return now.UTC().Truncate(24 * time.Hour), getStartOfWeek()
+	}
+	day = r.schedule.DayStart(now).UTC().Truncate(time.Hour)
+	week = r.schedule.WeekStart(now).UTC().Truncate(time.Hour)
+	return day, week
+}
+
 // LogActivity records XP award with all multipliers for transparency
 func (r *XPActivityRepo) LogActivity(
 	ctx context.Context,
@@ -36,12 +67,12 @@ func (r *XPActivityRepo) LogActivity(
 		DRMultiplier:     drMultiplier,
 		KerchunkPenalty:  kerchunkPenalty,
 	}
-	return r.db.WithContext(ctx).Create(&log).Error
+	return database.Retry(ctx, func() error { return r.db.WithContext(ctx).Create(&log).Error })
 }
 
 // GetWeeklyXP returns total awarded XP for a callsign in current week
 func (r *XPActivityRepo) GetWeeklyXP(ctx context.Context, callsign string) (int, error) {
-	startOfWeek := getStartOfWeek()
+	_, startOfWeek := r.periodStarts(time.Now())
 	var totalXP int64
 	err := r.db.WithContext(ctx).
 		Model(&models.XPActivityLog{}).
@@ -53,7 +84,7 @@ func (r *XPActivityRepo) GetWeeklyXP(ctx context.Context, callsign string) (int,
 
 // GetDailyXP returns total awarded XP for a callsign today
 func (r *XPActivityRepo) GetDailyXP(ctx context.Context, callsign string) (int, error) {
-	startOfDay := time.Now().UTC().Truncate(24 * time.Hour)
+	startOfDay, _ := r.periodStarts(time.Now())
 	var totalXP int64
 	err := r.db.WithContext(ctx).
 		Model(&models.XPActivityLog{}).
@@ -4,6 +4,8 @@ import (
 	"embed"
 	"io/fs"
 	"net/http"
+	"path"
+	"regexp"
 	"strings"
 )
 
@@ -16,55 +18,79 @@ func FileServer(embeddedFS embed.FS, path string) (http.Handler, error) {
 	return http.FileServer(http.FS(sub)), nil
 }
 
+// Cache-Control values used by SPAFileServer.
+const (
+	cacheImmutable = "public, max-age=31536000, immutable"
+	cacheNoCache   = "no-cache"
+)
+
+// hashedAsset matches Vite build output such as assets/index-B3x9_kQz.js, whose
+// content hash changes whenever the file does.
+var hashedAsset = regexp.MustCompile(`^assets/.+[-.][A-Za-z0-9_-]{8,}\.[A-Za-z0-9]+$`)
+
+// backendPrefixes are never answered with index.html: a missing API route must stay a 404
+// rather than returning HTML to a JSON client.
+var backendPrefixes = []string{"api/", "ws"}
+
 // SPAFileServer serves static assets from an embedded FS and falls back to index.html
 // for any non-existent path, enabling client-side routing (Vue Router history mode).
-func SPAFileServer(embeddedFS embed.FS, basePath string) (http.Handler, error) {
-	sub, err := fs.Sub(embeddedFS, basePath)
+// Fingerprinted assets get long-lived immutable caching; index.html and other files are
+// served no-cache so a new deploy is picked up on the next load.
+func SPAFileServer(fsys fs.FS, basePath string) (http.Handler, error) {
+	sub, err := fs.Sub(fsys, basePath)
 	if err != nil {
 		return nil, err
 	}
-	static := http.FS(sub)
-	fileServer := http.FileServer(static)
+	fileServer := http.FileServer(http.FS(sub))
 
 	// Read index.html once so we can serve it directly on SPA fallbacks
 	indexBytes, readErr := fs.ReadFile(sub, "index.html")
 	if readErr != nil {
 		return nil, readErr
 	}
+	serveIndex := func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Header().Set("Cache-Control", cacheNoCache)
+		w.WriteHeader(http.StatusOK)
+		if r.Method != http.MethodHead {
+			_, _ = w.Write(indexBytes)
+		}
+	}
 
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		// Let API and WS routes be handled by other mux routes (they are more specific than "/").
-		// This handler is only mounted at "/" as a catch-all for the SPA paths.
-
-		// Try to serve the requested file; if it doesn't exist, rewrite to "/" to serve index.html.
-		reqPath := strings.TrimPrefix(r.URL.Path, "/")
-		if reqPath == "" {
-			// Serve index.html directly at root
-			w.Header().Set("Content-Type", "text/html; charset=utf-8")
-			w.Header().Set("Cache-Control", "no-cache")
-			w.WriteHeader(http.StatusOK)
-			_, _ = w.Write(indexBytes)
+		// This handler is mounted at "/" as a catch-all; API and WS routes are more specific.
+		reqPath := strings.TrimPrefix(path.Clean("/"+r.URL.Path), "/")
+		if reqPath == "" || reqPath == "index.html" {
+			// Serve index.html directly (FileServer would redirect /index.html to /)
+			serveIndex(w, r)
 			return
 		}
 
 		// Attempt to open the requested path from the embedded FS
-		if f, err := sub.Open(reqPath); err == nil {
-			// If it's a file (not a directory), delegate to the file server
-			if fi, statErr := f.Stat(); statErr == nil && fi != nil && !fi.IsDir() {
-				_ = f.Close()
-				fileServer.ServeHTTP(w, r)
+		if fi, err := fs.Stat(sub, reqPath); err == nil && !fi.IsDir() {
+			if hashedAsset.MatchString(reqPath) {
+				w.Header().Set("Cache-Control", cacheImmutable)
+			} else {
+				w.Header().Set("Cache-Control", cacheNoCache)
+			}
+			fileServer.ServeHTTP(w, r)
+			return
+		}
+
+		// Unknown backend paths, missing files with an extension (a stale asset reference)
+		// and non-GET requests are real 404s rather than client-side routes.
+		for _, p := range backendPrefixes {
+			if reqPath == strings.TrimSuffix(p, "/") || strings.HasPrefix(reqPath, p) {
+				http.NotFound(w, r)
 				return
 			}
-			_ = f.Close()
+		}
+		if path.Ext(reqPath) != "" || (r.Method != http.MethodGet && r.Method != http.MethodHead) {
+			http.NotFound(w, r)
+			return
 		}
 
-		// Fallback: serve index.html directly to enable client-side routing
-		// Avoid relying on FileServer directory logic which may 404 for unknown paths.
-		// Preserve query/hash for client-side router in the browser.
-		w.Header().Set("Content-Type", "text/html; charset=utf-8")
-		// Defensive: ensure consistent cache behavior for index.html
-		w.Header().Set("Cache-Control", "no-cache")
-		w.WriteHeader(http.StatusOK)
-		_, _ = w.Write(indexBytes)
+		// Fallback: serve index.html so deep links such as /scoreboard reach the router.
+		serveIndex(w, r)
 	}), nil
 }
@@ -0,0 +1,53 @@
+package server
+
+import (
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+)
+
+func TestSPAFileServer(t *testing.T) {
+	fsys := fstest.MapFS{
+		"dist/index.html":               {Data: []byte("<html>app</html>")},
+		"dist/assets/index-B3x9_kQz.js": {Data: []byte("console.log(1)")},
+		"dist/favicon.ico":              {Data: []byte("ico")},
+		"dist/assets/logo.svg":          {Data: []byte("<svg/>")},
+	}
+	h, err := SPAFileServer(fsys, "dist")
+	if err != nil {
+		t.Fatalf("SPAFileServer: %v", err)
+	}
+
+	cases := []struct {
+		method, path string
+		status       int
+		cache        string
+		index        bool
+	}{
+		{"GET", "/", 200, cacheNoCache, true},
+		{"GET", "/index.html", 200, cacheNoCache, true},
+		{"GET", "/scoreboard", 200, cacheNoCache, true},
+		{"GET", "/nodes/12345/history", 200, cacheNoCache, true},
+		{"GET", "/assets/index-B3x9_kQz.js", 200, cacheImmutable, false},
+		{"GET", "/assets/logo.svg", 200, cacheNoCache, false},
+		{"GET", "/favicon.ico", 200, cacheNoCache, false},
+		{"GET", "/assets/index-OLDHASH1.js", 404, "", false},
+		{"GET", "/api/nope", 404, "", false},
+		{"GET", "/ws", 404, "", false},
+		{"POST", "/scoreboard", 404, "", false},
+	}
+	for _, tc := range cases {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(tc.method, tc.path, nil))
+		if rec.Code != tc.status {
+			t.Errorf("%s %s: status %d, want %d", tc.method, tc.path, rec.Code, tc.status)
+			continue
+		}
+		if tc.cache != "" && rec.Header().Get("Cache-Control") != tc.cache {
+			t.Errorf("%s %s: Cache-Control %q, want %q", tc.method, tc.path, rec.Header().Get("Cache-Control"), tc.cache)
+		}
+		if tc.index && rec.Body.String() != "<html>app</html>" {
+			t.Errorf("%s %s: expected index.html body, got %q", tc.method, tc.path, rec.Body.String())
+		}
+	}
+}
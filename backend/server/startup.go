@@ -0,0 +1,62 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync/atomic"
+)
+
+// Startup lets the HTTP listener come up before the database is migrated, so container
+// orchestrators can probe a starting process. Until Serve installs the application's
+// handler it answers only /api/health (liveness) and /api/health/startup; everything else
+// gets 503 with Retry-After.
+type Startup struct {
+	migrated atomic.Bool
+	handler  atomic.Pointer[http.Handler]
+}
+
+// MarkMigrated records that database migrations completed.
+func (s *Startup) MarkMigrated() { s.migrated.Store(true) }
+
+// Serve installs the application's handler, which must route /api/health/startup to Probe.
+func (s *Startup) Serve(h http.Handler) { s.handler.Store(&h) }
+
+// Probe reports 200 once migrations have completed and 503 before.
+// Endpoint: GET /api/health/startup
+func (s *Startup) Probe(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case !s.migrated.Load():
+		writeStatus(w, http.StatusServiceUnavailable, "migrating")
+	case s.handler.Load() == nil:
+		writeStatus(w, http.StatusOK, "starting")
+	default:
+		writeStatus(w, http.StatusOK, "ready")
+	}
+}
+
+func (s *Startup) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if h := s.handler.Load(); h != nil {
+		(*h).ServeHTTP(w, r)
+		return
+	}
+	switch r.URL.Path {
+	case "/api/health":
+		writeStatus(w, http.StatusOK, "ok")
+	case "/api/health/startup":
+		s.Probe(w, r)
+	default:
+		w.Header().Set("Retry-After", "5")
+		writeStatus(w, http.StatusServiceUnavailable, "starting")
+	}
+}
+
+// writeStatus writes the API's response envelope; failures carry the status as the error code.
+func writeStatus(w http.ResponseWriter, code int, status string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	body := map[string]any{"ok": true, "data": map[string]string{"status": status}}
+	if code >= 400 {
+		body = map[string]any{"ok": false, "error": map[string]string{"code": status, "message": "server is " + status}}
+	}
+	_ = json.NewEncoder(w).Encode(body)
+}
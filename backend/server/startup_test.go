@@ -0,0 +1,32 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestStartupGate(t *testing.T) {
+	s := &Startup{}
+	get := func(path string) int {
+		rec := httptest.NewRecorder()
+		s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec.Code
+	}
+
+	if get("/api/health") != 200 || get("/api/health/startup") != 503 || get("/api/status") != 503 {
+		t.Fatal("before migrations: want liveness 200, startup 503, app 503")
+	}
+	s.MarkMigrated()
+	if get("/api/health/startup") != 200 || get("/api/status") != 503 {
+		t.Fatal("after migrations: want startup 200 while the app is still starting")
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/health/startup", s.Probe)
+	mux.HandleFunc("/api/status", func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusTeapot) })
+	s.Serve(mux)
+	if get("/api/status") != http.StatusTeapot || get("/api/health/startup") != 200 {
+		t.Fatal("after Serve: requests should reach the application handler")
+	}
+}
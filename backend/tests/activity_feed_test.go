@@ -0,0 +1,79 @@
+package tests
+
+import (
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dbehnke/allstar-nexus/backend/api"
+	"github.com/dbehnke/allstar-nexus/internal/core"
+	"github.com/dbehnke/allstar-nexus/internal/privacy"
+)
+
+func TestActivityFeedAtom(t *testing.T) {
+	feed := core.NewActivityFeed(core.ActivityFeedOptions{QSOMinOvers: 1, LongTx: time.Minute})
+	at := time.Now().Add(-time.Hour)
+	feed.Observe(core.TalkerEvent{At: at, Kind: "TX_STOP", Node: 2000, Callsign: "W1AW", Duration: 90})
+	feed.Observe(core.TalkerEvent{At: at.Add(time.Minute), Kind: "TX_STOP", Node: 2001, Callsign: "K8ABC", Duration: 20})
+	feed.Flush(true)
+	feed.LevelUp("K8ABC", 7, 0)
+
+	apiLayer := &api.API{Privacy: privacy.DefaultPolicy(), BrandingDefaults: api.Branding{Title: "Test Club"}}
+	rec := httptest.NewRecorder()
+	apiLayer.ActivityFeedAtom(rec, httptest.NewRequest(http.MethodGet, "/feed.atom", nil))
+	if rec.Code != 503 {
+		t.Fatalf("disabled feed status = %d", rec.Code)
+	}
+
+	apiLayer.SetActivityFeed(feed)
+	rec = httptest.NewRecorder()
+	apiLayer.ActivityFeedAtom(rec, httptest.NewRequest(http.MethodGet, "http://hub.example.org:8080/feed.atom", nil))
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "application/atom+xml") {
+		t.Fatalf("content type = %q", ct)
+	}
+	var doc struct {
+		Title   string `xml:"title"`
+		Entries []struct {
+			ID       string `xml:"id"`
+			Title    string `xml:"title"`
+			Category struct {
+				Term string `xml:"term,attr"`
+			} `xml:"category"`
+		} `xml:"entry"`
+	}
+	if err := xml.Unmarshal(rec.Body.Bytes(), &doc); err != nil {
+		t.Fatalf("invalid atom: %v\n%s", err, rec.Body.String())
+	}
+	if doc.Title != "Test Club activity" || len(doc.Entries) != 3 {
+		t.Fatalf("feed = %+v", doc)
+	}
+	if e := doc.Entries[0]; e.Category.Term != "level_up" || e.Title != "K8ABC reached level 7" || !strings.HasPrefix(e.ID, "tag:hub.example.org,") {
+		t.Fatalf("newest entry = %+v", e)
+	}
+	if e := doc.Entries[1]; e.Category.Term != "qso" || !strings.Contains(e.Title, "W1AW, K8ABC") {
+		t.Fatalf("qso entry = %+v", e)
+	}
+
+	// Hidden callsigns: talker entries fall back to node numbers, level-ups are dropped
+	apiLayer.SetPrivacyPolicy(privacy.Policy{HideAnonCallsigns: true})
+	rec = httptest.NewRecorder()
+	apiLayer.ActivityFeedAtom(rec, httptest.NewRequest(http.MethodGet, "/feed.atom", nil))
+	body := rec.Body.String()
+	if strings.Contains(body, "W1AW") || strings.Contains(body, "K8ABC") || !strings.Contains(body, "node 2000") {
+		t.Fatalf("masked feed = %s", body)
+	}
+
+	// Hidden talker history: only level-ups could remain, and they need callsigns
+	apiLayer.SetPrivacyPolicy(privacy.Policy{HideAnonTalkerHistory: true})
+	rec = httptest.NewRecorder()
+	apiLayer.ActivityFeedAtom(rec, httptest.NewRequest(http.MethodGet, "/feed.atom", nil))
+	if body := rec.Body.String(); strings.Contains(body, "<entry>") && !strings.Contains(body, "level_up") {
+		t.Fatalf("restricted feed = %s", body)
+	}
+	if strings.Contains(rec.Body.String(), "qso") {
+		t.Fatalf("restricted feed leaked sessions: %s", rec.Body.String())
+	}
+}
@@ -0,0 +1,154 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dbehnke/allstar-nexus/backend/api"
+	"github.com/dbehnke/allstar-nexus/backend/auth"
+	"github.com/dbehnke/allstar-nexus/backend/models"
+	"github.com/dbehnke/allstar-nexus/backend/repository"
+	"github.com/dbehnke/allstar-nexus/internal/ami"
+	"github.com/dbehnke/allstar-nexus/internal/core"
+	"github.com/dbehnke/allstar-nexus/internal/web"
+	gws "github.com/gorilla/websocket"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+type consoleSender struct{ lines int }
+
+func (s consoleSender) SendCommand(ctx context.Context, command string) (ami.Message, error) {
+	raw := []string{"Response: Success", "Message: Command output follows"}
+	for i := 0; i < s.lines; i++ {
+		raw = append(raw, "Output: "+command+" line")
+	}
+	return ami.Message{Type: ami.MessageTypeResponse, Raw: raw}, nil
+}
+
+func TestAMIConsole(t *testing.T) {
+	gdb, err := gorm.Open(sqlite.New(sqlite.Config{DriverName: "sqlite", DSN: filepath.Join(t.TempDir(), "test.db")}), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open gorm sqlite: %v", err)
+	}
+	if err := gdb.AutoMigrate(&models.User{}, &models.AuditEntry{}); err != nil {
+		t.Fatalf("automigrate: %v", err)
+	}
+	apiLayer := api.New(gdb, "test-secret", time.Hour)
+	audit := repository.NewAuditRepo(gdb)
+	console := core.NewAMIConsole(consoleSender{lines: 120}, nil, 2000, 0, audit)
+	apiLayer.SetAMIConsole(console, audit)
+
+	hash, _ := auth.HashPassword("password123")
+	for email, role := range map[string]string{"root@example.com": models.RoleSuperAdmin, "admin@example.com": models.RoleAdmin} {
+		if _, err := apiLayer.Users.Create(t.Context(), email, hash, role); err != nil {
+			t.Fatalf("create user: %v", err)
+		}
+	}
+	rootToken, _ := auth.GenerateJWT("root@example.com", models.RoleSuperAdmin, time.Hour, "test-secret")
+	adminToken, _ := auth.GenerateJWT("admin@example.com", models.RoleAdmin, time.Hour, "test-secret")
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/admin/ami/command", apiLayer.AMICommand)
+	mux.HandleFunc("/api/admin/ami/console", web.HandleAMIConsole(console, func(r *http.Request) (string, bool) {
+		return "root@example.com", r.URL.Query().Get("token") == rootToken
+	}))
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	post := func(token, command string, out any) int {
+		body, _ := json.Marshal(map[string]string{"command": command})
+		req, _ := http.NewRequest(http.MethodPost, srv.URL+"/api/admin/ami/command", bytes.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("post: %v", err)
+		}
+		defer resp.Body.Close()
+		var env envelope
+		_ = json.NewDecoder(resp.Body).Decode(&env)
+		if out != nil {
+			_ = json.Unmarshal(env.Data, out)
+		}
+		return resp.StatusCode
+	}
+
+	if code := post(adminToken, "rpt stats 1999", nil); code != http.StatusForbidden {
+		t.Fatalf("admin run = %d, want 403", code)
+	}
+	if code := post(rootToken, "rpt fun 1999 *3", nil); code != http.StatusForbidden {
+		t.Fatalf("non-whitelisted run = %d, want 403", code)
+	}
+	var res core.AMIConsoleResult
+	if code := post(rootToken, "rpt stats 1999", &res); code != http.StatusOK {
+		t.Fatalf("run = %d", code)
+	}
+	if !res.Truncated || res.Bytes <= 2000 || len(strings.Join(res.Lines, "\n")) > 2000 || res.Lines[0] != "rpt stats 1999 line" {
+		t.Fatalf("result = %+v", res)
+	}
+
+	// WebSocket: output arrives in chunks followed by a done frame
+	wsURL := "ws" + strings.TrimPrefix(srv.URL, "http") + "/api/admin/ami/console?token="
+	if _, _, err := (&gws.Dialer{}).Dial(wsURL+url.QueryEscape(adminToken), nil); err == nil {
+		t.Fatal("admin token accepted by console websocket")
+	}
+	conn, _, err := (&gws.Dialer{}).Dial(wsURL+url.QueryEscape(rootToken), nil)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer func() { _ = conn.Close() }()
+	_ = conn.WriteJSON(map[string]string{"id": "1", "command": "iax2 show peers"})
+	var lines, chunks int
+	for done := false; !done; {
+		_ = conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+		var env struct {
+			MessageType string          `json:"messageType"`
+			Data        json.RawMessage `json:"data"`
+		}
+		if err := conn.ReadJSON(&env); err != nil {
+			t.Fatalf("read: %v", err)
+		}
+		switch env.MessageType {
+		case "AMI_CONSOLE_OUTPUT":
+			var chunk struct {
+				ID    string   `json:"id"`
+				Lines []string `json:"lines"`
+			}
+			_ = json.Unmarshal(env.Data, &chunk)
+			if chunk.ID != "1" {
+				t.Fatalf("chunk id %q", chunk.ID)
+			}
+			lines += len(chunk.Lines)
+			chunks++
+		case "AMI_CONSOLE_DONE":
+			done = true
+		case "AMI_CONSOLE_ERROR":
+			t.Fatalf("error frame: %s", env.Data)
+		}
+	}
+	if chunks < 2 || lines == 0 {
+		t.Fatalf("lines=%d chunks=%d, want output split into chunks", lines, chunks)
+	}
+
+	entries, err := audit.Recent(t.Context(), core.AuditActionAMICommand, 10)
+	if err != nil {
+		t.Fatalf("audit: %v", err)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("audit entries = %+v, want 3", entries)
+	}
+	if e := entries[2]; e.Outcome != models.AuditOutcomeDenied || e.Detail != "rpt fun 1999 *3" || e.Actor != "root@example.com" {
+		t.Fatalf("denied entry = %+v", e)
+	}
+	if e := entries[0]; e.Outcome != models.AuditOutcomeOK || e.Detail != "iax2 show peers" {
+		t.Fatalf("ws entry = %+v", e)
+	}
+}
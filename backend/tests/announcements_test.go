@@ -0,0 +1,124 @@
+package tests
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dbehnke/allstar-nexus/backend/api"
+	"github.com/dbehnke/allstar-nexus/backend/models"
+	"github.com/dbehnke/allstar-nexus/backend/repository"
+	"github.com/dbehnke/allstar-nexus/internal/ami"
+	"github.com/dbehnke/allstar-nexus/internal/core"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+type playSender struct {
+	mu       sync.Mutex
+	commands []string
+}
+
+func (s *playSender) SendCommand(ctx context.Context, command string) (ami.Message, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.commands = append(s.commands, command)
+	return ami.Message{}, nil
+}
+
+func TestAnnouncements(t *testing.T) {
+	gdb, err := gorm.Open(sqlite.New(sqlite.Config{DriverName: "sqlite", DSN: filepath.Join(t.TempDir(), "test.db")}), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open gorm sqlite: %v", err)
+	}
+	if err := gdb.AutoMigrate(&models.User{}, &models.AuditEntry{}, &models.Announcement{}); err != nil {
+		t.Fatalf("automigrate: %v", err)
+	}
+	soundsDir := t.TempDir()
+	sender := &playSender{}
+	synth := func(ctx context.Context, text, out string) error {
+		if strings.Contains(text, "fail") {
+			return os.ErrPermission
+		}
+		return os.WriteFile(out, []byte(text), 0o644)
+	}
+	repo, audit := repository.NewAnnouncementRepo(gdb), repository.NewAuditRepo(gdb)
+	announcer := core.NewAnnouncer(repo, audit, sender, synth, core.AnnouncerOptions{Nodes: []int{2001, 2002}, SoundsDir: soundsDir, MaxChars: 40})
+	apiLayer := api.New(gdb, "test-secret", time.Hour)
+	srv := httptest.NewServer(http.HandlerFunc(apiLayer.AdminAnnouncements))
+	t.Cleanup(srv.Close)
+
+	if code := apiRequest(t, http.MethodGet, srv.URL, "", nil); code != 503 {
+		t.Fatalf("disabled: expected 503, got %d", code)
+	}
+	apiLayer.SetAnnouncer(announcer, repo)
+
+	var now models.Announcement
+	if code := apiRequest(t, http.MethodPost, srv.URL, `{"text":"  Net starts   in ten minutes "}`, &now); code != 200 || now.Node != 2001 || now.Text != "Net starts in ten minutes" || now.Status != models.AnnouncementPending {
+		t.Fatalf("submit now: %d %+v", code, now)
+	}
+	var later models.Announcement
+	at := time.Now().Add(time.Hour).UTC().Format(time.RFC3339)
+	if code := apiRequest(t, http.MethodPost, srv.URL, `{"text":"Later","node":2002,"at":"`+at+`"}`, &later); code != 200 || later.Node != 2002 {
+		t.Fatalf("submit later: %d %+v", code, later)
+	}
+	for _, body := range []string{`{"text":"   "}`, `{"text":"x","node":9999}`, `{"text":"` + strings.Repeat("a", 41) + `"}`} {
+		if code := apiRequest(t, http.MethodPost, srv.URL, body, nil); code != 400 {
+			t.Fatalf("%s: expected 400, got %d", body, code)
+		}
+	}
+	var failing models.Announcement
+	apiRequest(t, http.MethodPost, srv.URL, `{"text":"this will fail"}`, &failing)
+
+	if played := announcer.PlayDue(context.Background()); played != 1 {
+		t.Fatalf("expected 1 played, got %d", played)
+	}
+	want := "rpt localplay 2001 " + filepath.Join(soundsDir, "announcement-1")
+	if len(sender.commands) != 1 || sender.commands[0] != want {
+		t.Fatalf("commands: %v (want %q)", sender.commands, want)
+	}
+	if _, err := os.Stat(filepath.Join(soundsDir, "announcement-1.wav")); err != nil {
+		t.Fatalf("rendered audio: %v", err)
+	}
+	if announcer.PlayDue(context.Background()) != 0 {
+		t.Fatal("announcements must only play once")
+	}
+
+	if code := apiRequest(t, http.MethodDelete, srv.URL+"?id=1", "", nil); code != 404 {
+		t.Fatalf("cancel played: expected 404, got %d", code)
+	}
+	if code := apiRequest(t, http.MethodDelete, srv.URL+"?id=2", "", nil); code != 200 {
+		t.Fatalf("cancel scheduled: %d", code)
+	}
+
+	var list struct {
+		Announcements []models.Announcement `json:"announcements"`
+		Nodes         []int                 `json:"nodes"`
+	}
+	apiRequest(t, http.MethodGet, srv.URL, "", &list)
+	status := map[uint]string{}
+	for _, a := range list.Announcements {
+		status[a.ID] = a.Status
+	}
+	if status[now.ID] != models.AnnouncementPlayed || status[later.ID] != models.AnnouncementCanceled || status[failing.ID] != models.AnnouncementFailed || len(list.Nodes) != 2 {
+		t.Fatalf("list: %+v", list)
+	}
+
+	entries, _ := audit.Recent(context.Background(), core.AuditActionAnnouncement, 20)
+	var details []string
+	for _, e := range entries {
+		details = append(details, e.Detail+" "+e.Outcome)
+	}
+	got := strings.Join(details, "\n")
+	for _, want := range []string{"submit #1 on 2001", "play #1 on 2001 ok", "play #3 on 2001 error", "cancel #2 ok"} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("audit log missing %q:\n%s", want, got)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package tests
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/dbehnke/allstar-nexus/backend/api"
+)
+
+func TestAstDBFilePassthrough(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "astdb.txt")
+	apiLayer := api.New(nil, "test-secret", time.Hour)
+	apiLayer.SetAstDBPath(path)
+	srv := httptest.NewServer(http.HandlerFunc(apiLayer.AstDBFile))
+	t.Cleanup(srv.Close)
+
+	if resp, err := http.Get(srv.URL); err != nil || resp.StatusCode != 404 {
+		t.Fatalf("missing file: resp=%v err=%v", resp, err)
+	}
+
+	content := "2000|W1AW|Hub|Newington, CT\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path+".meta", []byte(`{"etag":"\"v1\""}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := http.Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != 200 || string(body) != content || resp.Header.Get("ETag") != `"v1"` {
+		t.Fatalf("get: code=%d etag=%q body=%q", resp.StatusCode, resp.Header.Get("ETag"), body)
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, srv.URL, nil)
+	req.Header.Set("If-None-Match", `"v1"`)
+	resp, err = http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotModified {
+		t.Fatalf("conditional get: code=%d", resp.StatusCode)
+	}
+}
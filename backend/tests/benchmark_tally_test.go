@@ -32,6 +32,7 @@ func BenchmarkProcessTally_ManyLogs(b *testing.B) {
 		&models.TransmissionLog{},
 		&models.XPActivityLog{},
 		&models.TallyState{},
+		&models.TallyWindowMark{},
 	); err != nil {
 		b.Fatalf("automigrate: %v", err)
 	}
@@ -0,0 +1,158 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"image"
+	"image/png"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dbehnke/allstar-nexus/backend/api"
+	"github.com/dbehnke/allstar-nexus/backend/config"
+	"github.com/dbehnke/allstar-nexus/backend/models"
+	"github.com/dbehnke/allstar-nexus/backend/repository"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func newBrandingServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	dir := t.TempDir()
+	gdb, err := gorm.Open(sqlite.New(sqlite.Config{DriverName: "sqlite", DSN: filepath.Join(dir, "test.db")}), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open gorm sqlite: %v", err)
+	}
+	if err := gdb.AutoMigrate(&models.User{}, &models.Setting{}); err != nil {
+		t.Fatalf("automigrate: %v", err)
+	}
+	apiLayer := api.New(gdb, "test-secret", time.Hour)
+	apiLayer.SetBranding(api.Branding{
+		Title:       "Test Hub",
+		Colors:      map[string]string{"primary": "#112233", "accent": "#445566"},
+		FooterLinks: []config.FooterLink{{Label: "ASL", URL: "https://www.allstarlink.org"}},
+	}, repository.NewSettingsRepo(gdb), dir)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/branding", apiLayer.GetBranding)
+	mux.HandleFunc("/api/branding/logo", apiLayer.BrandingLogo)
+	mux.HandleFunc("/api/admin/branding", apiLayer.AdminBranding)
+	mux.HandleFunc("/api/admin/branding/logo", apiLayer.AdminBrandingLogo)
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func getBranding(t *testing.T, url string) api.Branding {
+	t.Helper()
+	resp, err := http.Get(url + "/api/branding")
+	if err != nil {
+		t.Fatalf("get branding: %v", err)
+	}
+	defer resp.Body.Close()
+	var env envelope
+	_ = json.NewDecoder(resp.Body).Decode(&env)
+	var b api.Branding
+	if err := json.Unmarshal(env.Data, &b); err != nil {
+		t.Fatalf("decode branding: %v", err)
+	}
+	return b
+}
+
+func putBranding(t *testing.T, url, body string) int {
+	t.Helper()
+	req, _ := http.NewRequest(http.MethodPut, url+"/api/admin/branding", strings.NewReader(body))
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("put branding: %v", err)
+	}
+	resp.Body.Close()
+	return resp.StatusCode
+}
+
+func TestBrandingOverrideMergesWithConfig(t *testing.T) {
+	srv := newBrandingServer(t)
+
+	b := getBranding(t, srv.URL)
+	if b.ClubName != "Test Hub" || b.Colors["primary"] != "#112233" || len(b.FooterLinks) != 1 {
+		t.Fatalf("unexpected defaults: %+v", b)
+	}
+
+	if code := putBranding(t, srv.URL, `{"club_name":"WC8MI","colors":{"primary":"#abcdef"}}`); code != 200 {
+		t.Fatalf("put status %d", code)
+	}
+	b = getBranding(t, srv.URL)
+	if b.ClubName != "WC8MI" || b.Colors["primary"] != "#abcdef" || b.Colors["accent"] != "#445566" {
+		t.Fatalf("override not merged: %+v", b)
+	}
+	if len(b.FooterLinks) != 1 {
+		t.Fatalf("footer links should be inherited, got %+v", b.FooterLinks)
+	}
+
+	for _, bad := range []string{
+		`{"colors":{"primary":"red; background:url(x)"}}`,
+		`{"footer_links":[{"label":"x","url":"javascript:alert(1)"}]}`,
+	} {
+		if code := putBranding(t, srv.URL, bad); code != 400 {
+			t.Errorf("expected 400 for %s, got %d", bad, code)
+		}
+	}
+
+	req, _ := http.NewRequest(http.MethodDelete, srv.URL+"/api/admin/branding", nil)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	resp.Body.Close()
+	if b = getBranding(t, srv.URL); b.ClubName != "Test Hub" {
+		t.Fatalf("reset failed: %+v", b)
+	}
+}
+
+func uploadLogo(t *testing.T, url string, data []byte) int {
+	t.Helper()
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+	fw, _ := mw.CreateFormFile("logo", "logo.bin")
+	_, _ = fw.Write(data)
+	_ = mw.Close()
+	resp, err := http.Post(url+"/api/admin/branding/logo", mw.FormDataContentType(), &buf)
+	if err != nil {
+		t.Fatalf("upload: %v", err)
+	}
+	resp.Body.Close()
+	return resp.StatusCode
+}
+
+func TestBrandingLogoUpload(t *testing.T) {
+	srv := newBrandingServer(t)
+
+	if code := uploadLogo(t, srv.URL, []byte("<svg onload=alert(1)></svg>")); code != 400 {
+		t.Fatalf("svg upload should be rejected, got %d", code)
+	}
+
+	var img bytes.Buffer
+	_ = png.Encode(&img, image.NewRGBA(image.Rect(0, 0, 4, 4)))
+	if code := uploadLogo(t, srv.URL, img.Bytes()); code != 200 {
+		t.Fatalf("png upload status %d", code)
+	}
+	b := getBranding(t, srv.URL)
+	if !strings.HasPrefix(b.LogoURL, "/api/branding/logo?v=") {
+		t.Fatalf("logo_url not set: %q", b.LogoURL)
+	}
+
+	resp, err := http.Get(srv.URL + b.LogoURL)
+	if err != nil {
+		t.Fatalf("get logo: %v", err)
+	}
+	defer resp.Body.Close()
+	got, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != 200 || resp.Header.Get("Content-Type") != "image/png" || !bytes.Equal(got, img.Bytes()) {
+		t.Fatalf("logo fetch: status=%d type=%q len=%d", resp.StatusCode, resp.Header.Get("Content-Type"), len(got))
+	}
+}
@@ -0,0 +1,67 @@
+package tests
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/dbehnke/allstar-nexus/backend/gamification"
+	"github.com/dbehnke/allstar-nexus/backend/models"
+	"github.com/dbehnke/allstar-nexus/backend/repository"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	_ "modernc.org/sqlite"
+)
+
+// Profile DailyXP/WeeklyXP counters are zeroed once their calendar cap period has ended.
+func TestTallyZeroesCapCountersOnPeriodRollover(t *testing.T) {
+	dir := t.TempDir()
+	gdb, err := gorm.Open(sqlite.New(sqlite.Config{DriverName: "sqlite", DSN: filepath.Join(dir, "cap_reset.db")}), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open gorm sqlite: %v", err)
+	}
+	if err := gdb.AutoMigrate(&models.CallsignProfile{}, &models.LevelConfig{}, &models.TransmissionLog{}, &models.XPActivityLog{}, &models.TallyState{}, &models.TallyWindowMark{}); err != nil {
+		t.Fatalf("automigrate: %v", err)
+	}
+	ctx := context.Background()
+	levelRepo := repository.NewLevelConfigRepo(gdb)
+	profileRepo := repository.NewCallsignProfileRepo(gdb)
+	txRepo := repository.NewTransmissionLogRepository(gdb)
+	activityRepo := repository.NewXPActivityRepo(gdb)
+	stateRepo := repository.NewTallyStateRepo(gdb)
+	if err := levelRepo.SeedDefaults(ctx, gamification.CalculateLevelRequirements()); err != nil {
+		t.Fatalf("seed level config: %v", err)
+	}
+
+	sched := gamification.CapSchedule{Location: time.UTC, ResetHour: 0, FirstWeekday: time.Monday}
+	now := time.Now().UTC()
+	seed := func(callsign string, lastTally time.Time) {
+		p, err := profileRepo.GetByCallsign(ctx, callsign)
+		if err != nil {
+			t.Fatalf("get profile: %v", err)
+		}
+		p.DailyXP, p.WeeklyXP, p.LastTallyAt = 300, 900, lastTally
+		if err := profileRepo.Upsert(ctx, p); err != nil {
+			t.Fatalf("upsert: %v", err)
+		}
+	}
+	seed("W1STALE", sched.WeekStart(now).Add(-time.Hour)) // previous week
+	seed("W1TODAY", sched.DayStart(now).Add(time.Second)) // current day
+
+	cfg := &gamification.Config{CapsEnabled: true, DailyCapSeconds: 1200, WeeklyCapSeconds: 7200, CapSchedule: sched}
+	ts := gamification.NewTallyService(gdb, txRepo, profileRepo, levelRepo, activityRepo, stateRepo, cfg, 30*time.Minute, zaptestLogger())
+	if err := ts.ProcessTally(); err != nil {
+		t.Fatalf("tally: %v", err)
+	}
+
+	stale, _ := profileRepo.GetByCallsign(ctx, "W1STALE")
+	if stale.DailyXP != 0 || stale.WeeklyXP != 0 {
+		t.Fatalf("expected stale counters zeroed, got daily=%d weekly=%d", stale.DailyXP, stale.WeeklyXP)
+	}
+	today, _ := profileRepo.GetByCallsign(ctx, "W1TODAY")
+	if today.DailyXP != 300 || today.WeeklyXP != 900 {
+		t.Fatalf("expected current counters kept, got daily=%d weekly=%d", today.DailyXP, today.WeeklyXP)
+	}
+}
@@ -0,0 +1,114 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/dbehnke/allstar-nexus/backend/api"
+	"github.com/dbehnke/allstar-nexus/backend/auth"
+	"github.com/dbehnke/allstar-nexus/backend/models"
+	"github.com/dbehnke/allstar-nexus/backend/repository/memrepo"
+	"github.com/dbehnke/allstar-nexus/internal/core"
+	"github.com/dbehnke/allstar-nexus/internal/privacy"
+)
+
+type dashboardStateStub struct{}
+
+func (dashboardStateStub) TalkerLogSnapshot() any {
+	return []core.TalkerEvent{
+		{Kind: "TX_STOP", Node: 2000, Callsign: "W1AW", Duration: 12},
+		{Kind: "TX_START", Node: 2001, Callsign: "K8ABC"},
+	}
+}
+
+func (dashboardStateStub) Snapshot() core.NodeState {
+	return core.NodeState{NodeID: 1999, TxKeyed: true, Links: []int{2000, 2001}, NumLinks: 7, NumALinks: 2}
+}
+
+func TestDashboardSummaryBootstrapPayload(t *testing.T) {
+	apiLayer := api.New(nil, "test-secret", time.Hour)
+	apiLayer.Users = memrepo.NewUsers()
+	apiLayer.SetStateManager(dashboardStateStub{})
+	apiLayer.SetPrivacyPolicy(privacy.Policy{HideAnonCallsigns: true, HideAnonTalkerHistory: true})
+
+	txLogs := memrepo.NewTxLogs()
+	profiles := memrepo.NewProfiles()
+	apiLayer.SetDashboardSources(txLogs, profiles)
+
+	now := time.Now()
+	for i, cs := range []string{"W1AW", "W1AW", "K8ABC"} {
+		start := now.Add(-time.Duration(i+1) * time.Second)
+		if err := txLogs.LogTransmission(1999, 2000+i, cs, start, start.Add(10*time.Second), 10); err != nil {
+			t.Fatalf("log: %v", err)
+		}
+	}
+	_ = txLogs.LogTransmission(1999, 2000, "W1AW", now.Add(-48*time.Hour), now.Add(-48*time.Hour+time.Minute), 60)
+	for i, cs := range []string{"W1AW", "K8ABC", "N0CALL", "KD8XYZ"} {
+		if err := profiles.Upsert(t.Context(), &models.CallsignProfile{Callsign: cs, Level: 10 - i}); err != nil {
+			t.Fatalf("profile: %v", err)
+		}
+	}
+	hash, _ := auth.HashPassword("password123")
+	if _, err := apiLayer.Users.Create(t.Context(), "user@example.com", hash, models.RoleUser); err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+	userToken, _ := auth.GenerateJWT("user@example.com", models.RoleUser, time.Hour, "test-secret")
+
+	get := func(token string) map[string]any {
+		req := httptest.NewRequest(http.MethodGet, "/api/dashboard/summary", nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		apiLayer.DashboardSummary(rec, req)
+		if rec.Code != 200 {
+			t.Fatalf("status %d: %s", rec.Code, rec.Body.String())
+		}
+		var env envelope
+		_ = json.Unmarshal(rec.Body.Bytes(), &env)
+		var out map[string]any
+		_ = json.Unmarshal(env.Data, &out)
+		return out
+	}
+
+	sum := get(userToken)
+	if sum["total_users"].(float64) != 1 {
+		t.Fatalf("total_users = %v", sum["total_users"])
+	}
+	node := sum["node"].(map[string]any)
+	if node["node_id"].(float64) != 1999 || node["tx_keyed"] != true || node["links"].(float64) != 2 || node["total_links"].(float64) != 7 {
+		t.Fatalf("node = %v", node)
+	}
+	if last := sum["last_talker"].(map[string]any); last["callsign"] != "K8ABC" {
+		t.Fatalf("last_talker = %v", last)
+	}
+	tx := sum["tx_today"].(map[string]any)
+	if tx["transmissions"].(float64) != 3 || tx["seconds"].(float64) != 30 || tx["callsigns"].(float64) != 2 {
+		t.Fatalf("tx_today = %v", tx)
+	}
+	top := sum["top_talkers"].([]any)
+	if len(top) != 3 || top[0].(map[string]any)["callsign"] != "W1AW" {
+		t.Fatalf("top_talkers = %v", top)
+	}
+	if ami := sum["ami"].(map[string]any); ami["enabled"] != false || ami["connected"] != false {
+		t.Fatalf("ami = %v", ami)
+	}
+	if u := sum["unavailable"].([]any); len(u) != 0 {
+		t.Fatalf("unavailable = %v", u)
+	}
+
+	// Anonymous viewers get no callsign-bearing sections under a restrictive policy
+	anon := get("")
+	if anon["last_talker"] != nil {
+		t.Fatalf("anonymous last_talker = %v", anon["last_talker"])
+	}
+	if _, ok := anon["top_talkers"]; ok {
+		t.Fatalf("anonymous top_talkers = %v", anon["top_talkers"])
+	}
+	if anon["tx_today"] == nil || anon["node"] == nil {
+		t.Fatalf("anonymous summary missing public sections: %v", anon)
+	}
+}
@@ -0,0 +1,110 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/dbehnke/allstar-nexus/backend/api"
+	"github.com/dbehnke/allstar-nexus/backend/models"
+	"github.com/dbehnke/allstar-nexus/backend/repository"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func seedCallsignData(t *testing.T) *gorm.DB {
+	t.Helper()
+	gdb, err := gorm.Open(sqlite.New(sqlite.Config{DriverName: "sqlite", DSN: filepath.Join(t.TempDir(), "test.db")}), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open gorm sqlite: %v", err)
+	}
+	if err := gdb.AutoMigrate(&models.User{}, &models.TransmissionLog{}, &models.XPActivityLog{}, &models.CallsignProfile{}, &models.LevelHistory{}, &models.TallyWindowMark{}, &models.LinkStat{}, &models.TextNode{}); err != nil {
+		t.Fatalf("automigrate: %v", err)
+	}
+	now := time.Now()
+	for _, cs := range []string{"W1AW", "W1AW", "K8XYZ"} {
+		gdb.Create(&models.TransmissionLog{SourceID: 1999, AdjacentLinkID: 2000, Callsign: cs, TimestampStart: now, TimestampEnd: now, DurationSeconds: 10})
+		gdb.Create(&models.XPActivityLog{Callsign: cs, HourBucket: now.Truncate(time.Hour), RawXP: 10, AwardedXP: 10})
+	}
+	gdb.Create(&models.CallsignProfile{Callsign: "W1AW"})
+	gdb.Create(&models.CallsignProfile{Callsign: "K8XYZ"})
+	gdb.Create(&models.LinkStat{Node: -12345, NodeCallsign: "K8XYZ", NodeDescription: "VOIP Client", IP: "192.0.2.10"})
+	gdb.Create(&models.TextNode{ID: -12345, Name: "K8XYZ", Hash: -12345})
+	return gdb
+}
+
+func postDeletion(t *testing.T, apiLayer *api.API, body string) (int, repository.PurgeReport) {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	apiLayer.CallsignDataDeletion(rec, httptest.NewRequest(http.MethodPost, "/api/admin/data-deletion", bytes.NewBufferString(body)))
+	var env envelope
+	_ = json.Unmarshal(rec.Body.Bytes(), &env)
+	var out struct {
+		Report repository.PurgeReport `json:"report"`
+	}
+	_ = json.Unmarshal(env.Data, &out)
+	return rec.Code, out.Report
+}
+
+func countCallsign(gdb *gorm.DB, model any, cs string) int64 {
+	var n int64
+	gdb.Model(model).Where("callsign = ?", cs).Count(&n)
+	return n
+}
+
+func TestCallsignDataDeletion(t *testing.T) {
+	gdb := seedCallsignData(t)
+	apiLayer := api.New(gdb, "test-secret", time.Hour)
+	apiLayer.SetCallsignData(repository.NewCallsignDataRepo(gdb))
+
+	// Dry run reports without modifying anything (callsign match is case-insensitive)
+	code, rep := postDeletion(t, apiLayer, `{"callsign":"w1aw","dry_run":true}`)
+	if code != 200 || rep.TransmissionLogs != 2 || rep.XPActivityLogs != 2 || rep.Profiles != 1 || len(rep.Nodes) != 1 || rep.Nodes[0] != 2000 {
+		t.Fatalf("dry run report wrong: code=%d %+v", code, rep)
+	}
+	if countCallsign(gdb, &models.TransmissionLog{}, "W1AW") != 2 {
+		t.Fatal("dry run modified data")
+	}
+
+	code, rep = postDeletion(t, apiLayer, `{"callsign":"W1AW","mode":"delete"}`)
+	if code != 200 || rep.TransmissionLogs != 2 {
+		t.Fatalf("delete failed: code=%d %+v", code, rep)
+	}
+	for _, m := range []any{&models.TransmissionLog{}, &models.XPActivityLog{}, &models.CallsignProfile{}} {
+		if n := countCallsign(gdb, m, "W1AW"); n != 0 {
+			t.Fatalf("%T still has %d rows for W1AW", m, n)
+		}
+	}
+	if countCallsign(gdb, &models.TransmissionLog{}, "K8XYZ") != 1 {
+		t.Fatal("other callsigns must be untouched")
+	}
+
+	code, rep = postDeletion(t, apiLayer, `{"callsign":"K8XYZ","mode":"anonymize"}`)
+	if code != 200 || rep.Pseudonym == "" {
+		t.Fatalf("anonymize failed: code=%d %+v", code, rep)
+	}
+	if countCallsign(gdb, &models.TransmissionLog{}, "K8XYZ") != 0 || countCallsign(gdb, &models.TransmissionLog{}, rep.Pseudonym) != 1 {
+		t.Fatal("transmission log not anonymized")
+	}
+	if countCallsign(gdb, &models.CallsignProfile{}, rep.Pseudonym) != 1 {
+		t.Fatal("profile not anonymized")
+	}
+	var link models.LinkStat
+	gdb.First(&link, "node = ?", -12345)
+	if rep.LinkStats != 1 || link.NodeCallsign != rep.Pseudonym || link.IP != "" {
+		t.Fatalf("persisted link label not anonymized: %+v", link)
+	}
+	var textNodes int64
+	gdb.Model(&models.TextNode{}).Count(&textNodes)
+	if rep.TextNodes != 1 || textNodes != 0 {
+		t.Fatalf("text node mapping not removed: report=%d remaining=%d", rep.TextNodes, textNodes)
+	}
+
+	if code, _ := postDeletion(t, apiLayer, `{"callsign":"K8XYZ","mode":"shred"}`); code != 400 {
+		t.Fatalf("invalid mode should be rejected, got %d", code)
+	}
+}
@@ -0,0 +1,73 @@
+package tests
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/dbehnke/allstar-nexus/backend/gamification"
+	"github.com/dbehnke/allstar-nexus/backend/models"
+	"github.com/dbehnke/allstar-nexus/internal/discordbot"
+)
+
+func TestDiscordBotScoreboardAndProfile(t *testing.T) {
+	_, levelRepo, profileRepo, txRepo, _ := setupDBForProfileTest(t)
+	ctx := context.Background()
+	for _, p := range []models.CallsignProfile{
+		{Callsign: "W1AW", Level: 12, ExperiencePoints: 500, RenownLevel: 1},
+		{Callsign: "K8ABC", Level: 30, ExperiencePoints: 100},
+		{Callsign: "N0HIDE", Level: 50},
+	} {
+		if err := profileRepo.Upsert(ctx, &p); err != nil {
+			t.Fatalf("profile: %v", err)
+		}
+	}
+	optOuts := gamification.NewOptOuts(gamification.OptOutHide)
+	optOuts.Set([]string{"N0HIDE"})
+
+	pub, priv, _ := ed25519.GenerateKey(nil)
+	bot, err := discordbot.New("123", hex.EncodeToString(pub), "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	bot.RegisterDefaults(discordbot.Sources{Profiles: profileRepo, Levels: levelRepo, TxLogs: txRepo, OptOuts: optOuts})
+
+	command := func(body string) string {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodPost, "/api/discord/interactions", strings.NewReader(body))
+		req.Header.Set("X-Signature-Ed25519", hex.EncodeToString(ed25519.Sign(priv, []byte("1"+body))))
+		req.Header.Set("X-Signature-Timestamp", "1")
+		rec := httptest.NewRecorder()
+		bot.ServeHTTP(rec, req)
+		var resp struct {
+			Data struct {
+				Content string `json:"content"`
+			} `json:"data"`
+		}
+		_ = json.Unmarshal(rec.Body.Bytes(), &resp)
+		return resp.Data.Content
+	}
+
+	board := command(`{"type":2,"data":{"name":"scoreboard"}}`)
+	if strings.Contains(board, "N0HIDE") || strings.Index(board, "W1AW") > strings.Index(board, "K8ABC") {
+		// renown ranks above level
+		t.Fatalf("scoreboard: %q", board)
+	}
+	if got := command(`{"type":2,"data":{"name":"profile","options":[{"name":"callsign","value":"w1aw"}]}}`); !strings.Contains(got, "**W1AW** — level 12") || !strings.Contains(got, "Renown 1") {
+		t.Fatalf("profile: %q", got)
+	}
+	if got := command(`{"type":2,"data":{"name":"profile","options":[{"name":"callsign","value":"N0HIDE"}]}}`); got != "No profile for N0HIDE." {
+		t.Fatalf("opted-out profile: %q", got)
+	}
+	if got := command(`{"type":2,"data":{"name":"profile","options":[{"name":"callsign","value":"NEW1"}]}}`); got != "No profile for NEW1." {
+		t.Fatalf("unknown profile: %q", got)
+	}
+	if p, _ := profileRepo.Find(ctx, "NEW1"); p != nil {
+		t.Fatal("profile lookup must not create a profile")
+	}
+}
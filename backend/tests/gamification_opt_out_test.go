@@ -0,0 +1,137 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/dbehnke/allstar-nexus/backend/api"
+	"github.com/dbehnke/allstar-nexus/backend/auth"
+	"github.com/dbehnke/allstar-nexus/backend/config"
+	"github.com/dbehnke/allstar-nexus/backend/gamification"
+	"github.com/dbehnke/allstar-nexus/backend/models"
+	"github.com/dbehnke/allstar-nexus/backend/repository"
+)
+
+func TestGamificationOptOut(t *testing.T) {
+	gdb, levelRepo, profileRepo, txRepo, activityRepo := setupDBForProfileTest(t)
+	if err := gdb.AutoMigrate(&models.User{}, &models.GamificationOptOut{}); err != nil {
+		t.Fatalf("automigrate: %v", err)
+	}
+	ctx := context.Background()
+	for i, cs := range []string{"W1AW", "K8ABC", "N0CALL"} {
+		if err := profileRepo.Upsert(ctx, &models.CallsignProfile{Callsign: cs, Level: 10 - i}); err != nil {
+			t.Fatalf("profile: %v", err)
+		}
+	}
+
+	apiLayer := api.New(gdb, "test-secret", time.Hour)
+	tokens := map[string]string{}
+	for email, role := range map[string]string{"owner@example.com": models.RoleUser, "other@example.com": models.RoleUser, "admin@example.com": models.RoleAdmin} {
+		hash, _ := auth.HashPassword("password123")
+		if _, err := apiLayer.Users.Create(ctx, email, hash, role); err != nil {
+			t.Fatalf("create user: %v", err)
+		}
+		tokens[email], _ = auth.GenerateJWT(email, role, time.Hour, "test-secret")
+	}
+	optOuts := gamification.NewOptOuts(gamification.OptOutHide)
+	changes := 0
+	apiLayer.SetGamificationOptOuts(repository.NewGamificationOptOutRepo(gdb), optOuts, func() { changes++ })
+
+	gapi := api.NewGamificationAPI(profileRepo, txRepo, levelRepo, activityRepo, gamification.DefaultLevelGroupings(), true, 36000, false, 0, 0, 1.0, 300, 7200, 1200, []config.DRTier{})
+	gapi.SetOptOuts(optOuts)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/gamification/opt-out", apiLayer.GamificationOptOut)
+	mux.HandleFunc("/api/admin/gamification/opt-outs", apiLayer.AdminGamificationOptOuts)
+	mux.HandleFunc("/api/gamification/scoreboard", gapi.Scoreboard)
+	mux.HandleFunc("/api/gamification/profile/", gapi.Profile)
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	do := func(user, method, path, body string) int {
+		t.Helper()
+		req, _ := http.NewRequest(method, srv.URL+path, bytes.NewBufferString(body))
+		if user != "" {
+			req.Header.Set("Authorization", "Bearer "+tokens[user])
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("%s %s: %v", method, path, err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+	scoreboard := func() []string {
+		t.Helper()
+		resp, err := http.Get(srv.URL + "/api/gamification/scoreboard")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		var out struct {
+			Scoreboard []struct {
+				Callsign string `json:"callsign"`
+			} `json:"scoreboard"`
+		}
+		_ = json.NewDecoder(resp.Body).Decode(&out)
+		var cs []string
+		for _, e := range out.Scoreboard {
+			cs = append(cs, e.Callsign)
+		}
+		return cs
+	}
+
+	if code := do("", http.MethodPost, "/api/gamification/opt-out", `{"callsign":"w1aw"}`); code != 401 {
+		t.Fatalf("anonymous opt-out: code=%d", code)
+	}
+	if code := do("owner@example.com", http.MethodPost, "/api/gamification/opt-out", `{"callsign":" w1aw "}`); code != 200 {
+		t.Fatalf("self opt-out: code=%d", code)
+	}
+	if !optOuts.Has("W1AW") || optOuts.Excluded("W1AW") || changes != 2 {
+		t.Fatalf("opt-out set: has=%v excluded=%v changes=%d", optOuts.Has("W1AW"), optOuts.Excluded("W1AW"), changes)
+	}
+	if cs := scoreboard(); len(cs) != 2 || cs[0] != "K8ABC" {
+		t.Fatalf("scoreboard = %v", cs)
+	}
+	if code := do("", http.MethodGet, "/api/gamification/profile/W1AW", ""); code != 404 {
+		t.Fatalf("opted-out profile: code=%d", code)
+	}
+
+	// Only the requester (or an admin) may opt back in
+	if code := do("other@example.com", http.MethodDelete, "/api/gamification/opt-out?callsign=W1AW", ""); code != 403 {
+		t.Fatalf("other user opt-in: code=%d", code)
+	}
+	if code := do("owner@example.com", http.MethodDelete, "/api/gamification/opt-out?callsign=W1AW", ""); code != 200 {
+		t.Fatalf("owner opt-in: code=%d", code)
+	}
+	if cs := scoreboard(); len(cs) != 3 {
+		t.Fatalf("scoreboard after opt-in = %v", cs)
+	}
+
+	// Admin opt-outs cannot be undone through self-service
+	if code := do("admin@example.com", http.MethodPost, "/api/admin/gamification/opt-outs", `{"callsign":"K8ABC"}`); code != 200 {
+		t.Fatalf("admin opt-out: code=%d", code)
+	}
+	if code := do("owner@example.com", http.MethodPost, "/api/gamification/opt-out", `{"callsign":"K8ABC"}`); code != 200 {
+		t.Fatalf("repeat opt-out: code=%d", code)
+	}
+	if code := do("owner@example.com", http.MethodDelete, "/api/gamification/opt-out?callsign=K8ABC", ""); code != 403 {
+		t.Fatalf("self opt-in of admin opt-out: code=%d", code)
+	}
+	if code := do("admin@example.com", http.MethodDelete, "/api/admin/gamification/opt-outs?callsign=K8ABC", ""); code != 200 {
+		t.Fatalf("admin opt-in: code=%d", code)
+	}
+	if code := do("admin@example.com", http.MethodDelete, "/api/admin/gamification/opt-outs?callsign=K8ABC", ""); code != 404 {
+		t.Fatalf("admin opt-in again: code=%d", code)
+	}
+
+	exclude := gamification.NewOptOuts(gamification.OptOutExclude)
+	exclude.Set([]string{"n0call"})
+	if !exclude.Excluded("N0CALL") || exclude.Mode() != gamification.OptOutExclude {
+		t.Fatal("exclude mode should report opted-out callsigns as excluded")
+	}
+}
@@ -0,0 +1,74 @@
+package harness
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/dbehnke/allstar-nexus/internal/ami"
+)
+
+// Frame is one line of a recorded AMI capture: a frame as the connector would
+// deliver it, sent DelayMS after the previous one. Lines starting with # are comments.
+//
+//	{"delay_ms": 100, "type": "EVENT", "headers": {"Event": "RPT_ALINKS", "Node": "1999", "EventValue": "1,2001TK"}}
+type Frame struct {
+	DelayMS int               `json:"delay_ms"`
+	Type    ami.MessageType   `json:"type"`
+	Headers map[string]string `json:"headers"`
+}
+
+// Message converts the frame to the connector's representation.
+func (f Frame) Message() ami.Message {
+	headers := make(map[string]string, len(f.Headers))
+	for k, v := range f.Headers {
+		headers[k] = v
+	}
+	typ := f.Type
+	if typ == "" {
+		typ = ami.MessageTypeEvent
+	}
+	return ami.Message{Type: typ, Headers: headers}
+}
+
+// Delay is the pause before the frame is delivered.
+func (f Frame) Delay() time.Duration { return time.Duration(f.DelayMS) * time.Millisecond }
+
+// LoadCapture reads a JSONL capture file.
+func LoadCapture(path string) ([]Frame, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+	frames, err := ParseCapture(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return frames, nil
+}
+
+// ParseCapture reads JSONL frames from r, skipping blank and comment lines.
+func ParseCapture(r io.Reader) ([]Frame, error) {
+	var frames []Frame
+	sc := bufio.NewScanner(r)
+	for n := 1; sc.Scan(); n++ {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		var fr Frame
+		if err := json.Unmarshal([]byte(line), &fr); err != nil {
+			return nil, fmt.Errorf("line %d: %w", n, err)
+		}
+		if len(fr.Headers) == 0 {
+			return nil, fmt.Errorf("line %d: frame has no headers", n)
+		}
+		frames = append(frames, fr)
+	}
+	return frames, sc.Err()
+}
@@ -0,0 +1,205 @@
+// Package harness boots the dashboard's real components in-process — database,
+// StateManager, WebSocket hub, HTTP API and tally — and drives them with recorded AMI
+// captures, so end-to-end scenario tests can assert on what a dashboard user would see.
+package harness
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/dbehnke/allstar-nexus/backend/api"
+	"github.com/dbehnke/allstar-nexus/backend/database"
+	"github.com/dbehnke/allstar-nexus/backend/gamification"
+	"github.com/dbehnke/allstar-nexus/backend/models"
+	"github.com/dbehnke/allstar-nexus/backend/repository"
+	"github.com/dbehnke/allstar-nexus/internal/ami"
+	"github.com/dbehnke/allstar-nexus/internal/core"
+	"github.com/dbehnke/allstar-nexus/internal/web"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// DefaultUnkeyDelay is the keying tracker's jitter delay in the harness. Production
+// uses 2s; a short delay keeps scenarios fast while exercising the same code path.
+const DefaultUnkeyDelay = 200 * time.Millisecond
+
+// Options configures a Harness.
+type Options struct {
+	SourceNodes  []int           // local nodes with keying trackers; default 1999
+	Nodes        []core.NodeInfo // private node registry used to resolve callsigns
+	UnkeyDelay   time.Duration   // keying tracker jitter delay; default DefaultUnkeyDelay
+	Gamification *gamification.Config
+}
+
+// Harness is a running dashboard stack fed from a fake AMI connection.
+type Harness struct {
+	DB       *gorm.DB
+	State    *core.StateManager
+	Hub      *web.Hub
+	API      *api.API
+	Server   *httptest.Server
+	TxLogs   *repository.TransmissionLogRepository
+	Profiles *repository.CallsignProfileRepo
+	Tally    *gamification.TallyService
+
+	feed chan ami.Message
+}
+
+// New starts a harness that is torn down when the test ends.
+func New(t testing.TB, opts Options) *Harness {
+	t.Helper()
+	if len(opts.SourceNodes) == 0 {
+		opts.SourceNodes = []int{1999}
+	}
+	if opts.UnkeyDelay <= 0 {
+		opts.UnkeyDelay = DefaultUnkeyDelay
+	}
+	if opts.Gamification == nil {
+		opts.Gamification = &gamification.Config{}
+	}
+	ctx := context.Background()
+
+	dbOpts := database.DefaultOptions()
+	dbOpts.Gorm = &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)}
+	db, err := database.OpenWithOptions(filepath.Join(t.TempDir(), "harness.db"), dbOpts)
+	if err != nil {
+		t.Fatalf("open database: %v", err)
+	}
+	t.Cleanup(func() { _ = db.CloseSafe() })
+	migrator, err := database.NewMigrator(db.DB, database.Migrations(database.MigrationOptions{PrimaryNode: opts.SourceNodes[0]}))
+	if err != nil {
+		t.Fatalf("migrator: %v", err)
+	}
+	if _, err := migrator.Up(ctx); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+
+	h := &Harness{
+		DB:       db.DB,
+		TxLogs:   repository.NewTransmissionLogRepository(db.DB),
+		Profiles: repository.NewCallsignProfileRepo(db.DB),
+		feed:     make(chan ami.Message, 64),
+	}
+	levels := repository.NewLevelConfigRepo(db.DB)
+	if err := levels.SeedDefaults(ctx, gamification.CalculateLevelRequirements()); err != nil {
+		t.Fatalf("seed levels: %v", err)
+	}
+	activity := repository.NewXPActivityRepo(db.DB)
+
+	lookup := core.NewNodeLookupService("")
+	lookup.SetLocalNodes(opts.Nodes)
+	h.State = core.NewStateManager()
+	h.State.SetNodeLookup(lookup)
+	h.State.SetTransmissionLogRepo(h.TxLogs)
+	h.State.SetNodeID(opts.SourceNodes[0])
+	for _, node := range opts.SourceNodes {
+		h.State.AddSourceNode(node, int(opts.UnkeyDelay.Milliseconds()))
+	}
+
+	h.Hub = web.NewHub()
+	h.Hub.SetState(h.State)
+	go h.Hub.BroadcastLoop(h.State.Updates())
+	go h.Hub.TalkerLoop(h.State.TalkerEvents())
+	go h.Hub.LinkUpdateLoop(h.State.LinkUpdates())
+	go h.Hub.LinkRemovalLoop(h.State.LinkRemovals())
+	go h.Hub.LinkTxBatchLoop(h.State.LinkTxEvents(), 100*time.Millisecond)
+	go h.Hub.SourceNodeKeyingLoop(h.State.KeyingUpdates())
+	go h.Hub.SourceNodeKeyingEventLoop(h.State.KeyingEvents())
+	go h.State.Run(h.feed)
+	t.Cleanup(func() { close(h.feed) })
+
+	h.Tally = gamification.NewTallyService(db.DB, h.TxLogs, h.Profiles, levels, activity,
+		repository.NewTallyStateRepo(db.DB), opts.Gamification, time.Hour, zap.NewNop())
+	if err := h.Tally.Start(); err != nil {
+		t.Fatalf("start tally: %v", err)
+	}
+	t.Cleanup(h.Tally.Stop)
+
+	h.API = api.New(db.DB, "harness-secret", time.Hour)
+	h.API.SetStateManager(h.State)
+	h.API.SetDashboardSources(h.TxLogs, h.Profiles)
+	games := api.NewGamificationAPI(h.Profiles, h.TxLogs, levels, activity,
+		gamification.DefaultLevelGroupings(), false, 0, false, 0, 0, 0, 0, 0, 0, nil)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/ws", h.Hub.HandleWS(h.State, nil))
+	mux.HandleFunc("/api/talker-log", h.API.TalkerLog)
+	mux.HandleFunc("/api/dashboard/summary", h.API.DashboardSummary)
+	mux.HandleFunc("/api/gamification/scoreboard", games.Scoreboard)
+	mux.HandleFunc("/api/gamification/profile/", games.Profile)
+	h.Server = httptest.NewServer(mux)
+	t.Cleanup(h.Server.Close)
+	return h
+}
+
+// Send delivers frames to the StateManager as the AMI connector would, honoring each
+// frame's delay.
+func (h *Harness) Send(frames ...Frame) {
+	for _, f := range frames {
+		if d := f.Delay(); d > 0 {
+			time.Sleep(d)
+		}
+		h.feed <- f.Message()
+	}
+}
+
+// Replay loads a JSONL capture and sends it.
+func (h *Harness) Replay(t testing.TB, path string) {
+	t.Helper()
+	frames, err := LoadCapture(path)
+	if err != nil {
+		t.Fatalf("load capture: %v", err)
+	}
+	h.Send(frames...)
+}
+
+// WaitForTransmissions waits until at least n transmission log rows are persisted and
+// returns them oldest first. Rows are written asynchronously after a confirmed unkey.
+func (h *Harness) WaitForTransmissions(t testing.TB, n int, timeout time.Duration) []models.TransmissionLog {
+	t.Helper()
+	deadline := time.Now().Add(timeout)
+	for {
+		var rows []models.TransmissionLog
+		if err := h.DB.Order("timestamp_start ASC, id ASC").Find(&rows).Error; err != nil {
+			t.Fatalf("read transmissions: %v", err)
+		}
+		if len(rows) >= n || time.Now().After(deadline) {
+			if len(rows) < n {
+				t.Fatalf("got %d transmission rows within %s, want %d: %+v", len(rows), timeout, n, rows)
+			}
+			return rows
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+// RunTally tallies everything logged so far and returns the summary.
+func (h *Harness) RunTally(t testing.TB) gamification.TallySummary {
+	t.Helper()
+	summary, err := h.Tally.RunNow()
+	if err != nil {
+		t.Fatalf("tally: %v", err)
+	}
+	return summary
+}
+
+// Get performs a GET against the harness server and returns the status and body.
+func (h *Harness) Get(t testing.TB, path string) (int, []byte) {
+	t.Helper()
+	resp, err := http.Get(h.Server.URL + path)
+	if err != nil {
+		t.Fatalf("GET %s: %v", path, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("read %s: %v", path, err)
+	}
+	return resp.StatusCode, body
+}
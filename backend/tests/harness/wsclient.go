@@ -0,0 +1,95 @@
+package harness
+
+import (
+	"encoding/json"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	gws "github.com/gorilla/websocket"
+)
+
+// Envelope is a decoded dashboard WebSocket message.
+type Envelope struct {
+	MessageType string          `json:"messageType"`
+	Data        json.RawMessage `json:"data"`
+	Timestamp   int64           `json:"timestamp"`
+}
+
+// WSClient is a dashboard WebSocket connection that records every message it receives.
+type WSClient struct {
+	conn *gws.Conn
+
+	mu       sync.Mutex
+	messages []Envelope
+	notify   chan struct{}
+}
+
+// DialWS connects to the harness server's /ws endpoint. The connection is closed when
+// the test ends.
+func (h *Harness) DialWS(t testing.TB) *WSClient {
+	t.Helper()
+	url := "ws" + strings.TrimPrefix(h.Server.URL, "http") + "/ws"
+	conn, _, err := gws.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		t.Fatalf("dial %s: %v", url, err)
+	}
+	c := &WSClient{conn: conn, notify: make(chan struct{}, 1)}
+	go c.readLoop()
+	t.Cleanup(func() { _ = conn.Close() })
+	return c
+}
+
+func (c *WSClient) readLoop() {
+	for {
+		_, b, err := c.conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		var env Envelope
+		if json.Unmarshal(b, &env) != nil {
+			continue
+		}
+		c.mu.Lock()
+		c.messages = append(c.messages, env)
+		c.mu.Unlock()
+		select {
+		case c.notify <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// Messages returns the messages of type messageType received so far, oldest first.
+func (c *WSClient) Messages(messageType string) []Envelope {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var out []Envelope
+	for _, m := range c.messages {
+		if m.MessageType == messageType {
+			out = append(out, m)
+		}
+	}
+	return out
+}
+
+// WaitFor returns the first message of type messageType for which match (nil matches
+// anything) returns true, failing the test if none arrives within timeout.
+func (c *WSClient) WaitFor(t testing.TB, messageType string, timeout time.Duration, match func(Envelope) bool) Envelope {
+	t.Helper()
+	deadline := time.After(timeout)
+	for {
+		for _, m := range c.Messages(messageType) {
+			if match == nil || match(m) {
+				return m
+			}
+		}
+		select {
+		case <-c.notify:
+		case <-deadline:
+			t.Fatalf("no matching %s message within %s", messageType, timeout)
+			return Envelope{}
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/dbehnke/allstar-nexus/backend/api"
+	"github.com/dbehnke/allstar-nexus/backend/config"
+	"github.com/dbehnke/allstar-nexus/backend/gamification"
+	"github.com/dbehnke/allstar-nexus/backend/models"
+	"github.com/dbehnke/allstar-nexus/backend/repository"
+)
+
+func TestLevelHistoryRecordedAndServed(t *testing.T) {
+	gdb, levelRepo, profileRepo, txRepo, activityRepo := setupDBForProfileTest(t)
+	if err := gdb.AutoMigrate(&models.LevelHistory{}); err != nil {
+		t.Fatalf("automigrate: %v", err)
+	}
+	ctx := context.Background()
+	if err := levelRepo.SeedDefaults(ctx, gamification.CalculateLevelRequirements()); err != nil {
+		t.Fatalf("seed level config: %v", err)
+	}
+
+	// 350 XP + 400s of talk crosses level 2 (360 XP) and level 3
+	callsign := "W1HIST"
+	prof, _ := profileRepo.GetByCallsign(ctx, callsign)
+	prof.ExperiencePoints = 350
+	if err := profileRepo.Upsert(ctx, prof); err != nil {
+		t.Fatalf("upsert profile: %v", err)
+	}
+	start := time.Now().Add(-10 * time.Minute)
+	if err := txRepo.LogTransmission(100, 200, callsign, start, start.Add(400*time.Second), 400); err != nil {
+		t.Fatalf("seed tx: %v", err)
+	}
+
+	historyRepo := repository.NewLevelHistoryRepo(gdb)
+	cfg := &gamification.Config{}
+	ts := gamification.NewTallyService(gdb, txRepo, profileRepo, levelRepo, activityRepo, repository.NewTallyStateRepo(gdb), cfg, 30*time.Minute, zaptestLogger())
+	ts.SetLevelHistory(historyRepo)
+	if err := ts.Start(); err != nil {
+		t.Fatalf("start tally: %v", err)
+	}
+	defer ts.Stop()
+
+	prof, _ = profileRepo.GetByCallsign(ctx, callsign)
+	levels, err := historyRepo.ForCallsign(ctx, callsign, time.Time{}, 0)
+	if err != nil || len(levels) != prof.Level-1 || len(levels) < 2 {
+		t.Fatalf("history = %+v err=%v (profile level %d)", levels, err, prof.Level)
+	}
+	if levels[0].Level != 2 || levels[0].Kind != models.LevelHistoryLevel || !levels[0].ReachedAt.Equal(levels[1].ReachedAt) {
+		t.Fatalf("first entry = %+v", levels[0])
+	}
+	if last := levels[len(levels)-1]; last.Level != prof.Level || last.CarryoverXP != prof.ExperiencePoints {
+		t.Fatalf("last entry %+v does not match profile %+v", last, prof)
+	}
+
+	gapi := api.NewGamificationAPI(profileRepo, txRepo, levelRepo, activityRepo, gamification.DefaultLevelGroupings(), true, 36000, false, 0, 0, 1.0, 300, 7200, 1200, []config.DRTier{})
+	gapi.SetLevelHistory(historyRepo)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/gamification/profile/", gapi.Profile)
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	resp, err := http.Get(srv.URL + "/api/gamification/profile/w1hist/history?days=7")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	var out struct {
+		Callsign string                `json:"callsign"`
+		Days     int                   `json:"days"`
+		Levels   []models.LevelHistory `json:"levels"`
+		XP       []struct {
+			AwardedXP    int `json:"awarded_xp"`
+			CumulativeXP int `json:"cumulative_xp"`
+		} `json:"xp"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil || resp.StatusCode != 200 {
+		t.Fatalf("status=%d err=%v", resp.StatusCode, err)
+	}
+	if out.Callsign != callsign || out.Days != 7 || len(out.Levels) != len(levels) {
+		t.Fatalf("history response = %+v", out)
+	}
+	if len(out.XP) == 0 || out.XP[len(out.XP)-1].CumulativeXP != 400 {
+		t.Fatalf("xp series = %+v", out.XP)
+	}
+}
@@ -255,6 +255,7 @@ func setupLevelingDB(t *testing.T) *gorm.DB {
 		&models.TransmissionLog{},
 		&models.XPActivityLog{},
 		&models.TallyState{},
+		&models.TallyWindowMark{},
 	); err != nil {
 		t.Fatalf("automigrate: %v", err)
 	}
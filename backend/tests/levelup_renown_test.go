@@ -32,6 +32,7 @@ func setupDB(t *testing.T) *gorm.DB {
 		&models.TransmissionLog{},
 		&models.XPActivityLog{},
 		&models.TallyState{},
+		&models.TallyWindowMark{},
 	); err != nil {
 		t.Fatalf("automigrate: %v", err)
 	}
@@ -0,0 +1,82 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/dbehnke/allstar-nexus/backend/api"
+	"github.com/dbehnke/allstar-nexus/backend/models"
+	"github.com/dbehnke/allstar-nexus/backend/repository"
+	"github.com/dbehnke/allstar-nexus/internal/privacy"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestLinkQualityRepoAndEndpoint(t *testing.T) {
+	gdb, err := gorm.Open(sqlite.New(sqlite.Config{DriverName: "sqlite", DSN: filepath.Join(t.TempDir(), "test.db")}), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open gorm sqlite: %v", err)
+	}
+	if err := gdb.AutoMigrate(&models.LinkQuality{}); err != nil {
+		t.Fatalf("automigrate: %v", err)
+	}
+	repo := repository.NewLinkQualityRepo(gdb)
+	ctx := context.Background()
+	now := time.Now()
+	rows := []models.LinkQuality{
+		{LocalNode: 1999, Node: 2001, CheckedAt: now.Add(-2 * time.Minute), Peer: "192.0.2.1", RTTMs: 30, RxJitterMs: 20, RxKpkts: 200},
+		{LocalNode: 1999, Node: 2001, CheckedAt: now.Add(-time.Minute), Peer: "192.0.2.1", RTTMs: 32, RxJitterMs: 20, RxKpkts: 215},
+		{LocalNode: 1999, Node: 2002, CheckedAt: now.Add(-time.Minute), Peer: "198.51.100.7", RTTMs: 148, RxJitterMs: 95, RxLostPct: 3, RxKpkts: 12},
+		{LocalNode: 1999, Node: 2002, CheckedAt: now.Add(-48 * time.Hour), Peer: "198.51.100.7", RxLostPct: 50},
+	}
+	if err := repo.Record(ctx, rows); err != nil {
+		t.Fatalf("record: %v", err)
+	}
+
+	latest, err := repo.Latest(ctx, now.Add(-time.Hour))
+	if err != nil {
+		t.Fatalf("latest: %v", err)
+	}
+	if len(latest) != 2 || latest[0].Node != 2002 || latest[1].RxKpkts != 215 {
+		t.Fatalf("latest = %+v", latest)
+	}
+
+	apiLayer := &api.API{Privacy: privacy.DefaultPolicy()}
+	rec := httptest.NewRecorder()
+	apiLayer.LinkQualityHandler(rec, httptest.NewRequest(http.MethodGet, "/api/link-quality", nil))
+	if rec.Code != 503 {
+		t.Fatalf("disabled status = %d", rec.Code)
+	}
+	apiLayer.SetLinkQuality(repo)
+
+	rec = httptest.NewRecorder()
+	apiLayer.LinkQualityHandler(rec, httptest.NewRequest(http.MethodGet, "/api/link-quality?node=2001", nil))
+	var env envelope
+	_ = json.Unmarshal(rec.Body.Bytes(), &env)
+	var hist struct {
+		Node    int                  `json:"node"`
+		Samples []models.LinkQuality `json:"samples"`
+	}
+	_ = json.Unmarshal(env.Data, &hist)
+	if hist.Node != 2001 || len(hist.Samples) != 2 || hist.Samples[0].RxKpkts != 200 {
+		t.Fatalf("history = %s", rec.Body.String())
+	}
+	if peer := hist.Samples[0].Peer; peer != "192.0.*.*" {
+		t.Fatalf("anonymous viewer saw peer %q", peer)
+	}
+
+	rec = httptest.NewRecorder()
+	apiLayer.LinkQualityHandler(rec, httptest.NewRequest(http.MethodGet, "/api/link-quality?node=abc", nil))
+	if rec.Code != 400 {
+		t.Fatalf("bad node status = %d", rec.Code)
+	}
+
+	if n, err := repo.Prune(ctx, now.Add(-24*time.Hour)); err != nil || n != 1 {
+		t.Fatalf("prune = %d, %v", n, err)
+	}
+}
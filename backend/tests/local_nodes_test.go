@@ -0,0 +1,84 @@
+package tests
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/dbehnke/allstar-nexus/backend/api"
+	"github.com/dbehnke/allstar-nexus/backend/models"
+	"github.com/dbehnke/allstar-nexus/backend/repository"
+	"github.com/dbehnke/allstar-nexus/internal/core"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestLocalNodeRegistry(t *testing.T) {
+	gdb, err := gorm.Open(sqlite.New(sqlite.Config{DriverName: "sqlite", DSN: filepath.Join(t.TempDir(), "test.db")}), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open gorm sqlite: %v", err)
+	}
+	if err := gdb.AutoMigrate(&models.User{}, &models.LocalNode{}); err != nil {
+		t.Fatalf("automigrate: %v", err)
+	}
+	repo := repository.NewLocalNodeRepo(gdb)
+	ctx := context.Background()
+
+	// Config seed
+	if n, err := repo.Seed(ctx, []models.LocalNode{{Node: 1000, Callsign: "K8FBI", Description: "Club hub"}, {Node: 1001, Callsign: "K8FBI", Description: "Repeater"}}); err != nil || n != 2 {
+		t.Fatalf("seed: n=%d err=%v", n, err)
+	}
+
+	lookup := core.NewNodeLookupService("")
+	apiLayer := api.New(gdb, "test-secret", time.Hour)
+	apiLayer.SetLocalNodes(repo, lookup)
+	srv := httptest.NewServer(http.HandlerFunc(apiLayer.AdminLocalNodes))
+	t.Cleanup(srv.Close)
+
+	if info := lookup.LookupNode(1000); info == nil || info.Description != "Club hub" {
+		t.Fatalf("seeded node not looked up: %+v", info)
+	}
+
+	// Admin edit wins over later config seeds
+	if code := apiRequest(t, http.MethodPost, srv.URL, `{"node":1001,"callsign":"w8abc","description":"Portable"}`, nil); code != 200 {
+		t.Fatalf("update: code=%d", code)
+	}
+	if info := lookup.LookupNode(1001); info == nil || info.Callsign != "W8ABC" {
+		t.Fatalf("admin edit not applied: %+v", info)
+	}
+	if n, err := repo.Seed(ctx, []models.LocalNode{{Node: 1000, Callsign: "K8FBI", Description: "Club hub (new)"}, {Node: 1001, Callsign: "K8FBI", Description: "Repeater"}}); err != nil || n != 1 {
+		t.Fatalf("reseed: n=%d err=%v", n, err)
+	}
+
+	var list struct {
+		Nodes []models.LocalNode `json:"nodes"`
+	}
+	if code := apiRequest(t, http.MethodGet, srv.URL, "", &list); code != 200 || len(list.Nodes) != 2 {
+		t.Fatalf("list: code=%d %+v", code, list)
+	}
+	if list.Nodes[0].Description != "Club hub (new)" || list.Nodes[1].Description != "Portable" || list.Nodes[1].Source != models.LocalNodeSourceAdmin {
+		t.Fatalf("unexpected rows: %+v", list.Nodes)
+	}
+
+	if code := apiRequest(t, http.MethodPost, srv.URL, `{"node":1002}`, nil); code != 400 {
+		t.Fatalf("empty entry: code=%d", code)
+	}
+	if got := lookup.SearchLocal("portable"); len(got) != 1 || got[0].Node != 1001 {
+		t.Fatalf("search: %+v", got)
+	}
+	if code := apiRequest(t, http.MethodDelete, srv.URL+"?node=1001", "", nil); code != 200 {
+		t.Fatalf("delete: code=%d", code)
+	}
+	if code := apiRequest(t, http.MethodDelete, srv.URL+"?node=1001", "", nil); code != 404 {
+		t.Fatalf("delete again: code=%d", code)
+	}
+	if _, ok := lookup.LocalNode(1001); ok {
+		t.Fatal("deleted node still in lookup")
+	}
+	if rec := apiLayer.LookupNodeByID(1000); rec == nil || rec.Callsign != "K8FBI" {
+		t.Fatalf("api lookup: %+v", rec)
+	}
+}
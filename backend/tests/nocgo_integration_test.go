@@ -52,6 +52,7 @@ func TestNoCGOIntegration(t *testing.T) {
 		&models.LevelConfig{},
 		&models.XPActivityLog{},
 		&models.TallyState{},
+		&models.TallyWindowMark{},
 	); err != nil {
 		t.Fatalf("auto-migrate failed: %v", err)
 	}
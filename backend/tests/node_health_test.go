@@ -0,0 +1,85 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/dbehnke/allstar-nexus/backend/api"
+	"github.com/dbehnke/allstar-nexus/backend/models"
+	"github.com/dbehnke/allstar-nexus/backend/repository"
+	"github.com/dbehnke/allstar-nexus/internal/privacy"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestNodeHealthRepoAndEndpoint(t *testing.T) {
+	gdb, err := gorm.Open(sqlite.New(sqlite.Config{DriverName: "sqlite", DSN: filepath.Join(t.TempDir(), "test.db")}), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open gorm sqlite: %v", err)
+	}
+	if err := gdb.AutoMigrate(&models.NodeHealth{}); err != nil {
+		t.Fatalf("automigrate: %v", err)
+	}
+	repo := repository.NewNodeHealthRepo(gdb)
+	ctx := context.Background()
+	now := time.Now()
+	rows := []models.NodeHealth{
+		{Node: 2001, CheckedAt: now.Add(-48 * time.Hour), IP: "192.0.2.1", Method: "iax2", Sent: 3, Received: 0},
+		{Node: 2001, CheckedAt: now.Add(-2 * time.Minute), IP: "192.0.2.1", Method: "iax2", Sent: 3, Received: 3, AvgRTTMs: 40, MaxRTTMs: 50},
+		{Node: 2001, CheckedAt: now.Add(-time.Minute), IP: "192.0.2.1", Method: "iax2", Sent: 3, Received: 1, AvgRTTMs: 80, MaxRTTMs: 80},
+		{Node: 2002, CheckedAt: now.Add(-time.Minute), IP: "192.0.2.2", Method: "iax2", Sent: 3, Received: 3, AvgRTTMs: 20, MaxRTTMs: 25},
+	}
+	if err := repo.Record(ctx, rows); err != nil {
+		t.Fatalf("record: %v", err)
+	}
+
+	sum, err := repo.Summary(ctx, now.Add(-time.Hour))
+	if err != nil {
+		t.Fatalf("summary: %v", err)
+	}
+	if len(sum) != 2 || sum[0].Node != 2001 || sum[0].Rounds != 2 || sum[0].Received != 4 {
+		t.Fatalf("summary = %+v", sum)
+	}
+	// Average weighted by answered probes: (3*40 + 1*80) / 4
+	if s := sum[0]; s.AvgRTTMs != 50 || s.MaxRTTMs != 80 || s.LossPct < 33.3 || s.LossPct > 33.4 {
+		t.Fatalf("2001 summary = %+v", s)
+	}
+
+	apiLayer := &api.API{Privacy: privacy.DefaultPolicy()}
+	rec := httptest.NewRecorder()
+	apiLayer.NodeHealthHandler(rec, httptest.NewRequest(http.MethodGet, "/api/node-health", nil))
+	if rec.Code != 503 {
+		t.Fatalf("disabled status = %d", rec.Code)
+	}
+	apiLayer.SetNodeHealth(repo)
+
+	rec = httptest.NewRecorder()
+	apiLayer.NodeHealthHandler(rec, httptest.NewRequest(http.MethodGet, "/api/node-health?node=2001&since=-72h", nil))
+	var env envelope
+	_ = json.Unmarshal(rec.Body.Bytes(), &env)
+	var hist struct {
+		Rounds []models.NodeHealth `json:"rounds"`
+	}
+	_ = json.Unmarshal(env.Data, &hist)
+	if len(hist.Rounds) != 3 || hist.Rounds[0].Received != 0 {
+		t.Fatalf("history = %s", rec.Body.String())
+	}
+	if ip := hist.Rounds[0].IP; ip != "192.0.*.*" {
+		t.Fatalf("anonymous viewer saw ip %q", ip)
+	}
+
+	rec = httptest.NewRecorder()
+	apiLayer.NodeHealthHandler(rec, httptest.NewRequest(http.MethodGet, "/api/node-health?since=yesterday", nil))
+	if rec.Code != 400 {
+		t.Fatalf("bad since status = %d", rec.Code)
+	}
+
+	if n, err := repo.Prune(ctx, now.Add(-24*time.Hour)); err != nil || n != 1 {
+		t.Fatalf("prune = %d, %v", n, err)
+	}
+}
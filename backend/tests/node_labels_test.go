@@ -0,0 +1,70 @@
+package tests
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/dbehnke/allstar-nexus/backend/api"
+	"github.com/dbehnke/allstar-nexus/backend/models"
+	"github.com/dbehnke/allstar-nexus/backend/repository"
+	"github.com/dbehnke/allstar-nexus/internal/core"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestNodeLabelOverrides(t *testing.T) {
+	gdb, err := gorm.Open(sqlite.New(sqlite.Config{DriverName: "sqlite", DSN: filepath.Join(t.TempDir(), "test.db")}), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open gorm sqlite: %v", err)
+	}
+	if err := gdb.AutoMigrate(&models.User{}, &models.NodeInfo{}, &models.LocalNode{}, &models.NodeLabel{}); err != nil {
+		t.Fatalf("automigrate: %v", err)
+	}
+	nodeRepo := repository.NewNodeInfoRepository(gdb)
+	if err := nodeRepo.Upsert(t.Context(), &models.NodeInfo{NodeID: 2000, Callsign: "W1AW", Description: "old hub", Location: "Newington, CT"}); err != nil {
+		t.Fatal(err)
+	}
+
+	lookup := core.NewNodeLookupService("")
+	lookup.SetNodeInfoRepository(nodeRepo)
+	apiLayer := api.New(gdb, "test-secret", time.Hour)
+	apiLayer.SetNodeLabels(repository.NewNodeLabelRepo(gdb), lookup)
+	srv := httptest.NewServer(http.HandlerFunc(apiLayer.AdminNodeLabels))
+	t.Cleanup(srv.Close)
+
+	if code := apiRequest(t, http.MethodPost, srv.URL, `{"node":2000,"label":"  Newington hub "}`, nil); code != 200 {
+		t.Fatalf("save: code=%d", code)
+	}
+	if info := lookup.LookupNode(2000); info == nil || info.Description != "Newington hub" || info.Callsign != "W1AW" || info.Location != "Newington, CT" {
+		t.Fatalf("label not applied over astdb: %+v", info)
+	}
+	// Nodes unknown to astdb get a name too
+	if code := apiRequest(t, http.MethodPost, srv.URL, `{"node":2999,"label":"Mystery link"}`, nil); code != 200 {
+		t.Fatalf("save unknown: code=%d", code)
+	}
+	if info := lookup.LookupNode(2999); info == nil || info.Description != "Mystery link" {
+		t.Fatalf("unknown node label: %+v", info)
+	}
+
+	var list struct {
+		Labels []models.NodeLabel `json:"labels"`
+	}
+	if code := apiRequest(t, http.MethodGet, srv.URL, "", &list); code != 200 || len(list.Labels) != 2 || list.Labels[0].Node != 2000 {
+		t.Fatalf("list: code=%d %+v", code, list)
+	}
+	if code := apiRequest(t, http.MethodPost, srv.URL, `{"node":2000,"label":"  "}`, nil); code != 400 {
+		t.Fatalf("empty label: code=%d", code)
+	}
+	if code := apiRequest(t, http.MethodDelete, srv.URL+"?node=2000", "", nil); code != 200 {
+		t.Fatalf("delete: code=%d", code)
+	}
+	if code := apiRequest(t, http.MethodDelete, srv.URL+"?node=2000", "", nil); code != 404 {
+		t.Fatalf("delete again: code=%d", code)
+	}
+	if info := lookup.LookupNode(2000); info == nil || info.Description != "old hub" {
+		t.Fatalf("astdb description not restored: %+v", info)
+	}
+}
@@ -0,0 +1,95 @@
+package tests
+
+import (
+	"context"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dbehnke/allstar-nexus/backend/models"
+	"github.com/dbehnke/allstar-nexus/backend/repository"
+	"github.com/dbehnke/allstar-nexus/internal/core"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+type fakeNodeFetcher struct {
+	mu    sync.Mutex
+	calls map[int]int
+	known map[int]models.NodeInfo
+}
+
+func (f *fakeNodeFetcher) Fetch(ctx context.Context, node int) (*models.NodeInfo, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls[node]++
+	if n, ok := f.known[node]; ok {
+		return &n, nil
+	}
+	return nil, nil
+}
+
+func (f *fakeNodeFetcher) count(node int) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.calls[node]
+}
+
+func TestNodeLookupWebFallback(t *testing.T) {
+	gdb, err := gorm.Open(sqlite.New(sqlite.Config{DriverName: "sqlite", DSN: filepath.Join(t.TempDir(), "test.db")}), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open gorm sqlite: %v", err)
+	}
+	if err := gdb.AutoMigrate(&models.NodeInfo{}); err != nil {
+		t.Fatalf("automigrate: %v", err)
+	}
+	repo := repository.NewNodeInfoRepository(gdb)
+	if err := repo.Upsert(context.Background(), &models.NodeInfo{NodeID: 2000, Callsign: "W1AW", LastSeen: time.Now()}); err != nil {
+		t.Fatal(err)
+	}
+
+	fetcher := &fakeNodeFetcher{calls: map[int]int{}, known: map[int]models.NodeInfo{
+		59999: {Callsign: "KD8NEW", Description: "146.520", Location: "Flint, MI"},
+	}}
+	lookup := core.NewNodeLookupService("")
+	lookup.SetNodeInfoRepository(repo)
+	lookup.EnableWebFallback(fetcher, time.Hour, time.Hour, 6000)
+
+	if info := lookup.LookupNode(2000); info == nil || info.Callsign != "W1AW" || fetcher.count(2000) != 0 {
+		t.Fatalf("astdb hit must not reach the web: %+v calls=%d", info, fetcher.count(2000))
+	}
+
+	// A miss returns nothing right away and is cached once the background fetch lands
+	if info := lookup.LookupNode(59999); info != nil {
+		t.Fatalf("miss should not block on the web: %+v", info)
+	}
+	var info *core.NodeInfo
+	for deadline := time.Now().Add(2 * time.Second); info == nil && time.Now().Before(deadline); {
+		time.Sleep(10 * time.Millisecond)
+		info = lookup.LookupNode(59999)
+	}
+	if info == nil || info.Callsign != "KD8NEW" || info.Location != "Flint, MI" {
+		t.Fatalf("fallback result not cached: %+v", info)
+	}
+	row, _ := repo.GetByNodeID(context.Background(), 59999)
+	if row == nil || row.FetchedAt == nil {
+		t.Fatalf("cached row not marked as fetched: %+v", row)
+	}
+
+	// Unknown nodes are asked for once per negative TTL; private numbers never
+	for i := 0; i < 5; i++ {
+		lookup.LookupNode(58888)
+		lookup.LookupNode(1999)
+		time.Sleep(20 * time.Millisecond)
+	}
+	if c := fetcher.count(58888); c != 1 {
+		t.Fatalf("unknown node fetched %d times, want 1", c)
+	}
+	if c := fetcher.count(1999); c != 0 {
+		t.Fatalf("private node fetched %d times", c)
+	}
+	if c := fetcher.count(59999); c != 1 {
+		t.Fatalf("cached node fetched %d times, want 1", c)
+	}
+}
@@ -0,0 +1,111 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/dbehnke/allstar-nexus/backend/api"
+	"github.com/dbehnke/allstar-nexus/backend/models"
+	"github.com/dbehnke/allstar-nexus/backend/repository"
+	"github.com/dbehnke/allstar-nexus/internal/core"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+type nodeNotes struct {
+	Node  int      `json:"node"`
+	Notes string   `json:"notes"`
+	Tags  []string `json:"tags"`
+}
+
+func newNodeNotesServer(t *testing.T, sm *core.StateManager) *httptest.Server {
+	t.Helper()
+	gdb, err := gorm.Open(sqlite.New(sqlite.Config{DriverName: "sqlite", DSN: filepath.Join(t.TempDir(), "test.db")}), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open gorm sqlite: %v", err)
+	}
+	if err := gdb.AutoMigrate(&models.User{}, &models.NodeAnnotation{}); err != nil {
+		t.Fatalf("automigrate: %v", err)
+	}
+	apiLayer := api.New(gdb, "test-secret", time.Hour)
+	apiLayer.SetStateManager(sm)
+	apiLayer.SetNodeAnnotations(repository.NewNodeAnnotationRepo(gdb))
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/admin/nodes/notes", apiLayer.NodeNotesList)
+	mux.HandleFunc("/api/admin/nodes/{id}/notes", apiLayer.NodeNotes)
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func apiRequest(t *testing.T, method, url, body string, out any) int {
+	t.Helper()
+	req, _ := http.NewRequest(method, url, bytes.NewBufferString(body))
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("%s %s: %v", method, url, err)
+	}
+	defer resp.Body.Close()
+	var env envelope
+	_ = json.NewDecoder(resp.Body).Decode(&env)
+	if out != nil {
+		_ = json.Unmarshal(env.Data, out)
+	}
+	return resp.StatusCode
+}
+
+func TestNodeNotes_CRUDAndLinkPayload(t *testing.T) {
+	sm := core.NewStateManager()
+	sm.SeedLinkStats([]core.LinkInfo{{Node: 2001}, {Node: 2002}})
+	srv := newNodeNotesServer(t, sm)
+	url := srv.URL + "/api/admin/nodes/2001/notes"
+
+	var got nodeNotes
+	if code := apiRequest(t, http.MethodGet, url, "", &got); code != 200 || got.Node != 2001 || len(got.Tags) != 0 {
+		t.Fatalf("empty notes: code=%d %+v", code, got)
+	}
+
+	code := apiRequest(t, http.MethodPut, url, `{"notes":" Intermittent audio ","tags":["Problem  Node","mobile","problem node"]}`, &got)
+	if code != 200 || got.Notes != "Intermittent audio" || len(got.Tags) != 2 || got.Tags[0] != "problem node" {
+		t.Fatalf("save notes: code=%d %+v", code, got)
+	}
+	_ = apiRequest(t, http.MethodPut, srv.URL+"/api/admin/nodes/2002/notes", `{"tags":["club repeater"]}`, nil)
+
+	// Tags and notes reach the live link payload
+	for _, li := range sm.Snapshot().LinksDetailed {
+		if li.Node == 2001 && (len(li.Tags) != 2 || li.Notes != "Intermittent audio") {
+			t.Fatalf("link 2001 not annotated: %+v", li)
+		}
+	}
+
+	var list struct {
+		Nodes []nodeNotes `json:"nodes"`
+	}
+	if code := apiRequest(t, http.MethodGet, srv.URL+"/api/admin/nodes/notes?tag=mobile", "", &list); code != 200 || len(list.Nodes) != 1 || list.Nodes[0].Node != 2001 {
+		t.Fatalf("tag filter: code=%d %+v", code, list)
+	}
+	if _ = apiRequest(t, http.MethodGet, srv.URL+"/api/admin/nodes/notes", "", &list); len(list.Nodes) != 2 {
+		t.Fatalf("expected 2 annotated nodes, got %+v", list)
+	}
+
+	if code := apiRequest(t, http.MethodPut, url, `{"tags":["a,b"]}`, nil); code != 400 {
+		t.Fatalf("comma in tag should be rejected, got %d", code)
+	}
+	if code := apiRequest(t, http.MethodGet, srv.URL+"/api/admin/nodes/abc/notes", "", nil); code != 400 {
+		t.Fatalf("invalid node id should be rejected, got %d", code)
+	}
+
+	if code := apiRequest(t, http.MethodDelete, url, "", nil); code != 200 {
+		t.Fatalf("delete: code=%d", code)
+	}
+	for _, li := range sm.Snapshot().LinksDetailed {
+		if li.Node == 2001 && (len(li.Tags) != 0 || li.Notes != "") {
+			t.Fatalf("link 2001 still annotated after delete: %+v", li)
+		}
+	}
+}
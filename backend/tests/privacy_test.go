@@ -0,0 +1,90 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/dbehnke/allstar-nexus/backend/api"
+	"github.com/dbehnke/allstar-nexus/backend/auth"
+	"github.com/dbehnke/allstar-nexus/backend/models"
+	"github.com/dbehnke/allstar-nexus/internal/core"
+	"github.com/dbehnke/allstar-nexus/internal/privacy"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+type privacyStateStub struct{}
+
+func (privacyStateStub) TalkerLogSnapshot() any {
+	return []core.TalkerEvent{{Kind: "TX_START", Node: 2000, Callsign: "W1AW", Description: "Newington"}}
+}
+
+func (privacyStateStub) Snapshot() core.NodeState {
+	return core.NodeState{NodeID: 1999, LinksDetailed: []core.LinkInfo{{Node: 2000, IP: "198.51.100.7", NodeCallsign: "W1AW"}}}
+}
+
+func TestPrivacyPolicyAppliedToREST(t *testing.T) {
+	gdb, err := gorm.Open(sqlite.New(sqlite.Config{DriverName: "sqlite", DSN: filepath.Join(t.TempDir(), "test.db")}), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open gorm sqlite: %v", err)
+	}
+	if err := gdb.AutoMigrate(&models.User{}, &models.LinkStat{}); err != nil {
+		t.Fatalf("automigrate: %v", err)
+	}
+	apiLayer := api.New(gdb, "test-secret", time.Hour)
+	apiLayer.SetStateManager(privacyStateStub{})
+	apiLayer.SetPrivacyPolicy(privacy.Policy{IPMask: privacy.MaskFull, HideAnonCallsigns: true, HideAnonTalkerHistory: true})
+
+	hash, _ := auth.HashPassword("password123")
+	if _, err := apiLayer.Users.Create(t.Context(), "user@example.com", hash, models.RoleUser); err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+	userToken, _ := auth.GenerateJWT("user@example.com", models.RoleUser, time.Hour, "test-secret")
+	gdb.Create(&models.LinkStat{Node: 2000, IP: "198.51.100.7", NodeCallsign: "W1AW", Direction: "OUT", Mode: "T"})
+
+	get := func(handler http.HandlerFunc, token string) map[string]any {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		handler(rec, req)
+		var env envelope
+		_ = json.Unmarshal(rec.Body.Bytes(), &env)
+		var out map[string]any
+		_ = json.Unmarshal(env.Data, &out)
+		return out
+	}
+
+	// Anonymous: no talker history, no callsigns, IPs hidden
+	talk := get(apiLayer.TalkerLog, "")
+	if events, _ := talk["events"].([]any); len(events) != 0 || talk["restricted"] != true {
+		t.Fatalf("anonymous talker log should be restricted: %v", talk)
+	}
+	status := get(apiLayer.Status, "")
+	link := status["state"].(map[string]any)["links_detailed"].([]any)[0].(map[string]any)
+	if link["ip"] != nil || link["node_callsign"] != nil || link["node"].(float64) != 2000 {
+		t.Fatalf("anonymous link not filtered: %v", link)
+	}
+
+	stats := get(apiLayer.LinkStatsHandler, "")["stats"].([]any)[0].(map[string]any)
+	if stats["ip"] != nil || stats["node_callsign"] != nil || stats["mode"] != "T" {
+		t.Fatalf("anonymous persisted link not filtered: %v", stats)
+	}
+
+	// Signed-in user: talker history and callsigns, but IPs still hidden
+	talk = get(apiLayer.TalkerLog, userToken)
+	events, _ := talk["events"].([]any)
+	if len(events) != 1 || events[0].(map[string]any)["callsign"] != "W1AW" {
+		t.Fatalf("user talker log wrong: %v", talk)
+	}
+	status = get(apiLayer.Status, userToken)
+	link = status["state"].(map[string]any)["links_detailed"].([]any)[0].(map[string]any)
+	if link["ip"] != nil || link["node_callsign"] != "W1AW" {
+		t.Fatalf("user link wrong: %v", link)
+	}
+}
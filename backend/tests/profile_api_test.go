@@ -38,6 +38,7 @@ func setupDBForProfileTest(t *testing.T) (*gorm.DB, *repository.LevelConfigRepo,
 		&models.TransmissionLog{},
 		&models.XPActivityLog{},
 		&models.TallyState{},
+		&models.TallyWindowMark{},
 	); err != nil {
 		t.Fatalf("automigrate: %v", err)
 	}
@@ -128,6 +129,7 @@ func TestProfileAPI_ReturnsAccurateAggregates(t *testing.T) {
 			RawXP     int    `json:"raw_xp"`
 			AwardedXP int    `json:"awarded_xp"`
 		} `json:"daily_breakdown"`
+		Estimate *gamification.LevelEstimate `json:"next_level_estimate"`
 	}
 
 	if err := json.NewDecoder(resp.Body).Decode(&payload); err != nil {
@@ -172,4 +174,13 @@ func TestProfileAPI_ReturnsAccurateAggregates(t *testing.T) {
 	if sumBreakdown < total {
 		t.Fatalf("breakdown awarded sum %d < total %d", sumBreakdown, total)
 	}
+
+	// 120 XP over the 30-day window is 4 XP/day; 240 XP to go is about 60 days
+	est := payload.Estimate
+	if est == nil || est.ActiveDays != 1 || est.AvgXPPerActiveDay != float64(total) || est.XPToNext != 360-total || est.NextLevel != 2 {
+		t.Fatalf("estimate = %+v", est)
+	}
+	if est.DaysToNext == nil || *est.DaysToNext != 60 || est.ProjectedAt == nil {
+		t.Fatalf("projection = %+v", est)
+	}
 }
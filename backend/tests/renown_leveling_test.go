@@ -23,7 +23,7 @@ func setupDBForRenown(t *testing.T) *gorm.DB {
 	if err != nil {
 		t.Fatalf("open gorm sqlite: %v", err)
 	}
-	if err := gdb.AutoMigrate(&models.CallsignProfile{}, &models.LevelConfig{}, &models.TransmissionLog{}, &models.XPActivityLog{}, &models.TallyState{}); err != nil {
+	if err := gdb.AutoMigrate(&models.CallsignProfile{}, &models.LevelConfig{}, &models.TransmissionLog{}, &models.XPActivityLog{}, &models.TallyState{}, &models.TallyWindowMark{}); err != nil {
 		t.Fatalf("automigrate: %v", err)
 	}
 	return gdb
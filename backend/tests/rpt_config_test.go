@@ -0,0 +1,95 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dbehnke/allstar-nexus/backend/api"
+	"github.com/dbehnke/allstar-nexus/backend/models"
+	"github.com/dbehnke/allstar-nexus/backend/repository"
+	"github.com/dbehnke/allstar-nexus/internal/ami"
+	"github.com/dbehnke/allstar-nexus/internal/core"
+	"github.com/dbehnke/allstar-nexus/internal/rptconf"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+type reloadSender struct {
+	commands []string
+	err      error
+}
+
+func (s *reloadSender) SendCommand(ctx context.Context, command string) (ami.Message, error) {
+	s.commands = append(s.commands, command)
+	return ami.Message{}, s.err
+}
+
+func TestNodeTelemetry(t *testing.T) {
+	gdb, err := gorm.Open(sqlite.New(sqlite.Config{DriverName: "sqlite", DSN: filepath.Join(t.TempDir(), "test.db")}), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open gorm sqlite: %v", err)
+	}
+	if err := gdb.AutoMigrate(&models.User{}, &models.AuditEntry{}); err != nil {
+		t.Fatalf("automigrate: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "rpt.conf")
+	conf := "[2001]\nrxchannel = dahdi/pseudo\nhangtime = 1000\n\n[3001]\nrxchannel = dahdi/pseudo\n\n[telemetry]\nct1 = |t(350,0,100,2048)\n"
+	if err := os.WriteFile(path, []byte(conf), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	sender := &reloadSender{}
+	audit := repository.NewAuditRepo(gdb)
+	apiLayer := api.New(gdb, "test-secret", time.Hour)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/admin/nodes/{id}/telemetry", apiLayer.NodeTelemetry)
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	url := srv.URL + "/api/admin/nodes/2001/telemetry"
+
+	if code := apiRequest(t, http.MethodGet, url, "", nil); code != 503 {
+		t.Fatalf("disabled: expected 503, got %d", code)
+	}
+	// 3001 is in rpt.conf but not a configured node
+	apiLayer.SetRptConfigEditor(core.NewRptConfigEditor(rptconf.New(path), sender, audit, []int{2001}, ""))
+
+	var got struct {
+		Node     int               `json:"node"`
+		Settings []rptconf.Setting `json:"settings"`
+		Tones    []string          `json:"tones"`
+	}
+	if code := apiRequest(t, http.MethodGet, url, "", &got); code != 200 || got.Node != 2001 || got.Settings[0].Key != "hangtime" || got.Settings[0].Value != "1000" || len(got.Tones) != 1 {
+		t.Fatalf("get: %d %+v", code, got)
+	}
+	if code := apiRequest(t, http.MethodGet, srv.URL+"/api/admin/nodes/3001/telemetry", "", nil); code != 404 {
+		t.Fatalf("unconfigured node: expected 404, got %d", code)
+	}
+	if code := apiRequest(t, http.MethodPut, url, `{"settings":{"idtime":"5"}}`, nil); code != 400 || len(sender.commands) != 0 {
+		t.Fatalf("invalid: expected 400 without reload, got %d (%v)", code, sender.commands)
+	}
+
+	if code := apiRequest(t, http.MethodPut, url, `{"settings":{"hangtime":"1500","unlinkedct":"ct1"}}`, &got); code != 200 || got.Settings[0].Value != "1500" {
+		t.Fatalf("put: %d %+v", code, got)
+	}
+	if strings.Join(sender.commands, ",") != "rpt reload" {
+		t.Fatalf("reload: %v", sender.commands)
+	}
+	entries, _ := audit.Recent(context.Background(), "", 10)
+	if len(entries) != 1 || entries[0].Action != core.AuditActionRptConfig || entries[0].Detail != `2001: hangtime "1000"->"1500", unlinkedct ""->"ct1"` {
+		t.Fatalf("audit: %+v", entries)
+	}
+
+	sender.err = errors.New("not connected")
+	if code := apiRequest(t, http.MethodPut, url, `{"settings":{"hangtime":""}}`, nil); code != 502 {
+		t.Fatalf("reload failure: expected 502, got %d", code)
+	}
+	if b, _ := os.ReadFile(path); strings.Contains(string(b), "hangtime") {
+		t.Fatalf("the change should be saved even when the reload fails:\n%s", b)
+	}
+}
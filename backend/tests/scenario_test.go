@@ -0,0 +1,126 @@
+package tests
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/dbehnke/allstar-nexus/backend/tests/harness"
+	"github.com/dbehnke/allstar-nexus/internal/core"
+)
+
+// Scenario tests replay AMI captures from testdata/scenarios through the full stack and
+// check what reaches WebSocket clients, the transmission log and the scoreboard.
+
+var scenarioNodes = []core.NodeInfo{
+	{Node: 2001, Callsign: "W1AW", Description: "Hub One"},
+	{Node: 2002, Callsign: "K8ABC", Description: "Mobile"},
+}
+
+func scenario(name string) string { return filepath.Join("testdata", "scenarios", name) }
+
+func talkerEvent(t *testing.T, env harness.Envelope) core.TalkerEvent {
+	t.Helper()
+	var evt core.TalkerEvent
+	if err := json.Unmarshal(env.Data, &evt); err != nil {
+		t.Fatalf("decode talker event: %v", err)
+	}
+	return evt
+}
+
+func TestScenarioSingleKeyup(t *testing.T) {
+	h := harness.New(t, harness.Options{Nodes: scenarioNodes})
+	ws := h.DialWS(t)
+	ws.WaitFor(t, "STATUS_UPDATE", 2*time.Second, nil)
+
+	h.Replay(t, scenario("single_keyup.jsonl"))
+
+	start := talkerEvent(t, ws.WaitFor(t, "TALKER_EVENT", 2*time.Second, func(e harness.Envelope) bool {
+		return talkerEvent(t, e).Kind == "TX_START"
+	}))
+	if start.Node != 2001 || start.Callsign != "W1AW" {
+		t.Fatalf("TX_START = %+v", start)
+	}
+	stop := talkerEvent(t, ws.WaitFor(t, "TALKER_EVENT", 2*time.Second, func(e harness.Envelope) bool {
+		return talkerEvent(t, e).Kind == "TX_STOP"
+	}))
+	if stop.Node != 2001 || stop.Duration != 2 {
+		t.Fatalf("TX_STOP = %+v", stop)
+	}
+	for _, e := range ws.Messages("TALKER_EVENT") {
+		if evt := talkerEvent(t, e); evt.Node == 2002 {
+			t.Fatalf("idle link produced a talker event: %+v", evt)
+		}
+	}
+
+	rows := h.WaitForTransmissions(t, 1, 3*time.Second)
+	if len(rows) != 1 || rows[0].Callsign != "W1AW" || rows[0].SourceID != 1999 || rows[0].AdjacentLinkID != 2001 || rows[0].DurationSeconds != 2 {
+		t.Fatalf("transmissions = %+v", rows)
+	}
+
+	if summary := h.RunTally(t); summary.TransmissionsHandled != 1 {
+		t.Fatalf("tally summary = %+v", summary)
+	}
+	p, err := h.Profiles.Find(t.Context(), "W1AW")
+	if err != nil || p == nil || p.ExperiencePoints != 2 {
+		t.Fatalf("profile = %+v, err = %v", p, err)
+	}
+	// A second tally must not award the same transmission again
+	h.RunTally(t)
+	if p, _ := h.Profiles.Find(t.Context(), "W1AW"); p.ExperiencePoints != 2 {
+		t.Fatalf("re-tally changed XP to %d", p.ExperiencePoints)
+	}
+
+	code, body := h.Get(t, "/api/gamification/scoreboard")
+	if code != 200 {
+		t.Fatalf("scoreboard status %d: %s", code, body)
+	}
+	var board struct {
+		Scoreboard []struct {
+			Callsign string `json:"callsign"`
+		} `json:"scoreboard"`
+	}
+	_ = json.Unmarshal(body, &board)
+	if len(board.Scoreboard) != 1 || board.Scoreboard[0].Callsign != "W1AW" {
+		t.Fatalf("scoreboard = %s", body)
+	}
+}
+
+func TestScenarioJitterIsOneTransmission(t *testing.T) {
+	h := harness.New(t, harness.Options{Nodes: scenarioNodes})
+	h.Replay(t, scenario("jitter_keyup.jsonl"))
+
+	h.WaitForTransmissions(t, 1, 3*time.Second)
+	time.Sleep(200 * time.Millisecond) // let any stray second row land
+	rows := h.WaitForTransmissions(t, 1, time.Second)
+	if len(rows) != 1 || rows[0].DurationSeconds != 3 {
+		t.Fatalf("transmissions = %+v", rows)
+	}
+}
+
+func TestScenarioTwoTalkers(t *testing.T) {
+	h := harness.New(t, harness.Options{Nodes: scenarioNodes})
+	ws := h.DialWS(t)
+	ws.WaitFor(t, "STATUS_UPDATE", 2*time.Second, nil)
+
+	h.Replay(t, scenario("two_talkers.jsonl"))
+
+	rows := h.WaitForTransmissions(t, 2, 3*time.Second)
+	if len(rows) != 2 || rows[0].Callsign != "W1AW" || rows[1].Callsign != "K8ABC" || rows[1].DurationSeconds != 2 {
+		t.Fatalf("transmissions = %+v", rows)
+	}
+	ws.WaitFor(t, "TALKER_EVENT", 2*time.Second, func(e harness.Envelope) bool {
+		evt := talkerEvent(t, e)
+		return evt.Kind == "TX_STOP" && evt.Node == 2002
+	})
+
+	if summary := h.RunTally(t); summary.CallsignsProcessed != 2 || summary.TransmissionsHandled != 2 {
+		t.Fatalf("tally summary = %+v", summary)
+	}
+	for cs, xp := range map[string]int{"W1AW": rows[0].DurationSeconds, "K8ABC": 2} {
+		if p, _ := h.Profiles.Find(t.Context(), cs); p == nil || p.ExperiencePoints != xp {
+			t.Fatalf("%s profile = %+v, want %d XP", cs, p, xp)
+		}
+	}
+}
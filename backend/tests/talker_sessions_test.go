@@ -0,0 +1,65 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/dbehnke/allstar-nexus/backend/api"
+	"github.com/dbehnke/allstar-nexus/internal/core"
+	"github.com/dbehnke/allstar-nexus/internal/privacy"
+	"github.com/dbehnke/allstar-nexus/internal/timefmt"
+)
+
+type sessionStateStub struct{ events []core.TalkerEvent }
+
+func (s sessionStateStub) TalkerLogSnapshot() any { return s.events }
+func (sessionStateStub) Snapshot() core.NodeState { return core.NodeState{} }
+
+func TestTalkerSessionsEndpoint(t *testing.T) {
+	now := time.Now()
+	apiLayer := &api.API{Privacy: privacy.Policy{HideAnonCallsigns: true}}
+	apiLayer.SetLocale(timefmt.Locale{Location: time.UTC, Tag: "en-GB"})
+	apiLayer.SetStateManager(sessionStateStub{events: []core.TalkerEvent{
+		{At: now.Add(-time.Minute), Kind: "TX_START", Node: 2000, Callsign: "W1AW"},
+		{At: now.Add(-50 * time.Second), Kind: "TX_STOP", Node: 2000, Callsign: "W1AW", Duration: 10},
+		{At: now.Add(-5 * time.Second), Kind: "TX_START", Node: 2001, Callsign: "K8ABC"},
+	}})
+
+	var hints timefmt.Hints
+	get := func(query string) (int, []core.TalkerSession) {
+		rec := httptest.NewRecorder()
+		apiLayer.TalkerSessions(rec, httptest.NewRequest(http.MethodGet, "/api/talker-sessions"+query, nil))
+		var env envelope
+		_ = json.Unmarshal(rec.Body.Bytes(), &env)
+		var out struct {
+			Sessions []core.TalkerSession `json:"sessions"`
+			Hints    timefmt.Hints        `json:"time_format"`
+		}
+		_ = json.Unmarshal(env.Data, &out)
+		hints = out.Hints
+		return rec.Code, out.Sessions
+	}
+
+	code, sessions := get("")
+	if code != 200 || len(sessions) != 2 {
+		t.Fatalf("sessions = %d %+v", code, sessions)
+	}
+	if s := sessions[0]; s.Node != 2001 || !s.Ongoing || s.End != nil || s.Callsign != "" {
+		t.Fatalf("ongoing session = %+v (callsigns must be hidden from anonymous viewers)", s)
+	}
+	if s := sessions[1]; s.Node != 2000 || s.Ongoing || s.Duration != 10 || s.DurationHuman != "10s" {
+		t.Fatalf("closed session = %+v", s)
+	}
+	if hints.Timezone != "UTC" || hints.UTCOffset != "+00:00" || hints.Locale != "en-GB" {
+		t.Fatalf("time hints = %+v", hints)
+	}
+	if _, sessions := get("?limit=1"); len(sessions) != 1 {
+		t.Fatalf("limited sessions = %+v", sessions)
+	}
+	if code, _ := get("?limit=x"); code != 400 {
+		t.Fatalf("bad limit status = %d", code)
+	}
+}
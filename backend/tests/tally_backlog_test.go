@@ -0,0 +1,86 @@
+package tests
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dbehnke/allstar-nexus/backend/gamification"
+	"github.com/dbehnke/allstar-nexus/backend/models"
+	"github.com/dbehnke/allstar-nexus/backend/repository"
+)
+
+func TestTallyBacklogRunsInBackground(t *testing.T) {
+	gdb := setUpGormTestDB(t)
+	if err := gdb.AutoMigrate(&models.TallyRun{}); err != nil {
+		t.Fatalf("automigrate: %v", err)
+	}
+	ctx := context.Background()
+	levelRepo := repository.NewLevelConfigRepo(gdb)
+	if err := levelRepo.SeedDefaults(ctx, gamification.CalculateLevelRequirements()); err != nil {
+		t.Fatalf("seed level config: %v", err)
+	}
+	txRepo := repository.NewTransmissionLogRepository(gdb)
+	profileRepo := repository.NewCallsignProfileRepo(gdb)
+	stateRepo := repository.NewTallyStateRepo(gdb)
+	runRepo := repository.NewTallyRunRepo(gdb)
+
+	// Three days of downtime since the last tally
+	since := time.Now().Add(-72 * time.Hour)
+	if _, err := stateRepo.GetOrInit(ctx); err != nil {
+		t.Fatalf("init state: %v", err)
+	}
+	if err := stateRepo.UpdateLastTally(ctx, since); err != nil {
+		t.Fatalf("seed last tally: %v", err)
+	}
+	for _, ago := range []time.Duration{70 * time.Hour, 40 * time.Hour, 2 * time.Hour} {
+		at := time.Now().Add(-ago)
+		_ = txRepo.LogTransmission(1001, 2001, "K9BACK", at, at.Add(30*time.Second), 30)
+	}
+
+	cfg := &gamification.Config{BacklogThreshold: time.Hour}
+	ts := gamification.NewTallyService(gdb, txRepo, profileRepo, levelRepo, repository.NewXPActivityRepo(gdb), stateRepo, cfg, 6*time.Hour, zaptestLogger())
+	ts.SetTallyRuns(runRepo)
+	var mu sync.Mutex
+	var events []gamification.TallyProgress
+	ts.SetProgressHook(func(p gamification.TallyProgress) {
+		mu.Lock()
+		events = append(events, p)
+		mu.Unlock()
+	})
+
+	if err := ts.Start(); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	defer ts.Stop()
+
+	// The run is recorded once the background tally has finished
+	var runs []models.TallyRun
+	deadline := time.Now().Add(5 * time.Second)
+	for len(runs) == 0 {
+		if time.Now().After(deadline) {
+			t.Fatalf("backlog tally did not finish; progress %+v", ts.Progress())
+		}
+		time.Sleep(10 * time.Millisecond)
+		runs, _ = runRepo.Recent(ctx, 10, false)
+	}
+	if len(runs) != 1 || runs[0].Trigger != "backlog" || runs[0].TransmissionsHandled != 3 {
+		t.Fatalf("tally runs: %+v", runs)
+	}
+
+	p := ts.Progress()
+	if p.Running || !p.Background || p.Windows < 12 || p.WindowsDone != p.Windows || p.Percent != 100 {
+		t.Errorf("final progress: %+v", p)
+	}
+	mu.Lock()
+	if len(events) < p.Windows {
+		t.Errorf("expected a progress event per window, got %d", len(events))
+	}
+	mu.Unlock()
+
+	prof, err := profileRepo.GetByCallsign(ctx, "K9BACK")
+	if err != nil || prof.ExperiencePoints != 90 {
+		t.Fatalf("expected 90 XP after catching up, got %+v (%v)", prof, err)
+	}
+}
@@ -0,0 +1,82 @@
+package tests
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/dbehnke/allstar-nexus/backend/gamification"
+	"github.com/dbehnke/allstar-nexus/backend/models"
+	"github.com/dbehnke/allstar-nexus/backend/repository"
+)
+
+// TestTallyRerunWindowDoesNotDoubleAward simulates a crash after XP was committed
+// but before the window completion was persisted: the restarted service re-runs the
+// same window and must skip what was already awarded.
+func TestTallyRerunWindowDoesNotDoubleAward(t *testing.T) {
+	gdb := setUpGormTestDB(t)
+	ctx := context.Background()
+	levelRepo := repository.NewLevelConfigRepo(gdb)
+	if err := levelRepo.SeedDefaults(ctx, gamification.CalculateLevelRequirements()); err != nil {
+		t.Fatalf("seed level config: %v", err)
+	}
+	txRepo := repository.NewTransmissionLogRepository(gdb)
+	profileRepo := repository.NewCallsignProfileRepo(gdb)
+	activityRepo := repository.NewXPActivityRepo(gdb)
+	stateRepo := repository.NewTallyStateRepo(gdb)
+	newService := func() *gamification.TallyService {
+		return gamification.NewTallyService(gdb, txRepo, profileRepo, levelRepo, activityRepo, stateRepo, &gamification.Config{}, 30*time.Minute, zaptestLogger())
+	}
+
+	start := time.Now().Add(-10 * time.Minute)
+	windowStart := start.Add(-time.Minute)
+	if _, err := stateRepo.GetOrInit(ctx); err != nil {
+		t.Fatalf("init state: %v", err)
+	}
+	if err := stateRepo.UpdateLastTally(ctx, windowStart); err != nil {
+		t.Fatalf("seed last tally: %v", err)
+	}
+	_ = txRepo.LogTransmission(1001, 2001, "K9ONCE", start, start.Add(40*time.Second), 40)
+	_ = txRepo.LogTransmission(1001, 2001, "K9ONCE", start.Add(time.Minute), start.Add(time.Minute+20*time.Second), 20)
+
+	first := newService()
+	if err := first.Start(); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	first.Stop()
+	xpAfter := func() int {
+		t.Helper()
+		p, err := profileRepo.GetByCallsign(ctx, "K9ONCE")
+		if err != nil {
+			t.Fatalf("profile: %v", err)
+		}
+		return p.ExperiencePoints
+	}
+	if got := xpAfter(); got != 60 {
+		t.Fatalf("expected 60 XP after first tally, got %d", got)
+	}
+
+	// Lose the persisted window completion, as if the process died mid-run
+	if err := stateRepo.UpdateLastTally(ctx, windowStart); err != nil {
+		t.Fatalf("reset last tally: %v", err)
+	}
+	_ = txRepo.LogTransmission(1001, 2001, "K9ONCE", start.Add(2*time.Minute), start.Add(2*time.Minute+10*time.Second), 10)
+
+	second := newService()
+	if err := second.Start(); err != nil {
+		t.Fatalf("restart: %v", err)
+	}
+	second.Stop()
+	if got := xpAfter(); got != 70 {
+		t.Fatalf("expected only the new transmission to be awarded (70 XP), got %d", got)
+	}
+	var activity int64
+	gdb.Model(&models.XPActivityLog{}).Where("callsign = ?", "K9ONCE").Count(&activity)
+	if activity != 3 {
+		t.Fatalf("expected 3 activity rows, got %d", activity)
+	}
+	var mark models.TallyWindowMark
+	if err := gdb.Where("callsign = ?", "K9ONCE").First(&mark).Error; err != nil || mark.Transmissions != 3 {
+		t.Fatalf("window mark: %+v (%v)", mark, err)
+	}
+}
@@ -0,0 +1,84 @@
+package tests
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/dbehnke/allstar-nexus/backend/api"
+	"github.com/dbehnke/allstar-nexus/backend/gamification"
+	"github.com/dbehnke/allstar-nexus/backend/models"
+	"github.com/dbehnke/allstar-nexus/backend/repository"
+)
+
+func TestTallyRunsHistoryAndTallyNow(t *testing.T) {
+	gdb := setUpGormTestDB(t)
+	if err := gdb.AutoMigrate(&models.TallyRun{}); err != nil {
+		t.Fatalf("automigrate: %v", err)
+	}
+	ctx := context.Background()
+	levelRepo := repository.NewLevelConfigRepo(gdb)
+	if err := levelRepo.SeedDefaults(ctx, gamification.CalculateLevelRequirements()); err != nil {
+		t.Fatalf("seed level config: %v", err)
+	}
+	txRepo := repository.NewTransmissionLogRepository(gdb)
+	ts := gamification.NewTallyService(gdb, txRepo, repository.NewCallsignProfileRepo(gdb), levelRepo,
+		repository.NewXPActivityRepo(gdb), repository.NewTallyStateRepo(gdb), &gamification.Config{}, 30*time.Minute, zaptestLogger())
+	runRepo := repository.NewTallyRunRepo(gdb)
+	ts.SetTallyRuns(runRepo)
+
+	start := time.Now().Add(-2 * time.Minute)
+	_ = txRepo.LogTransmission(1001, 2001, "K9RUN", start, start.Add(30*time.Second), 30)
+	if err := ts.Start(); err != nil {
+		t.Fatalf("start tally: %v", err)
+	}
+	defer ts.Stop()
+
+	apiLayer := api.New(gdb, "test-secret", time.Hour)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/admin/gamification/tally-runs", apiLayer.AdminTallyRuns)
+	mux.HandleFunc("/api/admin/gamification/tally-now", apiLayer.AdminTallyNow)
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	if code := apiRequest(t, http.MethodPost, srv.URL+"/api/admin/gamification/tally-now", "", nil); code != 503 {
+		t.Fatalf("tally-now before wiring: expected 503, got %d", code)
+	}
+	apiLayer.SetTallyRuns(runRepo, ts.RunNow)
+
+	var now struct {
+		Summary gamification.TallySummary `json:"summary"`
+	}
+	if code := apiRequest(t, http.MethodPost, srv.URL+"/api/admin/gamification/tally-now", "", &now); code != 200 {
+		t.Fatalf("tally-now: expected 200, got %d", code)
+	}
+
+	var list struct {
+		Runs []models.TallyRun `json:"runs"`
+	}
+	if code := apiRequest(t, http.MethodGet, srv.URL+"/api/admin/gamification/tally-runs", "", &list); code != 200 {
+		t.Fatalf("tally-runs: expected 200, got %d", code)
+	}
+	if len(list.Runs) != 2 {
+		t.Fatalf("expected 2 runs, got %+v", list.Runs)
+	}
+	manual, startup := list.Runs[0], list.Runs[1]
+	if manual.Trigger != "manual" || startup.Trigger != "scheduled" {
+		t.Errorf("unexpected triggers: %q, %q", manual.Trigger, startup.Trigger)
+	}
+	if startup.TransmissionsHandled != 1 || startup.CallsignsProcessed != 1 || startup.Error != "" {
+		t.Errorf("startup run: %+v", startup)
+	}
+	if startup.FinishedAt.Before(startup.StartedAt) {
+		t.Errorf("finished before start: %+v", startup)
+	}
+
+	if code := apiRequest(t, http.MethodGet, srv.URL+"/api/admin/gamification/tally-runs?failed=1", "", &list); code != 200 || len(list.Runs) != 0 {
+		t.Fatalf("failed runs: code=%d %+v", code, list.Runs)
+	}
+	if code := apiRequest(t, http.MethodGet, srv.URL+"/api/admin/gamification/tally-runs?limit=x", "", nil); code != 400 {
+		t.Fatalf("bad limit: expected 400, got %d", code)
+	}
+}
@@ -34,6 +34,7 @@ func setUpGormTestDB(t *testing.T) *gorm.DB {
 		&models.TransmissionLog{},
 		&models.XPActivityLog{},
 		&models.TallyState{},
+		&models.TallyWindowMark{},
 	); err != nil {
 		t.Fatalf("automigrate: %v", err)
 	}
@@ -0,0 +1,69 @@
+package tests
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/dbehnke/allstar-nexus/backend/gamification"
+	"github.com/dbehnke/allstar-nexus/backend/models"
+	"github.com/dbehnke/allstar-nexus/backend/repository"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	_ "modernc.org/sqlite"
+)
+
+// Tally runs that happen while the clock checker reports skew are annotated for later correction.
+func TestTallyAnnotatesRunsDuringClockSkew(t *testing.T) {
+	dir := t.TempDir()
+	gdb, err := gorm.Open(sqlite.New(sqlite.Config{DriverName: "sqlite", DSN: filepath.Join(dir, "skew_test.db")}), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open gorm sqlite: %v", err)
+	}
+	if err := gdb.AutoMigrate(&models.CallsignProfile{}, &models.LevelConfig{}, &models.TransmissionLog{}, &models.XPActivityLog{}, &models.TallyState{}, &models.TallyWindowMark{}, &models.TallySkewAnnotation{}); err != nil {
+		t.Fatalf("automigrate: %v", err)
+	}
+	levelRepo := repository.NewLevelConfigRepo(gdb)
+	profileRepo := repository.NewCallsignProfileRepo(gdb)
+	txRepo := repository.NewTransmissionLogRepository(gdb)
+	activityRepo := repository.NewXPActivityRepo(gdb)
+	stateRepo := repository.NewTallyStateRepo(gdb)
+	if err := levelRepo.SeedDefaults(context.Background(), gamification.CalculateLevelRequirements()); err != nil {
+		t.Fatalf("seed level config: %v", err)
+	}
+
+	cfg := &gamification.Config{}
+	ts := gamification.NewTallyService(gdb, txRepo, profileRepo, levelRepo, activityRepo, stateRepo, cfg, 30*time.Minute, zaptestLogger())
+	skewed := false
+	ts.ClockSkew = func() (time.Duration, bool) { return 42 * time.Second, skewed }
+
+	var summaries []gamification.TallySummary
+	done := make(chan struct{}, 2)
+	ts.OnTallyComplete = func(s gamification.TallySummary) {
+		summaries = append(summaries, s)
+		done <- struct{}{}
+	}
+
+	if err := ts.ProcessTally(); err != nil {
+		t.Fatalf("tally: %v", err)
+	}
+	<-done
+	skewed = true
+	if err := ts.ProcessTally(); err != nil {
+		t.Fatalf("tally: %v", err)
+	}
+	<-done
+
+	if summaries[0].ClockSkewed || !summaries[1].ClockSkewed || summaries[1].ClockOffsetSeconds != 42 {
+		t.Fatalf("unexpected summaries: %+v", summaries)
+	}
+	annotations, err := stateRepo.ListSkewAnnotations(context.Background(), 10)
+	if err != nil {
+		t.Fatalf("list annotations: %v", err)
+	}
+	if len(annotations) != 1 || annotations[0].OffsetSeconds != 42 {
+		t.Fatalf("expected one annotation with 42s offset, got %+v", annotations)
+	}
+}
@@ -0,0 +1,89 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/dbehnke/allstar-nexus/backend/api"
+	"github.com/dbehnke/allstar-nexus/backend/auth"
+	"github.com/dbehnke/allstar-nexus/backend/models"
+	"github.com/dbehnke/allstar-nexus/internal/telegrambot"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestTelegramLinkEndpoint(t *testing.T) {
+	gdb, err := gorm.Open(sqlite.New(sqlite.Config{DriverName: "sqlite", DSN: filepath.Join(t.TempDir(), "test.db")}), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open gorm sqlite: %v", err)
+	}
+	if err := gdb.AutoMigrate(&models.User{}); err != nil {
+		t.Fatalf("automigrate: %v", err)
+	}
+	apiLayer := api.New(gdb, "test-secret", time.Hour)
+	admin, err := apiLayer.Users.Create(context.Background(), "admin@example.com", "h", models.RoleAdmin)
+	if err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+	token, _ := auth.GenerateJWT("admin@example.com", models.RoleAdmin, time.Hour, "test-secret")
+	srv := httptest.NewServer(http.HandlerFunc(apiLayer.TelegramLink))
+	t.Cleanup(srv.Close)
+
+	call := func(method string, out any) int {
+		req, _ := http.NewRequest(method, srv.URL, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("%s: %v", method, err)
+		}
+		defer resp.Body.Close()
+		var env envelope
+		_ = json.NewDecoder(resp.Body).Decode(&env)
+		if out != nil {
+			_ = json.Unmarshal(env.Data, out)
+		}
+		return resp.StatusCode
+	}
+
+	if code := call(http.MethodPost, nil); code != 503 {
+		t.Fatalf("without a bot: expected 503, got %d", code)
+	}
+	apiLayer.SetTelegramLinker(telegrambot.New("TOKEN", ""))
+
+	var issued struct {
+		Code      string    `json:"code"`
+		Command   string    `json:"command"`
+		ExpiresAt time.Time `json:"expires_at"`
+	}
+	if code := call(http.MethodPost, &issued); code != 200 || issued.Code == "" || issued.Command != "/link "+issued.Code || time.Until(issued.ExpiresAt) <= 0 {
+		t.Fatalf("issue code: %d %+v", code, issued)
+	}
+
+	var status struct {
+		Linked bool `json:"linked"`
+	}
+	if code := call(http.MethodGet, &status); code != 200 || status.Linked {
+		t.Fatalf("status before linking: %d %+v", code, status)
+	}
+	tg := int64(77)
+	if err := apiLayer.Users.SetTelegramID(context.Background(), admin.ID, &tg); err != nil {
+		t.Fatal(err)
+	}
+	if call(http.MethodGet, &status); !status.Linked {
+		t.Fatal("expected linked account")
+	}
+	if code := call(http.MethodDelete, &status); code != 200 || status.Linked {
+		t.Fatalf("unlink: %d %+v", code, status)
+	}
+	if u, _ := apiLayer.Users.GetByTelegramID(context.Background(), tg); u != nil {
+		t.Fatal("account still linked")
+	}
+	if code := call(http.MethodPut, nil); code != 405 {
+		t.Fatalf("PUT: expected 405, got %d", code)
+	}
+}
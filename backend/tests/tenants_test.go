@@ -0,0 +1,162 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/dbehnke/allstar-nexus/backend/api"
+	"github.com/dbehnke/allstar-nexus/backend/auth"
+	"github.com/dbehnke/allstar-nexus/backend/middleware"
+	"github.com/dbehnke/allstar-nexus/backend/models"
+	"github.com/dbehnke/allstar-nexus/backend/repository"
+	"github.com/dbehnke/allstar-nexus/internal/core"
+	"github.com/dbehnke/allstar-nexus/internal/tenant"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+type tenantStateStub struct{}
+
+func (tenantStateStub) TalkerLogSnapshot() any {
+	return []core.TalkerEvent{{Kind: "TX_START", Node: 500, Source: 2001}, {Kind: "TX_START", Node: 501, Source: 3001}}
+}
+
+func (tenantStateStub) Snapshot() core.NodeState {
+	return core.NodeState{NodeID: 2001, Title: "Instance", Links: []int{500, 501}, LinksDetailed: []core.LinkInfo{{Node: 500}, {Node: 501, LocalNode: 3001}}}
+}
+
+func TestTenantScoping(t *testing.T) {
+	gdb, err := gorm.Open(sqlite.New(sqlite.Config{DriverName: "sqlite", DSN: filepath.Join(t.TempDir(), "test.db")}), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open gorm sqlite: %v", err)
+	}
+	if err := gdb.AutoMigrate(&models.User{}, &models.LinkStat{}); err != nil {
+		t.Fatalf("automigrate: %v", err)
+	}
+	reg, err := tenant.NewRegistry([]tenant.Tenant{
+		{ID: "west", Nodes: []int{2001}, Admins: []string{"wes@example.org"}, Branding: tenant.Branding{Title: "West Hub"}},
+		{ID: "east", Nodes: []int{3001}, Hosts: []string{"east.example.org"}},
+	})
+	if err != nil {
+		t.Fatalf("NewRegistry: %v", err)
+	}
+	apiLayer := api.New(gdb, "test-secret", time.Hour)
+	apiLayer.SetStateManager(tenantStateStub{})
+	apiLayer.SetTenants(reg)
+	apiLayer.SetBranding(api.Branding{Title: "Instance", ClubName: "Operators"}, nil, "")
+
+	roles := map[string]string{"wes@example.org": models.RoleUser, "op@example.org": models.RoleAdmin}
+	for email, role := range roles {
+		if _, err := apiLayer.Users.Create(t.Context(), email, "x", role); err != nil {
+			t.Fatalf("create user: %v", err)
+		}
+	}
+	userLoader := func(email string) (*repository.SafeUser, error) {
+		u, err := apiLayer.Users.GetByEmail(t.Context(), email)
+		if err != nil || u == nil {
+			return nil, err
+		}
+		return &repository.SafeUser{ID: u.ID, Email: u.Email, Role: u.Role}, nil
+	}
+	authMW := middleware.Auth("test-secret", userLoader)
+	admin := func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusNoContent) }
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/status", apiLayer.Status)
+	mux.HandleFunc("/api/talker-log", apiLayer.TalkerLog)
+	mux.HandleFunc("/api/branding", apiLayer.GetBranding)
+	mux.HandleFunc("/api/tenants", apiLayer.TenantList)
+	mux.Handle("/api/admin/instance", authMW(middleware.RequireAdmin(reg, false)(http.HandlerFunc(admin))))
+	mux.Handle("/api/admin/tenant", authMW(middleware.RequireAdmin(reg, true)(http.HandlerFunc(admin))))
+	srv := httptest.NewServer(reg.Middleware(mux))
+	t.Cleanup(srv.Close)
+
+	type state struct {
+		State struct {
+			NodeID int    `json:"node_id"`
+			Title  string `json:"title"`
+			Links  []int  `json:"links"`
+		} `json:"state"`
+	}
+	var st state
+	if code := apiRequest(t, http.MethodGet, srv.URL+"/api/status", "", &st); code != 200 || len(st.State.Links) != 2 {
+		t.Fatalf("unscoped status: code=%d %+v", code, st)
+	}
+	st = state{}
+	if code := apiRequest(t, http.MethodGet, srv.URL+"/h/west/api/status", "", &st); code != 200 || len(st.State.Links) != 1 || st.State.Links[0] != 500 || st.State.Title != "West Hub" {
+		t.Fatalf("west status: code=%d %+v", code, st)
+	}
+	st = state{}
+	if code := apiRequest(t, http.MethodGet, srv.URL+"/h/east/api/status", "", &st); code != 200 || len(st.State.Links) != 1 || st.State.Links[0] != 501 || st.State.NodeID != 3001 {
+		t.Fatalf("east status: code=%d %+v", code, st)
+	}
+	if code := apiRequest(t, http.MethodGet, srv.URL+"/h/south/api/status", "", nil); code != 404 {
+		t.Fatalf("unknown tenant: expected 404, got %d", code)
+	}
+
+	// virtual host routing
+	req, _ := http.NewRequest(http.MethodGet, srv.URL+"/api/status", nil)
+	req.Host = "east.example.org"
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var env envelope
+	_ = json.NewDecoder(resp.Body).Decode(&env)
+	resp.Body.Close()
+	st = state{}
+	_ = json.Unmarshal(env.Data, &st)
+	if st.State.NodeID != 3001 || len(st.State.Links) != 1 {
+		t.Fatalf("east host status: %+v", st)
+	}
+
+	var brand struct {
+		Title    string `json:"title"`
+		ClubName string `json:"club_name"`
+	}
+	if code := apiRequest(t, http.MethodGet, srv.URL+"/h/west/api/branding", "", &brand); code != 200 || brand.Title != "West Hub" || brand.ClubName != "West Hub" {
+		t.Fatalf("west branding: code=%d %+v", code, brand)
+	}
+
+	var tenants struct {
+		Tenants []struct {
+			ID   string `json:"id"`
+			Path string `json:"path"`
+		} `json:"tenants"`
+	}
+	if code := apiRequest(t, http.MethodGet, srv.URL+"/api/tenants", "", &tenants); code != 200 || len(tenants.Tenants) != 2 || tenants.Tenants[0].Path != "/h/west/" {
+		t.Fatalf("tenant list: code=%d %+v", code, tenants)
+	}
+
+	// tenant admins reach their own tenant's admin routes only; operators reach all
+	do := func(path, email string) int {
+		token, _ := auth.GenerateJWT(email, roles[email], time.Hour, "test-secret")
+		req, _ := http.NewRequest(http.MethodGet, srv.URL+path, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("%s: %v", path, err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+	for _, tc := range []struct {
+		path, email string
+		want        int
+	}{
+		{"/h/west/api/admin/tenant", "wes@example.org", 204},
+		{"/h/east/api/admin/tenant", "wes@example.org", 403},
+		{"/h/west/api/admin/instance", "wes@example.org", 403},
+		{"/api/admin/tenant", "wes@example.org", 403},
+		{"/h/east/api/admin/tenant", "op@example.org", 204},
+		{"/api/admin/instance", "op@example.org", 204},
+	} {
+		if got := do(tc.path, tc.email); got != tc.want {
+			t.Errorf("%s as %s: got %d, want %d", tc.path, tc.email, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package tests
+
+import (
+	"context"
+	"testing"
+
+	"github.com/dbehnke/allstar-nexus/backend/models"
+	"github.com/dbehnke/allstar-nexus/backend/repository"
+	"github.com/dbehnke/allstar-nexus/internal/textnode"
+)
+
+func TestTextNodeRepo_SurvivesRestart(t *testing.T) {
+	gdb := setUpGormTestDB(t)
+	if err := gdb.AutoMigrate(&models.TextNode{}); err != nil {
+		t.Fatalf("automigrate: %v", err)
+	}
+	repo := repository.NewTextNodeRepo(gdb)
+
+	// First run: one callsign is renumbered because its hash was taken
+	h := textnode.Hash("W1ABC")
+	for _, e := range []textnode.Entry{
+		{ID: h, Name: "OTHER", Hash: h},
+		{ID: h - 1, Name: "W1ABC", Hash: h},
+	} {
+		if err := repo.SaveTextNode(e); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// A repeated save never moves an existing ID
+	if err := repo.SaveTextNode(textnode.Entry{ID: h - 5, Name: "W1ABC", Hash: h}); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := repo.Entries(context.Background())
+	if err != nil {
+		t.Fatalf("Entries: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("entries = %+v, want 2", entries)
+	}
+
+	// Second run: the fresh registry restores the renumbered ID even though W1ABC
+	// is seen before OTHER
+	reg := textnode.NewRegistry()
+	reg.Load(entries)
+	if id := reg.ID("W1ABC"); id != h-1 {
+		t.Fatalf("ID(W1ABC) after restart = %d, want %d", id, h-1)
+	}
+	if name, ok := reg.Name(h); !ok || name != "OTHER" {
+		t.Fatalf("Name(%d) after restart = %q, %v", h, name, ok)
+	}
+}
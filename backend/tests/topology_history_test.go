@@ -0,0 +1,131 @@
+package tests
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/dbehnke/allstar-nexus/backend/api"
+	"github.com/dbehnke/allstar-nexus/backend/models"
+	"github.com/dbehnke/allstar-nexus/backend/repository"
+	"github.com/dbehnke/allstar-nexus/internal/core"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func historyGraph(at time.Time, remotes ...int) core.Topology {
+	t := core.Topology{Nodes: []core.TopologyNode{{Node: 1000, Local: true}}, UpdatedAt: at}
+	for _, n := range remotes {
+		t.Nodes = append(t.Nodes, core.TopologyNode{Node: n, Hops: 1, Callsign: "W1AW"})
+		t.Edges = append(t.Edges, core.TopologyEdge{From: 1000, To: n, Kind: core.EdgeDirect, Mode: "T"})
+	}
+	return t
+}
+
+func remotesOf(topo core.Topology) []int {
+	var out []int
+	for _, e := range topo.Edges {
+		out = append(out, e.To)
+	}
+	return out
+}
+
+func TestTopologyHistoryReconstructsPastGraphs(t *testing.T) {
+	gdb, err := gorm.Open(sqlite.New(sqlite.Config{DriverName: "sqlite", DSN: filepath.Join(t.TempDir(), "test.db")}), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open gorm sqlite: %v", err)
+	}
+	if err := gdb.AutoMigrate(&models.TopologySnapshot{}); err != nil {
+		t.Fatalf("automigrate: %v", err)
+	}
+	repo := repository.NewTopologyHistoryRepo(gdb)
+	history := core.NewTopologyHistory(repo, time.Minute, 2, 0)
+	ctx := context.Background()
+
+	t0 := time.Date(2025, 3, 1, 20, 0, 0, 0, time.UTC)
+	steps := [][]int{{2001}, {2001, 2002}, {2001, 2002}, {2002}, {2002, 2003}}
+	for i, remotes := range steps {
+		history.Observe(historyGraph(t0.Add(time.Duration(i)*time.Hour), remotes...))
+		if err := history.Flush(ctx); err != nil {
+			t.Fatalf("flush %d: %v", i, err)
+		}
+	}
+	var rows []models.TopologySnapshot
+	gdb.Order("id").Find(&rows)
+	// The unchanged graph at step 2 is not written; keyframe every 2 diffs
+	if len(rows) != 4 || !rows[0].Keyframe || rows[1].Keyframe || rows[2].Keyframe || !rows[3].Keyframe {
+		t.Fatalf("unexpected rows: %+v", rows)
+	}
+
+	if _, _, ok, err := history.At(ctx, t0.Add(-time.Minute)); err != nil || ok {
+		t.Fatalf("before first entry: ok=%v err=%v", ok, err)
+	}
+	cases := []struct {
+		at   time.Duration
+		want []int
+	}{
+		{30 * time.Minute, []int{2001}},
+		{2*time.Hour + 30*time.Minute, []int{2001, 2002}},
+		{3 * time.Hour, []int{2002}},
+		{10 * time.Hour, []int{2002, 2003}},
+	}
+	for _, c := range cases {
+		topo, _, ok, err := history.At(ctx, t0.Add(c.at))
+		if err != nil || !ok {
+			t.Fatalf("at +%v: ok=%v err=%v", c.at, ok, err)
+		}
+		got := remotesOf(topo)
+		if len(got) != len(c.want) {
+			t.Fatalf("at +%v: remotes=%v want %v", c.at, got, c.want)
+		}
+		for i := range got {
+			if got[i] != c.want[i] {
+				t.Fatalf("at +%v: remotes=%v want %v", c.at, got, c.want)
+			}
+		}
+		for _, n := range topo.Nodes {
+			if n.Callsign != "" {
+				t.Fatalf("history stored astdb details: %+v", n)
+			}
+		}
+	}
+
+	// Pruning keeps the keyframe that later diffs depend on
+	if n, err := repo.Prune(ctx, t0.Add(2*time.Hour)); err != nil || n != 0 {
+		t.Fatalf("prune before second keyframe: n=%d err=%v", n, err)
+	}
+	if n, err := repo.Prune(ctx, t0.Add(5*time.Hour)); err != nil || n != 3 {
+		t.Fatalf("prune after second keyframe: n=%d err=%v", n, err)
+	}
+	if topo, _, ok, _ := history.At(ctx, t0.Add(10*time.Hour)); !ok || len(topo.Edges) != 2 {
+		t.Fatalf("latest graph lost after prune: ok=%v %+v", ok, topo)
+	}
+
+	// HTTP: labels come from the live lookup
+	ts := core.NewTopologyService()
+	ts.SetLookup(func(node int) *core.NodeInfo { return &core.NodeInfo{Node: node, Callsign: "K8XYZ"} })
+	apiLayer := api.New(gdb, "test-secret", time.Hour)
+	apiLayer.SetTopology(ts)
+	apiLayer.SetTopologyHistory(history)
+	srv := httptest.NewServer(http.HandlerFunc(apiLayer.TopologyHistoryHandler))
+	t.Cleanup(srv.Close)
+
+	var body struct {
+		Topology core.Topology `json:"topology"`
+	}
+	if code := apiRequest(t, http.MethodGet, srv.URL+"?at="+t0.Add(10*time.Hour).Format(time.RFC3339), "", &body); code != 200 {
+		t.Fatalf("history: code=%d", code)
+	}
+	if len(body.Topology.Nodes) != 3 || body.Topology.Nodes[1].Callsign != "K8XYZ" {
+		t.Fatalf("history response: %+v", body.Topology)
+	}
+	if code := apiRequest(t, http.MethodGet, srv.URL+"?at=yesterday", "", nil); code != 400 {
+		t.Fatalf("bad at: code=%d", code)
+	}
+	if code := apiRequest(t, http.MethodGet, srv.URL+"?at="+t0.Format(time.RFC3339), "", nil); code != 404 {
+		t.Fatalf("pruned range: code=%d", code)
+	}
+}
@@ -0,0 +1,109 @@
+package tests
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/dbehnke/allstar-nexus/backend/models"
+	"github.com/dbehnke/allstar-nexus/backend/repository"
+)
+
+func countTransmissions(t *testing.T, repo *repository.TransmissionLogRepository) []models.TransmissionLog {
+	t.Helper()
+	logs, err := repo.GetRecentLogs(100)
+	if err != nil {
+		t.Fatalf("GetRecentLogs: %v", err)
+	}
+	return logs
+}
+
+func TestTransmissionLedger_DeduplicatesRepeatedReports(t *testing.T) {
+	gdb := setUpGormTestDB(t)
+	repo := repository.NewTransmissionLogRepository(gdb)
+	start := time.Now().UTC().Truncate(time.Second)
+
+	// Same key-up reported by ALINKS and by a poll, with slightly different edges
+	if err := repo.LogTransmission(1001, 2001, "K1AAA", start, start.Add(30*time.Second), 30); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.LogTransmission(1001, 2001, "K1AAA", start.Add(800*time.Millisecond), start.Add(31*time.Second), 31); err != nil {
+		t.Fatal(err)
+	}
+	// Exact retry
+	if err := repo.LogTransmission(1001, 2001, "K1AAA", start, start.Add(30*time.Second), 30); err != nil {
+		t.Fatal(err)
+	}
+
+	logs := countTransmissions(t, repo)
+	if len(logs) != 1 {
+		t.Fatalf("expected 1 transmission, got %d", len(logs))
+	}
+	if logs[0].DurationSeconds != 31 || !logs[0].TimestampEnd.Equal(start.Add(31*time.Second)) {
+		t.Fatalf("merged row = %+v, want widest report", logs[0])
+	}
+}
+
+func TestTransmissionLedger_KeepsDistinctTransmissions(t *testing.T) {
+	gdb := setUpGormTestDB(t)
+	repo := repository.NewTransmissionLogRepository(gdb)
+	start := time.Now().UTC().Truncate(time.Second)
+
+	// Overlapping starts with very different ends are separate transmissions
+	_ = repo.LogTransmission(1001, 2001, "K1AAA", start, start.Add(40*time.Second), 40)
+	_ = repo.LogTransmission(1001, 2001, "K1AAA", start.Add(time.Second), start.Add(3*time.Second), 2)
+	// Same time on another adjacent node or source
+	_ = repo.LogTransmission(1001, 2002, "K1BBB", start, start.Add(40*time.Second), 40)
+	_ = repo.LogTransmission(1002, 2001, "K1AAA", start, start.Add(40*time.Second), 40)
+
+	if got := len(countTransmissions(t, repo)); got != 4 {
+		t.Fatalf("expected 4 transmissions, got %d", got)
+	}
+}
+
+func TestTransmissionLedger_ReconcileLegacyRows(t *testing.T) {
+	gdb := setUpGormTestDB(t)
+	repo := repository.NewTransmissionLogRepository(gdb)
+	start := time.Now().UTC().Truncate(time.Second)
+
+	// Rows written before the ledger existed have no key and may be duplicated
+	for _, r := range []models.TransmissionLog{
+		{SourceID: 1001, AdjacentLinkID: 2001, Callsign: "unknown", TimestampStart: start, TimestampEnd: start.Add(20 * time.Second), DurationSeconds: 20},
+		{SourceID: 1001, AdjacentLinkID: 2001, Callsign: "K1AAA", TimestampStart: start.Add(time.Second), TimestampEnd: start.Add(21 * time.Second), DurationSeconds: 20},
+		{SourceID: 1001, AdjacentLinkID: 2001, Callsign: "K1AAA", TimestampStart: start.Add(time.Minute), TimestampEnd: start.Add(70 * time.Second), DurationSeconds: 10},
+	} {
+		row := r
+		if err := repo.Create(&row); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	merged, err := repo.ReconcileLedger(context.Background())
+	if err != nil {
+		t.Fatalf("ReconcileLedger: %v", err)
+	}
+	if merged != 1 {
+		t.Fatalf("merged = %d, want 1", merged)
+	}
+	logs := countTransmissions(t, repo)
+	if len(logs) != 2 {
+		t.Fatalf("expected 2 transmissions after reconcile, got %d", len(logs))
+	}
+	for _, l := range logs {
+		if l.LedgerKey == nil {
+			t.Fatalf("row %d has no ledger key after reconcile", l.ID)
+		}
+		if l.Callsign != "K1AAA" {
+			t.Fatalf("row %d callsign = %q, want known callsign kept", l.ID, l.Callsign)
+		}
+	}
+
+	// Second run is a no-op, and a late duplicate report still merges
+	if merged, err := repo.ReconcileLedger(context.Background()); err != nil || merged != 0 {
+		t.Fatalf("second reconcile = %d, %v", merged, err)
+	}
+	_ = repo.LogTransmission(1001, 2001, "K1AAA", start.Add(time.Minute), start.Add(70*time.Second), 10)
+	if got := len(countTransmissions(t, repo)); got != 2 {
+		t.Fatalf("expected 2 transmissions after duplicate report, got %d", got)
+	}
+}
@@ -0,0 +1,176 @@
+package tests
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dbehnke/allstar-nexus/backend/database"
+	"github.com/dbehnke/allstar-nexus/backend/gamification"
+	"github.com/dbehnke/allstar-nexus/backend/models"
+	"github.com/dbehnke/allstar-nexus/backend/repository"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+func openTxWindowDB(tb testing.TB) *gorm.DB {
+	tb.Helper()
+	db, err := database.Open(filepath.Join(tb.TempDir(), "tx.db"))
+	if err != nil {
+		tb.Fatalf("open db: %v", err)
+	}
+	tb.Cleanup(func() { _ = db.CloseSafe() })
+	if err := db.AutoMigrate(
+		&models.CallsignProfile{},
+		&models.LevelConfig{},
+		&models.TransmissionLog{},
+		&models.XPActivityLog{},
+		&models.TallyState{},
+		&models.TallyWindowMark{},
+	); err != nil {
+		tb.Fatalf("automigrate: %v", err)
+	}
+	return db.DB
+}
+
+func TestStreamLogsBetweenPagesInStartOrder(t *testing.T) {
+	gdb := openTxWindowDB(t)
+	repo := repository.NewTransmissionLogRepository(gdb)
+
+	// Report times in a non-UTC zone; storage and bounds are both normalized to UTC
+	zone := time.FixedZone("EST", -5*3600)
+	base := time.Date(2025, 3, 1, 7, 0, 0, 0, zone)
+	for i := 0; i < 7; i++ {
+		start := base.Add(time.Duration(i/2) * time.Minute) // pairs share a start time
+		if err := repo.LogTransmission(100, 200+i, fmt.Sprintf("W%dXX", i%3), start, start.Add(5*time.Second), 5); err != nil {
+			t.Fatalf("log: %v", err)
+		}
+	}
+	// Outside the window on both sides
+	_ = repo.LogTransmission(100, 300, "W0XX", base.Add(-time.Second), base.Add(4*time.Second), 5)
+	_ = repo.LogTransmission(100, 301, "W0XX", base.Add(10*time.Minute), base.Add(10*time.Minute+5*time.Second), 5)
+
+	var got []models.TransmissionLog
+	pages := 0
+	err := repo.StreamLogsBetween(context.Background(), base, base.Add(10*time.Minute), 2, func(page []models.TransmissionLog) error {
+		pages++
+		got = append(got, page...)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("stream: %v", err)
+	}
+	if len(got) != 7 || pages != 4 {
+		t.Fatalf("got %d rows in %d pages, want 7 in 4", len(got), pages)
+	}
+	seen := map[uint]bool{}
+	for i, row := range got {
+		if seen[row.ID] {
+			t.Fatalf("row %d returned twice", row.ID)
+		}
+		seen[row.ID] = true
+		if i > 0 && row.TimestampStart.Before(got[i-1].TimestampStart) {
+			t.Fatalf("rows out of order at %d", i)
+		}
+	}
+
+	groups, err := repo.GetLogsBetween(base.UTC(), base.Add(10*time.Minute).UTC())
+	if err != nil {
+		t.Fatalf("between: %v", err)
+	}
+	if len(groups["W0XX"]) != 3 || len(groups["W1XX"]) != 2 || len(groups["W2XX"]) != 2 {
+		t.Fatalf("unexpected grouping: %v", groups)
+	}
+	since, err := repo.GetLogsSince(base)
+	if err != nil || len(since["W0XX"]) != 4 {
+		t.Fatalf("since: %d rows for W0XX (err=%v), want 4", len(since["W0XX"]), err)
+	}
+}
+
+func TestTxWindowQueriesUseIndexes(t *testing.T) {
+	gdb := openTxWindowDB(t)
+	plan := func(query string) string {
+		rows, err := gdb.Raw("EXPLAIN QUERY PLAN " + query).Rows()
+		if err != nil {
+			t.Fatalf("explain: %v", err)
+		}
+		defer rows.Close()
+		var details []string
+		for rows.Next() {
+			var id, parent, unused int
+			var detail string
+			if err := rows.Scan(&id, &parent, &unused, &detail); err != nil {
+				t.Fatalf("scan: %v", err)
+			}
+			details = append(details, detail)
+		}
+		return strings.Join(details, "; ")
+	}
+
+	window := plan("SELECT * FROM transmission_logs WHERE timestamp_start >= '2025' AND timestamp_start < '2026' ORDER BY timestamp_start, id LIMIT 10")
+	if !strings.Contains(window, "idx_transmission_logs_timestamp_start") {
+		t.Fatalf("window scan does not use the start index: %s", window)
+	}
+	perCallsign := plan("SELECT * FROM transmission_logs WHERE callsign = 'W1AW' AND timestamp_start >= '2025' ORDER BY timestamp_start")
+	if !strings.Contains(perCallsign, "idx_tx_callsign_start") || strings.Contains(perCallsign, "TEMP B-TREE") {
+		t.Fatalf("per-callsign scan does not use the composite index: %s", perCallsign)
+	}
+}
+
+// seedTxLog bulk-inserts n transmissions spread over the 30 days before end, in the
+// same text format the driver writes, without going through the ORM.
+func seedTxLog(tb testing.TB, gdb *gorm.DB, n int, end time.Time) {
+	tb.Helper()
+	spacing := (30 * 24 * time.Hour / time.Duration(n)).Seconds()
+	err := gdb.Exec(`
+		WITH RECURSIVE seq(i) AS (SELECT 0 UNION ALL SELECT i + 1 FROM seq WHERE i + 1 < ?)
+		INSERT INTO transmission_logs (source_id, adjacent_link_id, callsign, timestamp_start, timestamp_end, duration_seconds, ledger_key, created_at)
+		SELECT 1000, 2000 + i % 50, 'BENCH' || (i % 500),
+			strftime('%Y-%m-%d %H:%M:%S', ?, printf('%+d seconds', 5 - CAST((? - i) * ? AS INTEGER))) || ' +0000 UTC',
+			strftime('%Y-%m-%d %H:%M:%S', ?, printf('%+d seconds', 10 - CAST((? - i) * ? AS INTEGER))) || ' +0000 UTC',
+			5, 'bench:' || i, ?
+		FROM seq`,
+		n, end.UTC().Format("2006-01-02 15:04:05"), n, spacing,
+		end.UTC().Format("2006-01-02 15:04:05"), n, spacing, end.UTC()).Error
+	if err != nil {
+		tb.Fatalf("seed: %v", err)
+	}
+}
+
+// BenchmarkTallyWindow_1MRows tallies the most recent 30 minute window of a
+// one-million-row transmission log (~700 rows per window).
+func BenchmarkTallyWindow_1MRows(b *testing.B) {
+	gdb := openTxWindowDB(b)
+	now := time.Now().UTC().Truncate(time.Second)
+	seedTxLog(b, gdb, 1_000_000, now)
+
+	txRepo := repository.NewTransmissionLogRepository(gdb)
+	b.Run("GetLogsBetween", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			groups, err := txRepo.GetLogsBetween(now.Add(-30*time.Minute), now)
+			if err != nil || len(groups) == 0 {
+				b.Fatalf("window: %d groups, err=%v", len(groups), err)
+			}
+		}
+	})
+
+	b.Run("ProcessTally", func(b *testing.B) {
+		levelRepo := repository.NewLevelConfigRepo(gdb)
+		if err := levelRepo.SeedDefaults(context.Background(), gamification.CalculateLevelRequirements()); err != nil {
+			b.Fatalf("seed level config: %v", err)
+		}
+		cfg := &gamification.Config{CapsEnabled: false, RestedEnabled: false, DREnabled: false, KerchunkEnabled: false}
+		// Without Start the service has no persisted cursor, so every run tallies the last interval
+		ts := gamification.NewTallyService(gdb, txRepo, repository.NewCallsignProfileRepo(gdb), levelRepo,
+			repository.NewXPActivityRepo(gdb), repository.NewTallyStateRepo(gdb), cfg, 30*time.Minute, zap.NewNop())
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			if err := ts.ProcessTally(); err != nil {
+				b.Fatalf("tally: %v", err)
+			}
+		}
+	})
+}
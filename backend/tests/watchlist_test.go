@@ -0,0 +1,61 @@
+package tests
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/dbehnke/allstar-nexus/backend/api"
+	"github.com/dbehnke/allstar-nexus/backend/models"
+	"github.com/dbehnke/allstar-nexus/backend/repository"
+	"github.com/dbehnke/allstar-nexus/internal/core"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestAdminWatchlist(t *testing.T) {
+	gdb, err := gorm.Open(sqlite.New(sqlite.Config{DriverName: "sqlite", DSN: filepath.Join(t.TempDir(), "test.db")}), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open gorm sqlite: %v", err)
+	}
+	if err := gdb.AutoMigrate(&models.User{}, &models.WatchedNode{}); err != nil {
+		t.Fatalf("automigrate: %v", err)
+	}
+	repo := repository.NewWatchlistRepo(gdb)
+	watchlist := core.NewWatchlist(core.NewStateManager(), nil, "", 0)
+	apiLayer := api.New(gdb, "test-secret", time.Hour)
+	apiLayer.SetWatchlist(repo, watchlist)
+	srv := httptest.NewServer(http.HandlerFunc(apiLayer.AdminWatchlist))
+	t.Cleanup(srv.Close)
+
+	if code := apiRequest(t, http.MethodPost, srv.URL, `{"node":2001,"label":"Portable","auto_connect":true}`, nil); code != 200 {
+		t.Fatalf("add: code=%d", code)
+	}
+	if code := apiRequest(t, http.MethodPost, srv.URL, `{"node":2001,"label":"Portable 2"}`, nil); code != 200 {
+		t.Fatalf("update: code=%d", code)
+	}
+	if code := apiRequest(t, http.MethodPost, srv.URL, `{"node":-5}`, nil); code != 400 {
+		t.Fatalf("text node should be rejected, got %d", code)
+	}
+
+	var out struct {
+		Nodes  []core.WatchStatus `json:"nodes"`
+		Active bool               `json:"active"`
+	}
+	apiRequest(t, http.MethodGet, srv.URL, "", &out)
+	if !out.Active || len(out.Nodes) != 1 || out.Nodes[0].Label != "Portable 2" || out.Nodes[0].AutoConnect {
+		t.Fatalf("watchlist = %+v", out)
+	}
+
+	if code := apiRequest(t, http.MethodDelete, srv.URL+"?node=2001", "", nil); code != 200 {
+		t.Fatalf("delete: code=%d", code)
+	}
+	if code := apiRequest(t, http.MethodDelete, srv.URL+"?node=2001", "", nil); code != 404 {
+		t.Fatalf("second delete: code=%d", code)
+	}
+	if len(watchlist.Status()) != 0 {
+		t.Fatalf("running watchlist not reloaded: %+v", watchlist.Status())
+	}
+}
@@ -0,0 +1,71 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dbehnke/allstar-nexus/backend/api"
+	"github.com/dbehnke/allstar-nexus/internal/core"
+	"github.com/dbehnke/allstar-nexus/internal/privacy"
+)
+
+type widgetStateStub struct{ events []core.TalkerEvent }
+
+func (s widgetStateStub) TalkerLogSnapshot() any { return s.events }
+func (widgetStateStub) Snapshot() core.NodeState {
+	return core.NodeState{NodeID: 1999, RxKeyed: true, Links: []int{2000, 2001}}
+}
+
+func TestWidgetStateAndPage(t *testing.T) {
+	at := time.Now().Add(-time.Minute)
+	apiLayer := &api.API{Privacy: privacy.DefaultPolicy(), BrandingDefaults: api.Branding{Title: "Test Club"}}
+	apiLayer.SetWidget(time.Second) // raised to the minimum
+	apiLayer.SetStateManager(widgetStateStub{events: []core.TalkerEvent{
+		{At: at, Kind: "TX_START", Node: 2000, Callsign: "W1AW"},
+		{At: at.Add(10 * time.Second), Kind: "TX_STOP", Node: 2000, Callsign: "W1AW", Duration: 10},
+		{At: at.Add(20 * time.Second), Kind: "TX_START", Node: 2001, Callsign: "K8ABC"},
+	}})
+
+	rec := httptest.NewRecorder()
+	apiLayer.WidgetState(rec, httptest.NewRequest(http.MethodGet, "/api/widget/state", nil))
+	if rec.Header().Get("Access-Control-Allow-Origin") != "*" {
+		t.Fatalf("widget state is not embeddable cross-origin")
+	}
+	var env envelope
+	_ = json.Unmarshal(rec.Body.Bytes(), &env)
+	var st map[string]any
+	_ = json.Unmarshal(env.Data, &st)
+	if st["title"] != "Test Club" || st["node_id"].(float64) != 1999 || st["keyed"] != true || st["links"].(float64) != 2 || st["refresh_seconds"].(float64) != 5 {
+		t.Fatalf("state = %v", st)
+	}
+	if cur := st["current_talker"].(map[string]any); cur["callsign"] != "K8ABC" {
+		t.Fatalf("current_talker = %v", cur)
+	}
+	if last := st["last_talker"].(map[string]any); last["callsign"] != "W1AW" || last["duration"].(float64) != 10 {
+		t.Fatalf("last_talker = %v", last)
+	}
+
+	// Restricted anonymous talker history hides talkers entirely
+	apiLayer.SetPrivacyPolicy(privacy.Policy{HideAnonTalkerHistory: true})
+	rec = httptest.NewRecorder()
+	apiLayer.WidgetState(rec, httptest.NewRequest(http.MethodGet, "/api/widget/state", nil))
+	if strings.Contains(rec.Body.String(), "K8ABC") {
+		t.Fatalf("restricted widget leaked talker: %s", rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	apiLayer.Widget(rec, httptest.NewRequest(http.MethodGet, "/widget?refresh=2&theme=dark", nil))
+	page := rec.Body.String()
+	if !strings.HasPrefix(rec.Header().Get("Content-Type"), "text/html") || !strings.Contains(page, "setInterval(poll, 5000 )") || !strings.Contains(page, "Test Club") {
+		t.Fatalf("widget page:\n%s", page)
+	}
+	rec = httptest.NewRecorder()
+	apiLayer.Widget(rec, httptest.NewRequest(http.MethodGet, "/widget?refresh=60", nil))
+	if !strings.Contains(rec.Body.String(), "setInterval(poll, 60000 )") {
+		t.Fatalf("slower refresh not honoured")
+	}
+}
@@ -0,0 +1,81 @@
+package tests
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dbehnke/allstar-nexus/backend/api"
+	"github.com/dbehnke/allstar-nexus/internal/core"
+	"github.com/dbehnke/allstar-nexus/internal/privacy"
+	"github.com/dbehnke/allstar-nexus/internal/web"
+	gws "github.com/gorilla/websocket"
+)
+
+func TestAdminWSClients(t *testing.T) {
+	hub := web.NewHub()
+	hub.SetIdentify(func(r *http.Request) string { return r.URL.Query().Get("token") })
+	apiLayer := &api.API{}
+	apiLayer.SetWSHub(hub)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/ws", hub.HandleWSViewer(core.NewStateManager(), func(r *http.Request) (bool, privacy.Viewer) {
+		if r.URL.Query().Get("token") == "admin@example.com" {
+			return true, privacy.ViewerAdmin
+		}
+		return true, privacy.ViewerAnonymous
+	}))
+	mux.HandleFunc("/api/admin/ws-clients", apiLayer.AdminWSClients)
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	wsURL := "ws" + strings.TrimPrefix(srv.URL, "http") + "/ws"
+	dial := func(query string) *gws.Conn {
+		c, _, err := (&gws.Dialer{}).Dial(wsURL+query, http.Header{"User-Agent": {"test-agent"}})
+		if err != nil {
+			t.Fatalf("dial: %v", err)
+		}
+		t.Cleanup(func() { _ = c.Close() })
+		_ = c.SetReadDeadline(time.Now().Add(2 * time.Second))
+		if _, _, err := c.ReadMessage(); err != nil { // initial STATUS_UPDATE
+			t.Fatalf("read: %v", err)
+		}
+		return c
+	}
+	dial("?token=admin@example.com")
+	anon := dial("")
+
+	var list struct {
+		Clients []web.ClientInfo `json:"clients"`
+		Count   int              `json:"count"`
+	}
+	if code := apiRequest(t, http.MethodGet, srv.URL+"/api/admin/ws-clients", "", &list); code != http.StatusOK || list.Count != 2 {
+		t.Fatalf("list = %d %+v", code, list)
+	}
+	a, b := list.Clients[0], list.Clients[1]
+	if !a.Admin || a.User != "admin@example.com" || a.UserAgent != "test-agent" || a.RemoteAddr != "127.0.0.1" || a.MessagesSent < 2 || a.BytesSent == 0 {
+		t.Fatalf("admin client = %+v", a)
+	}
+	if b.Admin || b.User != "" || b.Viewer != "anonymous" || b.ID <= a.ID {
+		t.Fatalf("anonymous client = %+v", b)
+	}
+
+	if code := apiRequest(t, http.MethodDelete, srv.URL+"/api/admin/ws-clients?id=999", "", nil); code != http.StatusNotFound {
+		t.Fatalf("unknown id = %d, want 404", code)
+	}
+	if code := apiRequest(t, http.MethodDelete, srv.URL+"/api/admin/ws-clients?id="+strconv.FormatUint(b.ID, 10), "", nil); code != http.StatusOK {
+		t.Fatalf("disconnect = %d", code)
+	}
+	_ = anon.SetReadDeadline(time.Now().Add(2 * time.Second))
+	for {
+		if _, _, err := anon.ReadMessage(); err != nil {
+			break // connection dropped
+		}
+	}
+	if hub.Clients()[0].ID != a.ID || len(hub.Clients()) != 1 {
+		t.Fatalf("clients after disconnect = %+v", hub.Clients())
+	}
+}
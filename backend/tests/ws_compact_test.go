@@ -0,0 +1,88 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dbehnke/allstar-nexus/internal/core"
+	"github.com/dbehnke/allstar-nexus/internal/privacy"
+	"github.com/dbehnke/allstar-nexus/internal/web"
+	gws "github.com/gorilla/websocket"
+)
+
+func TestWSCompactProfile(t *testing.T) {
+	hub := web.NewHub()
+	srv := httptest.NewServer(hub.HandleWSViewer(core.NewStateManager(), func(r *http.Request) (bool, privacy.Viewer) {
+		return true, privacy.ViewerAdmin
+	}))
+	t.Cleanup(srv.Close)
+
+	type frame struct {
+		MessageType string          `json:"messageType"`
+		Data        json.RawMessage `json:"data"`
+	}
+	wsURL := "ws" + strings.TrimPrefix(srv.URL, "http")
+	dial := func(query string) *gws.Conn {
+		c, _, err := (&gws.Dialer{}).Dial(wsURL+query, nil)
+		if err != nil {
+			t.Fatalf("dial: %v", err)
+		}
+		t.Cleanup(func() { _ = c.Close() })
+		return c
+	}
+	next := func(c *gws.Conn, messageType string) json.RawMessage {
+		_ = c.SetReadDeadline(time.Now().Add(2 * time.Second))
+		for {
+			var f frame
+			if err := c.ReadJSON(&f); err != nil {
+				t.Fatalf("waiting for %s: %v", messageType, err)
+			}
+			if f.MessageType == messageType {
+				return f.Data
+			}
+		}
+	}
+	full := dial("/ws")
+	compact := dial("/ws?profile=compact")
+	next(full, "TALKER_LOG_SNAPSHOT")
+	if initial := next(compact, "TALKER_LOG_SNAPSHOT"); string(initial) != "[]" && string(initial) != "" {
+		t.Fatalf("compact talker snapshot = %s", initial)
+	}
+
+	states := make(chan core.NodeState, 1)
+	go hub.BroadcastLoop(states)
+	since := time.Unix(1700000000, 0)
+	states <- core.NodeState{NodeID: 1999, TxKeyed: true, Links: []int{2000}, StateVersion: 7, LinksDetailed: []core.LinkInfo{{
+		Node: 2000, ConnectedSince: since, NodeCallsign: "W1AW", NodeDescription: "Newington, CT", Elapsed: "00:10:00",
+		LastTxStart: &since, Mode: "T", TotalTxSeconds: 42,
+	}}}
+	close(states)
+
+	if raw := next(full, "STATUS_UPDATE"); !strings.Contains(string(raw), `"node_description":"Newington, CT"`) {
+		t.Fatalf("full client lost detail: %s", raw)
+	}
+	var got map[string]any
+	raw := next(compact, "STATUS_UPDATE")
+	_ = json.Unmarshal(raw, &got)
+	if got["n"].(float64) != 1999 || got["tx"] != true || got["sv"].(float64) != 7 {
+		t.Fatalf("compact state = %s", raw)
+	}
+	link := got["ld"].([]any)[0].(map[string]any)
+	if link["n"].(float64) != 2000 || link["c"] != "W1AW" || link["cs"].(float64) != 1700000000 || link["t"].(float64) != 42 {
+		t.Fatalf("compact link = %v", link)
+	}
+	for _, dropped := range []string{"Newington", "00:10:00", "node_id", "last_tx_start"} {
+		if strings.Contains(string(raw), dropped) {
+			t.Fatalf("compact payload still contains %q: %s", dropped, raw)
+		}
+	}
+
+	clients := hub.Clients()
+	if len(clients) != 2 || clients[0].Profile != "full" || clients[1].Profile != "compact" {
+		t.Fatalf("clients = %+v", clients)
+	}
+}
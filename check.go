@@ -0,0 +1,172 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"os"
+	"time"
+
+	"github.com/dbehnke/allstar-nexus/backend/config"
+	"github.com/dbehnke/allstar-nexus/backend/database"
+	"github.com/dbehnke/allstar-nexus/backend/gamification"
+	"github.com/dbehnke/allstar-nexus/internal/ami"
+)
+
+// checkReport accumulates readiness results for `allstar-nexus check`.
+type checkReport struct {
+	failures int
+	warnings int
+}
+
+func (r *checkReport) pass(name, format string, args ...any) {
+	fmt.Printf("  [PASS] %-14s %s\n", name, fmt.Sprintf(format, args...))
+}
+
+func (r *checkReport) warn(name, format string, args ...any) {
+	r.warnings++
+	fmt.Printf("  [WARN] %-14s %s\n", name, fmt.Sprintf(format, args...))
+}
+
+func (r *checkReport) fail(name, format string, args ...any) {
+	r.failures++
+	fmt.Printf("  [FAIL] %-14s %s\n", name, fmt.Sprintf(format, args...))
+}
+
+// runCheck validates configuration and environment without starting the server.
+// It returns the process exit code: 0 when ready, 1 when any check failed.
+func runCheck(configPath string) int {
+	r := &checkReport{}
+	fmt.Println("Allstar Nexus readiness check")
+	fmt.Println()
+
+	if err := config.Validate(configPath); err != nil {
+		r.fail("config", "%v", err)
+		return r.summary()
+	}
+	cfg := config.Load(configPath)
+	r.pass("config", "parsed successfully")
+
+	issues := config.Lint(cfg)
+	for _, is := range issues {
+		if is.Severity == config.SeverityError {
+			r.fail("settings", "%s", is)
+		} else {
+			r.warn("settings", "%s", is)
+		}
+	}
+	if len(issues) == 0 {
+		r.pass("settings", "no problems found")
+	}
+
+	if cfg.Gamification.Enabled {
+		if len(cfg.Gamification.LevelGroupings) > 0 {
+			if err := gamification.ValidateGroupings(cfg.Gamification.LevelGroupings); err != nil {
+				r.fail("gamification", "level groupings: %v", err)
+			} else {
+				r.pass("gamification", "%d level groupings valid", len(cfg.Gamification.LevelGroupings))
+			}
+		}
+		if _, err := gamification.NewCapSchedule(cfg.Timezone, cfg.Gamification.XPCaps.ResetHour, cfg.Gamification.XPCaps.WeekStarts); err != nil {
+			r.fail("gamification", "xp cap schedule: %v", err)
+		}
+	}
+
+	// Port availability
+	if ln, err := net.Listen("tcp", ":"+cfg.Port); err != nil {
+		r.fail("http port", "cannot bind :%s (%v) - is another instance running?", cfg.Port, err)
+	} else {
+		_ = ln.Close()
+		r.pass("http port", ":%s is available", cfg.Port)
+	}
+
+	// Data directory and database writability
+	if err := checkDirWritable(cfg.DataDir); err != nil {
+		r.fail("data dir", "%s: %v (on a read-only root filesystem mount a volume here)", cfg.DataDir, err)
+	} else {
+		r.pass("data dir", "%s is writable", cfg.DataDir)
+	}
+	if err := checkDBWritable(cfg.DBPath); err != nil {
+		r.fail("database", "%s: %v", cfg.DBPath, err)
+	} else {
+		r.pass("database", "%s is writable", cfg.DBPath)
+	}
+
+	if _, err := os.Stat(cfg.AstDBPath); err != nil {
+		r.warn("astdb", "%s not found; it will be downloaded on first start", cfg.AstDBPath)
+	} else {
+		r.pass("astdb", "%s present", cfg.AstDBPath)
+	}
+
+	// AMI connectivity
+	if !cfg.AMIEnabled {
+		r.warn("ami", "disabled (ami_enabled: false); live node data will not be available")
+	} else {
+		var tlsCfg *tls.Config
+		if cfg.AMITLS {
+			var err error
+			if tlsCfg, err = ami.NewTLSConfig(cfg.AMIHost, cfg.AMITLSSkipVerify, cfg.AMITLSCAFile); err != nil {
+				r.fail("ami", "tls: %v", err)
+				return r.summary()
+			}
+			if cfg.AMITLSSkipVerify {
+				r.warn("ami", "ami_tls_skip_verify is enabled; the AMI certificate is not verified")
+			}
+		}
+		tunnel, err := amiSSHTunnel(cfg.AMISSH)
+		if err != nil {
+			r.fail("ami", "%v", err)
+			return r.summary()
+		}
+		if tunnel != nil && cfg.AMISSH.InsecureIgnoreHostKey {
+			r.warn("ami", "ami_ssh.insecure_ignore_host_key is enabled; the SSH host key is not verified")
+		}
+		via := ""
+		if tunnel != nil {
+			via = " via SSH " + tunnel.Addr()
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+		banner, err := ami.ProbeLogin(ctx, cfg.AMIHost, cfg.AMIPort, cfg.AMIUser, cfg.AMIPassword, tlsCfg, tunnel)
+		cancel()
+		if err != nil {
+			r.fail("ami", "%s:%d%s: %v", cfg.AMIHost, cfg.AMIPort, via, err)
+		} else {
+			r.pass("ami", "logged in to %s:%d%s as %s (%s)", cfg.AMIHost, cfg.AMIPort, via, cfg.AMIUser, banner)
+		}
+	}
+
+	return r.summary()
+}
+
+func (r *checkReport) summary() int {
+	fmt.Println()
+	if r.failures > 0 {
+		fmt.Printf("Result: NOT READY (%d failure(s), %d warning(s))\n", r.failures, r.warnings)
+		return 1
+	}
+	fmt.Printf("Result: READY (%d warning(s))\n", r.warnings)
+	return 0
+}
+
+// checkDBWritable opens the SQLite database and performs a create/drop round trip.
+func checkDirWritable(dir string) error {
+	f, err := os.CreateTemp(dir, ".nexus-selfcheck-*")
+	if err != nil {
+		return err
+	}
+	_ = f.Close()
+	return os.Remove(f.Name())
+}
+
+func checkDBWritable(path string) error {
+	db, err := database.Open(path)
+	if err != nil {
+		return fmt.Errorf("open: %w", err)
+	}
+	defer func() { _ = db.CloseSafe() }()
+	if err := db.Exec("CREATE TABLE IF NOT EXISTS nexus_selfcheck (id INTEGER)").Error; err != nil {
+		return fmt.Errorf("write: %w", err)
+	}
+	return db.Exec("DROP TABLE nexus_selfcheck").Error
+}
@@ -13,6 +13,15 @@ export const cfg = Object.assign({
 
 import { logger } from './utils/logger'
 
+// When served under a tenant path (/h/{id}/) on a multi-tenant instance, API and
+// websocket URLs are prefixed with it so the server scopes them to that hub.
+export const tenantBase = (typeof location !== 'undefined' && (location.pathname.match(/^\/h\/[a-z0-9][a-z0-9-]*(?=\/|$)/) || [''])[0]) || ''
+
+// Prefix root-relative /api/ URLs with the tenant base; other URLs pass through.
+export function withTenantBase(url) {
+  return tenantBase && typeof url === 'string' && url.startsWith('/api/') ? tenantBase + url : url
+}
+
 // Exponential backoff websocket connector with jitter.
 export function connectWS({ onMessage, onStatus, tokenProvider, maxDelay = 15000 }) {
   let attempt = 0;
@@ -27,7 +36,7 @@ export function connectWS({ onMessage, onStatus, tokenProvider, maxDelay = 15000
     if (typeof cfg.WS_PATH === 'string' && (cfg.WS_PATH.startsWith('ws://') || cfg.WS_PATH.startsWith('wss://'))) {
       url = `${cfg.WS_PATH}?token=${token}`;
     } else {
-      url = `${protocol}//${location.host}${cfg.WS_PATH}?token=${token}`;
+      url = `${protocol}//${location.host}${tenantBase}${cfg.WS_PATH}?token=${token}`;
     }
     const ws = new WebSocket(url);
     onStatus && onStatus('connecting');
@@ -2,6 +2,13 @@ import { createApp } from 'vue'
 import { createPinia } from 'pinia'
 import router from './router'
 import App from './App.vue'
+import { tenantBase, withTenantBase } from './env'
+
+// Multi-tenant hosting: route the app's API calls through the tenant's path
+if (tenantBase && typeof window !== 'undefined' && window.fetch) {
+	const fetch = window.fetch.bind(window)
+	window.fetch = (input, init) => fetch(withTenantBase(input), init)
+}
 
 // In E2E TEST_MODE, load dev test helpers early so tests can inject WS envelopes
 try {
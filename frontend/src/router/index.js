@@ -1,4 +1,5 @@
 import { createRouter, createWebHistory } from 'vue-router'
+import { tenantBase } from '../env'
 import Dashboard from '../views/Dashboard.vue'
 // Removed NodeStatus route; replaced with Talker Log
 import NodeLookup from '../views/NodeLookup.vue'
@@ -36,7 +37,7 @@ const routes = [
 ]
 
 const router = createRouter({
-  history: createWebHistory('/'),
+  history: createWebHistory(tenantBase + '/'),
   routes
 })
 
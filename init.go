@@ -0,0 +1,250 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dbehnke/allstar-nexus/backend/api"
+	"github.com/dbehnke/allstar-nexus/backend/auth"
+	"github.com/dbehnke/allstar-nexus/backend/config"
+	"github.com/dbehnke/allstar-nexus/backend/database"
+	"github.com/dbehnke/allstar-nexus/backend/models"
+	"github.com/dbehnke/allstar-nexus/backend/repository"
+	"github.com/dbehnke/allstar-nexus/internal/ami"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+// wizard reads answers line by line from in and writes prompts to out.
+type wizard struct {
+	in  *bufio.Reader
+	out io.Writer
+	eof bool
+}
+
+func (w *wizard) ask(prompt, def string) string {
+	if def != "" {
+		fmt.Fprintf(w.out, "%s [%s]: ", prompt, def)
+	} else {
+		fmt.Fprintf(w.out, "%s: ", prompt)
+	}
+	line, err := w.in.ReadString('\n')
+	if err != nil && line == "" {
+		// EOF: fall back to the default so scripted input can't loop forever
+		fmt.Fprintln(w.out)
+		w.eof = true
+		return def
+	}
+	if line = strings.TrimSpace(line); line == "" {
+		return def
+	}
+	return line
+}
+
+func (w *wizard) confirm(prompt string, def bool) bool {
+	d := "y/N"
+	if def {
+		d = "Y/n"
+	}
+	for {
+		ans := w.ask(prompt, d)
+		if ans == d || w.eof {
+			return def
+		}
+		switch strings.ToLower(ans) {
+		case "y", "yes":
+			return true
+		case "n", "no":
+			return false
+		}
+		fmt.Fprintln(w.out, "  please answer y or n")
+	}
+}
+
+func (w *wizard) askInt(prompt string, def, lo, hi int) int {
+	for {
+		v, err := strconv.Atoi(w.ask(prompt, strconv.Itoa(def)))
+		if err == nil && v >= lo && v <= hi {
+			return v
+		}
+		if w.eof {
+			return def
+		}
+		fmt.Fprintf(w.out, "  enter a number between %d and %d\n", lo, hi)
+	}
+}
+
+// runInit interviews the user, writes a complete config file and bootstraps the first
+// superadmin account. It returns the process exit code.
+func runInit(configPath string, in io.Reader, out io.Writer) int {
+	w := &wizard{in: bufio.NewReader(in), out: out}
+	if configPath == "" {
+		configPath = "config.yaml"
+	}
+	fmt.Fprintln(out, "Allstar Nexus setup")
+	fmt.Fprintln(out, "Press Enter to accept the [default] shown for each question.")
+	fmt.Fprintln(out)
+
+	if _, err := os.Stat(configPath); err == nil {
+		if !w.confirm(fmt.Sprintf("%s already exists. Overwrite it?", configPath), false) {
+			fmt.Fprintln(out, "aborted; existing configuration left untouched")
+			return 1
+		}
+	}
+
+	a := config.SetupAnswers{AllowAnonDashboard: true}
+	a.Title = w.ask("Dashboard title", "Allstar Nexus")
+	a.Port = strconv.Itoa(w.askInt("HTTP port", 8080, 1, 65535))
+	a.DBPath = w.ask("Database path", "data/allstar.db")
+	a.Timezone = w.ask("Timezone for daily/weekly resets (IANA name, blank = system local)", "")
+
+	for len(a.Nodes) == 0 {
+		if w.eof {
+			return abortInit(out)
+		}
+		raw := w.ask("AllStar node number(s), comma separated", "")
+		for _, f := range strings.FieldsFunc(raw, func(r rune) bool { return r == ',' || r == ' ' }) {
+			n, err := strconv.Atoi(f)
+			if err != nil || n <= 0 {
+				fmt.Fprintf(out, "  %q is not a valid node number\n", f)
+				a.Nodes = nil
+				break
+			}
+			a.Nodes = append(a.Nodes, n)
+		}
+	}
+
+	a.AMIEnabled = w.confirm("Connect to Asterisk AMI for live node data?", true)
+	a.AMIHost, a.AMIPort, a.AMIUser = "127.0.0.1", 5038, "admin"
+	if a.AMIEnabled {
+		for {
+			a.AMIHost = w.ask("AMI host", a.AMIHost)
+			a.AMIPort = w.askInt("AMI port", a.AMIPort, 1, 65535)
+			a.AMIUser = w.ask("AMI username (from manager.conf)", a.AMIUser)
+			a.AMIPassword = w.ask("AMI secret", a.AMIPassword)
+			fmt.Fprint(out, "  testing AMI login... ")
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			banner, err := ami.ProbeLogin(ctx, a.AMIHost, a.AMIPort, a.AMIUser, a.AMIPassword, nil, nil)
+			cancel()
+			if err == nil {
+				fmt.Fprintf(out, "ok (%s)\n", banner)
+				break
+			}
+			fmt.Fprintf(out, "failed: %v\n", err)
+			if w.eof || !w.confirm("Re-enter AMI settings?", true) {
+				fmt.Fprintln(out, "  keeping these settings; run `allstar-nexus check` after fixing AMI")
+				break
+			}
+		}
+	}
+
+	a.AllowAnonDashboard = w.confirm("Allow anonymous (read-only) dashboard access?", true)
+	a.GamificationEnabled = w.confirm("Enable gamification (XP, levels, scoreboard)?", false)
+
+	fmt.Fprintln(out)
+	fmt.Fprintln(out, "Create the first administrator (superadmin) account")
+	email := ""
+	for email == "" || !strings.Contains(email, "@") {
+		if w.eof {
+			return abortInit(out)
+		}
+		email = strings.ToLower(w.ask("Admin email", ""))
+	}
+	password := ""
+	for {
+		if w.eof {
+			return abortInit(out)
+		}
+		password = w.ask("Admin password (min 8 chars)", "")
+		if err := api.ValidatePassword(password); err != nil {
+			fmt.Fprintf(out, "  %v\n", err)
+			continue
+		}
+		if w.ask("Confirm password", "") != password {
+			fmt.Fprintln(out, "  passwords do not match")
+			continue
+		}
+		break
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		fmt.Fprintf(out, "failed to generate jwt secret: %v\n", err)
+		return 1
+	}
+	a.JWTSecret = hex.EncodeToString(secret)
+
+	// Config contains AMI and JWT secrets: keep it private to the service user.
+	if dir := filepath.Dir(configPath); dir != "." {
+		_ = os.MkdirAll(dir, 0o755)
+	}
+	if err := os.WriteFile(configPath, []byte(config.RenderConfig(a)), 0o600); err != nil {
+		fmt.Fprintf(out, "failed to write %s: %v\n", configPath, err)
+		return 1
+	}
+	if err := config.Validate(configPath); err != nil {
+		fmt.Fprintf(out, "generated config failed validation: %v\n", err)
+		return 1
+	}
+	fmt.Fprintf(out, "\nwrote %s\n", configPath)
+
+	created, err := bootstrapSuperAdmin(a.DBPath, email, password)
+	if err != nil {
+		fmt.Fprintf(out, "failed to create admin account: %v\n", err)
+		return 1
+	}
+	if created {
+		fmt.Fprintf(out, "created superadmin %s\n", email)
+	} else {
+		fmt.Fprintf(out, "user %s already exists; left unchanged\n", email)
+	}
+	fmt.Fprintf(out, "\nNext: ./allstar-nexus check --config %s && ./allstar-nexus --config %s\n", configPath, configPath)
+	return 0
+}
+
+func abortInit(out io.Writer) int {
+	fmt.Fprintln(out, "input ended before setup completed; nothing was written")
+	return 1
+}
+
+// bootstrapSuperAdmin creates the database (if needed) and a superadmin account.
+// It returns false without error when the email is already registered.
+func bootstrapSuperAdmin(dbPath, email, password string) (bool, error) {
+	if err := os.MkdirAll(filepath.Dir(dbPath), 0o755); err != nil {
+		return false, err
+	}
+	opts := database.DefaultOptions()
+	opts.Gorm = &gorm.Config{Logger: gormlogger.Default.LogMode(gormlogger.Silent)}
+	conn, err := database.OpenWithOptions(dbPath, opts)
+	if err != nil {
+		return false, err
+	}
+	defer func() { _ = conn.CloseSafe() }()
+	db := conn.DB
+	if err := db.AutoMigrate(&models.User{}); err != nil {
+		return false, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	users := repository.NewUserRepo(db)
+	if existing, err := users.GetByEmail(ctx, email); err == nil && existing != nil {
+		return false, nil
+	}
+	hash, err := auth.HashPassword(password)
+	if err != nil {
+		return false, err
+	}
+	if _, err := users.Create(ctx, email, hash, models.RoleSuperAdmin); err != nil {
+		return false, err
+	}
+	return true, nil
+}
@@ -4,6 +4,7 @@ import (
 	"bufio"
 	"context"
 	"crypto/rand"
+	"crypto/tls"
 	"fmt"
 	"log"
 	"net"
@@ -65,6 +66,9 @@ type Connector struct {
 
 	actionMu sync.Mutex
 	pending  map[string]chan Message // ActionID -> single-response channel
+
+	tlsConfig *tls.Config // nil = plain TCP
+	sshTunnel *SSHTunnel  // nil = dial AMI directly
 }
 
 // NewConnector builds a connector (not started yet).
@@ -83,6 +87,19 @@ func NewConnector(host string, port int, user, pass, events string, retryMin, re
 	}
 }
 
+// SetTLS makes the connector dial AMI over TLS (call before Start). Reconnects reuse the
+// same configuration and backoff as plain TCP.
+func (c *Connector) SetTLS(cfg *tls.Config) {
+	c.tlsConfig = cfg
+}
+
+// SetSSHTunnel makes the connector reach AMI through an SSH server (call before Start).
+// A fresh SSH session is opened for every AMI session, so tunnel failures follow the
+// same reconnect/backoff path as a dropped AMI socket.
+func (c *Connector) SetSSHTunnel(t *SSHTunnel) {
+	c.sshTunnel = t
+}
+
 // Raw returns the channel of parsed AMI messages.
 func (c *Connector) Raw() <-chan Message { return c.rawOut }
 
@@ -182,7 +199,7 @@ func (c *Connector) loop(ctx context.Context) {
 
 func (c *Connector) connectAndServe(ctx context.Context) error {
 	addr := net.JoinHostPort(c.host, strconv.Itoa(c.port))
-	conn, err := net.DialTimeout("tcp", addr, 5*time.Second)
+	conn, err := dialAMI(ctx, c.host, c.port, c.tlsConfig, c.sshTunnel)
 	if err != nil {
 		return err
 	}
@@ -191,7 +208,14 @@ func (c *Connector) connectAndServe(ctx context.Context) error {
 	c.mu.Unlock()
 
 	// Log successful TCP connection so it's visible in server logs
-	log.Printf("[AMI] connected to %s", addr)
+	var via string
+	if c.tlsConfig != nil {
+		via += " (TLS)"
+	}
+	if c.sshTunnel != nil {
+		via += " via SSH " + c.sshTunnel.Addr()
+	}
+	log.Printf("[AMI] connected to %s%s", addr, via)
 	if err := c.sendLogin(); err != nil {
 		if closeErr := conn.Close(); closeErr != nil {
 			log.Printf("Failed to close AMI connection: %v", closeErr)
@@ -364,6 +388,41 @@ func (c *Connector) RptStatus(ctx context.Context, node int, command string) (Me
 	}
 }
 
+// Ping issues an AMI Ping and waits for the correlated response. A connected session
+// that cannot answer a Ping means the read loop is no longer dispatching frames.
+func (c *Connector) Ping(ctx context.Context) error {
+	id := randID()
+	ch := make(chan Message, 1)
+	c.actionMu.Lock()
+	c.pending[id] = ch
+	c.actionMu.Unlock()
+	c.mu.RLock()
+	conn := c.conn
+	c.mu.RUnlock()
+	if conn == nil {
+		c.actionMu.Lock()
+		delete(c.pending, id)
+		c.actionMu.Unlock()
+		return fmt.Errorf("not connected")
+	}
+	payload := fmt.Sprintf("Action: Ping\r\nActionID: %s\r\n\r\n", id)
+	if _, err := conn.Write([]byte(payload)); err != nil {
+		return err
+	}
+	select {
+	case msg := <-ch:
+		if !strings.EqualFold(msg.Headers["Response"], "Success") && !strings.EqualFold(msg.Headers["Ping"], "Pong") {
+			return fmt.Errorf("unexpected ping response %q", msg.Headers["Response"])
+		}
+		return nil
+	case <-ctx.Done():
+		c.actionMu.Lock()
+		delete(c.pending, id)
+		c.actionMu.Unlock()
+		return ctx.Err()
+	}
+}
+
 // GetXStat retrieves and parses XStat for a node
 func (c *Connector) GetXStat(ctx context.Context, node int) (*XStatResult, error) {
 	msg, err := c.RptStatus(ctx, node, "XStat")
@@ -433,6 +492,22 @@ func extractCommandOutput(msg Message) string {
 	return strings.Join(output, "\n")
 }
 
+// CommandOutput returns the CLI output of an Action: Command response. Asterisk 14+
+// sends each line as an "Output:" header; older versions send raw text terminated by
+// --END COMMAND--.
+func CommandOutput(msg Message) string {
+	var output []string
+	for _, line := range msg.Raw {
+		if rest, ok := strings.CutPrefix(line, "Output:"); ok {
+			output = append(output, strings.TrimPrefix(rest, " "))
+		}
+	}
+	if len(output) > 0 {
+		return strings.Join(output, "\n")
+	}
+	return extractCommandOutput(msg)
+}
+
 // broadcastStatus sends connection status to statusOut channel
 func (c *Connector) broadcastStatus(connected bool, err error) {
 	c.mu.Lock()
@@ -0,0 +1,113 @@
+package ami
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// IAXRegistration is one line of `iax2 show registry`: an outbound registration, such
+// as the node's registration with the AllStarLink registrar.
+type IAXRegistration struct {
+	Host       string `json:"host"`
+	DNSManager bool   `json:"dns_manager"`
+	Username   string `json:"username"`
+	Perceived  string `json:"perceived"` // our address as seen by the registrar
+	Refresh    int    `json:"refresh"`   // seconds between re-registrations
+	State      string `json:"state"`     // Registered, Request Sent, Auth. Sent, Rejected, Timeout, ...
+	Registered bool   `json:"registered"`
+}
+
+// IAXPeer is one line of `iax2 show peers`.
+type IAXPeer struct {
+	Name        string `json:"name"`
+	Host        string `json:"host"` // "(Unspecified)" for dynamic peers that have not registered
+	Dynamic     bool   `json:"dynamic"`
+	Mask        string `json:"mask"`
+	Port        int    `json:"port"`
+	Trunk       bool   `json:"trunk"`
+	Status      string `json:"status"`               // OK, LAGGED, UNREACHABLE, UNKNOWN, Unmonitored
+	LatencyMS   int    `json:"latency_ms,omitempty"` // qualify round trip, when monitored
+	Description string `json:"description,omitempty"`
+}
+
+// Reachable reports whether a monitored peer answered its last qualify.
+func (p IAXPeer) Reachable() bool {
+	return p.Status == "OK" || p.Status == "LAGGED"
+}
+
+// Monitored reports whether the peer is qualified (qualify=yes), i.e. its status means
+// something.
+func (p IAXPeer) Monitored() bool {
+	return p.Status != "" && p.Status != "Unmonitored"
+}
+
+var iaxPeerStatusRe = regexp.MustCompile(`^(OK|LAGGED|UNREACHABLE|UNKNOWN|Unmonitored)(?:\s+\((\d+)\s*ms\))?\s*(.*)$`)
+
+// ParseIAXRegistry parses `iax2 show registry` output. Both the current layout and the
+// older one without the dnsmgr column are understood.
+func ParseIAXRegistry(output string) []IAXRegistration {
+	out := make([]IAXRegistration, 0)
+	for _, line := range strings.Split(output, "\n") {
+		f := strings.Fields(line)
+		if len(f) < 4 || f[0] == "Host" || strings.Contains(line, "registrations") {
+			continue
+		}
+		reg := IAXRegistration{Host: f[0]}
+		i := 1
+		if f[1] == "Y" || f[1] == "N" {
+			reg.DNSManager = f[1] == "Y"
+			i++
+		}
+		if len(f) < i+4 {
+			continue
+		}
+		refresh, err := strconv.Atoi(f[i+2])
+		if err != nil {
+			continue
+		}
+		reg.Username, reg.Perceived, reg.Refresh = f[i], f[i+1], refresh
+		reg.State = strings.Join(f[i+3:], " ")
+		reg.Registered = reg.State == "Registered"
+		out = append(out, reg)
+	}
+	return out
+}
+
+// ParseIAXPeers parses `iax2 show peers` output.
+func ParseIAXPeers(output string) []IAXPeer {
+	out := make([]IAXPeer, 0)
+	for _, line := range strings.Split(output, "\n") {
+		f := strings.Fields(line)
+		if len(f) < 5 || f[0] == "Name/Username" || strings.Contains(line, "iax2 peers") {
+			continue
+		}
+		p := IAXPeer{Name: f[0], Host: f[1]}
+		i := 2
+		if f[i] == "(D)" {
+			p.Dynamic = true
+			i++
+		}
+		if len(f) < i+3 {
+			continue
+		}
+		port, err := strconv.Atoi(f[i+1])
+		if err != nil {
+			continue
+		}
+		p.Mask, p.Port = f[i], port
+		i += 2
+		if f[i] == "(T)" {
+			p.Trunk = true
+			i++
+		}
+		m := iaxPeerStatusRe.FindStringSubmatch(strings.Join(f[i:], " "))
+		if m == nil {
+			continue
+		}
+		p.Status, p.Description = m[1], m[3]
+		p.LatencyMS, _ = strconv.Atoi(m[2])
+		out = append(out, p)
+	}
+	return out
+}
@@ -0,0 +1,111 @@
+package ami
+
+import (
+	"net"
+	"strconv"
+	"strings"
+)
+
+// RptLinkStat is one connection from `rpt lstats <node>`.
+type RptLinkStat struct {
+	Node        int    `json:"node"`
+	Peer        string `json:"peer,omitempty"` // remote address; empty for EchoLink and other non-IAX links
+	Reconnects  int    `json:"reconnects"`
+	Direction   string `json:"direction"`
+	ConnectTime string `json:"connect_time"`
+	State       string `json:"state"` // ESTABLISHED, CONNECTING, ...
+}
+
+// IAXJitterStats is one side of an `iax2 show netstats` line. Local counters describe
+// what we received, remote counters what the peer reports receiving from us.
+type IAXJitterStats struct {
+	JitterMS int `json:"jitter_ms"`
+	DelayMS  int `json:"delay_ms"`
+	Lost     int `json:"lost"`
+	LostPct  int `json:"lost_pct"`
+	Dropped  int `json:"dropped"`
+	OOO      int `json:"ooo"`   // out of order
+	Kpkts    int `json:"kpkts"` // thousands of packets
+}
+
+// IAXNetStat is one channel from `iax2 show netstats`. Counters are -1 until the
+// jitter buffer has data (e.g. while the call is still being set up).
+type IAXNetStat struct {
+	Channel string         `json:"channel"`
+	Peer    string         `json:"peer"` // host part of the channel name
+	RTTMS   int            `json:"rtt_ms"`
+	Local   IAXJitterStats `json:"local"`
+	Remote  IAXJitterStats `json:"remote"`
+}
+
+// ParseRptLStats parses `rpt lstats <node>` output.
+func ParseRptLStats(output string) []RptLinkStat {
+	out := make([]RptLinkStat, 0)
+	for _, line := range strings.Split(output, "\n") {
+		f := strings.Fields(line)
+		if len(f) < 6 {
+			continue
+		}
+		node, err := strconv.Atoi(f[0])
+		if err != nil {
+			continue // header and separator lines
+		}
+		reconnects, err := strconv.Atoi(f[2])
+		if err != nil {
+			continue
+		}
+		ls := RptLinkStat{Node: node, Peer: f[1], Reconnects: reconnects, Direction: f[3], ConnectTime: f[4], State: strings.Join(f[5:], " ")}
+		if net.ParseIP(ls.Peer) == nil {
+			ls.Peer = "" // "(none)" and similar placeholders
+		}
+		out = append(out, ls)
+	}
+	return out
+}
+
+// ParseIAXNetstats parses `iax2 show netstats` output.
+func ParseIAXNetstats(output string) []IAXNetStat {
+	out := make([]IAXNetStat, 0)
+	for _, line := range strings.Split(output, "\n") {
+		f := strings.Fields(line)
+		if len(f) < 16 || !strings.HasPrefix(f[0], "IAX2/") {
+			continue
+		}
+		n := make([]int, 15)
+		ok := true
+		for i := range n {
+			v, err := strconv.Atoi(f[i+1])
+			if err != nil {
+				ok = false
+				break
+			}
+			n[i] = v
+		}
+		if !ok {
+			continue
+		}
+		ns := IAXNetStat{
+			Channel: f[0],
+			Peer:    iaxChannelPeer(f[0]),
+			RTTMS:   n[0],
+			Local:   IAXJitterStats{JitterMS: n[1], DelayMS: n[2], Lost: n[3], LostPct: n[4], Dropped: n[5], OOO: n[6], Kpkts: n[7]},
+			Remote:  IAXJitterStats{JitterMS: n[8], DelayMS: n[9], Lost: n[10], LostPct: n[11], Dropped: n[12], OOO: n[13], Kpkts: n[14]},
+		}
+		out = append(out, ns)
+	}
+	return out
+}
+
+// iaxChannelPeer extracts the peer from a channel name: "IAX2/192.0.2.1:4569-6401"
+// gives "192.0.2.1", "IAX2/[2001:db8::1]:4569-12" gives "2001:db8::1" and named peers
+// ("IAX2/allstar-public-77") give the name.
+func iaxChannelPeer(channel string) string {
+	s := strings.TrimPrefix(channel, "IAX2/")
+	if i := strings.LastIndex(s, "-"); i > 0 {
+		s = s[:i]
+	}
+	if host, _, err := net.SplitHostPort(s); err == nil {
+		return host
+	}
+	return s
+}
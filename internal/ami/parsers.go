@@ -3,10 +3,12 @@ package ami
 import (
 	"fmt"
 	"log"
+	"net"
 	"strconv"
 	"strings"
-	"sync"
 	"time"
+
+	"github.com/dbehnke/allstar-nexus/internal/textnode"
 )
 
 // ParseXStat parses the response from RptStatus XStat command
@@ -106,7 +108,7 @@ func parseConnLine(line string) (Connection, error) {
 	} else {
 		// Format: NodeNum IP IsKeyed Direction Elapsed [LinkType]
 		if len(fields) >= 5 {
-			conn.IP = fields[1]
+			conn.IP = normalizeIP(fields[1])
 			conn.IsKeyed = fields[2] == "1"
 			conn.Direction = fields[3]
 			conn.Elapsed = fields[4]
@@ -119,6 +121,21 @@ func parseConnLine(line string) (Connection, error) {
 	return conn, nil
 }
 
+// normalizeIP canonicalises an address from app_rpt output. IPv6 peers may be printed
+// bracketed and/or with a port ("[2001:db8::1]:4569"); IPv4 may carry a port too.
+// Unparseable values are returned unchanged.
+func normalizeIP(s string) string {
+	host := s
+	if h, _, err := net.SplitHostPort(s); err == nil {
+		host = h
+	}
+	host = strings.Trim(host, "[]")
+	if ip := net.ParseIP(host); ip != nil {
+		return ip.String()
+	}
+	return s
+}
+
 // parseLinkedNodes parses the LinkedNodes: line
 func parseLinkedNodes(line string) []LinkedNode {
 	// Remove "LinkedNodes: " prefix
@@ -150,7 +167,6 @@ func parseLinkedNodes(line string) []LinkedNode {
 			// This is done in the AMI layer so CombinedNodeStatus can include these nodes
 			callsign := strings.ToUpper(strings.TrimSpace(nodeStr))
 			nodeNum = hashTextNodeToInt(callsign)
-			registerTextNodeInAMI(nodeNum, callsign)
 			log.Printf("[AMI] Registered text node: %s -> %d", callsign, nodeNum)
 		}
 
@@ -256,6 +272,7 @@ func CombineXStatSawStat(xstat *XStatResult, sawstat *SawStatResult) *CombinedNo
 		RxKeyed:     xstat.RxKeyed,
 		TxKeyed:     xstat.TxKeyed,
 		Connections: make([]ConnectionWithHistory, 0, len(xstat.Connections)),
+		LinkedNodes: xstat.LinkedNodes,
 		Timestamp:   time.Now(),
 	}
 
@@ -302,7 +319,8 @@ func CombineXStatSawStat(xstat *XStatResult, sawstat *SawStatResult) *CombinedNo
 					Node:      ln.Node,
 					Timestamp: time.Now(),
 				},
-				Mode: ln.Mode,
+				Mode:      ln.Mode,
+				Synthetic: true,
 			}
 
 			// Add keying info if available
@@ -403,47 +421,21 @@ func parseVoterLine(line string) VoterReceiver {
 	return rx
 }
 
-// hashTextNodeToInt converts a text node (callsign) to a stable negative integer
-// Uses FNV-1a hash to ensure consistent hashing
+// hashTextNodeToInt resolves a text node (callsign) to its negative node ID via the
+// shared registry, which handles hash collisions and persistence.
 func hashTextNodeToInt(s string) int {
-	s = strings.ToUpper(s) // Normalize to uppercase
-	hash := uint32(2166136261)
-	for i := 0; i < len(s); i++ {
-		hash ^= uint32(s[i])
-		hash *= 16777619
-	}
-	// Convert to negative number to distinguish from numeric AllStar nodes
-	// Use lower 30 bits to keep values reasonable
-	return -int(hash & 0x3FFFFFFF)
+	return textnode.ID(s)
 }
 
-// AMI-layer text node registry
-// This allows the AMI layer to register text nodes when parsing LinkedNodes
-// The core layer will also register them when processing RPT_ALINKS
-var (
-	amiTextNodeRegistry = make(map[int]string)
-	amiRegistryMu       sync.RWMutex
-)
-
+// registerTextNodeInAMI is kept for callers that resolved nodeID themselves; the
+// registry already holds the mapping, so it only reports a mismatch.
 func registerTextNodeInAMI(nodeID int, text string) {
-	amiRegistryMu.Lock()
-	defer amiRegistryMu.Unlock()
-
-	upperText := strings.ToUpper(text)
-
-	// Check for hash collisions
-	if existing, exists := amiTextNodeRegistry[nodeID]; exists && existing != upperText {
-		log.Printf("WARNING: Hash collision detected! Callsigns %s and %s both hash to %d",
-			existing, upperText, nodeID)
+	if id := textnode.ID(text); id != nodeID {
+		log.Printf("WARNING: text node %s resolved to %d, not %d", strings.ToUpper(text), id, nodeID)
 	}
-
-	amiTextNodeRegistry[nodeID] = upperText
 }
 
-// GetTextNodeFromAMI retrieves a text node name from the AMI-layer registry
+// GetTextNodeFromAMI retrieves a text node name from the shared registry
 func GetTextNodeFromAMI(nodeID int) (string, bool) {
-	amiRegistryMu.RLock()
-	defer amiRegistryMu.RUnlock()
-	text, ok := amiTextNodeRegistry[nodeID]
-	return text, ok
+	return textnode.Name(nodeID)
 }
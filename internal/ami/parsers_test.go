@@ -317,3 +317,123 @@ func TestFormatLastHeard(t *testing.T) {
 		})
 	}
 }
+
+func TestParseXStatIPv6(t *testing.T) {
+	data, err := os.ReadFile("testdata/xstat_ipv6.txt")
+	if err != nil {
+		t.Fatalf("failed to read test data: %v", err)
+	}
+	result, err := ParseXStat(1999, string(data))
+	if err != nil {
+		t.Fatalf("ParseXStat failed: %v", err)
+	}
+	if len(result.Connections) != 3 {
+		t.Fatalf("expected 3 connections, got %d", len(result.Connections))
+	}
+	want := []string{"2001:db8:10:20::a", "2001:db8:10:21::b", "192.168.1.12"}
+	for i, w := range want {
+		if got := result.Connections[i].IP; got != w {
+			t.Errorf("connection %d: IP %q, want %q", i, got, w)
+		}
+	}
+	if !result.Connections[1].IsKeyed || result.Connections[1].Direction != "IN" {
+		t.Errorf("fields after bracketed IPv6 misparsed: %+v", result.Connections[1])
+	}
+}
+
+func TestParseIAXRegistry(t *testing.T) {
+	data, err := os.ReadFile("testdata/iax2_registry.txt")
+	if err != nil {
+		t.Fatalf("failed to read test data: %v", err)
+	}
+	regs := ParseIAXRegistry(string(data))
+	if len(regs) != 3 {
+		t.Fatalf("expected 3 registrations, got %d: %+v", len(regs), regs)
+	}
+	if r := regs[0]; r.Host != "162.248.92.131:4569" || !r.DNSManager || r.Username != "43732" ||
+		r.Perceived != "203.0.113.10:4569" || r.Refresh != 60 || !r.Registered {
+		t.Errorf("unexpected registration: %+v", r)
+	}
+	if r := regs[1]; r.State != "Request Sent" || r.Registered {
+		t.Errorf("unexpected registration: %+v", r)
+	}
+	if r := regs[2]; r.State != "Auth. Sent" || r.DNSManager {
+		t.Errorf("unexpected registration: %+v", r)
+	}
+
+	// Older Asterisk versions have no dnsmgr column
+	old := ParseIAXRegistry("Host                  Username    Perceived             Refresh  State\n162.248.92.131:4569   43732       203.0.113.10:4569          60  Registered\n")
+	if len(old) != 1 || old[0].Username != "43732" || !old[0].Registered {
+		t.Errorf("unexpected legacy registration: %+v", old)
+	}
+}
+
+func TestParseIAXPeers(t *testing.T) {
+	data, err := os.ReadFile("testdata/iax2_peers.txt")
+	if err != nil {
+		t.Fatalf("failed to read test data: %v", err)
+	}
+	peers := ParseIAXPeers(string(data))
+	if len(peers) != 5 {
+		t.Fatalf("expected 5 peers, got %d: %+v", len(peers), peers)
+	}
+	if p := peers[0]; p.Name != "radio" || !p.Dynamic || p.Port != 0 || p.Status != "UNKNOWN" || p.Reachable() || !p.Monitored() {
+		t.Errorf("unexpected peer: %+v", p)
+	}
+	if p := peers[1]; p.Host != "162.248.92.131" || p.Status != "OK" || p.LatencyMS != 23 || !p.Reachable() {
+		t.Errorf("unexpected peer: %+v", p)
+	}
+	if p := peers[2]; !p.Trunk || p.Status != "UNREACHABLE" || p.Description != "Club hub" {
+		t.Errorf("unexpected peer: %+v", p)
+	}
+	if p := peers[3]; !p.Dynamic || p.Status != "LAGGED" || p.LatencyMS != 1210 || !p.Reachable() {
+		t.Errorf("unexpected peer: %+v", p)
+	}
+	if p := peers[4]; p.Monitored() {
+		t.Errorf("unexpected peer: %+v", p)
+	}
+}
+
+func TestParseRptLStats(t *testing.T) {
+	data, err := os.ReadFile("testdata/rpt_lstats.txt")
+	if err != nil {
+		t.Fatalf("failed to read test data: %v", err)
+	}
+	stats := ParseRptLStats(string(data))
+	if len(stats) != 4 {
+		t.Fatalf("expected 4 links, got %d: %+v", len(stats), stats)
+	}
+	if s := stats[1]; s.Node != 2002 || s.Peer != "198.51.100.7" || s.Reconnects != 3 || s.Direction != "IN" || s.State != "ESTABLISHED" {
+		t.Errorf("unexpected link: %+v", s)
+	}
+	if s := stats[2]; s.Node != 3123456 || s.Peer != "" {
+		t.Errorf("echolink peer placeholder kept: %+v", s)
+	}
+	if s := stats[3]; s.State != "CONNECTING" {
+		t.Errorf("unexpected link: %+v", s)
+	}
+}
+
+func TestParseIAXNetstats(t *testing.T) {
+	data, err := os.ReadFile("testdata/iax2_netstats.txt")
+	if err != nil {
+		t.Fatalf("failed to read test data: %v", err)
+	}
+	stats := ParseIAXNetstats(string(data))
+	if len(stats) != 3 {
+		t.Fatalf("expected 3 channels, got %d: %+v", len(stats), stats)
+	}
+	if s := stats[1]; s.Peer != "198.51.100.7" || s.RTTMS != 148 || s.Local.JitterMS != 95 || s.Local.Lost != 42 ||
+		s.Local.LostPct != 3 || s.Local.Dropped != 11 || s.Local.Kpkts != 12 || s.Remote.JitterMS != 60 || s.Remote.Lost != 10 {
+		t.Errorf("unexpected channel: %+v", s)
+	}
+	if s := stats[2]; s.RTTMS != -1 || s.Local.Kpkts != -1 {
+		t.Errorf("unset counters not kept as -1: %+v", s)
+	}
+	if p := iaxChannelPeer("IAX2/[2001:db8::1]:4569-12"); p != "2001:db8::1" {
+		t.Errorf("ipv6 peer = %q", p)
+	}
+	if p := iaxChannelPeer("IAX2/allstar-public-77"); p != "allstar-public" {
+		t.Errorf("named peer = %q", p)
+	}
+}
@@ -0,0 +1,108 @@
+package ami
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ProbeLogin opens a one-off AMI session, authenticates, and logs off. It returns the
+// manager banner (e.g. "Asterisk Call Manager/7.0.3") so callers such as setup and
+// self-check commands can validate host, port and credentials without starting a Connector.
+// tlsCfg may be nil for plain TCP; tunnel may be nil to connect directly.
+func ProbeLogin(ctx context.Context, host string, port int, user, pass string, tlsCfg *tls.Config, tunnel *SSHTunnel) (string, error) {
+	conn, err := dialAMI(ctx, host, port, tlsCfg, tunnel)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = conn.Close() }()
+	// SSH channels do not support deadlines, so also close on cancellation.
+	stop := context.AfterFunc(ctx, func() { _ = conn.Close() })
+	defer stop()
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	} else {
+		_ = conn.SetDeadline(time.Now().Add(10 * time.Second))
+	}
+
+	reader := bufio.NewReader(conn)
+	banner, err := reader.ReadString('\n')
+	if err != nil {
+		return "", fmt.Errorf("read banner: %w", err)
+	}
+	banner = strings.TrimSpace(banner)
+	if !strings.Contains(banner, "Call Manager") {
+		return banner, fmt.Errorf("unexpected banner %q (is this an AMI port?)", banner)
+	}
+
+	id := randID()
+	payload := fmt.Sprintf("Action: Login\r\nActionID: %s\r\nUsername: %s\r\nSecret: %s\r\nEvents: off\r\n\r\n", id, user, pass)
+	if _, err := conn.Write([]byte(payload)); err != nil {
+		return banner, fmt.Errorf("send login: %w", err)
+	}
+
+	headers := map[string]string{}
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return banner, fmt.Errorf("read login response: %w", err)
+		}
+		line = strings.TrimRight(line, "\r\n")
+		if line == "" {
+			if headers["ActionID"] == id || headers["Response"] != "" {
+				break
+			}
+			headers = map[string]string{}
+			continue
+		}
+		if idx := strings.Index(line, ":"); idx > 0 {
+			headers[strings.TrimSpace(line[:idx])] = strings.TrimSpace(line[idx+1:])
+		}
+	}
+	if !strings.EqualFold(headers["Response"], "Success") {
+		msg := headers["Message"]
+		if msg == "" {
+			msg = "login rejected"
+		}
+		return banner, fmt.Errorf("authentication failed: %s", msg)
+	}
+	_, _ = conn.Write([]byte("Action: Logoff\r\n\r\n"))
+	return banner, nil
+}
+
+// dialAMI connects to host:port over TCP, or TLS when tlsCfg is non-nil. When tunnel
+// is set the connection is forwarded through SSH and host:port is resolved from the
+// SSH server. The TLS handshake is bounded by the same 5s timeout as the TCP connect.
+func dialAMI(ctx context.Context, host string, port int, tlsCfg *tls.Config, tunnel *SSHTunnel) (net.Conn, error) {
+	addr := net.JoinHostPort(host, strconv.Itoa(port))
+	var conn net.Conn
+	var err error
+	if tunnel != nil {
+		conn, err = tunnel.Dial(ctx, addr)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		d := &net.Dialer{Timeout: 5 * time.Second}
+		conn, err = d.DialContext(ctx, "tcp", addr)
+		if err != nil {
+			return nil, fmt.Errorf("connect %s: %w", addr, err)
+		}
+	}
+	if tlsCfg == nil {
+		return conn, nil
+	}
+	hctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	tc := tls.Client(conn, tlsCfg)
+	if err := tc.HandshakeContext(hctx); err != nil {
+		_ = conn.Close()
+		return nil, fmt.Errorf("tls connect %s: %w", addr, err)
+	}
+	return tc, nil
+}
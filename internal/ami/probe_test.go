@@ -0,0 +1,70 @@
+package ami
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeAMI accepts one connection and answers a Login action with the given response.
+func fakeAMI(t *testing.T, response string) (string, int) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	return serveFakeAMI(t, ln, response)
+}
+
+func serveFakeAMI(t *testing.T, ln net.Listener, response string) (string, int) {
+	t.Helper()
+	t.Cleanup(func() { _ = ln.Close() })
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		_, _ = conn.Write([]byte("Asterisk Call Manager/7.0.3\r\n"))
+		r := bufio.NewReader(conn)
+		actionID := ""
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			line = strings.TrimSpace(line)
+			if strings.HasPrefix(line, "ActionID:") {
+				actionID = strings.TrimSpace(strings.TrimPrefix(line, "ActionID:"))
+			}
+			if line == "" {
+				break
+			}
+		}
+		_, _ = conn.Write([]byte("Response: " + response + "\r\nActionID: " + actionID + "\r\nMessage: Authentication accepted\r\n\r\n"))
+	}()
+	addr := ln.Addr().(*net.TCPAddr)
+	return addr.IP.String(), addr.Port
+}
+
+func TestProbeLogin(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	host, port := fakeAMI(t, "Success")
+	banner, err := ProbeLogin(ctx, host, port, "admin", "secret", nil, nil)
+	if err != nil {
+		t.Fatalf("expected successful login, got %v", err)
+	}
+	if !strings.Contains(banner, "Call Manager") {
+		t.Fatalf("unexpected banner %q", banner)
+	}
+
+	host, port = fakeAMI(t, "Error")
+	if _, err := ProbeLogin(ctx, host, port, "admin", "wrong", nil, nil); err == nil {
+		t.Fatalf("expected authentication failure")
+	}
+}
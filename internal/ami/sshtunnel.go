@@ -0,0 +1,177 @@
+package ami
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// SSHTunnelOptions describes an SSH host used to reach an AMI port that is not
+// directly routable (hub behind CGNAT or a firewall). The AMI host/port are then
+// resolved from the SSH server's side, typically 127.0.0.1:5038.
+type SSHTunnelOptions struct {
+	Host                  string
+	Port                  int // default 22
+	User                  string
+	KeyFile               string        // unencrypted private key (OpenSSH/PEM)
+	KnownHostsFile        string        // default ~/.ssh/known_hosts
+	InsecureIgnoreHostKey bool          // skip host key verification (testing only)
+	KeepAlive             time.Duration // 0 disables keepalive probes
+}
+
+// SSHTunnel dials AMI through an SSH connection. Each AMI session gets its own SSH
+// client, so a dead tunnel is torn down and re-established by the Connector's normal
+// reconnect/backoff loop.
+type SSHTunnel struct {
+	addr      string
+	config    *ssh.ClientConfig
+	keepAlive time.Duration
+}
+
+// NewSSHTunnel loads the private key and host key policy up front so configuration
+// errors surface at startup rather than on every reconnect.
+func NewSSHTunnel(opts SSHTunnelOptions) (*SSHTunnel, error) {
+	if opts.Host == "" {
+		return nil, errors.New("ssh tunnel: host is required")
+	}
+	if opts.User == "" {
+		return nil, errors.New("ssh tunnel: user is required")
+	}
+	if opts.KeyFile == "" {
+		return nil, errors.New("ssh tunnel: key file is required")
+	}
+	port := opts.Port
+	if port == 0 {
+		port = 22
+	}
+	keyPEM, err := os.ReadFile(opts.KeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("ssh tunnel: read key: %w", err)
+	}
+	signer, err := ssh.ParsePrivateKey(keyPEM)
+	if err != nil {
+		return nil, fmt.Errorf("ssh tunnel: parse key %s: %w", opts.KeyFile, err)
+	}
+
+	var hostKeyCallback ssh.HostKeyCallback
+	if opts.InsecureIgnoreHostKey {
+		hostKeyCallback = ssh.InsecureIgnoreHostKey() //nolint:gosec // explicit operator opt-in
+	} else {
+		khFile := opts.KnownHostsFile
+		if khFile == "" {
+			home, err := os.UserHomeDir()
+			if err != nil {
+				return nil, fmt.Errorf("ssh tunnel: locate known_hosts: %w", err)
+			}
+			khFile = filepath.Join(home, ".ssh", "known_hosts")
+		}
+		hostKeyCallback, err = knownhosts.New(khFile)
+		if err != nil {
+			return nil, fmt.Errorf("ssh tunnel: load known_hosts: %w", err)
+		}
+	}
+
+	return &SSHTunnel{
+		addr: net.JoinHostPort(opts.Host, strconv.Itoa(port)),
+		config: &ssh.ClientConfig{
+			User:            opts.User,
+			Auth:            []ssh.AuthMethod{ssh.PublicKeys(signer)},
+			HostKeyCallback: hostKeyCallback,
+			Timeout:         10 * time.Second,
+		},
+		keepAlive: opts.KeepAlive,
+	}, nil
+}
+
+// Addr returns the SSH server address (host:port).
+func (t *SSHTunnel) Addr() string { return t.addr }
+
+// Dial opens a new SSH session and forwards a TCP connection to addr from the SSH
+// server. Closing the returned conn also closes the SSH session.
+func (t *SSHTunnel) Dial(ctx context.Context, addr string) (net.Conn, error) {
+	d := &net.Dialer{Timeout: t.config.Timeout}
+	raw, err := d.DialContext(ctx, "tcp", t.addr)
+	if err != nil {
+		return nil, fmt.Errorf("ssh connect %s: %w", t.addr, err)
+	}
+	// Bound the SSH handshake; cleared once the client is up.
+	_ = raw.SetDeadline(time.Now().Add(t.config.Timeout))
+	sc, chans, reqs, err := ssh.NewClientConn(raw, t.addr, t.config)
+	if err != nil {
+		_ = raw.Close()
+		return nil, fmt.Errorf("ssh handshake %s: %w", t.addr, err)
+	}
+	_ = raw.SetDeadline(time.Time{})
+	client := ssh.NewClient(sc, chans, reqs)
+
+	conn, err := client.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		_ = client.Close()
+		return nil, fmt.Errorf("ssh forward to %s via %s: %w", addr, t.addr, err)
+	}
+	tc := &tunnelConn{Conn: conn, client: client, done: make(chan struct{})}
+	if t.keepAlive > 0 {
+		go tc.keepAliveLoop(t.keepAlive)
+	}
+	return tc, nil
+}
+
+// tunnelConn is a forwarded channel that owns its SSH client.
+type tunnelConn struct {
+	net.Conn
+	client *ssh.Client
+	once   sync.Once
+	done   chan struct{}
+}
+
+func (c *tunnelConn) Close() error {
+	var err error
+	c.once.Do(func() {
+		close(c.done)
+		err = c.Conn.Close()
+		if cerr := c.client.Close(); err == nil {
+			err = cerr
+		}
+	})
+	return err
+}
+
+// keepAliveLoop closes the tunnel when the SSH server stops answering, which unblocks
+// the AMI reader so the Connector reconnects. Without it a silently dropped NAT
+// mapping could leave the session hanging until TCP gives up.
+func (c *tunnelConn) keepAliveLoop(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-c.done:
+			return
+		case <-ticker.C:
+		}
+		reply := make(chan error, 1)
+		go func() {
+			_, _, err := c.client.SendRequest("keepalive@openssh.com", true, nil)
+			reply <- err
+		}()
+		select {
+		case <-c.done:
+			return
+		case err := <-reply:
+			if err == nil {
+				continue
+			}
+		case <-time.After(interval):
+		}
+		_ = c.Close()
+		return
+	}
+}
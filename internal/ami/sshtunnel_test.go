@@ -0,0 +1,171 @@
+package ami
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// fakeSSHServer accepts public-key logins for clientKey and forwards direct-tcpip
+// channels to the requested address. It returns its port and a known_hosts line.
+func fakeSSHServer(t *testing.T, clientKey ssh.PublicKey) (int, string) {
+	t.Helper()
+	_, hostPriv, _ := ed25519.GenerateKey(rand.Reader)
+	hostSigner, err := ssh.NewSignerFromKey(hostPriv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := &ssh.ServerConfig{
+		PublicKeyCallback: func(_ ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
+			if string(key.Marshal()) == string(clientKey.Marshal()) {
+				return nil, nil
+			}
+			return nil, fmt.Errorf("unknown key")
+		},
+	}
+	cfg.AddHostKey(hostSigner)
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = ln.Close() })
+	go func() {
+		for {
+			nc, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				_, chans, reqs, err := ssh.NewServerConn(nc, cfg)
+				if err != nil {
+					return
+				}
+				go ssh.DiscardRequests(reqs)
+				for nch := range chans {
+					if nch.ChannelType() != "direct-tcpip" {
+						_ = nch.Reject(ssh.UnknownChannelType, "unsupported")
+						continue
+					}
+					var req struct {
+						Host     string
+						Port     uint32
+						OrigHost string
+						OrigPort uint32
+					}
+					if err := ssh.Unmarshal(nch.ExtraData(), &req); err != nil {
+						_ = nch.Reject(ssh.ConnectionFailed, "bad request")
+						continue
+					}
+					target, err := net.Dial("tcp", net.JoinHostPort(req.Host, strconv.Itoa(int(req.Port))))
+					if err != nil {
+						_ = nch.Reject(ssh.ConnectionFailed, err.Error())
+						continue
+					}
+					ch, creqs, err := nch.Accept()
+					if err != nil {
+						_ = target.Close()
+						continue
+					}
+					go ssh.DiscardRequests(creqs)
+					go func() { _, _ = io.Copy(ch, target); _ = ch.Close() }()
+					go func() { _, _ = io.Copy(target, ch); _ = target.Close() }()
+				}
+			}()
+		}
+	}()
+	port := ln.Addr().(*net.TCPAddr).Port
+	return port, knownhostsLine(port, hostSigner.PublicKey())
+}
+
+func knownhostsLine(port int, key ssh.PublicKey) string {
+	return fmt.Sprintf("[127.0.0.1]:%d %s", port, ssh.MarshalAuthorizedKey(key))
+}
+
+func writeClientKey(t *testing.T, dir string) (string, ssh.PublicKey) {
+	t.Helper()
+	pub, priv, _ := ed25519.GenerateKey(rand.Reader)
+	block, err := ssh.MarshalPrivateKey(priv, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "id_ed25519")
+	if err := os.WriteFile(path, pem.EncodeToMemory(block), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	sshPub, _ := ssh.NewPublicKey(pub)
+	return path, sshPub
+}
+
+func TestProbeLoginViaSSHTunnel(t *testing.T) {
+	dir := t.TempDir()
+	keyFile, clientPub := writeClientKey(t, dir)
+	sshPort, khLine := fakeSSHServer(t, clientPub)
+	knownHosts := filepath.Join(dir, "known_hosts")
+	if err := os.WriteFile(knownHosts, []byte(khLine), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	amiHost, amiPort := fakeAMI(t, "Success")
+
+	tunnel, err := NewSSHTunnel(SSHTunnelOptions{
+		Host: "127.0.0.1", Port: sshPort, User: "nexus",
+		KeyFile: keyFile, KnownHostsFile: knownHosts, KeepAlive: time.Second,
+	})
+	if err != nil {
+		t.Fatalf("NewSSHTunnel: %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	banner, err := ProbeLogin(ctx, amiHost, amiPort, "admin", "secret", nil, tunnel)
+	if err != nil {
+		t.Fatalf("probe via tunnel: %v", err)
+	}
+	if banner != "Asterisk Call Manager/7.0.3" {
+		t.Fatalf("banner = %q", banner)
+	}
+}
+
+func TestSSHTunnelRejectsUnknownHostKey(t *testing.T) {
+	dir := t.TempDir()
+	keyFile, clientPub := writeClientKey(t, dir)
+	sshPort, _ := fakeSSHServer(t, clientPub)
+
+	// known_hosts lists a different key for this address
+	_, otherPriv, _ := ed25519.GenerateKey(rand.Reader)
+	otherSigner, _ := ssh.NewSignerFromKey(otherPriv)
+	knownHosts := filepath.Join(dir, "known_hosts")
+	_ = os.WriteFile(knownHosts, []byte(knownhostsLine(sshPort, otherSigner.PublicKey())), 0o600)
+
+	tunnel, err := NewSSHTunnel(SSHTunnelOptions{
+		Host: "127.0.0.1", Port: sshPort, User: "nexus",
+		KeyFile: keyFile, KnownHostsFile: knownHosts,
+	})
+	if err != nil {
+		t.Fatalf("NewSSHTunnel: %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if _, err := tunnel.Dial(ctx, "127.0.0.1:5038"); err == nil {
+		t.Fatal("expected host key mismatch to fail the handshake")
+	}
+}
+
+func TestNewSSHTunnelValidation(t *testing.T) {
+	if _, err := NewSSHTunnel(SSHTunnelOptions{Host: "h", User: "u"}); err == nil {
+		t.Fatal("expected error without key file")
+	}
+	if _, err := NewSSHTunnel(SSHTunnelOptions{Host: "h", User: "u", KeyFile: filepath.Join(t.TempDir(), "missing")}); err == nil {
+		t.Fatal("expected error for missing key file")
+	}
+}
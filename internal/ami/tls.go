@@ -0,0 +1,36 @@
+package ami
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+)
+
+// DefaultTLSPort is the conventional port for AMI over TLS (manager.conf tlsbindport).
+const DefaultTLSPort = 5039
+
+// NewTLSConfig builds a client TLS configuration for AMI. caFile (PEM) is added to the
+// system roots when set, for Asterisk servers using a private CA or self-signed cert.
+func NewTLSConfig(host string, skipVerify bool, caFile string) (*tls.Config, error) {
+	cfg := &tls.Config{
+		ServerName:         host,
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: skipVerify, //nolint:gosec // explicit operator opt-in for self-signed AMI certs
+	}
+	if caFile != "" {
+		pem, err := os.ReadFile(caFile)
+		if err != nil {
+			return nil, fmt.Errorf("read AMI CA file: %w", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("AMI CA file %s contains no PEM certificates", caFile)
+		}
+		cfg.RootCAs = pool
+	}
+	return cfg, nil
+}
@@ -0,0 +1,97 @@
+package ami
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// selfSignedCert returns a certificate for 127.0.0.1 and its PEM encoding.
+func selfSignedCert(t *testing.T) (tls.Certificate, []byte) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "asterisk-test"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("create cert: %v", err)
+	}
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
+
+func fakeAMITLS(t *testing.T, cert tls.Certificate) (string, int) {
+	t.Helper()
+	ln, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{Certificates: []tls.Certificate{cert}})
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	return serveFakeAMI(t, ln, "Success")
+}
+
+func TestProbeLoginTLS(t *testing.T) {
+	cert, certPEM := selfSignedCert(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// Untrusted self-signed certificate is rejected by default
+	host, port := fakeAMITLS(t, cert)
+	strict, err := NewTLSConfig(host, false, "")
+	if err != nil {
+		t.Fatalf("NewTLSConfig: %v", err)
+	}
+	if _, err := ProbeLogin(ctx, host, port, "admin", "secret", strict, nil); err == nil {
+		t.Fatal("expected verification failure for self-signed certificate")
+	}
+
+	// Trusted via CA file
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	if err := os.WriteFile(caFile, certPEM, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	host, port = fakeAMITLS(t, cert)
+	withCA, err := NewTLSConfig(host, false, caFile)
+	if err != nil {
+		t.Fatalf("NewTLSConfig with CA: %v", err)
+	}
+	if _, err := ProbeLogin(ctx, host, port, "admin", "secret", withCA, nil); err != nil {
+		t.Fatalf("probe with CA file: %v", err)
+	}
+
+	// Skip-verify accepts it without a CA
+	host, port = fakeAMITLS(t, cert)
+	insecure, _ := NewTLSConfig(host, true, "")
+	if _, err := ProbeLogin(ctx, host, port, "admin", "secret", insecure, nil); err != nil {
+		t.Fatalf("probe with skip verify: %v", err)
+	}
+}
+
+func TestNewTLSConfigBadCAFile(t *testing.T) {
+	bad := filepath.Join(t.TempDir(), "bad.pem")
+	_ = os.WriteFile(bad, []byte("not a cert"), 0o600)
+	if _, err := NewTLSConfig("localhost", false, bad); err == nil {
+		t.Fatal("expected error for CA file without certificates")
+	}
+}
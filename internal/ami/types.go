@@ -53,6 +53,7 @@ type CombinedNodeStatus struct {
 	RxKeyed     bool           // Local receiver COS
 	TxKeyed     bool           // Local transmitter PTT
 	Connections []ConnectionWithHistory // Connections with keying history
+	LinkedNodes []LinkedNode   // Every node in the linked network (adjacent and multi-hop) from XStat
 	Timestamp   time.Time      // When this data was captured
 }
 
@@ -62,6 +63,7 @@ type ConnectionWithHistory struct {
 	KeyingInfo *KeyingInfo // nil if no keying info available
 	LastHeard  string      // Human-readable last heard (e.g., "00:01:30" or "Never")
 	Mode       string      // Link mode from LinkedNodes (T/R/C/M)
+	Synthetic  bool        // Built from LinkedNodes only (no Conn line): may be multi-hop
 }
 
 // VoterReceiver represents an RTCM receiver
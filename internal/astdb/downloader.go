@@ -3,13 +3,15 @@ package astdb
 import (
 	"bufio"
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"os"
 	"path/filepath"
-	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/dbehnke/allstar-nexus/backend/models"
@@ -25,6 +27,44 @@ type Downloader struct {
 	CleanupDays  int // Days before cleaning up stale nodes (default 7)
 	logger       *zap.Logger
 	nodeInfoRepo *repository.NodeInfoRepository
+
+	runMu      sync.Mutex // serialises downloads and imports
+	mu         sync.Mutex // guards the fields below
+	onUpdate   func(Update)
+	progress   RefreshProgress
+	sources    []Source          // supplementary sources, highest priority first
+	sourceSeen map[string]string // local source path -> mtime:size at the last import
+}
+
+// ErrRefreshRunning is returned by Refresh while another download or import runs.
+var ErrRefreshRunning = errors.New("astdb refresh already running")
+
+// RefreshProgress describes the download or import in progress, or the last one.
+type RefreshProgress struct {
+	Running    bool       `json:"running"`
+	Phase      string     `json:"phase,omitempty"`     // downloading, importing or done
+	Imported   int        `json:"imported,omitempty"`  // rows written so far by the import
+	Unchanged  bool       `json:"unchanged,omitempty"` // upstream answered 304 Not Modified
+	StartedAt  *time.Time `json:"started_at,omitempty"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+	Error      string     `json:"error,omitempty"` // failure of the last run
+}
+
+// Status is the state of the node database and its refreshes.
+type Status struct {
+	URL         string          `json:"url"`
+	Sources     []string        `json:"sources,omitempty"` // supplementary sources, highest priority first
+	Path        string          `json:"path"`
+	Count       int64           `json:"count"`                  // nodes known
+	LastChecked *time.Time      `json:"last_checked,omitempty"` // last download or 304
+	FileUpdated *time.Time      `json:"file_updated,omitempty"` // when the file on disk last changed
+	Refresh     RefreshProgress `json:"refresh"`
+}
+
+// Update describes a completed refresh of the node database.
+type Update struct {
+	Count     int64     `json:"count"` // nodes now known
+	UpdatedAt time.Time `json:"updated_at"`
 }
 
 // NewDownloader creates a new astdb downloader
@@ -52,39 +92,225 @@ func (d *Downloader) SetNodeInfoRepository(repo *repository.NodeInfoRepository)
 	d.nodeInfoRepo = repo
 }
 
+// SetOnUpdate registers fn to run after every download that changed the node database
+// (a 304 from upstream does not count). It runs on the updater goroutine.
+func (d *Downloader) SetOnUpdate(fn func(Update)) {
+	d.mu.Lock()
+	d.onUpdate = fn
+	d.mu.Unlock()
+}
+
+// notifyUpdate reports a completed refresh to the registered callback, if any.
+func (d *Downloader) notifyUpdate() {
+	d.mu.Lock()
+	fn := d.onUpdate
+	d.mu.Unlock()
+	if fn == nil {
+		return
+	}
+	var count int64
+	if d.nodeInfoRepo != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		n, err := d.nodeInfoRepo.GetCount(ctx)
+		cancel()
+		if err != nil {
+			d.logger.Warn("failed to count nodes after astdb update", zap.Error(err))
+		}
+		count = n
+	} else if n, err := d.GetNodeCount(); err == nil {
+		count = int64(n)
+	}
+	fn(Update{Count: count, UpdatedAt: time.Now()})
+}
+
+func (d *Downloader) setPhase(phase string, imported int) {
+	d.mu.Lock()
+	d.progress.Phase, d.progress.Imported = phase, imported
+	d.mu.Unlock()
+}
+
+// begin marks a download (and possibly import) as running; finish records its outcome.
+func (d *Downloader) begin() {
+	now := time.Now()
+	d.mu.Lock()
+	d.progress = RefreshProgress{Running: true, Phase: "downloading", StartedAt: &now}
+	d.mu.Unlock()
+}
+
+func (d *Downloader) finish(err error) error {
+	end := time.Now()
+	d.mu.Lock()
+	d.progress.Running, d.progress.FinishedAt = false, &end
+	d.progress.Phase = "done"
+	if err != nil {
+		d.progress.Error = err.Error()
+	}
+	d.mu.Unlock()
+	return err
+}
+
+// Refresh starts a download and import in the background, unless one is already
+// running. With force set the stored validators are not sent, so the file is
+// downloaded and imported even when upstream reports it unchanged. Progress is
+// reported by Status.
+func (d *Downloader) Refresh(force bool) error {
+	if !d.runMu.TryLock() {
+		return ErrRefreshRunning
+	}
+	d.begin() // before returning, so an immediate Status call sees it running
+	go func() {
+		defer d.runMu.Unlock()
+		if err := d.finish(d.downloadAndImport(force)); err != nil {
+			d.logger.Error("manual astdb refresh failed", zap.Error(err))
+		}
+	}()
+	return nil
+}
+
+// Status reports the source, row count, last update times and refresh progress.
+func (d *Downloader) Status() Status {
+	st := Status{URL: d.URL, Sources: d.sourceLabels(), Path: d.FilePath}
+	if meta, ok := ReadMeta(d.FilePath); ok && !meta.CheckedAt.IsZero() {
+		checked := meta.CheckedAt
+		st.LastChecked = &checked
+	}
+	if info, err := os.Stat(d.FilePath); err == nil {
+		mod := info.ModTime()
+		st.FileUpdated = &mod
+	}
+	if d.nodeInfoRepo != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		st.Count, _ = d.nodeInfoRepo.GetCount(ctx)
+		cancel()
+	} else if n, err := d.GetNodeCount(); err == nil {
+		st.Count = int64(n)
+	}
+	d.mu.Lock()
+	st.Refresh = d.progress
+	d.mu.Unlock()
+	return st
+}
+
+// Meta holds the HTTP validators of the cached astdb file, stored next to it as
+// <file>.meta so the next update can be a conditional GET.
+type Meta struct {
+	ETag         string    `json:"etag,omitempty"`
+	LastModified string    `json:"last_modified,omitempty"` // upstream Last-Modified header, verbatim
+	CheckedAt    time.Time `json:"checked_at"`              // last successful download or 304
+}
+
+// MetaPath returns the sidecar path for an astdb file.
+func MetaPath(filePath string) string { return filePath + ".meta" }
+
+// ReadMeta loads the validators stored for filePath.
+func ReadMeta(filePath string) (Meta, bool) {
+	var m Meta
+	b, err := os.ReadFile(MetaPath(filePath))
+	if err != nil || json.Unmarshal(b, &m) != nil {
+		return Meta{}, false
+	}
+	return m, true
+}
+
+func writeMeta(filePath string, m Meta) error {
+	b, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+	tmp := MetaPath(filePath) + ".tmp"
+	if err := os.WriteFile(tmp, b, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, MetaPath(filePath))
+}
+
 // Download fetches the astdb file from the AllStar server
 func (d *Downloader) Download() error {
-	d.logger.Info("downloading astdb from AllStar server",
-		zap.String("url", d.URL),
-		zap.String("destination", d.FilePath))
+	d.runMu.Lock()
+	defer d.runMu.Unlock()
+	d.begin()
+	changed, err := d.download(false)
+	if err == nil && changed {
+		d.notifyUpdate()
+	}
+	return d.finish(err)
+}
 
-	// Create a temporary file
-	tmpPath := d.FilePath + ".tmp"
-	tmpFile, err := os.Create(tmpPath)
+// download fetches the main astdb file. It reports whether the file on disk changed.
+func (d *Downloader) download(force bool) (bool, error) {
+	d.setPhase("downloading", 0)
+	changed, err := d.fetch(d.URL, d.FilePath, force)
+	if err == nil && !changed {
+		d.mu.Lock()
+		d.progress.Unchanged = true
+		d.mu.Unlock()
+	}
+	return changed, err
+}
+
+// fetch downloads url to dest, sending the validators stored for dest (unless force
+// is set) so an unchanged file is not transferred again. It reports whether dest
+// changed.
+func (d *Downloader) fetch(url, dest string, force bool) (bool, error) {
+	d.logger.Info("downloading astdb",
+		zap.String("url", url),
+		zap.String("destination", dest))
+
+	req, err := http.NewRequest(http.MethodGet, url, nil)
 	if err != nil {
-		return fmt.Errorf("create temp file: %w", err)
+		return false, fmt.Errorf("build request: %w", err)
+	}
+	meta, haveMeta := ReadMeta(dest)
+	if _, statErr := os.Stat(dest); statErr == nil && haveMeta && !force {
+		if meta.ETag != "" {
+			req.Header.Set("If-None-Match", meta.ETag)
+		}
+		if meta.LastModified != "" {
+			req.Header.Set("If-Modified-Since", meta.LastModified)
+		}
 	}
-	defer func() { _ = tmpFile.Close() }()
-	defer func() { _ = os.Remove(tmpPath) }() // Clean up temp file if we error
 
 	// Download the file
 	client := &http.Client{
 		Timeout: 60 * time.Second,
 	}
-	resp, err := client.Get(d.URL)
+	resp, err := client.Do(req)
 	if err != nil {
-		return fmt.Errorf("http get: %w", err)
+		return false, fmt.Errorf("http get: %w", err)
 	}
 	defer func() { _ = resp.Body.Close() }()
 
+	if resp.StatusCode == http.StatusNotModified {
+		meta.CheckedAt = time.Now()
+		if err := writeMeta(dest, meta); err != nil {
+			d.logger.Warn("failed to update astdb meta", zap.Error(err))
+		}
+		d.logger.Info("astdb not modified upstream", zap.String("url", url), zap.String("etag", meta.ETag))
+		return false, nil
+	}
 	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("http status: %d", resp.StatusCode)
+		return false, fmt.Errorf("http status: %d", resp.StatusCode)
 	}
 
+	// Ensure directory exists
+	dir := filepath.Dir(dest)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return false, fmt.Errorf("create directory: %w", err)
+	}
+
+	// Create a temporary file
+	tmpPath := dest + ".tmp"
+	tmpFile, err := os.Create(tmpPath)
+	if err != nil {
+		return false, fmt.Errorf("create temp file: %w", err)
+	}
+	defer func() { _ = tmpFile.Close() }()
+	defer func() { _ = os.Remove(tmpPath) }() // Clean up temp file if we error
+
 	// Write to temp file
 	written, err := io.Copy(tmpFile, resp.Body)
 	if err != nil {
-		return fmt.Errorf("write file: %w", err)
+		return false, fmt.Errorf("write file: %w", err)
 	}
 
 	d.logger.Info("downloaded astdb",
@@ -92,50 +318,74 @@ func (d *Downloader) Download() error {
 
 	// Close temp file before rename
 	if err := tmpFile.Close(); err != nil {
-		return fmt.Errorf("failed to close temp file: %w", err)
+		return false, fmt.Errorf("failed to close temp file: %w", err)
 	}
 
-	// Ensure directory exists
-	dir := filepath.Dir(d.FilePath)
-	if err := os.MkdirAll(dir, 0755); err != nil {
-		return fmt.Errorf("create directory: %w", err)
+	// Atomic rename
+	if err := os.Rename(tmpPath, dest); err != nil {
+		return false, fmt.Errorf("rename file: %w", err)
 	}
 
-	// Atomic rename
-	if err := os.Rename(tmpPath, d.FilePath); err != nil {
-		return fmt.Errorf("rename file: %w", err)
+	meta = Meta{ETag: resp.Header.Get("ETag"), LastModified: resp.Header.Get("Last-Modified"), CheckedAt: time.Now()}
+	if err := writeMeta(dest, meta); err != nil {
+		d.logger.Warn("failed to write astdb meta", zap.Error(err))
 	}
 
 	d.logger.Info("astdb file updated successfully",
-		zap.String("path", d.FilePath))
+		zap.String("path", dest))
 
-	return nil
+	return true, nil
 }
 
-// DownloadAndImport downloads astdb and imports it into SQLite database
+// DownloadAndImport downloads astdb and imports it into SQLite database.
+// The import is skipped when the server reports the file unchanged.
 func (d *Downloader) DownloadAndImport() error {
+	d.runMu.Lock()
+	defer d.runMu.Unlock()
+	d.begin()
+	return d.finish(d.downloadAndImport(false))
+}
+
+func (d *Downloader) downloadAndImport(force bool) error {
 	// First download to temp file as before
-	if err := d.Download(); err != nil {
+	changed, err := d.download(force)
+	if err != nil {
 		return fmt.Errorf("download failed: %w", err)
 	}
+	if d.fetchSources(force) {
+		changed = true
+		d.mu.Lock()
+		d.progress.Unchanged = false
+		d.mu.Unlock()
+	}
 
 	// If no repository configured, just keep the file
 	if d.nodeInfoRepo == nil {
 		d.logger.Info("no repository configured, skipping database import")
 		return nil
 	}
+	if !changed {
+		return nil
+	}
 
 	// Parse and import into database
-	return d.ImportToDatabase()
+	if err := d.ImportToDatabase(); err != nil {
+		return err
+	}
+	d.notifyUpdate()
+	return nil
 }
 
-// ImportToDatabase parses the astdb file and imports it into the SQLite database
+// ImportToDatabase parses the astdb file and applies it to the SQLite database as a
+// diff: only new or changed nodes are written, and nodes no longer listed are marked
+// missing (and removed by CleanupStaleNodes once missing for CleanupDays).
 func (d *Downloader) ImportToDatabase() error {
 	if d.nodeInfoRepo == nil {
 		return fmt.Errorf("node info repository not configured")
 	}
 
 	d.logger.Info("importing astdb to database", zap.String("file", d.FilePath))
+	d.setPhase("importing", 0)
 
 	file, err := os.Open(d.FilePath)
 	if err != nil {
@@ -143,11 +393,47 @@ func (d *Downloader) ImportToDatabase() error {
 	}
 	defer func() { _ = file.Close() }()
 
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	existing, err := d.nodeInfoRepo.Current(ctx)
+	cancel()
+	if err != nil {
+		return fmt.Errorf("load current nodes: %w", err)
+	}
+
 	now := time.Now()
 	nodes := make([]models.NodeInfo, 0, 1000) // Batch buffer
+	listed := make(map[int]bool, len(existing))
 	scanner := bufio.NewScanner(file)
 	lineCount := 0
 	importCount := 0
+	unchanged := 0
+
+	overrides := d.loadOverrides()
+	apply := func(rec models.NodeInfo) error {
+		listed[rec.NodeID] = true
+		if old, ok := existing[rec.NodeID]; ok && old.MissingSince == nil && old.FetchedAt == nil &&
+			old.Callsign == rec.Callsign && old.Description == rec.Description && old.Location == rec.Location {
+			unchanged++
+			return nil
+		}
+		rec.LastSeen = now
+		nodes = append(nodes, rec)
+
+		// Batch upsert when buffer is full
+		if len(nodes) >= 1000 {
+			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			if err := d.nodeInfoRepo.BulkUpsert(ctx, nodes, 500); err != nil {
+				cancel()
+				return fmt.Errorf("bulk upsert failed: %w", err)
+			}
+			cancel()
+			importCount += len(nodes)
+			d.setPhase("importing", importCount)
+			nodes = nodes[:0] // Clear buffer
+			d.logger.Info("imported batch", zap.Int("count", importCount))
+		}
+		return nil
+	}
 
 	for scanner.Scan() {
 		lineCount++
@@ -157,48 +443,27 @@ func (d *Downloader) ImportToDatabase() error {
 		}
 
 		// Parse pipe-delimited format: NodeID|Callsign|Description|Location
-		parts := strings.Split(line, "|")
-		if len(parts) < 2 {
+		rec, ok := parseLine(line)
+		if !ok {
 			d.logger.Warn("invalid astdb line", zap.Int("line", lineCount), zap.String("content", line))
 			continue
 		}
-
-		nodeID, err := strconv.Atoi(strings.TrimSpace(parts[0]))
-		if err != nil {
-			d.logger.Warn("invalid node ID", zap.Int("line", lineCount), zap.String("node_id", parts[0]))
-			continue
+		if over, ok := overrides[rec.NodeID]; ok {
+			rec = mergeNode(rec, over)
+			delete(overrides, rec.NodeID)
 		}
-
-		callsign := strings.TrimSpace(parts[1])
-		description := ""
-		location := ""
-
-		if len(parts) > 2 {
-			description = strings.TrimSpace(parts[2])
+		if err := apply(rec); err != nil {
+			return err
 		}
-		if len(parts) > 3 {
-			location = strings.TrimSpace(parts[3])
-		}
-
-		nodes = append(nodes, models.NodeInfo{
-			NodeID:      nodeID,
-			Callsign:    callsign,
-			Description: description,
-			Location:    location,
-			LastSeen:    now,
-		})
+	}
 
-		// Batch upsert when buffer is full
-		if len(nodes) >= 1000 {
-			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-			if err := d.nodeInfoRepo.BulkUpsert(ctx, nodes, 500); err != nil {
-				cancel()
-				return fmt.Errorf("bulk upsert failed: %w", err)
-			}
-			cancel()
-			importCount += len(nodes)
-			nodes = nodes[:0] // Clear buffer
-			d.logger.Info("imported batch", zap.Int("count", importCount))
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("scan error: %w", err)
+	}
+	// Nodes only the supplementary sources list
+	for _, rec := range overrides {
+		if err := apply(rec); err != nil {
+			return err
 		}
 	}
 
@@ -213,13 +478,33 @@ func (d *Downloader) ImportToDatabase() error {
 		importCount += len(nodes)
 	}
 
-	if err := scanner.Err(); err != nil {
-		return fmt.Errorf("scan error: %w", err)
+	// Nodes no longer listed start their grace period. A truncated file must not mark
+	// most of the database missing, so skip this when it lists under half the nodes.
+	var missing []int
+	for id, n := range existing {
+		if !listed[id] && n.MissingSince == nil {
+			missing = append(missing, id)
+		}
+	}
+	if len(listed)*2 < len(existing) {
+		d.logger.Warn("astdb lists far fewer nodes than the database, not marking any missing",
+			zap.Int("listed", len(listed)), zap.Int("stored", len(existing)))
+		missing = nil
+	}
+	if len(missing) > 0 {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		err := d.nodeInfoRepo.MarkMissing(ctx, missing, now)
+		cancel()
+		if err != nil {
+			return fmt.Errorf("mark missing failed: %w", err)
+		}
 	}
 
 	d.logger.Info("astdb import completed",
 		zap.Int("lines_read", lineCount),
-		zap.Int("nodes_imported", importCount))
+		zap.Int("nodes_imported", importCount),
+		zap.Int("nodes_unchanged", unchanged),
+		zap.Int("nodes_missing", len(missing)))
 
 	// Clean up stale nodes
 	if d.CleanupDays > 0 {
@@ -277,7 +562,12 @@ func (d *Downloader) NeedsUpdate() bool {
 		return true
 	}
 
-	age := time.Since(info.ModTime())
+	// A 304 leaves the file untouched, so prefer the time of the last check
+	checked := info.ModTime()
+	if meta, ok := ReadMeta(d.FilePath); ok && meta.CheckedAt.After(checked) {
+		checked = meta.CheckedAt
+	}
+	age := time.Since(checked)
 	maxAge := time.Duration(d.UpdateHours) * time.Hour
 
 	needsUpdate := age > maxAge
@@ -307,6 +597,12 @@ func (d *Downloader) EnsureExists() error {
 		count, err := d.nodeInfoRepo.GetCount(ctx)
 		if err == nil && count == 0 {
 			d.logger.Info("database is empty, importing astdb file")
+			d.fetchSources(false)
+			return d.ImportToDatabase()
+		}
+		// Supplementary sources may have changed while we were down
+		if len(d.getSources()) > 0 && d.fetchSources(false) {
+			d.logger.Info("astdb sources changed, re-importing")
 			return d.ImportToDatabase()
 		}
 	}
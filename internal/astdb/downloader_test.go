@@ -0,0 +1,270 @@
+package astdb
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/dbehnke/allstar-nexus/backend/models"
+	"github.com/dbehnke/allstar-nexus/backend/repository"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	_ "modernc.org/sqlite"
+)
+
+func TestDownloadConditionalGET(t *testing.T) {
+	var full, notModified atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			notModified.Add(1)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		full.Add(1)
+		w.Header().Set("ETag", `"v1"`)
+		_, _ = w.Write([]byte("2000|W1AW|Hub|Newington, CT\n"))
+	}))
+	defer srv.Close()
+
+	d := NewDownloader(srv.URL, filepath.Join(t.TempDir(), "astdb.txt"), 24, nil)
+	var updates []Update
+	d.SetOnUpdate(func(u Update) { updates = append(updates, u) })
+	for i := 0; i < 2; i++ {
+		if err := d.Download(); err != nil {
+			t.Fatalf("download %d: %v", i, err)
+		}
+	}
+	if full.Load() != 1 || notModified.Load() != 1 {
+		t.Fatalf("full=%d not_modified=%d, want 1 and 1", full.Load(), notModified.Load())
+	}
+	if len(updates) != 1 || updates[0].Count != 1 {
+		t.Fatalf("updates = %+v, want one for the changed download", updates)
+	}
+	if meta, ok := ReadMeta(d.FilePath); !ok || meta.ETag != `"v1"` || meta.CheckedAt.IsZero() {
+		t.Fatalf("meta = %+v ok=%v", meta, ok)
+	}
+	if d.NeedsUpdate() {
+		t.Fatal("freshly checked file should not need an update")
+	}
+}
+
+func TestImportToDatabaseAppliesDiff(t *testing.T) {
+	gdb, err := gorm.Open(sqlite.New(sqlite.Config{DriverName: "sqlite", DSN: filepath.Join(t.TempDir(), "test.db")}), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open gorm sqlite: %v", err)
+	}
+	if err := gdb.AutoMigrate(&models.NodeInfo{}); err != nil {
+		t.Fatalf("automigrate: %v", err)
+	}
+	repo := repository.NewNodeInfoRepository(gdb)
+
+	body := "2000|W1AW|Hub|Newington, CT\n2001|K8ABC|Portable|Detroit, MI\n2002|N8XYZ|Repeater|Lansing, MI\n"
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(body))
+	}))
+	defer srv.Close()
+
+	d := NewDownloader(srv.URL, filepath.Join(t.TempDir(), "astdb.txt"), 24, nil)
+	d.SetNodeInfoRepository(repo)
+	if err := d.DownloadAndImport(); err != nil {
+		t.Fatalf("first import: %v", err)
+	}
+	var before models.NodeInfo
+	gdb.First(&before, "node_id = ?", 2000)
+
+	time.Sleep(10 * time.Millisecond)
+	body = "2000|W1AW|Hub|Newington, CT\n2001|K8ABC|Mobile|Detroit, MI\n2003|KD8NEW|New node|Flint, MI\n"
+	if err := d.DownloadAndImport(); err != nil {
+		t.Fatalf("second import: %v", err)
+	}
+
+	ctx := context.Background()
+	nodes, _ := repo.Current(ctx)
+	if len(nodes) != 4 {
+		t.Fatalf("nodes = %+v", nodes)
+	}
+	if nodes[2001].Description != "Mobile" || nodes[2003].Callsign != "KD8NEW" {
+		t.Fatalf("changed/new rows not applied: %+v %+v", nodes[2001], nodes[2003])
+	}
+	if nodes[2002].MissingSince == nil || nodes[2000].MissingSince != nil {
+		t.Fatalf("missing marks wrong: 2000=%v 2002=%v", nodes[2000].MissingSince, nodes[2002].MissingSince)
+	}
+	var after models.NodeInfo
+	gdb.First(&after, "node_id = ?", 2000)
+	if !after.UpdatedAt.Equal(before.UpdatedAt) {
+		t.Fatal("unchanged row was rewritten")
+	}
+
+	// The grace period runs from when the node went missing
+	if n, _ := repo.DeleteStaleNodes(ctx, time.Now().Add(-time.Hour)); n != 0 {
+		t.Fatalf("deleted %d nodes inside the grace period", n)
+	}
+	if n, _ := repo.DeleteStaleNodes(ctx, time.Now().Add(time.Second)); n != 1 {
+		t.Fatalf("deleted %d stale nodes, want 1", n)
+	}
+}
+
+func TestWebLookupFetch(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/stats/59999":
+			_, _ = w.Write([]byte(`{"stats":{},"node":{"callsign":"kd8new","node_frequency":"146.520","node_tone":"100.0","server":{"SiteName":"Home","Location":"Flint, MI"}}}`))
+		case "/api/stats/58888":
+			_, _ = w.Write([]byte(`{"stats":{},"node":null}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	wl := NewWebLookup(srv.URL + "/api/stats/%d")
+	info, err := wl.Fetch(context.Background(), 59999)
+	if err != nil || info == nil {
+		t.Fatalf("fetch: %+v %v", info, err)
+	}
+	if info.Callsign != "KD8NEW" || info.Description != "146.520 100.0" || info.Location != "Flint, MI" {
+		t.Fatalf("info = %+v", info)
+	}
+	for _, node := range []int{58888, 57777} {
+		if info, err := wl.Fetch(context.Background(), node); err != nil || info != nil {
+			t.Fatalf("unknown node %d: %+v %v", node, info, err)
+		}
+	}
+}
+
+func TestRefreshProgressAndForce(t *testing.T) {
+	gdb, err := gorm.Open(sqlite.New(sqlite.Config{DriverName: "sqlite", DSN: filepath.Join(t.TempDir(), "test.db")}), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open gorm sqlite: %v", err)
+	}
+	if err := gdb.AutoMigrate(&models.NodeInfo{}); err != nil {
+		t.Fatalf("automigrate: %v", err)
+	}
+	release := make(chan struct{})
+	var full atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		full.Add(1)
+		w.Header().Set("ETag", `"v1"`)
+		_, _ = w.Write([]byte("2000|W1AW|Hub|Newington, CT\n2001|K8ABC|Portable|Detroit, MI\n"))
+	}))
+	defer srv.Close()
+
+	d := NewDownloader(srv.URL, filepath.Join(t.TempDir(), "astdb.txt"), 24, nil)
+	d.SetNodeInfoRepository(repository.NewNodeInfoRepository(gdb))
+	wait := func() Status {
+		t.Helper()
+		deadline := time.Now().Add(5 * time.Second)
+		for time.Now().Before(deadline) {
+			if st := d.Status(); !st.Refresh.Running {
+				return st
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+		t.Fatal("refresh did not finish")
+		return Status{}
+	}
+
+	if err := d.Refresh(false); err != nil {
+		t.Fatal(err)
+	}
+	if st := d.Status(); !st.Refresh.Running || st.Refresh.Phase != "downloading" {
+		t.Fatalf("status while blocked = %+v", st.Refresh)
+	}
+	if err := d.Refresh(false); err != ErrRefreshRunning {
+		t.Fatalf("second refresh err = %v", err)
+	}
+	close(release)
+	st := wait()
+	if st.Count != 2 || st.Refresh.Phase != "done" || st.Refresh.Error != "" || st.LastChecked == nil || st.URL != srv.URL {
+		t.Fatalf("status = %+v", st)
+	}
+
+	if err := d.Refresh(false); err != nil {
+		t.Fatal(err)
+	}
+	if st := wait(); !st.Refresh.Unchanged || full.Load() != 1 {
+		t.Fatalf("unforced refresh: %+v full=%d", st.Refresh, full.Load())
+	}
+	if err := d.Refresh(true); err != nil {
+		t.Fatal(err)
+	}
+	if st := wait(); st.Refresh.Unchanged || full.Load() != 2 {
+		t.Fatalf("forced refresh: %+v full=%d", st.Refresh, full.Load())
+	}
+}
+
+func TestImportMergesSourcesByPriority(t *testing.T) {
+	gdb, err := gorm.Open(sqlite.New(sqlite.Config{DriverName: "sqlite", DSN: filepath.Join(t.TempDir(), "test.db")}), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open gorm sqlite: %v", err)
+	}
+	if err := gdb.AutoMigrate(&models.NodeInfo{}); err != nil {
+		t.Fatalf("automigrate: %v", err)
+	}
+	repo := repository.NewNodeInfoRepository(gdb)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/official", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"v1"`)
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		_, _ = w.Write([]byte("2000|W1AW|Hub|Newington, CT\n2001|K8ABC|Portable|Detroit, MI\n"))
+	})
+	mux.HandleFunc("/hub", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("2001|K8ABC|Hub portable|\n1999|W8HUB|Private hub|Ann Arbor, MI\n"))
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	dir := t.TempDir()
+	local := filepath.Join(dir, "local.txt")
+	if err := os.WriteFile(local, []byte("1999|W8HUB|Club hub|\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	d := NewDownloader(srv.URL+"/official", filepath.Join(dir, "astdb.txt"), 24, nil)
+	d.SetNodeInfoRepository(repo)
+	d.SetSources([]Source{{Name: "local", Path: local}, {Name: "hub", URL: srv.URL + "/hub"}})
+	if err := d.DownloadAndImport(); err != nil {
+		t.Fatalf("import: %v", err)
+	}
+
+	ctx := context.Background()
+	nodes, _ := repo.Current(ctx)
+	if len(nodes) != 3 {
+		t.Fatalf("nodes = %+v", nodes)
+	}
+	if n := nodes[2001]; n.Description != "Hub portable" || n.Location != "Detroit, MI" {
+		t.Fatalf("hub override not merged over official row: %+v", n)
+	}
+	if n := nodes[1999]; n.Description != "Club hub" || n.Location != "Ann Arbor, MI" {
+		t.Fatalf("local source should win over hub: %+v", n)
+	}
+
+	// Editing the local file alone triggers a re-import
+	time.Sleep(10 * time.Millisecond)
+	if err := os.WriteFile(local, []byte("1999|W8HUB|Renamed hub|\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.DownloadAndImport(); err != nil {
+		t.Fatalf("reimport: %v", err)
+	}
+	nodes, _ = repo.Current(ctx)
+	if nodes[1999].Description != "Renamed hub" {
+		t.Fatalf("local edit not imported: %+v", nodes[1999])
+	}
+	if st := d.Status(); len(st.Sources) != 2 || st.Sources[0] != "local" {
+		t.Fatalf("status sources = %v", st.Sources)
+	}
+}
@@ -0,0 +1,167 @@
+package astdb
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/dbehnke/allstar-nexus/backend/models"
+	"go.uber.org/zap"
+)
+
+// Source is a supplementary astdb-format file (NodeID|Callsign|Description|Location)
+// merged over the main download, e.g. a private hub's node list. Exactly one of URL
+// and Path is set.
+type Source struct {
+	Name string // for logs and status; defaults to the URL or path
+	URL  string // downloaded with conditional GETs and cached next to the main file
+	Path string // local file, re-read whenever it changes
+}
+
+func (s Source) label() string {
+	switch {
+	case s.Name != "":
+		return s.Name
+	case s.URL != "":
+		return s.URL
+	}
+	return s.Path
+}
+
+// SetSources configures supplementary sources in priority order: the first source
+// wins over the ones after it and all of them win over the main download. A node's
+// non-empty fields replace those from lower priority sources, so a supplement can
+// override just a callsign or add nodes the main file does not list.
+func (d *Downloader) SetSources(sources []Source) {
+	d.mu.Lock()
+	d.sources = append([]Source(nil), sources...)
+	d.mu.Unlock()
+}
+
+func (d *Downloader) getSources() []Source {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.sources
+}
+
+// sourceFile is where source i is read from: its cache file for URLs.
+func (d *Downloader) sourceFile(i int, s Source) string {
+	if s.URL != "" {
+		return fmt.Sprintf("%s.source%d", d.FilePath, i+1)
+	}
+	return s.Path
+}
+
+// fetchSources brings every source up to date and reports whether any changed. A
+// source that cannot be fetched is logged and its previous copy, if any, is used.
+func (d *Downloader) fetchSources(force bool) bool {
+	changed := false
+	for i, s := range d.getSources() {
+		file := d.sourceFile(i, s)
+		if s.URL != "" {
+			c, err := d.fetch(s.URL, file, force)
+			if err != nil {
+				d.logger.Warn("astdb source download failed", zap.String("source", s.label()), zap.Error(err))
+			}
+			changed = changed || c
+			continue
+		}
+		info, err := os.Stat(file)
+		if err != nil {
+			d.logger.Warn("astdb source unreadable", zap.String("source", s.label()), zap.Error(err))
+			continue
+		}
+		stamp := fmt.Sprintf("%d:%d", info.ModTime().UnixNano(), info.Size())
+		d.mu.Lock()
+		if d.sourceSeen == nil {
+			d.sourceSeen = make(map[string]string)
+		}
+		if d.sourceSeen[file] != stamp {
+			d.sourceSeen[file] = stamp
+			changed = true
+		}
+		d.mu.Unlock()
+	}
+	return changed || force
+}
+
+// loadOverrides merges every source into one record per node, higher priority
+// sources' non-empty fields winning.
+func (d *Downloader) loadOverrides() map[int]models.NodeInfo {
+	sources := d.getSources()
+	out := make(map[int]models.NodeInfo)
+	for i := len(sources) - 1; i >= 0; i-- {
+		f, err := os.Open(d.sourceFile(i, sources[i]))
+		if err != nil {
+			continue // reported by fetchSources
+		}
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			rec, ok := parseLine(scanner.Text())
+			if !ok {
+				continue
+			}
+			out[rec.NodeID] = mergeNode(out[rec.NodeID], rec)
+		}
+		if err := scanner.Err(); err != nil {
+			d.logger.Warn("astdb source read failed", zap.String("source", sources[i].label()), zap.Error(err))
+		}
+		_ = f.Close()
+	}
+	return out
+}
+
+// mergeNode returns base with the non-empty fields of over applied.
+func mergeNode(base, over models.NodeInfo) models.NodeInfo {
+	base.NodeID = over.NodeID
+	if over.Callsign != "" {
+		base.Callsign = over.Callsign
+	}
+	if over.Description != "" {
+		base.Description = over.Description
+	}
+	if over.Location != "" {
+		base.Location = over.Location
+	}
+	return base
+}
+
+// parseLine parses one astdb line. Blank lines, comments and malformed lines are
+// rejected.
+func parseLine(line string) (models.NodeInfo, bool) {
+	line = strings.TrimSpace(line)
+	if line == "" || strings.HasPrefix(line, "#") {
+		return models.NodeInfo{}, false
+	}
+	parts := strings.Split(line, "|")
+	if len(parts) < 2 {
+		return models.NodeInfo{}, false
+	}
+	nodeID, err := strconv.Atoi(strings.TrimSpace(parts[0]))
+	if err != nil {
+		return models.NodeInfo{}, false
+	}
+	rec := models.NodeInfo{NodeID: nodeID, Callsign: strings.TrimSpace(parts[1])}
+	if len(parts) > 2 {
+		rec.Description = strings.TrimSpace(parts[2])
+	}
+	if len(parts) > 3 {
+		rec.Location = strings.TrimSpace(parts[3])
+	}
+	return rec, true
+}
+
+// sourceLabels lists the configured sources for Status.
+func (d *Downloader) sourceLabels() []string {
+	sources := d.getSources()
+	if len(sources) == 0 {
+		return nil
+	}
+	out := make([]string, len(sources))
+	for i, s := range sources {
+		out[i] = s.label()
+	}
+	return out
+}
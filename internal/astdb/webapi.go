@@ -0,0 +1,82 @@
+package astdb
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/dbehnke/allstar-nexus/backend/models"
+)
+
+// DefaultWebLookupURL is the AllStarLink stats API; %d receives the node number.
+const DefaultWebLookupURL = "https://stats.allstarlink.org/api/stats/%d"
+
+// WebLookup fetches single nodes from the AllStarLink web API, for nodes registered
+// after the last astdb dump.
+type WebLookup struct {
+	URL    string // fmt template receiving the node number
+	client *http.Client
+}
+
+// NewWebLookup creates a client. An empty urlTemplate selects DefaultWebLookupURL.
+func NewWebLookup(urlTemplate string) *WebLookup {
+	if urlTemplate == "" {
+		urlTemplate = DefaultWebLookupURL
+	}
+	return &WebLookup{URL: urlTemplate, client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+// statsResponse is the subset of the stats API response used to build an astdb row.
+type statsResponse struct {
+	Node *struct {
+		Callsign  string `json:"callsign"`
+		Frequency string `json:"node_frequency"`
+		Tone      string `json:"node_tone"`
+		Server    *struct {
+			SiteName string `json:"SiteName"`
+			Location string `json:"Location"`
+		} `json:"server"`
+	} `json:"node"`
+}
+
+// Fetch returns the node as an astdb row, or nil when the API does not know it.
+func (w *WebLookup) Fetch(ctx context.Context, node int) (*models.NodeInfo, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf(w.URL, node), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("http status: %d", resp.StatusCode)
+	}
+
+	var body statsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("decode: %w", err)
+	}
+	if body.Node == nil || strings.TrimSpace(body.Node.Callsign) == "" {
+		return nil, nil
+	}
+
+	// astdb describes a node by its frequency/tone when set, otherwise by the site name
+	info := &models.NodeInfo{NodeID: node, Callsign: strings.ToUpper(strings.TrimSpace(body.Node.Callsign))}
+	info.Description = strings.TrimSpace(strings.TrimSpace(body.Node.Frequency) + " " + strings.TrimSpace(body.Node.Tone))
+	if srv := body.Node.Server; srv != nil {
+		if info.Description == "" {
+			info.Description = strings.TrimSpace(srv.SiteName)
+		}
+		info.Location = strings.TrimSpace(srv.Location)
+	}
+	return info, nil
+}
@@ -0,0 +1,154 @@
+package asterisklog
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestClassify(t *testing.T) {
+	c, err := NewClassifier([]Rule{{Category: "usb_audio", Pattern: `(?i)simpleusb.*not found`}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.now = func() time.Time { return time.Date(2025, 1, 2, 12, 0, 0, 0, time.Local) }
+
+	cases := []struct {
+		line, category, source, message string
+	}{
+		{"[2025-01-02 10:00:00] WARNING[1602]: app_rpt.c:2310 rpt_telemetry: Telemetry timed out on node 2000",
+			CategoryTelemetryTimeout, "app_rpt.c:2310", "rpt_telemetry: Telemetry timed out on node 2000"},
+		{"[2025-01-02 10:00:00.123] NOTICE[1602][C-00000001]: chan_iax2.c:9574 socket_process: Registration of '2000' rejected: 'Registration Refused'",
+			CategoryRegistrationFailure, "chan_iax2.c:9574", "socket_process: Registration of '2000' rejected: 'Registration Refused'"},
+		{"[Dec 31 23:59:00] ERROR[99] chan_simpleusb.c: simpleusb device not found",
+			"usb_audio", "chan_simpleusb.c", "simpleusb device not found"},
+		{"[2025-01-02 10:00:00] WARNING[7]: pbx.c:123 something odd", CategoryWarning, "pbx.c:123", "something odd"},
+	}
+	for _, tc := range cases {
+		e, ok := c.Classify(tc.line)
+		if !ok || e.Category != tc.category || e.Source != tc.source || e.Message != tc.message {
+			t.Errorf("Classify(%q) = %+v, %v", tc.line, e, ok)
+		}
+	}
+	if e, _ := c.Classify(cases[2].line); e.At.Year() != 2024 {
+		t.Errorf("year-less December timestamp read in January = %v", e.At)
+	}
+	for _, line := range []string{
+		"[2025-01-02 10:00:00] VERBOSE[1602]: app_rpt.c:100 rpt: keyed",
+		"  == Using SIP RTP CoS mark 5",
+		"",
+	} {
+		if e, ok := c.Classify(line); ok {
+			t.Errorf("Classify(%q) kept %+v", line, e)
+		}
+	}
+	if _, err := NewClassifier([]Rule{{Category: "x", Pattern: "("}}); err == nil {
+		t.Error("invalid pattern accepted")
+	}
+}
+
+func TestMonitorRingAndCooldown(t *testing.T) {
+	m, err := NewMonitor("/nonexistent", Options{MaxEntries: 3, AlertCategories: []string{CategoryTelemetryTimeout}, AlertCooldown: time.Minute})
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2025, 1, 2, 12, 0, 0, 0, time.Local)
+	m.now = func() time.Time { return now }
+	var alerts []Alert
+	m.SetNotify(func(ctx context.Context, a Alert) { alerts = append(alerts, a) })
+
+	timeout := "[2025-01-02 12:00:00] WARNING[1]: app_rpt.c:1 telemetry timed out"
+	m.Handle(timeout)
+	m.Handle(timeout)
+	m.Handle("[2025-01-02 12:00:00] WARNING[1]: pbx.c:1 first other")
+	now = now.Add(2 * time.Minute)
+	m.Handle(timeout)
+	m.Handle("[2025-01-02 12:00:00] WARNING[1]: pbx.c:1 last other")
+
+	if len(alerts) != 2 || alerts[1].Suppressed != 1 {
+		t.Fatalf("alerts = %+v", alerts)
+	}
+	got := m.Entries("", 0)
+	if len(got) != 3 || got[0].Message != "last other" || got[2].Message != "first other" {
+		t.Fatalf("entries = %+v", got)
+	}
+	if got := m.Entries(CategoryTelemetryTimeout, 1); len(got) != 1 {
+		t.Fatalf("filtered entries = %+v", got)
+	}
+	if st := m.Status(); st.Counts[CategoryTelemetryTimeout] != 3 || st.Counts[CategoryWarning] != 2 {
+		t.Fatalf("counts = %v", st.Counts)
+	}
+}
+
+func TestTailerFollowsRotationAndTruncation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "messages")
+	if err := os.WriteFile(path, []byte("old line\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	var mu sync.Mutex
+	var lines []string
+	tl := NewTailer(path, func(l string) {
+		mu.Lock()
+		lines = append(lines, l)
+		mu.Unlock()
+	})
+	tl.poll = 20 * time.Millisecond
+	if err := tl.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer tl.Stop()
+
+	waitFor := func(want ...string) {
+		t.Helper()
+		deadline := time.Now().Add(3 * time.Second)
+		for time.Now().Before(deadline) {
+			mu.Lock()
+			n := len(lines)
+			mu.Unlock()
+			if n >= len(want) {
+				break
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		if len(lines) != len(want) {
+			t.Fatalf("lines = %q, want %q", lines, want)
+		}
+		for i := range want {
+			if lines[i] != want[i] {
+				t.Fatalf("lines = %q, want %q", lines, want)
+			}
+		}
+	}
+	appendTo := func(s string) {
+		f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY|os.O_CREATE, 0o644)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, _ = f.WriteString(s)
+		_ = f.Close()
+	}
+
+	appendTo("one\ntw")
+	appendTo("o\n")
+	waitFor("one", "two")
+
+	// logrotate: move the file away and start a new one
+	if err := os.Rename(path, path+".1"); err != nil {
+		t.Fatal(err)
+	}
+	appendTo("three\n")
+	waitFor("one", "two", "three")
+
+	// copytruncate
+	if err := os.Truncate(path, 0); err != nil {
+		t.Fatal(err)
+	}
+	tl.Check()
+	appendTo("four\n")
+	waitFor("one", "two", "three", "four")
+}
@@ -0,0 +1,125 @@
+package asterisklog
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Built-in categories. Custom rules may add more.
+const (
+	CategoryTelemetryTimeout    = "telemetry_timeout"
+	CategoryRegistrationFailure = "registration_failure"
+	CategoryLinkFailure         = "link_failure"
+	CategoryWarning             = "warning" // any other WARNING line
+	CategoryError               = "error"   // any other ERROR line
+)
+
+// Entry is one classified log line.
+type Entry struct {
+	At       time.Time `json:"at"`
+	Level    string    `json:"level"`
+	Source   string    `json:"source,omitempty"` // e.g. app_rpt.c:1234
+	Category string    `json:"category"`
+	Message  string    `json:"message"`
+}
+
+// Rule assigns a category to lines whose message matches Pattern (a regular expression).
+type Rule struct {
+	Category string `json:"category"`
+	Pattern  string `json:"pattern"`
+}
+
+type compiledRule struct {
+	category string
+	re       *regexp.Regexp
+}
+
+// builtinRules recognise the app_rpt and chan_iax2 messages worth alerting on.
+var builtinRules = []compiledRule{
+	{CategoryTelemetryTimeout, regexp.MustCompile(`(?i)telem\w*\b.*\btimed?[ -]?out`)},
+	{CategoryRegistrationFailure, regexp.MustCompile(`(?i)regist\w*\b.*\b(timed?[ -]?out|fail|reject|refused|denied)|no authority found`)},
+	{CategoryLinkFailure, regexp.MustCompile(`(?i)\b(link|connection)\b.*\b(refused|failed|lost|dropped)`)},
+}
+
+// lineRE matches "[2025-01-06 12:00:00] WARNING[1234][C-00000001]: app_rpt.c:1234 fn: msg",
+// with or without the call id and colon (older Asterisk releases).
+var lineRE = regexp.MustCompile(`^\[([^\]]+)\]\s+([A-Z]+)\[\d+\](?:\[[^\]]*\])?:?\s+(.*)$`)
+
+// sourceRE matches the leading "file.c:line" (or "file.c:") of the message.
+var sourceRE = regexp.MustCompile(`^([\w.-]+\.c(?::\d+)?):?\s+(.*)$`)
+
+var timeLayouts = []string{"2006-01-02 15:04:05.000", "2006-01-02 15:04:05", "Jan _2 15:04:05"}
+
+// Classifier turns raw log lines into entries using custom rules ahead of the
+// built-in ones.
+type Classifier struct {
+	rules []compiledRule
+	now   func() time.Time
+}
+
+// NewClassifier compiles the custom rules.
+func NewClassifier(rules []Rule) (*Classifier, error) {
+	c := &Classifier{now: time.Now}
+	for _, r := range rules {
+		if strings.TrimSpace(r.Category) == "" {
+			return nil, fmt.Errorf("rule %q: category is required", r.Pattern)
+		}
+		re, err := regexp.Compile(r.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("rule %q: %w", r.Category, err)
+		}
+		c.rules = append(c.rules, compiledRule{r.Category, re})
+	}
+	c.rules = append(c.rules, builtinRules...)
+	return c, nil
+}
+
+// Classify parses line. ok is false for lines that are not worth keeping: anything
+// below WARNING that no rule matches, and lines that are not Asterisk log records.
+func (c *Classifier) Classify(line string) (e Entry, ok bool) {
+	m := lineRE.FindStringSubmatch(line)
+	if m == nil {
+		return Entry{}, false
+	}
+	e.At, e.Level, e.Message = c.parseTime(m[1]), m[2], strings.TrimSpace(m[3])
+	if sm := sourceRE.FindStringSubmatch(e.Message); sm != nil {
+		e.Source, e.Message = sm[1], sm[2]
+	}
+	for _, r := range c.rules {
+		if r.re.MatchString(e.Message) {
+			e.Category = r.category
+			return e, true
+		}
+	}
+	switch e.Level {
+	case "WARNING":
+		e.Category = CategoryWarning
+	case "ERROR":
+		e.Category = CategoryError
+	default:
+		return Entry{}, false
+	}
+	return e, true
+}
+
+// parseTime reads the log timestamp in local time; year-less timestamps are placed in
+// the current year and unparseable ones fall back to now.
+func (c *Classifier) parseTime(s string) time.Time {
+	now := c.now()
+	for _, layout := range timeLayouts {
+		t, err := time.ParseInLocation(layout, s, time.Local)
+		if err != nil {
+			continue
+		}
+		if t.Year() == 0 {
+			t = t.AddDate(now.Year(), 0, 0)
+			if t.After(now.Add(24 * time.Hour)) {
+				t = t.AddDate(-1, 0, 0) // December entries read in January
+			}
+		}
+		return t
+	}
+	return now
+}
@@ -0,0 +1,172 @@
+package asterisklog
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+)
+
+// Options configure a Monitor.
+type Options struct {
+	MaxEntries      int      // classified entries kept; default 500
+	Rules           []Rule   // custom classification rules, checked before the built-in ones
+	AlertCategories []string // categories that raise alerts
+	AlertCooldown   time.Duration
+}
+
+// Alert is raised for an entry in an alerting category. Suppressed counts the entries
+// of the same category swallowed by the cooldown since the previous alert.
+type Alert struct {
+	Category   string `json:"category"`
+	Entry      Entry  `json:"entry"`
+	Suppressed int    `json:"suppressed,omitempty"`
+}
+
+// Status summarises what the monitor has seen since it started.
+type Status struct {
+	Path   string         `json:"path"`
+	Counts map[string]int `json:"counts"`
+	Error  string         `json:"error,omitempty"` // last open/read failure
+}
+
+// Monitor tails the Asterisk log, keeps the most recent classified entries and raises
+// alerts for configured categories, at most one per category per AlertCooldown.
+type Monitor struct {
+	path       string
+	classifier *Classifier
+	tailer     *Tailer
+	opts       Options
+	alertOn    map[string]bool
+
+	mu         sync.Mutex
+	entries    []Entry // ring buffer, oldest first once full
+	next       int
+	counts     map[string]int
+	lastAlert  map[string]time.Time
+	suppressed map[string]int
+	notify     func(ctx context.Context, a Alert)
+	events     chan Alert
+	now        func() time.Time
+}
+
+// NewMonitor creates a monitor for path. It fails when a custom rule does not compile.
+func NewMonitor(path string, opts Options) (*Monitor, error) {
+	c, err := NewClassifier(opts.Rules)
+	if err != nil {
+		return nil, err
+	}
+	if opts.MaxEntries <= 0 {
+		opts.MaxEntries = 500
+	}
+	m := &Monitor{
+		path:       path,
+		classifier: c,
+		opts:       opts,
+		alertOn:    make(map[string]bool, len(opts.AlertCategories)),
+		counts:     make(map[string]int),
+		lastAlert:  make(map[string]time.Time),
+		suppressed: make(map[string]int),
+		events:     make(chan Alert, 16),
+		now:        time.Now,
+	}
+	for _, cat := range opts.AlertCategories {
+		m.alertOn[cat] = true
+	}
+	m.tailer = NewTailer(path, func(line string) { m.Handle(line) })
+	return m, nil
+}
+
+// SetNotify configures the alert action (e.g. a webhook).
+func (m *Monitor) SetNotify(fn func(ctx context.Context, a Alert)) {
+	m.mu.Lock()
+	m.notify = fn
+	m.mu.Unlock()
+}
+
+// Events returns alerts for broadcasting to admin dashboards.
+func (m *Monitor) Events() <-chan Alert { return m.events }
+
+// Start follows the log until Stop is called.
+func (m *Monitor) Start() error { return m.tailer.Start() }
+
+// Stop terminates the tailer.
+func (m *Monitor) Stop() { m.tailer.Stop() }
+
+// Handle classifies one raw line, records it and raises an alert when due. It reports
+// whether the line was kept.
+func (m *Monitor) Handle(line string) bool {
+	e, ok := m.classifier.Classify(line)
+	if !ok {
+		return false
+	}
+	m.mu.Lock()
+	if len(m.entries) < m.opts.MaxEntries {
+		m.entries = append(m.entries, e)
+	} else {
+		m.entries[m.next] = e
+		m.next = (m.next + 1) % m.opts.MaxEntries
+	}
+	m.counts[e.Category]++
+
+	var alert *Alert
+	if m.alertOn[e.Category] {
+		now := m.now()
+		if last, seen := m.lastAlert[e.Category]; seen && now.Sub(last) < m.opts.AlertCooldown {
+			m.suppressed[e.Category]++
+		} else {
+			alert = &Alert{Category: e.Category, Entry: e, Suppressed: m.suppressed[e.Category]}
+			m.lastAlert[e.Category] = now
+			m.suppressed[e.Category] = 0
+		}
+	}
+	notify := m.notify
+	m.mu.Unlock()
+
+	if alert != nil {
+		log.Printf("[ASTERISK LOG] %s: %s", alert.Category, e.Message)
+		select {
+		case m.events <- *alert:
+		default:
+		}
+		if notify != nil {
+			ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+			notify(ctx, *alert)
+			cancel()
+		}
+	}
+	return true
+}
+
+// Entries returns kept entries newest first, optionally limited to one category and
+// to at most limit entries (0 = all).
+func (m *Monitor) Entries(category string, limit int) []Entry {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	out := make([]Entry, 0, len(m.entries))
+	for i := len(m.entries) - 1; i >= 0; i-- {
+		e := m.entries[(m.next+i)%len(m.entries)]
+		if category != "" && e.Category != category {
+			continue
+		}
+		out = append(out, e)
+		if limit > 0 && len(out) == limit {
+			break
+		}
+	}
+	return out
+}
+
+// Status returns the followed path, per-category counts and the last tail error.
+func (m *Monitor) Status() Status {
+	m.mu.Lock()
+	st := Status{Path: m.path, Counts: make(map[string]int, len(m.counts))}
+	for k, v := range m.counts {
+		st.Counts[k] = v
+	}
+	m.mu.Unlock()
+	if err := m.tailer.Err(); err != nil {
+		st.Error = err.Error()
+	}
+	return st
+}
@@ -0,0 +1,224 @@
+// Package asterisklog follows the Asterisk messages log and classifies app_rpt and
+// channel driver warnings (telemetry timeouts, registration failures, ...) so they can
+// be shown to admins and raised as alerts.
+package asterisklog
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// maxLineLength caps a single buffered line; longer lines are split.
+const maxLineLength = 64 * 1024
+
+// Tailer follows a file like `tail -F`: it starts at the current end, picks up the new
+// file when logrotate moves or recreates it and starts over when it is truncated.
+// Changes are picked up through fsnotify on the parent directory; a slow poll covers
+// filesystems that do not deliver events.
+type Tailer struct {
+	path   string
+	onLine func(line string)
+	poll   time.Duration
+
+	mu      sync.Mutex
+	f       *os.File
+	info    os.FileInfo
+	offset  int64
+	partial []byte
+	lastErr error
+	running bool
+
+	stopCh   chan struct{}
+	stopOnce sync.Once
+	done     chan struct{}
+}
+
+// NewTailer creates a tailer calling onLine for every complete line appended to path.
+func NewTailer(path string, onLine func(line string)) *Tailer {
+	return &Tailer{
+		path:   filepath.Clean(path),
+		onLine: onLine,
+		poll:   5 * time.Second,
+		stopCh: make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+}
+
+// Start opens the file at its current end and follows it until Stop is called. A file
+// that does not exist yet is read from the start once it appears. Start only fails
+// when the parent directory cannot be watched.
+func (t *Tailer) Start() error {
+	w, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	if err := w.Add(filepath.Dir(t.path)); err != nil {
+		_ = w.Close()
+		return err
+	}
+	t.mu.Lock()
+	if err := t.openLocked(true); err != nil && !errors.Is(err, os.ErrNotExist) {
+		t.lastErr = err
+	}
+	t.running = true
+	t.mu.Unlock()
+
+	go func() {
+		defer close(t.done)
+		defer w.Close()
+		ticker := time.NewTicker(t.poll)
+		defer ticker.Stop()
+		for {
+			select {
+			case ev, ok := <-w.Events:
+				if !ok {
+					return
+				}
+				if filepath.Clean(ev.Name) == t.path {
+					t.Check()
+				}
+			case err, ok := <-w.Errors:
+				if !ok {
+					return
+				}
+				t.setErr(err)
+			case <-ticker.C:
+				t.Check()
+			case <-t.stopCh:
+				return
+			}
+		}
+	}()
+	return nil
+}
+
+// Stop terminates the follow loop and closes the file.
+func (t *Tailer) Stop() {
+	t.stopOnce.Do(func() { close(t.stopCh) })
+	t.mu.Lock()
+	running := t.running
+	t.mu.Unlock()
+	if running {
+		<-t.done
+	}
+	t.mu.Lock()
+	if t.f != nil {
+		_ = t.f.Close()
+		t.f = nil
+	}
+	t.mu.Unlock()
+}
+
+// Err returns the last error opening or reading the file, if any.
+func (t *Tailer) Err() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.lastErr
+}
+
+func (t *Tailer) setErr(err error) {
+	t.mu.Lock()
+	t.lastErr = err
+	t.mu.Unlock()
+}
+
+// Check reads anything appended since the last call, first draining and reopening the
+// file when it was replaced and rewinding when it was truncated.
+func (t *Tailer) Check() {
+	t.mu.Lock()
+	var lines []string
+	if t.f != nil {
+		lines = t.readLocked()
+	}
+	info, err := os.Stat(t.path)
+	switch {
+	case err != nil:
+		// Rotated away and not recreated yet: the old file has been drained above
+		if !errors.Is(err, os.ErrNotExist) {
+			t.lastErr = err
+		}
+	case t.f == nil || !os.SameFile(info, t.info):
+		if t.f != nil {
+			_ = t.f.Close()
+			t.f = nil
+		}
+		if err := t.openLocked(false); err != nil {
+			t.lastErr = err
+		} else {
+			lines = append(lines, t.readLocked()...)
+		}
+	case info.Size() < t.offset:
+		t.offset, t.partial = 0, nil
+		if _, err := t.f.Seek(0, io.SeekStart); err != nil {
+			t.lastErr = err
+		}
+		lines = append(lines, t.readLocked()...)
+	}
+	t.mu.Unlock()
+
+	for _, line := range lines {
+		t.onLine(line)
+	}
+}
+
+// openLocked opens the file, at its end when atEnd is set (t.mu must be held).
+func (t *Tailer) openLocked(atEnd bool) error {
+	f, err := os.Open(t.path)
+	if err != nil {
+		return err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return err
+	}
+	t.f, t.info, t.offset, t.partial, t.lastErr = f, info, 0, nil, nil
+	if atEnd {
+		if t.offset, err = f.Seek(0, io.SeekEnd); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readLocked reads to EOF and returns the complete lines (t.mu must be held).
+func (t *Tailer) readLocked() []string {
+	var lines []string
+	buf := make([]byte, 32*1024)
+	for {
+		n, err := t.f.Read(buf)
+		if n > 0 {
+			t.offset += int64(n)
+			t.partial = append(t.partial, buf[:n]...)
+			for {
+				i := bytes.IndexByte(t.partial, '\n')
+				if i < 0 {
+					break
+				}
+				lines = append(lines, string(bytes.TrimRight(t.partial[:i], "\r")))
+				t.partial = t.partial[i+1:]
+			}
+			if len(t.partial) > maxLineLength {
+				lines = append(lines, string(t.partial))
+				t.partial = nil
+			}
+		}
+		if err != nil {
+			if !errors.Is(err, io.EOF) {
+				t.lastErr = err
+			}
+			break
+		}
+	}
+	if len(t.partial) == 0 {
+		t.partial = nil // release the backing array between reads
+	}
+	return lines
+}
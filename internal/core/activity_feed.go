@@ -0,0 +1,346 @@
+package core
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Activity feed entry kinds.
+const (
+	ActivityQSO     = "qso"      // a back-and-forth between a few stations
+	ActivityNet     = "net"      // a session with many participants
+	ActivityLevelUp = "level_up" // a callsign gained a level or renown
+	ActivityLongTx  = "long_tx"  // a single long transmission
+)
+
+// ActivityKinds lists every activity kind.
+var ActivityKinds = []string{ActivityQSO, ActivityNet, ActivityLevelUp, ActivityLongTx}
+
+// ActivityFeedOptions control which activity is recorded.
+type ActivityFeedOptions struct {
+	Include            []string      // kinds to record; empty = all
+	LongTx             time.Duration // transmissions at least this long are listed on their own
+	SessionGap         time.Duration // silence that ends a session
+	QSOMinOvers        int           // speaker changes needed for a session with 2+ stations to count as a QSO
+	NetMinParticipants int           // distinct stations needed for a session to count as a net
+	MaxEntries         int
+}
+
+// ActivityParticipant is one station taking part in an activity entry.
+type ActivityParticipant struct {
+	Callsign      string `json:"callsign,omitempty"`
+	Node          int    `json:"node,omitempty"`
+	Transmissions int    `json:"transmissions,omitempty"`
+	Seconds       int    `json:"seconds,omitempty"`
+}
+
+// Label returns the callsign, or the node number when the callsign is unknown.
+func (p ActivityParticipant) Label() string {
+	if p.Callsign != "" {
+		return p.Callsign
+	}
+	return "node " + strconv.Itoa(p.Node)
+}
+
+// ActivityEntry is one notable event in the hub's activity feed.
+type ActivityEntry struct {
+	ID            string                `json:"id"`
+	Kind          string                `json:"kind"`
+	Start         time.Time             `json:"start"`
+	End           time.Time             `json:"end"`
+	Participants  []ActivityParticipant `json:"participants"`
+	Transmissions int                   `json:"transmissions,omitempty"`
+	Overs         int                   `json:"overs,omitempty"`
+	Seconds       int                   `json:"seconds,omitempty"`
+	Level         int                   `json:"level,omitempty"`
+	Renown        int                   `json:"renown,omitempty"`
+}
+
+// Title renders the entry as a one-line plain text headline.
+func (e ActivityEntry) Title() string {
+	labels := make([]string, len(e.Participants))
+	for i, p := range e.Participants {
+		labels[i] = p.Label()
+	}
+	dur := (time.Duration(e.Seconds) * time.Second).String()
+	switch e.Kind {
+	case ActivityNet:
+		return fmt.Sprintf("Net session: %d stations, %s on air", len(labels), dur)
+	case ActivityQSO:
+		return fmt.Sprintf("QSO: %s", strings.Join(labels, ", "))
+	case ActivityLongTx:
+		return fmt.Sprintf("Long transmission: %s for %s", strings.Join(labels, ", "), dur)
+	case ActivityLevelUp:
+		if e.Level == 1 && e.Renown > 0 {
+			return fmt.Sprintf("%s reached renown %d", strings.Join(labels, ", "), e.Renown)
+		}
+		return fmt.Sprintf("%s reached level %d", strings.Join(labels, ", "), e.Level)
+	}
+	return e.Kind
+}
+
+// Summary renders the entry's details as plain text.
+func (e ActivityEntry) Summary() string {
+	if e.Kind == ActivityLevelUp {
+		return e.Title() + "."
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s to %s UTC: %d transmissions", e.Start.UTC().Format("2006-01-02 15:04"), e.End.UTC().Format("15:04"), e.Transmissions)
+	if e.Overs > 0 {
+		fmt.Fprintf(&b, ", %d overs", e.Overs)
+	}
+	b.WriteString(".")
+	if e.Kind != ActivityLongTx {
+		for _, p := range e.Participants {
+			fmt.Fprintf(&b, "\n%s: %d transmissions, %s", p.Label(), p.Transmissions, time.Duration(p.Seconds)*time.Second)
+		}
+	}
+	return b.String()
+}
+
+// activitySession accumulates transmissions until a SessionGap of silence.
+type activitySession struct {
+	start, end    time.Time
+	participants  map[string]*ActivityParticipant
+	order         []string // participant keys in order of first transmission
+	lastKey       string
+	overs         int
+	transmissions int
+	seconds       int
+}
+
+// ActivityFeed turns the talker event stream (and level-ups reported by the tally
+// service) into a short list of notable events: QSOs, net sessions, long transmissions
+// and level-ups. Sessions are classified once a SessionGap of silence closes them.
+type ActivityFeed struct {
+	mu       sync.Mutex
+	opts     ActivityFeedOptions
+	include  map[string]bool
+	notify   func(ActivityEntry)
+	entries  []ActivityEntry // oldest first
+	session  *activitySession
+	now      func() time.Time
+	stopCh   chan struct{}
+	stopOnce sync.Once
+}
+
+// NewActivityFeed creates a feed. Zero values select 5 minute long transmissions, a 3
+// minute session gap, 4 overs per QSO, 4 net participants and 50 entries.
+func NewActivityFeed(opts ActivityFeedOptions) *ActivityFeed {
+	if opts.LongTx <= 0 {
+		opts.LongTx = 5 * time.Minute
+	}
+	if opts.SessionGap <= 0 {
+		opts.SessionGap = 3 * time.Minute
+	}
+	if opts.QSOMinOvers <= 0 {
+		opts.QSOMinOvers = 4
+	}
+	if opts.NetMinParticipants <= 0 {
+		opts.NetMinParticipants = 4
+	}
+	if opts.MaxEntries <= 0 {
+		opts.MaxEntries = 50
+	}
+	include := make(map[string]bool, len(ActivityKinds))
+	for _, k := range opts.Include {
+		include[strings.ToLower(strings.TrimSpace(k))] = true
+	}
+	if len(include) == 0 {
+		for _, k := range ActivityKinds {
+			include[k] = true
+		}
+	}
+	return &ActivityFeed{
+		opts:    opts,
+		include: include,
+		now:     time.Now,
+		stopCh:  make(chan struct{}),
+	}
+}
+
+// SetNotify configures an action run (in its own goroutine) for every new entry, e.g.
+// to post it to a chat.
+func (f *ActivityFeed) SetNotify(fn func(ActivityEntry)) {
+	f.mu.Lock()
+	f.notify = fn
+	f.mu.Unlock()
+}
+
+// Observe records a completed transmission. It only takes a short lock, so it is safe
+// to register as a StateManager talker hook.
+func (f *ActivityFeed) Observe(evt TalkerEvent) {
+	if evt.Kind != "TX_STOP" {
+		return
+	}
+	at := evt.At
+	if at.IsZero() {
+		at = f.now()
+	}
+	start := at.Add(-time.Duration(evt.Duration) * time.Second)
+	key := strings.ToUpper(evt.Callsign)
+	if key == "" {
+		key = "#" + strconv.Itoa(evt.Node)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if s := f.session; s != nil && start.Sub(s.end) > f.opts.SessionGap {
+		f.closeSessionLocked()
+	}
+	s := f.session
+	if s == nil {
+		s = &activitySession{start: start, participants: make(map[string]*ActivityParticipant)}
+		f.session = s
+	}
+	p, ok := s.participants[key]
+	if !ok {
+		p = &ActivityParticipant{Callsign: evt.Callsign, Node: evt.Node}
+		s.participants[key] = p
+		s.order = append(s.order, key)
+	}
+	p.Transmissions++
+	p.Seconds += evt.Duration
+	if s.lastKey != "" && s.lastKey != key {
+		s.overs++
+	}
+	s.lastKey = key
+	s.transmissions++
+	s.seconds += evt.Duration
+	if at.After(s.end) {
+		s.end = at
+	}
+
+	if time.Duration(evt.Duration)*time.Second >= f.opts.LongTx {
+		f.addLocked(ActivityEntry{
+			Kind:          ActivityLongTx,
+			Start:         start,
+			End:           at,
+			Participants:  []ActivityParticipant{{Callsign: evt.Callsign, Node: evt.Node, Transmissions: 1, Seconds: evt.Duration}},
+			Transmissions: 1,
+			Seconds:       evt.Duration,
+		})
+	}
+}
+
+// LevelUp records a callsign reaching a new level (or renown).
+func (f *ActivityFeed) LevelUp(callsign string, level, renown int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	now := f.now()
+	f.addLocked(ActivityEntry{
+		Kind:         ActivityLevelUp,
+		Start:        now,
+		End:          now,
+		Participants: []ActivityParticipant{{Callsign: callsign}},
+		Level:        level,
+		Renown:       renown,
+	})
+}
+
+// Start closes idle sessions every interval until Stop is called.
+func (f *ActivityFeed) Start(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				f.Flush(false)
+			case <-f.stopCh:
+				return
+			}
+		}
+	}()
+}
+
+// Stop terminates the background loop.
+func (f *ActivityFeed) Stop() {
+	f.stopOnce.Do(func() { close(f.stopCh) })
+}
+
+// Flush closes the current session once it has been silent for SessionGap (or
+// immediately when force is set), adding a QSO or net entry if it qualifies.
+func (f *ActivityFeed) Flush(force bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.session == nil || (!force && f.now().Sub(f.session.end) < f.opts.SessionGap) {
+		return
+	}
+	f.closeSessionLocked()
+}
+
+// Entries returns the recorded entries, newest first.
+func (f *ActivityFeed) Entries() []ActivityEntry {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	out := make([]ActivityEntry, len(f.entries))
+	for i, e := range f.entries {
+		out[len(out)-1-i] = e
+	}
+	return out
+}
+
+// closeSessionLocked classifies and discards the current session (f.mu must be held).
+func (f *ActivityFeed) closeSessionLocked() {
+	s := f.session
+	f.session = nil
+	if s == nil {
+		return
+	}
+	kind := ""
+	switch n := len(s.participants); {
+	case n >= f.opts.NetMinParticipants:
+		kind = ActivityNet
+	case n >= 2 && s.overs >= f.opts.QSOMinOvers:
+		kind = ActivityQSO
+	default:
+		return
+	}
+	participants := make([]ActivityParticipant, 0, len(s.order))
+	for _, key := range s.order {
+		participants = append(participants, *s.participants[key])
+	}
+	// Most active first; ties keep the order stations first transmitted in
+	sort.SliceStable(participants, func(i, j int) bool { return participants[i].Seconds > participants[j].Seconds })
+	f.addLocked(ActivityEntry{
+		Kind:          kind,
+		Start:         s.start,
+		End:           s.end,
+		Participants:  participants,
+		Transmissions: s.transmissions,
+		Overs:         s.overs,
+		Seconds:       s.seconds,
+	})
+}
+
+// addLocked appends an entry if its kind is included (f.mu must be held). IDs derive
+// from the entry itself so they stay stable for feed readers across restarts.
+func (f *ActivityFeed) addLocked(e ActivityEntry) {
+	if !f.include[e.Kind] {
+		return
+	}
+	// Prefer the node number so IDs do not reveal callsigns when a viewer may not see them
+	subject := ""
+	if len(e.Participants) > 0 {
+		subject = strconv.Itoa(e.Participants[0].Node)
+		if e.Participants[0].Node == 0 {
+			subject = strings.ToUpper(e.Participants[0].Callsign)
+		}
+	}
+	e.ID = fmt.Sprintf("%s-%d-%s", e.Kind, e.Start.Unix(), subject)
+	if e.Kind == ActivityLevelUp {
+		e.ID = fmt.Sprintf("%s-%s-%d-%d", e.Kind, subject, e.Renown, e.Level)
+	}
+	f.entries = append(f.entries, e)
+	if f.notify != nil {
+		go f.notify(e)
+	}
+	if over := len(f.entries) - f.opts.MaxEntries; over > 0 {
+		f.entries = append([]ActivityEntry(nil), f.entries[over:]...)
+	}
+}
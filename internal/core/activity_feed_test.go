@@ -0,0 +1,99 @@
+package core
+
+import (
+	"testing"
+	"time"
+)
+
+func stopAt(at time.Time, call string, node, secs int) TalkerEvent {
+	return TalkerEvent{At: at, Kind: "TX_STOP", Node: node, Callsign: call, Duration: secs}
+}
+
+func TestActivityFeedClassifiesSessions(t *testing.T) {
+	now := time.Date(2025, 1, 6, 19, 0, 0, 0, time.UTC)
+	f := NewActivityFeed(ActivityFeedOptions{SessionGap: 2 * time.Minute, QSOMinOvers: 3, NetMinParticipants: 4, LongTx: 3 * time.Minute})
+	f.now = func() time.Time { return now }
+
+	// A QSO: two stations alternating, one long over
+	at := now
+	for i, call := range []string{"W1AW", "K8ABC", "W1AW", "K8ABC"} {
+		secs := 30
+		if i == 2 {
+			secs = 200
+		}
+		at = at.Add(time.Duration(secs+5) * time.Second)
+		f.Observe(stopAt(at, call, 2000+i%2, secs))
+	}
+	if got := f.Entries(); len(got) != 1 || got[0].Kind != ActivityLongTx || got[0].Participants[0].Callsign != "W1AW" {
+		t.Fatalf("entries before close = %+v", got)
+	}
+	now = at.Add(time.Minute)
+	f.Flush(false)
+	if len(f.Entries()) != 1 {
+		t.Fatal("session closed before the gap elapsed")
+	}
+	now = at.Add(3 * time.Minute)
+	f.Flush(false)
+	got := f.Entries()
+	if len(got) != 2 || got[0].Kind != ActivityQSO || got[0].Overs != 3 || got[0].Transmissions != 4 || len(got[0].Participants) != 2 {
+		t.Fatalf("qso = %+v", got)
+	}
+	if got[0].Participants[0].Callsign != "W1AW" {
+		t.Fatalf("most active participant should come first: %+v", got[0].Participants)
+	}
+
+	// A net: five check-ins from four stations
+	at = now.Add(10 * time.Minute)
+	for i, call := range []string{"N8NET", "W1AW", "K8ABC", "", "N8NET"} {
+		at = at.Add(20 * time.Second)
+		f.Observe(stopAt(at, call, 3000+i, 15))
+	}
+	// A kerchunk after a long silence starts a new session and closes the net
+	f.Observe(stopAt(at.Add(10*time.Minute), "KD8LONE", 4000, 1))
+	got = f.Entries()
+	if got[0].Kind != ActivityNet || len(got[0].Participants) != 4 {
+		t.Fatalf("net = %+v", got[0])
+	}
+	now = at.Add(20 * time.Minute)
+	f.Flush(false)
+	if len(f.Entries()) != 3 {
+		t.Fatalf("single kerchunk should not be listed: %+v", f.Entries())
+	}
+}
+
+func TestActivityFeedIncludeAndCap(t *testing.T) {
+	f := NewActivityFeed(ActivityFeedOptions{Include: []string{"level_up"}, MaxEntries: 2, LongTx: time.Second})
+	f.Observe(stopAt(time.Now(), "W1AW", 2000, 600))
+	for level := 2; level <= 4; level++ {
+		f.LevelUp("W1AW", level, 0)
+	}
+	f.Flush(true)
+	got := f.Entries()
+	if len(got) != 2 || got[0].Level != 4 || got[1].Level != 3 {
+		t.Fatalf("entries = %+v", got)
+	}
+	if got[0].ID == got[1].ID {
+		t.Fatalf("duplicate ids: %s", got[0].ID)
+	}
+}
+
+func TestActivityFeedNotify(t *testing.T) {
+	f := NewActivityFeed(ActivityFeedOptions{Include: []string{"level_up"}})
+	got := make(chan ActivityEntry, 2)
+	f.SetNotify(func(e ActivityEntry) { got <- e })
+	f.Observe(stopAt(time.Now(), "W1AW", 2000, 600)) // long_tx is not included
+	f.LevelUp("W1AW", 5, 0)
+	select {
+	case e := <-got:
+		if e.Kind != ActivityLevelUp || e.Title() != "W1AW reached level 5" {
+			t.Fatalf("notified %+v", e)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("no notification")
+	}
+	select {
+	case e := <-got:
+		t.Fatalf("unexpected notification %+v", e)
+	case <-time.After(20 * time.Millisecond):
+	}
+}
@@ -0,0 +1,170 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/dbehnke/allstar-nexus/backend/models"
+	"github.com/dbehnke/allstar-nexus/backend/repository"
+	"github.com/dbehnke/allstar-nexus/internal/ami"
+)
+
+// AuditActionAMICommand is the audit log action for AMI console commands.
+const AuditActionAMICommand = "ami_command"
+
+// DefaultAMIConsoleCommands are the read-only CLI commands allowed when none are configured.
+var DefaultAMIConsoleCommands = []string{
+	"rpt stats",
+	"rpt lstats",
+	"rpt nodes",
+	"iax2 show peers",
+	"iax2 show registry",
+	"core show uptime",
+}
+
+// ErrCommandNotAllowed is returned for commands outside the console whitelist.
+var ErrCommandNotAllowed = errors.New("command not allowed")
+
+// AMIConsoleResult is the output of one console command.
+type AMIConsoleResult struct {
+	Command    string   `json:"command"`
+	Lines      []string `json:"lines"`
+	Bytes      int      `json:"bytes"`
+	Truncated  bool     `json:"truncated"`
+	DurationMS int64    `json:"duration_ms"`
+}
+
+// AMIConsole runs whitelisted CLI commands over AMI on behalf of an operator and
+// records every attempt, allowed or not, in the audit log.
+type AMIConsole struct {
+	sender   AMICommandSender
+	allowed  []string
+	maxBytes int
+	timeout  time.Duration
+	audit    *repository.AuditRepo
+}
+
+// NewAMIConsole creates a console. allowed lists permitted commands; each may be
+// followed by node numbers (e.g. "rpt stats" allows "rpt stats 43732"). Empty allowed
+// selects DefaultAMIConsoleCommands; zero maxBytes and timeout select 64 KiB and 10s.
+func NewAMIConsole(sender AMICommandSender, allowed []string, maxBytes int, timeout time.Duration, audit *repository.AuditRepo) *AMIConsole {
+	if len(allowed) == 0 {
+		allowed = DefaultAMIConsoleCommands
+	}
+	norm := make([]string, 0, len(allowed))
+	for _, c := range allowed {
+		if c = strings.ToLower(strings.Join(strings.Fields(c), " ")); c != "" {
+			norm = append(norm, c)
+		}
+	}
+	if maxBytes <= 0 {
+		maxBytes = 64 << 10
+	}
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+	return &AMIConsole{sender: sender, allowed: norm, maxBytes: maxBytes, timeout: timeout, audit: audit}
+}
+
+// Allowed returns the permitted commands.
+func (c *AMIConsole) Allowed() []string { return append([]string(nil), c.allowed...) }
+
+// MaxBytes returns the output limit per command.
+func (c *AMIConsole) MaxBytes() int { return c.maxBytes }
+
+// Check normalizes command and verifies it against the whitelist. Arguments after a
+// whitelisted command must be node numbers, so nothing can be appended to change what
+// the command does.
+func (c *AMIConsole) Check(command string) (string, error) {
+	for _, r := range command {
+		if unicode.IsControl(r) && r != '\t' {
+			return "", ErrCommandNotAllowed // CR/LF would inject extra AMI headers
+		}
+	}
+	fields := strings.Fields(command)
+	normalized := strings.Join(fields, " ")
+	lower := strings.ToLower(normalized)
+	for _, a := range c.allowed {
+		if lower == a {
+			return normalized, nil
+		}
+		if rest, ok := strings.CutPrefix(lower, a+" "); ok && allDigits(strings.Fields(rest)) {
+			return normalized, nil
+		}
+	}
+	return "", ErrCommandNotAllowed
+}
+
+func allDigits(args []string) bool {
+	for _, a := range args {
+		for _, r := range a {
+			if r < '0' || r > '9' {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// Run checks and executes command for actor, truncating output to the size limit.
+func (c *AMIConsole) Run(ctx context.Context, actor, remoteAddr, command string) (AMIConsoleResult, error) {
+	entry := models.AuditEntry{Actor: actor, RemoteAddr: remoteAddr, Action: AuditActionAMICommand, Detail: truncateString(command, 512)}
+	normalized, err := c.Check(command)
+	if err != nil {
+		entry.Outcome = models.AuditOutcomeDenied
+		c.record(&entry)
+		return AMIConsoleResult{}, err
+	}
+	entry.Detail = normalized
+
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+	start := time.Now()
+	msg, err := c.sender.SendCommand(ctx, normalized)
+	res := AMIConsoleResult{Command: normalized, DurationMS: time.Since(start).Milliseconds()}
+	entry.DurationMS = res.DurationMS
+	if err != nil {
+		entry.Outcome, entry.Error = models.AuditOutcomeError, truncateString(err.Error(), 512)
+		c.record(&entry)
+		return res, err
+	}
+	out := ami.CommandOutput(msg)
+	res.Bytes = len(out)
+	if len(out) > c.maxBytes {
+		out, res.Truncated = truncateString(out, c.maxBytes), true
+	}
+	res.Lines = strings.Split(strings.TrimRight(out, "\r\n"), "\n")
+	for i, l := range res.Lines {
+		res.Lines[i] = strings.TrimSuffix(l, "\r")
+	}
+	entry.Outcome, entry.OutputBytes = models.AuditOutcomeOK, res.Bytes
+	c.record(&entry)
+	return res, nil
+}
+
+func (c *AMIConsole) record(e *models.AuditEntry) {
+	log.Printf("[AUDIT] %s by %s from %s: %q -> %s", e.Action, e.Actor, e.RemoteAddr, e.Detail, e.Outcome)
+	if c.audit == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := c.audit.Record(ctx, e); err != nil {
+		log.Printf("[AUDIT] failed to record %s: %v", e.Action, err)
+	}
+}
+
+// truncateString shortens s to at most n bytes without splitting a UTF-8 sequence.
+func truncateString(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && s[n]&0xC0 == 0x80 {
+		n--
+	}
+	return s[:n]
+}
@@ -0,0 +1,49 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestAMIConsoleCheck(t *testing.T) {
+	c := NewAMIConsole(&fakeCommandSender{}, nil, 0, 0, nil)
+	cases := []struct {
+		in, want string
+		ok       bool
+	}{
+		{"rpt stats 43732", "rpt stats 43732", true},
+		{"  RPT   lstats\t43732 ", "RPT lstats 43732", true},
+		{"iax2 show peers", "iax2 show peers", true},
+		{"rpt stats", "rpt stats", true},
+		{"rpt fun 43732 *3", "", false},
+		{"rpt stats 43732 extra", "", false},
+		{"rpt statsx", "", false},
+		{"iax2 show peers\r\nAction: Command\r\nCommand: core restart now", "", false},
+		{"core restart now", "", false},
+		{"", "", false},
+	}
+	for _, tc := range cases {
+		got, err := c.Check(tc.in)
+		if tc.ok != (err == nil) || got != tc.want {
+			t.Errorf("Check(%q) = %q, %v; want %q ok=%v", tc.in, got, err, tc.want, tc.ok)
+		}
+		if err != nil && !errors.Is(err, ErrCommandNotAllowed) {
+			t.Errorf("Check(%q) error %v is not ErrCommandNotAllowed", tc.in, err)
+		}
+	}
+}
+
+func TestAMIConsoleRunDeniedDoesNotSend(t *testing.T) {
+	sender := &fakeCommandSender{}
+	c := NewAMIConsole(sender, []string{"rpt stats"}, 0, 0, nil)
+	if _, err := c.Run(context.Background(), "root@example.com", "", "iax2 show peers"); !errors.Is(err, ErrCommandNotAllowed) {
+		t.Fatalf("err = %v", err)
+	}
+	if _, err := c.Run(context.Background(), "root@example.com", "", "rpt stats 1999"); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if cmds := sender.commands(); len(cmds) != 1 || cmds[0] != "rpt stats 1999" {
+		t.Fatalf("sent %v", cmds)
+	}
+}
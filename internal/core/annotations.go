@@ -0,0 +1,69 @@
+package core
+
+// nodeAnnotation is the admin-maintained label set for one node.
+type nodeAnnotation struct {
+	tags  []string
+	notes string
+}
+
+// SetNodeAnnotation attaches tags and notes to node so links to it carry them in
+// snapshots and link updates. Empty tags and notes remove the annotation. Links that
+// are already connected are updated in place; clients pick the change up from the
+// next STATUS_UPDATE.
+func (sm *StateManager) SetNodeAnnotation(node int, tags []string, notes string) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	if len(tags) == 0 && notes == "" {
+		delete(sm.annotations, node)
+	} else {
+		sm.annotations[node] = nodeAnnotation{tags: append([]string(nil), tags...), notes: notes}
+	}
+	changed := false
+	for i := range sm.state.LinksDetailed {
+		if sm.state.LinksDetailed[i].Node == node {
+			sm.annotateLinkLocked(&sm.state.LinksDetailed[i])
+			changed = true
+		}
+	}
+	if changed {
+		sm.state.StateVersion++
+	}
+}
+
+// SetLinkHealth attaches probe results to links to node, or removes them when h is nil.
+// Like annotations, connected links are updated in place and clients see the change
+// with the next STATUS_UPDATE.
+func (sm *StateManager) SetLinkHealth(node int, h *LinkHealth) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	if h == nil {
+		delete(sm.linkHealth, node)
+	} else {
+		sm.linkHealth[node] = *h
+	}
+	changed := false
+	for i := range sm.state.LinksDetailed {
+		if sm.state.LinksDetailed[i].Node == node {
+			sm.annotateLinkLocked(&sm.state.LinksDetailed[i])
+			changed = true
+		}
+	}
+	if changed {
+		sm.state.StateVersion++
+	}
+}
+
+// annotateLinkLocked copies the node's annotation and probe health onto li. Caller
+// holds sm.mu.
+func (sm *StateManager) annotateLinkLocked(li *LinkInfo) {
+	li.Health = nil
+	if h, ok := sm.linkHealth[li.Node]; ok {
+		li.Health = &h
+	}
+	a, ok := sm.annotations[li.Node]
+	if !ok {
+		li.Tags, li.Notes = nil, ""
+		return
+	}
+	li.Tags, li.Notes = a.tags, a.notes
+}
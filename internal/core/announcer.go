@@ -0,0 +1,225 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dbehnke/allstar-nexus/backend/models"
+	"github.com/dbehnke/allstar-nexus/backend/repository"
+)
+
+// AuditActionAnnouncement is the audit log action for announcements being submitted,
+// canceled and played.
+const AuditActionAnnouncement = "announcement"
+
+// ErrInvalidAnnouncement wraps validation failures from Announcer.Submit.
+var ErrInvalidAnnouncement = errors.New("invalid announcement")
+
+// announcementFileTTL is how long rendered audio is kept; app_rpt plays asynchronously,
+// so files cannot be removed right after the play command.
+const announcementFileTTL = time.Hour
+
+// AnnouncerOptions control where announcements are rendered and how they are played.
+type AnnouncerOptions struct {
+	Nodes       []int  // local nodes announcements may play on; the first is the default
+	SoundsDir   string // rendered audio; must be readable by Asterisk
+	PlayCommand string // fmt template receiving the node and the sound file without extension
+	MaxChars    int
+}
+
+// Announcer turns admin-submitted text into speech and plays it over a node via AMI,
+// immediately or at a scheduled time. Every submission, cancellation and playback is
+// recorded in the audit log.
+type Announcer struct {
+	repo   *repository.AnnouncementRepo
+	audit  *repository.AuditRepo
+	sender AMICommandSender
+	synth  func(ctx context.Context, text, out string) error
+	opts   AnnouncerOptions
+	now    func() time.Time
+
+	playMu   sync.Mutex // plays one announcement at a time
+	wake     chan struct{}
+	stopCh   chan struct{}
+	stopOnce sync.Once
+}
+
+// NewAnnouncer creates an announcer. synth renders text to an 8 kHz mono WAV file (see
+// tts.Synthesizer). Empty PlayCommand selects "rpt localplay %d %s"; zero MaxChars
+// selects 500.
+func NewAnnouncer(repo *repository.AnnouncementRepo, audit *repository.AuditRepo, sender AMICommandSender, synth func(ctx context.Context, text, out string) error, opts AnnouncerOptions) *Announcer {
+	if opts.PlayCommand == "" {
+		opts.PlayCommand = "rpt localplay %d %s"
+	}
+	if opts.MaxChars <= 0 {
+		opts.MaxChars = 500
+	}
+	if abs, err := filepath.Abs(opts.SoundsDir); err == nil {
+		opts.SoundsDir = abs // Asterisk does not share our working directory
+	}
+	return &Announcer{
+		repo:   repo,
+		audit:  audit,
+		sender: sender,
+		synth:  synth,
+		opts:   opts,
+		now:    time.Now,
+		wake:   make(chan struct{}, 1),
+		stopCh: make(chan struct{}),
+	}
+}
+
+// Nodes returns the nodes announcements may be played on.
+func (a *Announcer) Nodes() []int { return a.opts.Nodes }
+
+// Submit validates and stores an announcement. Node 0 selects the first local node; a
+// zero or past at plays it as soon as possible.
+func (a *Announcer) Submit(ctx context.Context, actor, text string, node int, at time.Time) (*models.Announcement, error) {
+	text = strings.Join(strings.Fields(text), " ")
+	if text == "" {
+		return nil, fmt.Errorf("%w: text is required", ErrInvalidAnnouncement)
+	}
+	if n := len([]rune(text)); n > a.opts.MaxChars {
+		return nil, fmt.Errorf("%w: text is %d characters, the limit is %d", ErrInvalidAnnouncement, n, a.opts.MaxChars)
+	}
+	if node == 0 && len(a.opts.Nodes) > 0 {
+		node = a.opts.Nodes[0]
+	}
+	if !slices.Contains(a.opts.Nodes, node) {
+		return nil, fmt.Errorf("%w: %d is not a local node", ErrInvalidAnnouncement, node)
+	}
+	now := a.now()
+	if at.IsZero() || at.Before(now) {
+		at = now
+	}
+	ann := &models.Announcement{CreatedBy: actor, Text: text, Node: node, ScheduledAt: at.UTC(), Status: models.AnnouncementPending}
+	if err := a.repo.Create(ctx, ann); err != nil {
+		return nil, err
+	}
+	a.record(actor, fmt.Sprintf("submit #%d on %d at %s: %s", ann.ID, node, ann.ScheduledAt.Format(time.RFC3339), text), nil)
+	if !at.After(now) {
+		select {
+		case a.wake <- struct{}{}:
+		default:
+		}
+	}
+	return ann, nil
+}
+
+// Cancel cancels a pending announcement and reports whether there was one to cancel.
+func (a *Announcer) Cancel(ctx context.Context, actor string, id uint) (bool, error) {
+	ok, err := a.repo.Cancel(ctx, id)
+	if err == nil && ok {
+		a.record(actor, fmt.Sprintf("cancel #%d", id), nil)
+	}
+	return ok, err
+}
+
+// Start plays due announcements every interval, and right after an immediate
+// submission, until Stop is called.
+func (a *Announcer) Start(interval time.Duration) {
+	if interval <= 0 {
+		interval = 15 * time.Second
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+			case <-a.wake:
+			case <-a.stopCh:
+				return
+			}
+			a.PlayDue(context.Background())
+		}
+	}()
+}
+
+// Stop terminates the background loop.
+func (a *Announcer) Stop() {
+	a.stopOnce.Do(func() { close(a.stopCh) })
+}
+
+// PlayDue plays every due announcement in schedule order and returns how many played.
+func (a *Announcer) PlayDue(ctx context.Context) int {
+	a.playMu.Lock()
+	defer a.playMu.Unlock()
+	a.removeOldFiles()
+	due, err := a.repo.Due(ctx, a.now())
+	if err != nil {
+		log.Printf("[ANNOUNCE] failed to load due announcements: %v", err)
+		return 0
+	}
+	played := 0
+	for _, ann := range due {
+		if ok, err := a.repo.Claim(ctx, ann.ID); err != nil || !ok {
+			continue
+		}
+		playErr := a.play(ctx, ann)
+		msg := ""
+		if playErr != nil {
+			msg = truncateString(playErr.Error(), 512)
+			log.Printf("[ANNOUNCE] #%d on %d failed: %v", ann.ID, ann.Node, playErr)
+		} else {
+			played++
+		}
+		if err := a.repo.Finish(ctx, ann.ID, a.now(), msg); err != nil {
+			log.Printf("[ANNOUNCE] failed to record outcome of #%d: %v", ann.ID, err)
+		}
+		a.record(ann.CreatedBy, fmt.Sprintf("play #%d on %d", ann.ID, ann.Node), playErr)
+	}
+	return played
+}
+
+func (a *Announcer) play(ctx context.Context, ann models.Announcement) error {
+	if err := os.MkdirAll(a.opts.SoundsDir, 0o755); err != nil {
+		return err
+	}
+	base := filepath.Join(a.opts.SoundsDir, fmt.Sprintf("announcement-%d", ann.ID))
+	synthCtx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+	if err := a.synth(synthCtx, ann.Text, base+".wav"); err != nil {
+		return err
+	}
+	cmdCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	// app_rpt takes the sound file without its extension
+	if _, err := a.sender.SendCommand(cmdCtx, fmt.Sprintf(a.opts.PlayCommand, ann.Node, base)); err != nil {
+		return fmt.Errorf("play on %d: %w", ann.Node, err)
+	}
+	return nil
+}
+
+func (a *Announcer) removeOldFiles() {
+	files, _ := filepath.Glob(filepath.Join(a.opts.SoundsDir, "announcement-*.wav"))
+	for _, f := range files {
+		if st, err := os.Stat(f); err == nil && a.now().Sub(st.ModTime()) > announcementFileTTL {
+			_ = os.Remove(f)
+		}
+	}
+}
+
+func (a *Announcer) record(actor, detail string, err error) {
+	e := &models.AuditEntry{Actor: actor, Action: AuditActionAnnouncement, Detail: truncateString(detail, 512), Outcome: models.AuditOutcomeOK}
+	if err != nil {
+		e.Outcome, e.Error = models.AuditOutcomeError, truncateString(err.Error(), 512)
+	}
+	log.Printf("[AUDIT] %s by %s: %q -> %s", e.Action, e.Actor, e.Detail, e.Outcome)
+	if a.audit == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := a.audit.Record(ctx, e); err != nil {
+		log.Printf("[AUDIT] failed to record %s: %v", e.Action, err)
+	}
+}
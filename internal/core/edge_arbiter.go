@@ -0,0 +1,81 @@
+package core
+
+import "time"
+
+// edgeSource identifies which AMI path observed a keying change. Higher values win.
+type edgeSource int
+
+const (
+	edgeSourcePoll  edgeSource = iota // XStat/SawStat poll: complete but up to one interval stale
+	edgeSourceEvent                   // RPT_ALINKS event: real time
+)
+
+func (s edgeSource) String() string {
+	if s == edgeSourceEvent {
+		return "event"
+	}
+	return "poll"
+}
+
+// edgeHoldoff is how long an edge from a higher-priority source shields its node
+// from contradicting lower-priority observations. A poll answered just after an
+// ALINKS key-up can still report the link idle; after the holdoff the poll is
+// trusted again so a missed event cannot leave a node stuck keyed.
+const edgeHoldoff = 3 * time.Second
+
+type edgeKey struct {
+	localNode int
+	node      int // 0 = local node TX (global talker edge)
+}
+
+type edgeState struct {
+	kind   string // TX_START or TX_STOP
+	at     time.Time
+	source edgeSource
+}
+
+// edgeArbiter decides which TX edges are published when the event and polling paths
+// both observe the same keying change. It is not safe for concurrent use; the
+// StateManager calls it with sm.mu held.
+type edgeArbiter struct {
+	holdoff time.Duration
+	last    map[edgeKey]edgeState
+}
+
+func newEdgeArbiter(holdoff time.Duration) *edgeArbiter {
+	return &edgeArbiter{holdoff: holdoff, last: make(map[edgeKey]edgeState)}
+}
+
+// accept reports whether a kind edge for node (on localNode) observed by src at the
+// given time should be emitted, and whether it is the first edge seen for that key.
+// Repeats of the current state are dropped; a change reported by a lower-priority
+// source within the holdoff of a higher-priority edge is treated as stale.
+func (a *edgeArbiter) accept(localNode, node int, kind string, src edgeSource, at time.Time) (ok, first bool) {
+	k := edgeKey{localNode: localNode, node: node}
+	prev, seen := a.last[k]
+	if seen && prev.kind == kind {
+		if src > prev.source {
+			// A better source confirmed the state; let it shield the edge from here on
+			prev.source = src
+			a.last[k] = prev
+		}
+		return false, false
+	}
+	if a.shielded(localNode, node, src, at) {
+		return false, false
+	}
+	a.last[k] = edgeState{kind: kind, at: at, source: src}
+	return true, !seen
+}
+
+// shielded reports whether a recent higher-priority edge overrides what src observes
+// for the key, so callers can also leave link keying state untouched.
+func (a *edgeArbiter) shielded(localNode, node int, src edgeSource, at time.Time) bool {
+	prev, seen := a.last[edgeKey{localNode: localNode, node: node}]
+	return seen && src < prev.source && at.Sub(prev.at) < a.holdoff
+}
+
+// forget drops state for a link that went away so a reconnect starts fresh.
+func (a *edgeArbiter) forget(localNode, node int) {
+	delete(a.last, edgeKey{localNode: localNode, node: node})
+}
@@ -0,0 +1,134 @@
+package core
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dbehnke/allstar-nexus/internal/ami"
+)
+
+const arbiterLocalNode = 1000
+
+func newArbiterTestState() *StateManager {
+	sm := NewStateManager()
+	sm.SetNodeID(arbiterLocalNode)
+	return sm
+}
+
+func applyALinks(sm *StateManager, alinks string) {
+	sm.apply(ami.Message{Headers: map[string]string{"RPT_ALINKS": alinks}})
+}
+
+func applyPoll(sm *StateManager, keyed map[int]bool) {
+	combined := &ami.CombinedNodeStatus{Node: arbiterLocalNode, Timestamp: time.Now()}
+	for node, k := range keyed {
+		combined.Connections = append(combined.Connections, ami.ConnectionWithHistory{
+			Connection: ami.Connection{Node: node, IsKeyed: k},
+		})
+	}
+	sm.ApplyCombinedStatus(combined)
+}
+
+// talkerKinds returns the TX_* kinds logged for node, oldest first.
+func talkerKinds(sm *StateManager, node int) []string {
+	var kinds []string
+	for _, evt := range sm.log.Snapshot() {
+		if evt.Node == node {
+			kinds = append(kinds, evt.Kind)
+		}
+	}
+	return kinds
+}
+
+func assertKinds(t *testing.T, got []string, want ...string) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("talker events = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("talker events = %v, want %v", got, want)
+		}
+	}
+}
+
+func TestEdgeArbiter_InterleavedALinksAndPollAgree(t *testing.T) {
+	sm := newArbiterTestState()
+
+	applyALinks(sm, "2,2001TU,2002TU")
+	applyPoll(sm, map[int]bool{2001: false, 2002: false})
+	assertKinds(t, talkerKinds(sm, 2001)) // idle links are not talker edges
+
+	applyALinks(sm, "2,2001TK,2002TU")
+	applyPoll(sm, map[int]bool{2001: true, 2002: false}) // poll sees the same key-up
+	applyALinks(sm, "2,2001TK,2002TU")                   // repeated event
+	applyALinks(sm, "2,2001TU,2002TU")
+	applyPoll(sm, map[int]bool{2001: false, 2002: false})
+
+	assertKinds(t, talkerKinds(sm, 2001), "TX_START", "TX_STOP")
+	assertKinds(t, talkerKinds(sm, 2002))
+}
+
+func TestEdgeArbiter_StalePollDoesNotSplitTransmission(t *testing.T) {
+	sm := newArbiterTestState()
+
+	applyALinks(sm, "1,2001TK")
+	// SawStat answered before it saw the key-up: contradicts the fresh event
+	applyPoll(sm, map[int]bool{2001: false})
+	for _, li := range sm.Snapshot().LinksDetailed {
+		if li.Node == 2001 && !li.CurrentTx {
+			t.Fatal("stale poll must not unkey the link")
+		}
+	}
+	// The next poll catches up
+	applyPoll(sm, map[int]bool{2001: true})
+	applyALinks(sm, "1,2001TU")
+
+	assertKinds(t, talkerKinds(sm, 2001), "TX_START", "TX_STOP")
+}
+
+func TestEdgeArbiter_PollOnlyEdges(t *testing.T) {
+	sm := newArbiterTestState()
+
+	applyPoll(sm, map[int]bool{3001: false})
+	applyPoll(sm, map[int]bool{3001: true})
+	applyPoll(sm, map[int]bool{3001: true})
+	applyPoll(sm, map[int]bool{3001: false})
+	// A late ALINKS confirming the unkey is not a second stop
+	applyALinks(sm, "1,3001TU")
+
+	assertKinds(t, talkerKinds(sm, 3001), "TX_START", "TX_STOP")
+}
+
+func TestEdgeArbiter_HoldoffAndPriority(t *testing.T) {
+	a := newEdgeArbiter(3 * time.Second)
+	t0 := time.Now()
+
+	if ok, first := a.accept(1000, 2001, "TX_START", edgeSourceEvent, t0); !ok || !first {
+		t.Fatalf("first event edge: ok=%v first=%v", ok, first)
+	}
+	if ok, _ := a.accept(1000, 2001, "TX_START", edgeSourcePoll, t0.Add(time.Second)); ok {
+		t.Fatal("poll repeating the event edge must be suppressed")
+	}
+	if ok, _ := a.accept(1000, 2001, "TX_STOP", edgeSourcePoll, t0.Add(2*time.Second)); ok {
+		t.Fatal("poll contradicting a fresh event edge must be suppressed")
+	}
+	// After the holdoff the poll is trusted (e.g. the ALINKS unkey was missed)
+	if ok, first := a.accept(1000, 2001, "TX_STOP", edgeSourcePoll, t0.Add(5*time.Second)); !ok || first {
+		t.Fatalf("poll after holdoff: ok=%v first=%v", ok, first)
+	}
+	// An event may always override a poll edge
+	if ok, _ := a.accept(1000, 2001, "TX_START", edgeSourceEvent, t0.Add(5500*time.Millisecond)); !ok {
+		t.Fatal("event edge after poll edge must be accepted")
+	}
+
+	// Keys are per local node
+	if ok, first := a.accept(1001, 2001, "TX_START", edgeSourcePoll, t0); !ok || !first {
+		t.Fatal("same remote node on another local node is independent")
+	}
+
+	a.forget(1000, 2001)
+	if ok, first := a.accept(1000, 2001, "TX_START", edgeSourcePoll, t0.Add(6*time.Second)); !ok || !first {
+		t.Fatal("forgotten link starts fresh")
+	}
+}
@@ -0,0 +1,223 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/dbehnke/allstar-nexus/internal/ami"
+)
+
+// IAX alert kinds.
+const (
+	IAXRegistrationLapsed   = "IAX_REGISTRATION_LAPSED"
+	IAXRegistrationRestored = "IAX_REGISTRATION_RESTORED"
+	IAXPeerUnreachable      = "IAX_PEER_UNREACHABLE"
+	IAXPeerReachable        = "IAX_PEER_REACHABLE"
+)
+
+// IAXStatus is the last parsed `iax2 show registry` and `iax2 show peers` output.
+type IAXStatus struct {
+	Registrations []ami.IAXRegistration `json:"registrations"`
+	Peers         []ami.IAXPeer         `json:"peers"`
+	CheckedAt     *time.Time            `json:"checked_at,omitempty"`
+	Error         string                `json:"error,omitempty"` // last poll failure; the lists are from the last success
+}
+
+// IAXAlert is emitted when a registration lapses or a qualified peer stops answering,
+// and again when it recovers.
+type IAXAlert struct {
+	Kind     string    `json:"kind"`
+	Host     string    `json:"host"`               // registrar, or peer address
+	Username string    `json:"username,omitempty"` // registration username (usually the node number)
+	Peer     string    `json:"peer,omitempty"`     // peer name
+	State    string    `json:"state"`              // registration state or peer status
+	Since    time.Time `json:"since"`              // first poll the problem was seen (or, for recoveries, when it started)
+	At       time.Time `json:"at"`
+}
+
+type iaxHealth struct {
+	failures int
+	since    time.Time
+	alerted  bool
+}
+
+// IAXMonitor polls IAX2 registrations and peers over AMI. A registration that is not
+// Registered, or a qualified peer that is UNREACHABLE, on lapseAfter consecutive polls
+// raises an alert; a matching recovery alert follows when it comes back. Single failed
+// polls are ignored because registrations pass through "Request Sent" on every refresh.
+type IAXMonitor struct {
+	mu         sync.Mutex
+	sender     AMICommandSender
+	lapseAfter int
+	status     IAXStatus
+	regs       map[string]*iaxHealth
+	peers      map[string]*iaxHealth
+	notify     func(ctx context.Context, a IAXAlert)
+	events     chan IAXAlert
+	now        func() time.Time
+	stopCh     chan struct{}
+	stopOnce   sync.Once
+}
+
+// NewIAXMonitor creates a monitor. lapseAfter below 1 defaults to 2 polls.
+func NewIAXMonitor(sender AMICommandSender, lapseAfter int) *IAXMonitor {
+	if lapseAfter < 1 {
+		lapseAfter = 2
+	}
+	return &IAXMonitor{
+		sender:     sender,
+		lapseAfter: lapseAfter,
+		status:     IAXStatus{Registrations: []ami.IAXRegistration{}, Peers: []ami.IAXPeer{}},
+		regs:       make(map[string]*iaxHealth),
+		peers:      make(map[string]*iaxHealth),
+		events:     make(chan IAXAlert, 16),
+		now:        time.Now,
+		stopCh:     make(chan struct{}),
+	}
+}
+
+// SetNotify configures an extra alert action (e.g. a webhook) run for every alert.
+func (m *IAXMonitor) SetNotify(fn func(ctx context.Context, a IAXAlert)) {
+	m.mu.Lock()
+	m.notify = fn
+	m.mu.Unlock()
+}
+
+// Events returns alerts for broadcasting to admin dashboards.
+func (m *IAXMonitor) Events() <-chan IAXAlert { return m.events }
+
+// Status returns the last poll result.
+func (m *IAXMonitor) Status() IAXStatus {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	st := m.status
+	st.Registrations = append([]ami.IAXRegistration(nil), st.Registrations...)
+	st.Peers = append([]ami.IAXPeer(nil), st.Peers...)
+	return st
+}
+
+// Start polls immediately and then every interval until Stop is called.
+func (m *IAXMonitor) Start(interval time.Duration) {
+	if interval <= 0 {
+		interval = time.Minute
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+			if _, err := m.Poll(ctx); err != nil {
+				log.Printf("[IAX] poll failed: %v", err)
+			}
+			cancel()
+			select {
+			case <-ticker.C:
+			case <-m.stopCh:
+				return
+			}
+		}
+	}()
+}
+
+// Stop terminates the background poll loop.
+func (m *IAXMonitor) Stop() {
+	m.stopOnce.Do(func() { close(m.stopCh) })
+}
+
+// Poll runs both commands once, updates the status and returns the alerts it fired.
+func (m *IAXMonitor) Poll(ctx context.Context) ([]IAXAlert, error) {
+	regMsg, err := m.sender.SendCommand(ctx, "iax2 show registry")
+	var peerMsg ami.Message
+	if err == nil {
+		peerMsg, err = m.sender.SendCommand(ctx, "iax2 show peers")
+	}
+	m.mu.Lock()
+	if err != nil {
+		m.status.Error = err.Error()
+		m.mu.Unlock()
+		return nil, fmt.Errorf("iax2 status: %w", err)
+	}
+	now := m.now()
+	regs := ami.ParseIAXRegistry(ami.CommandOutput(regMsg))
+	peers := ami.ParseIAXPeers(ami.CommandOutput(peerMsg))
+	m.status = IAXStatus{Registrations: regs, Peers: peers, CheckedAt: &now}
+
+	var alerts []IAXAlert
+	seen := make(map[string]bool, len(regs))
+	for _, r := range regs {
+		key := r.Host + "|" + r.Username
+		seen[key] = true
+		base := IAXAlert{Host: r.Host, Username: r.Username, State: r.State, At: now}
+		if a, ok := m.track(m.regs, key, !r.Registered, now, base, IAXRegistrationLapsed, IAXRegistrationRestored); ok {
+			alerts = append(alerts, a)
+		}
+	}
+	forget(m.regs, seen)
+
+	seen = make(map[string]bool, len(peers))
+	for _, p := range peers {
+		if !p.Monitored() {
+			continue
+		}
+		seen[p.Name] = true
+		base := IAXAlert{Host: p.Host, Peer: p.Name, State: p.Status, At: now}
+		if a, ok := m.track(m.peers, p.Name, p.Status == "UNREACHABLE", now, base, IAXPeerUnreachable, IAXPeerReachable); ok {
+			alerts = append(alerts, a)
+		}
+	}
+	forget(m.peers, seen)
+	notify := m.notify
+	m.mu.Unlock()
+
+	for _, a := range alerts {
+		log.Printf("[IAX] %s host=%s username=%s peer=%s state=%q", a.Kind, a.Host, a.Username, a.Peer, a.State)
+		select {
+		case m.events <- a:
+		default:
+		}
+		if notify != nil {
+			nctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+			notify(nctx, a)
+			cancel()
+		}
+	}
+	return alerts, nil
+}
+
+// track updates the health of one registration or peer and returns the alert to fire,
+// if any (m.mu must be held).
+func (m *IAXMonitor) track(health map[string]*iaxHealth, key string, failing bool, now time.Time, base IAXAlert, downKind, upKind string) (IAXAlert, bool) {
+	h, ok := health[key]
+	if !ok {
+		h = &iaxHealth{}
+		health[key] = h
+	}
+	if !failing {
+		fired := h.alerted
+		base.Kind, base.Since = upKind, h.since
+		*h = iaxHealth{}
+		return base, fired
+	}
+	if h.failures == 0 {
+		h.since = now
+	}
+	h.failures++
+	if h.alerted || h.failures < m.lapseAfter {
+		return IAXAlert{}, false
+	}
+	h.alerted = true
+	base.Kind, base.Since = downKind, h.since
+	return base, true
+}
+
+// forget drops health for entries no longer listed (e.g. removed from iax.conf).
+func forget(health map[string]*iaxHealth, seen map[string]bool) {
+	for k := range health {
+		if !seen[k] {
+			delete(health, k)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package core
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/dbehnke/allstar-nexus/internal/ami"
+)
+
+type iaxFakeSender struct {
+	registry, peers string
+}
+
+func (f *iaxFakeSender) SendCommand(ctx context.Context, command string) (ami.Message, error) {
+	out := f.registry
+	if command == "iax2 show peers" {
+		out = f.peers
+	}
+	raw := []string{"Response: Success"}
+	for _, l := range strings.Split(out, "\n") {
+		raw = append(raw, "Output: "+l)
+	}
+	return ami.Message{Raw: raw}, nil
+}
+
+const (
+	iaxRegHeader  = "Host                  dnsmgr  Username    Perceived             Refresh  State\n"
+	iaxPeerHeader = "Name/Username    Host                 Mask             Port          Status      Description\n"
+)
+
+func iaxRegistry(state string) string {
+	return iaxRegHeader + "162.248.92.131:4569   Y       43732       203.0.113.10:4569          60  " + state + "\n"
+}
+
+func iaxPeers(status string) string {
+	return iaxPeerHeader + "hub-link         198.51.100.20        255.255.255.255  4569          " + status + "\n"
+}
+
+func TestIAXMonitorLapseAndRestore(t *testing.T) {
+	sender := &iaxFakeSender{registry: iaxRegistry("Registered"), peers: iaxPeers("OK (20 ms)")}
+	m := NewIAXMonitor(sender, 2)
+	poll := func() []IAXAlert {
+		t.Helper()
+		alerts, err := m.Poll(context.Background())
+		if err != nil {
+			t.Fatalf("poll: %v", err)
+		}
+		return alerts
+	}
+
+	if a := poll(); len(a) != 0 {
+		t.Fatalf("healthy poll fired %+v", a)
+	}
+	if st := m.Status(); len(st.Registrations) != 1 || len(st.Peers) != 1 || st.CheckedAt == nil || st.Peers[0].LatencyMS != 20 {
+		t.Fatalf("status = %+v", st)
+	}
+
+	// A single refresh in flight is not a lapse
+	sender.registry = iaxRegistry("Request Sent")
+	if a := poll(); len(a) != 0 {
+		t.Fatalf("first failed poll fired %+v", a)
+	}
+	sender.registry = iaxRegistry("Registered")
+	poll()
+
+	sender.registry, sender.peers = iaxRegistry("Rejected"), iaxPeers("UNREACHABLE")
+	poll()
+	a := poll()
+	if len(a) != 2 || a[0].Kind != IAXRegistrationLapsed || a[0].Username != "43732" || a[0].State != "Rejected" ||
+		a[1].Kind != IAXPeerUnreachable || a[1].Peer != "hub-link" {
+		t.Fatalf("lapse alerts = %+v", a)
+	}
+	if a := poll(); len(a) != 0 {
+		t.Fatalf("lapse alerted twice: %+v", a)
+	}
+
+	sender.registry, sender.peers = iaxRegistry("Registered"), iaxPeers("LAGGED (900 ms)")
+	a = poll()
+	if len(a) != 2 || a[0].Kind != IAXRegistrationRestored || a[1].Kind != IAXPeerReachable || a[0].Since.IsZero() {
+		t.Fatalf("restore alerts = %+v", a)
+	}
+	select {
+	case evt := <-m.Events():
+		if evt.Kind != IAXRegistrationLapsed {
+			t.Fatalf("first event = %+v", evt)
+		}
+	default:
+		t.Fatal("no events queued")
+	}
+}
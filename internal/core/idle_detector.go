@@ -0,0 +1,173 @@
+package core
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+)
+
+// IdleStatus is a point-in-time view of hub-wide idle tracking.
+type IdleStatus struct {
+	LastActivity  time.Time  `json:"last_activity"`
+	IdleSeconds   int        `json:"idle_seconds"`
+	LastTriggered *time.Time `json:"last_triggered,omitempty"`
+	Triggers      int        `json:"triggers"`
+	ActiveHours   bool       `json:"active_hours"`
+}
+
+// IdleDetector tracks how long the hub has been silent based on the talker stream
+// and invokes a reminder action (AMI command, webhook, ...) after a configured
+// period of silence, optionally restricted to a daily window of hours.
+type IdleDetector struct {
+	mu            sync.Mutex
+	threshold     time.Duration
+	startHour     int
+	endHour       int
+	repeat        bool
+	lastActivity  time.Time
+	lastTriggered time.Time
+	triggers      int
+	fired         bool // true once the action ran for the current silence period (when repeat is off)
+	action        func(ctx context.Context, idle time.Duration)
+	now           func() time.Time
+	stopCh        chan struct{}
+	stopOnce      sync.Once
+}
+
+// NewIdleDetector creates a detector that fires after threshold of silence.
+// startHour/endHour (0-23, local time) bound when reminders may fire; equal values mean all day.
+// A window such as 22 -> 6 wraps past midnight.
+func NewIdleDetector(threshold time.Duration, startHour, endHour int, repeat bool) *IdleDetector {
+	if threshold <= 0 {
+		threshold = 30 * time.Minute
+	}
+	return &IdleDetector{
+		threshold:    threshold,
+		startHour:    clampHour(startHour),
+		endHour:      clampHour(endHour),
+		repeat:       repeat,
+		lastActivity: time.Now(),
+		now:          time.Now,
+		stopCh:       make(chan struct{}),
+	}
+}
+
+// SetAction configures the callback invoked when the idle threshold is crossed.
+func (d *IdleDetector) SetAction(fn func(ctx context.Context, idle time.Duration)) {
+	d.mu.Lock()
+	d.action = fn
+	d.mu.Unlock()
+}
+
+// Observe records talker activity. It is safe to register as a StateManager talker hook.
+func (d *IdleDetector) Observe(evt TalkerEvent) {
+	d.mu.Lock()
+	if evt.At.After(d.lastActivity) {
+		d.lastActivity = evt.At
+	} else if evt.At.IsZero() {
+		d.lastActivity = d.now()
+	}
+	d.fired = false
+	d.mu.Unlock()
+}
+
+// Status returns the current idle tracking state.
+func (d *IdleDetector) Status() IdleStatus {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	now := d.now()
+	st := IdleStatus{
+		LastActivity: d.lastActivity,
+		IdleSeconds:  int(now.Sub(d.lastActivity).Seconds()),
+		Triggers:     d.triggers,
+		ActiveHours:  d.withinHours(now),
+	}
+	if !d.lastTriggered.IsZero() {
+		t := d.lastTriggered
+		st.LastTriggered = &t
+	}
+	return st
+}
+
+// Start runs the periodic idle check until Stop is called.
+func (d *IdleDetector) Start(checkInterval time.Duration) {
+	if checkInterval <= 0 {
+		checkInterval = 30 * time.Second
+	}
+	go func() {
+		ticker := time.NewTicker(checkInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				d.Check()
+			case <-d.stopCh:
+				return
+			}
+		}
+	}()
+}
+
+// Stop terminates the background check loop.
+func (d *IdleDetector) Stop() {
+	d.stopOnce.Do(func() { close(d.stopCh) })
+}
+
+// Check evaluates the idle state once and fires the action if due. It returns true when the action ran.
+func (d *IdleDetector) Check() bool {
+	d.mu.Lock()
+	now := d.now()
+	idle := now.Sub(d.lastActivity)
+	if idle < d.threshold || !d.withinHours(now) || d.action == nil {
+		d.mu.Unlock()
+		return false
+	}
+	if d.fired && !d.repeat {
+		d.mu.Unlock()
+		return false
+	}
+	// When repeating, wait a full threshold between reminders during continued silence.
+	if d.repeat && !d.lastTriggered.IsZero() && d.lastTriggered.After(d.lastActivity) && now.Sub(d.lastTriggered) < d.threshold {
+		d.mu.Unlock()
+		return false
+	}
+	d.fired = true
+	d.lastTriggered = now
+	d.triggers++
+	action := d.action
+	d.mu.Unlock()
+
+	log.Printf("[IDLE] hub idle for %s, triggering reminder action", idle.Truncate(time.Second))
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+	action(ctx, idle)
+	return true
+}
+
+// withinHours reports whether t falls inside the configured daily window (d.mu must be held).
+func (d *IdleDetector) withinHours(t time.Time) bool {
+	if d.startHour == d.endHour {
+		return true
+	}
+	return inHourWindow(t.Hour(), d.startHour, d.endHour)
+}
+
+// inHourWindow reports whether hour h lies in [start, end), wrapping past midnight
+// when start > end.
+func inHourWindow(h, start, end int) bool {
+	if start < end {
+		return h >= start && h < end
+	}
+	return h >= start || h < end
+}
+
+func clampHour(h int) int {
+	if h < 0 {
+		return 0
+	}
+	if h > 23 {
+		return 23
+	}
+	return h
+}
@@ -0,0 +1,68 @@
+package core
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestIdleDetectorFiresOncePerSilence(t *testing.T) {
+	base := time.Date(2025, 1, 6, 12, 0, 0, 0, time.Local)
+	now := base
+	d := NewIdleDetector(10*time.Minute, 0, 0, false)
+	d.now = func() time.Time { return now }
+	d.lastActivity = base
+	fired := 0
+	d.SetAction(func(ctx context.Context, idle time.Duration) { fired++ })
+
+	now = base.Add(5 * time.Minute)
+	if d.Check() {
+		t.Fatalf("should not fire before threshold")
+	}
+	now = base.Add(11 * time.Minute)
+	if !d.Check() {
+		t.Fatalf("expected fire after threshold")
+	}
+	now = base.Add(30 * time.Minute)
+	if d.Check() {
+		t.Fatalf("should not fire again without repeat")
+	}
+	// New activity re-arms the detector
+	d.Observe(TalkerEvent{At: now, Kind: "TX_STOP"})
+	now = now.Add(11 * time.Minute)
+	if !d.Check() {
+		t.Fatalf("expected fire after new silence period")
+	}
+	if fired != 2 {
+		t.Fatalf("expected 2 fires, got %d", fired)
+	}
+}
+
+func TestIdleDetectorRepeatAndHours(t *testing.T) {
+	base := time.Date(2025, 1, 6, 23, 0, 0, 0, time.Local)
+	now := base
+	// Window 22:00 -> 02:00 wraps midnight
+	d := NewIdleDetector(10*time.Minute, 22, 2, true)
+	d.now = func() time.Time { return now }
+	d.lastActivity = base
+	fired := 0
+	d.SetAction(func(ctx context.Context, idle time.Duration) { fired++ })
+
+	now = base.Add(10 * time.Minute)
+	d.Check()
+	now = base.Add(15 * time.Minute)
+	d.Check() // too soon after last reminder
+	now = base.Add(20 * time.Minute)
+	d.Check()
+	if fired != 2 {
+		t.Fatalf("expected 2 repeated fires, got %d", fired)
+	}
+	// 03:00 is outside the window
+	now = base.Add(4 * time.Hour)
+	if d.Check() {
+		t.Fatalf("should not fire outside configured hours")
+	}
+	if st := d.Status(); st.ActiveHours || st.Triggers != 2 {
+		t.Fatalf("unexpected status: %+v", st)
+	}
+}
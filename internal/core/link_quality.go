@@ -0,0 +1,145 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/dbehnke/allstar-nexus/backend/models"
+	"github.com/dbehnke/allstar-nexus/backend/repository"
+	"github.com/dbehnke/allstar-nexus/internal/ami"
+)
+
+// LinkQualityMonitor samples per-connection audio statistics over AMI. `rpt lstats`
+// lists each local node's connections with their peer address but carries no packet
+// counters, so it is joined on the peer address with `iax2 show netstats`, which has
+// RTT, jitter, loss and packet counts per IAX2 channel. Connections without a matching
+// channel that has data (EchoLink, calls still being set up) are not recorded.
+type LinkQualityMonitor struct {
+	sender    AMICommandSender
+	repo      *repository.LinkQualityRepo
+	nodes     []int
+	retention time.Duration
+	now       func() time.Time
+
+	stopCh   chan struct{}
+	stopOnce sync.Once
+}
+
+// NewLinkQualityMonitor creates a monitor for the given local nodes. Samples older than
+// retention are pruned hourly; 0 keeps everything.
+func NewLinkQualityMonitor(sender AMICommandSender, repo *repository.LinkQualityRepo, nodes []int, retention time.Duration) *LinkQualityMonitor {
+	return &LinkQualityMonitor{
+		sender:    sender,
+		repo:      repo,
+		nodes:     nodes,
+		retention: retention,
+		now:       time.Now,
+		stopCh:    make(chan struct{}),
+	}
+}
+
+// Start samples immediately and then every interval until Stop is called.
+func (m *LinkQualityMonitor) Start(interval time.Duration) {
+	if interval <= 0 {
+		interval = time.Minute
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		prune := time.NewTicker(time.Hour)
+		defer ticker.Stop()
+		defer prune.Stop()
+		m.prune()
+		for {
+			ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+			if _, err := m.Poll(ctx); err != nil {
+				log.Printf("[LINK QUALITY] poll failed: %v", err)
+			}
+			cancel()
+			select {
+			case <-ticker.C:
+			case <-prune.C:
+				m.prune()
+			case <-m.stopCh:
+				return
+			}
+		}
+	}()
+}
+
+// Stop terminates the background poll loop.
+func (m *LinkQualityMonitor) Stop() {
+	m.stopOnce.Do(func() { close(m.stopCh) })
+}
+
+func (m *LinkQualityMonitor) prune() {
+	if m.repo == nil || m.retention <= 0 {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if n, err := m.repo.Prune(ctx, m.now().Add(-m.retention)); err != nil {
+		log.Printf("[LINK QUALITY] prune failed: %v", err)
+	} else if n > 0 {
+		log.Printf("[LINK QUALITY] pruned %d samples", n)
+	}
+}
+
+// Poll samples every local node once, records the samples and returns them.
+func (m *LinkQualityMonitor) Poll(ctx context.Context) ([]models.LinkQuality, error) {
+	msg, err := m.sender.SendCommand(ctx, "iax2 show netstats")
+	if err != nil {
+		return nil, err
+	}
+	// Several connections can share a peer address (nodes on one server); hand the
+	// channels out in order so each is used once.
+	channels := make(map[string][]ami.IAXNetStat)
+	for _, ns := range ami.ParseIAXNetstats(ami.CommandOutput(msg)) {
+		if ns.RTTMS < 0 || ns.Local.Kpkts < 0 {
+			continue
+		}
+		channels[ns.Peer] = append(channels[ns.Peer], ns)
+	}
+
+	now := m.now()
+	rows := make([]models.LinkQuality, 0)
+	for _, local := range m.nodes {
+		msg, err := m.sender.SendCommand(ctx, fmt.Sprintf("rpt lstats %d", local))
+		if err != nil {
+			return nil, err
+		}
+		for _, ls := range ami.ParseRptLStats(ami.CommandOutput(msg)) {
+			chans := channels[ls.Peer]
+			if ls.Peer == "" || len(chans) == 0 {
+				continue
+			}
+			ns := chans[0]
+			channels[ls.Peer] = chans[1:]
+			rows = append(rows, models.LinkQuality{
+				LocalNode:  local,
+				Node:       ls.Node,
+				CheckedAt:  now,
+				Peer:       ls.Peer,
+				Direction:  ls.Direction,
+				Reconnects: ls.Reconnects,
+				RTTMs:      ns.RTTMS,
+				RxJitterMs: ns.Local.JitterMS,
+				RxLost:     ns.Local.Lost,
+				RxLostPct:  ns.Local.LostPct,
+				RxDropped:  ns.Local.Dropped,
+				RxOOO:      ns.Local.OOO,
+				RxKpkts:    ns.Local.Kpkts,
+				TxJitterMs: ns.Remote.JitterMS,
+				TxLost:     ns.Remote.Lost,
+				TxLostPct:  ns.Remote.LostPct,
+				TxKpkts:    ns.Remote.Kpkts,
+			})
+		}
+	}
+	if m.repo == nil {
+		return rows, nil
+	}
+	return rows, m.repo.Record(ctx, rows)
+}
@@ -0,0 +1,56 @@
+package core
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/dbehnke/allstar-nexus/internal/ami"
+)
+
+type linkQualityFakeSender struct {
+	outputs  map[string]string
+	commands []string
+}
+
+func (f *linkQualityFakeSender) SendCommand(ctx context.Context, command string) (ami.Message, error) {
+	f.commands = append(f.commands, command)
+	raw := []string{"Response: Success"}
+	for _, l := range strings.Split(f.outputs[command], "\n") {
+		raw = append(raw, "Output: "+l)
+	}
+	return ami.Message{Raw: raw}, nil
+}
+
+func TestLinkQualityMonitorJoinsNetstats(t *testing.T) {
+	lstats, err := os.ReadFile("../ami/testdata/rpt_lstats.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	netstats, err := os.ReadFile("../ami/testdata/iax2_netstats.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sender := &linkQualityFakeSender{outputs: map[string]string{
+		"rpt lstats 1999":    string(lstats),
+		"iax2 show netstats": string(netstats),
+	}}
+	m := NewLinkQualityMonitor(sender, nil, []int{1999}, 0)
+	rows, err := m.Poll(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 3123456 has no address and 2003's channel has no jitter buffer data yet
+	if len(rows) != 2 {
+		t.Fatalf("rows = %+v", rows)
+	}
+	r := rows[1]
+	if r.LocalNode != 1999 || r.Node != 2002 || r.Peer != "198.51.100.7" || r.Reconnects != 3 || r.RTTMs != 148 ||
+		r.RxJitterMs != 95 || r.RxLostPct != 3 || r.RxDropped != 11 || r.RxKpkts != 12 || r.TxJitterMs != 60 || r.TxLost != 10 {
+		t.Fatalf("row = %+v", r)
+	}
+	if len(sender.commands) != 2 {
+		t.Fatalf("commands = %v", sender.commands)
+	}
+}
@@ -1,6 +1,10 @@
 package core
 
-import "time"
+import (
+	"time"
+
+	"github.com/dbehnke/allstar-nexus/backend/models"
+)
 
 type LinkInfo struct {
 	Node           int        `json:"node"`
@@ -27,6 +31,71 @@ type LinkInfo struct {
 	NodeCallsign    string `json:"node_callsign,omitempty"`    // Callsign from astdb
 	NodeDescription string `json:"node_description,omitempty"` // Description from astdb
 	NodeLocation    string `json:"node_location,omitempty"`    // Location from astdb
+
+	// Admin annotations (see SetNodeAnnotation); notes are only shown to admins
+	Tags  []string `json:"tags,omitempty"`
+	Notes string   `json:"notes,omitempty"`
+
+	// Reachability from the node prober (see SetLinkHealth); nil when not probed
+	Health *LinkHealth `json:"health,omitempty"`
+}
+
+// ToLinkStat converts a link to its persisted form. Live-only fields (keyed state,
+// elapsed/last heard strings) are not stored.
+func (li LinkInfo) ToLinkStat() models.LinkStat {
+	cs := li.ConnectedSince
+	return models.LinkStat{
+		Node:            li.Node,
+		TotalTxSeconds:  li.TotalTxSeconds,
+		LastTxStart:     li.LastTxStart,
+		LastTxEnd:       li.LastTxEnd,
+		ConnectedSince:  &cs,
+		LocalNode:       li.LocalNode,
+		IP:              li.IP,
+		Direction:       li.Direction,
+		Mode:            li.Mode,
+		LinkType:        li.LinkType,
+		LastHeardAt:     li.LastHeardAt,
+		NodeCallsign:    li.NodeCallsign,
+		NodeDescription: li.NodeDescription,
+		NodeLocation:    li.NodeLocation,
+	}
+}
+
+// LinkInfoFromStat rebuilds a link from its persisted form for startup seeding.
+// defaultLocalNode is used for rows written before local_node was stored.
+func LinkInfoFromStat(s models.LinkStat, defaultLocalNode int) LinkInfo {
+	cs := time.Now()
+	if s.ConnectedSince != nil {
+		cs = *s.ConnectedSince
+	}
+	local := s.LocalNode
+	if local == 0 {
+		local = defaultLocalNode
+	}
+	return LinkInfo{
+		Node:            s.Node,
+		LocalNode:       local,
+		ConnectedSince:  cs,
+		LastTxStart:     s.LastTxStart,
+		LastTxEnd:       s.LastTxEnd,
+		TotalTxSeconds:  s.TotalTxSeconds,
+		IP:              s.IP,
+		Direction:       s.Direction,
+		Mode:            s.Mode,
+		LinkType:        s.LinkType,
+		LastHeardAt:     s.LastHeardAt,
+		NodeCallsign:    s.NodeCallsign,
+		NodeDescription: s.NodeDescription,
+		NodeLocation:    s.NodeLocation,
+	}
+}
+
+// linkDetailsChanged reports whether persisted connection details differ.
+func linkDetailsChanged(a, b *LinkInfo) bool {
+	return a.LocalNode != b.LocalNode || a.IP != b.IP || a.Direction != b.Direction ||
+		a.Mode != b.Mode || a.LinkType != b.LinkType || a.NodeCallsign != b.NodeCallsign ||
+		a.NodeDescription != b.NodeDescription || a.NodeLocation != b.NodeLocation
 }
 
 func (li *LinkInfo) UpdateTx(active bool, now time.Time) {
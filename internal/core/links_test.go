@@ -0,0 +1,56 @@
+package core
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dbehnke/allstar-nexus/internal/ami"
+)
+
+func TestLinkStatRoundTrip(t *testing.T) {
+	since := time.Now().Add(-time.Hour).Truncate(time.Second)
+	li := LinkInfo{
+		Node: 2001, LocalNode: 1000, ConnectedSince: since, TotalTxSeconds: 42,
+		IP: "192.0.2.1", Direction: "OUT", Mode: "T", LinkType: "ESTABLISHED",
+		NodeCallsign: "W1AW", NodeDescription: "Newington", NodeLocation: "CT",
+		CurrentTx: true, Elapsed: "01:00:00",
+	}
+	got := LinkInfoFromStat(li.ToLinkStat(), 999)
+	if got.LocalNode != 1000 || got.Direction != "OUT" || got.Mode != "T" || got.IP != "192.0.2.1" ||
+		got.NodeCallsign != "W1AW" || got.LinkType != "ESTABLISHED" || !got.ConnectedSince.Equal(since) || got.TotalTxSeconds != 42 {
+		t.Fatalf("round trip lost details: %+v", got)
+	}
+	if got.CurrentTx || got.Elapsed != "" {
+		t.Fatalf("live-only fields must not be restored: %+v", got)
+	}
+
+	// Rows persisted before local_node existed fall back to the primary node
+	stat := li.ToLinkStat()
+	stat.LocalNode = 0
+	if got := LinkInfoFromStat(stat, 999); got.LocalNode != 999 {
+		t.Fatalf("LocalNode = %d, want default 999", got.LocalNode)
+	}
+}
+
+func TestPollPersistsChangedLinkDetails(t *testing.T) {
+	sm := NewStateManager()
+	sm.SetNodeID(1000)
+	var persisted [][]LinkInfo
+	sm.SetPersistHook(func(ls []LinkInfo) { persisted = append(persisted, ls) })
+
+	poll := func(mode string) {
+		sm.ApplyCombinedStatus(&ami.CombinedNodeStatus{Node: 1000, Connections: []ami.ConnectionWithHistory{{
+			Connection: ami.Connection{Node: 2001, Direction: "OUT", IP: "192.0.2.1"},
+			Mode:       mode,
+		}}})
+	}
+	poll("T") // new link
+	poll("T") // unchanged
+	poll("R") // mode changed
+	if len(persisted) != 2 {
+		t.Fatalf("persist calls = %d, want 2 (new link, mode change)", len(persisted))
+	}
+	if last := persisted[1][0]; last.Mode != "R" || last.Direction != "OUT" {
+		t.Fatalf("persisted link = %+v", last)
+	}
+}
@@ -0,0 +1,159 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/dbehnke/allstar-nexus/backend/models"
+	"github.com/dbehnke/allstar-nexus/backend/repository"
+)
+
+// AuditActionUserNodeAction is the audit log action for node actions run by users on
+// their own claimed nodes.
+const AuditActionUserNodeAction = "user_node_action"
+
+// Node actions users may be allowed to run on their claimed node.
+const (
+	NodeActionDisconnect = "disconnect" // drop the node's link to the hub
+	NodeActionConnect    = "connect"    // link the node to the hub
+)
+
+var (
+	// ErrNodeActionNotAllowed is returned for actions that are unknown or not enabled.
+	ErrNodeActionNotAllowed = errors.New("node action not allowed")
+	// ErrNodeActionState is returned when the node is not in a state the action applies
+	// to, e.g. disconnecting a node that is not linked.
+	ErrNodeActionState = errors.New("node action does not apply")
+)
+
+// NodeActionRelay runs a small whitelist of AMI actions that affect one remote node's
+// link to the hub, on behalf of users who have claimed that node. Ownership is checked
+// by the caller; the relay only enforces the whitelist and the link state, and audits
+// every attempt.
+type NodeActionRelay struct {
+	sender   AMICommandSender
+	sm       *StateManager
+	audit    *repository.AuditRepo
+	nodes    []int             // local hub nodes; the first is used for connects
+	commands map[string]string // action -> fmt template receiving the local and remote node numbers
+}
+
+// NewNodeActionRelay creates a relay. commands maps each enabled action to its AMI
+// command template; actions with an empty template are dropped.
+func NewNodeActionRelay(sender AMICommandSender, sm *StateManager, audit *repository.AuditRepo, nodes []int, commands map[string]string) *NodeActionRelay {
+	enabled := map[string]string{}
+	for action, tmpl := range commands {
+		if tmpl != "" && (action == NodeActionDisconnect || action == NodeActionConnect) {
+			enabled[action] = tmpl
+		}
+	}
+	return &NodeActionRelay{sender: sender, sm: sm, audit: audit, nodes: nodes, commands: enabled}
+}
+
+// Actions returns the enabled actions in a stable order.
+func (r *NodeActionRelay) Actions() []string {
+	out := make([]string, 0, len(r.commands))
+	for action := range r.commands {
+		out = append(out, action)
+	}
+	slices.Sort(out)
+	return out
+}
+
+// linkedTo returns the local node that node is linked to, or 0.
+func (r *NodeActionRelay) linkedTo(node int) int {
+	snap := r.sm.Snapshot()
+	for _, li := range snap.LinksDetailed {
+		if li.Node != node {
+			continue
+		}
+		if li.LocalNode != 0 {
+			return li.LocalNode
+		}
+		return snap.NodeID
+	}
+	if slices.Contains(snap.Links, node) {
+		return snap.NodeID
+	}
+	return 0
+}
+
+// Run performs action on node for actor and returns the local node it was run against.
+// remoteAddr is recorded in the audit log; refused attempts are audited too.
+func (r *NodeActionRelay) Run(ctx context.Context, actor, remoteAddr string, node int, action string) (int, error) {
+	local, command, err := r.plan(node, action)
+	if err != nil {
+		r.Deny(actor, remoteAddr, node, action, err)
+		return 0, err
+	}
+	cmdCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	start := time.Now()
+	_, err = r.sender.SendCommand(cmdCtx, command)
+	entry := &models.AuditEntry{
+		Actor: actor, RemoteAddr: remoteAddr, Action: AuditActionUserNodeAction,
+		Detail: command, Outcome: models.AuditOutcomeOK, DurationMS: time.Since(start).Milliseconds(),
+	}
+	if err != nil {
+		entry.Outcome, entry.Error = models.AuditOutcomeError, truncateString(err.Error(), 512)
+	}
+	r.record(entry)
+	if err != nil {
+		return local, fmt.Errorf("%s %d: %w", action, node, err)
+	}
+	return local, nil
+}
+
+// plan checks action against the whitelist and the current links and returns the local
+// node and AMI command to run.
+func (r *NodeActionRelay) plan(node int, action string) (int, string, error) {
+	tmpl, ok := r.commands[action]
+	if !ok {
+		return 0, "", fmt.Errorf("%w: %q", ErrNodeActionNotAllowed, action)
+	}
+	if slices.Contains(r.nodes, node) {
+		return 0, "", fmt.Errorf("%w: %d is a hub node", ErrNodeActionNotAllowed, node)
+	}
+	local := r.linkedTo(node)
+	switch action {
+	case NodeActionDisconnect:
+		if local == 0 {
+			return 0, "", fmt.Errorf("%w: node %d is not linked", ErrNodeActionState, node)
+		}
+	case NodeActionConnect:
+		if local != 0 {
+			return 0, "", fmt.Errorf("%w: node %d is already linked to %d", ErrNodeActionState, node, local)
+		}
+		if len(r.nodes) == 0 {
+			return 0, "", fmt.Errorf("%w: no hub node configured", ErrNodeActionState)
+		}
+		local = r.nodes[0]
+	}
+	return local, fmt.Sprintf(tmpl, local, node), nil
+}
+
+// Deny audits a refused attempt, e.g. by a user without an approved claim to node.
+func (r *NodeActionRelay) Deny(actor, remoteAddr string, node int, action string, reason error) {
+	r.record(&models.AuditEntry{
+		Actor: actor, RemoteAddr: remoteAddr, Action: AuditActionUserNodeAction,
+		Detail: truncateString(action+" "+strconv.Itoa(node), 512), Outcome: models.AuditOutcomeDenied,
+		Error: truncateString(reason.Error(), 512),
+	})
+}
+
+func (r *NodeActionRelay) record(entry *models.AuditEntry) {
+	log.Printf("[AUDIT] %s by %s: %q -> %s", entry.Action, entry.Actor, entry.Detail, entry.Outcome)
+	if r.audit == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := r.audit.Record(ctx, entry); err != nil {
+		log.Printf("[AUDIT] failed to record %s: %v", entry.Action, err)
+	}
+}
@@ -0,0 +1,249 @@
+package core
+
+import (
+	"context"
+	"log"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/dbehnke/allstar-nexus/backend/models"
+	"github.com/dbehnke/allstar-nexus/backend/repository"
+)
+
+// Link health states.
+const (
+	LinkHealthGood     = "good"
+	LinkHealthDegraded = "degraded"
+	LinkHealthDown     = "down" // the latest round got no reply at all
+)
+
+// LinkHealth summarises recent probe rounds against a linked node.
+type LinkHealth struct {
+	Status    string    `json:"status"`
+	RTTMs     float64   `json:"rtt_ms,omitempty"` // average over answered probes in the window
+	LossPct   float64   `json:"loss_pct"`
+	Samples   int       `json:"samples"` // probes in the window
+	CheckedAt time.Time `json:"checked_at"`
+}
+
+// NodeProberOptions control how often and how hard linked nodes are probed.
+type NodeProberOptions struct {
+	Method       string        // recorded with each round (iax2 or icmp)
+	Interval     time.Duration // between rounds
+	Count        int           // probes per node per round
+	Timeout      time.Duration // per probe
+	Window       int           // rounds the live health is computed over
+	DegradedLoss float64       // loss percentage at or above which a link is degraded
+	DegradedRTT  time.Duration // average RTT at or above which a link is degraded
+	Retention    time.Duration // NodeHealth rows older than this are pruned; 0 keeps everything
+}
+
+// probeRound is the outcome of one round against one node.
+type probeRound struct {
+	sent, received int
+	rttSum, rttMax time.Duration
+}
+
+// NodeProber periodically probes every linked remote node that has an IP address
+// (EchoLink and other addressless links are skipped), records each round and pushes a
+// rolling health summary onto the links so flaky paths show up on the dashboard.
+type NodeProber struct {
+	sm    *StateManager
+	probe func(ctx context.Context, host string) (time.Duration, error)
+	repo  *repository.NodeHealthRepo // optional
+	opts  NodeProberOptions
+
+	mu     sync.Mutex
+	rounds map[int][]probeRound // per node, oldest first, at most opts.Window
+
+	stopCh   chan struct{}
+	stopOnce sync.Once
+}
+
+// NewNodeProber creates a prober. Zero values select a 1 minute interval, 3 probes with
+// a 2 second timeout, a 10 round window, 20% loss and 500ms RTT as degraded.
+func NewNodeProber(sm *StateManager, probe func(ctx context.Context, host string) (time.Duration, error), repo *repository.NodeHealthRepo, opts NodeProberOptions) *NodeProber {
+	if opts.Interval <= 0 {
+		opts.Interval = time.Minute
+	}
+	if opts.Count <= 0 {
+		opts.Count = 3
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = 2 * time.Second
+	}
+	if opts.Window <= 0 {
+		opts.Window = 10
+	}
+	if opts.DegradedLoss <= 0 {
+		opts.DegradedLoss = 20
+	}
+	if opts.DegradedRTT <= 0 {
+		opts.DegradedRTT = 500 * time.Millisecond
+	}
+	return &NodeProber{
+		sm:     sm,
+		probe:  probe,
+		repo:   repo,
+		opts:   opts,
+		rounds: make(map[int][]probeRound),
+		stopCh: make(chan struct{}),
+	}
+}
+
+// Start probes every interval and prunes old rounds hourly until Stop is called.
+func (p *NodeProber) Start() {
+	go func() {
+		ticker := time.NewTicker(p.opts.Interval)
+		prune := time.NewTicker(time.Hour)
+		defer ticker.Stop()
+		defer prune.Stop()
+		p.prune()
+		for {
+			select {
+			case <-ticker.C:
+				ctx, cancel := context.WithTimeout(context.Background(), p.opts.Interval)
+				if err := p.Round(ctx); err != nil {
+					log.Printf("[PROBE] recording node health failed: %v", err)
+				}
+				cancel()
+			case <-prune.C:
+				p.prune()
+			case <-p.stopCh:
+				return
+			}
+		}
+	}()
+}
+
+// Stop terminates the background loop.
+func (p *NodeProber) Stop() {
+	p.stopOnce.Do(func() { close(p.stopCh) })
+}
+
+func (p *NodeProber) prune() {
+	if p.repo == nil || p.opts.Retention <= 0 {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if n, err := p.repo.Prune(ctx, time.Now().Add(-p.opts.Retention)); err != nil {
+		log.Printf("[PROBE] node health prune failed: %v", err)
+	} else if n > 0 {
+		log.Printf("[PROBE] pruned %d node health rows", n)
+	}
+}
+
+// Round probes every currently linked node once (Count probes each, nodes in
+// parallel), updates link health and records the results. Health of nodes that are no
+// longer linked is dropped. Only a failure to record is returned.
+func (p *NodeProber) Round(ctx context.Context) error {
+	targets := make(map[int]string)
+	for _, li := range p.sm.Snapshot().LinksDetailed {
+		if li.IP != "" {
+			targets[li.Node] = li.IP
+		}
+	}
+
+	var wg sync.WaitGroup
+	var resMu sync.Mutex
+	results := make(map[int]probeRound, len(targets))
+	sem := make(chan struct{}, 8)
+	for node, ip := range targets {
+		wg.Add(1)
+		go func(node int, ip string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			r := p.probeNode(ctx, ip)
+			resMu.Lock()
+			results[node] = r
+			resMu.Unlock()
+		}(node, ip)
+	}
+	wg.Wait()
+
+	now := time.Now()
+	rows := make([]models.NodeHealth, 0, len(results))
+	p.mu.Lock()
+	for node := range p.rounds {
+		if _, ok := targets[node]; !ok {
+			delete(p.rounds, node)
+			p.sm.SetLinkHealth(node, nil)
+		}
+	}
+	for node, r := range results {
+		window := append(p.rounds[node], r)
+		if len(window) > p.opts.Window {
+			window = window[len(window)-p.opts.Window:]
+		}
+		p.rounds[node] = window
+		h := p.health(window, now)
+		p.sm.SetLinkHealth(node, &h)
+
+		row := models.NodeHealth{Node: node, CheckedAt: now, IP: targets[node], Method: p.opts.Method, Sent: r.sent, Received: r.received}
+		if r.received > 0 {
+			row.AvgRTTMs = ms(r.rttSum / time.Duration(r.received))
+			row.MaxRTTMs = ms(r.rttMax)
+		}
+		rows = append(rows, row)
+	}
+	p.mu.Unlock()
+
+	if p.repo == nil {
+		return nil
+	}
+	return p.repo.Record(ctx, rows)
+}
+
+// probeNode sends Count probes one after another to ip.
+func (p *NodeProber) probeNode(ctx context.Context, ip string) probeRound {
+	var r probeRound
+	host := ip
+	if h, _, err := net.SplitHostPort(ip); err == nil {
+		host = h // XStat reports the peer's source port, not its IAX2 listener
+	}
+	for i := 0; i < p.opts.Count && ctx.Err() == nil; i++ {
+		pctx, cancel := context.WithTimeout(ctx, p.opts.Timeout)
+		rtt, err := p.probe(pctx, host)
+		cancel()
+		r.sent++
+		if err != nil {
+			continue
+		}
+		r.received++
+		r.rttSum += rtt
+		if rtt > r.rttMax {
+			r.rttMax = rtt
+		}
+	}
+	return r
+}
+
+// health summarises a window of rounds (p.mu must be held).
+func (p *NodeProber) health(window []probeRound, now time.Time) LinkHealth {
+	var sent, received int
+	var rttSum time.Duration
+	for _, r := range window {
+		sent += r.sent
+		received += r.received
+		rttSum += r.rttSum
+	}
+	h := LinkHealth{Status: LinkHealthGood, Samples: sent, CheckedAt: now}
+	if sent > 0 {
+		h.LossPct = 100 * float64(sent-received) / float64(sent)
+	}
+	if received > 0 {
+		h.RTTMs = ms(rttSum / time.Duration(received))
+	}
+	switch {
+	case window[len(window)-1].received == 0:
+		h.Status = LinkHealthDown
+	case h.LossPct >= p.opts.DegradedLoss || time.Duration(h.RTTMs*float64(time.Millisecond)) >= p.opts.DegradedRTT:
+		h.Status = LinkHealthDegraded
+	}
+	return h
+}
+
+func ms(d time.Duration) float64 { return float64(d.Microseconds()) / 1000 }
@@ -0,0 +1,94 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func linkHealthOf(sm *StateManager, node int) *LinkHealth {
+	for _, li := range sm.Snapshot().LinksDetailed {
+		if li.Node == node {
+			return li.Health
+		}
+	}
+	return nil
+}
+
+func TestNodeProberHealthWindow(t *testing.T) {
+	sm := NewStateManager()
+	sm.state.LinksDetailed = []LinkInfo{
+		{Node: 2001, IP: "192.0.2.1:4569"},
+		{Node: 2002, IP: "192.0.2.2"},
+		{Node: 3001}, // EchoLink: no address
+	}
+
+	var mu sync.Mutex
+	replies := map[string][]bool{} // host -> answers for the next probes
+	var probed []string
+	probe := func(ctx context.Context, host string) (time.Duration, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		probed = append(probed, host)
+		q := replies[host]
+		ok := len(q) == 0 || q[0]
+		if len(q) > 0 {
+			replies[host] = q[1:]
+		}
+		if !ok {
+			return 0, errors.New("timeout")
+		}
+		if host == "192.0.2.2" {
+			return 800 * time.Millisecond, nil
+		}
+		return 40 * time.Millisecond, nil
+	}
+	p := NewNodeProber(sm, probe, nil, NodeProberOptions{Count: 2, Window: 2, DegradedLoss: 40, DegradedRTT: 500 * time.Millisecond})
+
+	replies["192.0.2.1"] = []bool{true, false}
+	if err := p.Round(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	for _, host := range probed {
+		if host != "192.0.2.1" && host != "192.0.2.2" {
+			t.Fatalf("probed %q; ports must be stripped and addressless links skipped", host)
+		}
+	}
+	if h := linkHealthOf(sm, 2001); h == nil || h.Status != LinkHealthDegraded || h.LossPct != 50 || h.RTTMs != 40 || h.Samples != 2 {
+		t.Fatalf("2001 health = %+v", h)
+	}
+	if h := linkHealthOf(sm, 2002); h == nil || h.Status != LinkHealthDegraded || h.LossPct != 0 {
+		t.Fatalf("slow 2002 health = %+v", h)
+	}
+	if linkHealthOf(sm, 3001) != nil {
+		t.Fatal("echolink link got health")
+	}
+
+	// Clean round: loss over the two-round window drops to 25%
+	if err := p.Round(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if h := linkHealthOf(sm, 2001); h.Status != LinkHealthGood || h.LossPct != 25 {
+		t.Fatalf("2001 health = %+v", h)
+	}
+	// Nothing answers: down, whatever the window says
+	replies["192.0.2.1"] = []bool{false, false}
+	_ = p.Round(context.Background())
+	if h := linkHealthOf(sm, 2001); h.Status != LinkHealthDown {
+		t.Fatalf("2001 health = %+v", h)
+	}
+
+	// Unlinked nodes are forgotten
+	sm.mu.Lock()
+	sm.state.LinksDetailed = sm.state.LinksDetailed[1:]
+	sm.mu.Unlock()
+	_ = p.Round(context.Background())
+	p.mu.Lock()
+	_, kept := p.rounds[2001]
+	p.mu.Unlock()
+	if kept {
+		t.Fatal("rounds kept for an unlinked node")
+	}
+}
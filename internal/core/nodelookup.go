@@ -2,8 +2,14 @@ package core
 
 import (
 	"context"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
+	"github.com/dbehnke/allstar-nexus/backend/models"
 	"github.com/dbehnke/allstar-nexus/backend/repository"
 	"github.com/dbehnke/allstar-nexus/internal/ami"
 )
@@ -16,11 +22,34 @@ type NodeInfo struct {
 	Location    string
 }
 
-// NodeLookupService provides fast node lookups from SQLite database
+// NodeFetcher looks up a single node outside astdb (e.g. the AllStarLink web API).
+// It returns nil, nil when the node is unknown.
+type NodeFetcher interface {
+	Fetch(ctx context.Context, node int) (*models.NodeInfo, error)
+}
+
+// NodeLookupService provides fast node lookups from SQLite database. Private nodes
+// registered with SetLocalNodes take precedence over astdb, and labels registered
+// with SetLabels replace the description from either.
 type NodeLookupService struct {
 	nodeInfoRepo *repository.NodeInfoRepository
+	mu           sync.RWMutex
+	local        map[int]NodeInfo
+	labels       map[int]string
+
+	// Optional web fallback for nodes missing from astdb; see EnableWebFallback
+	fetcher     NodeFetcher
+	cacheTTL    time.Duration
+	negativeTTL time.Duration
+	fetchQ      chan int
+	pending     map[int]bool
+	notFound    map[int]time.Time // node -> when the last fetch found nothing
+	now         func() time.Time
 }
 
+// minFallbackNode is the first public node number; lower numbers are private.
+const minFallbackNode = 2000
+
 // NewNodeLookupService creates a new node lookup service
 // The astdbPath parameter is kept for backward compatibility but not used
 func NewNodeLookupService(astdbPath string) *NodeLookupService {
@@ -32,8 +61,170 @@ func (nls *NodeLookupService) SetNodeInfoRepository(repo *repository.NodeInfoRep
 	nls.nodeInfoRepo = repo
 }
 
-// LookupNode looks up a node by ID from the SQLite database
+// SetLocalNodes replaces the private node registry.
+func (nls *NodeLookupService) SetLocalNodes(nodes []NodeInfo) {
+	local := make(map[int]NodeInfo, len(nodes))
+	for _, n := range nodes {
+		local[n.Node] = n
+	}
+	nls.mu.Lock()
+	nls.local = local
+	nls.mu.Unlock()
+}
+
+// LocalNode returns the private registry entry for nodeID, if any.
+func (nls *NodeLookupService) LocalNode(nodeID int) (NodeInfo, bool) {
+	nls.mu.RLock()
+	defer nls.mu.RUnlock()
+	n, ok := nls.local[nodeID]
+	return n, ok
+}
+
+// SetLabels replaces the display-name overrides (node -> label).
+func (nls *NodeLookupService) SetLabels(labels map[int]string) {
+	nls.mu.Lock()
+	nls.labels = labels
+	nls.mu.Unlock()
+}
+
+// Label returns the display-name override for nodeID, if any.
+func (nls *NodeLookupService) Label(nodeID int) (string, bool) {
+	nls.mu.RLock()
+	defer nls.mu.RUnlock()
+	l, ok := nls.labels[nodeID]
+	return l, ok
+}
+
+// SearchLocal returns private nodes whose number, callsign, description or location
+// contains query (case-insensitive), ordered by node number.
+func (nls *NodeLookupService) SearchLocal(query string) []NodeInfo {
+	q := strings.ToLower(query)
+	nls.mu.RLock()
+	var out []NodeInfo
+	for _, n := range nls.local {
+		if strings.Contains(strconv.Itoa(n.Node), query) ||
+			strings.Contains(strings.ToLower(n.Callsign), q) ||
+			strings.Contains(strings.ToLower(n.Description), q) ||
+			strings.Contains(strings.ToLower(n.Location), q) {
+			out = append(out, n)
+		}
+	}
+	nls.mu.RUnlock()
+	sort.Slice(out, func(i, j int) bool { return out[i].Node < out[j].Node })
+	return out
+}
+
+// EnableWebFallback queries f in the background for nodes missing from astdb and caches
+// the results in node_info. Cached rows are refreshed after cacheTTL; nodes f does not
+// know are not asked for again for negativeTTL. At most perMinute requests are made.
+func (nls *NodeLookupService) EnableWebFallback(f NodeFetcher, cacheTTL, negativeTTL time.Duration, perMinute int) {
+	if cacheTTL <= 0 {
+		cacheTTL = 6 * time.Hour
+	}
+	if negativeTTL <= 0 {
+		negativeTTL = time.Hour
+	}
+	if perMinute <= 0 {
+		perMinute = 10
+	}
+	nls.mu.Lock()
+	nls.fetcher = f
+	nls.cacheTTL = cacheTTL
+	nls.negativeTTL = negativeTTL
+	nls.fetchQ = make(chan int, 64)
+	nls.pending = make(map[int]bool)
+	nls.notFound = make(map[int]time.Time)
+	if nls.now == nil {
+		nls.now = time.Now
+	}
+	q := nls.fetchQ
+	nls.mu.Unlock()
+	go nls.fetchLoop(q, time.Minute/time.Duration(perMinute))
+}
+
+// queueFetch schedules a web lookup for nodeID unless one is pending or it recently
+// came back empty. Lookups never wait for the network.
+func (nls *NodeLookupService) queueFetch(nodeID int) {
+	if nodeID < minFallbackNode {
+		return
+	}
+	nls.mu.Lock()
+	defer nls.mu.Unlock()
+	if nls.fetcher == nil || nls.pending[nodeID] {
+		return
+	}
+	if at, ok := nls.notFound[nodeID]; ok && nls.now().Sub(at) < nls.negativeTTL {
+		return
+	}
+	select {
+	case nls.fetchQ <- nodeID:
+		nls.pending[nodeID] = true
+	default: // queue full; a later lookup retries
+	}
+}
+
+func (nls *NodeLookupService) fetchLoop(q <-chan int, spacing time.Duration) {
+	var last time.Time
+	for nodeID := range q {
+		if wait := spacing - time.Since(last); wait > 0 {
+			time.Sleep(wait)
+		}
+		last = time.Now()
+		nls.fetch(nodeID)
+	}
+}
+
+// fetch performs the web lookup for nodeID and caches the result.
+func (nls *NodeLookupService) fetch(nodeID int) {
+	nls.mu.RLock()
+	f := nls.fetcher
+	nls.mu.RUnlock()
+	if f == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+	info, err := f.Fetch(ctx, nodeID)
+	if err != nil {
+		log.Printf("[NODE LOOKUP] web lookup for %d failed: %v", nodeID, err)
+	}
+	if info != nil && nls.nodeInfoRepo != nil {
+		now := nls.now()
+		info.NodeID, info.LastSeen, info.FetchedAt = nodeID, now, &now
+		if err = nls.nodeInfoRepo.Upsert(ctx, info); err != nil {
+			log.Printf("[NODE LOOKUP] cache %d: %v", nodeID, err)
+		}
+	}
+
+	nls.mu.Lock()
+	delete(nls.pending, nodeID)
+	if info == nil || err != nil {
+		nls.notFound[nodeID] = nls.now()
+	} else {
+		delete(nls.notFound, nodeID)
+	}
+	nls.mu.Unlock()
+}
+
+// LookupNode looks up a node by ID in the private registry, then the SQLite database.
+// A label override replaces the description, and names nodes found in neither.
+// With the web fallback enabled, misses and expired web results are fetched in the
+// background and show up on a later lookup.
 func (nls *NodeLookupService) LookupNode(nodeID int) *NodeInfo {
+	info := nls.lookupNode(nodeID)
+	if label, ok := nls.Label(nodeID); ok {
+		if info == nil {
+			info = &NodeInfo{Node: nodeID}
+		}
+		info.Description = label
+	}
+	return info
+}
+
+func (nls *NodeLookupService) lookupNode(nodeID int) *NodeInfo {
+	if n, ok := nls.LocalNode(nodeID); ok {
+		return &n
+	}
 	if nls.nodeInfoRepo == nil {
 		return nil
 	}
@@ -42,9 +233,21 @@ func (nls *NodeLookupService) LookupNode(nodeID int) *NodeInfo {
 	defer cancel()
 
 	dbNode, err := nls.nodeInfoRepo.GetByNodeID(ctx, nodeID)
-	if err != nil || dbNode == nil {
+	if err != nil {
+		return nil
+	}
+	if dbNode == nil {
+		nls.queueFetch(nodeID)
 		return nil
 	}
+	if dbNode.FetchedAt != nil {
+		nls.mu.RLock()
+		expired := nls.fetcher != nil && nls.now().Sub(*dbNode.FetchedAt) > nls.cacheTTL
+		nls.mu.RUnlock()
+		if expired {
+			nls.queueFetch(nodeID) // serve the stale row meanwhile
+		}
+	}
 
 	return &NodeInfo{
 		Node:        dbNode.NodeID,
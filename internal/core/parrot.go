@@ -0,0 +1,121 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/dbehnke/allstar-nexus/internal/ami"
+)
+
+// ParrotModeStatus describes the parrot (audio test / echo) state of a source node.
+type ParrotModeStatus struct {
+	Node      int        `json:"node"`
+	Enabled   bool       `json:"enabled"`
+	StartedAt *time.Time `json:"started_at,omitempty"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	StartedBy string     `json:"started_by,omitempty"`
+}
+
+// AMICommandSender is the subset of the AMI connector needed to issue CLI commands.
+type AMICommandSender interface {
+	SendCommand(ctx context.Context, command string) (ami.Message, error)
+}
+
+// ParrotController enables app_rpt parrot mode on a source node for a bounded duration
+// and automatically disables it afterwards. State is mirrored into the StateManager.
+type ParrotController struct {
+	mu         sync.Mutex
+	sender     AMICommandSender
+	sm         *StateManager
+	enableCmd  string // fmt template receiving the node number
+	disableCmd string // fmt template receiving the node number
+	maxDur     time.Duration
+	timers     map[int]*time.Timer
+}
+
+// NewParrotController builds a controller. Empty command templates default to the
+// app_rpt COP functions 21 (parrot enable) and 22 (parrot disable).
+func NewParrotController(sender AMICommandSender, sm *StateManager, enableCmd, disableCmd string, maxDur time.Duration) *ParrotController {
+	if enableCmd == "" {
+		enableCmd = "rpt cmd %d cop 21"
+	}
+	if disableCmd == "" {
+		disableCmd = "rpt cmd %d cop 22"
+	}
+	if maxDur <= 0 {
+		maxDur = 30 * time.Minute
+	}
+	return &ParrotController{
+		sender:     sender,
+		sm:         sm,
+		enableCmd:  enableCmd,
+		disableCmd: disableCmd,
+		maxDur:     maxDur,
+		timers:     make(map[int]*time.Timer),
+	}
+}
+
+// MaxDuration returns the longest duration a caller may request.
+func (pc *ParrotController) MaxDuration() time.Duration { return pc.maxDur }
+
+// Enable turns on parrot mode for node and schedules automatic disable after dur.
+// Calling Enable while already enabled extends the expiry.
+func (pc *ParrotController) Enable(ctx context.Context, node int, dur time.Duration, by string) (ParrotModeStatus, error) {
+	if node <= 0 {
+		return ParrotModeStatus{}, fmt.Errorf("invalid node %d", node)
+	}
+	if dur <= 0 || dur > pc.maxDur {
+		return ParrotModeStatus{}, fmt.Errorf("duration must be between 1s and %s", pc.maxDur)
+	}
+	if _, err := pc.sender.SendCommand(ctx, fmt.Sprintf(pc.enableCmd, node)); err != nil {
+		return ParrotModeStatus{}, fmt.Errorf("enable parrot: %w", err)
+	}
+	now := time.Now()
+	expires := now.Add(dur)
+	st := ParrotModeStatus{Node: node, Enabled: true, StartedAt: &now, ExpiresAt: &expires, StartedBy: by}
+
+	pc.mu.Lock()
+	if t, ok := pc.timers[node]; ok {
+		t.Stop()
+	}
+	pc.timers[node] = time.AfterFunc(dur, func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if _, err := pc.Disable(ctx, node); err != nil {
+			log.Printf("[PARROT] auto-disable failed for node %d: %v", node, err)
+		}
+	})
+	pc.mu.Unlock()
+
+	pc.sm.SetParrotMode(st)
+	log.Printf("[PARROT] enabled on node %d for %s (by %s)", node, dur, by)
+	return st, nil
+}
+
+// Disable turns off parrot mode for node and cancels any pending auto-disable.
+func (pc *ParrotController) Disable(ctx context.Context, node int) (ParrotModeStatus, error) {
+	pc.mu.Lock()
+	if t, ok := pc.timers[node]; ok {
+		t.Stop()
+		delete(pc.timers, node)
+	}
+	pc.mu.Unlock()
+
+	st := ParrotModeStatus{Node: node, Enabled: false}
+	_, err := pc.sender.SendCommand(ctx, fmt.Sprintf(pc.disableCmd, node))
+	// Clear local state even if the command failed so the UI doesn't show a stale test mode.
+	pc.sm.SetParrotMode(st)
+	if err != nil {
+		return st, fmt.Errorf("disable parrot: %w", err)
+	}
+	log.Printf("[PARROT] disabled on node %d", node)
+	return st, nil
+}
+
+// Status returns the nodes currently in parrot mode.
+func (pc *ParrotController) Status() []ParrotModeStatus {
+	return pc.sm.ParrotModes()
+}
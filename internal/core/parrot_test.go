@@ -0,0 +1,56 @@
+package core
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dbehnke/allstar-nexus/internal/ami"
+)
+
+type fakeCommandSender struct {
+	mu   sync.Mutex
+	cmds []string
+}
+
+func (f *fakeCommandSender) SendCommand(ctx context.Context, command string) (ami.Message, error) {
+	f.mu.Lock()
+	f.cmds = append(f.cmds, command)
+	f.mu.Unlock()
+	return ami.Message{}, nil
+}
+
+func (f *fakeCommandSender) commands() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.cmds...)
+}
+
+func TestParrotControllerAutoDisable(t *testing.T) {
+	sm := NewStateManager()
+	sender := &fakeCommandSender{}
+	pc := NewParrotController(sender, sm, "", "", time.Minute)
+
+	if _, err := pc.Enable(context.Background(), 1999, 2*time.Minute, "admin"); err == nil {
+		t.Fatalf("expected error for duration above max")
+	}
+	if _, err := pc.Enable(context.Background(), 1999, 50*time.Millisecond, "admin"); err != nil {
+		t.Fatalf("enable: %v", err)
+	}
+	if st := pc.Status(); len(st) != 1 || !st[0].Enabled || st[0].StartedBy != "admin" {
+		t.Fatalf("unexpected status after enable: %+v", st)
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for len(pc.Status()) != 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if st := pc.Status(); len(st) != 0 {
+		t.Fatalf("expected parrot mode auto-disabled, got %+v", st)
+	}
+	cmds := sender.commands()
+	if len(cmds) != 2 || cmds[0] != "rpt cmd 1999 cop 21" || cmds[1] != "rpt cmd 1999 cop 22" {
+		t.Fatalf("unexpected commands: %v", cmds)
+	}
+}
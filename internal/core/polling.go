@@ -10,14 +10,39 @@ import (
 	"github.com/dbehnke/allstar-nexus/internal/ami"
 )
 
+// pollSource is the subset of the AMI connector used by the poller.
+type pollSource interface {
+	IsConnected() bool
+	GetCombinedStatus(ctx context.Context, node int) (*ami.CombinedNodeStatus, error)
+}
+
+// DefaultPollWorkers bounds how many node polls may be in flight at once.
+const DefaultPollWorkers = 4
+
+// NodePollStatus reports the outcome of the most recent poll for a node.
+type NodePollStatus struct {
+	Node            int        `json:"node"`
+	IntervalSeconds int        `json:"interval_seconds"`
+	Status          string     `json:"status"` // pending, ok, error, skipped
+	InFlight        bool       `json:"in_flight"`
+	LastPollAt      *time.Time `json:"last_poll_at,omitempty"`
+	LastSuccessAt   *time.Time `json:"last_success_at,omitempty"`
+	LastError       string     `json:"last_error,omitempty"`
+	LastDurationMs  int64      `json:"last_duration_ms"`
+	Connections     int        `json:"connections"`
+}
+
 // PollingService periodically queries AMI for node status to ensure data sync
 // This provides a hybrid approach: event-driven updates for real-time changes,
 // plus periodic polling for verification and enrichment with data not in events
 type PollingService struct {
-	connector       *ami.Connector
+	connector       pollSource
 	stateManager    *StateManager
 	interval        time.Duration
 	nodes           []int
+	intervals       map[int]time.Duration // per-node overrides of interval
+	sem             chan struct{}         // bounds concurrent polls
+	status          map[int]*NodePollStatus
 	ctx             context.Context
 	cancel          context.CancelFunc
 	wg              sync.WaitGroup
@@ -25,6 +50,7 @@ type PollingService struct {
 	mu              sync.Mutex
 	firstPollDone   bool   // Track if first poll completed successfully
 	cleanupCallback func() // Optional callback to trigger database cleanup after first poll
+	topology        *TopologyService
 }
 
 // NewPollingService creates a new polling service
@@ -33,15 +59,74 @@ func NewPollingService(conn *ami.Connector, sm *StateManager, interval time.Dura
 		interval = 60 * time.Second // Default to 1 minute
 	}
 
+	status := make(map[int]*NodePollStatus, len(nodes))
+	for _, n := range nodes {
+		status[n] = &NodePollStatus{Node: n, Status: "pending"}
+	}
 	return &PollingService{
 		connector:     conn,
 		stateManager:  sm,
 		interval:      interval,
 		nodes:         nodes,
+		intervals:     make(map[int]time.Duration),
+		sem:           make(chan struct{}, DefaultPollWorkers),
+		status:        status,
 		firstPollDone: false,
 	}
 }
 
+// SetNodeInterval overrides the poll interval for one node (call before Start).
+// A busy hub can be polled more often than a quiet remote node.
+func (ps *PollingService) SetNodeInterval(nodeID int, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	ps.intervals[nodeID] = interval
+}
+
+// SetMaxConcurrent limits how many nodes are polled at the same time (call before Start).
+func (ps *PollingService) SetMaxConcurrent(n int) {
+	if n <= 0 {
+		n = DefaultPollWorkers
+	}
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	ps.sem = make(chan struct{}, n)
+}
+
+// intervalFor returns the effective poll interval for a node.
+func (ps *PollingService) intervalFor(nodeID int) time.Duration {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	if d, ok := ps.intervals[nodeID]; ok {
+		return d
+	}
+	return ps.interval
+}
+
+// Status returns the last poll outcome for every configured node, ordered as configured.
+func (ps *PollingService) Status() []NodePollStatus {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	out := make([]NodePollStatus, 0, len(ps.nodes))
+	for _, n := range ps.nodes {
+		st := *ps.status[n]
+		st.IntervalSeconds = int(ps.interval.Seconds())
+		if d, ok := ps.intervals[n]; ok {
+			st.IntervalSeconds = int(d.Seconds())
+		}
+		out = append(out, st)
+	}
+	return out
+}
+
+// SetTopology feeds every successful poll into the link-graph topology (call before Start).
+func (ps *PollingService) SetTopology(ts *TopologyService) {
+	ps.topology = ts
+}
+
 // SetCleanupCallback sets a callback to be called after the first successful poll
 // This is useful for cleaning up stale database entries that were seeded at startup
 func (ps *PollingService) SetCleanupCallback(callback func()) {
@@ -61,7 +146,7 @@ func (ps *PollingService) Start() error {
 	ps.ctx, ps.cancel = context.WithCancel(context.Background())
 	ps.mu.Unlock()
 
-	log.Printf("[POLLING] Starting periodic polling service (interval=%s, nodes=%v)", ps.interval, ps.nodes)
+	log.Printf("[POLLING] Starting periodic polling service (interval=%s, nodes=%v, workers=%d)", ps.interval, ps.nodes, cap(ps.sem))
 
 	// Start polling goroutine for each node
 	for _, nodeID := range ps.nodes {
@@ -92,7 +177,11 @@ func (ps *PollingService) Stop() {
 func (ps *PollingService) pollNode(nodeID int) {
 	defer ps.wg.Done()
 
-	ticker := time.NewTicker(ps.interval)
+	interval := ps.intervalFor(nodeID)
+	if interval != ps.interval {
+		log.Printf("[POLLING] Node %d uses custom poll interval %s", nodeID, interval)
+	}
+	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
 
 	// Perform initial poll immediately (but wait a bit for AMI to stabilize)
@@ -114,16 +203,74 @@ func (ps *PollingService) pollNode(nodeID int) {
 	}
 }
 
+// beginPoll marks a node as in flight; it returns false if a poll for the node is
+// already running so ticks and on-demand triggers don't pile up behind a slow node.
+func (ps *PollingService) beginPoll(nodeID int) (*NodePollStatus, chan struct{}, bool) {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	st, ok := ps.status[nodeID]
+	if !ok {
+		// On-demand poll for a node outside the configured list
+		st = &NodePollStatus{Node: nodeID, Status: "pending"}
+		ps.status[nodeID] = st
+	}
+	if st.InFlight {
+		return nil, nil, false
+	}
+	st.InFlight = true
+	return st, ps.sem, true
+}
+
+// finishPoll records the outcome of a poll.
+func (ps *PollingService) finishPoll(st *NodePollStatus, started time.Time, status string, err error, connections int) {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	st.InFlight = false
+	st.Status = status
+	st.LastPollAt = &started
+	st.LastDurationMs = time.Since(started).Milliseconds()
+	st.LastError = ""
+	if err != nil {
+		st.LastError = err.Error()
+	}
+	if status == "ok" {
+		st.LastSuccessAt = &started
+		st.Connections = connections
+	}
+}
+
 // performPoll executes a single poll cycle for a node
 func (ps *PollingService) performPoll(nodeID int) {
+	st, sem, ok := ps.beginPoll(nodeID)
+	if !ok {
+		return
+	}
+	ctxParent := ps.ctx
+	if ctxParent == nil {
+		ctxParent = context.Background()
+	}
+
+	// Wait for a worker slot
+	select {
+	case sem <- struct{}{}:
+		defer func() { <-sem }()
+	case <-ctxParent.Done():
+		ps.mu.Lock()
+		st.InFlight = false
+		ps.mu.Unlock()
+		return
+	}
+	started := time.Now()
+
 	// Check if AMI is connected before polling
 	if !ps.connector.IsConnected() {
 		log.Printf("[POLLING] Skipping poll for node %d (AMI not connected, waiting for reconnection...)", nodeID)
+		ps.finishPoll(st, started, "skipped", fmt.Errorf("AMI not connected"), 0)
 		return
 	}
 
 	// Create context with timeout for this poll
-	ctx, cancel := context.WithTimeout(ps.ctx, 10*time.Second)
+	ctx, cancel := context.WithTimeout(ctxParent, 10*time.Second)
 	defer cancel()
 
 	log.Printf("[POLLING] Polling node %d for status...", nodeID)
@@ -132,8 +279,10 @@ func (ps *PollingService) performPoll(nodeID int) {
 	combined, err := ps.connector.GetCombinedStatus(ctx, nodeID)
 	if err != nil {
 		log.Printf("[POLLING] Failed to get status for node %d: %v (will retry on next interval)", nodeID, err)
+		ps.finishPoll(st, started, "error", err, 0)
 		return
 	}
+	ps.finishPoll(st, started, "ok", nil, len(combined.Connections))
 
 	// Log summary
 	log.Printf("[POLLING] Node %d: %d connections, RX=%t, TX=%t",
@@ -141,6 +290,9 @@ func (ps *PollingService) performPoll(nodeID int) {
 
 	// Apply to state manager
 	ps.stateManager.ApplyCombinedStatus(combined)
+	if ps.topology != nil {
+		ps.topology.Update(combined)
+	}
 
 	// Update keying tracker if it exists
 	// This enriches the tracker with connection details (Direction, IP, Elapsed, Mode)
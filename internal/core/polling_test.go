@@ -0,0 +1,121 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/dbehnke/allstar-nexus/internal/ami"
+)
+
+type fakePollSource struct {
+	connected bool
+	delay     time.Duration
+	fail      map[int]bool
+	inFlight  atomic.Int32
+	maxSeen   atomic.Int32
+}
+
+func (f *fakePollSource) IsConnected() bool { return f.connected }
+
+func (f *fakePollSource) GetCombinedStatus(ctx context.Context, node int) (*ami.CombinedNodeStatus, error) {
+	n := f.inFlight.Add(1)
+	defer f.inFlight.Add(-1)
+	for {
+		m := f.maxSeen.Load()
+		if n <= m || f.maxSeen.CompareAndSwap(m, n) {
+			break
+		}
+	}
+	select {
+	case <-time.After(f.delay):
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	if f.fail[node] {
+		return nil, errors.New("xstat timeout")
+	}
+	return &ami.CombinedNodeStatus{Node: node, Timestamp: time.Now()}, nil
+}
+
+func newTestPoller(src *fakePollSource, nodes []int) *PollingService {
+	ps := NewPollingService(nil, NewStateManager(), time.Minute, nodes)
+	ps.connector = src
+	ps.ctx, ps.cancel = context.WithCancel(context.Background())
+	return ps
+}
+
+func TestPollingServiceBoundsConcurrency(t *testing.T) {
+	src := &fakePollSource{connected: true, delay: 30 * time.Millisecond}
+	nodes := []int{1001, 1002, 1003, 1004, 1005, 1006}
+	ps := newTestPoller(src, nodes)
+	defer ps.cancel()
+	ps.SetMaxConcurrent(2)
+
+	var wg sync.WaitGroup
+	for _, n := range nodes {
+		wg.Add(1)
+		go func(n int) { defer wg.Done(); ps.performPoll(n) }(n)
+	}
+	wg.Wait()
+
+	if got := src.maxSeen.Load(); got > 2 {
+		t.Fatalf("max concurrent polls = %d, want <= 2", got)
+	}
+	for _, st := range ps.Status() {
+		if st.Status != "ok" || st.LastSuccessAt == nil || st.InFlight {
+			t.Fatalf("node %d status = %+v, want ok", st.Node, st)
+		}
+	}
+}
+
+func TestPollingServiceStatus(t *testing.T) {
+	src := &fakePollSource{connected: true, fail: map[int]bool{2002: true}}
+	ps := newTestPoller(src, []int{2001, 2002, 2003})
+	defer ps.cancel()
+	ps.SetNodeInterval(2001, 15*time.Second)
+	ps.SetNodeInterval(2003, 0) // ignored
+
+	ps.performPoll(2001)
+	ps.performPoll(2002)
+
+	st := ps.Status()
+	if len(st) != 3 {
+		t.Fatalf("expected 3 nodes, got %d", len(st))
+	}
+	if st[0].Node != 2001 || st[0].Status != "ok" || st[0].IntervalSeconds != 15 {
+		t.Fatalf("node 2001 = %+v", st[0])
+	}
+	if st[1].Status != "error" || st[1].LastError == "" || st[1].LastSuccessAt != nil {
+		t.Fatalf("node 2002 = %+v", st[1])
+	}
+	if st[2].Status != "pending" || st[2].LastPollAt != nil || st[2].IntervalSeconds != 60 {
+		t.Fatalf("node 2003 = %+v", st[2])
+	}
+
+	src.connected = false
+	ps.performPoll(2001)
+	st = ps.Status()
+	if st[0].Status != "skipped" || st[0].LastSuccessAt == nil {
+		t.Fatalf("node 2001 after disconnect = %+v", st[0])
+	}
+}
+
+func TestPollingServiceSkipsOverlappingPoll(t *testing.T) {
+	src := &fakePollSource{connected: true, delay: 50 * time.Millisecond}
+	ps := newTestPoller(src, []int{3001})
+	defer ps.cancel()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() { defer wg.Done(); ps.performPoll(3001) }()
+	}
+	wg.Wait()
+	if got := src.maxSeen.Load(); got != 1 {
+		t.Fatalf("overlapping polls for one node = %d, want 1", got)
+	}
+}
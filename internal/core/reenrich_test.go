@@ -0,0 +1,41 @@
+package core
+
+import (
+	"testing"
+	"time"
+)
+
+func TestReenrichLinksRelabelsUnknownNodes(t *testing.T) {
+	sm := NewStateManager()
+	nls := NewNodeLookupService("")
+	sm.SetNodeLookup(nls)
+	sm.AddSourceNode(1999, 0)
+	sm.state.Links = []int{2001, 2002}
+	sm.state.LinksDetailed = []LinkInfo{{Node: 2001, LocalNode: 1999}, {Node: 2002, LocalNode: 1999, NodeCallsign: "K8ABC"}}
+	sm.keyingTrackers[1999].ProcessALinks([]int{2001, 2002}, map[int]bool{}, time.Now())
+
+	if n := sm.ReenrichLinks(); n != 0 {
+		t.Fatalf("relabeled %d links with nothing known", n)
+	}
+
+	nls.SetLocalNodes([]NodeInfo{{Node: 2001, Callsign: "W1AW", Description: "Hub", Location: "Newington, CT"}, {Node: 2002, Callsign: "K8ABC"}})
+	version := sm.Snapshot().StateVersion
+	if n := sm.ReenrichLinks(); n != 1 {
+		t.Fatalf("relabeled %d links, want 1", n)
+	}
+	snap := sm.Snapshot()
+	if li := snap.LinksDetailed[0]; li.NodeCallsign != "W1AW" || li.NodeLocation != "Newington, CT" || snap.StateVersion != version+1 {
+		t.Fatalf("link = %+v (version %d)", li, snap.StateVersion)
+	}
+	if adj, _ := sm.keyingTrackers[1999].GetAdjacentNode(2001); adj.Callsign != "W1AW" || adj.Description != "Hub" {
+		t.Fatalf("keying tracker not relabeled: %+v", adj)
+	}
+	select {
+	case u := <-sm.KeyingUpdates():
+		if u.SourceNodeID != 1999 {
+			t.Fatalf("keying update = %+v", u)
+		}
+	default:
+		t.Fatal("no keying update emitted")
+	}
+}
@@ -0,0 +1,101 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/dbehnke/allstar-nexus/backend/models"
+	"github.com/dbehnke/allstar-nexus/backend/repository"
+	"github.com/dbehnke/allstar-nexus/internal/rptconf"
+)
+
+// AuditActionRptConfig is the audit log action for rpt.conf telemetry edits.
+const AuditActionRptConfig = "rpt_config"
+
+// ErrRptReload is returned by RptConfigEditor.Set when rpt.conf was saved but app_rpt
+// could not be reloaded.
+var ErrRptReload = errors.New("rpt.conf saved but reload failed")
+
+// RptConfigEditor edits per-node telemetry settings in rpt.conf and reloads app_rpt
+// over AMI so they take effect. Only configured local nodes can be edited, and every
+// change is recorded in the audit log.
+type RptConfigEditor struct {
+	file      *rptconf.File
+	sender    AMICommandSender
+	audit     *repository.AuditRepo
+	nodes     []int
+	reloadCmd string
+}
+
+// NewRptConfigEditor creates an editor for file. Empty reloadCmd selects "rpt reload".
+func NewRptConfigEditor(file *rptconf.File, sender AMICommandSender, audit *repository.AuditRepo, nodes []int, reloadCmd string) *RptConfigEditor {
+	if reloadCmd == "" {
+		reloadCmd = "rpt reload"
+	}
+	return &RptConfigEditor{file: file, sender: sender, audit: audit, nodes: nodes, reloadCmd: reloadCmd}
+}
+
+// Nodes returns the nodes whose settings may be edited.
+func (e *RptConfigEditor) Nodes() []int { return e.nodes }
+
+// Get returns a node's editable settings and the courtesy tones it can choose from.
+func (e *RptConfigEditor) Get(node int) ([]rptconf.Setting, []string, error) {
+	if !slices.Contains(e.nodes, node) {
+		return nil, nil, fmt.Errorf("%w: [%d]", rptconf.ErrNodeNotFound, node)
+	}
+	settings, err := e.file.Node(node)
+	if err != nil {
+		return nil, nil, err
+	}
+	tones, err := e.file.Tones(node)
+	return settings, tones, err
+}
+
+// Set writes changes to the node's stanza and reloads app_rpt. A failed reload is
+// returned but the file stays changed; the settings apply at the next reload.
+func (e *RptConfigEditor) Set(ctx context.Context, actor string, node int, changes map[string]string) error {
+	if !slices.Contains(e.nodes, node) {
+		return fmt.Errorf("%w: [%d]", rptconf.ErrNodeNotFound, node)
+	}
+	previous, err := e.file.Set(node, changes)
+	if err != nil {
+		if !errors.Is(err, rptconf.ErrInvalidSetting) {
+			e.record(actor, fmt.Sprintf("%d: write %s", node, e.file.Path()), err)
+		}
+		return err
+	}
+	var parts []string
+	for _, k := range slices.Sorted(maps.Keys(previous)) {
+		parts = append(parts, fmt.Sprintf("%s %q->%q", k, previous[k], strings.TrimSpace(changes[k])))
+	}
+	cmdCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	_, reloadErr := e.sender.SendCommand(cmdCtx, e.reloadCmd)
+	if reloadErr != nil {
+		reloadErr = fmt.Errorf("%w: %s: %v", ErrRptReload, e.reloadCmd, reloadErr)
+	}
+	e.record(actor, fmt.Sprintf("%d: %s", node, strings.Join(parts, ", ")), reloadErr)
+	return reloadErr
+}
+
+func (e *RptConfigEditor) record(actor, detail string, err error) {
+	entry := &models.AuditEntry{Actor: actor, Action: AuditActionRptConfig, Detail: truncateString(detail, 512), Outcome: models.AuditOutcomeOK}
+	if err != nil {
+		entry.Outcome, entry.Error = models.AuditOutcomeError, truncateString(err.Error(), 512)
+	}
+	log.Printf("[AUDIT] %s by %s: %q -> %s", entry.Action, entry.Actor, entry.Detail, entry.Outcome)
+	if e.audit == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := e.audit.Record(ctx, entry); err != nil {
+		log.Printf("[AUDIT] failed to record %s: %v", entry.Action, err)
+	}
+}
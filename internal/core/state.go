@@ -9,28 +9,30 @@ import (
 	"time"
 
 	"github.com/dbehnke/allstar-nexus/internal/ami"
+	"github.com/dbehnke/allstar-nexus/internal/textnode"
 )
 
 // NodeState represents current (placeholder) node metrics.
 type NodeState struct {
-	NodeID        int        `json:"node_id"`
-	RxKeyed       bool       `json:"rx_keyed"`
-	TxKeyed       bool       `json:"tx_keyed"`
-	Links         []int      `json:"links"`
-	LinksDetailed []LinkInfo `json:"links_detailed,omitempty"`
-	UptimeSec     int        `json:"uptime_sec"`
-	LastReloadSec int        `json:"last_reload_sec"`
-	BootedAt      *time.Time `json:"booted_at,omitempty"`
-	BuildTime     string     `json:"build_time,omitempty"`
-	UpdatedAt     time.Time  `json:"updated_at"`
-	Version       string     `json:"version"`
-	Heartbeat     int64      `json:"heartbeat"`
-	StateVersion  int64      `json:"state_version"`
-	SessionStart  time.Time  `json:"session_start"`
-	Title         string     `json:"title,omitempty"`
-	Subtitle      string     `json:"subtitle,omitempty"`
-	NumLinks      int        `json:"num_links"`  // Total links (global)
-	NumALinks     int        `json:"num_alinks"` // Adjacent links (local)
+	NodeID        int                `json:"node_id"`
+	RxKeyed       bool               `json:"rx_keyed"`
+	TxKeyed       bool               `json:"tx_keyed"`
+	Links         []int              `json:"links"`
+	LinksDetailed []LinkInfo         `json:"links_detailed,omitempty"`
+	UptimeSec     int                `json:"uptime_sec"`
+	LastReloadSec int                `json:"last_reload_sec"`
+	BootedAt      *time.Time         `json:"booted_at,omitempty"`
+	BuildTime     string             `json:"build_time,omitempty"`
+	UpdatedAt     time.Time          `json:"updated_at"`
+	Version       string             `json:"version"`
+	Heartbeat     int64              `json:"heartbeat"`
+	StateVersion  int64              `json:"state_version"`
+	SessionStart  time.Time          `json:"session_start"`
+	Title         string             `json:"title,omitempty"`
+	Subtitle      string             `json:"subtitle,omitempty"`
+	NumLinks      int                `json:"num_links"`              // Total links (global)
+	NumALinks     int                `json:"num_alinks"`             // Adjacent links (local)
+	ParrotModes   []ParrotModeStatus `json:"parrot_modes,omitempty"` // Source nodes currently in parrot/test mode
 }
 
 // SourceNodeKeyingUpdate represents a keying state update for a source node's adjacent links
@@ -82,7 +84,7 @@ type StateManager struct {
 	linkRemOut            chan []int
 	linkTxOut             chan LinkTxEvent
 	persistFn             func(ls []LinkInfo)
-	lastTalkerState       map[int]string // Track last TX event kind per node to prevent duplicate talker events
+	edges                 *edgeArbiter // Suppresses duplicate TX edges seen by both the event and polling paths
 	nodeLookup            *NodeLookupService
 	keyingTrackers        map[int]*KeyingTracker      // Per-source-node keying trackers
 	keyingOut             chan SourceNodeKeyingUpdate // Channel for source node keying updates
@@ -93,7 +95,15 @@ type StateManager struct {
 	perSourceNumALinks    map[int]int                 // Per-source adjacent links (server-provided or derived)
 	lastALinksProcessedAt time.Time                   // Track when we last processed ALINKS to avoid duplicate LINKS processing
 	txLogRepo             TransmissionLogRepo         // Repository for logging transmissions
-	txLogChan             chan transmissionLogEntry   // Async channel for transmission logging
+	txLogMu               sync.Mutex                  // Guards txLogQueue
+	txLogQueue            []transmissionLogEntry      // Unbounded queue so bursts are never dropped
+	txLogWake             chan struct{}               // Wakes the transmission log worker
+	talkerHooks           []func(TalkerEvent)         // Observers notified of every talker event (called with sm.mu held)
+	parrotModes           map[int]ParrotModeStatus    // Per-source-node parrot (test) mode state
+	parrotOut             chan ParrotModeStatus       // Channel for parrot mode changes
+	annotations           map[int]nodeAnnotation      // Admin notes/tags copied onto links
+	linkHealth            map[int]LinkHealth          // Prober results copied onto links
+	dedup                 *talkerDedup                // Cross-source talker deduplication; nil keeps one report per source
 }
 
 func NewStateManager() *StateManager {
@@ -105,13 +115,17 @@ func NewStateManager() *StateManager {
 		linkDiffOut:        make(chan []LinkInfo, 8),
 		linkRemOut:         make(chan []int, 8),
 		linkTxOut:          make(chan LinkTxEvent, 16),
-		lastTalkerState:    make(map[int]string),
+		edges:              newEdgeArbiter(edgeHoldoff),
 		keyingTrackers:     make(map[int]*KeyingTracker),
 		keyingOut:          make(chan SourceNodeKeyingUpdate, 16),
 		keyingEventOut:     make(chan SourceNodeKeyingEvent, 16),
-		txLogChan:          make(chan transmissionLogEntry, 32),
+		txLogWake:          make(chan struct{}, 1),
 		perSourceNumLinks:  make(map[int]int),
 		perSourceNumALinks: make(map[int]int),
+		parrotModes:        make(map[int]ParrotModeStatus),
+		parrotOut:          make(chan ParrotModeStatus, 8),
+		annotations:        make(map[int]nodeAnnotation),
+		linkHealth:         make(map[int]LinkHealth),
 	}
 	// Start async transmission logger
 	go sm.transmissionLogWorker()
@@ -130,35 +144,66 @@ func (sm *StateManager) SetTransmissionLogRepo(repo TransmissionLogRepo) {
 	sm.txLogRepo = repo
 }
 
+// txLogMaxAttempts bounds retries of a failed transmission insert. Retrying is safe
+// because the repository deduplicates by ledger key.
+const txLogMaxAttempts = 3
+
 // transmissionLogWorker processes transmission log entries asynchronously
 func (sm *StateManager) transmissionLogWorker() {
-	for entry := range sm.txLogChan {
-		if sm.txLogRepo != nil {
-			if err := sm.txLogRepo.LogTransmission(
-				entry.SourceID,
-				entry.AdjacentLinkID,
-				entry.Callsign,
-				entry.TimestampStart,
-				entry.TimestampEnd,
-				entry.DurationSeconds,
-			); err != nil {
-				log.Printf("[TX LOG ERROR] failed to persist transmission: %v", err)
+	for range sm.txLogWake {
+		for {
+			sm.txLogMu.Lock()
+			batch := sm.txLogQueue
+			sm.txLogQueue = nil
+			sm.txLogMu.Unlock()
+			if len(batch) == 0 {
+				break
+			}
+			for _, entry := range batch {
+				sm.persistTransmission(entry)
 			}
 		}
 	}
 }
 
+// persistTransmission writes one entry, retrying transient database errors.
+func (sm *StateManager) persistTransmission(entry transmissionLogEntry) {
+	if sm.txLogRepo == nil {
+		return
+	}
+	var err error
+	for attempt := 1; attempt <= txLogMaxAttempts; attempt++ {
+		if err = sm.txLogRepo.LogTransmission(
+			entry.SourceID,
+			entry.AdjacentLinkID,
+			entry.Callsign,
+			entry.TimestampStart,
+			entry.TimestampEnd,
+			entry.DurationSeconds,
+		); err == nil {
+			return
+		}
+		time.Sleep(time.Duration(attempt) * 500 * time.Millisecond)
+	}
+	log.Printf("[TX LOG ERROR] failed to persist transmission source=%d node=%d start=%s after %d attempts: %v",
+		entry.SourceID, entry.AdjacentLinkID, entry.TimestampStart.Format(time.RFC3339), txLogMaxAttempts, err)
+}
+
 // queueTransmissionLog queues a transmission log entry for async persistence
 // This method safely retrieves the callsign by querying the keying tracker
 func (sm *StateManager) queueTransmissionLog(sourceID, adjacentID int, startTime, endTime time.Time, durationSec int) {
 	// Retrieve callsign from keying tracker (requires lock)
 	sm.mu.RLock()
 	tracker, exists := sm.keyingTrackers[sourceID]
+	dedup := sm.dedup
 	sm.mu.RUnlock()
 
 	if !exists {
 		return
 	}
+	if dedup != nil && dedup.duplicateTx(sourceID, adjacentID, startTime) {
+		return
+	}
 
 	// Get adjacent node info (GetAdjacentNode acquires its own lock)
 	adjacentNode, found := tracker.GetAdjacentNode(adjacentID)
@@ -170,17 +215,19 @@ func (sm *StateManager) queueTransmissionLog(sourceID, adjacentID int, startTime
 		}
 	}
 
-	select {
-	case sm.txLogChan <- transmissionLogEntry{
+	sm.txLogMu.Lock()
+	sm.txLogQueue = append(sm.txLogQueue, transmissionLogEntry{
 		SourceID:        sourceID,
 		AdjacentLinkID:  adjacentID,
 		Callsign:        callsign,
 		TimestampStart:  startTime,
 		TimestampEnd:    endTime,
 		DurationSeconds: durationSec,
-	}:
-	default:
-		log.Printf("[TX LOG WARN] transmission log channel full, dropping entry")
+	})
+	sm.txLogMu.Unlock()
+	select {
+	case sm.txLogWake <- struct{}{}:
+	default: // worker already signalled
 	}
 }
 
@@ -192,6 +239,14 @@ func (sm *StateManager) LinkRemovals() <-chan []int                   { return s
 func (sm *StateManager) LinkTxEvents() <-chan LinkTxEvent             { return sm.linkTxOut }
 func (sm *StateManager) KeyingUpdates() <-chan SourceNodeKeyingUpdate { return sm.keyingOut }
 func (sm *StateManager) KeyingEvents() <-chan SourceNodeKeyingEvent   { return sm.keyingEventOut }
+func (sm *StateManager) ParrotModeEvents() <-chan ParrotModeStatus    { return sm.parrotOut }
+
+// ForgetTalker removes talker history for callsign and the nodes it transmitted from,
+// along with the callsign's text node mapping.
+func (sm *StateManager) ForgetTalker(callsign string, nodes []int) int {
+	textnode.Default.Forget(callsign)
+	return sm.log.Forget(callsign, nodes)
+}
 
 // enrichTalkerSnapshot enriches talker events with current node lookup data
 func (sm *StateManager) enrichTalkerSnapshot(events []TalkerEvent) []TalkerEvent {
@@ -240,6 +295,24 @@ func (sm *StateManager) enrichTalkerSnapshot(events []TalkerEvent) []TalkerEvent
 	return enriched
 }
 
+// AddTalkerHook registers an observer invoked for every talker event added to the log.
+// Hooks run while sm.mu is held, so they must be fast and must not call back into StateManager.
+func (sm *StateManager) AddTalkerHook(fn func(TalkerEvent)) {
+	if fn == nil {
+		return
+	}
+	sm.mu.Lock()
+	sm.talkerHooks = append(sm.talkerHooks, fn)
+	sm.mu.Unlock()
+}
+
+// notifyTalkerHooksLocked fans a talker event out to registered hooks (sm.mu must be held).
+func (sm *StateManager) notifyTalkerHooksLocked(evt TalkerEvent) {
+	for _, fn := range sm.talkerHooks {
+		fn(evt)
+	}
+}
+
 // SetPersistHook installs a callback invoked with full LinksDetailed slice after each apply where TX edges occurred.
 func (sm *StateManager) SetPersistHook(fn func([]LinkInfo)) { sm.persistFn = fn }
 
@@ -528,6 +601,7 @@ func (sm *StateManager) apply(m ami.Message) {
 						ni.NodeDescription = "VOIP Client"
 					}
 				}
+				sm.annotateLinkLocked(&ni)
 				newDetails = append(newDetails, ni)
 				added = append(added, ni)
 			}
@@ -552,6 +626,9 @@ func (sm *StateManager) apply(m ami.Message) {
 			case sm.linkRemOut <- removed:
 			default:
 			}
+			for _, id := range removed {
+				sm.edges.forget(sm.state.NodeID, id)
+			}
 		}
 		// Apply keyed status if we parsed ALINKS keyed map.
 		if alinksKeyed != nil {
@@ -577,14 +654,11 @@ func (sm *StateManager) apply(m ami.Message) {
 					newKind = "TX_START"
 				}
 
-				// Check against last known talker state to prevent duplicate events
-				lastKind, seen := sm.lastTalkerState[nodeID]
+				// The arbiter drops edges the polling path already reported (and vice versa)
+				ok, first := sm.edges.accept(newDetails[i].LocalNode, nodeID, newKind, edgeSourceEvent, now)
 
-				if !seen || lastKind != newKind {
+				if ok {
 					// Minimal logging only: indicate node transition without dumping old state
-					// State changed or first time seeing this node
-					sm.lastTalkerState[nodeID] = newKind
-
 					kind := "STOP"
 					if newActive {
 						kind = "START"
@@ -595,8 +669,11 @@ func (sm *StateManager) apply(m ami.Message) {
 					case sm.linkTxOut <- evt:
 					default:
 					}
-					// Emit talker event with node info, passing the LinkInfo for accurate duration
-					sm.emitTalkerFromLink("TX_"+kind, &newDetails[i])
+					// Emit talker event with node info, passing the LinkInfo for accurate duration.
+					// An idle link seen for the first time is not a talker edge.
+					if !first || newActive {
+						sm.emitTalkerFromLink("TX_"+kind, &newDetails[i])
+					}
 					emitted = true
 				}
 			}
@@ -640,15 +717,8 @@ func (sm *StateManager) apply(m ami.Message) {
 
 	// Talker edge detection (TX start/stop)
 	// Only emit global (node==0) talker events if no per-link TX events were emitted in this apply cycle.
-	if !sm.lastTx && sm.state.TxKeyed {
-		if !perLinkEmitted {
-			sm.emitTalker("TX_START", 0)
-		}
-	}
-	if sm.lastTx && !sm.state.TxKeyed {
-		if !perLinkEmitted {
-			sm.emitTalker("TX_STOP", 0)
-		}
+	if sm.lastTx != sm.state.TxKeyed && !perLinkEmitted {
+		sm.emitGlobalTxEdgeLocked(sm.state.TxKeyed, edgeSourceEvent, now)
 	}
 	sm.lastTx = sm.state.TxKeyed
 	snap := sm.state
@@ -659,6 +729,18 @@ func (sm *StateManager) apply(m ami.Message) {
 	}
 }
 
+// emitGlobalTxEdgeLocked emits a node==0 talker edge for the local transmitter if the
+// arbiter accepts it (must be called with sm.mu held).
+func (sm *StateManager) emitGlobalTxEdgeLocked(keyed bool, src edgeSource, at time.Time) {
+	kind := "TX_STOP"
+	if keyed {
+		kind = "TX_START"
+	}
+	if ok, _ := sm.edges.accept(0, 0, kind, src, at); ok {
+		sm.emitTalker(kind, 0)
+	}
+}
+
 func (sm *StateManager) emitTalker(kind string, node int) {
 	now := time.Now()
 	evt := TalkerEvent{At: now, Kind: kind, Node: node}
@@ -688,16 +770,10 @@ func (sm *StateManager) emitTalker(kind string, node int) {
 		}
 	}
 
-	// Check for duplicate: skip if last state for this node matches current kind
-	// This prevents duplicate events from being stored in the ring buffer
-	if lastState, exists := sm.lastTalkerState[node]; exists && lastState == kind {
-		// log.Printf("DEBUG: Skipping duplicate talker event: node=%d kind=%s", node, kind)
-		return // Already in this state, skip duplicate
-	}
-	sm.lastTalkerState[node] = kind
-
+	// Duplicate suppression happens before this point, in the edge arbiter
 	// log.Printf("DEBUG: Adding talker event to buffer: node=%d kind=%s callsign=%s", node, kind, evt.Callsign)
 	sm.log.Add(evt)
+	sm.notifyTalkerHooksLocked(evt)
 	select {
 	case sm.talkerOut <- evt:
 	default:
@@ -712,10 +788,14 @@ func (sm *StateManager) emitTalkerFromLink(kind string, link *LinkInfo) {
 	}
 
 	now := time.Now()
+	if sm.dedup != nil && sm.dedup.duplicateEdge(link.LocalNode, link.Node, kind, now) {
+		return
+	}
 	evt := TalkerEvent{
 		At:          now,
 		Kind:        kind,
 		Node:        link.Node,
+		Source:      link.LocalNode,
 		Callsign:    link.NodeCallsign,
 		Description: link.NodeDescription,
 	}
@@ -733,16 +813,10 @@ func (sm *StateManager) emitTalkerFromLink(kind string, link *LinkInfo) {
 		}
 	}
 
-	// Check for duplicate: skip if last state for this node matches current kind
-	// This prevents duplicate events from being stored in the ring buffer
-	if lastState, exists := sm.lastTalkerState[link.Node]; exists && lastState == kind {
-		// log.Printf("DEBUG: Skipping duplicate talker event (from link): node=%d kind=%s", link.Node, kind)
-		return // Already in this state, skip duplicate
-	}
-	sm.lastTalkerState[link.Node] = kind
-
+	// Duplicate suppression happens before this point, in the edge arbiter
 	// log.Printf("DEBUG: Adding talker event to buffer (from link): node=%d kind=%s callsign=%s", link.Node, kind, evt.Callsign)
 	sm.log.Add(evt)
+	sm.notifyTalkerHooksLocked(evt)
 	select {
 	case sm.talkerOut <- evt:
 	default:
@@ -765,6 +839,9 @@ func (sm *StateManager) SeedLinkStats(list []LinkInfo) {
 	sm.mu.Lock()
 	defer sm.mu.Unlock()
 	sm.state.LinksDetailed = append([]LinkInfo(nil), list...)
+	for i := range sm.state.LinksDetailed {
+		sm.annotateLinkLocked(&sm.state.LinksDetailed[i])
+	}
 	ids := make([]int, 0, len(list))
 	for _, l := range list {
 		ids = append(ids, l.Node)
@@ -801,6 +878,43 @@ func (sm *StateManager) SetSubtitle(subtitle string) {
 	sm.mu.Unlock()
 }
 
+// SetParrotMode records the parrot (test) mode state for a source node and publishes the change.
+func (sm *StateManager) SetParrotMode(st ParrotModeStatus) {
+	sm.mu.Lock()
+	if st.Enabled {
+		sm.parrotModes[st.Node] = st
+	} else {
+		delete(sm.parrotModes, st.Node)
+	}
+	modes := make([]ParrotModeStatus, 0, len(sm.parrotModes))
+	for _, m := range sm.parrotModes {
+		modes = append(modes, m)
+	}
+	sm.state.ParrotModes = modes
+	sm.state.UpdatedAt = time.Now()
+	snap := sm.state
+	sm.mu.Unlock()
+	select {
+	case sm.parrotOut <- st:
+	default:
+	}
+	select {
+	case sm.out <- snap:
+	default:
+	}
+}
+
+// ParrotModes returns the source nodes currently in parrot mode.
+func (sm *StateManager) ParrotModes() []ParrotModeStatus {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+	out := make([]ParrotModeStatus, 0, len(sm.parrotModes))
+	for _, m := range sm.parrotModes {
+		out = append(out, m)
+	}
+	return out
+}
+
 // SetNodeID sets the primary node ID to be exposed on STATUS_UPDATE snapshots.
 func (sm *StateManager) SetNodeID(nodeID int) {
 	sm.mu.Lock()
@@ -808,6 +922,65 @@ func (sm *StateManager) SetNodeID(nodeID int) {
 	sm.mu.Unlock()
 }
 
+// ReenrichLinks looks every connected link up again, e.g. after the node database was
+// refreshed, so nodes that were unknown when they connected get their callsign,
+// description and location without reconnecting. Keying trackers are updated too and
+// clients receive a STATUS_UPDATE plus keying updates. It returns the number of links
+// whose labels changed.
+func (sm *StateManager) ReenrichLinks() int {
+	if sm.nodeLookup == nil {
+		return 0
+	}
+	sm.mu.RLock()
+	links := append([]LinkInfo(nil), sm.state.LinksDetailed...)
+	sm.mu.RUnlock()
+
+	// Lookups hit the database, so they run without sm.mu
+	fresh := make(map[int]LinkInfo)
+	for _, li := range links {
+		e := li
+		sm.nodeLookup.EnrichLinkInfo(&e)
+		if e.NodeCallsign != li.NodeCallsign || e.NodeDescription != li.NodeDescription || e.NodeLocation != li.NodeLocation {
+			fresh[li.Node] = e
+		}
+	}
+	if len(fresh) == 0 {
+		return 0
+	}
+
+	now := time.Now()
+	sm.mu.Lock()
+	changed := 0
+	for i := range sm.state.LinksDetailed {
+		li := &sm.state.LinksDetailed[i]
+		if e, ok := fresh[li.Node]; ok {
+			li.NodeCallsign, li.NodeDescription, li.NodeLocation = e.NodeCallsign, e.NodeDescription, e.NodeLocation
+			changed++
+		}
+	}
+	for source, tracker := range sm.keyingTrackers {
+		touched := false
+		for node, e := range fresh {
+			if _, ok := tracker.GetAdjacentNode(node); ok {
+				tracker.UpdateNodeInfo(node, e.NodeCallsign, e.NodeDescription)
+				touched = true
+			}
+		}
+		if touched {
+			sm.emitKeyingUpdateLocked(source, now)
+		}
+	}
+	sm.state.StateVersion++
+	sm.state.UpdatedAt = now
+	snap := sm.state
+	sm.mu.Unlock()
+	select {
+	case sm.out <- snap:
+	default:
+	}
+	return changed
+}
+
 // SeedKeyingTrackerFromLinks populates the keying tracker with existing link data
 // This is useful on startup when links are loaded from persistence before AMI events arrive
 func (sm *StateManager) SeedKeyingTrackerFromLinks(sourceNodeID int) {
@@ -1025,6 +1198,7 @@ func (sm *StateManager) ApplyCombinedStatus(combined *ami.CombinedNodeStatus) {
 	now := time.Now()
 	newDetails := make([]LinkInfo, 0, len(combined.Connections))
 	var added []LinkInfo
+	var detailsChanged bool // direction/mode/IP/enrichment differ from what was persisted
 	currentSet := map[linkKey]struct{}{}
 
 	// Process each connection from combined status
@@ -1073,7 +1247,10 @@ func (sm *StateManager) ApplyCombinedStatus(combined *ami.CombinedNodeStatus) {
 		if conn.KeyingInfo != nil {
 			isCurrentlyKeyed = conn.KeyingInfo.IsKeyed
 		}
-		li.UpdateTx(isCurrentlyKeyed, now)
+		// A poll contradicting a fresh ALINKS edge is stale; keep the event's view
+		if isCurrentlyKeyed == li.CurrentTx || !sm.edges.shielded(combined.Node, conn.Node, edgeSourcePoll, now) {
+			li.UpdateTx(isCurrentlyKeyed, now)
+		}
 
 		// Enrich with node lookup data (callsign, description, location)
 		if sm.nodeLookup != nil {
@@ -1095,11 +1272,15 @@ func (sm *StateManager) ApplyCombinedStatus(combined *ami.CombinedNodeStatus) {
 			}
 		}
 
+		sm.annotateLinkLocked(&li)
 		newDetails = append(newDetails, li)
 
 		// Track if this is a new connection
 		if _, wasPresent := previousSet[key]; !wasPresent {
 			added = append(added, li)
+			detailsChanged = true
+		} else if existingLi, ok := existing[key]; ok && linkDetailsChanged(existingLi, &li) {
+			detailsChanged = true
 		}
 	}
 
@@ -1125,9 +1306,9 @@ func (sm *StateManager) ApplyCombinedStatus(combined *ami.CombinedNodeStatus) {
 		case sm.linkRemOut <- removed:
 		default:
 		}
-		// Clean up talker state for removed nodes
+		// Clean up edge state for removed nodes
 		for _, nodeID := range removed {
-			delete(sm.lastTalkerState, nodeID)
+			sm.edges.forget(combined.Node, nodeID)
 		}
 	}
 
@@ -1143,14 +1324,11 @@ func (sm *StateManager) ApplyCombinedStatus(combined *ami.CombinedNodeStatus) {
 			newKind = "TX_START"
 		}
 
-		// Check against last known talker state (not just existing link state)
-		// This prevents duplicate events when multiple pollers see the same link
-		lastKind, seen := sm.lastTalkerState[nodeID]
-
-		if !seen || lastKind != newKind {
-			// State changed or first time seeing this node
-			sm.lastTalkerState[nodeID] = newKind
+		// The arbiter drops edges ALINKS already reported and stale contradictions
+		// of a fresh ALINKS edge (SawStat can lag the event by a poll round trip)
+		ok, first := sm.edges.accept(combined.Node, nodeID, newKind, edgeSourcePoll, now)
 
+		if ok {
 			kind := "STOP"
 			if newActive {
 				kind = "START"
@@ -1167,14 +1345,18 @@ func (sm *StateManager) ApplyCombinedStatus(combined *ami.CombinedNodeStatus) {
 			case sm.linkTxOut <- evt:
 			default:
 			}
-			// Emit a talker event associated with this node so UI can show per-node duration
-			sm.emitTalker("TX_"+kind, nodeID)
+			// Emit a talker event associated with this node so UI can show per-node duration.
+			// An idle link seen for the first time is not a talker edge.
+			if !first || newActive {
+				sm.emitTalkerFromLink("TX_"+kind, &newDetails[i])
+			}
 			emitted = true
 		}
 	}
 
-	// Call persist hook if TX edges occurred
-	if emitted && sm.persistFn != nil {
+	// Call persist hook if TX edges occurred or connection details changed, so a
+	// restart seeds links with the same direction/mode/IP the poller reported
+	if (emitted || detailsChanged) && sm.persistFn != nil {
 		sm.persistFn(newDetails)
 	}
 
@@ -1201,11 +1383,8 @@ func (sm *StateManager) ApplyCombinedStatus(combined *ami.CombinedNodeStatus) {
 	sm.state.Heartbeat = now.UnixMilli()
 
 	// Talker edge detection (TX start/stop)
-	if !sm.lastTx && sm.state.TxKeyed {
-		sm.emitTalker("TX_START", 0)
-	}
-	if sm.lastTx && !sm.state.TxKeyed {
-		sm.emitTalker("TX_STOP", 0)
+	if sm.lastTx != sm.state.TxKeyed {
+		sm.emitGlobalTxEdgeLocked(sm.state.TxKeyed, edgeSourcePoll, now)
 	}
 	sm.lastTx = sm.state.TxKeyed
 
@@ -1294,39 +1473,19 @@ func parseLinkIDs(payload string) []int {
 		if _, dup := seen[nodeID]; !dup {
 			out = append(out, nodeID)
 			seen[nodeID] = struct{}{}
-			// Store mapping for later lookup
-			registerTextNode(nodeID, cleaned)
 		}
 	}
 	return out
 }
 
-// textNodeToInt converts a text node identifier to a stable integer ID
-// Uses FNV-1a hash mapped to negative range to avoid collision with numeric nodes
+// textNodeToInt converts a text node identifier to a stable negative ID using the
+// registry shared with the AMI layer
 func textNodeToInt(s string) int {
-	s = strings.ToUpper(s) // Normalize to uppercase
-	hash := uint32(2166136261)
-	for i := 0; i < len(s); i++ {
-		hash ^= uint32(s[i])
-		hash *= 16777619
-	}
-	// Convert to negative number to distinguish from numeric AllStar nodes
-	// Use lower 30 bits to keep values reasonable
-	return -int(hash & 0x3FFFFFFF)
-}
-
-// Global map to store text node ID to original string mapping
-var textNodeMap = sync.Map{} // map[int]string
-
-func registerTextNode(nodeID int, text string) {
-	textNodeMap.Store(nodeID, strings.ToUpper(text))
+	return textnode.ID(s)
 }
 
 func getTextNodeName(nodeID int) (string, bool) {
-	if val, ok := textNodeMap.Load(nodeID); ok {
-		return val.(string), true
-	}
-	return "", false
+	return textnode.Name(nodeID)
 }
 
 // GetTextNodeName returns the original text name for a hashed node ID (public API)
@@ -1401,7 +1560,6 @@ func parseALinks(payload string) (ids []int, keyed map[int]bool) {
 		if _, dup := seen[nodeID]; !dup {
 			ids = append(ids, nodeID)
 			seen[nodeID] = struct{}{}
-			registerTextNode(nodeID, cleaned)
 		}
 		if isKeyed {
 			keyed[nodeID] = true
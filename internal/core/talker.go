@@ -1,6 +1,7 @@
 package core
 
 import (
+	"strings"
 	"sync"
 	"time"
 )
@@ -10,6 +11,7 @@ type TalkerEvent struct {
 	At          time.Time `json:"at"`
 	Kind        string    `json:"kind"`     // TX_START / TX_STOP
 	Node        int       `json:"node,omitempty"`
+	Source      int       `json:"source,omitempty"` // local node the link is on (multi-node hubs)
 	Callsign    string    `json:"callsign,omitempty"`
 	Description string    `json:"description,omitempty"`
 	Duration    int       `json:"duration,omitempty"` // Duration in seconds (for STOP events)
@@ -43,6 +45,27 @@ func (tl *TalkerLog) Snapshot() []TalkerEvent {
 	return out
 }
 
+// Forget removes events for callsign (case-insensitive) or any of nodes, returning the
+// number removed. Used to honour per-callsign data deletion requests.
+func (tl *TalkerLog) Forget(callsign string, nodes []int) int {
+	drop := make(map[int]bool, len(nodes))
+	for _, n := range nodes {
+		drop[n] = true
+	}
+	tl.mu.Lock()
+	defer tl.mu.Unlock()
+	kept := tl.buf[:0]
+	for _, e := range tl.buf {
+		if (callsign != "" && strings.EqualFold(e.Callsign, callsign)) || drop[e.Node] {
+			continue
+		}
+		kept = append(kept, e)
+	}
+	removed := len(tl.buf) - len(kept)
+	tl.buf = kept
+	return removed
+}
+
 func (tl *TalkerLog) pruneLocked() {
 	if tl.ttl <= 0 || len(tl.buf) == 0 {
 		return
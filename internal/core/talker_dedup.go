@@ -0,0 +1,79 @@
+package core
+
+import (
+	"sync"
+	"time"
+)
+
+// Talker presentation modes for hubs with several configured source nodes.
+const (
+	TalkerModePerSource    = "per_source"   // one event/log row per source node that saw the transmission
+	TalkerModeDeduplicated = "deduplicated" // one per transmission, whichever source reported it first
+)
+
+// talkerDedup collapses reports of one remote node's transmission that arrive through
+// several local source nodes (the remote is linked to more than one of them). Reports
+// are keyed by remote node; a report from a different source within window of the last
+// accepted one is a duplicate. Reports from the same source are left to the edge
+// arbiter and the repository, which already handle repeats per source.
+type talkerDedup struct {
+	window time.Duration
+
+	mu    sync.Mutex
+	edges map[int]dedupReport // remote node -> last accepted talker edge
+	txs   map[int]dedupReport // remote node -> last accepted transmission (keyed on its start)
+}
+
+type dedupReport struct {
+	source int
+	kind   string
+	at     time.Time
+}
+
+func newTalkerDedup(window time.Duration) *talkerDedup {
+	return &talkerDedup{window: window, edges: make(map[int]dedupReport), txs: make(map[int]dedupReport)}
+}
+
+// duplicateEdge reports whether a kind edge for node seen through source at the given
+// time repeats one already accepted through another source, recording it otherwise.
+func (d *talkerDedup) duplicateEdge(source, node int, kind string, at time.Time) bool {
+	return d.check(d.edges, source, node, kind, at)
+}
+
+// duplicateTx reports whether a transmission by node starting at start was already
+// logged through another source.
+func (d *talkerDedup) duplicateTx(source, node int, start time.Time) bool {
+	return d.check(d.txs, source, node, "", start)
+}
+
+func (d *talkerDedup) check(seen map[int]dedupReport, source, node int, kind string, at time.Time) bool {
+	if node == 0 {
+		return false // local TX is reported once already
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if prev, ok := seen[node]; ok && prev.source != source && prev.kind == kind {
+		if delta := at.Sub(prev.at); delta <= d.window && delta >= -d.window {
+			return true
+		}
+	}
+	seen[node] = dedupReport{source: source, kind: kind, at: at}
+	return false
+}
+
+// SetTalkerMode selects how transmissions seen through several source nodes are
+// presented in talker events and the transmission log. Reports of the same remote node
+// within window count as one in TalkerModeDeduplicated; any other mode keeps one per
+// source (the default).
+func (sm *StateManager) SetTalkerMode(mode string, window time.Duration) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	if mode != TalkerModeDeduplicated {
+		sm.dedup = nil
+		return
+	}
+	if window <= 0 {
+		window = 3 * time.Second
+	}
+	sm.dedup = newTalkerDedup(window)
+}
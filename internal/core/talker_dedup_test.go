@@ -0,0 +1,54 @@
+package core
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTalkerModeDeduplicatesAcrossSources(t *testing.T) {
+	edges := func(mode string) []TalkerEvent {
+		sm := NewStateManager()
+		sm.SetTalkerMode(mode, 2*time.Second)
+		for _, kind := range []string{"TX_START", "TX_STOP"} {
+			for _, local := range []int{1999, 1998} {
+				sm.emitTalkerFromLink(kind, &LinkInfo{Node: 2001, LocalNode: local, NodeCallsign: "W1AW"})
+			}
+		}
+		// A different remote node is never collapsed into 2001
+		sm.emitTalkerFromLink("TX_START", &LinkInfo{Node: 2002, LocalNode: 1998})
+		return sm.log.Snapshot()
+	}
+
+	if got := edges(TalkerModePerSource); len(got) != 5 || got[1].Source != 1998 {
+		t.Fatalf("per_source events = %+v", got)
+	}
+	got := edges(TalkerModeDeduplicated)
+	if len(got) != 3 || got[0].Kind != "TX_START" || got[1].Kind != "TX_STOP" || got[2].Node != 2002 {
+		t.Fatalf("deduplicated events = %+v", got)
+	}
+	for _, e := range got[:2] {
+		if e.Source != 1999 {
+			t.Fatalf("first reporting source not kept: %+v", e)
+		}
+	}
+}
+
+func TestTalkerDedupWindow(t *testing.T) {
+	d := newTalkerDedup(2 * time.Second)
+	t0 := time.Date(2025, 1, 2, 12, 0, 0, 0, time.UTC)
+	if d.duplicateTx(1999, 2001, t0) {
+		t.Fatal("first report marked duplicate")
+	}
+	if !d.duplicateTx(1998, 2001, t0.Add(-time.Second)) {
+		t.Fatal("second source within window not collapsed")
+	}
+	if d.duplicateTx(1999, 2001, t0.Add(time.Second)) {
+		t.Fatal("same source must be left to the repository")
+	}
+	if d.duplicateTx(1998, 2001, t0.Add(time.Minute)) {
+		t.Fatal("report outside the window collapsed")
+	}
+	if d.duplicateEdge(1998, 0, "TX_START", t0) || d.duplicateEdge(1999, 0, "TX_START", t0) {
+		t.Fatal("local TX edges must not be deduplicated")
+	}
+}
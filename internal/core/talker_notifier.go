@@ -0,0 +1,219 @@
+package core
+
+import (
+	"context"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// TalkerNotifyOptions filter which transmissions produce notifications.
+type TalkerNotifyOptions struct {
+	MinDuration    time.Duration // ignore transmissions shorter than this (kerchunks)
+	Cooldown       time.Duration // per callsign (or node) between immediate notifications
+	QuietStartHour int           // local hour (0-23) notifications stop; equal to QuietEndHour = no quiet hours
+	QuietEndHour   int           // local hour (0-23) notifications resume; 23 -> 7 wraps midnight
+	Digest         bool          // send one summary per DigestInterval instead of one message per transmission
+	DigestInterval time.Duration
+}
+
+// TalkerDigestEntry summarizes one talker within a digest.
+type TalkerDigestEntry struct {
+	Callsign      string `json:"callsign,omitempty"`
+	Node          int    `json:"node"`
+	Transmissions int    `json:"transmissions"`
+	Seconds       int    `json:"seconds"`
+}
+
+// TalkerDigest summarizes the transmissions seen over a period.
+type TalkerDigest struct {
+	From          time.Time           `json:"from"`
+	To            time.Time           `json:"to"`
+	Transmissions int                 `json:"transmissions"`
+	Seconds       int                 `json:"seconds"`
+	Talkers       []TalkerDigestEntry `json:"talkers"`
+}
+
+// TalkerNotification is either a single transmission or a digest.
+type TalkerNotification struct {
+	Event  *TalkerEvent
+	Digest *TalkerDigest
+}
+
+// TalkerNotifier turns completed transmissions (TX_STOP) into notifications, applying a
+// minimum duration, a per-callsign cooldown and quiet hours. In digest mode
+// transmissions are summarized instead; a digest due during quiet hours is held back
+// until they end, so nothing is lost.
+type TalkerNotifier struct {
+	mu       sync.Mutex
+	opts     TalkerNotifyOptions
+	action   func(ctx context.Context, n TalkerNotification)
+	lastSent map[string]time.Time // cooldown key -> last immediate notification
+	digest   map[string]*TalkerDigestEntry
+	since    time.Time // start of the current digest period
+	events   chan TalkerEvent
+	now      func() time.Time
+	stopCh   chan struct{}
+	stopOnce sync.Once
+}
+
+// NewTalkerNotifier creates a notifier. A zero DigestInterval defaults to one hour.
+func NewTalkerNotifier(opts TalkerNotifyOptions) *TalkerNotifier {
+	if opts.DigestInterval <= 0 {
+		opts.DigestInterval = time.Hour
+	}
+	opts.QuietStartHour = clampHour(opts.QuietStartHour)
+	opts.QuietEndHour = clampHour(opts.QuietEndHour)
+	return &TalkerNotifier{
+		opts:     opts,
+		lastSent: make(map[string]time.Time),
+		digest:   make(map[string]*TalkerDigestEntry),
+		since:    time.Now(),
+		events:   make(chan TalkerEvent, 64),
+		now:      time.Now,
+		stopCh:   make(chan struct{}),
+	}
+}
+
+// SetAction configures the callback that delivers notifications (e.g. a webhook).
+func (tn *TalkerNotifier) SetAction(fn func(ctx context.Context, n TalkerNotification)) {
+	tn.mu.Lock()
+	tn.action = fn
+	tn.mu.Unlock()
+}
+
+// Observe queues a talker event. It never blocks, so it is safe to register as a
+// StateManager talker hook.
+func (tn *TalkerNotifier) Observe(evt TalkerEvent) {
+	if evt.Kind != "TX_STOP" {
+		return
+	}
+	select {
+	case tn.events <- evt:
+	default:
+	}
+}
+
+// Start processes queued events and flushes digests until Stop is called.
+func (tn *TalkerNotifier) Start() {
+	go func() {
+		var tick <-chan time.Time
+		if tn.opts.Digest {
+			// Check often so a digest held back by quiet hours goes out soon after they end
+			ticker := time.NewTicker(time.Minute)
+			defer ticker.Stop()
+			tick = ticker.C
+		}
+		for {
+			select {
+			case evt := <-tn.events:
+				tn.Handle(evt)
+			case <-tick:
+				tn.FlushDigest(false)
+			case <-tn.stopCh:
+				return
+			}
+		}
+	}()
+}
+
+// Stop terminates the background loop.
+func (tn *TalkerNotifier) Stop() {
+	tn.stopOnce.Do(func() { close(tn.stopCh) })
+}
+
+// Handle applies the filters to one event and delivers it or adds it to the digest.
+// It reports whether an immediate notification was sent.
+func (tn *TalkerNotifier) Handle(evt TalkerEvent) bool {
+	tn.mu.Lock()
+	now := tn.now()
+	if evt.Kind != "TX_STOP" || time.Duration(evt.Duration)*time.Second < tn.opts.MinDuration {
+		tn.mu.Unlock()
+		return false
+	}
+	key := strings.ToUpper(evt.Callsign)
+	if key == "" {
+		key = "#" + strconv.Itoa(evt.Node)
+	}
+	if tn.opts.Digest {
+		e, ok := tn.digest[key]
+		if !ok {
+			e = &TalkerDigestEntry{Callsign: evt.Callsign, Node: evt.Node}
+			tn.digest[key] = e
+		}
+		e.Transmissions++
+		e.Seconds += evt.Duration
+		tn.mu.Unlock()
+		return false
+	}
+	if tn.quietLocked(now) {
+		tn.mu.Unlock()
+		return false
+	}
+	if last, ok := tn.lastSent[key]; ok && now.Sub(last) < tn.opts.Cooldown {
+		tn.mu.Unlock()
+		return false
+	}
+	tn.lastSent[key] = now
+	action := tn.action
+	tn.mu.Unlock()
+
+	if action != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+		defer cancel()
+		action(ctx, TalkerNotification{Event: &evt})
+	}
+	return true
+}
+
+// FlushDigest sends the digest when the interval has elapsed (or immediately when force
+// is set) and there is something to report outside quiet hours. It reports whether a
+// digest was sent.
+func (tn *TalkerNotifier) FlushDigest(force bool) bool {
+	tn.mu.Lock()
+	now := tn.now()
+	if len(tn.digest) == 0 {
+		tn.since = now
+		tn.mu.Unlock()
+		return false
+	}
+	if (!force && now.Sub(tn.since) < tn.opts.DigestInterval) || tn.quietLocked(now) {
+		tn.mu.Unlock()
+		return false
+	}
+	d := TalkerDigest{From: tn.since, To: now, Talkers: make([]TalkerDigestEntry, 0, len(tn.digest))}
+	for _, e := range tn.digest {
+		d.Transmissions += e.Transmissions
+		d.Seconds += e.Seconds
+		d.Talkers = append(d.Talkers, *e)
+	}
+	sort.Slice(d.Talkers, func(i, j int) bool {
+		if d.Talkers[i].Seconds != d.Talkers[j].Seconds {
+			return d.Talkers[i].Seconds > d.Talkers[j].Seconds
+		}
+		return d.Talkers[i].Callsign < d.Talkers[j].Callsign
+	})
+	tn.digest = make(map[string]*TalkerDigestEntry)
+	tn.since = now
+	action := tn.action
+	tn.mu.Unlock()
+
+	log.Printf("[TALKER NOTIFY] digest: %d transmissions from %d talkers", d.Transmissions, len(d.Talkers))
+	if action != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+		defer cancel()
+		action(ctx, TalkerNotification{Digest: &d})
+	}
+	return true
+}
+
+// quietLocked reports whether t falls inside quiet hours (tn.mu must be held).
+func (tn *TalkerNotifier) quietLocked(t time.Time) bool {
+	if tn.opts.QuietStartHour == tn.opts.QuietEndHour {
+		return false
+	}
+	return inHourWindow(t.Hour(), tn.opts.QuietStartHour, tn.opts.QuietEndHour)
+}
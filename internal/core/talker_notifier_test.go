@@ -0,0 +1,82 @@
+package core
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func newTestNotifier(opts TalkerNotifyOptions, now *time.Time) (*TalkerNotifier, *[]TalkerNotification) {
+	tn := NewTalkerNotifier(opts)
+	tn.now = func() time.Time { return *now }
+	tn.since = *now
+	var sent []TalkerNotification
+	tn.SetAction(func(ctx context.Context, n TalkerNotification) { sent = append(sent, n) })
+	return tn, &sent
+}
+
+func TestTalkerNotifierDebounceAndQuietHours(t *testing.T) {
+	now := time.Date(2025, 1, 6, 12, 0, 0, 0, time.Local)
+	tn, sent := newTestNotifier(TalkerNotifyOptions{MinDuration: 5 * time.Second, Cooldown: 10 * time.Minute, QuietStartHour: 23, QuietEndHour: 7}, &now)
+
+	if tn.Handle(TalkerEvent{Kind: "TX_STOP", Callsign: "W1AW", Duration: 2}) {
+		t.Fatal("kerchunk below min_duration should not notify")
+	}
+	if tn.Handle(TalkerEvent{Kind: "TX_START", Callsign: "W1AW"}) {
+		t.Fatal("only TX_STOP notifies")
+	}
+	if !tn.Handle(TalkerEvent{Kind: "TX_STOP", Callsign: "W1AW", Duration: 30}) {
+		t.Fatal("expected notification")
+	}
+	now = now.Add(5 * time.Minute)
+	if tn.Handle(TalkerEvent{Kind: "TX_STOP", Callsign: "w1aw", Duration: 30}) {
+		t.Fatal("same callsign within cooldown should not notify")
+	}
+	if !tn.Handle(TalkerEvent{Kind: "TX_STOP", Callsign: "K8ABC", Duration: 30}) {
+		t.Fatal("cooldown is per callsign")
+	}
+	now = time.Date(2025, 1, 6, 23, 30, 0, 0, time.Local)
+	if tn.Handle(TalkerEvent{Kind: "TX_STOP", Callsign: "N8XYZ", Duration: 30}) {
+		t.Fatal("quiet hours should suppress notifications")
+	}
+	now = time.Date(2025, 1, 7, 7, 0, 0, 0, time.Local)
+	if !tn.Handle(TalkerEvent{Kind: "TX_STOP", Callsign: "N8XYZ", Duration: 30}) {
+		t.Fatal("notifications resume after quiet hours")
+	}
+	if len(*sent) != 3 || (*sent)[0].Event.Callsign != "W1AW" {
+		t.Fatalf("sent = %+v", *sent)
+	}
+}
+
+func TestTalkerNotifierDigest(t *testing.T) {
+	now := time.Date(2025, 1, 6, 22, 0, 0, 0, time.Local)
+	tn, sent := newTestNotifier(TalkerNotifyOptions{MinDuration: 5 * time.Second, QuietStartHour: 23, QuietEndHour: 7, Digest: true}, &now)
+
+	tn.Handle(TalkerEvent{Kind: "TX_STOP", Callsign: "W1AW", Node: 2000, Duration: 30})
+	tn.Handle(TalkerEvent{Kind: "TX_STOP", Callsign: "W1AW", Node: 2000, Duration: 60})
+	tn.Handle(TalkerEvent{Kind: "TX_STOP", Node: 2001, Duration: 20})
+	tn.Handle(TalkerEvent{Kind: "TX_STOP", Callsign: "K8ABC", Duration: 1}) // below min_duration
+	if len(*sent) != 0 {
+		t.Fatal("digest mode must not notify per transmission")
+	}
+	now = now.Add(30 * time.Minute)
+	if tn.FlushDigest(false) {
+		t.Fatal("digest sent before the interval elapsed")
+	}
+	// Due at 23:00, inside quiet hours: held until 07:00
+	now = now.Add(40 * time.Minute)
+	if tn.FlushDigest(false) {
+		t.Fatal("digest sent during quiet hours")
+	}
+	now = time.Date(2025, 1, 7, 7, 1, 0, 0, time.Local)
+	if !tn.FlushDigest(false) {
+		t.Fatal("held digest should go out after quiet hours")
+	}
+	d := (*sent)[0].Digest
+	if d == nil || d.Transmissions != 3 || d.Seconds != 110 || len(d.Talkers) != 2 || d.Talkers[0].Callsign != "W1AW" || d.Talkers[0].Transmissions != 2 {
+		t.Fatalf("digest = %+v", d)
+	}
+	if tn.FlushDigest(true) {
+		t.Fatal("empty digest should not be sent")
+	}
+}
@@ -0,0 +1,67 @@
+package core
+
+import (
+	"sort"
+	"time"
+
+	"github.com/dbehnke/allstar-nexus/internal/timefmt"
+)
+
+// TalkerSession is one transmission reconstructed from a TX_START/TX_STOP pair.
+type TalkerSession struct {
+	Node          int        `json:"node,omitempty"` // 0 = local node TX
+	Source        int        `json:"source,omitempty"`
+	Callsign      string     `json:"callsign,omitempty"`
+	Description   string     `json:"description,omitempty"`
+	Start         time.Time  `json:"start"`
+	End           *time.Time `json:"end,omitempty"`  // nil while ongoing
+	Duration      int        `json:"duration"`       // seconds; for ongoing sessions, so far
+	DurationHuman string     `json:"duration_human"` // Duration as "2h 13m"
+	Ongoing       bool       `json:"ongoing,omitempty"`
+}
+
+// GroupTalkerSessions pairs talker events (oldest first, as in the talker log) into
+// sessions per node and source, newest first. A TX_STOP whose TX_START has already
+// aged out of the log starts Duration seconds before it; a TX_START without a TX_STOP
+// yet is ongoing, timed up to now.
+func GroupTalkerSessions(events []TalkerEvent, now time.Time) []TalkerSession {
+	type key struct{ node, source int }
+	open := make(map[key]int) // index into out of the session awaiting its TX_STOP
+	out := make([]TalkerSession, 0)
+	for _, evt := range events {
+		k := key{evt.Node, evt.Source}
+		switch evt.Kind {
+		case "TX_START":
+			if _, ok := open[k]; ok {
+				continue // repeated start while already open
+			}
+			open[k] = len(out)
+			out = append(out, TalkerSession{Node: evt.Node, Source: evt.Source, Callsign: evt.Callsign, Description: evt.Description, Start: evt.At, Ongoing: true})
+		case "TX_STOP":
+			end := evt.At
+			i, ok := open[k]
+			if !ok {
+				out = append(out, TalkerSession{Node: evt.Node, Source: evt.Source, Start: end.Add(-time.Duration(evt.Duration) * time.Second)})
+				i = len(out) - 1
+			}
+			delete(open, k)
+			s := &out[i]
+			s.End, s.Ongoing = &end, false
+			s.Duration = evt.Duration
+			if s.Duration <= 0 {
+				s.Duration = int(end.Sub(s.Start).Seconds())
+			}
+			if s.Callsign == "" {
+				s.Callsign, s.Description = evt.Callsign, evt.Description
+			}
+		}
+	}
+	for _, i := range open {
+		out[i].Duration = int(now.Sub(out[i].Start).Seconds())
+	}
+	for i := range out {
+		out[i].DurationHuman = timefmt.Seconds(int64(out[i].Duration))
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Start.After(out[j].Start) })
+	return out
+}
@@ -0,0 +1,33 @@
+package core
+
+import (
+	"testing"
+	"time"
+)
+
+func TestGroupTalkerSessions(t *testing.T) {
+	t0 := time.Date(2025, 1, 2, 12, 0, 0, 0, time.UTC)
+	events := []TalkerEvent{
+		{At: t0, Kind: "TX_STOP", Node: 2000, Duration: 30}, // start aged out of the log
+		{At: t0.Add(10 * time.Second), Kind: "TX_START", Node: 2001, Callsign: "W1AW"},
+		{At: t0.Add(12 * time.Second), Kind: "TX_START", Node: 2001, Source: 1998}, // same node via another source
+		{At: t0.Add(25 * time.Second), Kind: "TX_STOP", Node: 2001, Callsign: "W1AW", Duration: 15},
+		{At: t0.Add(40 * time.Second), Kind: "TX_START", Node: 2002, Callsign: "K8ABC"},
+	}
+	got := GroupTalkerSessions(events, t0.Add(time.Minute))
+	if len(got) != 4 {
+		t.Fatalf("sessions = %+v", got)
+	}
+	if s := got[0]; s.Node != 2002 || !s.Ongoing || s.End != nil || s.Duration != 20 || s.Callsign != "K8ABC" {
+		t.Fatalf("ongoing session = %+v", s)
+	}
+	if s := got[1]; s.Source != 1998 || !s.Ongoing {
+		t.Fatalf("per-source session = %+v", s)
+	}
+	if s := got[2]; s.Node != 2001 || s.Ongoing || s.Duration != 15 || !s.End.Equal(t0.Add(25*time.Second)) {
+		t.Fatalf("closed session = %+v", s)
+	}
+	if s := got[3]; s.Node != 2000 || !s.Start.Equal(t0.Add(-30*time.Second)) || s.Duration != 30 {
+		t.Fatalf("orphan stop = %+v", s)
+	}
+}
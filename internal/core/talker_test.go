@@ -0,0 +1,23 @@
+package core
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTalkerLogForget(t *testing.T) {
+	tl := NewTalkerLog(10, time.Hour)
+	now := time.Now()
+	tl.Add(TalkerEvent{At: now, Kind: "TX_START", Node: 2000, Callsign: "W1AW"})
+	tl.Add(TalkerEvent{At: now, Kind: "TX_START", Node: 2001}) // not enriched yet, matched by node
+	tl.Add(TalkerEvent{At: now, Kind: "TX_START", Node: 3000, Callsign: "k8xyz"})
+	tl.Add(TalkerEvent{At: now, Kind: "TX_START", Node: 4000, Callsign: "N0CALL"})
+
+	if n := tl.Forget("K8XYZ", []int{2000, 2001}); n != 3 {
+		t.Fatalf("removed %d events, want 3", n)
+	}
+	snap := tl.Snapshot()
+	if len(snap) != 1 || snap[0].Callsign != "N0CALL" {
+		t.Fatalf("unexpected remaining events: %+v", snap)
+	}
+}
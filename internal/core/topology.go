@@ -0,0 +1,282 @@
+package core
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dbehnke/allstar-nexus/internal/ami"
+	"github.com/dbehnke/allstar-nexus/internal/textnode"
+)
+
+// Topology edge kinds. XStat only reports a node's own connections plus a flat list of
+// every node in the linked network, so paths beyond the first hop are inferred.
+const (
+	EdgeDirect   = "direct"   // From and To are connected (XStat Conn line)
+	EdgeVia      = "via"      // To is reached through From, the only remote neighbour of a local node
+	EdgeIndirect = "indirect" // To is in the network of local node From; the path is unknown
+)
+
+// TopologyNode is a vertex of the link graph.
+type TopologyNode struct {
+	Node        int    `json:"node"`
+	Local       bool   `json:"local"`
+	Hops        int    `json:"hops"` // 0 = local, 1 = adjacent, 2 = two or more
+	Callsign    string `json:"callsign,omitempty"`
+	Description string `json:"description,omitempty"`
+	Location    string `json:"location,omitempty"`
+}
+
+// TopologyEdge connects two nodes of the graph.
+type TopologyEdge struct {
+	From      int    `json:"from"`
+	To        int    `json:"to"`
+	Kind      string `json:"kind"`
+	Mode      string `json:"mode,omitempty"`      // T/R/C/M as reported by LinkedNodes
+	Direction string `json:"direction,omitempty"` // IN/OUT, direct edges only
+	LinkType  string `json:"link_type,omitempty"`
+}
+
+// Topology is a snapshot of the multi-hop link graph across all local nodes.
+type Topology struct {
+	Nodes     []TopologyNode `json:"nodes"`
+	Edges     []TopologyEdge `json:"edges"`
+	Version   uint64         `json:"version"`
+	UpdatedAt time.Time      `json:"updated_at"`
+}
+
+// topologyView is what one poll of a local node reported.
+type topologyView struct {
+	direct []ami.ConnectionWithHistory
+	linked []ami.LinkedNode
+}
+
+// TopologyService assembles the link graph from polled XStat results and publishes a
+// new snapshot whenever its shape changes.
+type TopologyService struct {
+	mu        sync.RWMutex
+	views     map[int]topologyView // by local node
+	lookup    func(node int) *NodeInfo
+	info      map[int]*NodeInfo // lookup cache for nodes currently in the graph
+	current   Topology
+	signature string
+	out       chan Topology
+	onChange  func(Topology)
+}
+
+// NewTopologyService creates an empty topology.
+func NewTopologyService() *TopologyService {
+	return &TopologyService{
+		views:   make(map[int]topologyView),
+		info:    make(map[int]*NodeInfo),
+		current: Topology{Nodes: []TopologyNode{}, Edges: []TopologyEdge{}},
+		out:     make(chan Topology, 4),
+	}
+}
+
+// SetLookup configures the astdb lookup used to label nodes.
+func (ts *TopologyService) SetLookup(fn func(node int) *NodeInfo) {
+	ts.mu.Lock()
+	ts.lookup = fn
+	ts.mu.Unlock()
+}
+
+// SetChangeHook installs a callback invoked with every new graph (e.g. history recording).
+func (ts *TopologyService) SetChangeHook(fn func(Topology)) {
+	ts.mu.Lock()
+	ts.onChange = fn
+	ts.mu.Unlock()
+}
+
+// Updates delivers a snapshot each time the graph changes.
+func (ts *TopologyService) Updates() <-chan Topology { return ts.out }
+
+// Snapshot returns the current graph.
+func (ts *TopologyService) Snapshot() Topology {
+	ts.mu.RLock()
+	defer ts.mu.RUnlock()
+	return ts.current
+}
+
+// Update records a poll result for a local node and rebuilds the graph.
+func (ts *TopologyService) Update(status *ami.CombinedNodeStatus) {
+	if status == nil {
+		return
+	}
+	view := topologyView{linked: status.LinkedNodes}
+	for _, c := range status.Connections {
+		// Among connections synthesized from LinkedNodes only text nodes (whose Conn
+		// lines cannot be parsed) are taken as adjacent
+		if !c.Synthetic || c.Node < 0 {
+			view.direct = append(view.direct, c)
+		}
+	}
+
+	ts.mu.Lock()
+	ts.views[status.Node] = view
+	topo, sig := ts.buildLocked()
+	if sig == ts.signature {
+		ts.mu.Unlock()
+		return
+	}
+	ts.signature = sig
+	topo.Version = ts.current.Version + 1
+	topo.UpdatedAt = status.Timestamp
+	if topo.UpdatedAt.IsZero() {
+		topo.UpdatedAt = time.Now()
+	}
+	ts.current = topo
+	onChange := ts.onChange
+	ts.mu.Unlock()
+
+	select {
+	case ts.out <- topo:
+	default:
+	}
+	if onChange != nil {
+		onChange(topo)
+	}
+}
+
+// Label fills in astdb details for the nodes of t, typically a graph read back from
+// history. Details reflect the current astdb, not the one at the time.
+func (ts *TopologyService) Label(t Topology) Topology {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	nodes := make([]TopologyNode, len(t.Nodes))
+	for i, n := range t.Nodes {
+		if info := ts.nodeInfoLocked(n.Node); info != nil {
+			n.Callsign, n.Description, n.Location = info.Callsign, info.Description, info.Location
+		}
+		nodes[i] = n
+	}
+	t.Nodes = nodes
+	return t
+}
+
+// buildLocked assembles nodes and edges from all views and returns them with a
+// signature of the graph shape. Caller holds ts.mu.
+func (ts *TopologyService) buildLocked() (Topology, string) {
+	locals := make([]int, 0, len(ts.views))
+	for n := range ts.views {
+		locals = append(locals, n)
+	}
+	sort.Ints(locals)
+	isLocal := make(map[int]bool, len(locals))
+	for _, n := range locals {
+		isLocal[n] = true
+	}
+
+	hops := make(map[int]int)
+	setHops := func(n, h int) {
+		if cur, ok := hops[n]; !ok || h < cur {
+			hops[n] = h
+		}
+	}
+	edges := make(map[[3]int]TopologyEdge) // {from, to, kind} -> edge
+	kindKey := map[string]int{EdgeDirect: 0, EdgeVia: 1, EdgeIndirect: 2}
+	addEdge := func(e TopologyEdge) {
+		if e.Kind == EdgeDirect && e.From > e.To && isLocal[e.To] {
+			// Links between two local nodes are reported by both; keep one
+			e.From, e.To = e.To, e.From
+		}
+		edges[[3]int{e.From, e.To, kindKey[e.Kind]}] = e
+	}
+
+	adjacent := make(map[int]bool) // adjacent to some local node
+	for _, local := range locals {
+		setHops(local, 0)
+		for _, c := range ts.views[local].direct {
+			adjacent[c.Node] = true
+		}
+	}
+
+	// Each multi-hop node gets one edge; a via edge beats an indirect one from another local node
+	reached := make(map[int]TopologyEdge)
+	for _, local := range locals {
+		v := ts.views[local]
+		modes := make(map[int]string, len(v.linked))
+		for _, ln := range v.linked {
+			modes[ln.Node] = ln.Mode
+		}
+		var gateways []int
+		seen := make(map[int]bool, len(v.direct))
+		for _, c := range v.direct {
+			seen[c.Node] = true
+			setHops(c.Node, 1)
+			mode := c.Mode
+			if mode == "" {
+				mode = modes[c.Node]
+			}
+			addEdge(TopologyEdge{From: local, To: c.Node, Kind: EdgeDirect, Mode: mode, Direction: c.Direction, LinkType: c.LinkType})
+			if !isLocal[c.Node] {
+				gateways = append(gateways, c.Node)
+			}
+		}
+		for _, ln := range v.linked {
+			if seen[ln.Node] || ln.Node == local || isLocal[ln.Node] || adjacent[ln.Node] {
+				continue
+			}
+			setHops(ln.Node, 2)
+			e := TopologyEdge{From: local, To: ln.Node, Kind: EdgeIndirect, Mode: ln.Mode}
+			if len(gateways) == 1 {
+				e.From, e.Kind = gateways[0], EdgeVia
+			}
+			if prev, ok := reached[ln.Node]; !ok || (prev.Kind == EdgeIndirect && e.Kind == EdgeVia) {
+				reached[ln.Node] = e
+			}
+		}
+	}
+	for _, e := range reached {
+		addEdge(e)
+	}
+
+	// Drop lookups for nodes that left; look up newcomers
+	for n := range ts.info {
+		if _, ok := hops[n]; !ok {
+			delete(ts.info, n)
+		}
+	}
+	topo := Topology{Nodes: make([]TopologyNode, 0, len(hops)), Edges: make([]TopologyEdge, 0, len(edges))}
+	for n, h := range hops {
+		tn := TopologyNode{Node: n, Local: isLocal[n], Hops: h}
+		if info := ts.nodeInfoLocked(n); info != nil {
+			tn.Callsign, tn.Description, tn.Location = info.Callsign, info.Description, info.Location
+		}
+		topo.Nodes = append(topo.Nodes, tn)
+	}
+	for _, e := range edges {
+		topo.Edges = append(topo.Edges, e)
+	}
+	sort.Slice(topo.Nodes, func(i, j int) bool { return topo.Nodes[i].Node < topo.Nodes[j].Node })
+	sortTopologyEdges(topo.Edges)
+
+	var sig strings.Builder
+	for _, n := range topo.Nodes {
+		fmt.Fprintf(&sig, "%d:%d;", n.Node, n.Hops)
+	}
+	for _, e := range topo.Edges {
+		fmt.Fprintf(&sig, "%d>%d:%s:%s:%s;", e.From, e.To, e.Kind, e.Mode, e.Direction)
+	}
+	return topo, sig.String()
+}
+
+// nodeInfoLocked returns cached astdb details for n. Text nodes are named from the
+// shared registry. Caller holds ts.mu.
+func (ts *TopologyService) nodeInfoLocked(n int) *NodeInfo {
+	if info, ok := ts.info[n]; ok {
+		return info
+	}
+	var info *NodeInfo
+	if n < 0 {
+		if name, ok := textnode.Name(n); ok {
+			info = &NodeInfo{Node: n, Callsign: name, Description: "VOIP Client"}
+		}
+	} else if ts.lookup != nil {
+		info = ts.lookup(n)
+	}
+	ts.info[n] = info
+	return info
+}
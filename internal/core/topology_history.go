@@ -0,0 +1,296 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/dbehnke/allstar-nexus/backend/models"
+	"github.com/dbehnke/allstar-nexus/backend/repository"
+)
+
+// TopologyDiff is the change between two graphs. Nodes and Edges hold entries that
+// were added or changed; removed edges carry only From, To and Kind.
+type TopologyDiff struct {
+	Nodes        []TopologyNode `json:"nodes,omitempty"`
+	RemovedNodes []int          `json:"removed_nodes,omitempty"`
+	Edges        []TopologyEdge `json:"edges,omitempty"`
+	RemovedEdges []TopologyEdge `json:"removed_edges,omitempty"`
+}
+
+// Empty reports whether the diff changes nothing.
+func (d TopologyDiff) Empty() bool {
+	return len(d.Nodes) == 0 && len(d.RemovedNodes) == 0 && len(d.Edges) == 0 && len(d.RemovedEdges) == 0
+}
+
+type topologyEdgeKey struct {
+	from, to int
+	kind     string
+}
+
+func edgeKeyOf(e TopologyEdge) topologyEdgeKey { return topologyEdgeKey{e.From, e.To, e.Kind} }
+
+// DiffTopology returns what changed from prev to next.
+func DiffTopology(prev, next Topology) TopologyDiff {
+	var d TopologyDiff
+	oldNodes := make(map[int]TopologyNode, len(prev.Nodes))
+	for _, n := range prev.Nodes {
+		oldNodes[n.Node] = n
+	}
+	for _, n := range next.Nodes {
+		if old, ok := oldNodes[n.Node]; !ok || old != n {
+			d.Nodes = append(d.Nodes, n)
+		}
+		delete(oldNodes, n.Node)
+	}
+	for n := range oldNodes {
+		d.RemovedNodes = append(d.RemovedNodes, n)
+	}
+	sort.Ints(d.RemovedNodes)
+
+	oldEdges := make(map[topologyEdgeKey]TopologyEdge, len(prev.Edges))
+	for _, e := range prev.Edges {
+		oldEdges[edgeKeyOf(e)] = e
+	}
+	for _, e := range next.Edges {
+		if old, ok := oldEdges[edgeKeyOf(e)]; !ok || old != e {
+			d.Edges = append(d.Edges, e)
+		}
+		delete(oldEdges, edgeKeyOf(e))
+	}
+	for k := range oldEdges {
+		d.RemovedEdges = append(d.RemovedEdges, TopologyEdge{From: k.from, To: k.to, Kind: k.kind})
+	}
+	sortTopologyEdges(d.RemovedEdges)
+	return d
+}
+
+// ApplyTopologyDiff returns t with d applied.
+func ApplyTopologyDiff(t Topology, d TopologyDiff) Topology {
+	nodes := make(map[int]TopologyNode, len(t.Nodes)+len(d.Nodes))
+	for _, n := range t.Nodes {
+		nodes[n.Node] = n
+	}
+	for _, n := range d.RemovedNodes {
+		delete(nodes, n)
+	}
+	for _, n := range d.Nodes {
+		nodes[n.Node] = n
+	}
+	edges := make(map[topologyEdgeKey]TopologyEdge, len(t.Edges)+len(d.Edges))
+	for _, e := range t.Edges {
+		edges[edgeKeyOf(e)] = e
+	}
+	for _, e := range d.RemovedEdges {
+		delete(edges, edgeKeyOf(e))
+	}
+	for _, e := range d.Edges {
+		edges[edgeKeyOf(e)] = e
+	}
+
+	out := Topology{Nodes: make([]TopologyNode, 0, len(nodes)), Edges: make([]TopologyEdge, 0, len(edges)), Version: t.Version, UpdatedAt: t.UpdatedAt}
+	for _, n := range nodes {
+		out.Nodes = append(out.Nodes, n)
+	}
+	for _, e := range edges {
+		out.Edges = append(out.Edges, e)
+	}
+	sort.Slice(out.Nodes, func(i, j int) bool { return out.Nodes[i].Node < out.Nodes[j].Node })
+	sortTopologyEdges(out.Edges)
+	return out
+}
+
+func sortTopologyEdges(edges []TopologyEdge) {
+	sort.Slice(edges, func(i, j int) bool {
+		a, b := edges[i], edges[j]
+		if a.From != b.From {
+			return a.From < b.From
+		}
+		if a.To != b.To {
+			return a.To < b.To
+		}
+		return a.Kind < b.Kind
+	})
+}
+
+// stripTopologyLabels drops astdb details so history only records structure; labels
+// are looked up again when a past graph is read back.
+func stripTopologyLabels(t Topology) Topology {
+	nodes := make([]TopologyNode, len(t.Nodes))
+	for i, n := range t.Nodes {
+		n.Callsign, n.Description, n.Location = "", "", ""
+		nodes[i] = n
+	}
+	t.Nodes = nodes
+	return t
+}
+
+// TopologyHistory records the link graph so past states can be reconstructed. At most
+// one entry is written per interval: a full keyframe every keyframeEvery entries and a
+// diff against the previous entry otherwise. Unchanged graphs are not written.
+type TopologyHistory struct {
+	repo          *repository.TopologyHistoryRepo
+	interval      time.Duration
+	keyframeEvery int
+	retention     time.Duration // 0 keeps everything
+
+	mu       sync.Mutex
+	pending  *Topology
+	last     *Topology // last graph written, labels stripped
+	sinceKey int       // diffs written since the last keyframe
+
+	stopCh   chan struct{}
+	stopOnce sync.Once
+}
+
+// NewTopologyHistory creates a recorder. Zero values select a 1 minute interval and a
+// keyframe every 60 entries.
+func NewTopologyHistory(repo *repository.TopologyHistoryRepo, interval time.Duration, keyframeEvery int, retention time.Duration) *TopologyHistory {
+	if interval <= 0 {
+		interval = time.Minute
+	}
+	if keyframeEvery <= 0 {
+		keyframeEvery = 60
+	}
+	return &TopologyHistory{
+		repo:          repo,
+		interval:      interval,
+		keyframeEvery: keyframeEvery,
+		retention:     retention,
+		stopCh:        make(chan struct{}),
+	}
+}
+
+// Observe queues t to be written at the next flush. Only the latest graph is kept.
+func (h *TopologyHistory) Observe(t Topology) {
+	h.mu.Lock()
+	h.pending = &t
+	h.mu.Unlock()
+}
+
+// Start flushes every interval and prunes expired history hourly until Stop is called.
+func (h *TopologyHistory) Start() {
+	go func() {
+		flush := time.NewTicker(h.interval)
+		prune := time.NewTicker(time.Hour)
+		defer flush.Stop()
+		defer prune.Stop()
+		h.prune()
+		for {
+			select {
+			case <-flush.C:
+				h.flushLogged()
+			case <-prune.C:
+				h.prune()
+			case <-h.stopCh:
+				h.flushLogged()
+				return
+			}
+		}
+	}()
+}
+
+// Stop writes any pending graph and terminates the background loop.
+func (h *TopologyHistory) Stop() {
+	h.stopOnce.Do(func() { close(h.stopCh) })
+}
+
+func (h *TopologyHistory) flushLogged() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := h.Flush(ctx); err != nil {
+		log.Printf("[TOPOLOGY] history write failed: %v", err)
+	}
+}
+
+func (h *TopologyHistory) prune() {
+	if h.retention <= 0 {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if n, err := h.repo.Prune(ctx, time.Now().Add(-h.retention)); err != nil {
+		log.Printf("[TOPOLOGY] history prune failed: %v", err)
+	} else if n > 0 {
+		log.Printf("[TOPOLOGY] pruned %d history entries", n)
+	}
+}
+
+// Flush writes the pending graph, if it differs from the last one written.
+func (h *TopologyHistory) Flush(ctx context.Context) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.pending == nil {
+		return nil
+	}
+	next := stripTopologyLabels(*h.pending)
+	h.pending = nil
+
+	row := models.TopologySnapshot{RecordedAt: next.UpdatedAt, Version: next.Version}
+	if row.RecordedAt.IsZero() {
+		row.RecordedAt = time.Now()
+	}
+	var data any = next
+	if h.last != nil && h.sinceKey < h.keyframeEvery {
+		diff := DiffTopology(*h.last, next)
+		if diff.Empty() {
+			return nil
+		}
+		data = diff
+	} else {
+		row.Keyframe = true
+	}
+	b, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	row.Data = string(b)
+	if err := h.repo.Append(ctx, &row); err != nil {
+		return err
+	}
+	h.last = &next
+	if row.Keyframe {
+		h.sinceKey = 0
+	} else {
+		h.sinceKey++
+	}
+	return nil
+}
+
+// At reconstructs the graph as it was at the given time and returns it with the time
+// of the entry it reflects. ok is false when no history reaches back that far.
+func (h *TopologyHistory) At(ctx context.Context, at time.Time) (topo Topology, recordedAt time.Time, ok bool, err error) {
+	chain, err := h.repo.Chain(ctx, at)
+	if err != nil || len(chain) == 0 {
+		return Topology{}, time.Time{}, false, err
+	}
+	if err := json.Unmarshal([]byte(chain[0].Data), &topo); err != nil {
+		return Topology{}, time.Time{}, false, fmt.Errorf("decode keyframe %d: %w", chain[0].ID, err)
+	}
+	for _, row := range chain[1:] {
+		if row.Keyframe {
+			err = json.Unmarshal([]byte(row.Data), &topo)
+		} else {
+			var d TopologyDiff
+			if err = json.Unmarshal([]byte(row.Data), &d); err == nil {
+				topo = ApplyTopologyDiff(topo, d)
+			}
+		}
+		if err != nil {
+			return Topology{}, time.Time{}, false, fmt.Errorf("decode history entry %d: %w", row.ID, err)
+		}
+	}
+	last := chain[len(chain)-1]
+	topo.Version, topo.UpdatedAt = last.Version, last.RecordedAt
+	if topo.Nodes == nil {
+		topo.Nodes = []TopologyNode{}
+	}
+	if topo.Edges == nil {
+		topo.Edges = []TopologyEdge{}
+	}
+	return topo, last.RecordedAt, true, nil
+}
@@ -0,0 +1,138 @@
+package core
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dbehnke/allstar-nexus/internal/ami"
+)
+
+func topoStatus(local int, direct []int, linked ...ami.LinkedNode) *ami.CombinedNodeStatus {
+	st := &ami.CombinedNodeStatus{Node: local, Timestamp: time.Now(), LinkedNodes: linked}
+	for _, n := range direct {
+		st.Connections = append(st.Connections, ami.ConnectionWithHistory{
+			Connection: ami.Connection{Node: n, Direction: "OUT", LinkType: "ESTABLISHED"},
+		})
+	}
+	return st
+}
+
+func findEdge(topo Topology, from, to int) (TopologyEdge, bool) {
+	for _, e := range topo.Edges {
+		if e.From == from && e.To == to {
+			return e, true
+		}
+	}
+	return TopologyEdge{}, false
+}
+
+func TestTopology_DirectViaAndIndirect(t *testing.T) {
+	ts := NewTopologyService()
+	ts.SetLookup(func(node int) *NodeInfo {
+		if node == 3001 {
+			return &NodeInfo{Node: node, Callsign: "W1AW"}
+		}
+		return nil
+	})
+
+	// 1000 has a single remote neighbour, so everything behind it is reached via 2001
+	ts.Update(topoStatus(1000, []int{2001},
+		ami.LinkedNode{Node: 2001, Mode: "T"}, ami.LinkedNode{Node: 3001, Mode: "T"}))
+	// 1001 has two neighbours; the path to 3002 is unknown
+	ts.Update(topoStatus(1001, []int{2002, 2003},
+		ami.LinkedNode{Node: 2002, Mode: "T"}, ami.LinkedNode{Node: 2003, Mode: "R"}, ami.LinkedNode{Node: 3002, Mode: "T"}))
+
+	topo := ts.Snapshot()
+	if e, ok := findEdge(topo, 1000, 2001); !ok || e.Kind != EdgeDirect || e.Mode != "T" || e.Direction != "OUT" {
+		t.Fatalf("direct edge 1000->2001 = %+v (found=%v)", e, ok)
+	}
+	if e, ok := findEdge(topo, 2001, 3001); !ok || e.Kind != EdgeVia {
+		t.Fatalf("via edge 2001->3001 = %+v (found=%v)", e, ok)
+	}
+	if e, ok := findEdge(topo, 1001, 3002); !ok || e.Kind != EdgeIndirect {
+		t.Fatalf("indirect edge 1001->3002 = %+v (found=%v)", e, ok)
+	}
+	if e, _ := findEdge(topo, 1001, 2003); e.Mode != "R" {
+		t.Fatalf("mode from LinkedNodes not applied: %+v", e)
+	}
+
+	hops := map[int]TopologyNode{}
+	for _, n := range topo.Nodes {
+		hops[n.Node] = n
+	}
+	if !hops[1000].Local || hops[1000].Hops != 0 || hops[2001].Hops != 1 || hops[3001].Hops != 2 {
+		t.Fatalf("unexpected nodes: %+v", topo.Nodes)
+	}
+	if hops[3001].Callsign != "W1AW" {
+		t.Fatalf("lookup not applied: %+v", hops[3001])
+	}
+}
+
+func TestTopology_LocalToLocalLinkDeduplicated(t *testing.T) {
+	ts := NewTopologyService()
+	ts.Update(topoStatus(1000, []int{1001}, ami.LinkedNode{Node: 1001, Mode: "T"}))
+	ts.Update(topoStatus(1001, []int{1000}, ami.LinkedNode{Node: 1000, Mode: "T"}))
+
+	topo := ts.Snapshot()
+	if len(topo.Edges) != 1 {
+		t.Fatalf("edges = %+v, want one", topo.Edges)
+	}
+	if e := topo.Edges[0]; e.From != 1000 || e.To != 1001 || e.Kind != EdgeDirect {
+		t.Fatalf("edge = %+v", e)
+	}
+}
+
+func TestTopology_PublishesOnlyOnChange(t *testing.T) {
+	ts := NewTopologyService()
+	ts.Update(topoStatus(1000, []int{2001}, ami.LinkedNode{Node: 2001, Mode: "T"}))
+	<-ts.Updates()
+
+	ts.Update(topoStatus(1000, []int{2001}, ami.LinkedNode{Node: 2001, Mode: "T"}))
+	select {
+	case topo := <-ts.Updates():
+		t.Fatalf("unchanged graph published version %d", topo.Version)
+	default:
+	}
+
+	ts.Update(topoStatus(1000, []int{2001, 2002}, ami.LinkedNode{Node: 2001, Mode: "T"}, ami.LinkedNode{Node: 2002, Mode: "T"}))
+	topo := <-ts.Updates()
+	if topo.Version != 2 || len(topo.Edges) != 2 {
+		t.Fatalf("version=%d edges=%+v", topo.Version, topo.Edges)
+	}
+}
+
+func TestTopology_SyntheticConnectionsAreNotAdjacent(t *testing.T) {
+	ts := NewTopologyService()
+	st := topoStatus(1000, []int{2001}, ami.LinkedNode{Node: 2001, Mode: "T"}, ami.LinkedNode{Node: 3001, Mode: "T"})
+	st.Connections = append(st.Connections, ami.ConnectionWithHistory{Connection: ami.Connection{Node: 3001}, Synthetic: true})
+	ts.Update(st)
+
+	if e, ok := findEdge(ts.Snapshot(), 2001, 3001); !ok || e.Kind != EdgeVia {
+		t.Fatalf("synthetic connection treated as direct: %+v", ts.Snapshot().Edges)
+	}
+}
+
+func TestTopologyDiffRoundTrip(t *testing.T) {
+	ts := NewTopologyService()
+	ts.Update(topoStatus(1000, []int{2001, 2002}, ami.LinkedNode{Node: 2001, Mode: "T"}, ami.LinkedNode{Node: 2002, Mode: "T"}, ami.LinkedNode{Node: 3001, Mode: "T"}))
+	prev := ts.Snapshot()
+	ts.Update(topoStatus(1000, []int{2002, 2003}, ami.LinkedNode{Node: 2002, Mode: "R"}, ami.LinkedNode{Node: 2003, Mode: "T"}))
+	next := ts.Snapshot()
+
+	d := DiffTopology(prev, next)
+	if d.Empty() {
+		t.Fatal("expected changes")
+	}
+	got := ApplyTopologyDiff(prev, d)
+	if len(got.Nodes) != len(next.Nodes) || len(got.Edges) != len(next.Edges) {
+		t.Fatalf("applied = %+v, want %+v", got, next)
+	}
+	for i := range got.Edges {
+		if got.Edges[i] != next.Edges[i] {
+			t.Fatalf("edge %d = %+v, want %+v", i, got.Edges[i], next.Edges[i])
+		}
+	}
+	if !DiffTopology(next, got).Empty() {
+		t.Fatal("round trip is not identical")
+	}
+}
@@ -0,0 +1,308 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Watch event kinds.
+const (
+	WatchAppeared     = "WATCH_APPEARED"      // the node showed up in the link graph
+	WatchAstDBUpdated = "WATCH_ASTDB_UPDATED" // the node (re)registered in astdb or its entry changed
+)
+
+// WatchTarget is an admin-defined node to look out for.
+type WatchTarget struct {
+	Node        int    `json:"node"`
+	Label       string `json:"label,omitempty"`
+	AutoConnect bool   `json:"auto_connect"`
+	ConnectFrom int    `json:"connect_from,omitempty"` // local node that issues the connect; 0 = primary node
+}
+
+// WatchStatus is a target plus what the watchlist last observed for it.
+type WatchStatus struct {
+	WatchTarget
+	Present          bool       `json:"present"`
+	SeenVia          int        `json:"seen_via,omitempty"` // local node the target was last seen linked to
+	LastSeenAt       *time.Time `json:"last_seen_at,omitempty"`
+	LastAlertAt      *time.Time `json:"last_alert_at,omitempty"`
+	LastConnectAt    *time.Time `json:"last_connect_at,omitempty"`
+	LastConnectError string     `json:"last_connect_error,omitempty"`
+	Callsign         string     `json:"callsign,omitempty"`
+	Description      string     `json:"description,omitempty"`
+	Location         string     `json:"location,omitempty"`
+}
+
+// WatchEvent is emitted when a watched node appears.
+type WatchEvent struct {
+	Kind         string    `json:"kind"`
+	Node         int       `json:"node"`
+	Label        string    `json:"label,omitempty"`
+	LocalNode    int       `json:"local_node,omitempty"`
+	Callsign     string    `json:"callsign,omitempty"`
+	Description  string    `json:"description,omitempty"`
+	Location     string    `json:"location,omitempty"`
+	ConnectSent  bool      `json:"connect_sent"`
+	ConnectError string    `json:"connect_error,omitempty"`
+	At           time.Time `json:"at"`
+}
+
+type watchState struct {
+	WatchStatus
+	primed      bool   // first observation recorded; nothing fires until then
+	fingerprint string // astdb callsign/description/location at the last check
+	lastConnect time.Time
+}
+
+// Watchlist periodically compares the link graph and astdb against a set of watched
+// nodes and, when one appears, emits an alert and optionally connects to it. The first
+// check after a target is added only records a baseline, so a restart does not re-alert
+// for nodes that were already online.
+type Watchlist struct {
+	mu         sync.Mutex
+	sm         *StateManager
+	sender     AMICommandSender
+	lookup     func(node int) *NodeInfo
+	poll       func(node int)
+	notify     func(ctx context.Context, evt WatchEvent)
+	connectCmd string // fmt template receiving the local and remote node numbers
+	cooldown   time.Duration
+	targets    map[int]*watchState
+	events     chan WatchEvent
+	now        func() time.Time
+	stopCh     chan struct{}
+	stopOnce   sync.Once
+}
+
+// NewWatchlist builds a watchlist. An empty connectCmd defaults to app_rpt ilink 3
+// (connect in transceive mode); cooldown bounds how often a target is auto-connected.
+func NewWatchlist(sm *StateManager, sender AMICommandSender, connectCmd string, cooldown time.Duration) *Watchlist {
+	if connectCmd == "" {
+		connectCmd = "rpt cmd %d ilink 3 %d"
+	}
+	if cooldown <= 0 {
+		cooldown = 10 * time.Minute
+	}
+	return &Watchlist{
+		sm:         sm,
+		sender:     sender,
+		connectCmd: connectCmd,
+		cooldown:   cooldown,
+		targets:    make(map[int]*watchState),
+		events:     make(chan WatchEvent, 16),
+		now:        time.Now,
+		stopCh:     make(chan struct{}),
+	}
+}
+
+// SetLookup configures the astdb lookup used to detect (re)registration.
+func (w *Watchlist) SetLookup(fn func(node int) *NodeInfo) {
+	w.mu.Lock()
+	w.lookup = fn
+	w.mu.Unlock()
+}
+
+// SetPollTrigger configures an immediate poll of the connecting local node after an
+// automatic connect, so the new link shows up without waiting for the next interval.
+func (w *Watchlist) SetPollTrigger(fn func(node int)) {
+	w.mu.Lock()
+	w.poll = fn
+	w.mu.Unlock()
+}
+
+// SetNotify configures an extra alert action (e.g. a webhook) run for every event.
+func (w *Watchlist) SetNotify(fn func(ctx context.Context, evt WatchEvent)) {
+	w.mu.Lock()
+	w.notify = fn
+	w.mu.Unlock()
+}
+
+// Events returns alerts for broadcasting to admin dashboards.
+func (w *Watchlist) Events() <-chan WatchEvent { return w.events }
+
+// SetTargets replaces the watched nodes. Observations for targets that remain are kept.
+func (w *Watchlist) SetTargets(targets []WatchTarget) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	next := make(map[int]*watchState, len(targets))
+	for _, t := range targets {
+		if t.Node <= 0 {
+			continue
+		}
+		st, ok := w.targets[t.Node]
+		if !ok {
+			st = &watchState{}
+		}
+		st.WatchTarget = t
+		next[t.Node] = st
+	}
+	w.targets = next
+}
+
+// Status returns the watched nodes ordered by node number.
+func (w *Watchlist) Status() []WatchStatus {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	out := make([]WatchStatus, 0, len(w.targets))
+	for _, st := range w.targets {
+		out = append(out, st.WatchStatus)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Node < out[j].Node })
+	return out
+}
+
+// Start runs the periodic check until Stop is called.
+func (w *Watchlist) Start(checkInterval time.Duration) {
+	if checkInterval <= 0 {
+		checkInterval = 30 * time.Second
+	}
+	go func() {
+		ticker := time.NewTicker(checkInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				w.Check()
+			case <-w.stopCh:
+				return
+			}
+		}
+	}()
+}
+
+// Stop terminates the background check loop.
+func (w *Watchlist) Stop() {
+	w.stopOnce.Do(func() { close(w.stopCh) })
+}
+
+// Check compares the current link graph and astdb against the targets once and
+// returns the events it fired.
+func (w *Watchlist) Check() []WatchEvent {
+	snap := w.sm.Snapshot()
+	graph := make(map[int]int, len(snap.Links)+len(snap.LinksDetailed))
+	for _, n := range snap.Links {
+		graph[n] = snap.NodeID
+	}
+	for _, li := range snap.LinksDetailed {
+		local := li.LocalNode
+		if local == 0 {
+			local = snap.NodeID
+		}
+		graph[li.Node] = local
+	}
+
+	type pending struct {
+		evt     WatchEvent
+		target  WatchTarget
+		connect bool
+	}
+	w.mu.Lock()
+	now := w.now()
+	var fired []pending
+	for _, st := range w.targets {
+		local, present := graph[st.Node]
+		var fp string
+		if w.lookup != nil {
+			if info := w.lookup(st.Node); info != nil {
+				st.Callsign, st.Description, st.Location = info.Callsign, info.Description, info.Location
+				fp = info.Callsign + "|" + info.Description + "|" + info.Location
+			}
+		}
+		if present {
+			t := now
+			st.LastSeenAt = &t
+			st.SeenVia = local
+		}
+
+		kind := ""
+		switch {
+		case !st.primed:
+		case present && !st.Present:
+			kind = WatchAppeared
+		case fp != "" && fp != st.fingerprint:
+			kind = WatchAstDBUpdated
+		}
+		st.primed = true
+		st.Present = present
+		st.fingerprint = fp
+		if kind == "" {
+			continue
+		}
+
+		t := now
+		st.LastAlertAt = &t
+		evt := WatchEvent{
+			Kind: kind, Node: st.Node, Label: st.Label, LocalNode: local,
+			Callsign: st.Callsign, Description: st.Description, Location: st.Location, At: now,
+		}
+		connect := st.AutoConnect && now.Sub(st.lastConnect) >= w.cooldown
+		if connect {
+			st.lastConnect = now
+		}
+		fired = append(fired, pending{evt: evt, target: st.WatchTarget, connect: connect})
+	}
+	sender, poll, notify := w.sender, w.poll, w.notify
+	w.mu.Unlock()
+
+	events := make([]WatchEvent, 0, len(fired))
+	for _, f := range fired {
+		evt := f.evt
+		if f.connect {
+			from, err := w.connect(sender, f.target, snap.NodeID)
+			evt.ConnectSent = err == nil
+			if err != nil {
+				evt.ConnectError = err.Error()
+			} else if poll != nil {
+				poll(from)
+			}
+			w.recordConnect(evt.Node, now, err)
+		}
+		log.Printf("[WATCHLIST] %s node=%d label=%q via=%d connect_sent=%v", evt.Kind, evt.Node, evt.Label, evt.LocalNode, evt.ConnectSent)
+		select {
+		case w.events <- evt:
+		default:
+		}
+		if notify != nil {
+			ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+			notify(ctx, evt)
+			cancel()
+		}
+		events = append(events, evt)
+	}
+	return events
+}
+
+// connect issues the connect command for t and returns the local node used.
+func (w *Watchlist) connect(sender AMICommandSender, t WatchTarget, primary int) (int, error) {
+	from := t.ConnectFrom
+	if from == 0 {
+		from = primary
+	}
+	if sender == nil || from <= 0 {
+		return from, fmt.Errorf("no local node to connect from")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if _, err := sender.SendCommand(ctx, fmt.Sprintf(w.connectCmd, from, t.Node)); err != nil {
+		return from, fmt.Errorf("connect %d to %d: %w", from, t.Node, err)
+	}
+	return from, nil
+}
+
+func (w *Watchlist) recordConnect(node int, at time.Time, err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	st, ok := w.targets[node]
+	if !ok {
+		return
+	}
+	st.LastConnectAt = &at
+	st.LastConnectError = ""
+	if err != nil {
+		st.LastConnectError = err.Error()
+	}
+}
@@ -0,0 +1,78 @@
+package core
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWatchlistAlertsAndConnectsOnAppearance(t *testing.T) {
+	sm := NewStateManager()
+	sm.SetNodeID(1999)
+	sender := &fakeCommandSender{}
+	w := NewWatchlist(sm, sender, "", time.Hour)
+	var polled []int
+	w.SetPollTrigger(func(node int) { polled = append(polled, node) })
+	w.SetTargets([]WatchTarget{{Node: 2001, Label: "Portable", AutoConnect: true}, {Node: 2002}})
+
+	// Baseline: a node that is already online does not alert
+	sm.SeedLinkStats([]LinkInfo{{Node: 2002, LocalNode: 1999}})
+	if evts := w.Check(); len(evts) != 0 {
+		t.Fatalf("baseline check fired %+v", evts)
+	}
+
+	sm.SeedLinkStats([]LinkInfo{{Node: 2002, LocalNode: 1999}, {Node: 2001, LocalNode: 1999}})
+	evts := w.Check()
+	if len(evts) != 1 || evts[0].Kind != WatchAppeared || evts[0].Node != 2001 || !evts[0].ConnectSent {
+		t.Fatalf("appearance events = %+v", evts)
+	}
+	if cmds := sender.commands(); len(cmds) != 1 || cmds[0] != "rpt cmd 1999 ilink 3 2001" {
+		t.Fatalf("commands = %v", cmds)
+	}
+	if len(polled) != 1 || polled[0] != 1999 {
+		t.Fatalf("polled = %v, want [1999]", polled)
+	}
+	select {
+	case evt := <-w.Events():
+		if evt.Node != 2001 {
+			t.Fatalf("event channel got %+v", evt)
+		}
+	default:
+		t.Fatal("no event published")
+	}
+
+	// Leaving and re-appearing alerts again, but the cooldown suppresses a second connect
+	sm.SeedLinkStats(nil)
+	w.Check()
+	sm.SeedLinkStats([]LinkInfo{{Node: 2001, LocalNode: 1999}})
+	evts = w.Check()
+	if len(evts) != 1 || evts[0].ConnectSent {
+		t.Fatalf("re-appearance events = %+v", evts)
+	}
+	if n := len(sender.commands()); n != 1 {
+		t.Fatalf("connect sent %d times, want 1 within cooldown", n)
+	}
+
+	for _, st := range w.Status() {
+		if st.Node == 2001 && (!st.Present || st.LastConnectAt == nil || st.LastAlertAt == nil) {
+			t.Fatalf("status = %+v", st)
+		}
+	}
+}
+
+func TestWatchlistAstDBRegistration(t *testing.T) {
+	sm := NewStateManager()
+	w := NewWatchlist(sm, nil, "", 0)
+	info := map[int]*NodeInfo{}
+	w.SetLookup(func(node int) *NodeInfo { return info[node] })
+	w.SetTargets([]WatchTarget{{Node: 3001}})
+
+	w.Check() // baseline: not registered
+	info[3001] = &NodeInfo{Node: 3001, Callsign: "W1AW", Location: "Newington"}
+	evts := w.Check()
+	if len(evts) != 1 || evts[0].Kind != WatchAstDBUpdated || evts[0].Callsign != "W1AW" {
+		t.Fatalf("registration events = %+v", evts)
+	}
+	if evts := w.Check(); len(evts) != 0 {
+		t.Fatalf("unchanged astdb entry fired %+v", evts)
+	}
+}
@@ -0,0 +1,242 @@
+// Package discordbot answers Discord slash commands (/whos-talking, /scoreboard,
+// /profile, /lastheard). It uses Discord's HTTP interactions endpoint rather than a
+// gateway connection: Discord POSTs each command to the dashboard, which verifies the
+// request signature and replies inline.
+package discordbot
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// DefaultAPIBase is Discord's REST API root.
+const DefaultAPIBase = "https://discord.com/api/v10"
+
+// maxContent is Discord's message length limit.
+const maxContent = 2000
+
+// replyTimeout keeps handlers inside Discord's three second response window.
+const replyTimeout = 2500 * time.Millisecond
+
+// Interaction and response types used here (see Discord's interactions documentation).
+const (
+	interactionPing    = 1
+	interactionCommand = 2
+
+	responsePong    = 1
+	responseMessage = 4
+
+	optionString = 3
+)
+
+// Option is a string argument of a command.
+type Option struct {
+	Name        string
+	Description string
+	Required    bool
+}
+
+// Command is one slash command. Handler receives the command's options by name and
+// returns the reply text.
+type Command struct {
+	Name        string
+	Description string
+	Options     []Option
+	Handler     func(ctx context.Context, opts map[string]string) (string, error)
+}
+
+// Bot verifies and answers interactions and registers its commands with Discord.
+type Bot struct {
+	appID     string
+	token     string
+	guildID   string
+	publicKey ed25519.PublicKey
+	commands  []Command
+	APIBase   string // defaults to DefaultAPIBase
+	Client    *http.Client
+}
+
+// New creates a bot. publicKey is the application's hex-encoded public key from the
+// Developer Portal. token is only needed to register commands; with guildID set they
+// are registered for that server (immediately available) instead of globally.
+func New(appID, publicKey, token, guildID string) (*Bot, error) {
+	key, err := hex.DecodeString(strings.TrimSpace(publicKey))
+	if err != nil || len(key) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("discord bot: public_key must be %d hex-encoded bytes", ed25519.PublicKeySize)
+	}
+	return &Bot{appID: appID, token: token, guildID: guildID, publicKey: ed25519.PublicKey(key)}, nil
+}
+
+// Register adds a command. Register every command before SyncCommands.
+func (b *Bot) Register(c Command) {
+	b.commands = append(b.commands, c)
+}
+
+// SyncCommands replaces the application's registered commands with the bot's.
+func (b *Bot) SyncCommands(ctx context.Context) error {
+	if b.token == "" || b.appID == "" {
+		return fmt.Errorf("discord bot: application_id and bot_token are required to register commands")
+	}
+	type option struct {
+		Type        int    `json:"type"`
+		Name        string `json:"name"`
+		Description string `json:"description"`
+		Required    bool   `json:"required,omitempty"`
+	}
+	type command struct {
+		Name        string   `json:"name"`
+		Description string   `json:"description"`
+		Options     []option `json:"options,omitempty"`
+	}
+	defs := make([]command, 0, len(b.commands))
+	for _, c := range b.commands {
+		def := command{Name: c.Name, Description: c.Description}
+		for _, o := range c.Options {
+			def.Options = append(def.Options, option{Type: optionString, Name: o.Name, Description: o.Description, Required: o.Required})
+		}
+		defs = append(defs, def)
+	}
+	body, err := json.Marshal(defs)
+	if err != nil {
+		return err
+	}
+
+	url := fmt.Sprintf("%s/applications/%s/commands", b.apiBase(), b.appID)
+	if b.guildID != "" {
+		url = fmt.Sprintf("%s/applications/%s/guilds/%s/commands", b.apiBase(), b.appID, b.guildID)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bot "+b.token)
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := b.client().Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("discord bot: registering commands: %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
+
+// ServeHTTP handles interactions posted by Discord. Requests without a valid
+// signature are rejected with 401, as Discord requires.
+// Endpoint: POST /api/discord/interactions
+func (b *Bot) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	body, err := io.ReadAll(io.LimitReader(r.Body, 64<<10))
+	if err != nil {
+		http.Error(w, "bad request", http.StatusBadRequest)
+		return
+	}
+	if !b.verify(r.Header.Get("X-Signature-Ed25519"), r.Header.Get("X-Signature-Timestamp"), body) {
+		http.Error(w, "invalid request signature", http.StatusUnauthorized)
+		return
+	}
+
+	var in struct {
+		Type int `json:"type"`
+		Data struct {
+			Name    string `json:"name"`
+			Options []struct {
+				Name  string `json:"name"`
+				Value any    `json:"value"`
+			} `json:"options"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &in); err != nil {
+		http.Error(w, "bad request", http.StatusBadRequest)
+		return
+	}
+
+	switch in.Type {
+	case interactionPing:
+		writeResponse(w, map[string]any{"type": responsePong})
+	case interactionCommand:
+		opts := make(map[string]string, len(in.Data.Options))
+		for _, o := range in.Data.Options {
+			opts[o.Name] = fmt.Sprint(o.Value)
+		}
+		writeResponse(w, message(b.run(r.Context(), in.Data.Name, opts)))
+	default:
+		http.Error(w, "unsupported interaction type", http.StatusBadRequest)
+	}
+}
+
+// run dispatches a command and turns failures into a reply.
+func (b *Bot) run(ctx context.Context, name string, opts map[string]string) string {
+	for _, c := range b.commands {
+		if c.Name != name {
+			continue
+		}
+		ctx, cancel := context.WithTimeout(ctx, replyTimeout)
+		defer cancel()
+		reply, err := c.Handler(ctx, opts)
+		if err != nil {
+			log.Printf("[DISCORD] /%s failed: %v", name, err)
+			return "Sorry, that didn't work. Try again in a moment."
+		}
+		return reply
+	}
+	return "Unknown command /" + name
+}
+
+func (b *Bot) verify(sigHex, timestamp string, body []byte) bool {
+	sig, err := hex.DecodeString(sigHex)
+	if err != nil || len(sig) != ed25519.SignatureSize || timestamp == "" {
+		return false
+	}
+	return ed25519.Verify(b.publicKey, append([]byte(timestamp), body...), sig)
+}
+
+func (b *Bot) apiBase() string {
+	if b.APIBase != "" {
+		return b.APIBase
+	}
+	return DefaultAPIBase
+}
+
+func (b *Bot) client() *http.Client {
+	if b.Client != nil {
+		return b.Client
+	}
+	return &http.Client{Timeout: 10 * time.Second}
+}
+
+// message builds a channel message response. Mentions are disabled so callsigns or
+// node descriptions can never ping anyone.
+func message(content string) map[string]any {
+	if r := []rune(content); len(r) > maxContent {
+		content = string(r[:maxContent-1]) + "…"
+	}
+	return map[string]any{
+		"type": responseMessage,
+		"data": map[string]any{
+			"content":          content,
+			"allowed_mentions": map[string]any{"parse": []string{}},
+		},
+	}
+}
+
+func writeResponse(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("[DISCORD] failed to encode interaction response: %v", err)
+	}
+}
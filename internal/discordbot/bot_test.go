@@ -0,0 +1,189 @@
+package discordbot
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dbehnke/allstar-nexus/backend/gamification"
+	"github.com/dbehnke/allstar-nexus/backend/models"
+	"github.com/dbehnke/allstar-nexus/backend/repository/memrepo"
+	"github.com/dbehnke/allstar-nexus/internal/core"
+	"github.com/dbehnke/allstar-nexus/internal/privacy"
+)
+
+func newTestBot(t *testing.T) (*Bot, ed25519.PrivateKey) {
+	t.Helper()
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bot, err := New("123", hex.EncodeToString(pub), "token", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return bot, priv
+}
+
+func interact(t *testing.T, bot *Bot, priv ed25519.PrivateKey, body string) (int, map[string]any) {
+	t.Helper()
+	ts := "1700000000"
+	req := httptest.NewRequest(http.MethodPost, "/api/discord/interactions", strings.NewReader(body))
+	if priv != nil {
+		req.Header.Set("X-Signature-Ed25519", hex.EncodeToString(ed25519.Sign(priv, []byte(ts+body))))
+	}
+	req.Header.Set("X-Signature-Timestamp", ts)
+	rec := httptest.NewRecorder()
+	bot.ServeHTTP(rec, req)
+	var out map[string]any
+	_ = json.Unmarshal(rec.Body.Bytes(), &out)
+	return rec.Code, out
+}
+
+func content(resp map[string]any) string {
+	data, _ := resp["data"].(map[string]any)
+	s, _ := data["content"].(string)
+	return s
+}
+
+func TestInteractionsVerifySignature(t *testing.T) {
+	bot, priv := newTestBot(t)
+	if code, _ := interact(t, bot, nil, `{"type":1}`); code != http.StatusUnauthorized {
+		t.Fatalf("unsigned request: expected 401, got %d", code)
+	}
+	_, other, _ := ed25519.GenerateKey(nil)
+	if code, _ := interact(t, bot, other, `{"type":1}`); code != http.StatusUnauthorized {
+		t.Fatalf("wrongly signed request: expected 401, got %d", code)
+	}
+	code, resp := interact(t, bot, priv, `{"type":1}`)
+	if code != 200 || resp["type"] != float64(responsePong) {
+		t.Fatalf("ping: code=%d resp=%v", code, resp)
+	}
+}
+
+func TestInteractionsDispatchCommands(t *testing.T) {
+	bot, priv := newTestBot(t)
+	bot.Register(Command{Name: "echo", Handler: func(ctx context.Context, opts map[string]string) (string, error) {
+		return "you said " + opts["text"], nil
+	}})
+
+	_, resp := interact(t, bot, priv, `{"type":2,"data":{"name":"echo","options":[{"name":"text","type":3,"value":"hi"}]}}`)
+	if resp["type"] != float64(responseMessage) || content(resp) != "you said hi" {
+		t.Fatalf("echo: %v", resp)
+	}
+	if _, resp := interact(t, bot, priv, `{"type":2,"data":{"name":"nope"}}`); content(resp) != "Unknown command /nope" {
+		t.Fatalf("unknown command: %v", resp)
+	}
+}
+
+func TestWhosTalkingAndLastHeard(t *testing.T) {
+	bot, priv := newTestBot(t)
+	sm := core.NewStateManager()
+	started := time.Now().Add(-90 * time.Second)
+	heard := time.Now().Add(-5 * time.Minute)
+	sm.SeedLinkStats([]core.LinkInfo{
+		{Node: 2001, NodeCallsign: "W1AW", NodeDescription: "Hub_*One*", CurrentTx: true, LastTxStart: &started, LastHeardAt: &started},
+		{Node: 2002, NodeCallsign: "K8ABC", LastHeardAt: &heard},
+		{Node: 2003},
+	})
+	bot.RegisterDefaults(Sources{State: sm, Privacy: privacy.DefaultPolicy()})
+
+	_, resp := interact(t, bot, priv, `{"type":2,"data":{"name":"whos-talking"}}`)
+	got := content(resp)
+	if !strings.Contains(got, `2001 W1AW (Hub\_\*One\*)`) || strings.Contains(got, "K8ABC") || !strings.Contains(got, "1m 30s") {
+		t.Fatalf("whos-talking: %q", got)
+	}
+
+	_, resp = interact(t, bot, priv, `{"type":2,"data":{"name":"lastheard"}}`)
+	got = content(resp)
+	if strings.Index(got, "W1AW") > strings.Index(got, "K8ABC") || strings.Contains(got, "2003") {
+		t.Fatalf("lastheard should list heard nodes newest first: %q", got)
+	}
+
+	// Anonymous viewers may be limited to node numbers
+	hidden := &Bot{publicKey: bot.publicKey}
+	hidden.RegisterDefaults(Sources{State: sm, Privacy: privacy.Policy{HideAnonCallsigns: true}})
+	if _, resp := interact(t, hidden, priv, `{"type":2,"data":{"name":"whos-talking"}}`); strings.Contains(content(resp), "W1AW") {
+		t.Fatalf("callsign leaked to anonymous viewer: %q", content(resp))
+	}
+}
+
+func TestScoreboardAndProfileCommands(t *testing.T) {
+	bot, priv := newTestBot(t)
+	profiles, txLogs := memrepo.NewProfiles(), memrepo.NewTxLogs()
+	for _, p := range []models.CallsignProfile{
+		{Callsign: "W1AW", Level: 7, ExperiencePoints: 120, RenownLevel: 1},
+		{Callsign: "K8ABC", Level: 9},
+		{Callsign: "N0CALL", Level: 3},
+	} {
+		_ = profiles.Upsert(context.Background(), &p)
+	}
+	start := time.Now().Add(-time.Hour)
+	_ = txLogs.LogTransmission(2000, 3000, "W1AW", start, start.Add(90*time.Second), 90)
+	optOuts := gamification.NewOptOuts(gamification.OptOutHide)
+	optOuts.Set([]string{"N0CALL"})
+	bot.RegisterDefaults(Sources{Profiles: profiles, TxLogs: txLogs, OptOuts: optOuts})
+
+	_, resp := interact(t, bot, priv, `{"type":2,"data":{"name":"scoreboard"}}`)
+	got := content(resp)
+	if !strings.HasPrefix(got, "**Scoreboard**\n1. W1AW — level 7, 120 XP, renown 1\n2. K8ABC") || strings.Contains(got, "N0CALL") {
+		t.Fatalf("scoreboard: %q", got)
+	}
+
+	_, resp = interact(t, bot, priv, `{"type":2,"data":{"name":"profile","options":[{"name":"callsign","value":"w1aw"}]}}`)
+	if got := content(resp); !strings.Contains(got, "**W1AW** — level 7, 120 XP") || !strings.Contains(got, "Talk time: 1m 30s") {
+		t.Fatalf("profile: %q", got)
+	}
+	for _, cs := range []string{"N0CALL", "KD8XYZ"} {
+		_, resp = interact(t, bot, priv, `{"type":2,"data":{"name":"profile","options":[{"name":"callsign","value":"`+cs+`"}]}}`)
+		if got := content(resp); got != "No profile for "+cs+"." {
+			t.Fatalf("profile %s: %q", cs, got)
+		}
+	}
+	if p, _ := profiles.Find(context.Background(), "KD8XYZ"); p != nil {
+		t.Fatal("profile lookup created a profile")
+	}
+}
+
+func TestSyncCommands(t *testing.T) {
+	var gotPath, gotAuth string
+	var gotBody []map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath, gotAuth = r.Method+" "+r.URL.Path, r.Header.Get("Authorization")
+		body, _ := io.ReadAll(r.Body)
+		_ = json.NewDecoder(bytes.NewReader(body)).Decode(&gotBody)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	pub, _, _ := ed25519.GenerateKey(nil)
+	bot, err := New("123", hex.EncodeToString(pub), "secret", "456")
+	if err != nil {
+		t.Fatal(err)
+	}
+	bot.APIBase = srv.URL
+	bot.RegisterDefaults(Sources{State: core.NewStateManager()})
+	if err := bot.SyncCommands(context.Background()); err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+	if gotPath != "PUT /applications/123/guilds/456/commands" || gotAuth != "Bot secret" {
+		t.Fatalf("request: %s auth=%q", gotPath, gotAuth)
+	}
+	if len(gotBody) != 2 || gotBody[0]["name"] != "whos-talking" {
+		t.Fatalf("commands: %v", gotBody)
+	}
+}
+
+func TestNewRejectsBadPublicKey(t *testing.T) {
+	if _, err := New("123", "not-hex", "", ""); err == nil {
+		t.Fatal("expected error for invalid public key")
+	}
+}
@@ -0,0 +1,167 @@
+package discordbot
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/dbehnke/allstar-nexus/backend/gamification"
+	"github.com/dbehnke/allstar-nexus/backend/repository"
+	"github.com/dbehnke/allstar-nexus/internal/core"
+	"github.com/dbehnke/allstar-nexus/internal/privacy"
+	"github.com/dbehnke/allstar-nexus/internal/timefmt"
+)
+
+// listLimit caps the entries in /scoreboard and /lastheard replies.
+const listLimit = 10
+
+// Sources are what the built-in commands answer from. Commands whose sources are
+// nil are not registered (e.g. /scoreboard without gamification).
+type Sources struct {
+	State    *core.StateManager
+	Privacy  privacy.Policy // Discord members are treated as anonymous viewers
+	Profiles repository.ProfileStore
+	Levels   *repository.LevelConfigRepo
+	TxLogs   repository.TxLogStore
+	OptOuts  *gamification.OptOuts
+}
+
+// RegisterDefaults registers /whos-talking, /lastheard, /scoreboard and /profile for
+// the available sources.
+func (b *Bot) RegisterDefaults(src Sources) {
+	if src.State != nil {
+		b.Register(Command{Name: "whos-talking", Description: "Who is transmitting right now", Handler: src.whosTalking})
+		b.Register(Command{Name: "lastheard", Description: "Recently heard nodes", Handler: src.lastHeard})
+	}
+	if src.Profiles != nil {
+		b.Register(Command{Name: "scoreboard", Description: "Top talkers by level", Handler: src.scoreboard})
+		b.Register(Command{
+			Name:        "profile",
+			Description: "Level and talk time for a callsign",
+			Options:     []Option{{Name: "callsign", Description: "Callsign, e.g. KF8S", Required: true}},
+			Handler:     src.profile,
+		})
+	}
+}
+
+func (s Sources) links() []core.LinkInfo {
+	return s.Privacy.Links(s.State.Snapshot().LinksDetailed, privacy.ViewerAnonymous)
+}
+
+func (s Sources) whosTalking(ctx context.Context, _ map[string]string) (string, error) {
+	now := time.Now()
+	var lines []string
+	for _, l := range s.links() {
+		if !l.CurrentTx && !l.IsKeyed {
+			continue
+		}
+		line := "• " + linkLabel(l)
+		if l.LastTxStart != nil {
+			line += fmt.Sprintf(" (%s)", timefmt.Duration(now.Sub(*l.LastTxStart)))
+		}
+		lines = append(lines, line)
+	}
+	if len(lines) == 0 {
+		return "Nobody is talking right now.", nil
+	}
+	return "**Talking now**\n" + strings.Join(lines, "\n"), nil
+}
+
+func (s Sources) lastHeard(ctx context.Context, _ map[string]string) (string, error) {
+	var heard []core.LinkInfo
+	for _, l := range s.links() {
+		if l.LastHeardAt != nil {
+			heard = append(heard, l)
+		}
+	}
+	if len(heard) == 0 {
+		return "No nodes heard yet.", nil
+	}
+	sort.Slice(heard, func(i, j int) bool { return heard[i].LastHeardAt.After(*heard[j].LastHeardAt) })
+	now := time.Now()
+	lines := []string{"**Last heard**"}
+	for _, l := range heard[:min(len(heard), listLimit)] {
+		lines = append(lines, fmt.Sprintf("• %s — %s ago", linkLabel(l), timefmt.Duration(now.Sub(*l.LastHeardAt))))
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+func (s Sources) scoreboard(ctx context.Context, _ map[string]string) (string, error) {
+	profiles, err := s.Profiles.GetLeaderboardExcluding(ctx, listLimit, s.OptOuts.List())
+	if err != nil {
+		return "", err
+	}
+	if len(profiles) == 0 {
+		return "The scoreboard is empty.", nil
+	}
+	lines := []string{"**Scoreboard**"}
+	for i, p := range profiles {
+		line := fmt.Sprintf("%d. %s — level %d, %d XP", i+1, escape(p.Callsign), p.Level, p.ExperiencePoints)
+		if p.RenownLevel > 0 {
+			line += fmt.Sprintf(", renown %d", p.RenownLevel)
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+func (s Sources) profile(ctx context.Context, opts map[string]string) (string, error) {
+	callsign := strings.ToUpper(strings.TrimSpace(opts["callsign"]))
+	if callsign == "" {
+		return "Usage: /profile callsign", nil
+	}
+	notFound := fmt.Sprintf("No profile for %s.", escape(callsign))
+	if s.OptOuts.Has(callsign) {
+		return notFound, nil
+	}
+	p, err := s.Profiles.Find(ctx, callsign)
+	if err != nil {
+		return "", err
+	}
+	if p == nil {
+		return notFound, nil
+	}
+
+	xp := fmt.Sprintf("%d XP", p.ExperiencePoints)
+	if s.Levels != nil {
+		if levels, err := s.Levels.GetAllAsMap(ctx); err == nil {
+			if next, ok := levels[p.Level+1]; ok {
+				xp = fmt.Sprintf("%d / %d XP", p.ExperiencePoints, next)
+			}
+		}
+	}
+	lines := []string{fmt.Sprintf("**%s** — level %d, %s", escape(p.Callsign), p.Level, xp)}
+	if p.RenownLevel > 0 {
+		lines = append(lines, fmt.Sprintf("Renown %d", p.RenownLevel))
+	}
+	if s.TxLogs != nil {
+		if total, err := s.TxLogs.GetTotalTransmissionTime(p.Callsign); err == nil {
+			lines = append(lines, "Talk time: "+timefmt.Seconds(int64(total)))
+		}
+	}
+	if !p.LastTransmissionAt.IsZero() {
+		lines = append(lines, "Last heard: "+timefmt.Duration(time.Since(p.LastTransmissionAt))+" ago")
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+// linkLabel renders a link as "2001 W1AW (Description)" with whatever is known.
+func linkLabel(l core.LinkInfo) string {
+	label := fmt.Sprintf("%d", l.Node)
+	if l.NodeCallsign != "" {
+		label += " " + escape(l.NodeCallsign)
+	}
+	if l.NodeDescription != "" {
+		label += " (" + escape(l.NodeDescription) + ")"
+	}
+	return label
+}
+
+var markdown = strings.NewReplacer(`\`, `\\`, "*", `\*`, "_", `\_`, "~", `\~`, "`", "\\`", "|", `\|`, ">", `\>`)
+
+// escape keeps names and descriptions from being rendered as Discord markdown.
+func escape(s string) string {
+	return markdown.Replace(s)
+}
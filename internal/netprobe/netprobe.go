@@ -0,0 +1,169 @@
+// Package netprobe measures round-trip time to remote AllStar nodes, either with an
+// IAX2 POKE (answered by any Asterisk IAX2 listener, no privileges needed) or an ICMP
+// echo (needs CAP_NET_RAW or root).
+package netprobe
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+// Methods accepted by New.
+const (
+	MethodIAX2 = "iax2"
+	MethodICMP = "icmp"
+)
+
+// DefaultIAXPort is the IAX2 port used when host carries no port.
+const DefaultIAXPort = 4569
+
+// ErrTimeout is returned when no reply arrives before the context deadline.
+var ErrTimeout = errors.New("no reply")
+
+// Probe sends one probe to host and returns the round-trip time.
+type Probe func(ctx context.Context, host string) (time.Duration, error)
+
+// New returns the probe for method.
+func New(method string) (Probe, error) {
+	switch method {
+	case "", MethodIAX2:
+		return IAX2Poke, nil
+	case MethodICMP:
+		return ICMPEcho, nil
+	}
+	return nil, fmt.Errorf("unknown probe method %q (want iax2 or icmp)", method)
+}
+
+// IAX2 full frame fields (RFC 5456).
+const (
+	iaxFrameTypeIAX = 6
+	iaxSubclassPong = 0x03
+	iaxSubclassAck  = 0x04
+	iaxSubclassPoke = 0x1e
+)
+
+var iaxCallNo atomic.Uint32
+
+func iaxFullFrame(src, dst uint16, ts uint32, oseq, iseq, subclass byte) []byte {
+	b := make([]byte, 12)
+	binary.BigEndian.PutUint16(b[0:], 0x8000|src)
+	binary.BigEndian.PutUint16(b[2:], dst)
+	binary.BigEndian.PutUint32(b[4:], ts)
+	b[8], b[9], b[10], b[11] = oseq, iseq, iaxFrameTypeIAX, subclass
+	return b
+}
+
+// IAX2Poke sends an IAX2 POKE to host (default port 4569) and waits for the PONG,
+// acknowledging it as Asterisk's own qualify does.
+func IAX2Poke(ctx context.Context, host string) (time.Duration, error) {
+	addr := host
+	if _, _, err := net.SplitHostPort(host); err != nil {
+		addr = net.JoinHostPort(host, strconv.Itoa(DefaultIAXPort))
+	}
+	conn, err := (&net.Dialer{}).DialContext(ctx, "udp", addr)
+	if err != nil {
+		return 0, err
+	}
+	defer conn.Close()
+	if dl, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(dl)
+	}
+
+	src := uint16(iaxCallNo.Add(1)%0x7ffe) + 1 // call numbers are 1..32767
+	start := time.Now()
+	if _, err := conn.Write(iaxFullFrame(src, 0, 0, 0, 0, iaxSubclassPoke)); err != nil {
+		return 0, err
+	}
+	buf := make([]byte, 1500)
+	for {
+		n, err := conn.Read(buf)
+		if err != nil {
+			return 0, readErr(err)
+		}
+		if n < 12 || buf[0]&0x80 == 0 || buf[10] != iaxFrameTypeIAX || buf[11] != iaxSubclassPong ||
+			binary.BigEndian.Uint16(buf[2:])&0x7fff != src {
+			continue
+		}
+		rtt := time.Since(start)
+		remote := binary.BigEndian.Uint16(buf[0:]) & 0x7fff
+		ts := binary.BigEndian.Uint32(buf[4:])
+		_, _ = conn.Write(iaxFullFrame(src, remote, ts, 1, buf[8]+1, iaxSubclassAck))
+		return rtt, nil
+	}
+}
+
+// ICMPEcho sends one ICMP echo request to host over a raw socket.
+func ICMPEcho(ctx context.Context, host string) (time.Duration, error) {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	ip, err := net.DefaultResolver.LookupIP(ctx, "ip4", host)
+	if err != nil {
+		return 0, err
+	}
+	conn, err := net.ListenPacket("ip4:icmp", "0.0.0.0")
+	if err != nil {
+		return 0, fmt.Errorf("icmp needs CAP_NET_RAW: %w", err)
+	}
+	defer conn.Close()
+	if dl, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(dl)
+	}
+
+	id := uint16(os.Getpid())
+	var seqb [2]byte
+	_, _ = rand.Read(seqb[:])
+	seq := binary.BigEndian.Uint16(seqb[:])
+	msg := []byte{8, 0, 0, 0, 0, 0, 0, 0, 'n', 'e', 'x', 'u', 's'}
+	binary.BigEndian.PutUint16(msg[4:], id)
+	binary.BigEndian.PutUint16(msg[6:], seq)
+	binary.BigEndian.PutUint16(msg[2:], icmpChecksum(msg))
+
+	start := time.Now()
+	if _, err := conn.WriteTo(msg, &net.IPAddr{IP: ip[0]}); err != nil {
+		return 0, err
+	}
+	buf := make([]byte, 1500)
+	for {
+		n, from, err := conn.ReadFrom(buf)
+		if err != nil {
+			return 0, readErr(err)
+		}
+		// Echo reply (type 0) for our id and sequence from the probed address
+		if n < 8 || buf[0] != 0 || !from.(*net.IPAddr).IP.Equal(ip[0]) ||
+			binary.BigEndian.Uint16(buf[4:]) != id || binary.BigEndian.Uint16(buf[6:]) != seq {
+			continue
+		}
+		return time.Since(start), nil
+	}
+}
+
+func icmpChecksum(b []byte) uint16 {
+	var sum uint32
+	for i := 0; i+1 < len(b); i += 2 {
+		sum += uint32(b[i])<<8 | uint32(b[i+1])
+	}
+	if len(b)%2 == 1 {
+		sum += uint32(b[len(b)-1]) << 8
+	}
+	for sum>>16 != 0 {
+		sum = sum&0xffff + sum>>16
+	}
+	return ^uint16(sum)
+}
+
+func readErr(err error) error {
+	var ne net.Error
+	if errors.As(err, &ne) && ne.Timeout() {
+		return ErrTimeout
+	}
+	return err
+}
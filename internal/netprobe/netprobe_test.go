@@ -0,0 +1,90 @@
+package netprobe
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"net"
+	"testing"
+	"time"
+)
+
+// fakeIAX answers POKEs with a PONG from call number 7 and reports the ACK it gets back.
+func fakeIAX(t *testing.T) (addr string, acks <-chan []byte) {
+	t.Helper()
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	ch := make(chan []byte, 1)
+	go func() {
+		buf := make([]byte, 1500)
+		for {
+			n, from, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			if n < 12 {
+				continue
+			}
+			switch buf[11] {
+			case iaxSubclassPoke:
+				src := binary.BigEndian.Uint16(buf[0:]) & 0x7fff
+				_, _ = conn.WriteTo(iaxFullFrame(7, src, 42, 0, 1, iaxSubclassPong), from)
+			case iaxSubclassAck:
+				ch <- append([]byte(nil), buf[:n]...)
+			}
+		}
+	}()
+	return conn.LocalAddr().String(), ch
+}
+
+func TestIAX2Poke(t *testing.T) {
+	addr, acks := fakeIAX(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	rtt, err := IAX2Poke(ctx, addr)
+	if err != nil || rtt <= 0 {
+		t.Fatalf("poke = %v, %v", rtt, err)
+	}
+	select {
+	case ack := <-acks:
+		if binary.BigEndian.Uint16(ack[2:])&0x7fff != 7 || binary.BigEndian.Uint32(ack[4:]) != 42 {
+			t.Fatalf("ack does not answer the pong: % x", ack)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("pong was not acknowledged")
+	}
+}
+
+func TestIAX2PokeTimeout(t *testing.T) {
+	silent, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer silent.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	if _, err := IAX2Poke(ctx, silent.LocalAddr().String()); !errors.Is(err, ErrTimeout) {
+		t.Fatalf("err = %v, want ErrTimeout", err)
+	}
+}
+
+func TestICMPChecksum(t *testing.T) {
+	// Echo request id=1 seq=1 without payload: ^(0x0800 + 0x0001 + 0x0001) = 0xf7fd
+	msg := []byte{8, 0, 0, 0, 0, 1, 0, 1}
+	if got := icmpChecksum(msg); got != 0xf7fd {
+		t.Fatalf("checksum = %#04x", got)
+	}
+	binary.BigEndian.PutUint16(msg[2:], icmpChecksum(msg))
+	if icmpChecksum(msg) != 0 {
+		t.Fatal("checksum of a checksummed message must be zero")
+	}
+}
+
+func TestNewRejectsUnknownMethod(t *testing.T) {
+	if _, err := New("tcp"); err == nil {
+		t.Fatal("unknown method accepted")
+	}
+}
@@ -0,0 +1,154 @@
+// Package notify delivers alerts (idle hub, watchlist, talker activity, clock skew) to
+// a generic JSON webhook and to chat/push services: Discord, Pushover, Telegram bots
+// and ntfy. Each alert rule selects the providers it uses by name.
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Priority of a message, mapped onto each service's own scale.
+type Priority int
+
+const (
+	PriorityLow    Priority = -1 // delivered quietly where supported
+	PriorityNormal Priority = 0
+	PriorityHigh   Priority = 1
+)
+
+// Provider names usable in an alert rule's notify list.
+const (
+	ProviderDiscord  = "discord"
+	ProviderPushover = "pushover"
+	ProviderTelegram = "telegram"
+	ProviderNtfy     = "ntfy"
+)
+
+// Message is one alert. Chat/push providers render Title and Body; the generic webhook
+// posts Payload as JSON (or Title and Body when Payload is nil).
+type Message struct {
+	Title    string
+	Body     string
+	Priority Priority
+	Tags     []string // ntfy tags / emoji shortcodes
+	Payload  any
+}
+
+// Provider sends messages to one service.
+type Provider interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// Dispatcher routes messages to the configured providers.
+type Dispatcher struct {
+	source    string // prefixed to titles so alerts from several hubs can be told apart
+	providers map[string]Provider
+}
+
+// NewDispatcher creates a dispatcher. source is typically the dashboard title.
+func NewDispatcher(source string) *Dispatcher {
+	return &Dispatcher{source: source, providers: make(map[string]Provider)}
+}
+
+// Register makes p available under name.
+func (d *Dispatcher) Register(name string, p Provider) {
+	d.providers[name] = p
+}
+
+// Names returns the registered provider names, sorted.
+func (d *Dispatcher) Names() []string {
+	out := make([]string, 0, len(d.providers))
+	for n := range d.providers {
+		out = append(out, n)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// Send delivers msg to the generic webhook (when webhookURL is set) and to each named
+// provider. All targets are attempted; failures are joined into the returned error.
+func (d *Dispatcher) Send(ctx context.Context, webhookURL string, names []string, msg Message) error {
+	if d.source != "" && msg.Title != "" {
+		msg.Title = d.source + ": " + msg.Title
+	}
+	var errs []error
+	if webhookURL != "" {
+		if err := (&Webhook{URL: webhookURL}).Send(ctx, msg); err != nil {
+			errs = append(errs, fmt.Errorf("webhook: %w", err))
+		}
+	}
+	for _, name := range names {
+		p, ok := d.providers[name]
+		if !ok {
+			errs = append(errs, fmt.Errorf("%s: provider not configured", name))
+			continue
+		}
+		if err := p.Send(ctx, msg); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+var httpClient = &http.Client{Timeout: 15 * time.Second}
+
+// do sends req and treats non-2xx responses as errors, including a snippet of the body.
+func do(req *http.Request) error {
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		snippet, _ := io.ReadAll(io.LimitReader(resp.Body, 256))
+		return fmt.Errorf("status %d: %s", resp.StatusCode, strings.TrimSpace(string(snippet)))
+	}
+	return nil
+}
+
+func postJSON(ctx context.Context, url string, v any) error {
+	body, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return do(req)
+}
+
+// truncate shortens s to at most n bytes on a rune boundary, marking the cut.
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	cut := n - len("…")
+	for cut > 0 && !utf8RuneStart(s[cut]) {
+		cut--
+	}
+	return s[:cut] + "…"
+}
+
+func utf8RuneStart(b byte) bool { return b&0xC0 != 0x80 }
+
+// text joins title and body for services without a separate title field.
+func text(msg Message, bold func(string) string) string {
+	switch {
+	case msg.Title == "":
+		return msg.Body
+	case msg.Body == "":
+		return bold(msg.Title)
+	}
+	return bold(msg.Title) + "\n" + msg.Body
+}
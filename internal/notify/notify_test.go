@@ -0,0 +1,119 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type captured struct {
+	path   string
+	header http.Header
+	body   string
+}
+
+func captureServer(t *testing.T, status int) (*httptest.Server, *[]captured) {
+	t.Helper()
+	var got []captured
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		got = append(got, captured{path: r.URL.Path, header: r.Header.Clone(), body: string(b)})
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &got
+}
+
+func TestDispatcherRoutesToWebhookAndProviders(t *testing.T) {
+	srv, got := captureServer(t, http.StatusOK)
+	d := NewDispatcher("Hub")
+	d.Register(ProviderDiscord, &Discord{WebhookURL: srv.URL + "/discord"})
+	d.Register(ProviderNtfy, &Ntfy{Server: srv.URL, Topic: "alerts", Token: "tk"})
+
+	msg := Message{Title: "Hub idle", Body: "quiet", Priority: PriorityLow, Tags: []string{"zzz"}, Payload: map[string]string{"event": "hub_idle"}}
+	if err := d.Send(context.Background(), srv.URL+"/hook", []string{ProviderNtfy, ProviderDiscord}, msg); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+	if len(*got) != 3 {
+		t.Fatalf("requests = %d, want 3", len(*got))
+	}
+	hook, ntfy, discord := (*got)[0], (*got)[1], (*got)[2]
+	if hook.path != "/hook" || !strings.Contains(hook.body, `"event":"hub_idle"`) {
+		t.Fatalf("webhook got %+v", hook)
+	}
+	if ntfy.path != "/alerts" || ntfy.body != "quiet" || ntfy.header.Get("Title") != "Hub: Hub idle" ||
+		ntfy.header.Get("Priority") != "1" || ntfy.header.Get("Tags") != "zzz" || ntfy.header.Get("Authorization") != "Bearer tk" {
+		t.Fatalf("ntfy got %+v", ntfy)
+	}
+	var dc map[string]string
+	if err := json.Unmarshal([]byte(discord.body), &dc); err != nil || dc["content"] != "**Hub: Hub idle**\nquiet" {
+		t.Fatalf("discord got %q (%v)", discord.body, err)
+	}
+}
+
+func TestDispatcherJoinsErrors(t *testing.T) {
+	srv, got := captureServer(t, http.StatusBadRequest)
+	d := NewDispatcher("")
+	d.Register(ProviderDiscord, &Discord{WebhookURL: srv.URL})
+
+	err := d.Send(context.Background(), "", []string{ProviderDiscord, ProviderTelegram}, Message{Title: "t"})
+	if err == nil || !strings.Contains(err.Error(), "discord: status 400") || !strings.Contains(err.Error(), "telegram: provider not configured") {
+		t.Fatalf("err = %v", err)
+	}
+	if len(*got) != 1 {
+		t.Fatalf("requests = %d, want 1", len(*got))
+	}
+}
+
+func TestPushoverForm(t *testing.T) {
+	srv, got := captureServer(t, http.StatusOK)
+	p := &Pushover{Token: "app", User: "usr", Device: "phone", APIURL: srv.URL}
+	if err := p.Send(context.Background(), Message{Title: "Watched node seen", Body: "W1AW", Priority: PriorityHigh}); err != nil {
+		t.Fatal(err)
+	}
+	req := (*got)[0]
+	if ct := req.header.Get("Content-Type"); ct != "application/x-www-form-urlencoded" {
+		t.Fatalf("content type %q", ct)
+	}
+	for _, want := range []string{"token=app", "user=usr", "device=phone", "priority=1", "title=Watched+node+seen", "message=W1AW"} {
+		if !strings.Contains(req.body, want) {
+			t.Fatalf("form %q missing %q", req.body, want)
+		}
+	}
+}
+
+func TestTelegramEscapesHTML(t *testing.T) {
+	srv, got := captureServer(t, http.StatusOK)
+	tg := &Telegram{BotToken: "123:abc", ChatID: "@club", APIURL: srv.URL}
+	if err := tg.Send(context.Background(), Message{Title: "On air", Body: "K1<X> & co", Priority: PriorityLow}); err != nil {
+		t.Fatal(err)
+	}
+	req := (*got)[0]
+	if req.path != "/bot123:abc/sendMessage" {
+		t.Fatalf("path %q", req.path)
+	}
+	var body struct {
+		ChatID  string `json:"chat_id"`
+		Text    string `json:"text"`
+		Mode    string `json:"parse_mode"`
+		Silence bool   `json:"disable_notification"`
+	}
+	if err := json.Unmarshal([]byte(req.body), &body); err != nil {
+		t.Fatal(err)
+	}
+	if body.ChatID != "@club" || body.Mode != "HTML" || !body.Silence || body.Text != "<b>On air</b>\nK1&lt;X&gt; &amp; co" {
+		t.Fatalf("body %+v", body)
+	}
+}
+
+func TestTruncateKeepsRunes(t *testing.T) {
+	s := strings.Repeat("é", 10) // 20 bytes
+	got := truncate(s, 10)
+	if got != "ééé…" {
+		t.Fatalf("truncate = %q", got)
+	}
+}
@@ -0,0 +1,135 @@
+package notify
+
+import (
+	"context"
+	"html"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Webhook posts messages as JSON to an arbitrary URL.
+type Webhook struct {
+	URL string
+}
+
+// Send posts msg.Payload, or {"title", "body"} when there is no payload.
+func (w *Webhook) Send(ctx context.Context, msg Message) error {
+	if msg.Payload != nil {
+		return postJSON(ctx, w.URL, msg.Payload)
+	}
+	return postJSON(ctx, w.URL, map[string]string{"title": msg.Title, "body": msg.Body})
+}
+
+// Discord posts to a Discord channel webhook.
+type Discord struct {
+	WebhookURL string
+	Username   string // optional override of the webhook's name
+}
+
+func (d *Discord) Send(ctx context.Context, msg Message) error {
+	payload := map[string]any{
+		"content": truncate(text(msg, func(s string) string { return "**" + s + "**" }), 2000),
+	}
+	if d.Username != "" {
+		payload["username"] = d.Username
+	}
+	return postJSON(ctx, d.WebhookURL, payload)
+}
+
+// Pushover sends through the Pushover messages API.
+type Pushover struct {
+	Token  string // application API token
+	User   string // user or group key
+	Device string // optional device name
+	Sound  string // optional sound name
+	APIURL string // defaults to the public API
+}
+
+func (p *Pushover) Send(ctx context.Context, msg Message) error {
+	endpoint := p.APIURL
+	if endpoint == "" {
+		endpoint = "https://api.pushover.net/1/messages.json"
+	}
+	form := url.Values{
+		"token":    {p.Token},
+		"user":     {p.User},
+		"message":  {truncate(msg.Body, 1024)},
+		"priority": {strconv.Itoa(int(msg.Priority))},
+	}
+	if msg.Body == "" {
+		form.Set("message", truncate(msg.Title, 1024))
+	}
+	if msg.Title != "" {
+		form.Set("title", truncate(msg.Title, 250))
+	}
+	if p.Device != "" {
+		form.Set("device", p.Device)
+	}
+	if p.Sound != "" {
+		form.Set("sound", p.Sound)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return do(req)
+}
+
+// Telegram sends through a Telegram bot to a chat, group or channel.
+type Telegram struct {
+	BotToken string
+	ChatID   string // numeric ID or @channelname
+	APIURL   string // defaults to https://api.telegram.org
+}
+
+func (t *Telegram) Send(ctx context.Context, msg Message) error {
+	base := t.APIURL
+	if base == "" {
+		base = "https://api.telegram.org"
+	}
+	escaped := Message{Title: html.EscapeString(msg.Title), Body: html.EscapeString(msg.Body)}
+	payload := map[string]any{
+		"chat_id":              t.ChatID,
+		"text":                 truncate(text(escaped, func(s string) string { return "<b>" + s + "</b>" }), 4096),
+		"parse_mode":           "HTML",
+		"disable_notification": msg.Priority == PriorityLow,
+	}
+	return postJSON(ctx, strings.TrimRight(base, "/")+"/bot"+t.BotToken+"/sendMessage", payload)
+}
+
+// Ntfy publishes to an ntfy topic (ntfy.sh or a self-hosted server).
+type Ntfy struct {
+	Server string // defaults to https://ntfy.sh
+	Topic  string
+	Token  string // optional access token
+}
+
+func (n *Ntfy) Send(ctx context.Context, msg Message) error {
+	server := n.Server
+	if server == "" {
+		server = "https://ntfy.sh"
+	}
+	body := msg.Body
+	if body == "" {
+		body = msg.Title
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimRight(server, "/")+"/"+url.PathEscape(n.Topic), strings.NewReader(body))
+	if err != nil {
+		return err
+	}
+	if msg.Title != "" {
+		req.Header.Set("Title", msg.Title)
+	}
+	// ntfy priorities run 1-5 with 3 as default
+	req.Header.Set("Priority", strconv.Itoa(3+2*int(msg.Priority)))
+	if len(msg.Tags) > 0 {
+		req.Header.Set("Tags", strings.Join(msg.Tags, ","))
+	}
+	if n.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+n.Token)
+	}
+	return do(req)
+}
@@ -0,0 +1,234 @@
+// Package privacy applies the configured masking policy to live node data before it is
+// sent to dashboard viewers, so REST and WebSocket payloads expose the same fields.
+package privacy
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/dbehnke/allstar-nexus/internal/core"
+)
+
+// MaskStyle controls how IP addresses are shown to non-admin viewers.
+type MaskStyle string
+
+const (
+	MaskFull    MaskStyle = "full"    // hide the address entirely
+	MaskPartial MaskStyle = "partial" // keep the network prefix (a.b.*.* for IPv4, the /64 for IPv6)
+	MaskNone    MaskStyle = "none"    // show the address unmodified
+)
+
+// Viewer classifies who a payload is being prepared for.
+type Viewer int
+
+const (
+	ViewerAnonymous Viewer = iota
+	ViewerUser
+	ViewerAdmin
+)
+
+// Viewers lists every viewer class, e.g. for building one payload per class.
+var Viewers = []Viewer{ViewerAnonymous, ViewerUser, ViewerAdmin}
+
+func (v Viewer) String() string {
+	switch v {
+	case ViewerAdmin:
+		return "admin"
+	case ViewerUser:
+		return "user"
+	default:
+		return "anonymous"
+	}
+}
+
+// Policy decides what each viewer class may see. Admins always see everything. The zero
+// value matches the historical behaviour: partial IP masking, everything else visible.
+type Policy struct {
+	IPMask                MaskStyle // applies to all non-admin viewers; empty = partial
+	HideAnonCallsigns     bool      // anonymous viewers see node numbers only
+	HideAnonTalkerHistory bool      // anonymous viewers do not receive the talker log
+}
+
+// DefaultPolicy returns the historical policy with the mask style spelled out.
+func DefaultPolicy() Policy {
+	return Policy{IPMask: MaskPartial}
+}
+
+// ParseMaskStyle validates a configured masking style; empty means partial.
+func ParseMaskStyle(s string) (MaskStyle, error) {
+	switch MaskStyle(strings.ToLower(strings.TrimSpace(s))) {
+	case "", MaskPartial:
+		return MaskPartial, nil
+	case MaskFull:
+		return MaskFull, nil
+	case MaskNone:
+		return MaskNone, nil
+	}
+	return "", fmt.Errorf("unknown ip masking style %q (want full, partial or none)", s)
+}
+
+// ShowCallsigns reports whether v may see callsigns and node descriptions.
+func (p Policy) ShowCallsigns(v Viewer) bool {
+	return v != ViewerAnonymous || !p.HideAnonCallsigns
+}
+
+// ShowTalkerHistory reports whether v may see the talker log.
+func (p Policy) ShowTalkerHistory(v Viewer) bool {
+	return v != ViewerAnonymous || !p.HideAnonTalkerHistory
+}
+
+// MaskIP returns ip as it should be shown to v.
+func (p Policy) MaskIP(ip string, v Viewer) string {
+	if ip == "" || v == ViewerAdmin {
+		return ip
+	}
+	switch p.IPMask {
+	case MaskNone:
+		return ip
+	case MaskFull:
+		return ""
+	}
+	return maskPartial(ip)
+}
+
+func maskPartial(ip string) string {
+	host, port, err := net.SplitHostPort(ip)
+	if err != nil {
+		host, port = strings.Trim(ip, "[]"), ""
+	}
+	parsed := net.ParseIP(host)
+	var masked string
+	switch {
+	case parsed == nil:
+		// Not an address (e.g. a hostname): there is no network prefix to keep
+		return "*"
+	case parsed.To4() != nil:
+		parts := strings.Split(parsed.To4().String(), ".")
+		masked = parts[0] + "." + parts[1] + ".*.*"
+	default:
+		// Keep the /64 network: it identifies the site, not the host
+		prefix := parsed.Mask(net.CIDRMask(64, 128))
+		masked = prefix.String() + "/64"
+		if port != "" {
+			return "[" + masked + "]:" + port
+		}
+		return masked
+	}
+	if port != "" {
+		return masked + ":" + port
+	}
+	return masked
+}
+
+// NodeState returns a copy of st filtered for v. The input is never mutated.
+func (p Policy) NodeState(st core.NodeState, v Viewer) core.NodeState {
+	st.LinksDetailed = p.Links(st.LinksDetailed, v)
+	return st
+}
+
+// Links returns a filtered copy of links for v.
+func (p Policy) Links(links []core.LinkInfo, v Viewer) []core.LinkInfo {
+	if len(links) == 0 || v == ViewerAdmin {
+		return links
+	}
+	out := make([]core.LinkInfo, len(links))
+	copy(out, links)
+	hide := !p.ShowCallsigns(v)
+	for i := range out {
+		out[i].IP = p.MaskIP(out[i].IP, v)
+		out[i].Notes = "" // admin notes; tags stay visible for badges and filters
+		if hide {
+			out[i].NodeCallsign, out[i].NodeDescription, out[i].NodeLocation = "", "", ""
+		}
+	}
+	return out
+}
+
+// Topology returns a filtered copy of t for v.
+func (p Policy) Topology(t core.Topology, v Viewer) core.Topology {
+	if p.ShowCallsigns(v) || len(t.Nodes) == 0 {
+		return t
+	}
+	nodes := make([]core.TopologyNode, len(t.Nodes))
+	copy(nodes, t.Nodes)
+	for i := range nodes {
+		nodes[i].Callsign, nodes[i].Description, nodes[i].Location = "", "", ""
+	}
+	t.Nodes = nodes
+	return t
+}
+
+// KeyingUpdate returns a filtered copy of upd for v.
+func (p Policy) KeyingUpdate(upd core.SourceNodeKeyingUpdate, v Viewer) core.SourceNodeKeyingUpdate {
+	if len(upd.AdjacentNodes) == 0 || v == ViewerAdmin {
+		return upd
+	}
+	hide := !p.ShowCallsigns(v)
+	out := make(map[int]core.AdjacentNodeStatus, len(upd.AdjacentNodes))
+	for k, n := range upd.AdjacentNodes {
+		n.IP = p.MaskIP(n.IP, v)
+		if hide {
+			n.Callsign, n.Description = "", ""
+		}
+		out[k] = n
+	}
+	upd.AdjacentNodes = out
+	return upd
+}
+
+// TalkerEvent filters a single talker event; ok is false when v may not see talker history.
+func (p Policy) TalkerEvent(evt core.TalkerEvent, v Viewer) (core.TalkerEvent, bool) {
+	if !p.ShowTalkerHistory(v) {
+		return core.TalkerEvent{}, false
+	}
+	if !p.ShowCallsigns(v) {
+		evt.Callsign, evt.Description = "", ""
+	}
+	return evt, true
+}
+
+// TalkerLog filters a talker log snapshot (as returned by StateManager.TalkerLogSnapshot)
+// for v. Viewers without talker history access get an empty list.
+func (p Policy) TalkerLog(snapshot any, v Viewer) any {
+	if !p.ShowTalkerHistory(v) {
+		return []core.TalkerEvent{}
+	}
+	events, ok := snapshot.([]core.TalkerEvent)
+	if !ok || p.ShowCallsigns(v) {
+		return snapshot
+	}
+	out := make([]core.TalkerEvent, len(events))
+	for i, evt := range events {
+		out[i], _ = p.TalkerEvent(evt, v)
+	}
+	return out
+}
+
+// Activity filters activity feed entries for v: without talker history only level-ups
+// remain, and level-ups are dropped when callsigns are hidden (they name nothing else).
+func (p Policy) Activity(entries []core.ActivityEntry, v Viewer) []core.ActivityEntry {
+	showHistory, showCalls := p.ShowTalkerHistory(v), p.ShowCallsigns(v)
+	out := make([]core.ActivityEntry, 0, len(entries))
+	for _, e := range entries {
+		if e.Kind == core.ActivityLevelUp {
+			if showCalls {
+				out = append(out, e)
+			}
+			continue
+		}
+		if !showHistory {
+			continue
+		}
+		if !showCalls {
+			ps := make([]core.ActivityParticipant, len(e.Participants))
+			for i, p := range e.Participants {
+				p.Callsign = ""
+				ps[i] = p
+			}
+			e.Participants = ps
+		}
+		out = append(out, e)
+	}
+	return out
+}
@@ -0,0 +1,93 @@
+package privacy
+
+import (
+	"testing"
+
+	"github.com/dbehnke/allstar-nexus/internal/core"
+)
+
+func TestMaskIP(t *testing.T) {
+	cases := []struct {
+		style MaskStyle
+		in    string
+		v     Viewer
+		want  string
+	}{
+		{MaskPartial, "203.0.113.45", ViewerUser, "203.0.*.*"},
+		{MaskPartial, "203.0.113.45:4569", ViewerAnonymous, "203.0.*.*:4569"},
+		{MaskPartial, "2001:db8:1234:5678:9abc::1", ViewerUser, "2001:db8:1234:5678::/64"},
+		{MaskPartial, "[2001:db8:1234:5678::1]:4569", ViewerUser, "[2001:db8:1234:5678::/64]:4569"},
+		{MaskPartial, "[2001:db8::1]", ViewerUser, "2001:db8::/64"},
+		{MaskPartial, "203.0.113.45", ViewerAdmin, "203.0.113.45"},
+		{MaskFull, "203.0.113.45", ViewerUser, ""},
+		{MaskNone, "203.0.113.45", ViewerAnonymous, "203.0.113.45"},
+		{MaskPartial, "", ViewerUser, ""},
+	}
+	for _, tc := range cases {
+		p := Policy{IPMask: tc.style}
+		if got := p.MaskIP(tc.in, tc.v); got != tc.want {
+			t.Errorf("%s MaskIP(%q, %s) = %q, want %q", tc.style, tc.in, tc.v, got, tc.want)
+		}
+	}
+}
+
+func TestParseMaskStyle(t *testing.T) {
+	if s, err := ParseMaskStyle(""); err != nil || s != MaskPartial {
+		t.Fatalf("empty: got %q %v", s, err)
+	}
+	if s, err := ParseMaskStyle("FULL"); err != nil || s != MaskFull {
+		t.Fatalf("FULL: got %q %v", s, err)
+	}
+	if _, err := ParseMaskStyle("half"); err == nil {
+		t.Fatal("expected error for unknown style")
+	}
+}
+
+func TestNodeStateHidesCallsignsFromAnonymous(t *testing.T) {
+	p := Policy{IPMask: MaskPartial, HideAnonCallsigns: true}
+	st := core.NodeState{LinksDetailed: []core.LinkInfo{{Node: 2000, IP: "198.51.100.7", NodeCallsign: "W1AW", NodeDescription: "Newington"}}}
+
+	anon := p.NodeState(st, ViewerAnonymous)
+	if l := anon.LinksDetailed[0]; l.NodeCallsign != "" || l.NodeDescription != "" || l.IP != "198.51.*.*" || l.Node != 2000 {
+		t.Fatalf("anonymous link not filtered: %+v", l)
+	}
+	user := p.NodeState(st, ViewerUser)
+	if l := user.LinksDetailed[0]; l.NodeCallsign != "W1AW" || l.IP != "198.51.*.*" {
+		t.Fatalf("user link wrong: %+v", l)
+	}
+	if st.LinksDetailed[0].IP != "198.51.100.7" || st.LinksDetailed[0].NodeCallsign != "W1AW" {
+		t.Fatal("input state was mutated")
+	}
+}
+
+func TestLinkNotesAdminOnly(t *testing.T) {
+	p := DefaultPolicy()
+	links := []core.LinkInfo{{Node: 2000, Tags: []string{"problem node"}, Notes: "Intermittent audio"}}
+
+	if l := p.Links(links, ViewerUser)[0]; l.Notes != "" || len(l.Tags) != 1 {
+		t.Fatalf("user link: %+v", l)
+	}
+	if l := p.Links(links, ViewerAdmin)[0]; l.Notes != "Intermittent audio" {
+		t.Fatalf("admin link: %+v", l)
+	}
+}
+
+func TestTalkerLogHiddenFromAnonymous(t *testing.T) {
+	events := []core.TalkerEvent{{Kind: "TX_START", Node: 2000, Callsign: "W1AW"}}
+	p := Policy{HideAnonTalkerHistory: true}
+	if got := p.TalkerLog(events, ViewerAnonymous).([]core.TalkerEvent); len(got) != 0 {
+		t.Fatalf("anonymous should get empty talker log, got %v", got)
+	}
+	if got := p.TalkerLog(events, ViewerUser).([]core.TalkerEvent); len(got) != 1 || got[0].Callsign != "W1AW" {
+		t.Fatalf("user talker log wrong: %v", got)
+	}
+
+	p = Policy{HideAnonCallsigns: true}
+	got := p.TalkerLog(events, ViewerAnonymous).([]core.TalkerEvent)
+	if len(got) != 1 || got[0].Callsign != "" || got[0].Node != 2000 {
+		t.Fatalf("anonymous talker log should keep node only: %v", got)
+	}
+	if events[0].Callsign != "W1AW" {
+		t.Fatal("input events were mutated")
+	}
+}
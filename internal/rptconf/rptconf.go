@@ -0,0 +1,365 @@
+// Package rptconf reads and edits a whitelist of per-node telemetry settings (courtesy
+// tones, ID intervals, hang and time-out timers) in app_rpt's rpt.conf. Edits keep the
+// file's comments and layout, leave a backup of the previous version and are written
+// atomically, so common tweaks don't need SSH.
+package rptconf
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ErrNodeNotFound is returned when rpt.conf has no stanza for a node.
+var ErrNodeNotFound = errors.New("node not found in rpt.conf")
+
+// ErrInvalidSetting wraps validation failures from Set.
+var ErrInvalidSetting = errors.New("invalid setting")
+
+// Param is one editable rpt.conf key.
+type Param struct {
+	Key         string `json:"key"`
+	Description string `json:"description"`
+	Kind        string `json:"kind"` // ms, tone or bool
+	Min         int    `json:"min,omitempty"`
+	Max         int    `json:"max,omitempty"`
+}
+
+// Params lists the editable keys.
+var Params = []Param{
+	{Key: "hangtime", Description: "Squelch tail hang time (ms)", Kind: "ms", Max: 60000},
+	{Key: "althangtime", Description: "Alternate hang time (ms)", Kind: "ms", Max: 60000},
+	{Key: "totime", Description: "Time-out timer (ms)", Kind: "ms", Max: 1800000},
+	{Key: "idtime", Description: "ID interval (ms)", Kind: "ms", Min: 60000, Max: 3600000},
+	{Key: "politeid", Description: "Polite ID window before the ID is forced (ms)", Kind: "ms", Max: 600000},
+	{Key: "unlinkedct", Description: "Courtesy tone when no nodes are linked", Kind: "tone"},
+	{Key: "remotect", Description: "Courtesy tone for remote base operation", Kind: "tone"},
+	{Key: "linkunkeyct", Description: "Courtesy tone when a linked node unkeys", Kind: "tone"},
+	{Key: "nounkeyct", Description: "Disable the courtesy tone (1) or enable it (0)", Kind: "bool"},
+}
+
+var toneName = regexp.MustCompile(`^[A-Za-z0-9_]{1,32}$`)
+
+// Lookup returns the editable parameter for key.
+func Lookup(key string) (Param, bool) {
+	for _, p := range Params {
+		if p.Key == key {
+			return p, true
+		}
+	}
+	return Param{}, false
+}
+
+// Validate checks a value for p. Empty values are valid: they remove the key so
+// app_rpt's default (or the template's value) applies.
+func (p Param) Validate(v string) error {
+	if v == "" {
+		return nil
+	}
+	switch p.Kind {
+	case "ms":
+		n, err := strconv.Atoi(v)
+		if err != nil || n < p.Min || n > p.Max {
+			return fmt.Errorf("%w: %s must be a number of milliseconds from %d to %d", ErrInvalidSetting, p.Key, p.Min, p.Max)
+		}
+	case "tone":
+		if !toneName.MatchString(v) {
+			return fmt.Errorf("%w: %s must name a telemetry entry (letters, digits and _)", ErrInvalidSetting, p.Key)
+		}
+	case "bool":
+		if v != "0" && v != "1" {
+			return fmt.Errorf("%w: %s must be 0 or 1", ErrInvalidSetting, p.Key)
+		}
+	}
+	return nil
+}
+
+// Setting is the effective value of an editable key for a node.
+type Setting struct {
+	Param
+	Value     string `json:"value"`               // empty = app_rpt default
+	Inherited string `json:"inherited,omitempty"` // template the value comes from
+}
+
+// File is an rpt.conf on disk. Edits are serialized.
+type File struct {
+	path string
+	mu   sync.Mutex
+}
+
+// New returns a File for path.
+func New(path string) *File { return &File{path: path} }
+
+// Path returns the file's path.
+func (f *File) Path() string { return f.path }
+
+// line is a parsed rpt.conf line.
+type line struct {
+	raw     string
+	section string   // set on section headers
+	parents []string // templates named in a header's parentheses
+	key     string   // set on key = value lines
+	value   string
+}
+
+var headerRe = regexp.MustCompile(`^\s*\[([^\]]+)\]\s*(?:\(([^)]*)\))?`)
+
+func parse(data []byte) []line {
+	var out []line
+	sc := bufio.NewScanner(strings.NewReader(string(data)))
+	sc.Buffer(make([]byte, 64*1024), 1024*1024)
+	for sc.Scan() {
+		l := line{raw: sc.Text()}
+		body, _, _ := strings.Cut(l.raw, ";")
+		if m := headerRe.FindStringSubmatch(body); m != nil {
+			l.section = strings.TrimSpace(m[1])
+			for _, p := range strings.Split(m[2], ",") {
+				if p = strings.TrimSpace(p); p != "" && p != "!" && p != "+" {
+					l.parents = append(l.parents, p)
+				}
+			}
+		} else if k, v, ok := strings.Cut(body, "="); ok {
+			l.key = strings.ToLower(strings.TrimSpace(k))
+			l.value = strings.TrimSpace(strings.TrimPrefix(v, ">"))
+		}
+		out = append(out, l)
+	}
+	return out
+}
+
+// sections maps each section name to its keys (first occurrence wins, as in app_rpt)
+// and parent templates.
+func sections(lines []line) (map[string]map[string]string, map[string][]string) {
+	values := map[string]map[string]string{}
+	parents := map[string][]string{}
+	cur := ""
+	for _, l := range lines {
+		switch {
+		case l.section != "":
+			cur = l.section
+			if values[cur] == nil {
+				values[cur] = map[string]string{}
+			}
+			parents[cur] = append(parents[cur], l.parents...)
+		case l.key != "" && cur != "":
+			if _, seen := values[cur][l.key]; !seen {
+				values[cur][l.key] = l.value
+			}
+		}
+	}
+	return values, parents
+}
+
+func (f *File) read() ([]line, error) {
+	data, err := os.ReadFile(f.path)
+	if err != nil {
+		return nil, err
+	}
+	return parse(data), nil
+}
+
+// Node returns the editable settings of a node, resolving values inherited from
+// templates.
+func (f *File) Node(node int) ([]Setting, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	lines, err := f.read()
+	if err != nil {
+		return nil, err
+	}
+	values, parents := sections(lines)
+	name := strconv.Itoa(node)
+	if _, ok := values[name]; !ok {
+		return nil, fmt.Errorf("%w: [%d]", ErrNodeNotFound, node)
+	}
+	out := make([]Setting, 0, len(Params))
+	for _, p := range Params {
+		s := Setting{Param: p}
+		s.Value, s.Inherited = resolve(values, parents, name, p.Key, 0)
+		if s.Inherited == name {
+			s.Inherited = ""
+		}
+		out = append(out, s)
+	}
+	return out, nil
+}
+
+// resolve finds key in section or, depth first, its templates. It returns the value
+// and the section it was found in.
+func resolve(values map[string]map[string]string, parents map[string][]string, section, key string, depth int) (string, string) {
+	if v, ok := values[section][key]; ok {
+		return v, section
+	}
+	if depth > 8 {
+		return "", ""
+	}
+	for _, p := range parents[section] {
+		if v, from := resolve(values, parents, p, key, depth+1); from != "" {
+			return v, from
+		}
+	}
+	return "", ""
+}
+
+// Tones lists the courtesy tones (ct* entries) defined in the telemetry stanza the node
+// uses, for choosing tone settings.
+func (f *File) Tones(node int) ([]string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	lines, err := f.read()
+	if err != nil {
+		return nil, err
+	}
+	values, parents := sections(lines)
+	stanza, _ := resolve(values, parents, strconv.Itoa(node), "telemetry", 0)
+	if stanza == "" {
+		stanza = "telemetry"
+	}
+	var out []string
+	for k := range values[stanza] {
+		if strings.HasPrefix(k, "ct") {
+			out = append(out, k)
+		}
+	}
+	sort.Strings(out)
+	return out, nil
+}
+
+// Set applies changes to a node's own stanza and returns the previous values of the
+// changed keys. Empty values remove the key. The old file is kept as path.bak.
+func (f *File) Set(node int, changes map[string]string) (map[string]string, error) {
+	keys := make([]string, 0, len(changes))
+	for k, v := range changes {
+		p, ok := Lookup(k)
+		if !ok {
+			return nil, fmt.Errorf("%w: %s is not an editable setting", ErrInvalidSetting, k)
+		}
+		if err := p.Validate(strings.TrimSpace(v)); err != nil {
+			return nil, err
+		}
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	lines, err := f.read()
+	if err != nil {
+		return nil, err
+	}
+	name := strconv.Itoa(node)
+	start, end := -1, len(lines)
+	for i, l := range lines {
+		if l.section == "" {
+			continue
+		}
+		if start >= 0 {
+			end = i
+			break
+		}
+		if l.section == name {
+			start = i
+		}
+	}
+	if start < 0 {
+		return nil, fmt.Errorf("%w: [%d]", ErrNodeNotFound, node)
+	}
+
+	previous := map[string]string{}
+	body := append([]line(nil), lines[start+1:end]...)
+	for _, k := range keys {
+		v := strings.TrimSpace(changes[k])
+		idx := -1
+		for i, l := range body {
+			if l.key == k {
+				idx = i
+				break
+			}
+		}
+		if idx >= 0 {
+			previous[k] = body[idx].value
+			if v == "" {
+				body = append(body[:idx], body[idx+1:]...)
+			} else {
+				body[idx] = line{raw: replaceValue(body[idx].raw, v), key: k, value: v}
+			}
+			continue
+		}
+		previous[k] = ""
+		if v == "" {
+			continue
+		}
+		// Append after the stanza's last setting, ahead of blank lines and comments
+		// that belong to the next section
+		at := len(body)
+		for at > 0 && body[at-1].key == "" {
+			at--
+		}
+		added := line{raw: k + " = " + v + " ; set by Allstar Nexus", key: k, value: v}
+		body = append(body[:at], append([]line{added}, body[at:]...)...)
+	}
+
+	out := append(append(append([]line(nil), lines[:start+1]...), body...), lines[end:]...)
+	if err := f.write(out); err != nil {
+		return nil, err
+	}
+	return previous, nil
+}
+
+// replaceValue swaps the value in a "key = value ; comment" line, keeping the key's
+// spelling, the operator and any comment.
+func replaceValue(raw, value string) string {
+	body, comment, hasComment := strings.Cut(raw, ";")
+	eq := strings.Index(body, "=")
+	op := "="
+	if strings.HasPrefix(body[eq:], "=>") {
+		op = "=>"
+	}
+	out := strings.TrimRight(body[:eq], " \t") + " " + op + " " + value
+	if hasComment {
+		out += " ;" + comment
+	}
+	return out
+}
+
+// write replaces the file atomically, keeping the previous version as path.bak.
+func (f *File) write(lines []line) error {
+	var b strings.Builder
+	for _, l := range lines {
+		b.WriteString(l.raw)
+		b.WriteByte('\n')
+	}
+	mode := os.FileMode(0o644)
+	if st, err := os.Stat(f.path); err == nil {
+		mode = st.Mode().Perm()
+	}
+	if old, err := os.ReadFile(f.path); err == nil {
+		if err := os.WriteFile(f.path+".bak", old, mode); err != nil {
+			return fmt.Errorf("writing backup: %w", err)
+		}
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(f.path), ".rpt.conf-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.WriteString(b.String()); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(mode); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), f.path)
+}
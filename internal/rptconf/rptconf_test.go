@@ -0,0 +1,131 @@
+package rptconf
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const sample = `; rpt.conf
+[node-main](!)
+hangtime = 2000      ; template default
+idtime => 540000
+telemetry = telem
+
+[43732](node-main)
+rxchannel = SimpleUSB/usb_43732
+HANGTIME = 1000 ; tail
+unlinkedct = ct2
+
+; --- second node ---
+[2001]
+rxchannel = dahdi/pseudo
+
+[telem]
+ct1 = |t(350,0,100,2048)
+ct2 = |t(660,880,150,2048)
+remotetx = |t(1633,0,50,3000)
+`
+
+func writeSample(t *testing.T) *File {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "rpt.conf")
+	if err := os.WriteFile(path, []byte(sample), 0o640); err != nil {
+		t.Fatal(err)
+	}
+	return New(path)
+}
+
+func setting(t *testing.T, settings []Setting, key string) Setting {
+	t.Helper()
+	for _, s := range settings {
+		if s.Key == key {
+			return s
+		}
+	}
+	t.Fatalf("no setting %s", key)
+	return Setting{}
+}
+
+func TestNodeResolvesTemplates(t *testing.T) {
+	f := writeSample(t)
+	settings, err := f.Node(43732)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := setting(t, settings, "hangtime"); s.Value != "1000" || s.Inherited != "" {
+		t.Fatalf("hangtime: %+v", s)
+	}
+	if s := setting(t, settings, "idtime"); s.Value != "540000" || s.Inherited != "node-main" {
+		t.Fatalf("idtime: %+v", s)
+	}
+	if s := setting(t, settings, "totime"); s.Value != "" {
+		t.Fatalf("totime: %+v", s)
+	}
+	tones, _ := f.Tones(43732)
+	if strings.Join(tones, ",") != "ct1,ct2" {
+		t.Fatalf("tones: %v", tones)
+	}
+	if _, err := f.Node(9999); !errors.Is(err, ErrNodeNotFound) {
+		t.Fatalf("missing node: %v", err)
+	}
+}
+
+func TestSetKeepsLayout(t *testing.T) {
+	f := writeSample(t)
+	prev, err := f.Set(43732, map[string]string{"hangtime": "1500", "unlinkedct": "", "totime": "180000"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if prev["hangtime"] != "1000" || prev["unlinkedct"] != "ct2" || prev["totime"] != "" {
+		t.Fatalf("previous: %v", prev)
+	}
+	got, _ := os.ReadFile(f.Path())
+	want := strings.Replace(sample, "HANGTIME = 1000 ; tail\nunlinkedct = ct2\n",
+		"HANGTIME = 1500 ; tail\ntotime = 180000 ; set by Allstar Nexus\n", 1)
+	if string(got) != want {
+		t.Fatalf("file:\n%s", got)
+	}
+	if bak, _ := os.ReadFile(f.Path() + ".bak"); string(bak) != sample {
+		t.Fatal("backup should hold the previous file")
+	}
+	if st, _ := os.Stat(f.Path()); st.Mode().Perm() != 0o640 {
+		t.Fatalf("mode: %v", st.Mode())
+	}
+
+	// a node without its own key gets one even when the template has it
+	if _, err := f.Set(2001, map[string]string{"idtime": "600000"}); err != nil {
+		t.Fatal(err)
+	}
+	settings, _ := f.Node(2001)
+	if s := setting(t, settings, "idtime"); s.Value != "600000" {
+		t.Fatalf("idtime: %+v", s)
+	}
+	got, _ = os.ReadFile(f.Path())
+	if !strings.Contains(string(got), "rxchannel = dahdi/pseudo\nidtime = 600000 ; set by Allstar Nexus\n\n[telem]") {
+		t.Fatalf("inserted in the wrong place:\n%s", got)
+	}
+}
+
+func TestSetValidates(t *testing.T) {
+	f := writeSample(t)
+	for _, changes := range []map[string]string{
+		{"rxchannel": "x"},
+		{"idtime": "1000"},
+		{"hangtime": "abc"},
+		{"unlinkedct": "ct1;rm"},
+		{"nounkeyct": "yes"},
+	} {
+		if _, err := f.Set(43732, changes); !errors.Is(err, ErrInvalidSetting) {
+			t.Errorf("%v: expected invalid setting, got %v", changes, err)
+		}
+	}
+	if _, err := f.Set(9999, map[string]string{"hangtime": "1"}); !errors.Is(err, ErrNodeNotFound) {
+		t.Fatalf("missing node: %v", err)
+	}
+	if got, _ := os.ReadFile(f.Path()); string(got) != sample {
+		t.Fatal("rejected changes must not touch the file")
+	}
+}
@@ -0,0 +1,107 @@
+// Package sdnotify implements the small subset of the systemd notification protocol
+// needed for Type=notify services: readiness, stopping and watchdog keepalives.
+// All functions are no-ops when the process was not started by systemd.
+package sdnotify
+
+import (
+	"context"
+	"errors"
+	"net"
+	"os"
+	"strconv"
+	"time"
+)
+
+// Well-known notification states.
+const (
+	Ready    = "READY=1"
+	Stopping = "STOPPING=1"
+	Watchdog = "WATCHDOG=1"
+)
+
+// Notify sends state to the socket named by $NOTIFY_SOCKET. It returns false with a nil
+// error when the variable is unset (not running under systemd or Type!=notify).
+func Notify(state string) (bool, error) {
+	socket := os.Getenv("NOTIFY_SOCKET")
+	if socket == "" {
+		return false, nil
+	}
+	addr := &net.UnixAddr{Name: socket, Net: "unixgram"}
+	if socket[0] == '@' {
+		// Abstract namespace socket
+		addr.Name = "\x00" + socket[1:]
+	}
+	conn, err := net.DialUnix("unixgram", nil, addr)
+	if err != nil {
+		return false, err
+	}
+	defer func() { _ = conn.Close() }()
+	if _, err := conn.Write([]byte(state)); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Status formats a free-form STATUS= line shown by `systemctl status`.
+func Status(msg string) string { return "STATUS=" + msg }
+
+// WatchdogInterval returns the watchdog timeout systemd expects keepalives within
+// ($WATCHDOG_USEC), or 0 when the watchdog is disabled or targeted at another process.
+func WatchdogInterval() (time.Duration, error) {
+	usec := os.Getenv("WATCHDOG_USEC")
+	if usec == "" {
+		return 0, nil
+	}
+	n, err := strconv.ParseInt(usec, 10, 64)
+	if err != nil || n <= 0 {
+		return 0, errors.New("invalid WATCHDOG_USEC " + strconv.Quote(usec))
+	}
+	if pid := os.Getenv("WATCHDOG_PID"); pid != "" {
+		p, err := strconv.Atoi(pid)
+		if err != nil {
+			return 0, errors.New("invalid WATCHDOG_PID " + strconv.Quote(pid))
+		}
+		if p != os.Getpid() {
+			return 0, nil
+		}
+	}
+	return time.Duration(n) * time.Microsecond, nil
+}
+
+// HealthCheck reports whether one internal subsystem is healthy.
+type HealthCheck func(ctx context.Context) error
+
+// RunWatchdog sends WATCHDOG=1 every interval/2 for as long as all checks pass. When a
+// check fails the keepalive is withheld so systemd restarts the service once the
+// watchdog timeout elapses. onFail (optional) is called with each failure. It returns
+// when ctx is cancelled.
+func RunWatchdog(ctx context.Context, interval time.Duration, checks map[string]HealthCheck, onFail func(name string, err error)) {
+	period := interval / 2
+	if period <= 0 {
+		return
+	}
+	ticker := time.NewTicker(period)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		healthy := true
+		for name, check := range checks {
+			cctx, cancel := context.WithTimeout(ctx, period)
+			err := check(cctx)
+			cancel()
+			if err != nil {
+				healthy = false
+				if onFail != nil {
+					onFail(name, err)
+				}
+			}
+		}
+		if healthy {
+			_, _ = Notify(Watchdog)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package sdnotify
+
+import (
+	"context"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func listen(t *testing.T) *net.UnixConn {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "notify.sock")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: path, Net: "unixgram"})
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	t.Setenv("NOTIFY_SOCKET", path)
+	return conn
+}
+
+func read(t *testing.T, conn *net.UnixConn, timeout time.Duration) (string, bool) {
+	t.Helper()
+	buf := make([]byte, 256)
+	_ = conn.SetReadDeadline(time.Now().Add(timeout))
+	n, err := conn.Read(buf)
+	if err != nil {
+		return "", false
+	}
+	return string(buf[:n]), true
+}
+
+func TestNotifyWithoutSocket(t *testing.T) {
+	t.Setenv("NOTIFY_SOCKET", "")
+	sent, err := Notify(Ready)
+	if sent || err != nil {
+		t.Fatalf("expected no-op, got sent=%v err=%v", sent, err)
+	}
+}
+
+func TestNotifySendsState(t *testing.T) {
+	conn := listen(t)
+	sent, err := Notify(Ready)
+	if !sent || err != nil {
+		t.Fatalf("notify: sent=%v err=%v", sent, err)
+	}
+	if got, ok := read(t, conn, time.Second); !ok || got != Ready {
+		t.Fatalf("got %q, want %q", got, Ready)
+	}
+}
+
+func TestWatchdogInterval(t *testing.T) {
+	t.Setenv("WATCHDOG_USEC", "")
+	if d, err := WatchdogInterval(); d != 0 || err != nil {
+		t.Fatalf("unset: got %v %v", d, err)
+	}
+	t.Setenv("WATCHDOG_USEC", "30000000")
+	t.Setenv("WATCHDOG_PID", strconv.Itoa(os.Getpid()))
+	if d, _ := WatchdogInterval(); d != 30*time.Second {
+		t.Fatalf("got %v, want 30s", d)
+	}
+	t.Setenv("WATCHDOG_PID", "1")
+	if d, _ := WatchdogInterval(); d != 0 && os.Getpid() != 1 {
+		t.Fatalf("other pid: got %v, want 0", d)
+	}
+	t.Setenv("WATCHDOG_USEC", "bogus")
+	if _, err := WatchdogInterval(); err == nil {
+		t.Fatal("expected error for invalid WATCHDOG_USEC")
+	}
+}
+
+func TestRunWatchdogWithholdsKeepaliveWhenUnhealthy(t *testing.T) {
+	conn := listen(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	healthy := make(chan bool, 1)
+	healthy <- false
+	failures := make(chan string, 10)
+	checks := map[string]HealthCheck{
+		"ami": func(context.Context) error {
+			select {
+			case ok := <-healthy:
+				if !ok {
+					return errors.New("wedged")
+				}
+			default:
+			}
+			return nil
+		},
+	}
+	go RunWatchdog(ctx, 40*time.Millisecond, checks, func(name string, err error) { failures <- name })
+
+	select {
+	case name := <-failures:
+		if name != "ami" {
+			t.Fatalf("unexpected failing check %q", name)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected failure callback")
+	}
+	// Subsequent ticks are healthy and must produce keepalives.
+	if got, ok := read(t, conn, time.Second); !ok || got != Watchdog {
+		t.Fatalf("got %q ok=%v, want %q", got, ok, Watchdog)
+	}
+}
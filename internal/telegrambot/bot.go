@@ -0,0 +1,335 @@
+// Package telegrambot runs a Telegram bot that answers "who's on?", posts activity
+// alerts to a group chat and lets linked admins poll, connect and disconnect nodes.
+// It long-polls Telegram's getUpdates, so the dashboard needs no public URL.
+package telegrambot
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultAPIBase is Telegram's Bot API root.
+const DefaultAPIBase = "https://api.telegram.org"
+
+// maxMessage is Telegram's message length limit.
+const maxMessage = 4096
+
+// pollTimeout is how long each getUpdates call waits for new messages.
+const pollTimeout = 30 * time.Second
+
+// commandTimeout bounds a single command handler.
+const commandTimeout = 15 * time.Second
+
+// LinkCodeTTL is how long a code from NewLinkCode can be redeemed with /link.
+const LinkCodeTTL = 10 * time.Minute
+
+// Request is a command sent to the bot.
+type Request struct {
+	ChatID  int64
+	UserID  int64 // Telegram user who sent the command
+	Private bool  // sent in a one-to-one chat with the bot
+	Args    []string
+}
+
+// Command is one bot command. Handler returns the reply text.
+type Command struct {
+	Name        string
+	Description string
+	Handler     func(ctx context.Context, req Request) (string, error)
+}
+
+type linkCode struct {
+	userID  int64
+	expires time.Time
+}
+
+// Bot receives commands by long polling and replies in the chat they came from.
+type Bot struct {
+	token     string
+	alertChat string
+	APIBase   string // defaults to DefaultAPIBase
+	Client    *http.Client
+
+	mu       sync.Mutex
+	commands []Command
+	codes    map[string]linkCode
+	offset   int64
+	now      func() time.Time
+
+	stopCh   chan struct{}
+	stopOnce sync.Once
+}
+
+// New creates a bot. alertChatID (a numeric chat ID or @channelname) receives Alert
+// messages; leave it empty to disable alerts.
+func New(token, alertChatID string) *Bot {
+	return &Bot{
+		token:     token,
+		alertChat: alertChatID,
+		codes:     make(map[string]linkCode),
+		now:       time.Now,
+		stopCh:    make(chan struct{}),
+	}
+}
+
+// Register adds a command. Register every command before Start.
+func (b *Bot) Register(c Command) {
+	b.commands = append(b.commands, c)
+}
+
+// Start publishes the command menu and polls for updates until Stop is called.
+func (b *Bot) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-b.stopCh
+		cancel()
+	}()
+	go func() {
+		if err := b.setMyCommands(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("[TELEGRAM] registering command menu failed: %v", err)
+		}
+		for ctx.Err() == nil {
+			if err := b.pollOnce(ctx); err != nil && ctx.Err() == nil {
+				log.Printf("[TELEGRAM] getUpdates failed: %v", err)
+				select {
+				case <-time.After(5 * time.Second):
+				case <-ctx.Done():
+				}
+			}
+		}
+	}()
+}
+
+// Stop terminates the polling loop.
+func (b *Bot) Stop() {
+	b.stopOnce.Do(func() { close(b.stopCh) })
+}
+
+// Alert posts text to the alert chat. It is a no-op when no alert chat is configured.
+func (b *Bot) Alert(ctx context.Context, text string) error {
+	if b.alertChat == "" {
+		return nil
+	}
+	return b.send(ctx, b.alertChat, text)
+}
+
+// NewLinkCode issues a one-time code that links the Telegram account redeeming it
+// with /link to the given dashboard user.
+func (b *Bot) NewLinkCode(userID int64) (string, time.Time) {
+	buf := make([]byte, 5)
+	_, _ = rand.Read(buf)
+	code := base32.StdEncoding.EncodeToString(buf)
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	now := b.now()
+	for c, lc := range b.codes {
+		if lc.userID == userID || now.After(lc.expires) {
+			delete(b.codes, c)
+		}
+	}
+	expires := now.Add(LinkCodeTTL)
+	b.codes[code] = linkCode{userID: userID, expires: expires}
+	return code, expires
+}
+
+// redeemLinkCode consumes a code, returning the user it was issued for.
+func (b *Bot) redeemLinkCode(code string) (int64, bool) {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	lc, ok := b.codes[code]
+	delete(b.codes, code)
+	if !ok || b.now().After(lc.expires) {
+		return 0, false
+	}
+	return lc.userID, true
+}
+
+type update struct {
+	UpdateID int64    `json:"update_id"`
+	Message  *message `json:"message"`
+}
+
+type user struct {
+	ID int64 `json:"id"`
+}
+
+type message struct {
+	From *user `json:"from"`
+	Chat struct {
+		ID   int64  `json:"id"`
+		Type string `json:"type"`
+	} `json:"chat"`
+	Text string `json:"text"`
+}
+
+func (b *Bot) pollOnce(ctx context.Context) error {
+	b.mu.Lock()
+	q := url.Values{
+		"offset":          {strconv.FormatInt(b.offset, 10)},
+		"timeout":         {strconv.Itoa(int(pollTimeout.Seconds()))},
+		"allowed_updates": {`["message"]`},
+	}
+	b.mu.Unlock()
+	var updates []update
+	if err := b.call(ctx, "getUpdates?"+q.Encode(), nil, &updates); err != nil {
+		return err
+	}
+	for _, u := range updates {
+		b.mu.Lock()
+		b.offset = max(b.offset, u.UpdateID+1)
+		b.mu.Unlock()
+		if reply, chatID, ok := b.handle(ctx, u); ok {
+			if err := b.send(ctx, strconv.FormatInt(chatID, 10), reply); err != nil {
+				log.Printf("[TELEGRAM] reply failed: %v", err)
+			}
+		}
+	}
+	return nil
+}
+
+// handle runs the command in an update; ok is false when there is nothing to reply to.
+func (b *Bot) handle(ctx context.Context, u update) (reply string, chatID int64, ok bool) {
+	m := u.Message
+	if m == nil || m.From == nil {
+		return "", 0, false
+	}
+	name, args := parseCommand(m.Text)
+	if name == "" {
+		return "", 0, false
+	}
+	req := Request{ChatID: m.Chat.ID, UserID: m.From.ID, Private: m.Chat.Type == "private", Args: args}
+	for _, c := range b.commands {
+		if c.Name != name {
+			continue
+		}
+		ctx, cancel := context.WithTimeout(ctx, commandTimeout)
+		defer cancel()
+		reply, err := c.Handler(ctx, req)
+		if err != nil {
+			log.Printf("[TELEGRAM] /%s failed: %v", name, err)
+			reply = "Sorry, that didn't work. Try again in a moment."
+		}
+		return reply, m.Chat.ID, reply != ""
+	}
+	if req.Private {
+		return "Unknown command /" + name + ". Try /help.", m.Chat.ID, true
+	}
+	// Stay quiet in groups, where other bots' commands are common
+	return "", 0, false
+}
+
+// parseCommand splits "/connect@NexusBot 2001" into "connect" and its arguments. A
+// plain "who's on?" is treated as /whoson.
+func parseCommand(text string) (string, []string) {
+	text = strings.TrimSpace(text)
+	switch strings.ToLower(strings.TrimRight(text, "?! ")) {
+	case "who's on", "whos on", "who is on":
+		return "whoson", nil
+	}
+	if !strings.HasPrefix(text, "/") {
+		return "", nil
+	}
+	fields := strings.Fields(text[1:])
+	if len(fields) == 0 {
+		return "", nil
+	}
+	name, _, _ := strings.Cut(fields[0], "@")
+	return strings.ToLower(name), fields[1:]
+}
+
+func (b *Bot) setMyCommands(ctx context.Context) error {
+	type command struct {
+		Command     string `json:"command"`
+		Description string `json:"description"`
+	}
+	cmds := make([]command, 0, len(b.commands))
+	for _, c := range b.commands {
+		if c.Description != "" {
+			cmds = append(cmds, command{c.Name, c.Description})
+		}
+	}
+	return b.call(ctx, "setMyCommands", map[string]any{"commands": cmds}, nil)
+}
+
+func (b *Bot) send(ctx context.Context, chatID, text string) error {
+	if r := []rune(text); len(r) > maxMessage {
+		text = string(r[:maxMessage-1]) + "…"
+	}
+	return b.call(ctx, "sendMessage", map[string]any{
+		"chat_id":                  chatID,
+		"text":                     text,
+		"disable_web_page_preview": true,
+	}, nil)
+}
+
+// call invokes a Bot API method (POST with a JSON body, or GET when body is nil) and
+// decodes its result into out.
+func (b *Bot) call(ctx context.Context, method string, body any, out any) error {
+	endpoint := strings.TrimRight(b.apiBase(), "/") + "/bot" + b.token + "/" + method
+	var req *http.Request
+	var err error
+	if body == nil {
+		req, err = http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	} else {
+		var buf []byte
+		if buf, err = json.Marshal(body); err != nil {
+			return err
+		}
+		req, err = http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(buf))
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if err != nil {
+		return err
+	}
+	resp, err := b.client().Do(req)
+	if err != nil {
+		// The URL carries the bot token; keep it out of logs
+		if ue, ok := err.(*url.Error); ok {
+			err = ue.Err
+		}
+		return fmt.Errorf("%s: %w", strings.SplitN(method, "?", 2)[0], err)
+	}
+	defer resp.Body.Close()
+	var env struct {
+		OK          bool            `json:"ok"`
+		Description string          `json:"description"`
+		Result      json.RawMessage `json:"result"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&env); err != nil {
+		return fmt.Errorf("%s: %s", method, resp.Status)
+	}
+	if !env.OK {
+		return fmt.Errorf("%s: %s", strings.SplitN(method, "?", 2)[0], env.Description)
+	}
+	if out != nil {
+		return json.Unmarshal(env.Result, out)
+	}
+	return nil
+}
+
+func (b *Bot) apiBase() string {
+	if b.APIBase != "" {
+		return b.APIBase
+	}
+	return DefaultAPIBase
+}
+
+func (b *Bot) client() *http.Client {
+	if b.Client != nil {
+		return b.Client
+	}
+	return &http.Client{Timeout: pollTimeout + 10*time.Second}
+}
@@ -0,0 +1,132 @@
+package telegrambot
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dbehnke/allstar-nexus/internal/core"
+	"github.com/dbehnke/allstar-nexus/internal/privacy"
+)
+
+func TestParseCommand(t *testing.T) {
+	cases := []struct {
+		text, name string
+		args       int
+	}{
+		{"/connect@NexusBot 2001 43732", "connect", 2},
+		{"/WhosOn", "whoson", 0},
+		{"Who's on?", "whoson", 0},
+		{"hello there", "", 0},
+		{"/", "", 0},
+	}
+	for _, c := range cases {
+		name, args := parseCommand(c.text)
+		if name != c.name || len(args) != c.args {
+			t.Errorf("%q: got %q %v", c.text, name, args)
+		}
+	}
+}
+
+// fakeTelegram serves one batch of updates and records sent messages.
+type fakeTelegram struct {
+	mu      sync.Mutex
+	updates string
+	sent    []map[string]any
+}
+
+func (f *fakeTelegram) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	switch {
+	case strings.HasSuffix(r.URL.Path, "/getUpdates"):
+		result := f.updates
+		f.updates = "[]"
+		_, _ = w.Write([]byte(`{"ok":true,"result":` + result + `}`))
+	case strings.HasSuffix(r.URL.Path, "/sendMessage"):
+		var msg map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&msg)
+		f.sent = append(f.sent, msg)
+		_, _ = w.Write([]byte(`{"ok":true,"result":{}}`))
+	default:
+		_, _ = w.Write([]byte(`{"ok":false,"description":"Not Found"}`))
+	}
+}
+
+func TestPollRepliesToCommands(t *testing.T) {
+	fake := &fakeTelegram{updates: `[
+		{"update_id":7,"message":{"from":{"id":1},"chat":{"id":-100,"type":"group"},"text":"/whoson@NexusBot"}},
+		{"update_id":8,"message":{"from":{"id":1},"chat":{"id":-100,"type":"group"},"text":"/otherbot"}},
+		{"update_id":9,"message":{"from":{"id":1},"chat":{"id":5,"type":"private"},"text":"/nope"}}
+	]`}
+	srv := httptest.NewServer(fake)
+	defer srv.Close()
+
+	sm := core.NewStateManager()
+	started := time.Now().Add(-90 * time.Second)
+	sm.SeedLinkStats([]core.LinkInfo{
+		{Node: 2002, NodeCallsign: "K8ABC"},
+		{Node: 2001, NodeCallsign: "W1AW", CurrentTx: true, LastTxStart: &started},
+	})
+	bot := New("TOKEN", "")
+	bot.APIBase = srv.URL
+	bot.RegisterDefaults(Sources{State: sm, Privacy: privacy.DefaultPolicy()})
+	if err := bot.pollOnce(context.Background()); err != nil {
+		t.Fatalf("poll: %v", err)
+	}
+	if bot.offset != 10 {
+		t.Fatalf("expected offset 10, got %d", bot.offset)
+	}
+	if len(fake.sent) != 2 {
+		// the unknown command in the group is ignored
+		t.Fatalf("expected 2 replies, got %v", fake.sent)
+	}
+	whos := fake.sent[0]["text"].(string)
+	if fake.sent[0]["chat_id"] != "-100" || strings.Index(whos, "2001 W1AW — talking (1m 30s)") > strings.Index(whos, "2002 K8ABC") {
+		t.Fatalf("whoson reply: %v", fake.sent[0])
+	}
+	if fake.sent[1]["text"] != "Unknown command /nope. Try /help." {
+		t.Fatalf("private unknown command: %v", fake.sent[1])
+	}
+}
+
+func TestWhosOnHidesCallsignsFromAnonymousViewers(t *testing.T) {
+	sm := core.NewStateManager()
+	sm.SeedLinkStats([]core.LinkInfo{{Node: 2001, NodeCallsign: "W1AW"}})
+	got, _ := Sources{State: sm, Privacy: privacy.Policy{HideAnonCallsigns: true}}.whosOn(context.Background(), Request{})
+	if strings.Contains(got, "W1AW") || !strings.Contains(got, "2001") {
+		t.Fatalf("whoson: %q", got)
+	}
+}
+
+func TestLinkCodes(t *testing.T) {
+	bot := New("TOKEN", "")
+	now := time.Now()
+	bot.now = func() time.Time { return now }
+
+	old, _ := bot.NewLinkCode(1)
+	code, expires := bot.NewLinkCode(1)
+	if !expires.Equal(now.Add(LinkCodeTTL)) {
+		t.Fatalf("expires: %v", expires)
+	}
+	if _, ok := bot.redeemLinkCode(old); ok {
+		t.Fatal("a new code should replace the user's previous one")
+	}
+	if id, ok := bot.redeemLinkCode(strings.ToLower(code)); !ok || id != 1 {
+		t.Fatalf("redeem: %d %v", id, ok)
+	}
+	if _, ok := bot.redeemLinkCode(code); ok {
+		t.Fatal("codes are single use")
+	}
+
+	expired, _ := bot.NewLinkCode(2)
+	now = now.Add(LinkCodeTTL + time.Second)
+	if _, ok := bot.redeemLinkCode(expired); ok {
+		t.Fatal("expired code accepted")
+	}
+}
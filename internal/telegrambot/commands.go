@@ -0,0 +1,238 @@
+package telegrambot
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dbehnke/allstar-nexus/backend/models"
+	"github.com/dbehnke/allstar-nexus/backend/repository"
+	"github.com/dbehnke/allstar-nexus/internal/core"
+	"github.com/dbehnke/allstar-nexus/internal/privacy"
+	"github.com/dbehnke/allstar-nexus/internal/timefmt"
+)
+
+// AuditActionNodeControl is the audit log action for connects and disconnects made
+// through the bot.
+const AuditActionNodeControl = "telegram_node_control"
+
+// Sources are what the built-in commands answer from and act on. Commands whose
+// sources are nil are not registered.
+type Sources struct {
+	State   *core.StateManager
+	Privacy privacy.Policy // chat members are treated as anonymous viewers
+	Users   repository.UserStore
+	Audit   *repository.AuditRepo
+	Sender  core.AMICommandSender // nil without an AMI connection
+	Poll    func(node int)        // 0 polls every node
+	Nodes   []int                 // local nodes; the first is the default for /connect and /disconnect
+
+	ConnectCommand    string // fmt template receiving the local and remote node numbers
+	DisconnectCommand string
+}
+
+// RegisterDefaults registers /help, /whoson and, with a user repository, /link,
+// /unlink, /poll, /connect and /disconnect.
+func (b *Bot) RegisterDefaults(src Sources) {
+	b.Register(Command{Name: "start", Handler: b.help})
+	b.Register(Command{Name: "help", Description: "List commands", Handler: b.help})
+	if src.State != nil {
+		b.Register(Command{Name: "whoson", Description: "Connected nodes and who is talking", Handler: src.whosOn})
+	}
+	if src.Users == nil {
+		return
+	}
+	b.Register(Command{Name: "link", Description: "Link your dashboard admin account: /link CODE", Handler: func(ctx context.Context, req Request) (string, error) {
+		return src.link(ctx, b, req)
+	}})
+	b.Register(Command{Name: "unlink", Description: "Unlink your dashboard account", Handler: src.unlink})
+	b.Register(Command{Name: "poll", Description: "Poll nodes now: /poll [node]", Handler: src.poll})
+	b.Register(Command{Name: "connect", Description: "Connect a node: /connect REMOTE [LOCAL]", Handler: func(ctx context.Context, req Request) (string, error) {
+		return src.control(ctx, req, "connect", src.ConnectCommand)
+	}})
+	b.Register(Command{Name: "disconnect", Description: "Disconnect a node: /disconnect REMOTE [LOCAL]", Handler: func(ctx context.Context, req Request) (string, error) {
+		return src.control(ctx, req, "disconnect", src.DisconnectCommand)
+	}})
+}
+
+func (b *Bot) help(ctx context.Context, _ Request) (string, error) {
+	lines := []string{"Commands:"}
+	for _, c := range b.commands {
+		if c.Description != "" {
+			lines = append(lines, fmt.Sprintf("/%s — %s", c.Name, c.Description))
+		}
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+func (s Sources) whosOn(ctx context.Context, _ Request) (string, error) {
+	links := s.Privacy.Links(s.State.Snapshot().LinksDetailed, privacy.ViewerAnonymous)
+	if len(links) == 0 {
+		return "No nodes are connected.", nil
+	}
+	sort.Slice(links, func(i, j int) bool { return links[i].Node < links[j].Node })
+	now := time.Now()
+	lines := []string{fmt.Sprintf("%d connected:", len(links))}
+	for _, l := range links {
+		line := "• " + linkLabel(l)
+		if (l.CurrentTx || l.IsKeyed) && l.LastTxStart != nil {
+			line += fmt.Sprintf(" — talking (%s)", timefmt.Duration(now.Sub(*l.LastTxStart)))
+		} else if l.CurrentTx || l.IsKeyed {
+			line += " — talking"
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+func (s Sources) link(ctx context.Context, b *Bot, req Request) (string, error) {
+	if !req.Private {
+		return "Send /link to me in a private chat.", nil
+	}
+	if len(req.Args) != 1 {
+		return "Usage: /link CODE (get a code from your dashboard profile).", nil
+	}
+	userID, ok := b.redeemLinkCode(req.Args[0])
+	if !ok {
+		return "That code is invalid or has expired.", nil
+	}
+	telegramID := req.UserID
+	if err := s.Users.SetTelegramID(ctx, userID, &telegramID); err != nil {
+		return "", err
+	}
+	return "Linked. Admin commands are now available to you.", nil
+}
+
+func (s Sources) unlink(ctx context.Context, req Request) (string, error) {
+	u, err := s.Users.GetByTelegramID(ctx, req.UserID)
+	if err != nil {
+		return "", err
+	}
+	if u == nil {
+		return "Your Telegram account is not linked.", nil
+	}
+	if err := s.Users.SetTelegramID(ctx, u.ID, nil); err != nil {
+		return "", err
+	}
+	return "Unlinked.", nil
+}
+
+// admin returns the linked dashboard admin sending req, or the reply explaining why
+// the command is refused. The role is checked on every command so demotions apply
+// immediately.
+func (s Sources) admin(ctx context.Context, req Request) (*models.User, string, error) {
+	u, err := s.Users.GetByTelegramID(ctx, req.UserID)
+	if err != nil {
+		return nil, "", err
+	}
+	if u == nil {
+		return nil, "Link your dashboard admin account first: /link CODE", nil
+	}
+	if u.Role != models.RoleAdmin && u.Role != models.RoleSuperAdmin {
+		return nil, "That command needs an admin account.", nil
+	}
+	return u, "", nil
+}
+
+func (s Sources) poll(ctx context.Context, req Request) (string, error) {
+	if _, denied, err := s.admin(ctx, req); err != nil || denied != "" {
+		return denied, err
+	}
+	if s.Poll == nil {
+		return "Polling is not available.", nil
+	}
+	node := 0
+	if len(req.Args) > 0 {
+		n, err := strconv.Atoi(req.Args[0])
+		if err != nil || !slices.Contains(s.Nodes, n) {
+			return fmt.Sprintf("%s is not a local node.", req.Args[0]), nil
+		}
+		node = n
+	}
+	s.Poll(node)
+	if node == 0 {
+		return "Polling all nodes.", nil
+	}
+	return fmt.Sprintf("Polling node %d.", node), nil
+}
+
+// control runs a connect or disconnect through AMI and audits it.
+func (s Sources) control(ctx context.Context, req Request, verb, tmpl string) (string, error) {
+	u, denied, err := s.admin(ctx, req)
+	if err != nil || denied != "" {
+		return denied, err
+	}
+	if s.Sender == nil || tmpl == "" {
+		return "Node control needs an AMI connection.", nil
+	}
+	if len(req.Args) < 1 || len(req.Args) > 2 {
+		return fmt.Sprintf("Usage: /%s REMOTE [LOCAL]", verb), nil
+	}
+	remote, err := strconv.Atoi(req.Args[0])
+	if err != nil || remote <= 0 {
+		return fmt.Sprintf("%s is not a node number.", req.Args[0]), nil
+	}
+	if len(s.Nodes) == 0 {
+		return "No local nodes are configured.", nil
+	}
+	local := s.Nodes[0]
+	if len(req.Args) == 2 {
+		n, err := strconv.Atoi(req.Args[1])
+		if err != nil || !slices.Contains(s.Nodes, n) {
+			return fmt.Sprintf("%s is not a local node.", req.Args[1]), nil
+		}
+		local = n
+	}
+
+	command := fmt.Sprintf(tmpl, local, remote)
+	start := time.Now()
+	_, sendErr := s.Sender.SendCommand(ctx, command)
+	if s.Audit != nil {
+		entry := &models.AuditEntry{
+			Actor:      u.Email,
+			RemoteAddr: "telegram:" + strconv.FormatInt(req.UserID, 10),
+			Action:     AuditActionNodeControl,
+			Detail:     command,
+			Outcome:    models.AuditOutcomeOK,
+			DurationMS: time.Since(start).Milliseconds(),
+		}
+		if sendErr != nil {
+			entry.Outcome, entry.Error = models.AuditOutcomeError, sendErr.Error()
+		}
+		_ = s.Audit.Record(context.WithoutCancel(ctx), entry)
+	}
+	if sendErr != nil {
+		return "", fmt.Errorf("%s %d to %d: %w", verb, local, remote, sendErr)
+	}
+	if s.Poll != nil {
+		s.Poll(local)
+	}
+	if verb == "connect" {
+		return fmt.Sprintf("Connecting %d to %d.", local, remote), nil
+	}
+	return fmt.Sprintf("Disconnecting %d from %d.", local, remote), nil
+}
+
+// linkLabel renders a link as "2001 W1AW (Description)" with whatever is known.
+func linkLabel(l core.LinkInfo) string {
+	label := strconv.Itoa(l.Node)
+	if l.NodeCallsign != "" {
+		label += " " + l.NodeCallsign
+	}
+	if l.NodeDescription != "" {
+		label += " (" + l.NodeDescription + ")"
+	}
+	return label
+}
+
+// ActivityAlert formats an activity feed entry for the alert chat.
+func ActivityAlert(e core.ActivityEntry) string {
+	if e.Kind == core.ActivityLevelUp {
+		return e.Title()
+	}
+	return e.Title() + "\n" + e.Summary()
+}
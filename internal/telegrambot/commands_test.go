@@ -0,0 +1,114 @@
+package telegrambot
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dbehnke/allstar-nexus/backend/models"
+	"github.com/dbehnke/allstar-nexus/backend/repository"
+	"github.com/dbehnke/allstar-nexus/internal/ami"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	_ "modernc.org/sqlite"
+)
+
+type recordingSender struct {
+	commands []string
+	err      error
+}
+
+func (s *recordingSender) SendCommand(ctx context.Context, command string) (ami.Message, error) {
+	s.commands = append(s.commands, command)
+	return ami.Message{}, s.err
+}
+
+func newControlBot(t *testing.T) (*Bot, *repository.UserRepo, *repository.AuditRepo, *recordingSender, *[]int) {
+	t.Helper()
+	gdb, err := gorm.Open(sqlite.New(sqlite.Config{DriverName: "sqlite", DSN: filepath.Join(t.TempDir(), "tg.db")}), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open gorm sqlite: %v", err)
+	}
+	if err := gdb.AutoMigrate(&models.User{}, &models.AuditEntry{}); err != nil {
+		t.Fatalf("automigrate: %v", err)
+	}
+	users, audit := repository.NewUserRepo(gdb), repository.NewAuditRepo(gdb)
+	sender := &recordingSender{}
+	var polled []int
+	bot := New("TOKEN", "")
+	bot.RegisterDefaults(Sources{
+		Users: users, Audit: audit, Sender: sender, Nodes: []int{2001, 2002},
+		Poll:              func(node int) { polled = append(polled, node) },
+		ConnectCommand:    "rpt cmd %d ilink 3 %d",
+		DisconnectCommand: "rpt cmd %d ilink 1 %d",
+	})
+	return bot, users, audit, sender, &polled
+}
+
+// say runs text as a command from Telegram user 77 and returns the reply.
+func say(bot *Bot, private bool, text string) string {
+	u := update{Message: &message{From: &user{ID: 77}, Text: text}}
+	u.Message.Chat.ID, u.Message.Chat.Type = -100, "group"
+	if private {
+		u.Message.Chat.ID, u.Message.Chat.Type = 77, "private"
+	}
+	reply, _, _ := bot.handle(context.Background(), u)
+	return reply
+}
+
+func TestLinkAndNodeControl(t *testing.T) {
+	bot, users, audit, sender, polled := newControlBot(t)
+	ctx := context.Background()
+	admin, _ := users.Create(ctx, "admin@example.com", "h", models.RoleAdmin)
+
+	if got := say(bot, true, "/connect 43732"); !strings.HasPrefix(got, "Link your dashboard admin account") {
+		t.Fatalf("unlinked connect: %q", got)
+	}
+	code, _ := bot.NewLinkCode(admin.ID)
+	if got := say(bot, false, "/link "+code); got != "Send /link to me in a private chat." {
+		t.Fatalf("link in group: %q", got)
+	}
+	if got := say(bot, true, "/link "+code); !strings.HasPrefix(got, "Linked.") {
+		t.Fatalf("link: %q", got)
+	}
+
+	if got := say(bot, false, "/connect 43732 2002"); got != "Connecting 2002 to 43732." {
+		t.Fatalf("connect: %q", got)
+	}
+	if got := say(bot, false, "/disconnect 43732"); got != "Disconnecting 2001 from 43732." {
+		t.Fatalf("disconnect: %q", got)
+	}
+	if got := say(bot, false, "/connect 43732 9999"); got != "9999 is not a local node." {
+		t.Fatalf("foreign local node: %q", got)
+	}
+	if len(sender.commands) != 2 || sender.commands[0] != "rpt cmd 2002 ilink 3 43732" || sender.commands[1] != "rpt cmd 2001 ilink 1 43732" {
+		t.Fatalf("commands: %v", sender.commands)
+	}
+	if len(*polled) != 2 || (*polled)[0] != 2002 {
+		t.Fatalf("connects should poll the local node: %v", *polled)
+	}
+
+	sender.err = errors.New("not connected")
+	if got := say(bot, false, "/connect 43732"); !strings.HasPrefix(got, "Sorry") {
+		t.Fatalf("failed connect: %q", got)
+	}
+	entries, _ := audit.Recent(ctx, AuditActionNodeControl, 10)
+	if len(entries) != 3 || entries[0].Outcome != models.AuditOutcomeError || entries[2].Actor != "admin@example.com" || entries[2].RemoteAddr != "telegram:77" {
+		t.Fatalf("audit: %+v", entries)
+	}
+
+	// Demoted accounts lose access even while linked
+	users.DB.Model(&models.User{}).Where("id = ?", admin.ID).Update("role", models.RoleUser)
+	if got := say(bot, false, "/poll"); got != "That command needs an admin account." {
+		t.Fatalf("demoted poll: %q", got)
+	}
+	if got := say(bot, true, "/unlink"); got != "Unlinked." {
+		t.Fatalf("unlink: %q", got)
+	}
+	if u, _ := users.GetByTelegramID(ctx, 77); u != nil {
+		t.Fatal("account still linked")
+	}
+}
@@ -0,0 +1,148 @@
+package tenant
+
+import (
+	"slices"
+
+	"github.com/dbehnke/allstar-nexus/internal/core"
+)
+
+// The Scope helpers narrow live state to what a tenant's viewers may see. They return
+// copies, never mutate their input, and return it unchanged for a nil tenant.
+
+// NodeState keeps the links on the tenant's nodes and presents the tenant's primary
+// node, branding and counts.
+func (t *Tenant) NodeState(st core.NodeState) core.NodeState {
+	if t == nil {
+		return st
+	}
+	primary := st.NodeID
+	st.LinksDetailed = t.Links(st.LinksDetailed, primary)
+	st.Links = make([]int, 0, len(st.LinksDetailed))
+	for _, l := range st.LinksDetailed {
+		st.Links = append(st.Links, l.Node)
+	}
+	st.NumLinks, st.NumALinks = len(st.Links), len(st.Links)
+	if !t.HasNode(primary) {
+		// keying flags describe the instance's primary node
+		st.RxKeyed, st.TxKeyed = false, false
+	}
+	st.NodeID = t.Nodes[0]
+	if t.Branding.Title != "" {
+		st.Title = t.Branding.Title
+	}
+	if t.Branding.Subtitle != "" {
+		st.Subtitle = t.Branding.Subtitle
+	}
+	var parrots []core.ParrotModeStatus
+	for _, p := range st.ParrotModes {
+		if t.HasNode(p.Node) {
+			parrots = append(parrots, p)
+		}
+	}
+	st.ParrotModes = parrots
+	return st
+}
+
+// Links keeps links connected to the tenant's nodes. Links without a recorded local
+// node are attributed to primary, the instance's primary node.
+func (t *Tenant) Links(links []core.LinkInfo, primary int) []core.LinkInfo {
+	if t == nil {
+		return links
+	}
+	out := make([]core.LinkInfo, 0, len(links))
+	for _, l := range links {
+		local := l.LocalNode
+		if local == 0 {
+			local = primary
+		}
+		if t.HasNode(local) {
+			out = append(out, l)
+		}
+	}
+	return out
+}
+
+// TalkerEvent reports whether evt happened on one of the tenant's nodes.
+func (t *Tenant) TalkerEvent(evt core.TalkerEvent) bool {
+	if t == nil {
+		return true
+	}
+	if evt.Source != 0 {
+		return t.HasNode(evt.Source)
+	}
+	return evt.Node != 0 && t.HasNode(evt.Node) // local TX
+}
+
+// TalkerLog keeps the talker log events (as returned by
+// StateManager.TalkerLogSnapshot) that happened on the tenant's nodes.
+func (t *Tenant) TalkerLog(snapshot any) any {
+	events, ok := snapshot.([]core.TalkerEvent)
+	if t == nil || !ok {
+		return snapshot
+	}
+	out := make([]core.TalkerEvent, 0, len(events))
+	for _, e := range events {
+		if t.TalkerEvent(e) {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+// TalkerSessions keeps sessions on the tenant's nodes.
+func (t *Tenant) TalkerSessions(sessions []core.TalkerSession) []core.TalkerSession {
+	if t == nil {
+		return sessions
+	}
+	out := make([]core.TalkerSession, 0, len(sessions))
+	for _, s := range sessions {
+		if t.TalkerEvent(core.TalkerEvent{Node: s.Node, Source: s.Source}) {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// Topology keeps the part of the link graph reachable from the tenant's nodes.
+func (t *Tenant) Topology(topo core.Topology) core.Topology {
+	if t == nil {
+		return topo
+	}
+	adj := map[int][]int{}
+	for _, e := range topo.Edges {
+		adj[e.From] = append(adj[e.From], e.To)
+		adj[e.To] = append(adj[e.To], e.From)
+	}
+	local := map[int]bool{}
+	for _, n := range topo.Nodes {
+		if n.Local {
+			local[n.Node] = true
+		}
+	}
+	reach := map[int]bool{}
+	queue := slices.Clone(t.Nodes)
+	for len(queue) > 0 {
+		n := queue[0]
+		queue = queue[1:]
+		// other tenants' local nodes end the walk: what hangs off them is theirs
+		if reach[n] || (local[n] && !t.HasNode(n)) {
+			continue
+		}
+		reach[n] = true
+		queue = append(queue, adj[n]...)
+	}
+	var nodes []core.TopologyNode
+	for _, n := range topo.Nodes {
+		if reach[n.Node] {
+			nodes = append(nodes, n)
+		}
+	}
+	var edges []core.TopologyEdge
+	for _, e := range topo.Edges {
+		if reach[e.From] && reach[e.To] {
+			edges = append(edges, e)
+		}
+	}
+	topo.Nodes, topo.Edges = nodes, edges
+	return topo
+}
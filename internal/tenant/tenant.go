@@ -0,0 +1,213 @@
+// Package tenant lets one Nexus instance host several independent hubs. Each tenant owns
+// a set of local nodes, its own admins and branding, and is reached under /h/{id}/ or on
+// its own virtual host. Requests are resolved to a tenant by Middleware; everything a
+// tenant's viewers receive is then narrowed to its nodes with the Scope helpers.
+//
+// Requests that resolve to no tenant are served unscoped, as a single-hub instance
+// would, for the operators running the instance.
+package tenant
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/dbehnke/allstar-nexus/backend/models"
+)
+
+// PathPrefix is the path under which tenants are served, e.g. /h/west/api/status.
+const PathPrefix = "/h/"
+
+var idRe = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,31}$`)
+
+// Tenant is one hosted hub.
+type Tenant struct {
+	ID       string
+	Name     string
+	Hosts    []string // virtual hosts, matched without port and case-insensitively
+	Nodes    []int    // local nodes owned by the tenant; the first is its primary node
+	Admins   []string // emails of the users who administer this tenant
+	Branding Branding
+}
+
+// Branding overrides the dashboard theme for a tenant. Empty fields inherit the
+// instance's configured branding.
+type Branding struct {
+	Title    string
+	Subtitle string
+	ClubName string
+	LogoURL  string
+	Colors   map[string]string
+}
+
+// HasNode reports whether node is one of the tenant's local nodes. A nil tenant (an
+// unscoped request) has every node.
+func (t *Tenant) HasNode(node int) bool {
+	return t == nil || slices.Contains(t.Nodes, node)
+}
+
+// Key identifies the tenant in cache keys; empty for unscoped requests.
+func (t *Tenant) Key() string {
+	if t == nil {
+		return ""
+	}
+	return t.ID
+}
+
+// Registry holds the configured tenants. A nil *Registry is a single-hub instance:
+// every request is unscoped.
+type Registry struct {
+	tenants []*Tenant
+	byID    map[string]*Tenant
+	byHost  map[string]*Tenant
+	admins  map[string]bool // emails that administer some tenant
+}
+
+// NewRegistry validates tenants: IDs must be unique lowercase slugs, and hosts and
+// nodes may not be shared between tenants.
+func NewRegistry(tenants []Tenant) (*Registry, error) {
+	r := &Registry{byID: map[string]*Tenant{}, byHost: map[string]*Tenant{}, admins: map[string]bool{}}
+	owner := map[int]string{}
+	for i := range tenants {
+		t := tenants[i]
+		if !idRe.MatchString(t.ID) {
+			return nil, fmt.Errorf("tenant id %q must be a lowercase slug (letters, digits, -)", t.ID)
+		}
+		if r.byID[t.ID] != nil {
+			return nil, fmt.Errorf("duplicate tenant id %q", t.ID)
+		}
+		if len(t.Nodes) == 0 {
+			return nil, fmt.Errorf("tenant %q has no nodes", t.ID)
+		}
+		for _, n := range t.Nodes {
+			if other, ok := owner[n]; ok {
+				return nil, fmt.Errorf("node %d belongs to both tenant %q and %q", n, other, t.ID)
+			}
+			owner[n] = t.ID
+		}
+		if t.Name == "" {
+			t.Name = t.ID
+		}
+		for j, h := range t.Hosts {
+			h = strings.ToLower(strings.TrimSpace(h))
+			if other := r.byHost[h]; other != nil {
+				return nil, fmt.Errorf("host %q belongs to both tenant %q and %q", h, other.ID, t.ID)
+			}
+			t.Hosts[j] = h
+		}
+		for j, e := range t.Admins {
+			t.Admins[j] = strings.ToLower(strings.TrimSpace(e))
+			r.admins[t.Admins[j]] = true
+		}
+		tp := &t
+		r.tenants = append(r.tenants, tp)
+		r.byID[t.ID] = tp
+		for _, h := range t.Hosts {
+			r.byHost[h] = tp
+		}
+	}
+	return r, nil
+}
+
+// Tenants returns the configured tenants in configuration order.
+func (r *Registry) Tenants() []*Tenant {
+	if r == nil {
+		return nil
+	}
+	return r.tenants
+}
+
+// Get returns the tenant with id, or nil.
+func (r *Registry) Get(id string) *Tenant {
+	if r == nil {
+		return nil
+	}
+	return r.byID[id]
+}
+
+// Resolve finds the tenant a request is for: by virtual host first, then by a
+// /h/{id} path prefix, which is returned stripped from path. ok is false for a /h/
+// path naming an unknown tenant.
+func (r *Registry) Resolve(req *http.Request) (t *Tenant, path string, ok bool) {
+	path = req.URL.Path
+	if r == nil {
+		return nil, path, true
+	}
+	host := strings.ToLower(req.Host)
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	if t := r.byHost[host]; t != nil {
+		return t, path, true
+	}
+	if !strings.HasPrefix(path, PathPrefix) {
+		return nil, path, true
+	}
+	id, rest, _ := strings.Cut(strings.TrimPrefix(path, PathPrefix), "/")
+	if t = r.byID[id]; t == nil {
+		return nil, path, false
+	}
+	return t, "/" + rest, true
+}
+
+// Middleware resolves each request's tenant, strips a /h/{id} prefix so the request
+// reaches the regular routes, and stores the tenant in the request context.
+func (r *Registry) Middleware(next http.Handler) http.Handler {
+	if r == nil {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		t, path, ok := r.Resolve(req)
+		if !ok {
+			http.NotFound(w, req)
+			return
+		}
+		if t == nil {
+			next.ServeHTTP(w, req)
+			return
+		}
+		if path != req.URL.Path {
+			u := *req.URL
+			u.Path, u.RawPath = path, ""
+			req = req.Clone(req.Context())
+			req.URL = &u
+		}
+		next.ServeHTTP(w, req.WithContext(WithTenant(req.Context(), t)))
+	})
+}
+
+// IsAdmin reports whether a user administers t. Superadmins administer everything.
+// Users listed as a tenant's admins administer only their tenants; other admins are
+// the instance's operators and administer every tenant as well as the unscoped site.
+func (r *Registry) IsAdmin(t *Tenant, email, role string) bool {
+	if role == models.RoleSuperAdmin {
+		return true
+	}
+	email = strings.ToLower(email)
+	if r != nil && t != nil && slices.Contains(t.Admins, email) {
+		return true
+	}
+	return role == models.RoleAdmin && !r.IsTenantAdmin(email)
+}
+
+// IsTenantAdmin reports whether email is listed as an admin of some tenant.
+func (r *Registry) IsTenantAdmin(email string) bool {
+	return r != nil && r.admins[strings.ToLower(email)]
+}
+
+type ctxKey struct{}
+
+// WithTenant returns ctx carrying t.
+func WithTenant(ctx context.Context, t *Tenant) context.Context {
+	return context.WithValue(ctx, ctxKey{}, t)
+}
+
+// FromContext returns the request's tenant, or nil for unscoped requests.
+func FromContext(ctx context.Context) *Tenant {
+	t, _ := ctx.Value(ctxKey{}).(*Tenant)
+	return t
+}
@@ -0,0 +1,161 @@
+package tenant
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/dbehnke/allstar-nexus/backend/models"
+	"github.com/dbehnke/allstar-nexus/internal/core"
+)
+
+func testRegistry(t *testing.T) *Registry {
+	t.Helper()
+	reg, err := NewRegistry([]Tenant{
+		{ID: "west", Hosts: []string{"West.Example.org"}, Nodes: []int{2001, 2002}, Admins: []string{"Wes@example.org"}},
+		{ID: "east", Name: "East Hub", Nodes: []int{3001}, Admins: []string{"eve@example.org"}},
+	})
+	if err != nil {
+		t.Fatalf("NewRegistry: %v", err)
+	}
+	return reg
+}
+
+func TestNewRegistry_Validation(t *testing.T) {
+	cases := map[string][]Tenant{
+		"bad id":      {{ID: "West Hub", Nodes: []int{1}}},
+		"duplicate":   {{ID: "a", Nodes: []int{1}}, {ID: "a", Nodes: []int{2}}},
+		"no nodes":    {{ID: "a"}},
+		"shared node": {{ID: "a", Nodes: []int{1}}, {ID: "b", Nodes: []int{1}}},
+		"shared host": {{ID: "a", Nodes: []int{1}, Hosts: []string{"x.org"}}, {ID: "b", Nodes: []int{2}, Hosts: []string{"X.org"}}},
+	}
+	for name, ts := range cases {
+		if _, err := NewRegistry(ts); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+	reg := testRegistry(t)
+	if got := reg.Get("west").Name; got != "west" {
+		t.Errorf("default name = %q, want west", got)
+	}
+}
+
+func TestResolveAndMiddleware(t *testing.T) {
+	reg := testRegistry(t)
+	var gotTenant, gotPath string
+	h := reg.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotTenant, gotPath = FromContext(r.Context()).Key(), r.URL.Path
+	}))
+
+	for _, tc := range []struct {
+		host, path, tenant, stripped string
+		status                       int
+	}{
+		{"nexus.example.org", "/api/status", "", "/api/status", 200},
+		{"nexus.example.org", "/h/east/api/status", "east", "/api/status", 200},
+		{"nexus.example.org", "/h/east", "east", "/", 200},
+		{"west.example.org:8080", "/api/status", "west", "/api/status", 200},
+		{"nexus.example.org", "/h/nope/api/status", "", "", 404},
+	} {
+		gotTenant, gotPath = "", ""
+		req := httptest.NewRequest("GET", tc.path, nil)
+		req.Host = tc.host
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, req)
+		if rr.Code != tc.status || gotTenant != tc.tenant || gotPath != tc.stripped {
+			t.Errorf("%s%s: status %d tenant %q path %q, want %d %q %q", tc.host, tc.path, rr.Code, gotTenant, gotPath, tc.status, tc.tenant, tc.stripped)
+		}
+	}
+
+	// a nil registry serves everything unscoped
+	var none *Registry
+	gotTenant = "x"
+	none.Middleware(h).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/api/status", nil))
+	if gotTenant != "" {
+		t.Errorf("nil registry scoped the request to %q", gotTenant)
+	}
+}
+
+func TestIsAdmin(t *testing.T) {
+	reg := testRegistry(t)
+	west, east := reg.Get("west"), reg.Get("east")
+	for _, tc := range []struct {
+		name  string
+		t     *Tenant
+		email string
+		role  string
+		want  bool
+	}{
+		{"superadmin everywhere", east, "root@example.org", models.RoleSuperAdmin, true},
+		{"tenant admin on own tenant", west, "wes@example.org", models.RoleUser, true},
+		{"tenant admin email case", west, "WES@example.org", models.RoleUser, true},
+		{"tenant admin on other tenant", east, "wes@example.org", models.RoleAdmin, false},
+		{"tenant admin unscoped", nil, "wes@example.org", models.RoleAdmin, false},
+		{"operator admin on tenant", east, "op@example.org", models.RoleAdmin, true},
+		{"operator admin unscoped", nil, "op@example.org", models.RoleAdmin, true},
+		{"plain user", west, "joe@example.org", models.RoleUser, false},
+	} {
+		if got := reg.IsAdmin(tc.t, tc.email, tc.role); got != tc.want {
+			t.Errorf("%s: IsAdmin = %v, want %v", tc.name, got, tc.want)
+		}
+	}
+	var none *Registry
+	if !none.IsAdmin(nil, "op@example.org", models.RoleAdmin) || none.IsAdmin(nil, "joe@example.org", models.RoleUser) {
+		t.Error("nil registry should fall back to roles")
+	}
+}
+
+func TestScope(t *testing.T) {
+	west := testRegistry(t).Get("west")
+	west.Branding.Title = "West Hub"
+
+	st := core.NodeState{
+		NodeID:  3001, // instance primary belongs to east
+		RxKeyed: true,
+		Title:   "Instance",
+		LinksDetailed: []core.LinkInfo{
+			{Node: 500, LocalNode: 2001},
+			{Node: 501, LocalNode: 3001},
+			{Node: 502}, // on the primary
+			{Node: 503, LocalNode: 2002},
+		},
+		ParrotModes: []core.ParrotModeStatus{{Node: 2002}, {Node: 3001}},
+	}
+	got := west.NodeState(st)
+	if len(got.Links) != 2 || got.Links[0] != 500 || got.Links[1] != 503 || got.NumLinks != 2 {
+		t.Errorf("links = %v (%d), want [500 503]", got.Links, got.NumLinks)
+	}
+	if got.NodeID != 2001 || got.RxKeyed || got.Title != "West Hub" {
+		t.Errorf("state = node %d rx %v title %q", got.NodeID, got.RxKeyed, got.Title)
+	}
+	if len(got.ParrotModes) != 1 || got.ParrotModes[0].Node != 2002 {
+		t.Errorf("parrot modes = %+v", got.ParrotModes)
+	}
+	if len(st.LinksDetailed) != 4 {
+		t.Error("NodeState mutated its input")
+	}
+	var none *Tenant
+	if n := len(none.NodeState(st).LinksDetailed); n != 4 {
+		t.Errorf("nil tenant kept %d links, want 4", n)
+	}
+
+	log := west.TalkerLog([]core.TalkerEvent{{Node: 500, Source: 2001}, {Node: 501, Source: 3001}, {Node: 2002}}).([]core.TalkerEvent)
+	if len(log) != 2 || log[0].Node != 500 || log[1].Node != 2002 {
+		t.Errorf("talker log = %+v", log)
+	}
+
+	// 2001-500-600 is west's; 3001-501 is east's even though 500 also links to 3001
+	topo := west.Topology(core.Topology{
+		Nodes: []core.TopologyNode{{Node: 2001, Local: true}, {Node: 3001, Local: true}, {Node: 500}, {Node: 501}, {Node: 600}},
+		Edges: []core.TopologyEdge{{From: 2001, To: 500}, {From: 500, To: 600}, {From: 3001, To: 501}, {From: 3001, To: 500}},
+	})
+	var nodes []string
+	for _, n := range topo.Nodes {
+		nodes = append(nodes, strconv.Itoa(n.Node))
+	}
+	if strings.Join(nodes, ",") != "2001,500,600" || len(topo.Edges) != 2 {
+		t.Errorf("topology nodes = %v edges = %+v", nodes, topo.Edges)
+	}
+}
@@ -0,0 +1,166 @@
+// Package textnode maps text node identifiers (callsigns linked by name, e.g. "KF8S")
+// to the negative integer IDs used wherever the rest of the code expects a node
+// number. The AMI and core layers share one registry so both resolve a name to the
+// same ID, and the mapping is persisted so history survives a restart.
+package textnode
+
+import (
+	"log"
+	"strings"
+	"sync"
+)
+
+// idMask keeps hashed IDs within 30 bits so they stay clear of real node numbers
+// once negated.
+const idMask = 0x3FFFFFFF
+
+// Entry is a single persisted mapping. Hash is the unsalted hash of Name; it differs
+// from ID when the name was renumbered after a collision.
+type Entry struct {
+	ID   int
+	Name string
+	Hash int
+}
+
+// Store persists new mappings. Save is called from a background goroutine and must be
+// safe for concurrent use.
+type Store interface {
+	SaveTextNode(e Entry) error
+}
+
+// Registry is a bidirectional name <-> ID map with collision detection.
+type Registry struct {
+	mu     sync.RWMutex
+	byID   map[int]string
+	byName map[string]int
+	store  Store
+}
+
+// NewRegistry returns an empty registry with no persistence.
+func NewRegistry() *Registry {
+	return &Registry{byID: make(map[int]string), byName: make(map[string]int)}
+}
+
+// Default is the process-wide registry used by the AMI parsers and StateManager.
+var Default = NewRegistry()
+
+// Hash returns the unsalted ID for name: FNV-1a over the uppercased string, negated.
+func Hash(name string) int {
+	s := normalize(name)
+	hash := uint32(2166136261)
+	for i := 0; i < len(s); i++ {
+		hash ^= uint32(s[i])
+		hash *= 16777619
+	}
+	return -int(hash & idMask)
+}
+
+func normalize(name string) string { return strings.ToUpper(strings.TrimSpace(name)) }
+
+// SetStore enables persistence of mappings created from now on.
+func (r *Registry) SetStore(s Store) {
+	r.mu.Lock()
+	r.store = s
+	r.mu.Unlock()
+}
+
+// Load seeds the registry from persisted entries, typically at startup before AMI
+// connects. Persisted IDs win over hashing so renumbered names keep their IDs.
+// Returns how many entries were skipped because they conflict with one already loaded.
+func (r *Registry) Load(entries []Entry) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	skipped := 0
+	for _, e := range entries {
+		name := normalize(e.Name)
+		if name == "" || e.ID >= 0 {
+			skipped++
+			continue
+		}
+		if _, taken := r.byID[e.ID]; taken {
+			skipped++
+			continue
+		}
+		if _, known := r.byName[name]; known {
+			skipped++
+			continue
+		}
+		r.byID[e.ID] = name
+		r.byName[name] = e.ID
+	}
+	return skipped
+}
+
+// ID returns the node ID for name, assigning one on first sight. If the name's hash is
+// already held by a different name, the next free ID below it is used instead and
+// the collision is logged, so two callsigns never share an ID.
+func (r *Registry) ID(name string) int {
+	name = normalize(name)
+	r.mu.RLock()
+	id, ok := r.byName[name]
+	r.mu.RUnlock()
+	if ok {
+		return id
+	}
+
+	r.mu.Lock()
+	if id, ok := r.byName[name]; ok {
+		r.mu.Unlock()
+		return id
+	}
+	hash := Hash(name)
+	id = hash
+	for {
+		if id == 0 || id < -idMask {
+			id = -1 // wrap; 0 is never a text node
+		}
+		if _, taken := r.byID[id]; !taken {
+			break
+		}
+		id--
+	}
+	if id != hash {
+		log.Printf("[TEXTNODE] collision: %s hashes to %d (held by %s), renumbered to %d",
+			name, hash, r.byID[hash], id)
+	}
+	r.byID[id] = name
+	r.byName[name] = id
+	store := r.store
+	r.mu.Unlock()
+
+	if store != nil {
+		e := Entry{ID: id, Name: name, Hash: hash}
+		go func() {
+			if err := store.SaveTextNode(e); err != nil {
+				log.Printf("[TEXTNODE] persist %s -> %d: %v", e.Name, e.ID, err)
+			}
+		}()
+	}
+	return id
+}
+
+// Name returns the text name registered for id.
+func (r *Registry) Name(id int) (string, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	name, ok := r.byID[id]
+	return name, ok
+}
+
+// Forget drops name from memory (e.g. after a callsign data purge). Its ID is released
+// and may be reassigned.
+func (r *Registry) Forget(name string) {
+	name = normalize(name)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if id, ok := r.byName[name]; ok {
+		delete(r.byName, name)
+		delete(r.byID, id)
+	}
+}
+
+// ID resolves name in the Default registry.
+func ID(name string) int { return Default.ID(name) }
+
+// Name looks up id in the Default registry.
+func Name(id int) (string, bool) { return Default.Name(id) }
@@ -0,0 +1,106 @@
+package textnode
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+type memStore struct {
+	mu    sync.Mutex
+	saved []Entry
+}
+
+func (m *memStore) SaveTextNode(e Entry) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.saved = append(m.saved, e)
+	return nil
+}
+
+func (m *memStore) entries() []Entry {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]Entry(nil), m.saved...)
+}
+
+func TestRegistryStableAndCaseInsensitive(t *testing.T) {
+	r := NewRegistry()
+	id := r.ID("kf8s")
+	if id >= 0 || id != Hash("KF8S") {
+		t.Fatalf("ID(kf8s) = %d, want hash %d", id, Hash("KF8S"))
+	}
+	if again := r.ID(" KF8S "); again != id {
+		t.Fatalf("ID(KF8S) = %d, want %d", again, id)
+	}
+	if name, ok := r.Name(id); !ok || name != "KF8S" {
+		t.Fatalf("Name(%d) = %q, %v", id, name, ok)
+	}
+}
+
+func TestRegistryRenumbersCollision(t *testing.T) {
+	r := NewRegistry()
+	h := Hash("W1ABC")
+	// Another callsign already holds W1ABC's hash (and the next slot)
+	r.Load([]Entry{{ID: h, Name: "OTHER1", Hash: h}, {ID: h - 1, Name: "OTHER2", Hash: h - 1}})
+
+	id := r.ID("W1ABC")
+	if id != h-2 {
+		t.Fatalf("ID(W1ABC) = %d, want renumbered %d", id, h-2)
+	}
+	if name, _ := r.Name(h); name != "OTHER1" {
+		t.Fatalf("existing mapping overwritten: %q", name)
+	}
+	if name, _ := r.Name(id); name != "W1ABC" {
+		t.Fatalf("Name(%d) = %q", id, name)
+	}
+}
+
+func TestRegistryLoadKeepsPersistedIDs(t *testing.T) {
+	r := NewRegistry()
+	skipped := r.Load([]Entry{
+		{ID: -42, Name: "w1abc", Hash: Hash("W1ABC")}, // renumbered in an earlier run
+		{ID: -43, Name: "W1ABC"},                      // duplicate name
+		{ID: -42, Name: "K1XYZ"},                      // duplicate ID
+		{ID: 5, Name: "BAD"},                          // not a text node ID
+	})
+	if skipped != 3 {
+		t.Fatalf("skipped = %d, want 3", skipped)
+	}
+	if id := r.ID("W1ABC"); id != -42 {
+		t.Fatalf("ID(W1ABC) = %d, want persisted -42", id)
+	}
+}
+
+func TestRegistryPersistsNewMappings(t *testing.T) {
+	r := NewRegistry()
+	r.Load([]Entry{{ID: -7, Name: "KNOWN"}})
+	store := &memStore{}
+	r.SetStore(store)
+
+	r.ID("KNOWN")
+	id := r.ID("N0NEW")
+	r.ID("N0NEW")
+
+	deadline := time.Now().Add(time.Second)
+	for len(store.entries()) == 0 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	time.Sleep(20 * time.Millisecond)
+	got := store.entries()
+	if len(got) != 1 || got[0] != (Entry{ID: id, Name: "N0NEW", Hash: Hash("N0NEW")}) {
+		t.Fatalf("saved = %+v, want only N0NEW", got)
+	}
+}
+
+func TestRegistryForget(t *testing.T) {
+	r := NewRegistry()
+	id := r.ID("W1AW")
+	r.Forget("w1aw")
+	if _, ok := r.Name(id); ok {
+		t.Fatal("forgotten name still resolves")
+	}
+	if again := r.ID("W1AW"); again != id {
+		t.Fatalf("re-registered ID = %d, want hash %d", again, id)
+	}
+}
@@ -0,0 +1,78 @@
+// Package timefmt formats durations for people and describes the hub's timezone and
+// locale, so API payloads and webhook templates can show "2h 13m" next to the raw
+// seconds instead of every client re-implementing the formatting.
+package timefmt
+
+import (
+	"fmt"
+	"time"
+)
+
+var units = []struct {
+	suffix string
+	size   time.Duration
+}{
+	{"d", 24 * time.Hour},
+	{"h", time.Hour},
+	{"m", time.Minute},
+	{"s", time.Second},
+}
+
+// Duration renders d with its two most significant units, e.g. "45s", "13m 5s",
+// "2h 13m" or "3d 4h". Anything under a second is "0s"; negative durations get a
+// leading "-".
+func Duration(d time.Duration) string {
+	sign := ""
+	if d < 0 {
+		sign, d = "-", -d
+	}
+	for i, u := range units {
+		if d < u.size {
+			continue
+		}
+		out := fmt.Sprintf("%d%s", d/u.size, u.suffix)
+		if i+1 < len(units) {
+			if n := d % u.size / units[i+1].size; n > 0 {
+				out += fmt.Sprintf(" %d%s", n, units[i+1].suffix)
+			}
+		}
+		return sign + out
+	}
+	return "0s"
+}
+
+// Seconds is Duration for a whole number of seconds.
+func Seconds(s int64) string {
+	return Duration(time.Duration(s) * time.Second)
+}
+
+// Locale is the hub's configured timezone and language tag.
+type Locale struct {
+	Location *time.Location // nil = system local
+	Tag      string         // BCP 47 language tag (e.g. "en-US"); empty = client default
+}
+
+// Hints tells clients how the hub presents times.
+type Hints struct {
+	Timezone  string `json:"timezone"`         // IANA name, or "Local" for the system zone
+	UTCOffset string `json:"utc_offset"`       // current offset, e.g. "-04:00"
+	Locale    string `json:"locale,omitempty"` // BCP 47 language tag
+}
+
+// Zone returns the hub's time zone.
+func (l Locale) Zone() *time.Location {
+	if l.Location == nil {
+		return time.Local
+	}
+	return l.Location
+}
+
+// Hints describes l at now; the offset follows daylight saving time.
+func (l Locale) Hints(now time.Time) Hints {
+	loc := l.Zone()
+	return Hints{
+		Timezone:  loc.String(),
+		UTCOffset: now.In(loc).Format("-07:00"),
+		Locale:    l.Tag,
+	}
+}
@@ -0,0 +1,46 @@
+package timefmt
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDuration(t *testing.T) {
+	cases := []struct {
+		in   time.Duration
+		want string
+	}{
+		{0, "0s"},
+		{900 * time.Millisecond, "0s"},
+		{45 * time.Second, "45s"},
+		{13*time.Minute + 5*time.Second, "13m 5s"},
+		{2*time.Hour + 13*time.Minute + 59*time.Second, "2h 13m"},
+		{2*time.Hour + 30*time.Second, "2h"},
+		{76 * time.Hour, "3d 4h"},
+		{-90 * time.Second, "-1m 30s"},
+	}
+	for _, c := range cases {
+		if got := Duration(c.in); got != c.want {
+			t.Errorf("Duration(%v) = %q, want %q", c.in, got, c.want)
+		}
+	}
+	if got := Seconds(7980); got != "2h 13m" {
+		t.Errorf("Seconds(7980) = %q", got)
+	}
+}
+
+func TestLocaleHints(t *testing.T) {
+	loc, err := time.LoadLocation("America/Detroit")
+	if err != nil {
+		t.Skipf("tzdata unavailable: %v", err)
+	}
+	l := Locale{Location: loc, Tag: "en-US"}
+	summer := l.Hints(time.Date(2026, 7, 1, 12, 0, 0, 0, time.UTC))
+	winter := l.Hints(time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC))
+	if summer.Timezone != "America/Detroit" || summer.UTCOffset != "-04:00" || winter.UTCOffset != "-05:00" || summer.Locale != "en-US" {
+		t.Fatalf("hints = %+v / %+v", summer, winter)
+	}
+	if h := (Locale{}).Hints(time.Now()); h.Timezone != "Local" {
+		t.Fatalf("zero locale = %+v", h)
+	}
+}
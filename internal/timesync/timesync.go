@@ -0,0 +1,260 @@
+package timesync
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// ntpEpochOffset is the number of seconds between 1900-01-01 (NTP epoch) and 1970-01-01 (Unix epoch).
+const ntpEpochOffset = 2208988800
+
+// Result is the outcome of a single clock comparison.
+// Offset is reference time minus local time: a positive offset means the local clock is behind.
+type Result struct {
+	CheckedAt     time.Time     `json:"checked_at"`
+	Source        string        `json:"source,omitempty"`
+	Offset        time.Duration `json:"-"`
+	OffsetSeconds float64       `json:"offset_seconds"`
+	Skewed        bool          `json:"skewed"`
+	Error         string        `json:"error,omitempty"`
+}
+
+// Checker periodically compares the system clock against NTP servers, falling back
+// to HTTP Date headers when UDP/123 is blocked. Raspberry Pi deployments lack an RTC
+// and frequently boot with a stale clock, which silently corrupts tally windows.
+type Checker struct {
+	NTPServers []string      // host or host:port, queried in order
+	HTTPURLs   []string      // fallback URLs whose Date header is used as reference
+	MaxSkew    time.Duration // offsets beyond this are reported as skew
+	Timeout    time.Duration // per-source timeout
+
+	// OnSkew, if set, is invoked when a check detects skew (alerting hook).
+	OnSkew func(Result)
+
+	mu      sync.RWMutex
+	last    Result
+	hasLast bool
+	logger  *zap.Logger
+	stopCh  chan struct{}
+	once    sync.Once
+	now     func() time.Time
+}
+
+// NewChecker creates a checker. Empty source lists fall back to pool.ntp.org and a
+// well-known HTTPS endpoint; maxSkew <= 0 defaults to 5 seconds.
+func NewChecker(ntpServers, httpURLs []string, maxSkew time.Duration, logger *zap.Logger) *Checker {
+	if len(ntpServers) == 0 && len(httpURLs) == 0 {
+		ntpServers = []string{"pool.ntp.org"}
+		httpURLs = []string{"https://www.google.com"}
+	}
+	if maxSkew <= 0 {
+		maxSkew = 5 * time.Second
+	}
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &Checker{
+		NTPServers: ntpServers,
+		HTTPURLs:   httpURLs,
+		MaxSkew:    maxSkew,
+		Timeout:    5 * time.Second,
+		logger:     logger,
+		stopCh:     make(chan struct{}),
+		now:        time.Now,
+	}
+}
+
+// Check queries the configured sources in order and records the first successful result.
+func (c *Checker) Check(ctx context.Context) Result {
+	res := Result{CheckedAt: c.now()}
+	var errs []error
+	for _, server := range c.NTPServers {
+		off, err := c.queryNTP(ctx, server)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("ntp %s: %w", server, err))
+			continue
+		}
+		res.Source = "ntp:" + server
+		res.Offset = off
+		return c.record(res)
+	}
+	for _, url := range c.HTTPURLs {
+		off, err := c.queryHTTP(ctx, url)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("http %s: %w", url, err))
+			continue
+		}
+		res.Source = "http:" + url
+		res.Offset = off
+		return c.record(res)
+	}
+	res.Error = errors.Join(errs...).Error()
+	c.logger.Warn("clock sanity check failed: no time source reachable", zap.String("error", res.Error))
+	c.mu.Lock()
+	// Keep the previous offset so a transient network failure doesn't clear a known skew.
+	if c.hasLast {
+		prev := c.last
+		prev.Error = res.Error
+		prev.CheckedAt = res.CheckedAt
+		res = prev
+	}
+	c.last = res
+	c.hasLast = true
+	c.mu.Unlock()
+	return res
+}
+
+func (c *Checker) record(res Result) Result {
+	res.OffsetSeconds = res.Offset.Seconds()
+	abs := res.Offset
+	if abs < 0 {
+		abs = -abs
+	}
+	res.Skewed = abs > c.MaxSkew
+	c.mu.Lock()
+	c.last = res
+	c.hasLast = true
+	cb := c.OnSkew
+	c.mu.Unlock()
+
+	if res.Skewed {
+		c.logger.Warn("system clock skew detected",
+			zap.String("source", res.Source),
+			zap.Duration("offset", res.Offset),
+			zap.Duration("max_skew", c.MaxSkew))
+		if cb != nil {
+			cb(res)
+		}
+	} else {
+		c.logger.Debug("system clock within tolerance", zap.String("source", res.Source), zap.Duration("offset", res.Offset))
+	}
+	return res
+}
+
+// Last returns the most recent result and whether any check has run.
+func (c *Checker) Last() (Result, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.last, c.hasLast
+}
+
+// Skew reports the last measured offset and whether it exceeded MaxSkew.
+// Its signature matches gamification.TallyService.ClockSkew.
+func (c *Checker) Skew() (time.Duration, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.last.Offset, c.last.Skewed
+}
+
+// Start re-checks the clock every interval until Stop is called. Callers wanting a
+// startup check should call Check first.
+func (c *Checker) Start(interval time.Duration) {
+	if interval <= 0 {
+		interval = time.Hour
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				c.runOnce()
+			case <-c.stopCh:
+				return
+			}
+		}
+	}()
+}
+
+// Stop terminates the periodic check loop.
+func (c *Checker) Stop() {
+	c.once.Do(func() { close(c.stopCh) })
+}
+
+func (c *Checker) runOnce() {
+	ctx, cancel := context.WithTimeout(context.Background(), c.Timeout*time.Duration(len(c.NTPServers)+len(c.HTTPURLs)+1))
+	defer cancel()
+	c.Check(ctx)
+}
+
+// queryNTP performs a single SNTP (RFC 4330) exchange and returns the clock offset.
+func (c *Checker) queryNTP(ctx context.Context, server string) (time.Duration, error) {
+	if _, _, err := net.SplitHostPort(server); err != nil {
+		server = net.JoinHostPort(server, "123")
+	}
+	d := net.Dialer{Timeout: c.Timeout}
+	conn, err := d.DialContext(ctx, "udp", server)
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = conn.Close() }()
+	_ = conn.SetDeadline(time.Now().Add(c.Timeout))
+
+	req := make([]byte, 48)
+	req[0] = 0x1B // LI=0, VN=3, Mode=3 (client)
+	t1 := c.now()
+	if _, err := conn.Write(req); err != nil {
+		return 0, err
+	}
+	resp := make([]byte, 48)
+	n, err := conn.Read(resp)
+	if err != nil {
+		return 0, err
+	}
+	t4 := c.now()
+	if n < 48 {
+		return 0, fmt.Errorf("short ntp response (%d bytes)", n)
+	}
+	if mode := resp[0] & 0x07; mode != 4 {
+		return 0, fmt.Errorf("unexpected ntp mode %d", mode)
+	}
+	if resp[1] == 0 {
+		return 0, errors.New("ntp kiss-of-death (stratum 0)")
+	}
+	t2 := ntpTime(resp[32:40])
+	t3 := ntpTime(resp[40:48])
+	return (t2.Sub(t1) + t3.Sub(t4)) / 2, nil
+}
+
+// queryHTTP derives the offset from a server's Date header (1 second resolution).
+func (c *Checker) queryHTTP(ctx context.Context, url string) (time.Duration, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.Timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, url, nil)
+	if err != nil {
+		return 0, err
+	}
+	t1 := c.now()
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	_ = resp.Body.Close()
+	t4 := c.now()
+	date := resp.Header.Get("Date")
+	if date == "" {
+		return 0, errors.New("no Date header")
+	}
+	ref, err := http.ParseTime(date)
+	if err != nil {
+		return 0, fmt.Errorf("parse Date header: %w", err)
+	}
+	// Date is truncated to the second; compare against the midpoint of the round trip plus half a second.
+	mid := t1.Add(t4.Sub(t1) / 2)
+	return ref.Add(500 * time.Millisecond).Sub(mid), nil
+}
+
+func ntpTime(b []byte) time.Time {
+	secs := binary.BigEndian.Uint32(b[0:4])
+	frac := binary.BigEndian.Uint32(b[4:8])
+	nsec := (int64(frac) * 1e9) >> 32
+	return time.Unix(int64(secs)-ntpEpochOffset, nsec)
+}
@@ -0,0 +1,71 @@
+package timesync
+
+import (
+	"context"
+	"encoding/binary"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestCheckerHTTPFallbackDetectsSkew(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Date", time.Now().Add(30*time.Second).UTC().Format(http.TimeFormat))
+	}))
+	defer srv.Close()
+
+	c := NewChecker(nil, []string{srv.URL}, 5*time.Second, nil)
+	alerted := false
+	c.OnSkew = func(Result) { alerted = true }
+
+	res := c.Check(context.Background())
+	if res.Error != "" {
+		t.Fatalf("unexpected error: %s", res.Error)
+	}
+	if !res.Skewed || !alerted {
+		t.Fatalf("expected skew to be detected and alerted, got %+v", res)
+	}
+	if res.OffsetSeconds < 28 || res.OffsetSeconds > 32 {
+		t.Fatalf("expected ~30s offset, got %.2f", res.OffsetSeconds)
+	}
+	if off, skewed := c.Skew(); !skewed || off <= 0 {
+		t.Fatalf("Skew() = %s, %v", off, skewed)
+	}
+}
+
+func TestCheckerNTPWithinTolerance(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("udp not available: %v", err)
+	}
+	defer pc.Close()
+	go func() {
+		buf := make([]byte, 48)
+		n, addr, err := pc.ReadFrom(buf)
+		if err != nil || n < 48 {
+			return
+		}
+		resp := make([]byte, 48)
+		resp[0] = 0x1C // LI=0, VN=3, Mode=4 (server)
+		resp[1] = 2    // stratum
+		putNTP(resp[32:40], time.Now())
+		putNTP(resp[40:48], time.Now())
+		_, _ = pc.WriteTo(resp, addr)
+	}()
+
+	c := NewChecker([]string{pc.LocalAddr().String()}, nil, 5*time.Second, nil)
+	res := c.Check(context.Background())
+	if res.Error != "" {
+		t.Fatalf("unexpected error: %s", res.Error)
+	}
+	if res.Skewed {
+		t.Fatalf("expected no skew against local clock, got %+v", res)
+	}
+}
+
+func putNTP(b []byte, t time.Time) {
+	binary.BigEndian.PutUint32(b[0:4], uint32(t.Unix()+ntpEpochOffset))
+	binary.BigEndian.PutUint32(b[4:8], uint32((int64(t.Nanosecond())<<32)/1e9))
+}
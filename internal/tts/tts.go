@@ -0,0 +1,100 @@
+// Package tts renders announcement text to audio app_rpt can play: 8 kHz, mono,
+// 16-bit WAV. Speech comes from espeak-ng or Piper and is resampled with sox. Text is
+// always passed on stdin, never through a shell.
+package tts
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// Supported engines.
+const (
+	EngineEspeak = "espeak"
+	EnginePiper  = "piper"
+)
+
+// Config selects the speech engine.
+type Config struct {
+	Engine string // espeak (default) or piper
+	Binary string // defaults to espeak-ng or piper on PATH
+	Voice  string // espeak voice (e.g. en-us) or path to a Piper .onnx model
+	Sox    string // defaults to sox on PATH
+}
+
+// Synthesizer runs the configured engine.
+type Synthesizer struct {
+	cfg Config
+	// run executes a command with stdin; replaceable in tests
+	run func(ctx context.Context, name string, args []string, stdin string) error
+}
+
+// New validates c and fills in default binaries.
+func New(c Config) (*Synthesizer, error) {
+	c.Engine = strings.ToLower(strings.TrimSpace(c.Engine))
+	switch c.Engine {
+	case "", EngineEspeak:
+		c.Engine = EngineEspeak
+		if c.Binary == "" {
+			c.Binary = "espeak-ng"
+		}
+	case EnginePiper:
+		if c.Binary == "" {
+			c.Binary = "piper"
+		}
+		if c.Voice == "" {
+			return nil, fmt.Errorf("tts: piper needs a voice model (.onnx)")
+		}
+	default:
+		return nil, fmt.Errorf("tts: unknown engine %q (want espeak or piper)", c.Engine)
+	}
+	if c.Sox == "" {
+		c.Sox = "sox"
+	}
+	return &Synthesizer{cfg: c, run: runCommand}, nil
+}
+
+// Synthesize speaks text into out, an 8 kHz mono 16-bit WAV file.
+func (s *Synthesizer) Synthesize(ctx context.Context, text, out string) error {
+	raw := strings.TrimSuffix(out, ".wav") + ".raw.wav"
+	defer os.Remove(raw)
+
+	var args []string
+	switch s.cfg.Engine {
+	case EnginePiper:
+		args = []string{"--model", s.cfg.Voice, "--output_file", raw}
+	default:
+		args = []string{"--stdin", "-w", raw}
+		if s.cfg.Voice != "" {
+			args = append(args, "-v", s.cfg.Voice)
+		}
+	}
+	if err := s.run(ctx, s.cfg.Binary, args, text); err != nil {
+		return fmt.Errorf("tts: %s: %w", s.cfg.Engine, err)
+	}
+	if err := s.run(ctx, s.cfg.Sox, []string{raw, "-r", "8000", "-c", "1", "-b", "16", out}, ""); err != nil {
+		return fmt.Errorf("tts: resampling: %w", err)
+	}
+	return nil
+}
+
+func runCommand(ctx context.Context, name string, args []string, stdin string) error {
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Stdin = strings.NewReader(stdin)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			if len(msg) > 200 {
+				msg = msg[:200]
+			}
+			return fmt.Errorf("%w: %s", err, msg)
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package tts
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+type call struct {
+	name  string
+	args  []string
+	stdin string
+}
+
+func record(calls *[]call, fail string) func(ctx context.Context, name string, args []string, stdin string) error {
+	return func(ctx context.Context, name string, args []string, stdin string) error {
+		*calls = append(*calls, call{name, args, stdin})
+		if name == fail {
+			return errors.New("exit status 1")
+		}
+		return nil
+	}
+}
+
+func TestSynthesizeEspeak(t *testing.T) {
+	s, err := New(Config{Voice: "en-us"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var calls []call
+	s.run = record(&calls, "")
+	if err := s.Synthesize(context.Background(), "Net starts at 8; QRT", "/snd/announcement-1.wav"); err != nil {
+		t.Fatal(err)
+	}
+	if len(calls) != 2 {
+		t.Fatalf("calls: %+v", calls)
+	}
+	if got := calls[0]; got.name != "espeak-ng" || got.stdin != "Net starts at 8; QRT" || strings.Join(got.args, " ") != "--stdin -w /snd/announcement-1.raw.wav -v en-us" {
+		t.Fatalf("espeak call: %+v", got)
+	}
+	if got := strings.Join(calls[1].args, " "); calls[1].name != "sox" || got != "/snd/announcement-1.raw.wav -r 8000 -c 1 -b 16 /snd/announcement-1.wav" {
+		t.Fatalf("sox call: %s %s", calls[1].name, got)
+	}
+}
+
+func TestSynthesizePiperFailure(t *testing.T) {
+	if _, err := New(Config{Engine: "piper"}); err == nil {
+		t.Fatal("piper without a model should be rejected")
+	}
+	if _, err := New(Config{Engine: "festival"}); err == nil {
+		t.Fatal("unknown engine should be rejected")
+	}
+	s, _ := New(Config{Engine: "Piper", Voice: "/models/en_US-amy.onnx", Binary: "/opt/piper/piper"})
+	var calls []call
+	s.run = record(&calls, "/opt/piper/piper")
+	err := s.Synthesize(context.Background(), "hello", "/snd/a.wav")
+	if err == nil || !strings.Contains(err.Error(), "piper") || len(calls) != 1 {
+		t.Fatalf("expected engine failure without resampling, got %v (%d calls)", err, len(calls))
+	}
+	if strings.Join(calls[0].args, " ") != "--model /models/en_US-amy.onnx --output_file /snd/a.raw.wav" {
+		t.Fatalf("piper args: %v", calls[0].args)
+	}
+}
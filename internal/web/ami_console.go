@@ -0,0 +1,82 @@
+package web
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/coder/websocket"
+	"github.com/dbehnke/allstar-nexus/internal/core"
+)
+
+// amiConsoleChunkLines bounds the lines sent per AMI_CONSOLE_OUTPUT frame.
+const amiConsoleChunkLines = 50
+
+// amiConsoleRequest is sent by the client to run a command; ID is echoed in replies.
+type amiConsoleRequest struct {
+	ID      string `json:"id"`
+	Command string `json:"command"`
+}
+
+// HandleAMIConsole serves the AMI console over a dedicated websocket. Clients send
+// {"id","command"} frames; output is streamed back as AMI_CONSOLE_OUTPUT chunks
+// followed by AMI_CONSOLE_DONE, or a single AMI_CONSOLE_ERROR. authValidator must only
+// admit superadmins and returns the actor recorded in the audit log.
+func HandleAMIConsole(console *core.AMIConsole, authValidator func(r *http.Request) (actor string, ok bool)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		actor, ok := authValidator(r)
+		if !ok {
+			http.Error(w, "forbidden", http.StatusForbidden)
+			return
+		}
+		c, err := websocket.Accept(w, r, nil)
+		if err != nil {
+			http.Error(w, "websocket_accept_failed", http.StatusInternalServerError)
+			return
+		}
+		defer func() { _ = c.Close(websocket.StatusNormalClosure, "") }()
+		log.Printf("[AMI CONSOLE] %s connected", actor)
+
+		ctx := r.Context()
+		send := func(messageType string, data any) error {
+			b, _ := json.Marshal(messageEnvelope{MessageType: messageType, Data: data, Timestamp: time.Now().UnixMilli()})
+			wctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+			defer cancel()
+			return c.Write(wctx, websocket.MessageText, b)
+		}
+		_ = send("AMI_CONSOLE_READY", map[string]any{"commands": console.Allowed(), "max_output_bytes": console.MaxBytes()})
+
+		for {
+			_, data, err := c.Read(ctx)
+			if err != nil {
+				return
+			}
+			var req amiConsoleRequest
+			if err := json.Unmarshal(data, &req); err != nil {
+				_ = send("AMI_CONSOLE_ERROR", map[string]any{"error": "bad_request", "message": "invalid json"})
+				continue
+			}
+			res, err := console.Run(ctx, actor, r.RemoteAddr, req.Command)
+			if err != nil {
+				code := "ami_error"
+				if errors.Is(err, core.ErrCommandNotAllowed) {
+					code = "command_not_allowed"
+				}
+				_ = send("AMI_CONSOLE_ERROR", map[string]any{"id": req.ID, "error": code, "message": err.Error()})
+				continue
+			}
+			for seq, start := 0, 0; start < len(res.Lines); seq, start = seq+1, start+amiConsoleChunkLines {
+				end := min(start+amiConsoleChunkLines, len(res.Lines))
+				if err := send("AMI_CONSOLE_OUTPUT", map[string]any{"id": req.ID, "seq": seq, "lines": res.Lines[start:end]}); err != nil {
+					return
+				}
+			}
+			_ = send("AMI_CONSOLE_DONE", map[string]any{
+				"id": req.ID, "command": res.Command, "bytes": res.Bytes, "truncated": res.Truncated, "duration_ms": res.DurationMS,
+			})
+		}
+	}
+}
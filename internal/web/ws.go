@@ -2,15 +2,18 @@ package web
 
 import (
 	"context"
-	"encoding/json"
 	"log"
 	"net/http"
-	"strings"
+	"slices"
 	"sync"
 	"time"
 
 	"github.com/coder/websocket"
+	"github.com/dbehnke/allstar-nexus/internal/astdb"
+	"github.com/dbehnke/allstar-nexus/internal/asterisklog"
 	"github.com/dbehnke/allstar-nexus/internal/core"
+	"github.com/dbehnke/allstar-nexus/internal/privacy"
+	"github.com/dbehnke/allstar-nexus/internal/tenant"
 )
 
 // messageEnvelope defines WS protocol envelope.
@@ -23,19 +26,86 @@ type messageEnvelope struct {
 // Hub manages websocket clients and broadcasts.
 type Hub struct {
 	mu      sync.RWMutex
-	clients map[*websocket.Conn]clientInfo
+	clients map[*websocket.Conn]*clientInfo
+	nextID  uint64
 	// Optional hook to trigger a server-side poll when new clients connect.
 	// This will be set by main when a PollingService is in-use.
 	triggerPoll func()
 	pollMu      sync.Mutex
 	pollTimer   *time.Timer
+	policy      privacy.Policy
+	identify    func(r *http.Request) string // optional: who a client is signed in as
+	state       *core.StateManager           // current links, for scoping LINK_TX to tenants
 }
 
 type clientInfo struct {
-	isAdmin bool
+	viewer  privacy.Viewer
+	tenant  *tenant.Tenant // nil for unscoped clients
+	profile PayloadProfile
+	stats   clientStats
 }
 
-func NewHub() *Hub { return &Hub{clients: map[*websocket.Conn]clientInfo{}} }
+func NewHub() *Hub {
+	return &Hub{clients: map[*websocket.Conn]*clientInfo{}, policy: privacy.DefaultPolicy()}
+}
+
+// SetPrivacyPolicy sets the masking policy applied to non-admin clients.
+func (h *Hub) SetPrivacyPolicy(p privacy.Policy) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.policy = p
+}
+
+// SetState lets the hub scope per-link TX events to tenants by the links currently on
+// their nodes.
+func (h *Hub) SetState(sm *core.StateManager) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.state = sm
+}
+
+type payloadKey struct {
+	viewer  privacy.Viewer
+	tenant  *tenant.Tenant
+	profile PayloadProfile
+}
+
+// broadcastPerViewer marshals one payload per viewer class, tenant and payload profile
+// (only for combinations with connected clients) and fans it out. build returns
+// ok=false to skip a class.
+func (h *Hub) broadcastPerViewer(messageType string, build func(p privacy.Policy, v privacy.Viewer, t *tenant.Tenant) (data any, ok bool)) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	payloads := map[payloadKey][]byte{}
+	for c, info := range h.clients {
+		key := payloadKey{info.viewer, info.tenant, info.profile}
+		p, done := payloads[key]
+		if !done {
+			if data, ok := build(h.policy, info.viewer, info.tenant); ok {
+				p = encodeMessage(info.profile, messageType, data)
+			}
+			payloads[key] = p
+		}
+		if p == nil {
+			continue
+		}
+		go h.write(c, info, p)
+	}
+}
+
+// broadcast sends data to every client, marshalling it once per payload profile. Use it
+// only for data that is not tied to particular nodes.
+func (h *Hub) broadcast(messageType string, data any) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	var payloads [ProfileCompact + 1][]byte
+	for c, info := range h.clients {
+		if payloads[info.profile] == nil {
+			payloads[info.profile] = encodeMessage(info.profile, messageType, data)
+		}
+		go h.write(c, info, payloads[info.profile])
+	}
+}
 
 // SetTriggerPoll sets an optional function that will be invoked (debounced)
 // shortly after new clients connect. Debouncing avoids immediate repeated
@@ -72,8 +142,23 @@ func (h *Hub) TriggerPollDebounced() {
 	})
 }
 
-// HandleWS upgrades and registers a client.
+// HandleWS upgrades and registers a client. Non-admin clients are treated as signed-in users;
+// use HandleWSViewer to distinguish anonymous viewers.
 func (h *Hub) HandleWS(sm *core.StateManager, authValidator func(r *http.Request) (allowed bool, isAdmin bool)) http.HandlerFunc {
+	return h.HandleWSViewer(sm, func(r *http.Request) (bool, privacy.Viewer) {
+		if authValidator == nil {
+			return true, privacy.ViewerUser
+		}
+		allowed, isAdmin := authValidator(r)
+		if isAdmin {
+			return allowed, privacy.ViewerAdmin
+		}
+		return allowed, privacy.ViewerUser
+	})
+}
+
+// HandleWSViewer upgrades and registers a client, classifying it for the privacy policy.
+func (h *Hub) HandleWSViewer(sm *core.StateManager, authValidator func(r *http.Request) (allowed bool, viewer privacy.Viewer)) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		// If this is not a WebSocket upgrade request, return a helpful status.
 		if r.Header.Get("Connection") == "" || r.Header.Get("Upgrade") == "" {
@@ -82,11 +167,9 @@ func (h *Hub) HandleWS(sm *core.StateManager, authValidator func(r *http.Request
 			_, _ = w.Write([]byte(`{"ok":false,"error":"websocket_upgrade_required"}`))
 			return
 		}
-		var allowed, isAdmin bool
+		allowed, viewer := true, privacy.ViewerAnonymous
 		if authValidator != nil {
-			allowed, isAdmin = authValidator(r)
-		} else {
-			allowed, isAdmin = true, false
+			allowed, viewer = authValidator(r)
 		}
 		if !allowed {
 			http.Error(w, "unauthorized", http.StatusUnauthorized)
@@ -98,8 +181,13 @@ func (h *Hub) HandleWS(sm *core.StateManager, authValidator func(r *http.Request
 			http.Error(w, "websocket_accept_failed", http.StatusInternalServerError)
 			return
 		}
+		t := tenant.FromContext(r.Context())
+		info := &clientInfo{viewer: viewer, tenant: t, profile: ParseProfile(r.URL.Query().Get("profile"))}
 		h.mu.Lock()
-		h.clients[c] = clientInfo{isAdmin: isAdmin}
+		h.nextID++
+		info.stats.init(h.nextID, r, h.identify)
+		h.clients[c] = info
+		policy := h.policy
 		clientCount := len(h.clients)
 		h.mu.Unlock()
 		log.Printf("[WS] client connected (total=%d)", clientCount)
@@ -110,40 +198,33 @@ func (h *Hub) HandleWS(sm *core.StateManager, authValidator func(r *http.Request
 				h.mu.Unlock()
 				_ = c.Close(websocket.StatusNormalClosure, "")
 			}()
-			for { // discard inbound
+			for { // discard inbound, but count it as activity
 				if _, _, err := c.Read(context.Background()); err != nil {
 					return
 				}
+				info.stats.markReceived()
 			}
 		}()
-		// Immediately send current snapshot (apply masking for non-admins)
-		snap := sm.Snapshot()
-		if !isAdmin {
-			maskNodeStateIPs(&snap)
-		}
-		env := messageEnvelope{MessageType: "STATUS_UPDATE", Data: snap, Timestamp: time.Now().UnixMilli()}
-		b, _ := json.Marshal(env)
-		if err := c.Write(context.Background(), websocket.MessageText, b); err != nil {
+		// Immediately send current snapshot (apply privacy policy for non-admins)
+		snap := policy.NodeState(t.NodeState(sm.Snapshot()), viewer)
+		if err := h.write(c, info, encodeMessage(info.profile, "STATUS_UPDATE", snap)); err != nil {
 			log.Printf("[WS] write STATUS_UPDATE failed: %v", err)
 		}
 
-		// Send initial talker log snapshot
-		talkerLog := sm.TalkerLogSnapshot()
-		talkerEnv := messageEnvelope{MessageType: "TALKER_LOG_SNAPSHOT", Data: talkerLog, Timestamp: time.Now().UnixMilli()}
-		talkerB, _ := json.Marshal(talkerEnv)
-		if err := c.Write(context.Background(), websocket.MessageText, talkerB); err != nil {
+		// Send initial talker log snapshot (empty when the viewer may not see talker history)
+		talkerLog := policy.TalkerLog(t.TalkerLog(sm.TalkerLogSnapshot()), viewer)
+		if err := h.write(c, info, encodeMessage(info.profile, "TALKER_LOG_SNAPSHOT", talkerLog)); err != nil {
 			log.Printf("[WS] write TALKER_LOG_SNAPSHOT failed: %v", err)
 		}
 
-		// Send initial source node keying snapshots (apply masking for non-admins)
+		// Send initial source node keying snapshots (apply privacy policy for non-admins)
 		for _, sourceNodeID := range sm.GetSourceNodes() {
+			if !t.HasNode(sourceNodeID) {
+				continue
+			}
 			if snapshot, ok := sm.GetSourceNodeSnapshot(sourceNodeID); ok {
-				if !isAdmin {
-					maskSourceNodeKeyingUpdateIPs(&snapshot)
-				}
-				snEnv := messageEnvelope{MessageType: "SOURCE_NODE_KEYING", Data: snapshot, Timestamp: time.Now().UnixMilli()}
-				snB, _ := json.Marshal(snEnv)
-				if err := c.Write(context.Background(), websocket.MessageText, snB); err != nil {
+				snapshot = policy.KeyingUpdate(snapshot, viewer)
+				if err := h.write(c, info, encodeMessage(info.profile, "SOURCE_NODE_KEYING", snapshot)); err != nil {
 					log.Printf("[WS] write SOURCE_NODE_KEYING failed: %v", err)
 				}
 			}
@@ -161,87 +242,43 @@ func (h *Hub) HandleWS(sm *core.StateManager, authValidator func(r *http.Request
 // BroadcastLoop listens for state updates and fans out.
 func (h *Hub) BroadcastLoop(updates <-chan core.NodeState) {
 	for st := range updates {
-		// Build both admin and masked payloads once
-		adminEnv := messageEnvelope{MessageType: "STATUS_UPDATE", Data: st, Timestamp: time.Now().UnixMilli()}
-		adminPayload, _ := json.Marshal(adminEnv)
-		masked := st
-		maskNodeStateIPs(&masked)
-		maskedEnv := messageEnvelope{MessageType: "STATUS_UPDATE", Data: masked, Timestamp: time.Now().UnixMilli()}
-		maskedPayload, _ := json.Marshal(maskedEnv)
-		h.mu.RLock()
-		for c, info := range h.clients {
-			if info.isAdmin {
-				go func(conn *websocket.Conn, p []byte) {
-					_ = conn.Write(context.Background(), websocket.MessageText, p)
-				}(c, adminPayload)
-			} else {
-				go func(conn *websocket.Conn, p []byte) {
-					_ = conn.Write(context.Background(), websocket.MessageText, p)
-				}(c, maskedPayload)
-			}
-		}
-		h.mu.RUnlock()
+		h.broadcastPerViewer("STATUS_UPDATE", func(p privacy.Policy, v privacy.Viewer, t *tenant.Tenant) (any, bool) {
+			return p.NodeState(t.NodeState(st), v), true
+		})
 	}
 }
 
 // TalkerLoop broadcasts talker events.
 func (h *Hub) TalkerLoop(events <-chan core.TalkerEvent) {
 	for evt := range events {
-		env := messageEnvelope{MessageType: "TALKER_EVENT", Data: evt, Timestamp: time.Now().UnixMilli()}
-		payload, _ := json.Marshal(env)
-		h.mu.RLock()
-		for c := range h.clients {
-			go func(conn *websocket.Conn, p []byte) {
-			_ = conn.Write(context.Background(), websocket.MessageText, p)
-		}(c, payload)
-		}
-		h.mu.RUnlock()
+		h.broadcastPerViewer("TALKER_EVENT", func(p privacy.Policy, v privacy.Viewer, t *tenant.Tenant) (any, bool) {
+			if !t.TalkerEvent(evt) {
+				return nil, false
+			}
+			return p.TalkerEvent(evt, v)
+		})
 	}
 }
 
 // LinkUpdateLoop broadcasts incremental link additions.
 func (h *Hub) LinkUpdateLoop(updates <-chan []core.LinkInfo) {
 	for added := range updates {
-		// Prepare admin and masked variants
-		adminEnv := messageEnvelope{MessageType: "LINK_ADDED", Data: added, Timestamp: time.Now().UnixMilli()}
-		adminPayload, _ := json.Marshal(adminEnv)
-		maskedSlice := make([]core.LinkInfo, len(added))
-		copy(maskedSlice, added)
-		for i := range maskedSlice {
-			maskedSlice[i].IP = maskIP(maskedSlice[i].IP)
-		}
-		maskedEnv := messageEnvelope{MessageType: "LINK_ADDED", Data: maskedSlice, Timestamp: time.Now().UnixMilli()}
-		maskedPayload, _ := json.Marshal(maskedEnv)
-		h.mu.RLock()
-		for c, info := range h.clients {
-			if info.isAdmin {
-				go func(conn *websocket.Conn, p []byte) {
-					_ = conn.Write(context.Background(), websocket.MessageText, p)
-				}(c, adminPayload)
-			} else {
-				go func(conn *websocket.Conn, p []byte) {
-					_ = conn.Write(context.Background(), websocket.MessageText, p)
-				}(c, maskedPayload)
-			}
-		}
-		h.mu.RUnlock()
+		primary := h.primaryNode()
+		h.broadcastPerViewer("LINK_ADDED", func(p privacy.Policy, v privacy.Viewer, t *tenant.Tenant) (any, bool) {
+			links := t.Links(added, primary)
+			return p.Links(links, v), len(links) > 0
+		})
 		// Trigger a debounced poll after link additions to enrich state (e.g., elapsed, IP)
 		h.TriggerPollDebounced()
 	}
 }
 
-// LinkRemovalLoop broadcasts link removals.
+// LinkRemovalLoop broadcasts link removals. Removals carry only node numbers and the
+// link is already gone from the state, so they go to every client; dashboards ignore
+// nodes they never had.
 func (h *Hub) LinkRemovalLoop(removals <-chan []int) {
 	for rem := range removals {
-		env := messageEnvelope{MessageType: "LINK_REMOVED", Data: rem, Timestamp: time.Now().UnixMilli()}
-		payload, _ := json.Marshal(env)
-		h.mu.RLock()
-		for c := range h.clients {
-			go func(conn *websocket.Conn, p []byte) {
-			_ = conn.Write(context.Background(), websocket.MessageText, p)
-		}(c, payload)
-		}
-		h.mu.RUnlock()
+		h.broadcast("LINK_REMOVED", rem)
 		// Trigger a debounced poll after link removals to confirm state and enrich details.
 		h.TriggerPollDebounced()
 	}
@@ -250,15 +287,10 @@ func (h *Hub) LinkRemovalLoop(removals <-chan []int) {
 // LinkTxLoop broadcasts per-link TX start/stop events.
 func (h *Hub) LinkTxLoop(events <-chan core.LinkTxEvent) {
 	for evt := range events {
-		env := messageEnvelope{MessageType: "LINK_TX", Data: evt, Timestamp: time.Now().UnixMilli()}
-		payload, _ := json.Marshal(env)
-		h.mu.RLock()
-		for c := range h.clients {
-			go func(conn *websocket.Conn, p []byte) {
-			_ = conn.Write(context.Background(), websocket.MessageText, p)
-		}(c, payload)
-		}
-		h.mu.RUnlock()
+		linked := h.linkedNodes()
+		h.broadcastPerViewer("LINK_TX", func(_ privacy.Policy, _ privacy.Viewer, t *tenant.Tenant) (any, bool) {
+			return evt, t == nil || linked(t)[evt.Node]
+		})
 	}
 }
 
@@ -273,15 +305,21 @@ func (h *Hub) LinkTxBatchLoop(events <-chan core.LinkTxEvent, window time.Durati
 		if len(buf) == 0 {
 			return
 		}
-		env := messageEnvelope{MessageType: "LINK_TX_BATCH", Data: buf, Timestamp: time.Now().UnixMilli()}
-		payload, _ := json.Marshal(env)
-		h.mu.RLock()
-		for c := range h.clients {
-			go func(conn *websocket.Conn, p []byte) {
-			_ = conn.Write(context.Background(), websocket.MessageText, p)
-		}(c, payload)
-		}
-		h.mu.RUnlock()
+		batch := slices.Clone(buf)
+		linked := h.linkedNodes()
+		h.broadcastPerViewer("LINK_TX_BATCH", func(_ privacy.Policy, _ privacy.Viewer, t *tenant.Tenant) (any, bool) {
+			if t == nil {
+				return batch, true
+			}
+			mine := linked(t)
+			var out []core.LinkTxEvent
+			for _, e := range batch {
+				if mine[e.Node] {
+					out = append(out, e)
+				}
+			}
+			return out, len(out) > 0
+		})
 		buf = buf[:0]
 	}
 	for {
@@ -327,26 +365,9 @@ func (h *Hub) HeartbeatLoop(sm *core.StateManager, interval time.Duration) {
 			tickCount = 0
 		}
 		snap := sm.Snapshot()
-		// Build both admin and masked payloads
-		adminEnv := messageEnvelope{MessageType: "STATUS_UPDATE", Data: snap, Timestamp: time.Now().UnixMilli()}
-		adminPayload, _ := json.Marshal(adminEnv)
-		masked := snap
-		maskNodeStateIPs(&masked)
-		maskedEnv := messageEnvelope{MessageType: "STATUS_UPDATE", Data: masked, Timestamp: time.Now().UnixMilli()}
-		maskedPayload, _ := json.Marshal(maskedEnv)
-		h.mu.RLock()
-		for c, info := range h.clients {
-			if info.isAdmin {
-				go func(conn *websocket.Conn, p []byte) {
-					_ = conn.Write(context.Background(), websocket.MessageText, p)
-				}(c, adminPayload)
-			} else {
-				go func(conn *websocket.Conn, p []byte) {
-					_ = conn.Write(context.Background(), websocket.MessageText, p)
-				}(c, maskedPayload)
-			}
-		}
-		h.mu.RUnlock()
+		h.broadcastPerViewer("STATUS_UPDATE", func(p privacy.Policy, v privacy.Viewer, t *tenant.Tenant) (any, bool) {
+			return p.NodeState(t.NodeState(snap), v), true
+		})
 	}
 }
 
@@ -360,112 +381,128 @@ func (h *Hub) TalkerLogRefreshLoop(sm *core.StateManager, interval time.Duration
 	defer ticker.Stop()
 	for range ticker.C {
 		talkerLog := sm.TalkerLogSnapshot()
-		env := messageEnvelope{MessageType: "TALKER_LOG_SNAPSHOT", Data: talkerLog, Timestamp: time.Now().UnixMilli()}
-		payload, _ := json.Marshal(env)
-		h.mu.RLock()
-		for c := range h.clients {
-			go func(conn *websocket.Conn, p []byte) {
-			_ = conn.Write(context.Background(), websocket.MessageText, p)
-		}(c, payload)
-		}
-		h.mu.RUnlock()
+		h.broadcastPerViewer("TALKER_LOG_SNAPSHOT", func(p privacy.Policy, v privacy.Viewer, t *tenant.Tenant) (any, bool) {
+			return p.TalkerLog(t.TalkerLog(talkerLog), v), true
+		})
 	}
 }
 
 // SourceNodeKeyingLoop broadcasts source node keying state updates
 func (h *Hub) SourceNodeKeyingLoop(updates <-chan core.SourceNodeKeyingUpdate) {
 	for update := range updates {
-		// Build both admin and masked payloads
-		adminEnv := messageEnvelope{MessageType: "SOURCE_NODE_KEYING", Data: update, Timestamp: time.Now().UnixMilli()}
-		adminPayload, _ := json.Marshal(adminEnv)
-		maskedUpdate := update
-		maskSourceNodeKeyingUpdateIPs(&maskedUpdate)
-		maskedEnv := messageEnvelope{MessageType: "SOURCE_NODE_KEYING", Data: maskedUpdate, Timestamp: time.Now().UnixMilli()}
-		maskedPayload, _ := json.Marshal(maskedEnv)
-		h.mu.RLock()
-		for c, info := range h.clients {
-			if info.isAdmin {
-				go func(conn *websocket.Conn, p []byte) {
-					_ = conn.Write(context.Background(), websocket.MessageText, p)
-				}(c, adminPayload)
-			} else {
-				go func(conn *websocket.Conn, p []byte) {
-					_ = conn.Write(context.Background(), websocket.MessageText, p)
-				}(c, maskedPayload)
-			}
-		}
-		h.mu.RUnlock()
+		h.broadcastPerViewer("SOURCE_NODE_KEYING", func(p privacy.Policy, v privacy.Viewer, t *tenant.Tenant) (any, bool) {
+			return p.KeyingUpdate(update, v), t.HasNode(update.SourceNodeID)
+		})
 	}
 }
 
 // SourceNodeKeyingEventLoop broadcasts session edge events (TX_START/TX_END)
 func (h *Hub) SourceNodeKeyingEventLoop(events <-chan core.SourceNodeKeyingEvent) {
 	for event := range events {
-		env := messageEnvelope{MessageType: "SOURCE_NODE_KEYING_EVENT", Data: event, Timestamp: time.Now().UnixMilli()}
-		payload, _ := json.Marshal(env)
-		h.mu.RLock()
-		for c := range h.clients {
-			go func(conn *websocket.Conn, p []byte) {
-			_ = conn.Write(context.Background(), websocket.MessageText, p)
-		}(c, payload)
-		}
-		h.mu.RUnlock()
+		h.broadcastPerViewer("SOURCE_NODE_KEYING_EVENT", func(_ privacy.Policy, _ privacy.Viewer, t *tenant.Tenant) (any, bool) {
+			return event, t.HasNode(event.SourceNodeID)
+		})
+	}
+}
+
+// ParrotModeLoop broadcasts parrot (audio test) mode changes so dashboards can flag test mode
+func (h *Hub) ParrotModeLoop(events <-chan core.ParrotModeStatus) {
+	for evt := range events {
+		h.broadcastPerViewer("PARROT_MODE", func(_ privacy.Policy, _ privacy.Viewer, t *tenant.Tenant) (any, bool) {
+			return evt, t.HasNode(evt.Node)
+		})
 	}
 }
 
 // BroadcastTallyCompleted emits a GAMIFICATION_TALLY_COMPLETED event with an optional summary payload
 func (h *Hub) BroadcastTallyCompleted(summary interface{}) {
-	env := messageEnvelope{MessageType: "GAMIFICATION_TALLY_COMPLETED", Data: summary, Timestamp: time.Now().UnixMilli()}
-	payload, _ := json.Marshal(env)
-	h.mu.RLock()
-	for c := range h.clients {
-		go func(conn *websocket.Conn, p []byte) {
-			_ = conn.Write(context.Background(), websocket.MessageText, p)
-		}(c, payload)
-	}
-	h.mu.RUnlock()
+	h.broadcast("GAMIFICATION_TALLY_COMPLETED", summary)
 }
 
-// maskIP masks the last two octets of an IPv4 address, leaving others unchanged
-func maskIP(ip string) string {
-	if ip == "" {
-		return ip
-	}
-	parts := strings.Split(ip, ".")
-	if len(parts) == 4 {
-		return parts[0] + "." + parts[1] + ".*.*"
+// BroadcastTallyProgress emits GAMIFICATION_TALLY_PROGRESS while a startup
+// backlog is being tallied in the background.
+func (h *Hub) BroadcastTallyProgress(progress interface{}) {
+	h.broadcast("GAMIFICATION_TALLY_PROGRESS", progress)
+}
+
+// BroadcastAstDBUpdated emits an ASTDB_UPDATED event after the node database was
+// refreshed; relabeled is the number of connected links whose labels changed.
+func (h *Hub) BroadcastAstDBUpdated(u astdb.Update, relabeled int) {
+	h.broadcast("ASTDB_UPDATED", map[string]any{"count": u.Count, "updated_at": u.UpdatedAt, "relabeled": relabeled})
+}
+
+// WatchlistLoop pushes watchlist alerts to the instance's admin clients only.
+func (h *Hub) WatchlistLoop(events <-chan core.WatchEvent) {
+	for evt := range events {
+		h.broadcastPerViewer("WATCHLIST_ALERT", func(_ privacy.Policy, v privacy.Viewer, t *tenant.Tenant) (any, bool) {
+			return evt, v == privacy.ViewerAdmin && t == nil
+		})
 	}
-	// Non-IPv4: leave as-is (could extend to IPv6 masking later)
-	return ip
 }
 
-// maskNodeStateIPs mutates the provided NodeState to mask IPs in LinksDetailed
-func maskNodeStateIPs(st *core.NodeState) {
-	if st == nil {
-		return
+// TopologyLoop broadcasts TOPOLOGY_UPDATE whenever the link graph changes.
+func (h *Hub) TopologyLoop(updates <-chan core.Topology) {
+	for t := range updates {
+		topo := t
+		h.broadcastPerViewer("TOPOLOGY_UPDATE", func(p privacy.Policy, v privacy.Viewer, t *tenant.Tenant) (any, bool) {
+			return p.Topology(t.Topology(topo), v), true
+		})
 	}
-	// Make a defensive copy of the LinksDetailed slice so masking doesn't
-	// mutate the shared backing array that may be referenced elsewhere.
-	if len(st.LinksDetailed) == 0 {
-		return
+}
+
+// AsteriskLogAlertLoop pushes Asterisk log alerts to the instance's admin clients only.
+func (h *Hub) AsteriskLogAlertLoop(events <-chan asterisklog.Alert) {
+	for evt := range events {
+		h.broadcastPerViewer("ASTERISK_LOG_ALERT", func(_ privacy.Policy, v privacy.Viewer, t *tenant.Tenant) (any, bool) {
+			return evt, v == privacy.ViewerAdmin && t == nil
+		})
 	}
-	copied := make([]core.LinkInfo, len(st.LinksDetailed))
-	copy(copied, st.LinksDetailed)
-	for i := range copied {
-		copied[i].IP = maskIP(copied[i].IP)
+}
+
+// IAXAlertLoop pushes IAX2 registration/peer alerts to the instance's admin clients only.
+func (h *Hub) IAXAlertLoop(events <-chan core.IAXAlert) {
+	for evt := range events {
+		h.broadcastPerViewer("IAX_ALERT", func(_ privacy.Policy, v privacy.Viewer, t *tenant.Tenant) (any, bool) {
+			return evt, v == privacy.ViewerAdmin && t == nil
+		})
 	}
-	st.LinksDetailed = copied
 }
 
-// maskSourceNodeKeyingUpdateIPs mutates the update to mask IPs in AdjacentNodes
-func maskSourceNodeKeyingUpdateIPs(upd *core.SourceNodeKeyingUpdate) {
-	if upd == nil || len(upd.AdjacentNodes) == 0 {
-		return
+// primaryNode returns the instance's primary node, which links without a recorded local
+// node belong to.
+func (h *Hub) primaryNode() int {
+	h.mu.RLock()
+	sm := h.state
+	h.mu.RUnlock()
+	if sm == nil {
+		return 0
 	}
-	masked := make(map[int]core.AdjacentNodeStatus, len(upd.AdjacentNodes))
-	for k, v := range upd.AdjacentNodes {
-		v.IP = maskIP(v.IP)
-		masked[k] = v
+	return sm.Snapshot().NodeID
+}
+
+// linkedNodes returns a lookup of the remote nodes currently linked to a tenant's
+// nodes, computing the snapshot once and each tenant's set on first use.
+func (h *Hub) linkedNodes() func(t *tenant.Tenant) map[int]bool {
+	h.mu.RLock()
+	sm := h.state
+	h.mu.RUnlock()
+	var st *core.NodeState
+	sets := map[*tenant.Tenant]map[int]bool{}
+	return func(t *tenant.Tenant) map[int]bool {
+		if set, ok := sets[t]; ok {
+			return set
+		}
+		set := map[int]bool{}
+		if sm != nil {
+			if st == nil {
+				snap := sm.Snapshot()
+				st = &snap
+			}
+			for _, l := range t.Links(st.LinksDetailed, st.NodeID) {
+				set[l.Node] = true
+			}
+		}
+		sets[t] = set
+		return set
 	}
-	upd.AdjacentNodes = masked
 }
@@ -0,0 +1,51 @@
+package web
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+// BenchStorm describes a synthetic event storm used to load-test websocket fan-out.
+type BenchStorm struct {
+	Run      string        `json:"run"`      // identifies the storm's messages to the bench client
+	Count    int           `json:"count"`    // messages to broadcast
+	Interval time.Duration `json:"interval"` // gap between messages; 0 sends as fast as possible
+	Size     int           `json:"size"`     // padding bytes per message, to mimic large STATUS_UPDATEs
+}
+
+// benchMessage is the payload of a BENCH message. Sent is unix nanoseconds taken just
+// before the message is handed to the fan-out, so clients on a synced clock can measure
+// end-to-end latency; Seq lets them count drops.
+type benchMessage struct {
+	Run  string `json:"run"`
+	Seq  int    `json:"seq"`
+	Sent int64  `json:"sent"`
+	Pad  string `json:"pad,omitempty"`
+}
+
+// Bench broadcasts a storm of BENCH messages to every client through the same path as
+// real events, returning the number sent before ctx was cancelled. Dashboards ignore the
+// unknown message type, so a storm is harmless to regular viewers beyond the load itself.
+func (h *Hub) Bench(ctx context.Context, s BenchStorm) int {
+	pad := strings.Repeat("x", max(s.Size, 0))
+	var tick <-chan time.Time
+	if s.Interval > 0 {
+		t := time.NewTicker(s.Interval)
+		defer t.Stop()
+		tick = t.C
+	}
+	for seq := 1; seq <= s.Count; seq++ {
+		if tick != nil && seq > 1 {
+			select {
+			case <-ctx.Done():
+				return seq - 1
+			case <-tick:
+			}
+		} else if ctx.Err() != nil {
+			return seq - 1
+		}
+		h.broadcast("BENCH", benchMessage{Run: s.Run, Seq: seq, Sent: time.Now().UnixNano(), Pad: pad})
+	}
+	return s.Count
+}
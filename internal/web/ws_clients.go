@@ -0,0 +1,142 @@
+package web
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/coder/websocket"
+	"github.com/dbehnke/allstar-nexus/internal/privacy"
+)
+
+// ClientInfo describes a connected dashboard websocket client.
+type ClientInfo struct {
+	ID               uint64    `json:"id"`
+	ConnectedAt      time.Time `json:"connected_at"`
+	RemoteAddr       string    `json:"remote_addr"`
+	UserAgent        string    `json:"user_agent,omitempty"`
+	User             string    `json:"user,omitempty"` // signed-in email; empty for anonymous viewers
+	Viewer           string    `json:"viewer"`
+	Admin            bool      `json:"admin"`
+	Tenant           string    `json:"tenant,omitempty"` // hosted hub the client is viewing
+	Profile          string    `json:"profile"`          // payload profile: full or compact
+	MessagesSent     uint64    `json:"messages_sent"`
+	BytesSent        uint64    `json:"bytes_sent"`
+	MessagesReceived uint64    `json:"messages_received"`
+	WriteErrors      uint64    `json:"write_errors"`
+	LastActivity     time.Time `json:"last_activity"` // last successful send or inbound frame
+}
+
+// clientStats is the per-connection bookkeeping behind ClientInfo. Counters are updated
+// from concurrent write goroutines, so they are atomics.
+type clientStats struct {
+	id          uint64
+	connectedAt time.Time
+	remoteAddr  string
+	userAgent   string
+	user        string
+	sent        atomic.Uint64
+	bytes       atomic.Uint64
+	received    atomic.Uint64
+	writeErrors atomic.Uint64
+	lastActive  atomic.Int64 // unix nanos
+}
+
+func (s *clientStats) init(id uint64, r *http.Request, identify func(r *http.Request) string) {
+	s.id = id
+	s.connectedAt = time.Now()
+	s.remoteAddr = r.RemoteAddr
+	if xff := r.Header.Get("X-Forwarded-For"); xff != "" {
+		s.remoteAddr = strings.TrimSpace(strings.Split(xff, ",")[0])
+	} else if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		s.remoteAddr = host
+	}
+	s.userAgent = r.UserAgent()
+	if identify != nil {
+		s.user = identify(r)
+	}
+	s.lastActive.Store(s.connectedAt.UnixNano())
+}
+
+func (s *clientStats) markReceived() {
+	s.received.Add(1)
+	s.lastActive.Store(time.Now().UnixNano())
+}
+
+// writeTimeout bounds a single send so a stuck client cannot pin a goroutine forever.
+const writeTimeout = 10 * time.Second
+
+// write sends p to one client and records the outcome.
+func (h *Hub) write(c *websocket.Conn, info *clientInfo, p []byte) error {
+	ctx, cancel := context.WithTimeout(context.Background(), writeTimeout)
+	defer cancel()
+	if err := c.Write(ctx, websocket.MessageText, p); err != nil {
+		info.stats.writeErrors.Add(1)
+		return err
+	}
+	info.stats.sent.Add(1)
+	info.stats.bytes.Add(uint64(len(p)))
+	info.stats.lastActive.Store(time.Now().UnixNano())
+	return nil
+}
+
+// SetIdentify configures how a connecting client's user is determined (e.g. from the
+// token query parameter), for the admin connections view.
+func (h *Hub) SetIdentify(fn func(r *http.Request) string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.identify = fn
+}
+
+// Clients returns the connected clients ordered by connect time.
+func (h *Hub) Clients() []ClientInfo {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	out := make([]ClientInfo, 0, len(h.clients))
+	for _, info := range h.clients {
+		s := &info.stats
+		out = append(out, ClientInfo{
+			ID:               s.id,
+			ConnectedAt:      s.connectedAt,
+			RemoteAddr:       s.remoteAddr,
+			UserAgent:        s.userAgent,
+			User:             s.user,
+			Viewer:           info.viewer.String(),
+			Admin:            info.viewer == privacy.ViewerAdmin,
+			Tenant:           info.tenant.Key(),
+			Profile:          info.profile.String(),
+			MessagesSent:     s.sent.Load(),
+			BytesSent:        s.bytes.Load(),
+			MessagesReceived: s.received.Load(),
+			WriteErrors:      s.writeErrors.Load(),
+			LastActivity:     time.Unix(0, s.lastActive.Load()),
+		})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out
+}
+
+// Disconnect closes the client with the given ID and reports whether it was connected.
+// The connection is dropped without waiting for a close handshake, so stuck clients
+// go away immediately.
+func (h *Hub) Disconnect(id uint64) bool {
+	h.mu.Lock()
+	var conn *websocket.Conn
+	for c, info := range h.clients {
+		if info.stats.id == id {
+			conn = c
+			delete(h.clients, c)
+			break
+		}
+	}
+	h.mu.Unlock()
+	if conn == nil {
+		return false
+	}
+	_ = conn.CloseNow()
+	return true
+}
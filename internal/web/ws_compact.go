@@ -0,0 +1,199 @@
+package web
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/dbehnke/allstar-nexus/internal/core"
+)
+
+// PayloadProfile selects how much detail a websocket client receives. It is chosen per
+// client at upgrade time with /ws?profile=compact.
+type PayloadProfile uint8
+
+const (
+	// ProfileFull sends payloads exactly as the dashboard expects them.
+	ProfileFull PayloadProfile = iota
+	// ProfileCompact is for mobile/kiosk clients on metered connections: descriptions,
+	// locations, notes, human-readable strings and per-link TX history timestamps are
+	// dropped, times are unix seconds and data keys are abbreviated. Message types and
+	// the envelope are unchanged so clients can share one dispatcher.
+	ProfileCompact
+)
+
+// ParseProfile maps the ?profile= query value to a profile; unknown values mean full.
+func ParseProfile(s string) PayloadProfile {
+	if strings.EqualFold(strings.TrimSpace(s), "compact") {
+		return ProfileCompact
+	}
+	return ProfileFull
+}
+
+func (p PayloadProfile) String() string {
+	if p == ProfileCompact {
+		return "compact"
+	}
+	return "full"
+}
+
+// encodeMessage marshals one envelope for profile.
+func encodeMessage(profile PayloadProfile, messageType string, data any) []byte {
+	if profile == ProfileCompact {
+		data = compactData(data)
+	}
+	b, _ := json.Marshal(messageEnvelope{MessageType: messageType, Data: data, Timestamp: time.Now().UnixMilli()})
+	return b
+}
+
+type compactState struct {
+	Node         int           `json:"n"`
+	RxKeyed      bool          `json:"rx,omitempty"`
+	TxKeyed      bool          `json:"tx,omitempty"`
+	Links        []int         `json:"l"`
+	Detailed     []compactLink `json:"ld,omitempty"`
+	UptimeSec    int           `json:"up,omitempty"`
+	StateVersion int64         `json:"sv"`
+	Heartbeat    int64         `json:"hb,omitempty"`
+	NumLinks     int           `json:"nl,omitempty"`
+	NumALinks    int           `json:"na,omitempty"`
+	Parrot       []int         `json:"pm,omitempty"` // source nodes in parrot mode
+}
+
+type compactLink struct {
+	Node      int    `json:"n"`
+	LocalNode int    `json:"ln,omitempty"`
+	Since     int64  `json:"cs,omitempty"`
+	Keyed     bool   `json:"k,omitempty"`
+	TxSeconds int    `json:"t,omitempty"`
+	Direction string `json:"d,omitempty"`
+	Mode      string `json:"m,omitempty"`
+	Callsign  string `json:"c,omitempty"`
+	Health    string `json:"h,omitempty"` // good / degraded / down
+}
+
+type compactTalker struct {
+	At       int64  `json:"at"`
+	Kind     string `json:"k"`
+	Node     int    `json:"n,omitempty"`
+	Source   int    `json:"ln,omitempty"`
+	Callsign string `json:"c,omitempty"`
+	Duration int    `json:"d,omitempty"`
+}
+
+type compactAdjacent struct {
+	Keyed     bool   `json:"k,omitempty"`
+	Tx        bool   `json:"tx,omitempty"`
+	Pending   bool   `json:"pu,omitempty"`
+	TxSeconds int    `json:"t,omitempty"`
+	Callsign  string `json:"c,omitempty"`
+	Mode      string `json:"m,omitempty"`
+}
+
+type compactKeying struct {
+	Source    int                     `json:"s"`
+	Adjacent  map[int]compactAdjacent `json:"a"`
+	TxKeyed   bool                    `json:"tx,omitempty"`
+	RxKeyed   bool                    `json:"rx,omitempty"`
+	NumLinks  int                     `json:"nl,omitempty"`
+	NumALinks int                     `json:"na,omitempty"`
+}
+
+type compactKeyingEvent struct {
+	Type     string `json:"ty"`
+	Source   int    `json:"s"`
+	Node     int    `json:"n"`
+	Duration int    `json:"d,omitempty"`
+}
+
+type compactLinkTx struct {
+	Node      int    `json:"n"`
+	Kind      string `json:"k"`
+	At        int64  `json:"at"`
+	TxSeconds int    `json:"t"`
+}
+
+func unixOrZero(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.Unix()
+}
+
+// compactData trims the payload types sent to compact clients; other data is unchanged.
+func compactData(data any) any {
+	switch d := data.(type) {
+	case core.NodeState:
+		out := compactState{
+			Node: d.NodeID, RxKeyed: d.RxKeyed, TxKeyed: d.TxKeyed, Links: d.Links,
+			Detailed: compactLinks(d.LinksDetailed), UptimeSec: d.UptimeSec,
+			StateVersion: d.StateVersion, Heartbeat: d.Heartbeat, NumLinks: d.NumLinks, NumALinks: d.NumALinks,
+		}
+		if out.Links == nil {
+			out.Links = []int{}
+		}
+		for _, pm := range d.ParrotModes {
+			if pm.Enabled {
+				out.Parrot = append(out.Parrot, pm.Node)
+			}
+		}
+		sort.Ints(out.Parrot)
+		return out
+	case []core.LinkInfo:
+		return compactLinks(d)
+	case core.TalkerEvent:
+		return compactTalkerEvent(d)
+	case []core.TalkerEvent:
+		out := make([]compactTalker, len(d))
+		for i, evt := range d {
+			out[i] = compactTalkerEvent(evt)
+		}
+		return out
+	case core.SourceNodeKeyingUpdate:
+		out := compactKeying{Source: d.SourceNodeID, Adjacent: make(map[int]compactAdjacent, len(d.AdjacentNodes)),
+			TxKeyed: d.TxKeyed, RxKeyed: d.RxKeyed, NumLinks: d.NumLinks, NumALinks: d.NumALinks}
+		for id, n := range d.AdjacentNodes {
+			out.Adjacent[id] = compactAdjacent{Keyed: n.IsKeyed, Tx: n.IsTransmitting, Pending: n.PendingUnkey,
+				TxSeconds: n.TotalTxSeconds, Callsign: n.Callsign, Mode: n.Mode}
+		}
+		return out
+	case core.SourceNodeKeyingEvent:
+		return compactKeyingEvent{Type: d.Type, Source: d.SourceNodeID, Node: d.NodeID, Duration: d.DurationSec}
+	case core.LinkTxEvent:
+		return compactLinkTxEvent(d)
+	case []core.LinkTxEvent:
+		out := make([]compactLinkTx, len(d))
+		for i, evt := range d {
+			out[i] = compactLinkTxEvent(evt)
+		}
+		return out
+	}
+	return data
+}
+
+func compactLinks(links []core.LinkInfo) []compactLink {
+	if len(links) == 0 {
+		return nil
+	}
+	out := make([]compactLink, len(links))
+	for i, li := range links {
+		out[i] = compactLink{
+			Node: li.Node, LocalNode: li.LocalNode, Since: unixOrZero(li.ConnectedSince),
+			Keyed: li.IsKeyed || li.CurrentTx, TxSeconds: li.TotalTxSeconds,
+			Direction: li.Direction, Mode: li.Mode, Callsign: li.NodeCallsign,
+		}
+		if li.Health != nil {
+			out[i].Health = li.Health.Status
+		}
+	}
+	return out
+}
+
+func compactTalkerEvent(evt core.TalkerEvent) compactTalker {
+	return compactTalker{At: unixOrZero(evt.At), Kind: evt.Kind, Node: evt.Node, Source: evt.Source, Callsign: evt.Callsign, Duration: evt.Duration}
+}
+
+func compactLinkTxEvent(evt core.LinkTxEvent) compactLinkTx {
+	return compactLinkTx{Node: evt.Node, Kind: evt.Kind, At: unixOrZero(evt.At), TxSeconds: evt.TotalTxSeconds}
+}
@@ -4,17 +4,23 @@ import (
 	"context"
 	"embed"
 	"flag"
+	"fmt"
 	"io/fs"
 	"log"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"slices"
+	"strings"
+	"sync"
 	"syscall"
 	"time"
 
 	"github.com/dbehnke/allstar-nexus/backend/api"
 	"github.com/dbehnke/allstar-nexus/backend/auth"
 	"github.com/dbehnke/allstar-nexus/backend/config"
+	"github.com/dbehnke/allstar-nexus/backend/database"
 	"github.com/dbehnke/allstar-nexus/backend/gamification"
 	"github.com/dbehnke/allstar-nexus/backend/middleware"
 	"github.com/dbehnke/allstar-nexus/backend/models"
@@ -22,11 +28,22 @@ import (
 	"github.com/dbehnke/allstar-nexus/backend/server"
 	"github.com/dbehnke/allstar-nexus/internal/ami"
 	"github.com/dbehnke/allstar-nexus/internal/astdb"
+	"github.com/dbehnke/allstar-nexus/internal/asterisklog"
 	"github.com/dbehnke/allstar-nexus/internal/core"
+	"github.com/dbehnke/allstar-nexus/internal/discordbot"
+	"github.com/dbehnke/allstar-nexus/internal/netprobe"
+	"github.com/dbehnke/allstar-nexus/internal/notify"
+	"github.com/dbehnke/allstar-nexus/internal/privacy"
+	"github.com/dbehnke/allstar-nexus/internal/rptconf"
+	"github.com/dbehnke/allstar-nexus/internal/sdnotify"
+	"github.com/dbehnke/allstar-nexus/internal/telegrambot"
+	"github.com/dbehnke/allstar-nexus/internal/tenant"
+	"github.com/dbehnke/allstar-nexus/internal/textnode"
+	"github.com/dbehnke/allstar-nexus/internal/timefmt"
+	"github.com/dbehnke/allstar-nexus/internal/timesync"
+	"github.com/dbehnke/allstar-nexus/internal/tts"
 	"github.com/dbehnke/allstar-nexus/internal/web"
 	"go.uber.org/zap"
-	"gorm.io/driver/sqlite"
-	"gorm.io/gorm"
 
 	_ "modernc.org/sqlite"
 )
@@ -40,12 +57,17 @@ func main() {
 	// Command-line flags
 	configFile := flag.String("config", "", "Path to config file (default: search ./config.yaml, data/config.yaml, etc.)")
 	force := flag.Bool("force", false, "When set, ignore config validation errors and continue startup")
+	validate := flag.Bool("validate", false, "Run the readiness check (same as the `check` subcommand) and exit")
+	migrateOnly := flag.Bool("migrate-only", false, "Apply pending database migrations and exit")
+	migrateDown := flag.Int("migrate-down", -1, "Revert database migrations above this schema version and exit")
 	flag.Usage = func() {
 		// Minimal usage with subcommands
 		_, _ = os.Stderr.WriteString("Allstar Nexus\n")
 		_, _ = os.Stderr.WriteString("\nUsage:\n")
 		_, _ = os.Stderr.WriteString("  allstar-nexus [flags]\n")
 		_, _ = os.Stderr.WriteString("  allstar-nexus config validate [--config path]\n")
+		_, _ = os.Stderr.WriteString("  allstar-nexus [--config path] check   (readiness report: config, port, DB, AMI)\n")
+		_, _ = os.Stderr.WriteString("  allstar-nexus [--config path] init    (interactive setup wizard)\n")
 		_, _ = os.Stderr.WriteString("\nFlags:\n")
 		flag.PrintDefaults()
 	}
@@ -63,6 +85,21 @@ func main() {
 		return
 	}
 
+	// `init` subcommand: interactive setup wizard (writes config.yaml and the first superadmin)
+	if flag.Arg(0) == "init" {
+		_ = flag.CommandLine.Parse(flag.Args()[1:])
+		os.Exit(runInit(*configFile, os.Stdin, os.Stdout))
+	}
+
+	// `check` subcommand (or --validate): print a readiness report without starting the server
+	if *validate || flag.Arg(0) == "check" {
+		if flag.Arg(0) == "check" {
+			// Allow flags after the subcommand: `allstar-nexus check --config path`
+			_ = flag.CommandLine.Parse(flag.Args()[1:])
+		}
+		os.Exit(runCheck(*configFile))
+	}
+
 	// Load configuration
 	// Fail-fast: validate YAML and basic structure before full startup unless --force is provided.
 	if err := config.Validate(*configFile); err != nil {
@@ -79,38 +116,72 @@ func main() {
 	logger, _ := zap.NewProduction()
 	defer func() { _ = logger.Sync() }()
 
-	// Initialize GORM database with modernc.org/sqlite (pure Go, no CGO)
-	gormDB, err := gorm.Open(sqlite.New(sqlite.Config{
-		DriverName: "sqlite",
-		DSN:        cfg.DBPath,
-	}), &gorm.Config{})
+	// Bind the listener before opening the database so orchestrators can probe startup
+	// (/api/health/startup) while migrations run; the full router is installed at the end.
+	addr := ":" + cfg.Port
+	startup := &server.Startup{}
+	srv := &http.Server{Addr: addr, Handler: startup, ReadTimeout: 10 * time.Second, WriteTimeout: 15 * time.Second}
+	migrateMode := *migrateOnly || *migrateDown >= 0
+	if !migrateMode {
+		ln, err := net.Listen("tcp", addr)
+		if err != nil {
+			log.Fatalf("server error: %v", err)
+		}
+		go func() {
+			log.Printf("Allstar Nexus starting on %s (env=%s) build=%s", addr, cfg.Env, cfg.BuildTime)
+			if err := srv.Serve(ln); err != nil && err != http.ErrServerClosed {
+				log.Fatalf("server error: %v", err)
+			}
+		}()
+	}
+
+	// Initialize GORM database with modernc.org/sqlite (pure Go, no CGO). Everything in the
+	// process shares this one pool; WAL, busy_timeout and foreign keys are set per connection.
+	dbOpts := database.DefaultOptions()
+	if cfg.DBBusyTimeout > 0 {
+		dbOpts.BusyTimeout = cfg.DBBusyTimeout
+	}
+	appDB, err := database.OpenWithOptions(cfg.DBPath, dbOpts)
 	if err != nil {
 		log.Fatalf("GORM database open error: %v", err)
 	}
-
-	// Set PRAGMA settings for optimized write performance
+	gormDB := appDB.DB
 	sqlDB, err := gormDB.DB()
 	if err != nil {
 		log.Fatalf("failed to get sql.DB from GORM: %v", err)
 	}
-	if _, err := sqlDB.Exec("PRAGMA journal_mode=WAL;"); err != nil {
-		logger.Warn("failed to set journal_mode=WAL", zap.Error(err))
+	// Versioned schema migrations (see backend/database/migrations.go)
+	primaryNode := 0
+	if len(cfg.Nodes) > 0 {
+		primaryNode = cfg.Nodes[0].NodeID
+	}
+	migrator, err := database.NewMigrator(gormDB, database.Migrations(database.MigrationOptions{PrimaryNode: primaryNode}))
+	if err != nil {
+		log.Fatalf("database migrations: %v", err)
+	}
+	if *migrateDown >= 0 {
+		reverted, err := migrator.Down(context.Background(), *migrateDown)
+		for _, m := range reverted {
+			logger.Info("reverted migration", zap.Int("version", m.Version), zap.String("description", m.Description))
+		}
+		if err != nil {
+			log.Fatalf("database migration error: %v", err)
+		}
+		return
+	}
+	applied, err := migrator.Up(context.Background())
+	for _, m := range applied {
+		logger.Info("applied migration", zap.Int("version", m.Version), zap.String("description", m.Description))
 	}
-	if _, err := sqlDB.Exec("PRAGMA synchronous=NORMAL;"); err != nil {
-		logger.Warn("failed to set synchronous=NORMAL", zap.Error(err))
+	if err != nil {
+		log.Fatalf("database migration error: %v", err)
 	}
-	if err := gormDB.AutoMigrate(
-		&models.User{},
-		&models.TransmissionLog{},
-		&models.NodeInfo{},
-		&models.LinkStat{},
-		&models.CallsignProfile{},
-		&models.LevelConfig{},
-		&models.XPActivityLog{},
-		&models.TallyState{},
-	); err != nil {
-		log.Fatalf("GORM auto-migrate error: %v", err)
+	if *migrateOnly {
+		version, _ := migrator.Version(context.Background())
+		logger.Info("database migrated", zap.Int("schema_version", version))
+		return
 	}
+	startup.MarkMigrated()
 	logger.Info("GORM database initialized successfully")
 
 	// Repository variables (declare so closures later can access)
@@ -123,9 +194,44 @@ func main() {
 	txLogRepo = repository.NewTransmissionLogRepository(gormDB)
 	nodeInfoRepo = repository.NewNodeInfoRepository(gormDB)
 
+	// Key legacy transmission rows into the dedup ledger (no-op once reconciled)
+	{
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+		merged, err := txLogRepo.ReconcileLedger(ctx)
+		cancel()
+		if err != nil {
+			logger.Warn("transmission ledger reconciliation failed", zap.Error(err))
+		} else if merged > 0 {
+			logger.Info("merged duplicate transmission log rows", zap.Int64("merged", merged))
+		}
+	}
+
+	// Restore text node IDs before AMI connects so persisted history keeps resolving,
+	// then persist any new mappings
+	{
+		textNodeRepo := repository.NewTextNodeRepo(gormDB)
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		entries, err := textNodeRepo.Entries(ctx)
+		cancel()
+		if err != nil {
+			logger.Warn("failed to load text node registry", zap.Error(err))
+		} else {
+			skipped := textnode.Default.Load(entries)
+			logger.Info("text node registry loaded", zap.Int("entries", len(entries)-skipped), zap.Int("skipped", skipped))
+		}
+		textnode.Default.SetStore(textNodeRepo)
+	}
+
 	// Initialize astdb downloader with node info repository
 	astdbDownloader := astdb.NewDownloader(cfg.AstDBURL, cfg.AstDBPath, cfg.AstDBUpdateHours, logger)
 	astdbDownloader.SetNodeInfoRepository(nodeInfoRepo)
+	if len(cfg.AstDBSources) > 0 {
+		sources := make([]astdb.Source, 0, len(cfg.AstDBSources))
+		for _, s := range cfg.AstDBSources {
+			sources = append(sources, astdb.Source{Name: s.Name, URL: s.URL, Path: s.Path})
+		}
+		astdbDownloader.SetSources(sources)
+	}
 
 	if err := astdbDownloader.EnsureExists(); err != nil {
 		logger.Warn("failed to download/import astdb, node lookup may not work", zap.Error(err))
@@ -141,18 +247,104 @@ func main() {
 		cancel()
 	}
 
+	// Node lookup: private node registry first, then astdb
+	nodeLookup := core.NewNodeLookupService(cfg.AstDBPath)
+	nodeLookup.SetNodeInfoRepository(nodeInfoRepo)
+	if fb := cfg.NodeLookupFallback; fb.Enabled {
+		nodeLookup.EnableWebFallback(astdb.NewWebLookup(fb.URL), fb.CacheTTL, fb.NegativeTTL, fb.RequestsPerMinute)
+		logger.Info("node lookup web fallback enabled", zap.Int("requests_per_minute", fb.RequestsPerMinute))
+	}
+
+	// Multi-tenant hub hosting: nil (single hub) unless tenants are configured
+	tenants, err := tenantRegistry(cfg.Tenants)
+	if err != nil {
+		log.Fatalf("invalid tenants config: %v", err)
+	}
+	if tenants != nil {
+		logger.Info("multi-tenant hosting enabled", zap.Int("tenants", len(cfg.Tenants)))
+	}
+
 	// API setup (use GORM for all repos now)
 	apiLayer := api.New(gormDB, cfg.JWTSecret, cfg.TokenTTL)
+	apiLayer.SetTenants(tenants)
 	apiLayer.SetAstDBPath(cfg.AstDBPath)
+	apiLayer.SetAstDBDownloader(astdbDownloader)
 	apiLayer.SetBuildInfo(buildVersion, buildTime)
+	apiLayer.SetBranding(api.Branding{
+		ClubName:    cfg.Branding.ClubName,
+		Title:       cfg.Title,
+		Subtitle:    cfg.Subtitle,
+		LogoURL:     cfg.Branding.LogoURL,
+		Colors:      cfg.Branding.Colors,
+		FooterLinks: cfg.Branding.FooterLinks,
+	}, repository.NewSettingsRepo(gormDB), cfg.DataDir)
 	mux := http.NewServeMux()
 	mux.HandleFunc("/api/health", api.Health)
+	mux.HandleFunc("/api/health/startup", startup.Probe)
 	mux.HandleFunc("/api/version", apiLayer.Version)
 	mux.HandleFunc("/api/status", apiLayer.Status)
 	mux.HandleFunc("/api/dashboard/summary", apiLayer.DashboardSummary)
-	limiter := middleware.RateLimiter(cfg.AuthRateLimitRPM)
-	mux.Handle("/api/auth/register", limiter(http.HandlerFunc(apiLayer.Register)))
-	mux.Handle("/api/auth/login", limiter(http.HandlerFunc(apiLayer.Login)))
+	mux.HandleFunc("/api/idle-status", apiLayer.IdleStatus)
+	// Rate limiting: per-route policies from config, falling back to auth_rpm / public_stats_rpm
+	// Privacy policy shared by REST handlers and the WebSocket hub
+	ipMask, err := privacy.ParseMaskStyle(cfg.Privacy.IPMasking)
+	if err != nil {
+		logger.Warn("invalid privacy.ip_masking; using partial", zap.Error(err))
+		ipMask = privacy.MaskPartial
+	}
+	privacyPolicy := privacy.Policy{
+		IPMask:                ipMask,
+		HideAnonCallsigns:     !cfg.Privacy.AnonShowCallsigns,
+		HideAnonTalkerHistory: !cfg.Privacy.AnonTalkerHistory,
+	}
+	apiLayer.SetPrivacyPolicy(privacyPolicy)
+	locale := timefmt.Locale{Tag: cfg.Locale}
+	if cfg.Timezone != "" {
+		if loc, err := time.LoadLocation(cfg.Timezone); err != nil {
+			logger.Warn("invalid timezone; using system local time", zap.String("timezone", cfg.Timezone), zap.Error(err))
+		} else {
+			locale.Location = loc
+		}
+	}
+	apiLayer.SetLocale(locale)
+	apiLayer.SetCallsignData(repository.NewCallsignDataRepo(gormDB))
+	nodeAnnotationRepo := repository.NewNodeAnnotationRepo(gormDB)
+	apiLayer.SetNodeAnnotations(nodeAnnotationRepo)
+	localNodeRepo := repository.NewLocalNodeRepo(gormDB)
+	if len(cfg.LocalNodes) > 0 {
+		seed := make([]models.LocalNode, 0, len(cfg.LocalNodes))
+		for _, n := range cfg.LocalNodes {
+			seed = append(seed, models.LocalNode{Node: n.Node, Callsign: strings.ToUpper(n.Callsign), Description: n.Description, Location: n.Location})
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		if written, err := localNodeRepo.Seed(ctx, seed); err != nil {
+			logger.Warn("failed to seed local node registry", zap.Error(err))
+		} else if written > 0 {
+			logger.Info("local node registry seeded from config", zap.Int("written", written))
+		}
+		cancel()
+	}
+	apiLayer.SetLocalNodes(localNodeRepo, nodeLookup)
+	apiLayer.SetNodeLabels(repository.NewNodeLabelRepo(gormDB), nodeLookup)
+	watchlistRepo := repository.NewWatchlistRepo(gormDB)
+	apiLayer.SetWatchlist(watchlistRepo, nil)
+
+	rateLimits := middleware.NewRateLimits(cfg.RateLimits.MaxKeys, rateLimitPolicies(cfg.RateLimits.Routes))
+	// Public read endpoints are cached briefly; tally completion and link persistence invalidate them
+	var responseCache *middleware.ResponseCache
+	if cfg.ResponseCache.Enabled {
+		responseCache = middleware.NewResponseCache(cfg.ResponseCache.MaxEntries, cfg.ResponseCache.Routes)
+	}
+	// Opted-out callsigns are hidden from gamification displays and talker webhooks
+	optOuts := gamification.NewOptOuts(cfg.Gamification.OptOutMode)
+	apiLayer.SetGamificationOptOuts(repository.NewGamificationOptOutRepo(gormDB), optOuts, func() { responseCache.Invalidate("gamification") })
+	cacheLinkStats := func(route string, h http.HandlerFunc) http.Handler {
+		return responseCache.For(route, "link_stats", 10*time.Second)(h)
+	}
+	authPolicy := middleware.RatePolicy{RequestsPerMinute: cfg.AuthRateLimitRPM}
+	publicPolicy := middleware.RatePolicy{RequestsPerMinute: cfg.PublicStatsRateLimitRPM}
+	mux.Handle("/api/auth/register", rateLimits.For("/api/auth/register", authPolicy)(http.HandlerFunc(apiLayer.Register)))
+	mux.Handle("/api/auth/login", rateLimits.For("/api/auth/login", authPolicy)(http.HandlerFunc(apiLayer.Login)))
 
 	// Repositories for middleware loaders
 	userRepo := repository.NewUserRepo(gormDB)
@@ -167,40 +359,191 @@ func main() {
 	}
 
 	authMW := middleware.Auth(cfg.JWTSecret, userLoader)
-	adminMW := middleware.RequireRole("admin", "superadmin")
+	adminMW := middleware.RequireAdmin(tenants, false)
+	tenantAdminMW := middleware.RequireAdmin(tenants, true) // for handlers confined to the request's tenant
+	superMW := middleware.RequireRole("superadmin")
 
 	mux.Handle("/api/me", authMW(http.HandlerFunc(apiLayer.Me)))
+	if tenants != nil {
+		mux.HandleFunc("/api/tenants", apiLayer.TenantList)
+	}
 	mux.Handle("/api/admin/summary", authMW(adminMW(http.HandlerFunc(apiLayer.AdminSummary))))
+	mux.Handle("/api/admin/parrot", authMW(adminMW(http.HandlerFunc(apiLayer.ParrotMode))))
+	mux.Handle("/api/iax-status", authMW(http.HandlerFunc(apiLayer.IAXStatus)))
+	mux.Handle("/api/admin/ami/command", authMW(superMW(http.HandlerFunc(apiLayer.AMICommand))))
+	mux.Handle("/api/admin/ws-clients", authMW(adminMW(http.HandlerFunc(apiLayer.AdminWSClients))))
+	mux.Handle("/api/admin/ws-bench", authMW(superMW(http.HandlerFunc(apiLayer.AdminWSBench))))
+	mux.Handle("/api/admin/announcements", authMW(adminMW(http.HandlerFunc(apiLayer.AdminAnnouncements))))
+	mux.Handle("/api/admin/telegram/link", authMW(adminMW(http.HandlerFunc(apiLayer.TelegramLink))))
+	mux.Handle("/api/admin/time-sync", authMW(adminMW(http.HandlerFunc(apiLayer.TimeSyncStatus))))
+	mux.Handle("/api/admin/asterisk-log", authMW(adminMW(http.HandlerFunc(apiLayer.AsteriskLogEntries))))
+	mux.Handle("/api/admin/astdb/status", authMW(adminMW(http.HandlerFunc(apiLayer.AstDBStatus))))
+	mux.Handle("/api/admin/astdb/refresh", authMW(adminMW(http.HandlerFunc(apiLayer.AstDBRefresh))))
+	mux.Handle("/api/admin/data-deletion", authMW(adminMW(http.HandlerFunc(apiLayer.CallsignDataDeletion))))
+	mux.Handle("/api/admin/local-nodes", authMW(adminMW(http.HandlerFunc(apiLayer.AdminLocalNodes))))
+	mux.Handle("/api/admin/node-labels", authMW(adminMW(http.HandlerFunc(apiLayer.AdminNodeLabels))))
+	mux.Handle("/api/admin/gamification/opt-outs", authMW(adminMW(http.HandlerFunc(apiLayer.AdminGamificationOptOuts))))
+	mux.Handle("/api/admin/gamification/tally-runs", authMW(adminMW(http.HandlerFunc(apiLayer.AdminTallyRuns))))
+	mux.Handle("/api/admin/gamification/tally-now", authMW(adminMW(http.HandlerFunc(apiLayer.AdminTallyNow))))
+	mux.Handle("/api/admin/gamification/tally-progress", authMW(adminMW(http.HandlerFunc(apiLayer.AdminTallyProgress))))
+	mux.Handle("/api/gamification/opt-out", authMW(http.HandlerFunc(apiLayer.GamificationOptOut)))
+	mux.Handle("/api/admin/watchlist", authMW(adminMW(http.HandlerFunc(apiLayer.AdminWatchlist))))
+	mux.Handle("/api/admin/nodes/notes", authMW(adminMW(http.HandlerFunc(apiLayer.NodeNotesList))))
+	mux.Handle("/api/admin/nodes/{id}/notes", authMW(adminMW(http.HandlerFunc(apiLayer.NodeNotes))))
+	mux.Handle("/api/admin/nodes/{id}/telemetry", authMW(tenantAdminMW(http.HandlerFunc(apiLayer.NodeTelemetry))))
+	mux.Handle("/api/admin/branding", authMW(adminMW(http.HandlerFunc(apiLayer.AdminBranding))))
+	mux.Handle("/api/admin/branding/logo", authMW(adminMW(http.HandlerFunc(apiLayer.AdminBrandingLogo))))
 
-	// Node lookup and talker log APIs - can be public or require auth based on config
-	if cfg.AllowAnonDashboard {
-		publicLimiter := middleware.RateLimiter(cfg.PublicStatsRateLimitRPM)
-		mux.Handle("/api/node-lookup", publicLimiter(http.HandlerFunc(apiLayer.NodeLookup)))
-		mux.Handle("/api/talker-log", publicLimiter(http.HandlerFunc(apiLayer.TalkerLog)))
-	} else {
-		mux.Handle("/api/node-lookup", authMW(http.HandlerFunc(apiLayer.NodeLookup)))
-		mux.Handle("/api/talker-log", authMW(http.HandlerFunc(apiLayer.TalkerLog)))
+	// Node actions: users claim their node, admins approve, then the user may run the
+	// whitelisted actions on it. The relay is attached once AMI is connected.
+	var nodeClaimRepo *repository.NodeClaimRepo
+	if cfg.NodeActions.Enabled {
+		nodeClaimRepo = repository.NewNodeClaimRepo(gormDB)
+		apiLayer.SetNodeActions(nodeClaimRepo, nil)
+		actionPolicy := middleware.RatePolicy{RequestsPerMinute: cfg.NodeActions.RateLimitRPM, Burst: 2, KeyBy: middleware.KeyByToken}
+		mux.Handle("/api/me/node-claims", authMW(http.HandlerFunc(apiLayer.MyNodeClaims)))
+		mux.Handle("/api/me/nodes/{id}/actions", rateLimits.For("/api/me/nodes/actions", actionPolicy)(authMW(http.HandlerFunc(apiLayer.MyNodeAction))))
+		mux.Handle("/api/admin/node-claims", authMW(adminMW(http.HandlerFunc(apiLayer.AdminNodeClaims))))
 	}
 
-	// RPT and Voter stats APIs - require authentication
-	mux.Handle("/api/rpt-stats", authMW(http.HandlerFunc(apiLayer.RPTStats)))
-	mux.Handle("/api/voter-stats", authMW(http.HandlerFunc(apiLayer.VoterStats)))
+	// Branding is always public: the login page and anonymous dashboard both need it.
+	mux.Handle("/api/branding", rateLimits.For("/api/branding", publicPolicy)(http.HandlerFunc(apiLayer.GetBranding)))
+	if cfg.AstDBServe {
+		mux.Handle("/astdb.txt", rateLimits.For("/astdb.txt", publicPolicy)(http.HandlerFunc(apiLayer.AstDBFile)))
+	}
+	mux.Handle("/api/branding/logo", rateLimits.For("/api/branding/logo", publicPolicy)(http.HandlerFunc(apiLayer.BrandingLogo)))
 
-	// Poll-now endpoint - authenticated by default; if anon dashboard is allowed, rate-limit it
+	// Stat endpoints: public when the anonymous dashboard is allowed, otherwise signed-in
+	// users only; endpoint_access overrides either per route.
+	defaultAccess := middleware.AccessUser
 	if cfg.AllowAnonDashboard {
-		publicLimiter := middleware.RateLimiter(cfg.PublicStatsRateLimitRPM)
-		mux.Handle("/api/poll-now", publicLimiter(http.HandlerFunc(apiLayer.PollNow)))
-	} else {
-		mux.Handle("/api/poll-now", authMW(http.HandlerFunc(apiLayer.PollNow)))
+		defaultAccess = middleware.AccessAnonymous
 	}
+	statRoutes := middleware.NewAccessRouter(mux, middleware.AccessRouterOptions{
+		Default:  defaultAccess,
+		Policies: cfg.EndpointAccess,
+		Auth:     authMW,
+		Admin:    adminMW,
+		Public: func(route string) func(http.Handler) http.Handler {
+			return rateLimits.For(route, publicPolicy)
+		},
+	})
+	statRoutes.Handle("/api/node-lookup", http.HandlerFunc(apiLayer.NodeLookup))
+	statRoutes.Handle("/api/talker-log", http.HandlerFunc(apiLayer.TalkerLog))
+	statRoutes.Handle("/api/talker-sessions", http.HandlerFunc(apiLayer.TalkerSessions))
 
-	if cfg.AllowAnonDashboard {
-		publicLimiter := middleware.RateLimiter(cfg.PublicStatsRateLimitRPM)
-		mux.Handle("/api/link-stats", publicLimiter(http.HandlerFunc(apiLayer.LinkStatsHandler)))
-		mux.Handle("/api/link-stats/top", publicLimiter(http.HandlerFunc(apiLayer.TopLinkStatsHandler)))
-	} else {
-		mux.Handle("/api/link-stats", authMW(http.HandlerFunc(apiLayer.LinkStatsHandler)))
-		mux.Handle("/api/link-stats/top", authMW(http.HandlerFunc(apiLayer.TopLinkStatsHandler)))
+	// RPT and Voter stats APIs - require authentication unless opened per endpoint
+	statRoutes.HandleDefault("/api/rpt-stats", middleware.AccessUser, http.HandlerFunc(apiLayer.RPTStats))
+	statRoutes.HandleDefault("/api/voter-stats", middleware.AccessUser, http.HandlerFunc(apiLayer.VoterStats))
+
+	// Poll-now is rate limited when public
+	statRoutes.Handle("/api/poll-now", http.HandlerFunc(apiLayer.PollNow))
+	statRoutes.Handle("/api/poll-status", http.HandlerFunc(apiLayer.PollStatusHandler))
+	statRoutes.Handle("/api/topology", http.HandlerFunc(apiLayer.TopologyHandler))
+	statRoutes.Handle("/api/topology/history", http.HandlerFunc(apiLayer.TopologyHistoryHandler))
+
+	statRoutes.Handle("/api/link-stats", cacheLinkStats("/api/link-stats", apiLayer.LinkStatsHandler))
+	statRoutes.Handle("/api/link-stats/top", cacheLinkStats("/api/link-stats/top", apiLayer.TopLinkStatsHandler))
+
+	statRoutes.Handle("/api/node-health", http.HandlerFunc(apiLayer.NodeHealthHandler))
+	statRoutes.Handle("/api/link-quality", http.HandlerFunc(apiLayer.LinkQualityHandler))
+
+	if cfg.Widget.Enabled {
+		apiLayer.SetWidget(time.Duration(cfg.Widget.RefreshSeconds) * time.Second)
+		widgetPolicy := middleware.RatePolicy{RequestsPerMinute: cfg.Widget.RPM, KeyBy: middleware.KeyByIP}
+		mux.Handle("/widget", rateLimits.For("/widget", widgetPolicy)(http.HandlerFunc(apiLayer.Widget)))
+		mux.Handle("/api/widget/state", rateLimits.For("/api/widget/state", widgetPolicy)(
+			responseCache.For("/api/widget/state", "widget", 2*time.Second)(http.HandlerFunc(apiLayer.WidgetState))))
+	}
+
+	// Activity feed: notable events from the talker stream and tally level-ups as Atom
+	var activityFeed *core.ActivityFeed
+	if af := cfg.ActivityFeed; af.Enabled {
+		activityFeed = core.NewActivityFeed(core.ActivityFeedOptions{
+			Include:            af.Include,
+			LongTx:             af.LongTx,
+			SessionGap:         af.SessionGap,
+			QSOMinOvers:        af.QSOMinOvers,
+			NetMinParticipants: af.NetMinParticipants,
+			MaxEntries:         af.MaxEntries,
+		})
+		activityFeed.Start(30 * time.Second)
+		defer activityFeed.Stop()
+		apiLayer.SetActivityFeed(activityFeed)
+		mux.Handle("/feed.atom", rateLimits.For("/feed.atom", publicPolicy)(
+			responseCache.For("/feed.atom", "activity_feed", 30*time.Second)(http.HandlerFunc(apiLayer.ActivityFeedAtom))))
+		logger.Info("activity feed enabled", zap.Strings("include", af.Include), zap.Duration("session_gap", af.SessionGap))
+	}
+
+	// Alert delivery: generic webhooks plus the chat/push providers under notifications
+	notifier := newNotifier(cfg.Notifications, cfg.Title)
+	if names := notifier.Names(); len(names) > 0 {
+		logger.Info("notification providers configured", zap.Strings("providers", names))
+	}
+
+	// Asterisk log: classify app_rpt / channel driver warnings and alert on selected classes
+	var asteriskLog *asterisklog.Monitor
+	if al := cfg.AsteriskLog; al.Enabled {
+		rules := make([]asterisklog.Rule, len(al.Rules))
+		for i, r := range al.Rules {
+			rules[i] = asterisklog.Rule{Category: r.Category, Pattern: r.Pattern}
+		}
+		m, err := asterisklog.NewMonitor(al.Path, asterisklog.Options{
+			MaxEntries:      al.MaxEntries,
+			Rules:           rules,
+			AlertCategories: al.AlertCategories,
+			AlertCooldown:   al.AlertCooldown,
+		})
+		if err == nil {
+			err = m.Start()
+		}
+		if err != nil {
+			logger.Warn("asterisk log monitor disabled", zap.String("path", al.Path), zap.Error(err))
+		} else {
+			if al.WebhookURL != "" || len(al.Notify) > 0 {
+				m.SetNotify(func(ctx context.Context, a asterisklog.Alert) {
+					if err := notifier.Send(ctx, al.WebhookURL, al.Notify, asteriskLogMessage(a, cfg.Title)); err != nil {
+						logger.Warn("asterisk log notification failed", zap.String("category", a.Category), zap.Error(err))
+					}
+				})
+			}
+			defer m.Stop()
+			asteriskLog = m
+			apiLayer.SetAsteriskLog(m)
+			logger.Info("asterisk log monitor enabled", zap.String("path", al.Path), zap.Strings("alert_categories", al.AlertCategories))
+		}
+	}
+
+	// Clock sanity check: Pi deployments often boot without an RTC, which corrupts tally windows
+	var clockChecker *timesync.Checker
+	if cfg.TimeSync.Enabled {
+		clockChecker = timesync.NewChecker(cfg.TimeSync.NTPServers, cfg.TimeSync.HTTPURLs,
+			time.Duration(cfg.TimeSync.MaxSkewSeconds)*time.Second, logger)
+		if ts := cfg.TimeSync; ts.WebhookURL != "" || len(ts.Notify) > 0 {
+			clockChecker.OnSkew = func(res timesync.Result) {
+				ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+				defer cancel()
+				msg := notify.Message{
+					Title:    "Clock skew detected",
+					Body:     fmt.Sprintf("System clock is off by %.1fs according to %s. Tally windows and timestamps may be wrong.", res.OffsetSeconds, res.Source),
+					Priority: notify.PriorityHigh,
+					Tags:     []string{"warning", "clock"},
+					Payload:  map[string]any{"event": "clock_skew", "source": res.Source, "offset_seconds": res.OffsetSeconds, "at": res.CheckedAt},
+				}
+				if err := notifier.Send(ctx, ts.WebhookURL, ts.Notify, msg); err != nil {
+					logger.Warn("clock skew notification failed", zap.Error(err))
+				}
+			}
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+		res := clockChecker.Check(ctx)
+		cancel()
+		if res.Error == "" {
+			logger.Info("clock sanity check", zap.String("source", res.Source), zap.Float64("offset_seconds", res.OffsetSeconds), zap.Bool("skewed", res.Skewed))
+		}
+		clockChecker.Start(time.Duration(cfg.TimeSync.IntervalMinutes) * time.Minute)
+		defer clockChecker.Stop()
+		apiLayer.SetTimeSync(clockChecker, repository.NewTallyStateRepo(gormDB))
 	}
 
 	// Gamification System Initialization
@@ -221,6 +564,10 @@ func main() {
 			levelGroupings = gamification.DefaultLevelGroupings()
 			logger.Info("using default level groupings", zap.Int("groups", len(levelGroupings)))
 		}
+		renownTiers := cfg.Gamification.Renown.Titles
+		if err := gamification.ValidateRenownTiers(renownTiers); err != nil {
+			log.Fatalf("invalid renown titles configuration: %v", err)
+		}
 
 		// Initialize gamification repositories
 		profileRepo = repository.NewCallsignProfileRepo(gormDB)
@@ -241,6 +588,13 @@ func main() {
 			logger.Info("level config seeded", zap.Int("levels", len(levelRequirements)))
 		}
 
+		// Calendar-based cap resets in the hub timezone
+		capSchedule, err := gamification.NewCapSchedule(cfg.Timezone, cfg.Gamification.XPCaps.ResetHour, cfg.Gamification.XPCaps.WeekStarts)
+		if err != nil {
+			logger.Warn("invalid XP cap reset schedule; using midnight UTC", zap.Error(err))
+		}
+		activityRepo.SetResetSchedule(capSchedule)
+
 		// Build gamification config for TallyService
 		gameCfg := &gamification.Config{
 			RestedEnabled:              cfg.Gamification.RestedBonus.Enabled,
@@ -259,9 +613,12 @@ func main() {
 			CapsEnabled:                cfg.Gamification.XPCaps.Enabled,
 			DailyCapSeconds:            cfg.Gamification.XPCaps.DailyCap,
 			WeeklyCapSeconds:           cfg.Gamification.XPCaps.WeeklyCap,
+			CapSchedule:                capSchedule,
 			// Renown settings
 			RenownEnabled:    cfg.Gamification.Renown.Enabled,
 			RenownXPPerLevel: cfg.Gamification.Renown.XPPerLevel,
+			RenownTiers:      renownTiers,
+			BacklogThreshold: time.Duration(cfg.Gamification.BacklogThresholdHours) * time.Hour,
 		}
 
 		// Convert DR tiers
@@ -295,10 +652,28 @@ func main() {
 			tallyInterval,
 			logger,
 		)
+		if clockChecker != nil {
+			tallyService.ClockSkew = clockChecker.Skew
+		}
+		tallyService.Excluded = optOuts.Excluded
+		levelHistoryRepo := repository.NewLevelHistoryRepo(gormDB)
+		tallyService.SetLevelHistory(levelHistoryRepo)
+		tallyRunRepo := repository.NewTallyRunRepo(gormDB)
+		tallyService.SetTallyRuns(tallyRunRepo)
+		if activityFeed != nil {
+			tallyService.OnLevelUp = func(callsign string, level, renown int) {
+				if !optOuts.Has(callsign) {
+					activityFeed.LevelUp(callsign, level, renown)
+				}
+			}
+		}
 
+		apiLayer.SetTallyProgress(tallyService.Progress)
 		if err := tallyService.Start(); err != nil {
 			logger.Error("failed to start tally service", zap.Error(err))
+			apiLayer.SetTallyRuns(tallyRunRepo, nil)
 		} else {
+			apiLayer.SetTallyRuns(tallyRunRepo, tallyService.RunNow)
 			logger.Info("gamification tally service started",
 				zap.Duration("interval", tallyInterval),
 				zap.Bool("rested_bonus", gameCfg.RestedEnabled),
@@ -326,22 +701,30 @@ func main() {
 			cfg.Gamification.XPCaps.DailyCap,
 			cfg.Gamification.DiminishingReturns.Tiers,
 		)
+		gamificationAPI.SetOptOuts(optOuts)
+		gamificationAPI.SetLevelHistory(levelHistoryRepo)
+		gamificationAPI.SetRenownTiers(renownTiers)
 
-		if cfg.AllowAnonDashboard {
-			publicLimiter := middleware.RateLimiter(cfg.PublicStatsRateLimitRPM)
-			mux.Handle("/api/gamification/scoreboard", publicLimiter(http.HandlerFunc(gamificationAPI.Scoreboard)))
-			mux.Handle("/api/gamification/profile/", publicLimiter(http.HandlerFunc(gamificationAPI.Profile)))
-			mux.Handle("/api/gamification/recent-transmissions", publicLimiter(http.HandlerFunc(gamificationAPI.RecentTransmissions)))
-			mux.Handle("/api/gamification/level-config", publicLimiter(http.HandlerFunc(gamificationAPI.LevelConfig)))
-		} else {
-			mux.Handle("/api/gamification/scoreboard", authMW(http.HandlerFunc(gamificationAPI.Scoreboard)))
-			mux.Handle("/api/gamification/profile/", authMW(http.HandlerFunc(gamificationAPI.Profile)))
-			mux.Handle("/api/gamification/recent-transmissions", authMW(http.HandlerFunc(gamificationAPI.RecentTransmissions)))
-			mux.Handle("/api/gamification/level-config", authMW(http.HandlerFunc(gamificationAPI.LevelConfig)))
+		cached := func(route string, ttl time.Duration, h http.HandlerFunc) http.Handler {
+			return responseCache.For(route, "gamification", ttl)(h)
 		}
+		statRoutes.Handle("/api/gamification/scoreboard", cached("/api/gamification/scoreboard", 30*time.Second, gamificationAPI.Scoreboard))
+		statRoutes.Handle("/api/gamification/profile/", cached("/api/gamification/profile/", 15*time.Second, gamificationAPI.Profile))
+		statRoutes.Handle("/api/gamification/recent-transmissions", cached("/api/gamification/recent-transmissions", 30*time.Second, gamificationAPI.RecentTransmissions))
+		statRoutes.Handle("/api/gamification/level-config", cached("/api/gamification/level-config", 5*time.Minute, gamificationAPI.LevelConfig))
 
 		logger.Info("gamification API endpoints registered")
 	}
+	// Profile-backed features stay off without gamification; keep the interface nil
+	// rather than wrapping a nil repository pointer.
+	var profiles repository.ProfileStore
+	if profileRepo != nil {
+		profiles = profileRepo
+	}
+	apiLayer.SetDashboardSources(txLogRepo, profiles)
+	if unused := statRoutes.Unused(); len(unused) > 0 {
+		logger.Warn("endpoint_access names routes that are not registered", zap.Strings("routes", unused))
+	}
 
 	// Serve Vue.js dashboard from embedded frontend/dist
 	if _, err := fs.Sub(frontendFiles, "frontend/dist"); err != nil {
@@ -357,6 +740,10 @@ func main() {
 
 	// AMI + WebSocket wiring (conditional). Always provide a /ws endpoint so the UI never hard-fails.
 	var hub *web.Hub
+	// amiReady is closed on the first successful AMI login; systemd readiness waits on it.
+	var amiConn *ami.Connector
+	amiReady := make(chan struct{})
+	var amiReadyOnce sync.Once
 	if cfg.AMIEnabled {
 		// Log effective AMI configuration (masking sensitive values) to aid troubleshooting
 		logger.Info("AMI enabled. Effective configuration",
@@ -368,17 +755,23 @@ func main() {
 			zap.Duration("retry_max", cfg.AMIRetryMax),
 		)
 		hub = web.NewHub()
+		hub.SetPrivacyPolicy(privacyPolicy)
 		sm := core.NewStateManager()
+		hub.SetState(sm)
 
 		// Initialize transmission log repository and inject into StateManager
 		sm.SetTransmissionLogRepo(txLogRepo)
 		logger.Info("transmission log repository initialized")
 
 		// Configure node lookup service for server-side enrichment
-		nodeLookup := core.NewNodeLookupService(cfg.AstDBPath)
-		nodeLookup.SetNodeInfoRepository(nodeInfoRepo)
 		sm.SetNodeLookup(nodeLookup)
 		logger.Info("node lookup service configured with SQLite backend")
+		// Relabel links to nodes that were unknown when they connected once astdb refreshes
+		astdbDownloader.SetOnUpdate(func(u astdb.Update) {
+			relabeled := sm.ReenrichLinks()
+			logger.Info("astdb updated", zap.Int64("node_count", u.Count), zap.Int("relabeled_links", relabeled))
+			hub.BroadcastAstDBUpdated(u, relabeled)
+		})
 		// Propagate build metadata into StateManager so UI can display it
 		if buildVersion != "" {
 			sm.SetVersion(buildVersion)
@@ -397,11 +790,22 @@ func main() {
 		if len(cfg.Nodes) > 0 {
 			sm.SetNodeID(cfg.Nodes[0].NodeID)
 		}
+		sm.SetTalkerMode(cfg.TalkerDedup.Mode, cfg.TalkerDedup.Window)
 		// Initialize keying trackers for all configured source nodes (2 second jitter delay)
 		for _, node := range cfg.Nodes {
 			sm.AddSourceNode(node.NodeID, 2000)
 			logger.Info("initialized keying tracker for source node", zap.Int("node_id", node.NodeID))
 		}
+		// Load admin node notes/tags before seeding links so seeded links carry them
+		annCtx, annCancel := context.WithTimeout(context.Background(), 2*time.Second)
+		if anns, err := nodeAnnotationRepo.List(annCtx, ""); err == nil {
+			for _, a := range anns {
+				sm.SetNodeAnnotation(a.Node, a.TagList(), a.Notes)
+			}
+		} else {
+			logger.Warn("failed to load node annotations", zap.Error(err))
+		}
+		annCancel()
 		// Seed persisted link stats (if any) so totals survive restarts
 		lsRepo := repository.NewLinkStatsRepo(gormDB)
 		seedCtx, seedCancel := context.WithTimeout(context.Background(), 2*time.Second)
@@ -412,19 +816,8 @@ func main() {
 				primaryNodeID = cfg.Nodes[0].NodeID
 			}
 			for _, s := range stats {
-				cs := time.Now()
-				if s.ConnectedSince != nil {
-					cs = *s.ConnectedSince
-				}
-				linkInfo := core.LinkInfo{
-					Node:           s.Node,
-					LocalNode:      primaryNodeID, // Set LocalNode for multi-node compatibility
-					ConnectedSince: cs,
-					LastTxStart:    s.LastTxStart,
-					LastTxEnd:      s.LastTxEnd,
-					TotalTxSeconds: s.TotalTxSeconds,
-				}
-				// Enrich seeded links with node lookup data
+				linkInfo := core.LinkInfoFromStat(s, primaryNodeID)
+				// Refresh enrichment from node lookup (persisted values are the fallback)
 				nodeLookup.EnrichLinkInfo(&linkInfo)
 				li = append(li, linkInfo)
 			}
@@ -446,9 +839,221 @@ func main() {
 		go hub.SourceNodeKeyingLoop(sm.KeyingUpdates())     // Source node keying updates
 		go hub.SourceNodeKeyingEventLoop(sm.KeyingEvents()) // Session edge events (TX_START/TX_END)
 		conn := ami.NewConnector(cfg.AMIHost, cfg.AMIPort, cfg.AMIUser, cfg.AMIPassword, cfg.AMIEvents, cfg.AMIRetryInterval, cfg.AMIRetryMax)
+		if cfg.AMITLS {
+			tlsCfg, err := ami.NewTLSConfig(cfg.AMIHost, cfg.AMITLSSkipVerify, cfg.AMITLSCAFile)
+			if err != nil {
+				log.Fatalf("AMI TLS configuration error: %v", err)
+			}
+			conn.SetTLS(tlsCfg)
+		}
+		if tunnel, err := amiSSHTunnel(cfg.AMISSH); err != nil {
+			log.Fatalf("AMI SSH tunnel configuration error: %v", err)
+		} else if tunnel != nil {
+			conn.SetSSHTunnel(tunnel)
+		}
+		amiConn = conn
 		// Pass AMI connector and StateManager to API layer
 		apiLayer.SetAMIConnector(conn)
 		apiLayer.SetStateManager(sm)
+		startDiscordBot(cfg.DiscordBot, mux, discordbot.Sources{
+			State: sm, Privacy: privacyPolicy, Profiles: profiles, Levels: levelConfigRepo, TxLogs: txLogRepo, OptOuts: optOuts,
+		})
+
+		// Parrot (audio test) mode: admin endpoint toggles app_rpt parrot and auto-disables it
+		parrot := core.NewParrotController(conn, sm, cfg.Parrot.EnableCommand, cfg.Parrot.DisableCommand, time.Duration(cfg.Parrot.MaxSeconds)*time.Second)
+		apiLayer.SetParrotController(parrot, time.Duration(cfg.Parrot.DefaultSeconds)*time.Second)
+		go hub.ParrotModeLoop(sm.ParrotModeEvents())
+
+		// AMI console: superadmins run whitelisted read-only CLI commands; all attempts are audited
+		if ac := cfg.AMIConsole; ac.Enabled {
+			audit := repository.NewAuditRepo(gormDB)
+			console := core.NewAMIConsole(conn, ac.Commands, ac.MaxOutputBytes, ac.Timeout, audit)
+			apiLayer.SetAMIConsole(console, audit)
+			// Browsers cannot set headers on websocket upgrades, so the token comes in the query
+			mux.HandleFunc("/api/admin/ami/console", web.HandleAMIConsole(console, func(r *http.Request) (string, bool) {
+				email, role, exp, err := auth.ParseJWT(r.URL.Query().Get("token"), cfg.JWTSecret)
+				if err != nil || time.Now().After(exp) || role != models.RoleSuperAdmin {
+					return "", false
+				}
+				u, err := userLoader(email)
+				if err != nil || u == nil || u.Role != models.RoleSuperAdmin {
+					return "", false
+				}
+				return u.Email, true
+			}))
+		}
+
+		// Announcements: admin text spoken over a node via TTS, now or at a scheduled time
+		if an := cfg.Announcements; an.Enabled {
+			synth, err := tts.New(tts.Config{Engine: an.Engine, Binary: an.Binary, Voice: an.Voice, Sox: an.SoxPath})
+			if err != nil {
+				log.Fatalf("announcements configuration error: %v", err)
+			}
+			announcementRepo := repository.NewAnnouncementRepo(gormDB)
+			announcer := core.NewAnnouncer(announcementRepo, repository.NewAuditRepo(gormDB), conn, synth.Synthesize, core.AnnouncerOptions{
+				Nodes:       configuredNodeIDs(cfg.Nodes),
+				SoundsDir:   an.SoundsDir,
+				PlayCommand: an.PlayCommand,
+				MaxChars:    an.MaxChars,
+			})
+			apiLayer.SetAnnouncer(announcer, announcementRepo)
+			announcer.Start(an.CheckInterval)
+			defer announcer.Stop()
+			logger.Info("announcements enabled", zap.String("engine", an.Engine), zap.String("sounds_dir", an.SoundsDir))
+		}
+
+		if na := cfg.NodeActions; na.Enabled {
+			commands := map[string]string{}
+			for _, action := range na.Actions {
+				switch action {
+				case core.NodeActionConnect:
+					commands[action] = na.ConnectCommand
+				case core.NodeActionDisconnect:
+					commands[action] = na.DisconnectCommand
+				}
+			}
+			relay := core.NewNodeActionRelay(conn, sm, repository.NewAuditRepo(gormDB), configuredNodeIDs(cfg.Nodes), commands)
+			apiLayer.SetNodeActions(nodeClaimRepo, relay)
+			logger.Info("user node actions enabled", zap.Strings("actions", relay.Actions()))
+		}
+
+		// rpt.conf telemetry settings (courtesy tones, ID and timers) editable from the admin UI
+		if rc := cfg.RptConfig; rc.Enabled {
+			apiLayer.SetRptConfigEditor(core.NewRptConfigEditor(rptconf.New(rc.Path), conn, repository.NewAuditRepo(gormDB), configuredNodeIDs(cfg.Nodes), rc.ReloadCommand))
+			logger.Info("rpt.conf editing enabled", zap.String("path", rc.Path))
+		}
+
+		// Idle hub detector: fires an AMI command and/or webhook after a period of silence
+		if cfg.IdleReminder.Enabled {
+			ir := cfg.IdleReminder
+			idle := core.NewIdleDetector(time.Duration(ir.IdleMinutes)*time.Minute, ir.StartHour, ir.EndHour, ir.Repeat)
+			idle.SetAction(func(ctx context.Context, d time.Duration) {
+				if ir.AMICommand != "" {
+					if _, err := conn.SendCommand(ctx, ir.AMICommand); err != nil {
+						logger.Warn("idle reminder AMI command failed", zap.String("command", ir.AMICommand), zap.Error(err))
+					}
+				}
+				if ir.WebhookURL != "" || len(ir.Notify) > 0 {
+					msg := notify.Message{
+						Title:    "Hub idle",
+						Body:     fmt.Sprintf("No transmissions for %s.", timefmt.Duration(d.Round(time.Minute))),
+						Priority: notify.PriorityLow,
+						Tags:     []string{"zzz"},
+						Payload: map[string]any{
+							"event": "hub_idle", "idle_seconds": int(d.Seconds()), "idle_human": timefmt.Duration(d),
+							"title": cfg.Title, "timestamp": time.Now().UTC(), "time_format": locale.Hints(time.Now()),
+						},
+					}
+					if err := notifier.Send(ctx, ir.WebhookURL, ir.Notify, msg); err != nil {
+						logger.Warn("idle reminder notification failed", zap.Error(err))
+					}
+				}
+			})
+			sm.AddTalkerHook(idle.Observe)
+			idle.Start(30 * time.Second)
+			defer idle.Stop()
+			apiLayer.SetIdleDetector(idle)
+			logger.Info("idle reminder enabled",
+				zap.Int("idle_minutes", ir.IdleMinutes),
+				zap.Int("start_hour", ir.StartHour),
+				zap.Int("end_hour", ir.EndHour),
+				zap.Bool("repeat", ir.Repeat),
+			)
+		}
+		// Talker webhook: transmission notifications with debounce, quiet hours and digests
+		if tw := cfg.TalkerWebhook; tw.Enabled && (tw.WebhookURL != "" || len(tw.Notify) > 0) {
+			talkerNotifier := core.NewTalkerNotifier(core.TalkerNotifyOptions{
+				MinDuration:    tw.MinDuration,
+				Cooldown:       tw.Cooldown,
+				QuietStartHour: tw.QuietStartHour,
+				QuietEndHour:   tw.QuietEndHour,
+				Digest:         tw.Digest,
+				DigestInterval: tw.DigestInterval,
+			})
+			talkerNotifier.SetAction(func(ctx context.Context, n core.TalkerNotification) {
+				n, ok := withoutOptOuts(n, optOuts)
+				if !ok {
+					return
+				}
+				if err := notifier.Send(ctx, tw.WebhookURL, tw.Notify, talkerMessage(n, cfg.Title, locale)); err != nil {
+					logger.Warn("talker notification failed", zap.Error(err))
+				}
+			})
+			sm.AddTalkerHook(talkerNotifier.Observe)
+			talkerNotifier.Start()
+			defer talkerNotifier.Stop()
+			logger.Info("talker webhook enabled", zap.Bool("digest", tw.Digest), zap.Duration("min_duration", tw.MinDuration))
+		}
+		if activityFeed != nil {
+			sm.AddTalkerHook(activityFeed.Observe)
+		}
+		// IAX2 monitoring: transport-level health alongside link-level monitoring
+		if im := cfg.IAXMonitor; im.Enabled {
+			iax := core.NewIAXMonitor(conn, im.LapsePolls)
+			if im.WebhookURL != "" || len(im.Notify) > 0 {
+				iax.SetNotify(func(ctx context.Context, a core.IAXAlert) {
+					if err := notifier.Send(ctx, im.WebhookURL, im.Notify, iaxMessage(a, cfg.Title)); err != nil {
+						logger.Warn("IAX alert notification failed", zap.String("kind", a.Kind), zap.Error(err))
+					}
+				})
+			}
+			apiLayer.SetIAXMonitor(iax)
+			go hub.IAXAlertLoop(iax.Events())
+			iax.Start(im.Interval)
+			defer iax.Stop()
+		}
+		// Node health: probe linked nodes and show reachability on each link
+		if nh := cfg.NodeHealth; nh.Enabled {
+			probe, err := netprobe.New(nh.Method)
+			if err != nil {
+				logger.Warn("node health probing disabled", zap.Error(err))
+			} else {
+				nodeHealthRepo := repository.NewNodeHealthRepo(gormDB)
+				prober := core.NewNodeProber(sm, probe, nodeHealthRepo, core.NodeProberOptions{
+					Method:       nh.Method,
+					Interval:     nh.Interval,
+					Count:        nh.Count,
+					Timeout:      nh.Timeout,
+					Window:       nh.Window,
+					DegradedLoss: nh.DegradedLoss,
+					DegradedRTT:  nh.DegradedRTT,
+					Retention:    nh.Retention,
+				})
+				apiLayer.SetNodeHealth(nodeHealthRepo)
+				prober.Start()
+				defer prober.Stop()
+				logger.Info("node health probing enabled", zap.String("method", nh.Method), zap.Duration("interval", nh.Interval))
+			}
+		}
+		// Link quality: per-connection jitter and packet statistics
+		if lq := cfg.LinkQuality; lq.Enabled {
+			nodeIDs := make([]int, len(cfg.Nodes))
+			for i, node := range cfg.Nodes {
+				nodeIDs[i] = node.NodeID
+			}
+			linkQualityRepo := repository.NewLinkQualityRepo(gormDB)
+			linkQuality := core.NewLinkQualityMonitor(conn, linkQualityRepo, nodeIDs, lq.Retention)
+			apiLayer.SetLinkQuality(linkQualityRepo)
+			linkQuality.Start(lq.Interval)
+			defer linkQuality.Stop()
+			logger.Info("link quality sampling enabled", zap.Duration("interval", lq.Interval))
+		}
+		// Watchlist: alert admins (and optionally connect) when a watched node appears
+		wl := cfg.Watchlist
+		watchlist := core.NewWatchlist(sm, conn, wl.ConnectCommand, wl.ConnectCooldown)
+		watchlist.SetLookup(nodeLookup.LookupNode)
+		if wl.WebhookURL != "" || len(wl.Notify) > 0 {
+			watchlist.SetNotify(func(ctx context.Context, evt core.WatchEvent) {
+				if err := notifier.Send(ctx, wl.WebhookURL, wl.Notify, watchMessage(evt, cfg.Title)); err != nil {
+					logger.Warn("watchlist notification failed", zap.Int("node", evt.Node), zap.Error(err))
+				}
+			})
+		}
+		apiLayer.SetWatchlist(watchlistRepo, watchlist)
+		go hub.WatchlistLoop(watchlist.Events())
+		watchlist.Start(wl.CheckInterval)
+		defer watchlist.Stop()
+
 		ctxAMI, cancelAMI := context.WithCancel(context.Background())
 
 		// Monitor AMI connection status changes
@@ -456,6 +1061,7 @@ func main() {
 			for status := range conn.ConnectionStatusChan() {
 				if status.Connected {
 					logger.Info("AMI connection established", zap.Time("timestamp", status.Timestamp))
+					amiReadyOnce.Do(func() { close(amiReady) })
 				} else {
 					if status.Error != nil {
 						logger.Warn("AMI connection lost", zap.Error(status.Error), zap.Time("timestamp", status.Timestamp))
@@ -468,15 +1074,22 @@ func main() {
 
 		// If tally service is running, broadcast a WS event when it completes
 		if tallyService != nil {
+			// A startup backlog is tallied in the background; let admins watch it catch up
+			tallyService.SetProgressHook(func(p gamification.TallyProgress) {
+				if hub != nil {
+					hub.BroadcastTallyProgress(p)
+				}
+			})
 			// When a tally completes, broadcast the summary and include the current leaderboard
 			// so clients can update immediately without an extra HTTP fetch.
 			tallyService.OnTallyComplete = func(summary gamification.TallySummary) {
+				responseCache.Invalidate("gamification")
 				if hub == nil {
 					return
 				}
 				// Build a lightweight scoreboard snapshot to send over WS
 				ctx := context.Background()
-				profiles, err := profileRepo.GetLeaderboard(ctx, 50)
+				profiles, err := profileRepo.GetLeaderboardExcluding(ctx, 50, optOuts.List())
 				if err != nil {
 					// fallback: broadcast only summary
 					hub.BroadcastTallyCompleted(summary)
@@ -496,8 +1109,10 @@ func main() {
 						"level":                   p.Level,
 						"experience_points":       p.ExperiencePoints,
 						"renown_level":            p.RenownLevel,
+						"renown_title":            gamification.GetRenownTitle(p.RenownLevel, cfg.Gamification.Renown.Titles),
 						"next_level_xp":           nextXP,
 						"total_talk_time_seconds": totalTime,
+						"total_talk_time_human":   timefmt.Seconds(int64(totalTime)),
 					})
 				}
 				hub.BroadcastTallyCompleted(map[string]any{"summary": summary, "scoreboard": entries})
@@ -518,13 +1133,17 @@ func main() {
 		// Start periodic polling service for data sync and enrichment
 		// This provides a hybrid approach:
 		// - Events drive real-time updates (ALINKS, TXKEYED, etc.)
-		// - Polling (1 min) ensures sync and enriches with XStat/SawStat data (direction, IP, elapsed, mode)
+		// - Polling (poll_interval, per-node overrides) ensures sync and enriches with XStat/SawStat data (direction, IP, elapsed, mode)
 		if !cfg.DisableLinkPoller {
 			nodeIDs := make([]int, len(cfg.Nodes))
 			for i, node := range cfg.Nodes {
 				nodeIDs[i] = node.NodeID
 			}
-			pollingService := core.NewPollingService(conn, sm, 60*time.Second, nodeIDs)
+			pollingService := core.NewPollingService(conn, sm, cfg.PollInterval, nodeIDs)
+			pollingService.SetMaxConcurrent(cfg.PollWorkers)
+			for _, node := range cfg.Nodes {
+				pollingService.SetNodeInterval(node.NodeID, node.PollInterval)
+			}
 
 			// Set cleanup callback to sync database with actual state after first poll
 			// This cleans up any stale links that were seeded from database but are no longer connected
@@ -551,14 +1170,7 @@ func main() {
 
 				// Update active links in database
 				for _, li := range currentLinks {
-					stat := models.LinkStat{
-						Node:           li.Node,
-						TotalTxSeconds: li.TotalTxSeconds,
-						LastTxStart:    li.LastTxStart,
-						LastTxEnd:      li.LastTxEnd,
-						ConnectedSince: &li.ConnectedSince,
-					}
-					if err := lsRepo.Upsert(ctx, stat); err != nil {
+					if err := lsRepo.Upsert(ctx, li.ToLinkStat()); err != nil {
 						logger.Warn("failed to sync link stat", zap.Int("node", li.Node), zap.Error(err))
 					}
 				}
@@ -569,7 +1181,7 @@ func main() {
 			if err := pollingService.Start(); err != nil {
 				logger.Warn("failed to start polling service", zap.Error(err))
 			} else {
-				logger.Info("polling service started", zap.Duration("interval", 60*time.Second), zap.Ints("nodes", nodeIDs))
+				logger.Info("polling service started", zap.Duration("interval", cfg.PollInterval), zap.Int("workers", cfg.PollWorkers), zap.Ints("nodes", nodeIDs))
 			}
 			// If a hub exists, wire a trigger so new WS clients cause an immediate
 			// on-demand poll shortly after connecting (debounced).
@@ -582,39 +1194,64 @@ func main() {
 					pollingService.TriggerPollOnce()
 				}
 			})
+			apiLayer.SetPollStatus(pollingService.Status)
+			watchlist.SetPollTrigger(pollingService.TriggerPollNode)
+			// Link-graph topology is assembled from the same polls
+			topology := core.NewTopologyService()
+			topology.SetLookup(nodeLookup.LookupNode)
+			pollingService.SetTopology(topology)
+			apiLayer.SetTopology(topology)
+			go hub.TopologyLoop(topology.Updates())
+			if th := cfg.TopologyHistory; th.Enabled {
+				history := core.NewTopologyHistory(repository.NewTopologyHistoryRepo(gormDB), th.Interval, th.KeyframeEvery, time.Duration(th.RetentionDays)*24*time.Hour)
+				topology.SetChangeHook(history.Observe)
+				apiLayer.SetTopologyHistory(history)
+				history.Start()
+				defer history.Stop()
+			}
 			// Stop polling service on shutdown
 			defer pollingService.Stop()
 		} else {
 			logger.Info("polling service disabled via config (disable_link_poller=true)")
 		}
+		if tg := startTelegramBot(cfg.TelegramBot, apiLayer, activityFeed, telegrambot.Sources{
+			State: sm, Privacy: privacyPolicy, Users: apiLayer.Users, Audit: repository.NewAuditRepo(gormDB),
+			Sender: conn, Poll: apiLayer.TriggerPoll, Nodes: configuredNodeIDs(cfg.Nodes),
+		}); tg != nil {
+			defer tg.Stop()
+		}
 		// Persist per-link TX stats on edges
 		sm.SetPersistHook(func(list []core.LinkInfo) {
 			ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
 			defer cancel()
 			for _, li := range list {
-				stat := models.LinkStat{Node: li.Node, TotalTxSeconds: li.TotalTxSeconds, LastTxStart: li.LastTxStart, LastTxEnd: li.LastTxEnd, ConnectedSince: &li.ConnectedSince}
-				_ = lsRepo.Upsert(ctx, stat)
+				_ = lsRepo.Upsert(ctx, li.ToLinkStat())
 			}
+			responseCache.Invalidate("link_stats")
 		})
-		validator := func(r *http.Request) (bool, bool) {
+		validator := func(r *http.Request) (bool, privacy.Viewer) {
 			token := r.URL.Query().Get("token")
 			if token == "" {
 				// allow anonymous if configured
-				return cfg.AllowAnonDashboard, false
+				return cfg.AllowAnonDashboard, privacy.ViewerAnonymous
 			}
-			_, role, exp, err := auth.ParseJWT(token, cfg.JWTSecret)
+			email, role, exp, err := auth.ParseJWT(token, cfg.JWTSecret)
 			if err != nil || time.Now().After(exp) {
-				return false, false
+				return false, privacy.ViewerAnonymous
 			}
-			isAdmin := role == models.RoleAdmin || role == models.RoleSuperAdmin
-			return true, isAdmin
+			if tenants.IsAdmin(tenant.FromContext(r.Context()), email, role) {
+				return true, privacy.ViewerAdmin
+			}
+			return true, privacy.ViewerUser
 		}
-		mux.HandleFunc("/ws", hub.HandleWS(sm, validator))
+		mux.HandleFunc("/ws", hub.HandleWSViewer(sm, validator))
 		defer cancelAMI()
 	} else {
 		// Fallback: serve a static heartbeat-only websocket with empty state (allows anonymous dashboard to load).
 		hub = web.NewHub()
+		hub.SetPrivacyPolicy(privacyPolicy)
 		sm := core.NewStateManager()
+		hub.SetState(sm)
 		if buildVersion != "" {
 			sm.SetVersion(buildVersion)
 		}
@@ -632,45 +1269,104 @@ func main() {
 		if len(cfg.Nodes) > 0 {
 			sm.SetNodeID(cfg.Nodes[0].NodeID)
 		}
-		validator := func(r *http.Request) (bool, bool) {
+		startDiscordBot(cfg.DiscordBot, mux, discordbot.Sources{
+			State: sm, Privacy: privacyPolicy, Profiles: profiles, Levels: levelConfigRepo, TxLogs: txLogRepo, OptOuts: optOuts,
+		})
+		if tg := startTelegramBot(cfg.TelegramBot, apiLayer, activityFeed, telegrambot.Sources{
+			State: sm, Privacy: privacyPolicy, Users: apiLayer.Users, Nodes: configuredNodeIDs(cfg.Nodes),
+		}); tg != nil {
+			defer tg.Stop()
+		}
+		validator := func(r *http.Request) (bool, privacy.Viewer) {
 			token := r.URL.Query().Get("token")
 			if token == "" {
-				return cfg.AllowAnonDashboard, false
+				return cfg.AllowAnonDashboard, privacy.ViewerAnonymous
 			}
-			_, role, exp, err := auth.ParseJWT(token, cfg.JWTSecret)
+			email, role, exp, err := auth.ParseJWT(token, cfg.JWTSecret)
 			if err != nil || time.Now().After(exp) {
-				return false, false
+				return false, privacy.ViewerAnonymous
+			}
+			if tenants.IsAdmin(tenant.FromContext(r.Context()), email, role) {
+				return true, privacy.ViewerAdmin
 			}
-			isAdmin := role == models.RoleAdmin || role == models.RoleSuperAdmin
-			return true, isAdmin
+			return true, privacy.ViewerUser
 		}
-		mux.HandleFunc("/ws", hub.HandleWS(sm, validator))
+		mux.HandleFunc("/ws", hub.HandleWSViewer(sm, validator))
 		// Heartbeat provides periodic STATUS_UPDATE so client replaces 'Waiting for data'.
 		go hub.HeartbeatLoop(sm, 5*time.Second)
 	}
+	// Admin connections view: record which user each dashboard client is signed in as
+	hub.SetIdentify(func(r *http.Request) string {
+		email, _, exp, err := auth.ParseJWT(r.URL.Query().Get("token"), cfg.JWTSecret)
+		if err != nil || time.Now().After(exp) {
+			return ""
+		}
+		return email
+	})
+	apiLayer.SetWSHub(hub)
+	if asteriskLog != nil {
+		go hub.AsteriskLogAlertLoop(asteriskLog.Events())
+	}
 
-	addr := ":" + cfg.Port
 	zapLogger, err := zap.NewProduction()
 	if err != nil {
 		log.Fatalf("failed to init zap: %v", err)
 	}
 	defer func() { _ = zapLogger.Sync() }()
 	loggingMW := middleware.Logging(zapLogger)
-	srv := &http.Server{Addr: addr, Handler: loggingMW(mux), ReadTimeout: 10 * time.Second, WriteTimeout: 15 * time.Second}
+	startup.Serve(loggingMW(tenants.Middleware(mux)))
 
-	// Start server in goroutine
+	// systemd Type=notify integration (no-op when NOTIFY_SOCKET is unset)
+	if !cfg.AMIEnabled {
+		amiReadyOnce.Do(func() { close(amiReady) })
+	}
+	ctxNotify, cancelNotify := context.WithCancel(context.Background())
+	defer cancelNotify()
 	go func() {
-		log.Printf("Allstar Nexus starting on %s (env=%s) build=%s", addr, cfg.Env, cfg.BuildTime)
-		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			log.Fatalf("server error: %v", err)
+		status := "serving on " + addr
+		select {
+		case <-amiReady:
+		case <-time.After(sdReadyAMITimeout):
+			// Don't hold startup hostage to Asterisk; the connector keeps retrying.
+			status += " (AMI not connected yet)"
+		case <-ctxNotify.Done():
+			return
+		}
+		if sent, err := sdnotify.Notify(sdnotify.Ready + "\n" + sdnotify.Status(status)); err != nil {
+			logger.Warn("systemd ready notification failed", zap.Error(err))
+		} else if sent {
+			logger.Info("notified systemd: ready", zap.String("status", status))
 		}
 	}()
+	if interval, err := sdnotify.WatchdogInterval(); err != nil {
+		logger.Warn("systemd watchdog disabled", zap.Error(err))
+	} else if interval > 0 {
+		checks := map[string]sdnotify.HealthCheck{
+			"database": sqlDB.PingContext,
+		}
+		if amiConn != nil {
+			checks["ami"] = func(ctx context.Context) error {
+				// Only a connected session can prove the read loop is alive; while the
+				// connector is reconnecting it is making progress on its own.
+				if !amiConn.IsConnected() {
+					return nil
+				}
+				return amiConn.Ping(ctx)
+			}
+		}
+		go sdnotify.RunWatchdog(ctxNotify, interval, checks, func(name string, err error) {
+			logger.Warn("watchdog health check failed; withholding keepalive", zap.String("check", name), zap.Error(err))
+		})
+		logger.Info("systemd watchdog enabled", zap.Duration("timeout", interval))
+	}
 
 	// Wait for termination signal
 	stop := make(chan os.Signal, 1)
 	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
 	<-stop
 	log.Printf("shutdown signal received, shutting down...")
+	cancelNotify()
+	_, _ = sdnotify.Notify(sdnotify.Stopping)
 
 	// Stop gamification tally service
 	if tallyService != nil {
@@ -687,3 +1383,273 @@ func main() {
 	}
 	log.Printf("server stopped cleanly")
 }
+
+// sdReadyAMITimeout bounds how long systemd readiness waits for the first AMI login.
+const sdReadyAMITimeout = 60 * time.Second
+
+// newNotifier registers the chat/push providers configured under notifications.
+func newNotifier(c config.NotificationsConfig, source string) *notify.Dispatcher {
+	d := notify.NewDispatcher(source)
+	for _, name := range c.Configured() {
+		switch name {
+		case notify.ProviderDiscord:
+			d.Register(name, &notify.Discord{WebhookURL: c.Discord.WebhookURL, Username: c.Discord.Username})
+		case notify.ProviderPushover:
+			d.Register(name, &notify.Pushover{Token: c.Pushover.Token, User: c.Pushover.User, Device: c.Pushover.Device, Sound: c.Pushover.Sound})
+		case notify.ProviderTelegram:
+			d.Register(name, &notify.Telegram{BotToken: c.Telegram.BotToken, ChatID: c.Telegram.ChatID})
+		case notify.ProviderNtfy:
+			d.Register(name, &notify.Ntfy{Server: c.Ntfy.Server, Topic: c.Ntfy.Topic, Token: c.Ntfy.Token})
+		}
+	}
+	return d
+}
+
+// startDiscordBot serves slash commands on /api/discord/interactions and registers
+// them with Discord when a bot token is configured.
+func startDiscordBot(c config.DiscordBotConfig, mux *http.ServeMux, src discordbot.Sources) {
+	if !c.Enabled {
+		return
+	}
+	bot, err := discordbot.New(c.ApplicationID, c.PublicKey, c.BotToken, c.GuildID)
+	if err != nil {
+		log.Printf("[DISCORD] bot disabled: %v", err)
+		return
+	}
+	bot.RegisterDefaults(src)
+	mux.Handle("/api/discord/interactions", bot)
+	if c.BotToken == "" {
+		return
+	}
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		if err := bot.SyncCommands(ctx); err != nil {
+			log.Printf("[DISCORD] %v", err)
+			return
+		}
+		log.Printf("[DISCORD] slash commands registered")
+	}()
+}
+
+// startTelegramBot long-polls for bot commands, posts the selected activity kinds to
+// the alert chat and lets admins link their Telegram accounts for node control.
+func startTelegramBot(c config.TelegramBotConfig, apiLayer *api.API, feed *core.ActivityFeed, src telegrambot.Sources) *telegrambot.Bot {
+	if !c.Enabled || c.BotToken == "" {
+		return nil
+	}
+	bot := telegrambot.New(c.BotToken, c.AlertChatID)
+	src.ConnectCommand, src.DisconnectCommand = c.ConnectCommand, c.DisconnectCommand
+	bot.RegisterDefaults(src)
+	apiLayer.SetTelegramLinker(bot)
+	if c.AlertChatID != "" && feed == nil {
+		log.Printf("[TELEGRAM] alerts disabled: activity_feed is not enabled")
+	} else if c.AlertChatID != "" {
+		feed.SetNotify(func(e core.ActivityEntry) {
+			if len(c.Alerts) > 0 && !slices.Contains(c.Alerts, e.Kind) {
+				return
+			}
+			// The alert chat is treated like an anonymous dashboard viewer
+			visible := src.Privacy.Activity([]core.ActivityEntry{e}, privacy.ViewerAnonymous)
+			if len(visible) == 0 {
+				return
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+			if err := bot.Alert(ctx, telegrambot.ActivityAlert(visible[0])); err != nil {
+				log.Printf("[TELEGRAM] activity alert failed: %v", err)
+			}
+		})
+	}
+	bot.Start()
+	log.Printf("[TELEGRAM] bot started")
+	return bot
+}
+
+// configuredNodeIDs lists the local node numbers in configuration order.
+func configuredNodeIDs(nodes []config.NodeConfig) []int {
+	ids := make([]int, len(nodes))
+	for i, n := range nodes {
+		ids[i] = n.NodeID
+	}
+	return ids
+}
+
+// tenantRegistry builds the hosted tenants from config; nil when none are configured.
+func tenantRegistry(tcs []config.TenantConfig) (*tenant.Registry, error) {
+	if len(tcs) == 0 {
+		return nil, nil
+	}
+	ts := make([]tenant.Tenant, len(tcs))
+	for i, tc := range tcs {
+		ts[i] = tenant.Tenant{
+			ID: tc.ID, Name: tc.Name, Hosts: tc.Hosts, Nodes: tc.Nodes, Admins: tc.Admins,
+			Branding: tenant.Branding{Title: tc.Title, Subtitle: tc.Subtitle, ClubName: tc.ClubName, LogoURL: tc.LogoURL, Colors: tc.Colors},
+		}
+	}
+	return tenant.NewRegistry(ts)
+}
+
+// talkerMessage formats a transmission or digest notification. Payloads carry
+// preformatted durations and the hub's time hints for webhook templates.
+func talkerMessage(n core.TalkerNotification, title string, locale timefmt.Locale) notify.Message {
+	now := time.Now()
+	if d := n.Digest; d != nil {
+		var b strings.Builder
+		fmt.Fprintf(&b, "%d transmissions, %s on air since %s.", d.Transmissions, timefmt.Seconds(int64(d.Seconds)), d.From.In(locale.Zone()).Format("15:04"))
+		for i, t := range d.Talkers {
+			if i == 10 {
+				fmt.Fprintf(&b, "\n…and %d more", len(d.Talkers)-i)
+				break
+			}
+			fmt.Fprintf(&b, "\n%s: %d× %s", talkerName(t.Callsign, t.Node), t.Transmissions, timefmt.Seconds(int64(t.Seconds)))
+		}
+		return notify.Message{
+			Title:    "Talker digest",
+			Body:     b.String(),
+			Priority: notify.PriorityLow,
+			Tags:     []string{"radio"},
+			Payload: map[string]any{
+				"event": "talker_digest", "digest": d, "duration_human": timefmt.Seconds(int64(d.Seconds)),
+				"title": title, "timestamp": now.UTC(), "time_format": locale.Hints(now),
+			},
+		}
+	}
+	evt := n.Event
+	body := fmt.Sprintf("%s transmitted for %s.", talkerName(evt.Callsign, evt.Node), timefmt.Seconds(int64(evt.Duration)))
+	if evt.Description != "" {
+		body += "\n" + evt.Description
+	}
+	return notify.Message{
+		Title: "On air",
+		Body:  body,
+		Tags:  []string{"radio"},
+		Payload: map[string]any{
+			"event": "talker", "talker": evt, "duration_human": timefmt.Seconds(int64(evt.Duration)),
+			"title": title, "timestamp": now.UTC(), "time_format": locale.Hints(now),
+		},
+	}
+}
+
+// withoutOptOuts drops opted-out callsigns from a talker notification. It reports
+// false when nothing is left to announce.
+func withoutOptOuts(n core.TalkerNotification, optOuts *gamification.OptOuts) (core.TalkerNotification, bool) {
+	if n.Event != nil {
+		return n, !optOuts.Has(n.Event.Callsign)
+	}
+	if n.Digest == nil {
+		return n, false
+	}
+	d := *n.Digest
+	d.Talkers = make([]core.TalkerDigestEntry, 0, len(n.Digest.Talkers))
+	for _, t := range n.Digest.Talkers {
+		if !optOuts.Has(t.Callsign) {
+			d.Talkers = append(d.Talkers, t)
+		}
+	}
+	return core.TalkerNotification{Digest: &d}, true
+}
+
+// watchMessage formats a watchlist alert.
+func watchMessage(evt core.WatchEvent, title string) notify.Message {
+	name := talkerName(evt.Callsign, evt.Node)
+	if evt.Label != "" {
+		name += " (" + evt.Label + ")"
+	}
+	body := fmt.Sprintf("%s is on the air", name)
+	if evt.LocalNode != 0 {
+		body += fmt.Sprintf(" via node %d", evt.LocalNode)
+	}
+	body += "."
+	if d := strings.TrimSpace(evt.Description + " " + evt.Location); d != "" {
+		body += "\n" + d
+	}
+	switch {
+	case evt.ConnectError != "":
+		body += "\nAuto-connect failed: " + evt.ConnectError
+	case evt.ConnectSent:
+		body += "\nAuto-connect sent."
+	}
+	return notify.Message{
+		Title:    "Watched node seen",
+		Body:     body,
+		Priority: notify.PriorityHigh,
+		Tags:     []string{"eyes"},
+		Payload:  map[string]any{"event": "watchlist", "alert": evt, "title": title, "timestamp": time.Now().UTC()},
+	}
+}
+
+// iaxMessage formats an IAX2 registration/peer alert.
+func iaxMessage(a core.IAXAlert, title string) notify.Message {
+	msg := notify.Message{
+		Priority: notify.PriorityHigh,
+		Tags:     []string{"warning"},
+		Payload:  map[string]any{"event": "iax", "alert": a, "title": title, "timestamp": time.Now().UTC()},
+	}
+	switch a.Kind {
+	case core.IAXRegistrationLapsed:
+		msg.Title = "IAX registration lapsed"
+		msg.Body = fmt.Sprintf("Registration of %s with %s is %q since %s.", a.Username, a.Host, a.State, a.Since.Format("15:04"))
+	case core.IAXRegistrationRestored:
+		msg.Title, msg.Priority, msg.Tags = "IAX registration restored", notify.PriorityNormal, []string{"white_check_mark"}
+		msg.Body = fmt.Sprintf("%s is registered with %s again.", a.Username, a.Host)
+	case core.IAXPeerUnreachable:
+		msg.Title = "IAX peer unreachable"
+		msg.Body = fmt.Sprintf("Peer %s (%s) stopped answering at %s.", a.Peer, a.Host, a.Since.Format("15:04"))
+	default:
+		msg.Title, msg.Priority, msg.Tags = "IAX peer reachable", notify.PriorityNormal, []string{"white_check_mark"}
+		msg.Body = fmt.Sprintf("Peer %s (%s) is answering again (%s).", a.Peer, a.Host, a.State)
+	}
+	return msg
+}
+
+func asteriskLogMessage(a asterisklog.Alert, title string) notify.Message {
+	msg := notify.Message{
+		Title:    "Asterisk: " + strings.ReplaceAll(a.Category, "_", " "),
+		Body:     a.Entry.Message,
+		Priority: notify.PriorityHigh,
+		Tags:     []string{"warning"},
+		Payload:  map[string]any{"event": "asterisk_log", "alert": a, "title": title, "timestamp": time.Now().UTC()},
+	}
+	if a.Suppressed > 0 {
+		msg.Body += fmt.Sprintf(" (%d similar since the last alert)", a.Suppressed)
+	}
+	return msg
+}
+
+// talkerName prefers the callsign and falls back to the node number.
+func talkerName(callsign string, node int) string {
+	if callsign != "" {
+		return callsign
+	}
+	return fmt.Sprintf("node %d", node)
+}
+
+// amiSSHTunnel builds the optional SSH tunnel for AMI; nil when ami_ssh.host is unset.
+func amiSSHTunnel(c config.AMISSHConfig) (*ami.SSHTunnel, error) {
+	if c.Host == "" {
+		return nil, nil
+	}
+	return ami.NewSSHTunnel(ami.SSHTunnelOptions{
+		Host:                  c.Host,
+		Port:                  c.Port,
+		User:                  c.User,
+		KeyFile:               c.KeyFile,
+		KnownHostsFile:        c.KnownHostsFile,
+		InsecureIgnoreHostKey: c.InsecureIgnoreHostKey,
+		KeepAlive:             c.KeepAlive,
+	})
+}
+
+// rateLimitPolicies converts configured per-route rate limits into middleware policies.
+func rateLimitPolicies(routes map[string]config.RateLimitPolicyConfig) map[string]middleware.RatePolicy {
+	out := make(map[string]middleware.RatePolicy, len(routes))
+	for route, p := range routes {
+		out[route] = middleware.RatePolicy{
+			RequestsPerMinute: p.RequestsPerMinute,
+			Burst:             p.Burst,
+			KeyBy:             middleware.KeyStrategy(p.KeyBy),
+		}
+	}
+	return out
+}
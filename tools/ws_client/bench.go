@@ -0,0 +1,292 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// benchConfig is one load-test run: Conns clients listen while the server broadcasts a
+// storm of Count BENCH messages (see POST /api/admin/ws-bench).
+type benchConfig struct {
+	WSURL    url.URL
+	StormURL url.URL
+	Token    string
+	Conns    int
+	Count    int
+	Interval time.Duration
+	Size     int
+	Profile  string
+	Grace    time.Duration
+}
+
+// benchEnvelope is the server's websocket envelope with a BENCH payload.
+type benchEnvelope struct {
+	MessageType string `json:"messageType"`
+	Data        struct {
+		Run  string `json:"run"`
+		Seq  int    `json:"seq"`
+		Sent int64  `json:"sent"`
+	} `json:"data"`
+}
+
+// benchConn tracks what one connection received.
+type benchConn struct {
+	conn      *websocket.Conn
+	dial      time.Duration
+	mu        sync.Mutex
+	seen      map[int]bool
+	dupes     int
+	reordered int
+	lastSeq   int
+	latencies []time.Duration
+	other     int // non-BENCH messages, e.g. the initial snapshot
+	bytes     int64
+	err       error
+	done      chan struct{} // closed once every storm message has arrived
+}
+
+func (b *benchConn) read(run *string, runMu *sync.RWMutex, count int) {
+	for {
+		_, msg, err := b.conn.ReadMessage()
+		now := time.Now()
+		if err != nil {
+			b.mu.Lock()
+			b.err = err
+			b.mu.Unlock()
+			return
+		}
+		var env benchEnvelope
+		_ = json.Unmarshal(msg, &env)
+		runMu.RLock()
+		mine := env.MessageType == "BENCH" && env.Data.Run == *run
+		runMu.RUnlock()
+
+		b.mu.Lock()
+		b.bytes += int64(len(msg))
+		switch {
+		case !mine:
+			b.other++
+		case b.seen[env.Data.Seq]:
+			b.dupes++
+		default:
+			b.seen[env.Data.Seq] = true
+			if env.Data.Seq < b.lastSeq {
+				b.reordered++
+			}
+			b.lastSeq = env.Data.Seq
+			b.latencies = append(b.latencies, now.Sub(time.Unix(0, env.Data.Sent)))
+			if len(b.seen) == count {
+				close(b.done)
+			}
+		}
+		b.mu.Unlock()
+	}
+}
+
+// benchReport summarizes a run.
+type benchReport struct {
+	Config      benchConfig
+	Connected   int
+	DialFailed  int
+	DialErrors  map[string]int
+	DialP50     time.Duration
+	DialMax     time.Duration
+	Expected    int
+	Received    int
+	Dupes       int
+	Reordered   int
+	Disconnects int
+	Other       int
+	Bytes       int64
+	Elapsed     time.Duration
+	Latency     []time.Duration // sorted
+	WorstConn   int             // fewest storm messages received by one connection
+}
+
+func runBench(cfg benchConfig) (*benchReport, error) {
+	if cfg.Conns < 1 || cfg.Count < 1 {
+		return nil, fmt.Errorf("conns and count must be positive")
+	}
+	if cfg.Profile != "" {
+		q := cfg.WSURL.Query()
+		q.Set("profile", cfg.Profile)
+		cfg.WSURL.RawQuery = q.Encode()
+	}
+	report := &benchReport{Config: cfg, DialErrors: map[string]int{}}
+
+	// The run id is chosen by the server when the storm starts; until then no message
+	// counts as ours.
+	run := "\x00pending"
+	var runMu sync.RWMutex
+
+	dialer := *websocket.DefaultDialer
+	dialer.HandshakeTimeout = 15 * time.Second
+	conns := make([]*benchConn, cfg.Conns)
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, 50) // bounded dial concurrency, like a crowd arriving at once
+	for i := range conns {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			start := time.Now()
+			c, resp, err := dialer.Dial(cfg.WSURL.String(), nil)
+			if err != nil {
+				if resp != nil {
+					err = fmt.Errorf("status %s", resp.Status)
+				}
+				conns[i] = &benchConn{err: err}
+				return
+			}
+			b := &benchConn{conn: c, dial: time.Since(start), seen: map[int]bool{}, done: make(chan struct{})}
+			conns[i] = b
+			go b.read(&run, &runMu, cfg.Count)
+		}(i)
+	}
+	wg.Wait()
+	defer func() {
+		for _, b := range conns {
+			if b.conn != nil {
+				_ = b.conn.Close()
+			}
+		}
+	}()
+
+	var dials []time.Duration
+	for _, b := range conns {
+		if b.conn == nil {
+			report.DialFailed++
+			report.DialErrors[b.err.Error()]++
+			continue
+		}
+		report.Connected++
+		dials = append(dials, b.dial)
+	}
+	if report.Connected == 0 {
+		return report, fmt.Errorf("no connections succeeded (%d failed)", report.DialFailed)
+	}
+	slices.Sort(dials)
+	report.DialP50, report.DialMax = percentile(dials, 50), dials[len(dials)-1]
+
+	runMu.Lock()
+	id, err := startStorm(cfg)
+	if err == nil {
+		run = id
+	}
+	runMu.Unlock()
+	if err != nil {
+		return report, err
+	}
+	started := time.Now()
+
+	deadline := time.After(time.Duration(cfg.Count)*cfg.Interval + cfg.Grace)
+wait:
+	for _, b := range conns {
+		if b.conn == nil {
+			continue
+		}
+		select {
+		case <-b.done:
+		case <-deadline:
+			break wait
+		}
+	}
+	report.Elapsed = time.Since(started)
+
+	report.Expected = cfg.Count * report.Connected
+	report.WorstConn = cfg.Count
+	for _, b := range conns {
+		if b.conn == nil {
+			continue
+		}
+		b.mu.Lock()
+		report.Received += len(b.seen)
+		report.Dupes += b.dupes
+		report.Reordered += b.reordered
+		report.Other += b.other
+		report.Bytes += b.bytes
+		report.Latency = append(report.Latency, b.latencies...)
+		report.WorstConn = min(report.WorstConn, len(b.seen))
+		if b.err != nil {
+			report.Disconnects++
+		}
+		b.mu.Unlock()
+	}
+	slices.Sort(report.Latency)
+	return report, nil
+}
+
+// startStorm asks the server to broadcast the storm and returns its run id.
+func startStorm(cfg benchConfig) (string, error) {
+	u := cfg.StormURL
+	q := u.Query()
+	q.Set("count", strconv.Itoa(cfg.Count))
+	q.Set("interval", cfg.Interval.String())
+	q.Set("size", strconv.Itoa(cfg.Size))
+	u.RawQuery = q.Encode()
+	req, err := http.NewRequest(http.MethodPost, u.String(), nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Authorization", "Bearer "+cfg.Token)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("start storm: %w", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusAccepted {
+		return "", fmt.Errorf("start storm: %s: %s", resp.Status, body)
+	}
+	var out struct {
+		Data struct {
+			Run string `json:"run"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &out); err != nil || out.Data.Run == "" {
+		return "", fmt.Errorf("start storm: unexpected response %s", body)
+	}
+	return out.Data.Run, nil
+}
+
+func percentile(sorted []time.Duration, p int) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	return sorted[(len(sorted)-1)*p/100]
+}
+
+// Print writes a human-readable report.
+func (r *benchReport) Print(w io.Writer) {
+	c := r.Config
+	fmt.Fprintf(w, "storm:       %d messages, interval %s, padding %dB, profile %q\n", c.Count, c.Interval, c.Size, c.Profile)
+	fmt.Fprintf(w, "connections: %d/%d connected (dial p50 %s, max %s)\n", r.Connected, c.Conns, r.DialP50.Round(time.Millisecond), r.DialMax.Round(time.Millisecond))
+	for msg, n := range r.DialErrors {
+		fmt.Fprintf(w, "  dial error x%d: %s\n", n, msg)
+	}
+	dropped := r.Expected - r.Received
+	var dropPct float64
+	if r.Expected > 0 {
+		dropPct = 100 * float64(dropped) / float64(r.Expected)
+	}
+	fmt.Fprintf(w, "delivery:    %d/%d received, %d dropped (%.2f%%), worst connection %d/%d\n", r.Received, r.Expected, dropped, dropPct, r.WorstConn, c.Count)
+	fmt.Fprintf(w, "             %d duplicates, %d out of order, %d disconnects, %d other messages\n", r.Dupes, r.Reordered, r.Disconnects, r.Other)
+	if secs := r.Elapsed.Seconds(); secs > 0 {
+		fmt.Fprintf(w, "throughput:  %.0f msg/s, %.1f MiB/s over %s\n", float64(r.Received)/secs, float64(r.Bytes)/secs/(1<<20), r.Elapsed.Round(time.Millisecond))
+	}
+	if len(r.Latency) > 0 {
+		fmt.Fprintf(w, "latency:     p50 %s  p90 %s  p99 %s  max %s\n",
+			percentile(r.Latency, 50).Round(time.Microsecond), percentile(r.Latency, 90).Round(time.Microsecond),
+			percentile(r.Latency, 99).Round(time.Microsecond), r.Latency[len(r.Latency)-1].Round(time.Microsecond))
+	}
+}
@@ -1,3 +1,11 @@
+// Command ws_client connects to the dashboard websocket and prints the first messages.
+//
+// With -bench it becomes a load generator: it opens -conns connections, asks the server
+// (POST /api/admin/ws-bench, superadmin token) to broadcast a synthetic storm, and
+// reports delivery, drops, ordering and end-to-end latency. Latency assumes the client
+// and server clocks agree, so run it on the server or an NTP-synced host:
+//
+//	go run . -bench -addr hub.example.org:8080 -token $JWT -conns 500 -count 2000 -interval 2ms -size 2048
 package main
 
 import (
@@ -14,13 +22,39 @@ import (
 func main() {
 	addr := flag.String("addr", "localhost:8080", "server address")
 	path := flag.String("path", "/ws", "websocket path")
+	token := flag.String("token", "", "JWT passed as ?token= (bench mode: must be a superadmin)")
+	secure := flag.Bool("tls", false, "use wss:// and https://")
+	bench := flag.Bool("bench", false, "load-test websocket fan-out instead of printing messages")
+	var cfg benchConfig
+	flag.IntVar(&cfg.Conns, "conns", 100, "bench: concurrent websocket connections")
+	flag.IntVar(&cfg.Count, "count", 1000, "bench: messages in the synthetic storm")
+	flag.DurationVar(&cfg.Interval, "interval", time.Millisecond, "bench: gap between storm messages (0 = as fast as possible)")
+	flag.IntVar(&cfg.Size, "size", 0, "bench: padding bytes per message")
+	flag.StringVar(&cfg.Profile, "profile", "", "bench: payload profile requested by each connection (full or compact)")
+	flag.DurationVar(&cfg.Grace, "grace", 5*time.Second, "bench: how long to wait for stragglers after the storm should have finished")
 	flag.Parse()
 
-	u := url.URL{Scheme: "ws", Host: *addr, Path: *path}
+	wsScheme, httpScheme := "ws", "http"
+	if *secure {
+		wsScheme, httpScheme = "wss", "https"
+	}
+	u := url.URL{Scheme: wsScheme, Host: *addr, Path: *path}
 	q := u.Query()
-	q.Set("token", "")
+	q.Set("token", *token)
 	u.RawQuery = q.Encode()
 
+	if *bench {
+		cfg.WSURL = u
+		cfg.StormURL = url.URL{Scheme: httpScheme, Host: *addr, Path: "/api/admin/ws-bench"}
+		cfg.Token = *token
+		report, err := runBench(cfg)
+		if err != nil {
+			log.Fatalf("bench: %v", err)
+		}
+		report.Print(os.Stdout)
+		return
+	}
+
 	log.Printf("connecting to %s", u.String())
 	dialer := websocket.DefaultDialer
 	c, resp, err := dialer.Dial(u.String(), nil)